@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"vanta/pkg/config"
+)
+
+// adminClient talks to the admin API of a running server
+type adminClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// newAdminClient targets adminURL, or the admin listener described by cfg
+// when adminURL is empty. token falls back to admin.token.
+func newAdminClient(cfg *config.Config, adminURL, token string) (*adminClient, error) {
+	if adminURL == "" {
+		if !cfg.Admin.Enabled {
+			return nil, fmt.Errorf("admin API is not enabled; set admin.enabled in the configuration or pass --admin-url")
+		}
+		host := cfg.Admin.Host
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "127.0.0.1"
+		}
+		adminURL = "http://" + net.JoinHostPort(host, strconv.Itoa(cfg.Admin.Port))
+	}
+	if !strings.Contains(adminURL, "://") {
+		adminURL = "http://" + adminURL
+	}
+	if token == "" {
+		token = cfg.Admin.Token
+	}
+
+	return &adminClient{
+		baseURL: strings.TrimSuffix(adminURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// do sends body as JSON to path and decodes the JSON response into out
+func (c *adminClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach admin API at %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read admin API response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("admin API returned %d: %s", resp.StatusCode, apiErr.Error)
+		}
+		return fmt.Errorf("admin API returned %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to decode admin API response: %w", err)
+		}
+	}
+	return nil
+}
@@ -24,6 +24,9 @@ and latency into your OpenAPI mock server to test client resilience.
 Available chaos types:
   - latency: Add artificial delay to responses
   - error:   Return HTTP error responses
+  - bandwidth: Trickle response bodies at a capped bytes-per-second rate
+  - connection_reset: Drop the connection part-way through the response
+  - body_corruption: Truncate, flip bytes in or break the JSON of response bodies
 
 Use subcommands to manage chaos scenarios:
   - start:   Start chaos testing with specified scenarios
@@ -185,6 +188,7 @@ func runChaosStart(ctx context.Context, logger *zap.Logger, configFile, scenario
 		fmt.Printf("  - %s (%s): %.1f%% probability on %v\n", 
 			s.Name, s.Type, s.Probability*100, s.Endpoints)
 	}
+	fmt.Printf("🕒 Active now: %v\n", engine.GetActiveScenarios())
 
 	if duration > 0 {
 		fmt.Printf("⏰ Will run for %v\n", duration)
@@ -261,6 +265,10 @@ func runChaosStatus(ctx context.Context, logger *zap.Logger, configFile string)
 		fmt.Printf("  %d. %s (%s)\n", i+1, scenario.Name, scenario.Type)
 		fmt.Printf("     Endpoints: %v\n", scenario.Endpoints)
 		fmt.Printf("     Probability: %.1f%%\n", scenario.Probability*100)
+		if scenario.Schedule != nil {
+			fmt.Printf("     Schedule: start=%q duration=%v period=%v\n",
+				scenario.Schedule.Start, scenario.Schedule.Duration, scenario.Schedule.Period)
+		}
 		if len(scenario.Parameters) > 0 {
 			fmt.Printf("     Parameters: %v\n", scenario.Parameters)
 		}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 	"vanta/pkg/config"
+	"vanta/pkg/plugins"
 )
 
 func newConfigCommand(ctx context.Context, logger *zap.Logger) *cobra.Command {
@@ -21,70 +23,94 @@ func newConfigCommand(ctx context.Context, logger *zap.Logger) *cobra.Command {
 	cmd.AddCommand(newConfigInitCommand(logger))
 	cmd.AddCommand(newConfigValidateCommand(logger))
 	cmd.AddCommand(newConfigEditCommand(logger))
+	cmd.AddCommand(newConfigSchemaCommand(logger))
 
 	return cmd
 }
 
 func newConfigInitCommand(logger *zap.Logger) *cobra.Command {
-	var outputFile string
+	var (
+		outputFile  string
+		pluginNames []string
+		force       bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize a new configuration file",
-		Long:  `Create a new configuration file with default values.`,
+		Long: `Create a commented configuration file with the default server, logging and
+metrics settings. Each plugin named with --plugins is added enabled, with the
+defaults from its configuration schema. Secrets are referenced from environment
+variables, e.g. the auth plugin reads its JWT secret from VANTA_JWT_SECRET.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if outputFile == "" {
 				outputFile = "vanta.yaml"
 			}
 
 			// Check if file already exists
-			if _, err := os.Stat(outputFile); err == nil {
-				return fmt.Errorf("configuration file already exists: %s", outputFile)
+			if _, err := os.Stat(outputFile); err == nil && !force {
+				return fmt.Errorf("configuration file already exists: %s (use --force to overwrite)", outputFile)
 			}
 
-			logger.Info("Creating configuration file", zap.String("file", outputFile))
+			logger.Info("Creating configuration file",
+				zap.String("file", outputFile),
+				zap.Strings("plugins", pluginNames),
+			)
 
-			// Generate default configuration
-			cfg := config.DefaultConfig()
+			data, err := renderStarterConfig(pluginNames)
+			if err != nil {
+				return err
+			}
 
-			// Write to file
-			if err := config.WriteToFile(cfg, outputFile); err != nil {
+			if err := os.WriteFile(outputFile, data, 0644); err != nil {
 				return fmt.Errorf("failed to write configuration file: %w", err)
 			}
 
 			logger.Info("Configuration file created successfully", zap.String("file", outputFile))
-			fmt.Printf("Configuration file created: %s\n", outputFile)
+			fmt.Fprintf(cmd.OutOrStdout(), "Configuration file created: %s\n", outputFile)
 
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&outputFile, "output", "o", "vanta.yaml", "Output configuration file")
+	cmd.Flags().StringSliceVar(&pluginNames, "plugins", nil, "Comma-separated plugins to enable with their default configuration (e.g. auth,cors)")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing configuration file")
 
 	return cmd
 }
 
 func newConfigValidateCommand(logger *zap.Logger) *cobra.Command {
+	var (
+		configFiles []string
+		strict      bool
+	)
+
 	cmd := &cobra.Command{
-		Use:   "validate [config-file]",
+		Use:   "validate [config-file...]",
 		Short: "Validate a configuration file",
-		Long:  `Validate the syntax and content of a configuration file.`,
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Validate the syntax and content of a configuration file, including the
+configuration of every plugin entry after environment variable substitution.
+Several files are merged in order, later ones overriding earlier ones.
+Exits non-zero if the file or any plugin configuration is invalid.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			configFile := "vanta.yaml"
-			if len(args) > 0 {
-				configFile = args[0]
+			configFiles = append(configFiles, args...)
+			if len(configFiles) == 0 {
+				configFiles = []string{"vanta.yaml"}
 			}
+			configDesc := strings.Join(configFiles, ", ")
 
-			logger.Info("Validating configuration file", zap.String("file", configFile))
+			logger.Info("Validating configuration files", zap.Strings("files", configFiles))
 
-			// Check if file exists
-			if _, err := os.Stat(configFile); os.IsNotExist(err) {
-				return fmt.Errorf("configuration file not found: %s", configFile)
+			// Check if files exist
+			for _, configFile := range configFiles {
+				if _, err := os.Stat(configFile); os.IsNotExist(err) {
+					return fmt.Errorf("configuration file not found: %s", configFile)
+				}
 			}
 
 			// Load and validate configuration
-			cfg, err := config.LoadFromFile(configFile)
+			cfg, err := config.LoadConfigs(configFiles...)
 			if err != nil {
 				return fmt.Errorf("failed to load configuration: %w", err)
 			}
@@ -93,13 +119,39 @@ func newConfigValidateCommand(logger *zap.Logger) *cobra.Command {
 				return fmt.Errorf("configuration validation failed: %w", err)
 			}
 
-			logger.Info("Configuration file is valid", zap.String("file", configFile))
-			fmt.Printf("Configuration file is valid: %s\n", configFile)
+			out := cmd.OutOrStdout()
+			failed := 0
+			for _, report := range plugins.ValidatePluginConfigs(cfg.Plugins, strict) {
+				if report.Valid {
+					fmt.Fprintf(out, "PASS  plugin %s\n", report.Name)
+					continue
+				}
+
+				failed++
+				fmt.Fprintf(out, "FAIL  plugin %s\n", report.Name)
+				for _, validationErr := range report.Errors {
+					if validationErr.Value != nil {
+						fmt.Fprintf(out, "      %s: %s (value: %v)\n", validationErr.Field, validationErr.Message, validationErr.Value)
+					} else {
+						fmt.Fprintf(out, "      %s: %s\n", validationErr.Field, validationErr.Message)
+					}
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d plugin configurations are invalid", failed, len(cfg.Plugins))
+			}
+
+			logger.Info("Configuration is valid", zap.Strings("files", configFiles))
+			fmt.Fprintf(out, "Configuration file is valid: %s\n", configDesc)
 
 			return nil
 		},
 	}
 
+	cmd.Flags().StringArrayVarP(&configFiles, "config", "c", nil, "Configuration file path; repeat to merge files (default vanta.yaml)")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Reject plugin entries that do not name a known plugin")
+
 	return cmd
 }
 
@@ -151,4 +203,41 @@ func newConfigEditCommand(logger *zap.Logger) *cobra.Command {
 	}
 
 	return cmd
-}
\ No newline at end of file
+}
+
+func newConfigSchemaCommand(logger *zap.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema [plugin]",
+		Short: "Print plugin configuration JSON schemas",
+		Long: `Print the JSON schema for a plugin's configuration. Without a plugin name,
+print a combined schema covering every plugin, keyed by plugin name. The
+output can be used for editor autocompletion and validation.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry := plugins.GetConfigRegistry()
+
+			var (
+				document []byte
+				err      error
+			)
+			if len(args) > 0 {
+				if _, exists := registry.GetSchema(args[0]); !exists {
+					return fmt.Errorf("unknown plugin %q (available: %s)", args[0], strings.Join(registry.SchemaNames(), ", "))
+				}
+				document, err = registry.SchemaJSON(args[0])
+			} else {
+				document, err = registry.CombinedSchemaJSON()
+			}
+			if err != nil {
+				return fmt.Errorf("failed to encode schema: %w", err)
+			}
+
+			logger.Debug("Printing plugin configuration schema", zap.Strings("plugins", args))
+			fmt.Fprintln(cmd.OutOrStdout(), string(document))
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"vanta/pkg/config"
+	"vanta/pkg/plugins"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "vanta.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func runConfigValidate(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newConfigValidateCommand(zap.NewNop())
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestConfigValidate_ValidFile(t *testing.T) {
+	t.Setenv("TEST_JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	path := writeConfigFile(t, `
+plugins:
+  - name: auth
+    enabled: true
+    config:
+      jwt_secret: "${TEST_JWT_SECRET}"
+      jwt_method: HS256
+  - name: rate_limit
+    enabled: true
+    config:
+      global_requests_per_second: 100
+      global_burst: 200
+`)
+
+	out, err := runConfigValidate(t, "--config", path, "--strict")
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "PASS  plugin auth")
+	assert.Contains(t, out, "PASS  plugin rate_limit")
+	assert.Contains(t, out, "Configuration file is valid")
+}
+
+func TestConfigValidate_BadJWTMethod(t *testing.T) {
+	path := writeConfigFile(t, `
+plugins:
+  - name: auth
+    enabled: true
+    config:
+      jwt_secret: "0123456789abcdef0123456789abcdef"
+      jwt_method: HS999
+`)
+
+	out, err := runConfigValidate(t, "--config", path)
+	require.Error(t, err)
+	assert.Contains(t, out, "FAIL  plugin auth")
+	assert.Contains(t, out, "jwt_method:")
+	assert.Contains(t, out, "HS999")
+}
+
+func TestConfigValidate_StrictRejectsUnknownPlugin(t *testing.T) {
+	path := writeConfigFile(t, `
+plugins:
+  - name: not_a_plugin
+    enabled: true
+`)
+
+	out, err := runConfigValidate(t, "--config", path)
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "PASS  plugin not_a_plugin")
+
+	out, err = runConfigValidate(t, "--config", path, "--strict")
+	require.Error(t, err)
+	assert.Contains(t, out, "FAIL  plugin not_a_plugin")
+	assert.Contains(t, out, "unknown plugin")
+}
+
+func TestConfigValidate_MissingEnvironmentVariable(t *testing.T) {
+	path := writeConfigFile(t, `
+plugins:
+  - name: auth
+    enabled: true
+    config:
+      jwt_secret: "${TEST_UNSET_JWT_SECRET:?jwt secret is required}"
+`)
+
+	out, err := runConfigValidate(t, "--config", path)
+	require.Error(t, err)
+	assert.Contains(t, out, "environment substitution failed")
+}
+
+func runConfigInit(t *testing.T, args ...string) error {
+	t.Helper()
+	cmd := newConfigInitCommand(zap.NewNop())
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(args)
+	return cmd.Execute()
+}
+
+func TestConfigInit_GeneratesValidConfig(t *testing.T) {
+	t.Setenv("VANTA_JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	path := filepath.Join(t.TempDir(), "vanta.yaml")
+	included := []string{"auth", "cors", "logging", "rate_limit", "versioning"}
+
+	require.NoError(t, runConfigInit(t, "--output", path, "--plugins", "auth,cors,logging,rate_limit,versioning"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Secret key for HMAC-based JWT signing")
+
+	cfg, err := config.LoadConfig(path)
+	require.NoError(t, err)
+	require.NoError(t, config.Validate(cfg))
+	assert.Equal(t, config.DefaultConfig().Server.ReadTimeout, cfg.Server.ReadTimeout)
+	assert.Equal(t, config.DefaultConfig().Metrics.Port, cfg.Metrics.Port)
+
+	require.Len(t, cfg.Plugins, len(included))
+	for i, report := range plugins.ValidatePluginConfigs(cfg.Plugins, true) {
+		assert.Equal(t, included[i], report.Name)
+		assert.True(t, cfg.Plugins[i].Enabled)
+		assert.True(t, report.Valid, "plugin %s: %v", report.Name, report.Errors)
+	}
+}
+
+func TestConfigInit_RefusesToOverwrite(t *testing.T) {
+	path := writeConfigFile(t, "server:\n  port: 1234\n")
+
+	err := runConfigInit(t, "--output", path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "server:\n  port: 1234\n", string(data))
+
+	require.NoError(t, runConfigInit(t, "--output", path, "--force"))
+	cfg, err := config.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Server.Port)
+}
+
+func TestConfigInit_UnknownPlugin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vanta.yaml")
+
+	err := runConfigInit(t, "--output", path, "--plugins", "not_a_plugin")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown plugin")
+	assert.NoFileExists(t, path)
+}
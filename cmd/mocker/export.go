@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"vanta/pkg/recorder"
+)
+
+// harLog is the root of an HTTP Archive 1.2 document
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// exportedRecording is a recording as written by the json and ndjson
+// exports: text bodies inline, binary bodies base64-encoded
+type exportedRecording struct {
+	*recorder.Recording
+	Request  exportedRequest  `json:"request"`
+	Response exportedResponse `json:"response"`
+}
+
+type exportedRequest struct {
+	recorder.RecordedRequest
+	Body         string `json:"body,omitempty"`
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+type exportedResponse struct {
+	recorder.RecordedResponse
+	Body         string `json:"body,omitempty"`
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+func newExportedRecording(recording *recorder.Recording) exportedRecording {
+	exported := exportedRecording{
+		Recording: recording,
+		Request:   exportedRequest{RecordedRequest: recording.Request},
+		Response:  exportedResponse{RecordedResponse: recording.Response},
+	}
+	exported.Request.Body, exported.Request.BodyEncoding = encodeBody(recording.Request.Body)
+	exported.Response.Body, exported.Response.BodyEncoding = encodeBody(recording.Response.Body)
+	return exported
+}
+
+// exportWriter opens output for writing, or stdout when output is empty
+func exportWriter(output string) (io.WriteCloser, error) {
+	if output == "" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	file, err := os.Create(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return file, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// writeExport encodes document as indented JSON to output
+func writeExport(document interface{}, output string) error {
+	writer, err := exportWriter(output)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return writer.Close()
+}
+
+// exportJSON writes the recordings as a JSON array
+func exportJSON(recordings []*recorder.Recording, output string) error {
+	exported := make([]exportedRecording, 0, len(recordings))
+	for _, recording := range recordings {
+		exported = append(exported, newExportedRecording(recording))
+	}
+	return writeExport(exported, output)
+}
+
+// exportNDJSON streams the recordings produced by each as one JSON object per
+// line, so no more than one recording is held in memory. It returns the
+// number of recordings written.
+func exportNDJSON(each func(fn func(*recorder.Recording) error) error, output string) (int, error) {
+	writer, err := exportWriter(output)
+	if err != nil {
+		return 0, err
+	}
+
+	buffered := bufio.NewWriter(writer)
+	encoder := json.NewEncoder(buffered)
+	count := 0
+	err = each(func(recording *recorder.Recording) error {
+		if err := encoder.Encode(newExportedRecording(recording)); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		writer.Close()
+		return count, fmt.Errorf("failed to write export: %w", err)
+	}
+	return count, writer.Close()
+}
+
+// exportHAR writes the recordings as an HTTP Archive
+func exportHAR(recordings []*recorder.Recording, output string) error {
+	return writeExport(harFromRecordings(recordings), output)
+}
+
+// harFromRecordings converts recordings to HAR entries. Recorded URIs are
+// relative, so the URL host comes from the recorded Host header.
+func harFromRecordings(recordings []*recorder.Recording) *harLog {
+	document := &harLog{}
+	document.Log.Version = "1.2"
+	document.Log.Creator = harCreator{Name: "vanta", Version: version}
+	document.Log.Entries = make([]harEntry, 0, len(recordings))
+
+	for _, recording := range recordings {
+		host := headerValue(recording.Request.Headers, "Host")
+		if host == "" {
+			host = "localhost"
+		}
+		requestURL := "http://" + host + recording.Request.URI
+
+		elapsed := float64(recording.Duration) / float64(time.Millisecond)
+		entry := harEntry{
+			StartedDateTime: recording.Timestamp.Format(time.RFC3339Nano),
+			Time:            elapsed,
+			Request: harRequest{
+				Method:      recording.Request.Method,
+				URL:         requestURL,
+				HTTPVersion: "HTTP/1.1",
+				Cookies:     []harNameValue{},
+				Headers:     harHeaders(recording.Request.Headers),
+				QueryString: harQueryString(recording.Request.URI),
+				HeadersSize: -1,
+				BodySize:    len(recording.Request.Body),
+			},
+			Response: harResponse{
+				Status:      recording.Response.StatusCode,
+				StatusText:  http.StatusText(recording.Response.StatusCode),
+				HTTPVersion: "HTTP/1.1",
+				Cookies:     []harNameValue{},
+				Headers:     harHeaders(recording.Response.Headers),
+				Content:     harBody(recording.Response.Body, recording.Response.ContentType),
+				RedirectURL: headerValue(recording.Response.Headers, "Location"),
+				HeadersSize: -1,
+				BodySize:    len(recording.Response.Body),
+			},
+			Timings: harTimings{Wait: elapsed},
+			Comment: recording.ID,
+		}
+		if len(recording.Request.Body) > 0 {
+			entry.Request.PostData = &harPostData{
+				MimeType: recording.Request.ContentType,
+				Text:     string(recording.Request.Body),
+			}
+		}
+		if recording.Metadata.BodyOmitted {
+			entry.Response.BodySize = -1
+		}
+		document.Log.Entries = append(document.Log.Entries, entry)
+	}
+
+	return document
+}
+
+// harHeaders lists headers sorted by name so exports are stable
+func harHeaders(headers map[string]string) []harNameValue {
+	pairs := make([]harNameValue, 0, len(headers))
+	for name, value := range headers {
+		pairs = append(pairs, harNameValue{Name: name, Value: value})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+	return pairs
+}
+
+func harQueryString(uri string) []harNameValue {
+	pairs := []harNameValue{}
+	parsed, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return pairs
+	}
+	for _, param := range strings.Split(parsed.RawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(param, "=")
+		name, _ = url.QueryUnescape(name)
+		value, _ = url.QueryUnescape(value)
+		pairs = append(pairs, harNameValue{Name: name, Value: value})
+	}
+	return pairs
+}
+
+// harBody keeps text bodies as is and base64-encodes binary ones
+func harBody(body []byte, contentType string) harContent {
+	content := harContent{Size: len(body), MimeType: contentType}
+	content.Text, content.Encoding = encodeBody(body)
+	return content
+}
+
+// encodeBody returns a text body as is and a binary one base64-encoded,
+// along with the encoding used
+func encodeBody(body []byte) (text, encoding string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
@@ -2,13 +2,17 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"vanta/pkg/api"
 	"vanta/pkg/config"
 	"vanta/pkg/recorder"
 )
@@ -60,11 +64,15 @@ func newRecordStartCommand(ctx context.Context, logger *zap.Logger) *cobra.Comma
 	var outputDir string
 	var maxRecordings int
 	var maxBodySize string
+	var adminURL string
+	var adminToken string
 
 	cmd := &cobra.Command{
 		Use:   "start",
 		Short: "Start recording API traffic",
-		Long:  `Start recording incoming API requests and responses to files.`,
+		Long: `Start recording incoming API requests and responses to files on a
+running server. The server is reached through its admin API, which must be
+enabled (admin.enabled) in its configuration.`,
 		Example: `  # Start recording with default settings
   mocker record start
 
@@ -77,7 +85,7 @@ func newRecordStartCommand(ctx context.Context, logger *zap.Logger) *cobra.Comma
   # Start recording with limits
   mocker record start --max-recordings 500 --max-body-size 2MB`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRecordStart(ctx, logger, configPath, filters, outputDir, maxRecordings, maxBodySize)
+			return runRecordStart(ctx, logger, configPath, adminURL, adminToken, filters, outputDir, maxRecordings, maxBodySize)
 		},
 	}
 
@@ -86,6 +94,8 @@ func newRecordStartCommand(ctx context.Context, logger *zap.Logger) *cobra.Comma
 	cmd.Flags().StringVarP(&outputDir, "output", "o", "", "Output directory for recordings")
 	cmd.Flags().IntVar(&maxRecordings, "max-recordings", 0, "Maximum number of recordings to keep")
 	cmd.Flags().StringVar(&maxBodySize, "max-body-size", "", "Maximum body size to record (e.g., 1MB, 2KB)")
+	cmd.Flags().StringVar(&adminURL, "admin-url", "", "Admin API address of the running server (default: from admin config)")
+	cmd.Flags().StringVar(&adminToken, "admin-token", "", "Admin API token (default: admin.token)")
 
 	return cmd
 }
@@ -93,22 +103,26 @@ func newRecordStartCommand(ctx context.Context, logger *zap.Logger) *cobra.Comma
 // newRecordStopCommand creates the record stop subcommand
 func newRecordStopCommand(ctx context.Context, logger *zap.Logger) *cobra.Command {
 	var configPath string
+	var adminURL string
+	var adminToken string
 
 	cmd := &cobra.Command{
 		Use:   "stop",
 		Short: "Stop active recording",
-		Long:  `Stop the currently active recording session.`,
+		Long:  `Stop the recording session active on a running server, through its admin API.`,
 		Example: `  # Stop recording
   mocker record stop
 
   # Stop recording with custom config
   mocker record stop --config recording.yaml`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRecordStop(ctx, logger, configPath)
+			return runRecordStop(ctx, logger, configPath, adminURL, adminToken)
 		},
 	}
 
 	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Configuration file path")
+	cmd.Flags().StringVar(&adminURL, "admin-url", "", "Admin API address of the running server (default: from admin config)")
+	cmd.Flags().StringVar(&adminToken, "admin-token", "", "Admin API token (default: admin.token)")
 
 	return cmd
 }
@@ -117,9 +131,14 @@ func newRecordStopCommand(ctx context.Context, logger *zap.Logger) *cobra.Comman
 func newRecordListCommand(ctx context.Context, logger *zap.Logger) *cobra.Command {
 	var configPath string
 	var limit int
+	var offset int
+	var page int
 	var method string
 	var status string
 	var since string
+	var headers []string
+	var grep string
+	var minCount int
 
 	cmd := &cobra.Command{
 		Use:   "list",
@@ -131,21 +150,45 @@ func newRecordListCommand(ctx context.Context, logger *zap.Logger) *cobra.Comman
   # List last 10 recordings
   mocker record list --limit 10
 
+  # Browse the third page of 20 recordings
+  mocker record list --limit 20 --page 3
+
   # List GET requests only
   mocker record list --method GET
 
   # List recordings from the last hour
-  mocker record list --since 1h`,
+  mocker record list --since 1h
+
+  # List a tenant's requests whose body mentions an order
+  mocker record list --header X-Tenant-Id=acme --grep order-1234
+
+  # List deduplicated exchanges seen at least 10 times
+  mocker record list --min-count 10`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRecordList(ctx, logger, configPath, limit, method, status, since)
+			if cmd.Flags().Changed("page") {
+				var err error
+				if offset, err = pageOffset(page, limit); err != nil {
+					return err
+				}
+			}
+			if offset < 0 {
+				return fmt.Errorf("--offset cannot be negative")
+			}
+			return runRecordList(ctx, logger, configPath, limit, offset, method, status, since, headers, grep, minCount)
 		},
 	}
 
 	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Configuration file path")
 	cmd.Flags().IntVarP(&limit, "limit", "l", 50, "Maximum number of recordings to list")
+	cmd.Flags().IntVar(&offset, "offset", 0, "Number of matching recordings to skip")
+	cmd.Flags().IntVar(&page, "page", 1, "Page of --limit recordings to list, starting at 1")
 	cmd.Flags().StringVarP(&method, "method", "m", "", "Filter by HTTP method")
 	cmd.Flags().StringVarP(&status, "status", "s", "", "Filter by status code")
 	cmd.Flags().StringVar(&since, "since", "", "Filter by time (e.g., 1h, 30m, 24h)")
+	cmd.Flags().StringArrayVar(&headers, "header", nil, "Filter by request header value (name=value, repeatable)")
+	cmd.Flags().StringVar(&grep, "grep", "", "Filter by text in the request or response body")
+	cmd.Flags().IntVar(&minCount, "min-count", 0, "Filter by times a deduplicated recording was seen")
+	cmd.MarkFlagsMutuallyExclusive("offset", "page")
 
 	return cmd
 }
@@ -215,6 +258,9 @@ func newRecordReplayCommand(ctx context.Context, logger *zap.Logger) *cobra.Comm
 	var recordingIDs []string
 	var since string
 	var limit int
+	var preserveTiming bool
+	var timeScale float64
+	var report string
 
 	cmd := &cobra.Command{
 		Use:   "replay",
@@ -230,9 +276,15 @@ func newRecordReplayCommand(ctx context.Context, logger *zap.Logger) *cobra.Comm
   mocker record replay --target http://localhost:8080 --concurrency 5 --delay 100ms
 
   # Replay recent recordings
-  mocker record replay --target http://localhost:8080 --since 1h --limit 10`,
+  mocker record replay --target http://localhost:8080 --since 1h --limit 10
+
+  # Reproduce the recorded gaps between requests at double speed
+  mocker record replay --target http://localhost:8080 --preserve-timing --time-scale 0.5
+
+  # Compare replayed latency with the recorded latency
+  mocker record replay --target http://localhost:8080 --report latency.csv`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRecordReplay(ctx, logger, configPath, targetURL, concurrency, delay, recordingIDs, since, limit)
+			return runRecordReplay(ctx, logger, configPath, targetURL, concurrency, delay, recordingIDs, since, limit, preserveTiming, timeScale, report)
 		},
 	}
 
@@ -243,6 +295,9 @@ func newRecordReplayCommand(ctx context.Context, logger *zap.Logger) *cobra.Comm
 	cmd.Flags().StringSliceVar(&recordingIDs, "ids", nil, "Specific recording IDs to replay")
 	cmd.Flags().StringVar(&since, "since", "", "Replay recordings from specific time (e.g., 1h, 30m)")
 	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of recordings to replay")
+	cmd.Flags().BoolVar(&preserveTiming, "preserve-timing", false, "Space requests by their recorded gaps instead of --delay")
+	cmd.Flags().Float64Var(&timeScale, "time-scale", 1, "Multiplier for recorded gaps with --preserve-timing (0.5 = twice as fast)")
+	cmd.Flags().StringVar(&report, "report", "", "Write per-recording status and latency to this file (.csv for CSV, JSON otherwise)")
 
 	cmd.MarkFlagRequired("target")
 
@@ -255,35 +310,60 @@ func newRecordExportCommand(ctx context.Context, logger *zap.Logger) *cobra.Comm
 	var format string
 	var output string
 	var recordingIDs []string
+	var limit int
+	var method string
+	var status string
+	var since string
+	var grep string
 
 	cmd := &cobra.Command{
 		Use:   "export",
 		Short: "Export recordings to different formats",
-		Long:  `Export recordings to various formats like HAR, Postman, or cURL commands.`,
+		Long:  `Export recordings to various formats like JSON, NDJSON, HAR, Postman, or cURL commands.`,
 		Example: `  # Export all recordings to HAR format
   mocker record export --format har --output recordings.har
 
   # Export specific recordings to Postman collection
   mocker record export --format postman --output collection.json --ids abc123,def456
 
+  # Stream a large capture as one JSON recording per line
+  mocker record export --format ndjson --output recordings.ndjson
+
   # Export recordings as cURL commands
-  mocker record export --format curl --output commands.sh`,
+  mocker record export --format curl --output commands.sh
+
+  # Export the last hour of server errors to HAR format
+  mocker record export --format har --output errors.har --status 5xx --since 1h`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRecordExport(ctx, logger, configPath, format, output, recordingIDs)
+			filter, err := buildListFilter(limit, method, status, since, nil, grep, 0)
+			if err != nil {
+				return err
+			}
+			return runRecordExport(ctx, logger, configPath, format, output, recordingIDs, filter)
 		},
 	}
 
 	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Configuration file path")
-	cmd.Flags().StringVar(&format, "format", "json", "Export format (json, har, postman, curl)")
+	cmd.Flags().StringVar(&format, "format", "json", "Export format (json, ndjson, har, postman, curl)")
 	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file (default: stdout)")
 	cmd.Flags().StringSliceVar(&recordingIDs, "ids", nil, "Specific recording IDs to export")
+	cmd.Flags().IntVarP(&limit, "limit", "l", 0, "Maximum number of recordings to export")
+	cmd.Flags().StringVarP(&method, "method", "m", "", "Filter by HTTP method")
+	cmd.Flags().StringVarP(&status, "status", "s", "", "Filter by status codes or classes (e.g., 404, 500,503, 5xx)")
+	cmd.Flags().StringVar(&since, "since", "", "Filter by time (e.g., 1h, 30m, 24h)")
+	cmd.Flags().StringVar(&grep, "grep", "", "Filter by text in the request or response body")
+	cmd.MarkFlagsMutuallyExclusive("ids", "limit")
+	cmd.MarkFlagsMutuallyExclusive("ids", "method")
+	cmd.MarkFlagsMutuallyExclusive("ids", "status")
+	cmd.MarkFlagsMutuallyExclusive("ids", "since")
+	cmd.MarkFlagsMutuallyExclusive("ids", "grep")
 
 	return cmd
 }
 
 // Implementation functions
 
-func runRecordStart(ctx context.Context, logger *zap.Logger, configPath string, filters []string, outputDir string, maxRecordings int, maxBodySize string) error {
+func runRecordStart(ctx context.Context, logger *zap.Logger, configPath, adminURL, adminToken string, filters []string, outputDir string, maxRecordings int, maxBodySize string) error {
 	fmt.Println("🎬 Starting recording...")
 
 	// Load configuration
@@ -292,19 +372,22 @@ func runRecordStart(ctx context.Context, logger *zap.Logger, configPath string,
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Override configuration with command line parameters
-	if outputDir != "" {
-		cfg.Recording.Storage.Directory = outputDir
+	client, err := newAdminClient(cfg, adminURL, adminToken)
+	if err != nil {
+		return err
 	}
-	if maxRecordings > 0 {
-		cfg.Recording.MaxRecordings = maxRecordings
+
+	// Command line parameters override the server's recording configuration
+	req := api.RecordingStartRequest{
+		Directory:     outputDir,
+		MaxRecordings: maxRecordings,
 	}
 	if maxBodySize != "" {
 		size, err := parseSize(maxBodySize)
 		if err != nil {
 			return fmt.Errorf("invalid max body size: %w", err)
 		}
-		cfg.Recording.MaxBodySize = size
+		req.MaxBodySize = size
 	}
 
 	// Parse command line filters
@@ -313,22 +396,32 @@ func runRecordStart(ctx context.Context, logger *zap.Logger, configPath string,
 		if err != nil {
 			return fmt.Errorf("invalid filters: %w", err)
 		}
-		cfg.Recording.Filters = append(cfg.Recording.Filters, parsedFilters...)
+		req.Filters = parsedFilters
+	}
+
+	if err := client.do(ctx, "POST", "/recording/start", req, nil); err != nil {
+		return fmt.Errorf("failed to start recording: %w", err)
 	}
 
-	// Enable recording
-	cfg.Recording.Enabled = true
+	// Report what the server is actually doing rather than what was asked
+	var status api.RecordingStatus
+	if err := client.do(ctx, "GET", "/recording", nil, &status); err != nil {
+		return fmt.Errorf("failed to query recording status: %w", err)
+	}
+	if !status.Recording {
+		return fmt.Errorf("server did not start recording")
+	}
 
-	fmt.Printf("✅ Recording enabled\n")
-	fmt.Printf("📁 Storage directory: %s\n", cfg.Recording.Storage.Directory)
-	fmt.Printf("📊 Max recordings: %d\n", cfg.Recording.MaxRecordings)
-	fmt.Printf("📏 Max body size: %d bytes\n", cfg.Recording.MaxBodySize)
-	fmt.Printf("🔍 Filters: %d configured\n", len(cfg.Recording.Filters))
+	fmt.Printf("✅ Recording on\n")
+	fmt.Printf("📁 Storage directory: %s\n", status.Directory)
+	fmt.Printf("📊 Max recordings: %d\n", status.MaxRecordings)
+	fmt.Printf("📏 Max body size: %d bytes\n", status.MaxBodySize)
+	fmt.Printf("🔍 Filters: %d configured\n", status.Filters)
 
 	return nil
 }
 
-func runRecordStop(ctx context.Context, logger *zap.Logger, configPath string) error {
+func runRecordStop(ctx context.Context, logger *zap.Logger, configPath, adminURL, adminToken string) error {
 	fmt.Println("⏹️  Stopping recording...")
 
 	// Load configuration
@@ -337,14 +430,31 @@ func runRecordStop(ctx context.Context, logger *zap.Logger, configPath string) e
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Disable recording
-	cfg.Recording.Enabled = false
+	client, err := newAdminClient(cfg, adminURL, adminToken)
+	if err != nil {
+		return err
+	}
+
+	if err := client.do(ctx, "POST", "/recording/stop", nil, nil); err != nil {
+		return fmt.Errorf("failed to stop recording: %w", err)
+	}
+
+	var status api.RecordingStatus
+	if err := client.do(ctx, "GET", "/recording", nil, &status); err != nil {
+		return fmt.Errorf("failed to query recording status: %w", err)
+	}
+	if status.Recording {
+		return fmt.Errorf("server is still recording")
+	}
 
-	fmt.Println("✅ Recording stopped")
+	fmt.Println("✅ Recording off")
+	if status.Stats != nil {
+		fmt.Printf("📊 Recorded requests: %d\n", status.Stats.RecordedRequests)
+	}
 	return nil
 }
 
-func runRecordList(ctx context.Context, logger *zap.Logger, configPath string, limit int, method, status, since string) error {
+func runRecordList(ctx context.Context, logger *zap.Logger, configPath string, limit, offset int, method, status, since string, headers []string, grep string, minCount int) error {
 	// Load storage configuration
 	cfg, err := loadConfigForRecording(configPath)
 	if err != nil {
@@ -358,54 +468,57 @@ func runRecordList(ctx context.Context, logger *zap.Logger, configPath string, l
 	}
 	defer storage.Close()
 
-	// Build filter
-	filter := recorder.ListFilter{
-		Limit: limit,
-	}
-
-	if method != "" {
-		filter.Methods = []string{method}
-	}
-
-	if status != "" {
-		statusCode, err := strconv.Atoi(status)
-		if err != nil {
-			return fmt.Errorf("invalid status code: %s", status)
-		}
-		filter.StatusCodes = []int{statusCode}
-	}
-
-	if since != "" {
-		duration, err := time.ParseDuration(since)
-		if err != nil {
-			return fmt.Errorf("invalid duration: %s", since)
-		}
-		filter.StartTime = time.Now().Add(-duration)
+	filter, err := buildListFilter(limit, method, status, since, headers, grep, minCount)
+	if err != nil {
+		return err
 	}
+	filter.Offset = offset
 
 	// List recordings
-	recordings, err := storage.List(filter)
+	page, err := storage.ListPage(filter)
 	if err != nil {
 		return fmt.Errorf("failed to list recordings: %w", err)
 	}
+	recordings := page.Recordings
 
 	// Display results
-	fmt.Printf("📋 Found %d recordings:\n\n", len(recordings))
-	fmt.Printf("%-40s %-8s %-50s %-6s %-20s\n", "ID", "METHOD", "URI", "STATUS", "TIMESTAMP")
-	fmt.Printf("%s\n", strings.Repeat("-", 130))
+	fmt.Printf("📋 Found %d recordings:\n\n", page.Total)
+	fmt.Printf("%-40s %-8s %-50s %-6s %-6s %-20s\n", "ID", "METHOD", "URI", "STATUS", "COUNT", "TIMESTAMP")
+	fmt.Printf("%s\n", strings.Repeat("-", 137))
 
 	for _, recording := range recordings {
-		fmt.Printf("%-40s %-8s %-50s %-6d %-20s\n",
+		fmt.Printf("%-40s %-8s %-50s %-6d %-6d %-20s\n",
 			recording.ID[:40],
 			recording.Request.Method,
 			truncateString(recording.Request.URI, 50),
 			recording.Response.StatusCode,
+			max(recording.Metadata.Count, 1),
 			recording.Timestamp.Format("2006-01-02 15:04:05"))
 	}
 
+	fmt.Printf("\n%s\n", pageFooter(page))
 	return nil
 }
 
+// pageOffset converts a 1-based page of limit recordings into an offset
+func pageOffset(page, limit int) (int, error) {
+	if page < 1 {
+		return 0, fmt.Errorf("--page must be at least 1")
+	}
+	if limit <= 0 {
+		return 0, fmt.Errorf("--page requires a positive --limit")
+	}
+	return (page - 1) * limit, nil
+}
+
+// pageFooter describes which part of the matching recordings a page shows
+func pageFooter(page *recorder.RecordingPage) string {
+	if len(page.Recordings) == 0 {
+		return fmt.Sprintf("Showing 0 of %d", page.Total)
+	}
+	return fmt.Sprintf("Showing %d–%d of %d", page.Offset+1, page.Offset+len(page.Recordings), page.Total)
+}
+
 func runRecordShow(ctx context.Context, logger *zap.Logger, configPath, recordingID, format string) error {
 	// Load storage configuration
 	cfg, err := loadConfigForRecording(configPath)
@@ -487,7 +600,7 @@ func runRecordDelete(ctx context.Context, logger *zap.Logger, configPath string,
 	return nil
 }
 
-func runRecordReplay(ctx context.Context, logger *zap.Logger, configPath, targetURL string, concurrency int, delay string, recordingIDs []string, since string, limit int) error {
+func runRecordReplay(ctx context.Context, logger *zap.Logger, configPath, targetURL string, concurrency int, delay string, recordingIDs []string, since string, limit int, preserveTiming bool, timeScale float64, report string) error {
 	fmt.Printf("🔄 Starting replay to %s...\n", targetURL)
 
 	// Load storage configuration
@@ -512,13 +625,19 @@ func runRecordReplay(ctx context.Context, logger *zap.Logger, configPath, target
 		return fmt.Errorf("invalid delay duration: %w", err)
 	}
 
+	if timeScale <= 0 {
+		return fmt.Errorf("time scale must be positive, got %v", timeScale)
+	}
+
 	// Create replay configuration
 	replayConfig := &recorder.ReplayConfig{
-		TargetURL:    targetURL,
-		Concurrency:  concurrency,
-		DelayBetween: delayDuration,
-		Timeout:      30 * time.Second,
-		ReplaceHost:  true,
+		TargetURL:      targetURL,
+		Concurrency:    concurrency,
+		DelayBetween:   delayDuration,
+		Timeout:        30 * time.Second,
+		ReplaceHost:    true,
+		PreserveTiming: preserveTiming,
+		TimeScale:      timeScale,
 	}
 
 	// Load recordings
@@ -559,11 +678,96 @@ func runRecordReplay(ctx context.Context, logger *zap.Logger, configPath, target
 	fmt.Printf("   Average latency: %v\n", stats.AverageLatency)
 	fmt.Printf("   Duration: %v\n", stats.EndTime.Sub(stats.StartTime))
 
+	if report != "" {
+		if err := writeReplayReport(stats.Results, report); err != nil {
+			return err
+		}
+		fmt.Printf("   Report: %s\n", report)
+	}
+
 	return nil
 }
 
-func runRecordExport(ctx context.Context, logger *zap.Logger, configPath, format, output string, recordingIDs []string) error {
-	fmt.Printf("📤 Exporting recordings in %s format...\n", format)
+// replayReportRow is one replayed recording in a replay report. Durations
+// are in milliseconds so JSON and CSV reports read the same.
+type replayReportRow struct {
+	ID             string  `json:"id"`
+	Method         string  `json:"method"`
+	URI            string  `json:"uri"`
+	RecordedStatus int     `json:"recorded_status"`
+	ReplayedStatus int     `json:"replayed_status"`
+	StatusMatch    bool    `json:"status_match"`
+	RecordedMs     float64 `json:"recorded_ms"`
+	ReplayedMs     float64 `json:"replayed_ms"`
+	DeltaMs        float64 `json:"delta_ms"`
+	Error          string  `json:"error,omitempty"`
+}
+
+func newReplayReportRow(result recorder.ReplayResult) replayReportRow {
+	recorded := durationMs(result.RecordedDuration)
+	replayed := durationMs(result.ReplayedDuration)
+	return replayReportRow{
+		ID:             result.ID,
+		Method:         result.Method,
+		URI:            result.URI,
+		RecordedStatus: result.RecordedStatus,
+		ReplayedStatus: result.ReplayedStatus,
+		StatusMatch:    result.StatusMatch,
+		RecordedMs:     recorded,
+		ReplayedMs:     replayed,
+		DeltaMs:        replayed - recorded,
+		Error:          result.Error,
+	}
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// writeReplayReport writes one row per replayed recording to path, as CSV
+// when the file ends in .csv and as a JSON array otherwise
+func writeReplayReport(results []recorder.ReplayResult, path string) error {
+	rows := make([]replayReportRow, 0, len(results))
+	for _, result := range results {
+		rows = append(rows, newReplayReportRow(result))
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		return writeExport(rows, path)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"id", "method", "uri", "recorded_status", "replayed_status", "status_match", "recorded_ms", "replayed_ms", "delta_ms", "error"})
+	for _, row := range rows {
+		writer.Write([]string{
+			row.ID,
+			row.Method,
+			row.URI,
+			strconv.Itoa(row.RecordedStatus),
+			strconv.Itoa(row.ReplayedStatus),
+			strconv.FormatBool(row.StatusMatch),
+			strconv.FormatFloat(row.RecordedMs, 'f', 3, 64),
+			strconv.FormatFloat(row.ReplayedMs, 'f', 3, 64),
+			strconv.FormatFloat(row.DeltaMs, 'f', 3, 64),
+			row.Error,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return file.Close()
+}
+
+func runRecordExport(ctx context.Context, logger *zap.Logger, configPath, format, output string, recordingIDs []string, filter recorder.ListFilter) error {
+	// Progress goes to stderr so an export to stdout stays parseable
+	fmt.Fprintf(os.Stderr, "📤 Exporting recordings in %s format...\n", format)
 
 	// Load storage configuration
 	cfg, err := loadConfigForRecording(configPath)
@@ -578,27 +782,43 @@ func runRecordExport(ctx context.Context, logger *zap.Logger, configPath, format
 	}
 	defer storage.Close()
 
-	// Load recordings
-	var recordings []*recorder.Recording
-	if len(recordingIDs) > 0 {
+	// Recordings are produced one at a time, by ID or from the filter
+	each := func(fn func(*recorder.Recording) error) error {
+		if len(recordingIDs) == 0 {
+			return storage.Walk(filter, fn)
+		}
 		for _, id := range recordingIDs {
 			recording, err := storage.Load(id)
 			if err != nil {
 				logger.Warn("Failed to load recording", zap.String("id", id), zap.Error(err))
 				continue
 			}
-			recordings = append(recordings, recording)
+			if err := fn(recording); err != nil {
+				return err
+			}
 		}
-	} else {
-		// Load all recordings
-		allRecordings, err := storage.List(recorder.ListFilter{})
+		return nil
+	}
+
+	// NDJSON streams, every other format needs the whole set
+	if format == "ndjson" {
+		count, err := exportNDJSON(each, output)
 		if err != nil {
-			return fmt.Errorf("failed to list recordings: %w", err)
+			return err
 		}
-		recordings = allRecordings
+		fmt.Fprintf(os.Stderr, "📋 Exported %d recordings\n", count)
+		return nil
+	}
+
+	var recordings []*recorder.Recording
+	if err := each(func(recording *recorder.Recording) error {
+		recordings = append(recordings, recording)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to list recordings: %w", err)
 	}
 
-	fmt.Printf("📋 Loaded %d recordings for export\n", len(recordings))
+	fmt.Fprintf(os.Stderr, "📋 Loaded %d recordings for export\n", len(recordings))
 
 	// Export based on format
 	switch format {
@@ -615,6 +835,72 @@ func runRecordExport(ctx context.Context, logger *zap.Logger, configPath, format
 
 // Helper functions
 
+// buildListFilter turns the recording filter flags shared by list and export
+// into a ListFilter. status takes comma-separated codes or classes like 5xx.
+func buildListFilter(limit int, method, status, since string, headers []string, grep string, minCount int) (recorder.ListFilter, error) {
+	filter := recorder.ListFilter{
+		Limit:        limit,
+		BodyContains: grep,
+		MinCount:     minCount,
+	}
+
+	if method != "" {
+		filter.Methods = []string{strings.ToUpper(method)}
+	}
+
+	if status != "" {
+		statusCodes, err := parseStatusFilter(status)
+		if err != nil {
+			return filter, err
+		}
+		filter.StatusCodes = statusCodes
+	}
+
+	if since != "" {
+		duration, err := time.ParseDuration(since)
+		if err != nil {
+			return filter, fmt.Errorf("invalid duration: %s", since)
+		}
+		filter.StartTime = time.Now().Add(-duration)
+	}
+
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return filter, fmt.Errorf("invalid header filter %q: expected name=value", header)
+		}
+		if filter.HeaderMatch == nil {
+			filter.HeaderMatch = make(map[string]string)
+		}
+		filter.HeaderMatch[strings.TrimSpace(name)] = value
+	}
+
+	return filter, nil
+}
+
+// parseStatusFilter expands comma-separated status codes and classes such as
+// 5xx into the status codes they match
+func parseStatusFilter(status string) ([]int, error) {
+	var statusCodes []int
+	for _, part := range strings.Split(status, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if len(part) == 3 && strings.HasSuffix(part, "xx") && part[0] >= '1' && part[0] <= '5' {
+			base := int(part[0]-'0') * 100
+			for code := base; code < base+100; code++ {
+				statusCodes = append(statusCodes, code)
+			}
+			continue
+		}
+
+		statusCode, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code: %s", part)
+		}
+		statusCodes = append(statusCodes, statusCode)
+	}
+	return statusCodes, nil
+}
+
 func loadConfigForRecording(configPath string) (*config.Config, error) {
 	// If no config path specified, use defaults
 	if configPath == "" {
@@ -721,6 +1007,9 @@ func displayRecordingTable(recording *recorder.Recording) error {
 	if recording.Metadata.RequestID != "" {
 		fmt.Printf("  Request ID: %s\n", recording.Metadata.RequestID)
 	}
+	if recording.Metadata.Count > 1 {
+		fmt.Printf("  Count:     %d\n", recording.Metadata.Count)
+	}
 
 	return nil
 }
@@ -737,11 +1026,6 @@ func displayRecordingYAML(recording *recorder.Recording) error {
 	return nil
 }
 
-func exportHAR(recordings []*recorder.Recording, output string) error {
-	fmt.Printf("HAR export not yet implemented\n")
-	return nil
-}
-
 func exportPostman(recordings []*recorder.Recording, output string) error {
 	fmt.Printf("Postman export not yet implemented\n")
 	return nil
@@ -751,8 +1035,3 @@ func exportCurl(recordings []*recorder.Recording, output string) error {
 	fmt.Printf("cURL export not yet implemented\n")
 	return nil
 }
-
-func exportJSON(recordings []*recorder.Recording, output string) error {
-	fmt.Printf("JSON export not yet implemented\n")
-	return nil
-}
\ No newline at end of file
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+
+	"vanta/pkg/api"
+	"vanta/pkg/config"
+	"vanta/pkg/recorder"
+)
+
+const recordTestSpec = `openapi: 3.0.0
+info:
+  title: Record Test
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: string
+                enum: [ok]
+`
+
+func startRecordTestServer(t *testing.T) *api.Server {
+	logger := zaptest.NewLogger(t)
+	specFile := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(specFile, []byte(recordTestSpec), 0644))
+	spec, err := parseOpenAPISpec(specFile, logger)
+	require.NoError(t, err)
+
+	cfg := config.DefaultConfig()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = 0
+	cfg.Metrics.Enabled = false
+	cfg.Admin = config.AdminConfig{Enabled: true, Host: "127.0.0.1", Port: 0, Token: "s3cret"}
+
+	server, err := api.NewServer(cfg, spec, logger)
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { assert.NoError(t, server.Stop()) })
+	return server
+}
+
+func runRecordCommand(t *testing.T, args ...string) error {
+	t.Helper()
+	cmd := newRecordCommand(context.Background(), zap.NewNop())
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs(args)
+	return cmd.Execute()
+}
+
+func TestRecordStart_RecordsOnRunningServer(t *testing.T) {
+	server := startRecordTestServer(t)
+	dir := t.TempDir()
+	adminFlags := []string{"--admin-url", server.AdminAddr(), "--admin-token", "s3cret"}
+
+	require.NoError(t, runRecordCommand(t, append([]string{"start", "--output", dir, "--filter", "method:GET"}, adminFlags...)...))
+	assert.True(t, server.RecordingStatus().Recording)
+
+	resp, err := (&http.Client{Timeout: 2 * time.Second}).Get("http://" + server.ListenAddrs()[0] + "/users")
+	require.NoError(t, err)
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	// Recordings are written in the background
+	var recordings []*recorder.Recording
+	assert.Eventually(t, func() bool {
+		storage, err := recorder.NewFileStorage(&config.StorageConfig{Directory: dir}, zap.NewNop())
+		if err != nil {
+			return false
+		}
+		recordings, err = storage.List(recorder.ListFilter{})
+		return err == nil && len(recordings) == 1
+	}, 2*time.Second, 20*time.Millisecond)
+	require.Len(t, recordings, 1)
+	assert.Equal(t, "/users", recordings[0].Request.URI)
+
+	require.NoError(t, runRecordCommand(t, append([]string{"stop"}, adminFlags...)...))
+	assert.False(t, server.RecordingStatus().Recording)
+}
+
+func TestRecordStart_RequiresAdminAPI(t *testing.T) {
+	err := runRecordCommand(t, "start")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "admin API is not enabled")
+}
+
+func writeExportFixtures(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	storage, err := recorder.NewFileStorage(&config.StorageConfig{Type: "file", Directory: dir, Format: "jsonlines"}, zap.NewNop())
+	require.NoError(t, err)
+
+	for i, status := range []int{200, 500, 404, 503, 201} {
+		require.NoError(t, storage.Save(&recorder.Recording{
+			ID:        fmt.Sprintf("rec-%d-%d", i, status),
+			Timestamp: time.Now().Add(-time.Duration(i) * time.Minute),
+			Request: recorder.RecordedRequest{
+				Method:  "GET",
+				URI:     fmt.Sprintf("/orders/%d?verbose=true", i),
+				Headers: map[string]string{"Host": "api.example.com"},
+			},
+			Response: recorder.RecordedResponse{
+				StatusCode:  status,
+				Body:        []byte(fmt.Sprintf(`{"status":%d}`, status)),
+				ContentType: "application/json",
+			},
+		}))
+	}
+	require.NoError(t, storage.Close())
+
+	configPath := filepath.Join(t.TempDir(), "vanta.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(fmt.Sprintf(`recording:
+  storage:
+    type: file
+    directory: %q
+    format: jsonlines
+`, dir)), 0644))
+	return configPath
+}
+
+func TestRecordExport_HARFilteredByStatusClass(t *testing.T) {
+	configPath := writeExportFixtures(t)
+	output := filepath.Join(t.TempDir(), "errors.har")
+
+	require.NoError(t, runRecordCommand(t, "export", "--config", configPath, "--format", "har", "--output", output, "--status", "5xx"))
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	var har harLog
+	require.NoError(t, json.Unmarshal(data, &har))
+	assert.Equal(t, "1.2", har.Log.Version)
+
+	var statuses []int
+	for _, entry := range har.Log.Entries {
+		statuses = append(statuses, entry.Response.Status)
+		assert.Equal(t, fmt.Sprintf(`{"status":%d}`, entry.Response.Status), entry.Response.Content.Text)
+		assert.Contains(t, entry.Request.URL, "http://api.example.com/orders/")
+		assert.Equal(t, []harNameValue{{Name: "verbose", Value: "true"}}, entry.Request.QueryString)
+	}
+	assert.ElementsMatch(t, []int{500, 503}, statuses)
+}
+
+func TestRecordExport_JSONFilteredByStatusAndLimit(t *testing.T) {
+	configPath := writeExportFixtures(t)
+	output := filepath.Join(t.TempDir(), "errors.json")
+
+	require.NoError(t, runRecordCommand(t, "export", "--config", configPath, "--output", output, "--status", "500,404"))
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	var recordings []exportedRecording
+	require.NoError(t, json.Unmarshal(data, &recordings))
+	var statuses []int
+	for _, recording := range recordings {
+		statuses = append(statuses, recording.Response.StatusCode)
+		assert.Equal(t, fmt.Sprintf(`{"status":%d}`, recording.Response.StatusCode), recording.Response.Body)
+	}
+	assert.ElementsMatch(t, []int{500, 404}, statuses)
+
+	require.NoError(t, runRecordCommand(t, "export", "--config", configPath, "--output", output, "--status", "5xx", "--limit", "1"))
+	data, err = os.ReadFile(output)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &recordings))
+	require.Len(t, recordings, 1)
+	assert.GreaterOrEqual(t, recordings[0].Response.StatusCode, 500)
+}
+
+func TestRecordExport_NDJSONStreamsOneRecordingPerLine(t *testing.T) {
+	configPath := writeExportFixtures(t)
+	output := filepath.Join(t.TempDir(), "recordings.ndjson")
+
+	require.NoError(t, runRecordCommand(t, "export", "--config", configPath, "--format", "ndjson", "--output", output))
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 5)
+	for _, line := range lines {
+		var recording exportedRecording
+		require.NoError(t, json.Unmarshal([]byte(line), &recording))
+		assert.NotEmpty(t, recording.ID)
+		assert.Equal(t, fmt.Sprintf(`{"status":%d}`, recording.Response.StatusCode), recording.Response.Body)
+		assert.Empty(t, recording.Response.BodyEncoding)
+	}
+
+	require.NoError(t, runRecordCommand(t, "export", "--config", configPath, "--format", "ndjson", "--output", output, "--status", "5xx"))
+	data, err = os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "\n"))
+}
+
+func TestExportNDJSON_EncodesBinaryBodies(t *testing.T) {
+	binary := []byte{0xff, 0xd8, 0xff, 0xe0}
+	output := filepath.Join(t.TempDir(), "binary.ndjson")
+
+	count, err := exportNDJSON(func(fn func(*recorder.Recording) error) error {
+		return fn(&recorder.Recording{
+			ID:       "rec-binary",
+			Request:  recorder.RecordedRequest{Method: "POST", URI: "/upload", Body: []byte("name=logo")},
+			Response: recorder.RecordedResponse{StatusCode: 200, Body: binary, ContentType: "image/jpeg"},
+		})
+	}, output)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &line))
+	request := line["request"].(map[string]interface{})
+	response := line["response"].(map[string]interface{})
+	assert.Equal(t, "name=logo", request["body"])
+	assert.NotContains(t, request, "body_encoding")
+	assert.Equal(t, base64.StdEncoding.EncodeToString(binary), response["body"])
+	assert.Equal(t, "base64", response["body_encoding"])
+}
+
+func TestRecordExport_RejectsIDsWithFilters(t *testing.T) {
+	err := runRecordCommand(t, "export", "--ids", "abc", "--status", "5xx")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "none of the others can be")
+
+	_, err = parseStatusFilter("5xy")
+	assert.Error(t, err)
+}
+
+func TestPageOffset(t *testing.T) {
+	offset, err := pageOffset(1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 0, offset)
+
+	offset, err = pageOffset(3, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 40, offset)
+
+	_, err = pageOffset(0, 20)
+	assert.ErrorContains(t, err, "--page must be at least 1")
+	_, err = pageOffset(2, 0)
+	assert.ErrorContains(t, err, "--page requires a positive --limit")
+}
+
+func TestPageFooter(t *testing.T) {
+	page := &recorder.RecordingPage{
+		Recordings: make([]*recorder.Recording, 20),
+		Offset:     40,
+		Total:      95,
+	}
+	assert.Equal(t, "Showing 41–60 of 95", pageFooter(page))
+
+	page = &recorder.RecordingPage{Offset: 100, Total: 95}
+	assert.Equal(t, "Showing 0 of 95", pageFooter(page))
+}
+
+func TestRecordList_RejectsInvalidPaging(t *testing.T) {
+	configPath := writeExportFixtures(t)
+
+	err := runRecordCommand(t, "list", "--config", configPath, "--offset", "5", "--page", "2")
+	assert.ErrorContains(t, err, "none of the others can be")
+
+	err = runRecordCommand(t, "list", "--config", configPath, "--offset", "-1")
+	assert.ErrorContains(t, err, "--offset cannot be negative")
+
+	err = runRecordCommand(t, "list", "--config", configPath, "--page", "0")
+	assert.ErrorContains(t, err, "--page must be at least 1")
+}
+
+func TestRecordReplay_WritesLatencyReport(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	configPath := writeExportFixtures(t)
+	dir := t.TempDir()
+
+	csvReport := filepath.Join(dir, "latency.csv")
+	require.NoError(t, runRecordCommand(t, "replay", "--config", configPath, "--target", target.URL, "--delay", "1ms", "--report", csvReport))
+
+	file, err := os.Open(csvReport)
+	require.NoError(t, err)
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 6, "header plus one row per recording")
+	assert.Equal(t, []string{"id", "method", "uri", "recorded_status", "replayed_status", "status_match", "recorded_ms", "replayed_ms", "delta_ms", "error"}, rows[0])
+
+	ids := map[string]bool{}
+	for _, row := range rows[1:] {
+		ids[row[0]] = true
+		assert.Equal(t, "200", row[4])
+		assert.Equal(t, strconv.FormatBool(row[3] == "200"), row[5])
+		replayed, err := strconv.ParseFloat(row[7], 64)
+		require.NoError(t, err)
+		assert.Positive(t, replayed)
+		_, err = strconv.ParseFloat(row[6], 64)
+		assert.NoError(t, err)
+	}
+	assert.Len(t, ids, 5)
+
+	jsonReport := filepath.Join(dir, "latency.json")
+	require.NoError(t, runRecordCommand(t, "replay", "--config", configPath, "--target", target.URL, "--delay", "1ms", "--report", jsonReport))
+
+	data, err := os.ReadFile(jsonReport)
+	require.NoError(t, err)
+	var report []replayReportRow
+	require.NoError(t, json.Unmarshal(data, &report))
+	require.Len(t, report, 5)
+	for _, row := range report {
+		assert.NotEmpty(t, row.ID)
+		assert.Positive(t, row.ReplayedMs)
+		assert.InDelta(t, row.ReplayedMs-row.RecordedMs, row.DeltaMs, 1e-9)
+	}
+}
+
+func TestWriteReplayReport_IncludesBothDurations(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, writeReplayReport([]recorder.ReplayResult{{
+		ID:               "rec-1",
+		Method:           "GET",
+		URI:              "/orders",
+		RecordedStatus:   200,
+		ReplayedStatus:   200,
+		StatusMatch:      true,
+		RecordedDuration: 40 * time.Millisecond,
+		ReplayedDuration: 55 * time.Millisecond,
+	}}, report))
+
+	data, err := os.ReadFile(report)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"id": "rec-1", "method": "GET", "uri": "/orders", "recorded_status": 200, "replayed_status": 200,
+		"status_match": true, "recorded_ms": 40, "replayed_ms": 55, "delta_ms": 15}]`, string(data))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"vanta/pkg/config"
+	"vanta/pkg/plugins"
+)
+
+// starterPluginConfig fills settings a plugin cannot start without, or does
+// nothing without, and that have no schema default. Secrets are referenced from the environment rather
+// than written to disk.
+var starterPluginConfig = map[string]map[string]interface{}{
+	"auth":       {"jwt_secret": "${VANTA_JWT_SECRET}"},
+	"cors":       {"allow_origins": []interface{}{"*"}},
+	"rate_limit": {"ip_requests_per_second": 10.0},
+}
+
+// renderStarterConfig builds a commented YAML configuration holding the
+// server, logging and metrics defaults and an enabled entry for each of the
+// named plugins, configured with its schema defaults
+func renderStarterConfig(pluginNames []string) ([]byte, error) {
+	registry := plugins.GetConfigRegistry()
+	defaults := config.DefaultConfig()
+
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	sections := []struct {
+		key     string
+		comment string
+		value   interface{}
+	}{
+		{"server", "HTTP listener settings. Durations accept Go syntax such as 30s or 1m.", defaults.Server},
+		{"logging", "level: debug, info, warn or error; format: json or console; output: stdout, stderr or a file path", defaults.Logging},
+		{"metrics", "Prometheus metrics are served on their own port", defaults.Metrics},
+	}
+	for _, section := range sections {
+		value, err := encodeSection(reflect.ValueOf(section.value))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s section: %w", section.key, err)
+		}
+		key := scalarNode(section.key)
+		key.HeadComment = section.comment
+		doc.Content = append(doc.Content, key, value)
+	}
+
+	entries := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, name := range pluginNames {
+		schema, exists := registry.GetSchema(name)
+		if !exists {
+			return nil, fmt.Errorf("unknown plugin %q (available: %s)", name, strings.Join(registry.SchemaNames(), ", "))
+		}
+
+		pluginConfig := plugins.GetDefaultConfig(name)
+		for key, value := range starterPluginConfig[name] {
+			pluginConfig[key] = value
+		}
+		configNode := &yaml.Node{}
+		if err := configNode.Encode(pluginConfig); err != nil {
+			return nil, fmt.Errorf("failed to encode %s plugin configuration: %w", name, err)
+		}
+		describeProperties(configNode, schema.Properties)
+
+		entry := &yaml.Node{Kind: yaml.MappingNode}
+		entry.Content = append(entry.Content,
+			scalarNode("name"), scalarNode(name),
+			scalarNode("enabled"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"},
+			scalarNode("config"), configNode,
+		)
+		entry.HeadComment = schema.Title
+		if schema.Description != "" {
+			entry.HeadComment += "\n" + schema.Description
+		}
+		entries.Content = append(entries.Content, entry)
+	}
+	pluginsKey := scalarNode("plugins")
+	pluginsKey.HeadComment = "Plugins run in priority order; see `mocker config schema <plugin>` for every option"
+	doc.Content = append(doc.Content, pluginsKey, entries)
+
+	var out bytes.Buffer
+	out.WriteString("# vanta configuration generated by `mocker config init`\n\n")
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// encodeSection encodes a configuration struct keyed by its yaml tags,
+// writing durations as strings and nil slices as empty lists
+func encodeSection(value reflect.Value) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		var child *yaml.Node
+		switch {
+		case field.Type == reflect.TypeOf(time.Duration(0)):
+			child = scalarNode(time.Duration(fieldValue.Int()).String())
+		case field.Type.Kind() == reflect.Struct:
+			var err error
+			if child, err = encodeSection(fieldValue); err != nil {
+				return nil, err
+			}
+		case field.Type.Kind() == reflect.Slice && fieldValue.IsNil():
+			child = &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		default:
+			child = &yaml.Node{}
+			if err := child.Encode(fieldValue.Interface()); err != nil {
+				return nil, fmt.Errorf("%s: %w", tag, err)
+			}
+		}
+		node.Content = append(node.Content, scalarNode(tag), child)
+	}
+
+	return node, nil
+}
+
+// describeProperties attaches schema descriptions to the keys of a plugin
+// configuration mapping as comments
+func describeProperties(node *yaml.Node, properties map[string]plugins.JSONSchemaProperty) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		property, exists := properties[node.Content[i].Value]
+		if !exists {
+			continue
+		}
+		node.Content[i].HeadComment = property.Description
+		if node.Content[i+1].Kind == yaml.MappingNode {
+			describeProperties(node.Content[i+1], property.Properties)
+		}
+	}
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"vanta/internal/hotreload"
 	"vanta/pkg/api"
 	"vanta/pkg/config"
 	"vanta/pkg/openapi"
@@ -15,10 +16,12 @@ import (
 
 func newStartCommand(ctx context.Context, logger *zap.Logger) *cobra.Command {
 	var (
-		specFile   string
-		port       int
-		host       string
-		configFile string
+		specFile    string
+		port        int
+		host        string
+		configFiles []string
+		selfCheck   bool
+		watchSpec   bool
 	)
 
 	cmd := &cobra.Command{
@@ -48,10 +51,14 @@ func newStartCommand(ctx context.Context, logger *zap.Logger) *cobra.Command {
 			}
 
 			// Load configuration
-			cfg, err := loadConfiguration(configFile, port, host, logger)
+			cfg, err := loadConfiguration(configFiles, port, host, logger)
 			if err != nil {
 				return fmt.Errorf("failed to load configuration: %w", err)
 			}
+			if watchSpec {
+				cfg.HotReload.Enabled = true
+				cfg.HotReload.WatchSpec = true
+			}
 
 			// Parse OpenAPI specification
 			spec, err := parseOpenAPISpec(specFile, logger)
@@ -59,6 +66,13 @@ func newStartCommand(ctx context.Context, logger *zap.Logger) *cobra.Command {
 				return fmt.Errorf("failed to parse OpenAPI spec: %w", err)
 			}
 
+			// Refuse to serve responses that would not match their own schemas
+			if selfCheck {
+				if err := runSelfCheck(cfg, spec, logger); err != nil {
+					return err
+				}
+			}
+
 			// Create and start server
 			server, err := api.NewServer(cfg, spec, logger)
 			if err != nil {
@@ -75,10 +89,25 @@ func newStartCommand(ctx context.Context, logger *zap.Logger) *cobra.Command {
 				}
 			}()
 
+			// Reload when the spec (or config) file changes
+			var reloader *hotreload.HotReloader
+			if cfg.HotReload.Enabled {
+				// Config reloads read a single file, so layered configs only reload the spec
+				configFile := ""
+				if len(configFiles) == 1 {
+					configFile = configFiles[0]
+				}
+				reloader = startHotReload(server, configFile, specFile, cfg, spec, logger)
+			}
+
 			// Wait for context cancellation or server error
 			select {
 			case <-ctx.Done():
 				logger.Info("Shutdown signal received, stopping server...")
+				// A reload must not restart the server while it shuts down
+				if reloader != nil {
+					reloader.Stop()
+				}
 				if err := server.Stop(); err != nil {
 					logger.Error("Error stopping server", zap.Error(err))
 					return err
@@ -86,6 +115,9 @@ func newStartCommand(ctx context.Context, logger *zap.Logger) *cobra.Command {
 				logger.Info("Server stopped successfully")
 				return nil
 			case err := <-serverErrCh:
+				if reloader != nil {
+					reloader.Stop()
+				}
 				return fmt.Errorf("server error: %w", err)
 			}
 		},
@@ -95,18 +127,20 @@ func newStartCommand(ctx context.Context, logger *zap.Logger) *cobra.Command {
 	cmd.Flags().StringVarP(&specFile, "spec", "s", "", "Path to OpenAPI specification file")
 	cmd.Flags().IntVarP(&port, "port", "p", 8080, "Server port")
 	cmd.Flags().StringVarP(&host, "host", "H", "0.0.0.0", "Server host")
-	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Path to configuration file")
+	cmd.Flags().StringArrayVarP(&configFiles, "config", "c", nil, "Path to configuration file; repeat to merge files, later ones overriding earlier")
+	cmd.Flags().BoolVar(&watchSpec, "watch", false, "Reload the server when the OpenAPI spec file changes")
+	cmd.Flags().BoolVar(&selfCheck, "self-check", false, "Validate a generated response for every operation against its schema before starting")
 
 	return cmd
 }
 
-func loadConfiguration(configFile string, port int, host string, logger *zap.Logger) (*config.Config, error) {
+func loadConfiguration(configFiles []string, port int, host string, logger *zap.Logger) (*config.Config, error) {
 	var cfg *config.Config
 	var err error
 
-	if configFile != "" {
-		logger.Info("Loading configuration from file", zap.String("file", configFile))
-		cfg, err = config.LoadFromFile(configFile)
+	if len(configFiles) > 0 {
+		logger.Info("Loading configuration from files", zap.Strings("files", configFiles))
+		cfg, err = config.LoadConfigs(configFiles...)
 		if err != nil {
 			return nil, err
 		}
@@ -131,6 +165,21 @@ func loadConfiguration(configFile string, port int, host string, logger *zap.Log
 	return cfg, nil
 }
 
+// startHotReload watches the spec, and the config file when one is used, and
+// restarts server with the changed file. A reloader that cannot start is
+// logged and nil is returned; the server keeps running.
+func startHotReload(server *api.Server, configFile, specFile string, cfg *config.Config, spec *openapi.Specification, logger *zap.Logger) *hotreload.HotReloader {
+	reloader, err := hotreload.NewHotReloader(server, configFile, specFile, cfg, spec, logger)
+	if err == nil {
+		err = reloader.Start()
+	}
+	if err != nil {
+		logger.Warn("Hot reload disabled", zap.Error(err))
+		return nil
+	}
+	return reloader
+}
+
 func parseOpenAPISpec(specFile string, logger *zap.Logger) (*openapi.Specification, error) {
 	logger.Info("Parsing OpenAPI specification", zap.String("file", specFile))
 
@@ -165,4 +214,40 @@ func parseOpenAPISpec(specFile string, logger *zap.Logger) (*openapi.Specificati
 	)
 
 	return spec, nil
-}
\ No newline at end of file
+}
+// runSelfCheck generates a response for every operation with the configured
+// generator settings and fails if any does not satisfy its response schema
+func runSelfCheck(cfg *config.Config, spec *openapi.Specification, logger *zap.Logger) error {
+	logger.Info("Running response self-check")
+
+	var generator *openapi.DefaultDataGenerator
+	if cfg.Mock.Seed != 0 {
+		generator = openapi.NewDefaultDataGeneratorWithSeed(cfg.Mock.Seed)
+	} else {
+		generator = openapi.NewDefaultDataGenerator()
+	}
+	if cfg.Mock.Locale != "" {
+		generator.SetLocale(cfg.Mock.Locale)
+	}
+	generator.SetPreferExamples(cfg.Mock.PreferExamples)
+	generator.SetNullableProbability(cfg.Mock.NullableProbability)
+
+	failures := openapi.SelfCheck(spec, generator)
+	for i := range failures {
+		failure := &failures[i]
+		logger.Error("Generated response does not match its schema",
+			zap.String("method", failure.Method),
+			zap.String("path", failure.Path),
+			zap.String("status_code", failure.StatusCode),
+			zap.String("media_type", failure.MediaType),
+			zap.Error(failure),
+		)
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("self-check failed: %d generated response(s) do not match their schema", len(failures))
+	}
+
+	logger.Info("Response self-check passed")
+	return nil
+}
@@ -3,6 +3,7 @@ package hotreload
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sync/atomic"
 	"time"
 
@@ -135,6 +136,11 @@ func (hr *HotReloader) Start() error {
 		}
 	}
 
+	// Response override files are part of the configuration
+	if hr.config.WatchConfig {
+		hr.watchOverrideFiles(hr.currentConfig)
+	}
+
 	// Start file watcher
 	if err := hr.watcher.Start(hr.onFileEvent); err != nil {
 		return fmt.Errorf("failed to start file watcher: %w", err)
@@ -213,6 +219,8 @@ func (hr *HotReloader) handleReloadEvent(event FileEvent) {
 		result = hr.reloadConfig()
 	case hr.isSpecFile(event.Path):
 		result = hr.reloadSpec()
+	case hr.isOverrideFile(event.Path):
+		result = hr.reloadConfig()
 	default:
 		hr.logger.Warn("Unknown file changed", zap.String("path", event.Path))
 		return
@@ -263,6 +271,9 @@ func (hr *HotReloader) reloadConfig() ReloadResult {
 	// Update current configuration
 	hr.currentConfig = newConfig
 	hr.config = &newConfig.HotReload
+	if hr.config.WatchConfig {
+		hr.watchOverrideFiles(newConfig)
+	}
 
 	result.Success = true
 	result.Duration = time.Since(start)
@@ -365,6 +376,9 @@ func (hr *HotReloader) fullReload() ReloadResult {
 	hr.currentConfig = newConfig
 	hr.currentSpec = newSpec
 	hr.config = &newConfig.HotReload
+	if hr.config.WatchConfig {
+		hr.watchOverrideFiles(newConfig)
+	}
 
 	result.Success = true
 	result.Duration = time.Since(start)
@@ -374,11 +388,40 @@ func (hr *HotReloader) fullReload() ReloadResult {
 // Helper functions
 
 func (hr *HotReloader) isConfigFile(path string) bool {
-	return path == hr.configPath
+	return hr.configPath != "" && sameFile(path, hr.configPath)
 }
 
 func (hr *HotReloader) isSpecFile(path string) bool {
-	return path == hr.specPath
+	return hr.specPath != "" && sameFile(path, hr.specPath)
+}
+
+func (hr *HotReloader) isOverrideFile(path string) bool {
+	for _, override := range hr.currentConfig.Mock.Overrides {
+		if sameFile(path, override.File) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchOverrideFiles watches the response override files of cfg so editing
+// one reloads the configuration
+func (hr *HotReloader) watchOverrideFiles(cfg *config.Config) {
+	for _, override := range cfg.Mock.Overrides {
+		if err := hr.watcher.AddPath(override.File); err != nil {
+			hr.logger.Warn("Failed to watch response override file",
+				zap.String("file", override.File),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// sameFile reports whether two paths refer to the same file once made absolute
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
 }
 
 func (hr *HotReloader) loadConfig() (*config.Config, error) {
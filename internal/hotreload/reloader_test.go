@@ -0,0 +1,163 @@
+package hotreload_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+	"vanta/internal/hotreload"
+	"vanta/pkg/api"
+	"vanta/pkg/config"
+	"vanta/pkg/openapi"
+)
+
+const watchedSpec = `
+openapi: 3.0.0
+info:
+  title: Watched API
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      responses:
+        '200':
+          description: Success
+`
+
+const watchedSpecWithOrders = watchedSpec + `  /orders:
+    get:
+      responses:
+        '200':
+          description: Success
+`
+
+func routeStatus(server *api.Server, path string) int {
+	addrs := server.ListenAddrs()
+	if len(addrs) == 0 {
+		return 0
+	}
+	resp, err := (&http.Client{Timeout: time.Second}).Get("http://" + addrs[0] + path)
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// startWatchedServer serves the spec at specPath with spec hot reload enabled
+func startWatchedServer(t *testing.T, specPath string) (*api.Server, *hotreload.HotReloader) {
+	t.Helper()
+	logger := zaptest.NewLogger(t)
+
+	cfg := config.DefaultConfig()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = 0
+	cfg.Metrics.Enabled = false
+	cfg.HotReload.Enabled = true
+	cfg.HotReload.WatchSpec = true
+	cfg.HotReload.DebounceDelay = 50 * time.Millisecond
+
+	spec, err := openapi.LoadSpecification(specPath)
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	server, err := api.NewServer(cfg, spec, logger)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { server.Stop() })
+
+	// A relative path must match the absolute paths reported by the watcher
+	relSpecPath, err := filepath.Rel(mustGetwd(t), specPath)
+	if err != nil {
+		relSpecPath = specPath
+	}
+
+	reloader, err := hotreload.NewHotReloader(server, "", relSpecPath, cfg, spec, logger)
+	if err != nil {
+		t.Fatalf("Failed to create hot reloader: %v", err)
+	}
+	if err := reloader.Start(); err != nil {
+		t.Fatalf("Failed to start hot reloader: %v", err)
+	}
+	t.Cleanup(func() { reloader.Stop() })
+
+	return server, reloader
+}
+
+func mustGetwd(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	return wd
+}
+
+func waitFor(condition func() bool) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return false
+}
+
+func TestHotReloader_SpecChangeServesNewRoutes(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(specPath, []byte(watchedSpec), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	server, reloader := startWatchedServer(t, specPath)
+	if status := routeStatus(server, "/orders"); status != http.StatusNotFound {
+		t.Fatalf("Expected /orders to be missing before reload, got %d", status)
+	}
+
+	if err := os.WriteFile(specPath, []byte(watchedSpecWithOrders), 0644); err != nil {
+		t.Fatalf("Failed to rewrite spec: %v", err)
+	}
+
+	if !waitFor(func() bool { return routeStatus(server, "/orders") == http.StatusOK }) {
+		t.Fatal("Expected /orders to be served after the spec changed")
+	}
+	if status := routeStatus(server, "/users"); status != http.StatusOK {
+		t.Errorf("Expected /users to still be served, got %d", status)
+	}
+	if reloads := reloader.GetMetrics()["success_reloads"].(int64); reloads != 1 {
+		t.Errorf("Expected one reload, got %d", reloads)
+	}
+}
+
+func TestHotReloader_InvalidSpecKeepsServing(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(specPath, []byte(watchedSpec), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	server, reloader := startWatchedServer(t, specPath)
+	addrs := server.ListenAddrs()
+
+	if err := os.WriteFile(specPath, []byte("openapi: [not valid"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite spec: %v", err)
+	}
+
+	if !waitFor(func() bool { return reloader.GetMetrics()["failed_reloads"].(int64) == 1 }) {
+		t.Fatal("Expected the invalid spec to fail to reload")
+	}
+	if got := server.ListenAddrs(); len(got) != len(addrs) || got[0] != addrs[0] {
+		t.Errorf("Expected the server not to restart, listening on %v then %v", addrs, got)
+	}
+	if status := routeStatus(server, "/users"); status != http.StatusOK {
+		t.Errorf("Expected the old spec to keep being served, got %d", status)
+	}
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"vanta/pkg/plugins"
+)
+
+// adminTokenHeader is an alternative to "Authorization: Bearer <token>"
+const adminTokenHeader = "X-Admin-Token"
+
+// startAdmin binds the admin API listener when it is enabled. Callers hold s.mu.
+func (s *Server) startAdmin() error {
+	adminCfg := &s.fullConfig.Admin
+	if !adminCfg.Enabled {
+		return nil
+	}
+
+	addr := net.JoinHostPort(adminCfg.Host, fmt.Sprintf("%d", adminCfg.Port))
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on admin address %s: %w", addr, err)
+	}
+
+	s.adminServer = &fasthttp.Server{
+		Handler:      s.adminHandler(adminCfg.Token),
+		ReadTimeout:  s.config.ReadTimeout,
+		WriteTimeout: s.config.WriteTimeout,
+	}
+	s.adminAddr = ln.Addr().String()
+
+	s.logger.Info("Starting admin API listener", zap.String("address", s.adminAddr))
+
+	go func(server *fasthttp.Server) {
+		if err := server.Serve(ln); err != nil {
+			s.logger.Error("Admin API stopped with error", zap.Error(err))
+		}
+	}(s.adminServer)
+	return nil
+}
+
+// stopAdmin closes the admin API listener if it is running
+func (s *Server) stopAdmin() {
+	s.mu.Lock()
+	adminServer := s.adminServer
+	s.adminServer = nil
+	s.adminAddr = ""
+	s.mu.Unlock()
+
+	if adminServer == nil {
+		return
+	}
+	if err := adminServer.Shutdown(); err != nil {
+		s.logger.Warn("Failed to shutdown admin API", zap.Error(err))
+	}
+}
+
+// AdminAddr returns the address bound by the admin API, or "" when it is not running
+func (s *Server) AdminAddr() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.adminAddr
+}
+
+// adminHandler serves the plugin and recording management endpoints:
+//
+//	GET  /plugins
+//	GET  /plugins/latency         (per-plugin pre/post-process and handler latency)
+//	POST /plugins/{name}/enable
+//	POST /plugins/{name}/disable
+//	POST /plugins/{name}/reload   (plugin configuration as the JSON body)
+//	GET  /recording
+//	POST /recording/start         (RecordingStartRequest as the JSON body)
+//	POST /recording/stop
+func (s *Server) adminHandler(token string) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !validAdminToken(ctx, token) {
+			writeAdminError(ctx, fasthttp.StatusUnauthorized, "missing or invalid admin token")
+			return
+		}
+
+		segments := strings.Split(strings.Trim(string(ctx.Path()), "/"), "/")
+		if segments[0] == "recording" {
+			s.handleAdminRecording(ctx, segments[1:])
+			return
+		}
+		if segments[0] == "plugins" && len(segments) == 2 && segments[1] == "latency" {
+			if !ctx.IsGet() {
+				writeAdminError(ctx, fasthttp.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			writeAdminJSON(ctx, fasthttp.StatusOK, s.GetPluginsManager().GetLatencyBreakdown())
+			return
+		}
+		if segments[0] != "plugins" || len(segments) > 3 || len(segments) == 2 {
+			writeAdminError(ctx, fasthttp.StatusNotFound, "not found")
+			return
+		}
+
+		if len(segments) == 1 {
+			if !ctx.IsGet() {
+				writeAdminError(ctx, fasthttp.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+			writeAdminJSON(ctx, fasthttp.StatusOK, map[string]interface{}{"plugins": s.GetPluginStats()})
+			return
+		}
+
+		if !ctx.IsPost() {
+			writeAdminError(ctx, fasthttp.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		name, action := segments[1], segments[2]
+		var err error
+		switch action {
+		case "enable":
+			err = s.EnablePlugin(name)
+		case "disable":
+			err = s.DisablePlugin(name)
+		case "reload":
+			var pluginConfig map[string]interface{}
+			pluginConfig, err = s.adminReloadConfig(ctx, name)
+			if err != nil {
+				writeAdminError(ctx, fasthttp.StatusBadRequest, err.Error())
+				return
+			}
+			err = s.ReloadPlugin(name, pluginConfig)
+		default:
+			writeAdminError(ctx, fasthttp.StatusNotFound, "not found")
+			return
+		}
+
+		if err != nil {
+			status := fasthttp.StatusConflict
+			if errors.Is(err, plugins.ErrPluginNotFound) {
+				status = fasthttp.StatusNotFound
+			}
+			writeAdminError(ctx, status, err.Error())
+			return
+		}
+
+		s.logger.Info("Admin API plugin action",
+			zap.String("plugin", name),
+			zap.String("action", action),
+		)
+		writeAdminJSON(ctx, fasthttp.StatusOK, s.pluginInfo(name))
+	}
+}
+
+// adminReloadConfig decodes a reload request body. An empty body reloads the
+// plugin with its current configuration.
+func (s *Server) adminReloadConfig(ctx *fasthttp.RequestCtx, name string) (map[string]interface{}, error) {
+	body := ctx.PostBody()
+	if len(strings.TrimSpace(string(body))) == 0 {
+		if info := s.pluginInfo(name); info != nil && info.Config != nil {
+			return info.Config, nil
+		}
+		return map[string]interface{}{}, nil
+	}
+
+	var pluginConfig map[string]interface{}
+	if err := json.Unmarshal(body, &pluginConfig); err != nil {
+		return nil, fmt.Errorf("plugin configuration must be a JSON object: %w", err)
+	}
+	return pluginConfig, nil
+}
+
+// handleAdminRecording starts, stops and reports recording on the running server
+func (s *Server) handleAdminRecording(ctx *fasthttp.RequestCtx, segments []string) {
+	switch {
+	case len(segments) == 0:
+		if !ctx.IsGet() {
+			writeAdminError(ctx, fasthttp.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+	case len(segments) == 1 && (segments[0] == "start" || segments[0] == "stop"):
+		if !ctx.IsPost() {
+			writeAdminError(ctx, fasthttp.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		var err error
+		if segments[0] == "start" {
+			var req RecordingStartRequest
+			if body := ctx.PostBody(); len(strings.TrimSpace(string(body))) > 0 {
+				if err := json.Unmarshal(body, &req); err != nil {
+					writeAdminError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("recording request must be a JSON object: %v", err))
+					return
+				}
+			}
+			err = s.StartRecording(req)
+		} else {
+			err = s.StopRecording()
+		}
+		if err != nil {
+			writeAdminError(ctx, fasthttp.StatusConflict, err.Error())
+			return
+		}
+		s.logger.Info("Admin API recording action", zap.String("action", segments[0]))
+	default:
+		writeAdminError(ctx, fasthttp.StatusNotFound, "not found")
+		return
+	}
+
+	writeAdminJSON(ctx, fasthttp.StatusOK, s.RecordingStatus())
+}
+
+// pluginInfo returns the current state of a single plugin, or nil if unknown
+func (s *Server) pluginInfo(name string) *plugins.PluginInfo {
+	for _, info := range s.GetPluginStats() {
+		if info.Name == name {
+			return &info
+		}
+	}
+	return nil
+}
+
+// validAdminToken accepts the token as a bearer token or in X-Admin-Token
+func validAdminToken(ctx *fasthttp.RequestCtx, token string) bool {
+	provided := string(ctx.Request.Header.Peek(adminTokenHeader))
+	if auth := string(ctx.Request.Header.Peek("Authorization")); strings.HasPrefix(auth, "Bearer ") {
+		provided = strings.TrimPrefix(auth, "Bearer ")
+	}
+	return token != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
+
+func writeAdminJSON(ctx *fasthttp.RequestCtx, status int, body interface{}) {
+	responseBytes, err := json.Marshal(body)
+	if err != nil {
+		writeAdminError(ctx, fasthttp.StatusInternalServerError, err.Error())
+		return
+	}
+	ctx.SetContentType("application/json")
+	ctx.SetStatusCode(status)
+	ctx.SetBody(responseBytes)
+}
+
+func writeAdminError(ctx *fasthttp.RequestCtx, status int, message string) {
+	writeAdminJSON(ctx, status, map[string]string{"error": message})
+}
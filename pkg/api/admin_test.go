@@ -0,0 +1,225 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"vanta/pkg/config"
+	"vanta/pkg/plugins"
+	"vanta/pkg/recorder"
+)
+
+const testAdminToken = "s3cret"
+
+func startAdminTestServer(t *testing.T) *Server {
+	cfg := newTestServerConfig()
+	cfg.Admin = config.AdminConfig{Enabled: true, Host: "127.0.0.1", Port: 0, Token: testAdminToken}
+	cfg.Plugins = []config.PluginConfig{{
+		Name:    "rate_limit",
+		Enabled: false,
+		Config:  map[string]interface{}{"ip_requests_per_second": 100.0},
+	}}
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { assert.NoError(t, server.Stop()) })
+	require.NotEmpty(t, server.AdminAddr())
+	return server
+}
+
+func adminRequest(t *testing.T, server *Server, method, path, body string) (int, map[string]interface{}) {
+	req, err := http.NewRequest(method, "http://"+server.AdminAddr()+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+
+	resp, err := (&http.Client{Timeout: 2 * time.Second}).Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	return resp.StatusCode, decoded
+}
+
+func pluginState(t *testing.T, server *Server, name string) plugins.PluginState {
+	for _, info := range server.GetPluginsManager().ListPlugins() {
+		if info.Name == name {
+			return info.State
+		}
+	}
+	t.Fatalf("plugin %s not loaded", name)
+	return ""
+}
+
+func rateLimitHeader(t *testing.T, server *Server) string {
+	resp, err := (&http.Client{Timeout: 2 * time.Second}).Get("http://" + server.ListenAddrs()[0] + "/users")
+	require.NoError(t, err)
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.Header.Get("X-RateLimit-Limit")
+}
+
+func TestAdminAPI_PluginLifecycle(t *testing.T) {
+	server := startAdminTestServer(t)
+
+	status, body := adminRequest(t, server, "GET", "/plugins", "")
+	require.Equal(t, http.StatusOK, status)
+	listed := body["plugins"].([]interface{})
+	require.Len(t, listed, 1)
+	assert.Equal(t, "rate_limit", listed[0].(map[string]interface{})["name"])
+	assert.Equal(t, "loaded", listed[0].(map[string]interface{})["state"])
+	assert.Empty(t, rateLimitHeader(t, server))
+
+	status, body = adminRequest(t, server, "POST", "/plugins/rate_limit/enable", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "enabled", body["state"])
+	assert.Equal(t, plugins.StateEnabled, pluginState(t, server, "rate_limit"))
+	assert.Equal(t, "100", rateLimitHeader(t, server))
+
+	status, body = adminRequest(t, server, "POST", "/plugins/rate_limit/reload", `{"ip_requests_per_second": 50, "ip_burst": 7}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "enabled", body["state"])
+	assert.Equal(t, 7.0, body["config"].(map[string]interface{})["ip_burst"])
+	assert.Equal(t, plugins.StateEnabled, pluginState(t, server, "rate_limit"))
+	assert.Equal(t, "7", rateLimitHeader(t, server))
+
+	status, body = adminRequest(t, server, "POST", "/plugins/rate_limit/disable", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "disabled", body["state"])
+	assert.Equal(t, plugins.StateDisabled, pluginState(t, server, "rate_limit"))
+	assert.Empty(t, rateLimitHeader(t, server))
+}
+
+func TestAdminAPI_PluginLatency(t *testing.T) {
+	server := startAdminTestServer(t)
+
+	status, _ := adminRequest(t, server, "POST", "/plugins/rate_limit/enable", "")
+	require.Equal(t, http.StatusOK, status)
+	for i := 0; i < 3; i++ {
+		rateLimitHeader(t, server)
+	}
+
+	req, err := http.NewRequest("GET", "http://"+server.AdminAddr()+"/plugins/latency", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	resp, err := (&http.Client{Timeout: 2 * time.Second}).Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var breakdown plugins.LatencyBreakdown
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&breakdown))
+
+	require.Len(t, breakdown.Plugins, 1)
+	plugin := breakdown.Plugins[0]
+	assert.Equal(t, "rate_limit", plugin.Name)
+	assert.Equal(t, int64(3), plugin.PreProcess.Count)
+	assert.Equal(t, int64(3), plugin.PostProcess.Count)
+	assert.Greater(t, plugin.PreProcess.AverageLatency, time.Duration(0))
+
+	assert.Equal(t, int64(3), breakdown.Handler.Count)
+	assert.Greater(t, breakdown.Handler.AverageLatency, time.Duration(0))
+
+	// The mock router no longer serves it
+	resp, err = (&http.Client{Timeout: 2 * time.Second}).Get("http://" + server.ListenAddrs()[0] + "/__plugins/latency")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	status, _ = adminRequest(t, server, "POST", "/plugins/latency", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+}
+
+func TestAdminAPI_Errors(t *testing.T) {
+	server := startAdminTestServer(t)
+
+	status, _ := adminRequest(t, server, "POST", "/plugins/missing/enable", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = adminRequest(t, server, "POST", "/plugins/rate_limit/reload", `not json`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = adminRequest(t, server, "GET", "/plugins/rate_limit/enable", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+
+	status, _ = adminRequest(t, server, "POST", "/plugins/rate_limit/explode", "")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestAdminAPI_RequiresToken(t *testing.T) {
+	server := startAdminTestServer(t)
+
+	for _, token := range []string{"", "wrong"} {
+		req, err := http.NewRequest("GET", "http://"+server.AdminAddr()+"/plugins", nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set(adminTokenHeader, token)
+		}
+
+		resp, err := (&http.Client{Timeout: 2 * time.Second}).Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "token %q", token)
+	}
+
+	req, err := http.NewRequest("GET", "http://"+server.AdminAddr()+"/plugins", nil)
+	require.NoError(t, err)
+	req.Header.Set(adminTokenHeader, testAdminToken)
+	resp, err := (&http.Client{Timeout: 2 * time.Second}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestAdminAPI_DisabledByDefault(t *testing.T) {
+	server, err := NewServer(newTestServerConfig(), createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+
+	assert.Empty(t, server.AdminAddr())
+}
+
+func TestAdminAPI_RecordingLifecycle(t *testing.T) {
+	server := startAdminTestServer(t)
+	dir := t.TempDir()
+
+	status, body := adminRequest(t, server, "GET", "/recording", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, false, body["recording"])
+
+	status, _ = adminRequest(t, server, "POST", "/recording/stop", "")
+	assert.Equal(t, http.StatusConflict, status)
+
+	status, body = adminRequest(t, server, "POST", "/recording/start",
+		`{"directory":"`+dir+`","max_recordings":5,"filters":[{"type":"method","values":["GET"]}]}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, true, body["recording"])
+	assert.Equal(t, dir, body["directory"])
+	assert.Equal(t, 5.0, body["max_recordings"])
+	assert.Equal(t, 1.0, body["filters"])
+
+	rateLimitHeader(t, server)
+	assert.Eventually(t, func() bool {
+		recordings, err := server.GetRecordingEngine().GetStorage().List(recorder.ListFilter{})
+		return err == nil && len(recordings) == 1
+	}, 2*time.Second, 20*time.Millisecond)
+
+	status, body = adminRequest(t, server, "POST", "/recording/stop", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, false, body["recording"])
+
+	status, _ = adminRequest(t, server, "POST", "/recording/start", `not json`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = adminRequest(t, server, "GET", "/recording/start", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+}
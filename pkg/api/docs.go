@@ -0,0 +1,106 @@
+package api
+
+import (
+	"embed"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// Paths served when mock.serve_docs is enabled
+const (
+	specJSONPath  = "/openapi.json"
+	specYAMLPath  = "/openapi.yaml"
+	docsPath      = "/docs"
+	docsAssetsDir = "/docs/"
+)
+
+// swaggerUIAssets holds the Swagger UI files served under /docs/, so the page
+// works without reaching a CDN
+//
+//go:embed swaggerui/swagger-ui.css swaggerui/swagger-ui-bundle.js
+var swaggerUIAssets embed.FS
+
+// swaggerUIContentTypes maps the embedded asset names to their content types
+var swaggerUIContentTypes = map[string]string{
+	"swagger-ui.css":       "text/css; charset=utf-8",
+	"swagger-ui-bundle.js": "application/javascript; charset=utf-8",
+}
+
+// swaggerUIPage loads the embedded Swagger UI and points it at the JSON spec.
+// Arguments: page title, spec URL.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>%s</title>
+  <link rel="stylesheet" href="/docs/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="/docs/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({ url: "%s", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`
+
+// isDocsPath reports whether path is one of the documentation endpoints
+func isDocsPath(path string) bool {
+	switch path {
+	case specJSONPath, specYAMLPath, docsPath:
+		return true
+	}
+	_, ok := swaggerUIContentTypes[strings.TrimPrefix(path, docsAssetsDir)]
+	return ok && strings.HasPrefix(path, docsAssetsDir)
+}
+
+// handleDocs serves the loaded specification as an OpenAPI document, or the
+// Swagger UI page that renders it and its assets
+func (s *Server) handleDocs(ctx *fasthttp.RequestCtx, path string) {
+	if strings.HasPrefix(path, docsAssetsDir) {
+		name := strings.TrimPrefix(path, docsAssetsDir)
+		body, err := swaggerUIAssets.ReadFile("swaggerui/" + name)
+		if err != nil {
+			ctx.SetStatusCode(fasthttp.StatusNotFound)
+			return
+		}
+		ctx.SetContentType(swaggerUIContentTypes[name])
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		ctx.SetBody(body)
+		return
+	}
+
+	s.mu.RLock()
+	spec := s.spec
+	s.mu.RUnlock()
+
+	if path == docsPath {
+		ctx.SetContentType("text/html; charset=utf-8")
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		ctx.SetBodyString(fmt.Sprintf(swaggerUIPage, html.EscapeString(spec.Info.Title), specJSONPath))
+		return
+	}
+
+	format, contentType := "json", "application/json"
+	if path == specYAMLPath {
+		format, contentType = "yaml", "application/yaml"
+	}
+
+	body, err := spec.MarshalDocument(format)
+	if err != nil {
+		s.logger.Error("Failed to encode specification", zap.Error(err))
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		return
+	}
+
+	ctx.SetContentType(contentType)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(body)
+}
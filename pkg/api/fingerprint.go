@@ -0,0 +1,66 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// RequestFingerprintKey is the user value key holding the request fingerprint
+const RequestFingerprintKey = "request_fingerprint"
+
+// RequestFingerprintHeader is the response header exposing the request fingerprint
+const RequestFingerprintHeader = "X-Request-Fingerprint"
+
+// fingerprintHeaders are the request headers that change the mock response and
+// therefore take part in the fingerprint
+var fingerprintHeaders = []string{"Accept", "Content-Type", "Prefer"}
+
+// RequestFingerprint returns a stable hash of the request's method, path, sorted
+// query and relevant headers. It is computed once and cached on the request context.
+func RequestFingerprint(ctx *fasthttp.RequestCtx) string {
+	if fingerprint, ok := ctx.UserValue(RequestFingerprintKey).(string); ok && fingerprint != "" {
+		return fingerprint
+	}
+
+	fingerprint := computeFingerprint(ctx)
+	ctx.SetUserValue(RequestFingerprintKey, fingerprint)
+	return fingerprint
+}
+
+// computeFingerprint hashes the parts of the request that identify it
+func computeFingerprint(ctx *fasthttp.RequestCtx) string {
+	query := make([]string, 0, ctx.QueryArgs().Len())
+	ctx.QueryArgs().VisitAll(func(key, value []byte) {
+		query = append(query, string(key)+"="+string(value))
+	})
+	sort.Strings(query)
+
+	hash := sha256.New()
+	hash.Write(ctx.Method())
+	hash.Write([]byte{'\n'})
+	hash.Write(ctx.Path())
+	hash.Write([]byte{'\n'})
+	hash.Write([]byte(strings.Join(query, "&")))
+	for _, name := range fingerprintHeaders {
+		hash.Write([]byte{'\n'})
+		hash.Write([]byte(name + ":"))
+		hash.Write(ctx.Request.Header.Peek(name))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// Fingerprint middleware computes the request fingerprint and exposes it
+// in the X-Request-Fingerprint response header
+func Fingerprint() MiddlewareFunc {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			ctx.Response.Header.Set(RequestFingerprintHeader, RequestFingerprint(ctx))
+			next(ctx)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func newFingerprintCtx(method, uri string, headers map[string]string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+	for name, value := range headers {
+		ctx.Request.Header.Set(name, value)
+	}
+	return ctx
+}
+
+func TestRequestFingerprint_IdenticalRequestsMatch(t *testing.T) {
+	a := newFingerprintCtx("GET", "/users?limit=10&page=2", map[string]string{"Accept": "application/json"})
+	b := newFingerprintCtx("GET", "/users?page=2&limit=10", map[string]string{"Accept": "application/json", "User-Agent": "other"})
+
+	assert.Equal(t, RequestFingerprint(a), RequestFingerprint(b), "query order and irrelevant headers must not change the fingerprint")
+	assert.Len(t, RequestFingerprint(a), 16)
+}
+
+func TestRequestFingerprint_DifferentRequestsDiffer(t *testing.T) {
+	base := RequestFingerprint(newFingerprintCtx("GET", "/users?limit=10", nil))
+
+	variants := map[string]*fasthttp.RequestCtx{
+		"method": newFingerprintCtx("POST", "/users?limit=10", nil),
+		"path":   newFingerprintCtx("GET", "/accounts?limit=10", nil),
+		"query":  newFingerprintCtx("GET", "/users?limit=20", nil),
+		"accept": newFingerprintCtx("GET", "/users?limit=10", map[string]string{"Accept": "application/xml"}),
+		"prefer": newFingerprintCtx("GET", "/users?limit=10", map[string]string{"Prefer": "example=admin"}),
+	}
+
+	for name, ctx := range variants {
+		t.Run(name, func(t *testing.T) {
+			assert.NotEqual(t, base, RequestFingerprint(ctx))
+		})
+	}
+}
+
+func TestRequestFingerprint_ComputedOncePerRequest(t *testing.T) {
+	ctx := newFingerprintCtx("GET", "/users", nil)
+	first := RequestFingerprint(ctx)
+
+	// Later changes to the request do not alter the cached fingerprint
+	ctx.Request.SetRequestURI("/accounts")
+	assert.Equal(t, first, RequestFingerprint(ctx))
+}
+
+func TestFingerprintMiddleware(t *testing.T) {
+	var seen string
+	handler := NewStack(Fingerprint()).Apply(func(ctx *fasthttp.RequestCtx) {
+		seen, _ = ctx.UserValue(RequestFingerprintKey).(string)
+	})
+
+	ctx := newFingerprintCtx("GET", "/users?b=2&a=1", nil)
+	handler(ctx)
+
+	require.NotEmpty(t, seen)
+	assert.Equal(t, seen, string(ctx.Response.Header.Peek(RequestFingerprintHeader)))
+	assert.Equal(t, seen, RequestFingerprint(newFingerprintCtx("GET", "/users?a=1&b=2", nil)))
+}
@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 	"vanta/pkg/openapi"
 )
 
+// GenerationDurationKey is the user value key holding how long mock data generation took
+const GenerationDurationKey = "generation_duration"
+
 // MockHandler handles requests by generating mock responses based on OpenAPI specification
 func MockHandler(spec *openapi.Specification, generator openapi.DataGenerator, logger *zap.Logger) HandlerFunc {
 	return func(ctx *fasthttp.RequestCtx) error {
@@ -33,12 +37,21 @@ func MockHandler(spec *openapi.Specification, generator openapi.DataGenerator, l
 			zap.Any("path_params", pathParams),
 		)
 		
-		// Determine appropriate response status code
+		// Determine appropriate response status code, honoring a code the client asked for
 		responseCode := determineResponseCode(endpoint)
+		requestedCode, requested := requestedResponseCode(ctx, endpoint)
+		if requested {
+			responseCode = requestedCode
+		}
 		
-		// Get response schema for the status code
-		responseSchema, mediaType := getResponseSchema(endpoint, responseCode)
-		if responseSchema == nil {
+		// Get response media type for the status code
+		responseMedia, mediaType := getResponseMedia(endpoint, responseCode)
+		if responseMedia == nil {
+			if requested {
+				// The requested response has no body to generate
+				setResponseHeaders(ctx, responseCode, "")
+				return nil
+			}
 			return handleNoResponseSchema(ctx, responseCode, logger)
 		}
 		
@@ -47,6 +60,12 @@ func MockHandler(spec *openapi.Specification, generator openapi.DataGenerator, l
 			zap.String("media_type", mediaType),
 		)
 		
+		// Identical requests generate identical data in per-request mode
+		requestGenerator := generator
+		if defaultGen, ok := generator.(*openapi.DefaultDataGenerator); ok && defaultGen.DeterministicPerRequest() {
+			requestGenerator = defaultGen.WithSeed(defaultGen.RequestSeed(RequestFingerprint(ctx)))
+		}
+		
 		// Create generation context
 		genCtx := &openapi.GenerationContext{
 			MaxDepth:     5,
@@ -54,12 +73,15 @@ func MockHandler(spec *openapi.Specification, generator openapi.DataGenerator, l
 			Visited:      make(map[string]bool),
 			ArraySizes:   make(map[string]int),
 			Locale:       "en",
-			Seed:         generator.(*openapi.DefaultDataGenerator).GetSeed(),
+			Seed:         requestGenerator.(*openapi.DefaultDataGenerator).GetSeed(),
 			Timestamp:    ctx.Time(),
+			Schemas:      spec.Schemas,
 		}
 		
 		// Generate mock data
-		mockData, err := generator.Generate(responseSchema, genCtx)
+		generationStart := time.Now()
+		mockData, err := requestGenerator.GenerateForMediaType(responseMedia, preferredExampleName(ctx), genCtx)
+		ctx.SetUserValue(GenerationDurationKey, time.Since(generationStart))
 		if err != nil {
 			logger.Error("Failed to generate mock data", zap.Error(err))
 			return handleGenerationError(ctx, err, logger)
@@ -97,7 +119,7 @@ func findMatchingEndpoint(spec *openapi.Specification, method, path string) (*op
 // getOperationFromPathItem gets the operation for a specific HTTP method
 func getOperationFromPathItem(pathItem openapi.PathItem, method string) *openapi.Operation {
 	switch strings.ToUpper(method) {
-	case "GET":
+	case "GET", "HEAD":
 		return pathItem.GET
 	case "POST":
 		return pathItem.POST
@@ -166,8 +188,9 @@ func determineResponseCode(operation *openapi.Operation) string {
 	return "200"
 }
 
-// getResponseSchema extracts the response schema for a given status code
-func getResponseSchema(operation *openapi.Operation, statusCode string) (*openapi.Schema, string) {
+// getResponseMedia extracts the response media type for a given status code.
+// Media types without a schema are used only when they carry examples.
+func getResponseMedia(operation *openapi.Operation, statusCode string) (*openapi.MediaTypeObject, string) {
 	if operation == nil || operation.Responses == nil {
 		return nil, ""
 	}
@@ -178,20 +201,70 @@ func getResponseSchema(operation *openapi.Operation, statusCode string) (*openap
 	}
 	
 	// Look for JSON content first
-	if mediaObj, exists := response.Content["application/json"]; exists && mediaObj.Schema != nil {
-		return mediaObj.Schema, "application/json"
+	if mediaObj, exists := response.Content["application/json"]; exists && hasResponseContent(mediaObj) {
+		return &mediaObj, "application/json"
 	}
 	
 	// Fall back to any available content type
 	for mediaType, mediaObj := range response.Content {
-		if mediaObj.Schema != nil {
-			return mediaObj.Schema, mediaType
+		if hasResponseContent(mediaObj) {
+			return &mediaObj, mediaType
 		}
 	}
 	
 	return nil, ""
 }
 
+// hasResponseContent reports whether a media type has a schema or examples to serve
+func hasResponseContent(mediaObj openapi.MediaTypeObject) bool {
+	return mediaObj.Schema != nil || mediaObj.Example != nil || len(mediaObj.Examples) > 0
+}
+
+// preferredExampleName returns the named example requested via the
+// "Prefer: example=<name>" or "X-Mock-Example" header, or the "__example"
+// query parameter
+func preferredExampleName(ctx *fasthttp.RequestCtx) string {
+	if name := preference(ctx, "example"); name != "" {
+		return name
+	}
+	if name := strings.TrimSpace(string(ctx.Request.Header.Peek("X-Mock-Example"))); name != "" {
+		return name
+	}
+	return string(ctx.QueryArgs().Peek("__example"))
+}
+
+// preference returns the value of a "Prefer: <key>=<value>" preference, or "" if absent
+func preference(ctx *fasthttp.RequestCtx, key string) string {
+	for _, pref := range strings.Split(string(ctx.Request.Header.Peek("Prefer")), ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(pref), "=")
+		if found && strings.EqualFold(strings.TrimSpace(name), key) {
+			return strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return ""
+}
+
+// requestedResponseCode returns the status code a client forced via the
+// "Prefer: code=<status>" or "X-Mock-Status" header, if the operation defines it
+func requestedResponseCode(ctx *fasthttp.RequestCtx, operation *openapi.Operation) (string, bool) {
+	if operation == nil {
+		return "", false
+	}
+
+	code := preference(ctx, "code")
+	if code == "" {
+		code = strings.TrimSpace(string(ctx.Request.Header.Peek("X-Mock-Status")))
+	}
+	if code == "" {
+		return "", false
+	}
+
+	if _, exists := operation.Responses[code]; !exists {
+		return "", false
+	}
+	return code, true
+}
+
 // setResponseHeaders sets appropriate response headers
 func setResponseHeaders(ctx *fasthttp.RequestCtx, statusCode, mediaType string) {
 	// Set status code
@@ -211,11 +284,6 @@ func setResponseHeaders(ctx *fasthttp.RequestCtx, statusCode, mediaType string)
 	// Add mock-specific headers
 	ctx.Response.Header.Set("X-Mock-Response", "true")
 	ctx.Response.Header.Set("X-Mock-Generator", "vanta")
-	
-	// Add CORS headers for browser compatibility
-	ctx.Response.Header.Set("Access-Control-Allow-Origin", "*")
-	ctx.Response.Header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH, OPTIONS")
-	ctx.Response.Header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
 }
 
 // sendMockResponse serializes and sends the mock response
@@ -225,7 +293,7 @@ func sendMockResponse(ctx *fasthttp.RequestCtx, mockData interface{}, logger *za
 		return nil
 	}
 	
-	responseBytes, err := json.Marshal(mockData)
+	responseBytes, err := marshalResponse(ctx, mockData)
 	if err != nil {
 		logger.Error("Failed to marshal mock response", zap.Error(err))
 		return fmt.Errorf("failed to marshal response: %w", err)
@@ -305,13 +373,10 @@ func handleGenerationError(ctx *fasthttp.RequestCtx, err error, logger *zap.Logg
 	return nil
 }
 
-// OptionsHandler handles CORS preflight requests
+// OptionsHandler answers OPTIONS requests with 204. CORS headers are left to
+// the CORS middleware or plugin, which know the allowed origins.
 func OptionsHandler() HandlerFunc {
 	return func(ctx *fasthttp.RequestCtx) error {
-		ctx.Response.Header.Set("Access-Control-Allow-Origin", "*")
-		ctx.Response.Header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH, OPTIONS")
-		ctx.Response.Header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-		ctx.Response.Header.Set("Access-Control-Max-Age", "86400")
 		ctx.SetStatusCode(fasthttp.StatusNoContent)
 		return nil
 	}
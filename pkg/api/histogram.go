@@ -0,0 +1,101 @@
+package api
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// histogramMinDuration is the upper bound of the first bucket
+	histogramMinDuration = time.Microsecond
+	// histogramGrowth is the ratio between consecutive bucket bounds, which
+	// bounds the relative error of a reported percentile to 5%
+	histogramGrowth = 1.05
+	// histogramBuckets covers 1µs up to roughly 10 minutes; slower
+	// observations land in the last bucket
+	histogramBuckets = 420
+)
+
+// durationHistogram counts durations in fixed, exponentially sized buckets so
+// its memory stays constant no matter how many observations it receives. It
+// is not safe for concurrent use; its owner guards it with its own lock.
+type durationHistogram struct {
+	counts [histogramBuckets]uint64
+	count  uint64
+	sum    time.Duration
+	min    time.Duration
+	max    time.Duration
+}
+
+// observe records a single duration
+func (h *durationHistogram) observe(d time.Duration) {
+	if h.count == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.counts[histogramBucket(d)]++
+	h.count++
+	h.sum += d
+}
+
+// countAtMost returns how many observations fall in buckets whose upper
+// bound does not exceed limit
+func (h *durationHistogram) countAtMost(limit time.Duration) uint64 {
+	var total uint64
+	for i := 0; i < histogramBuckets-1 && histogramUpperBound(i) <= limit; i++ {
+		total += h.counts[i]
+	}
+	return total
+}
+
+// percentile returns an upper estimate of the q-th quantile (0 < q <= 1),
+// clamped to the smallest and largest observed durations
+func (h *durationHistogram) percentile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(q * float64(h.count)))
+	if rank == 0 {
+		rank = 1
+	}
+
+	var seen uint64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			if i == histogramBuckets-1 {
+				// The overflow bucket has no upper bound
+				return h.max
+			}
+			estimate := histogramUpperBound(i)
+			if estimate < h.min {
+				return h.min
+			}
+			if estimate > h.max {
+				return h.max
+			}
+			return estimate
+		}
+	}
+	return h.max
+}
+
+// histogramBucket returns the index of the bucket d falls into
+func histogramBucket(d time.Duration) int {
+	if d <= histogramMinDuration {
+		return 0
+	}
+	i := int(math.Ceil(math.Log(float64(d)/float64(histogramMinDuration)) / math.Log(histogramGrowth)))
+	if i >= histogramBuckets {
+		return histogramBuckets - 1
+	}
+	return i
+}
+
+// histogramUpperBound returns the largest duration bucket i holds
+func histogramUpperBound(i int) time.Duration {
+	return time.Duration(float64(histogramMinDuration) * math.Pow(histogramGrowth, float64(i)))
+}
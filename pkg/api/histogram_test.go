@@ -0,0 +1,74 @@
+package api
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationHistogram_Percentiles(t *testing.T) {
+	collector := NewDefaultMetricsCollector()
+
+	// 1ms..1000ms, each once: the exact p50/p95/p99 are 500/950/990ms
+	for i := 1; i <= 1000; i++ {
+		collector.ObserveLatency("GET", "/users", time.Duration(i)*time.Millisecond)
+	}
+
+	p50, p95, p99 := collector.GetPercentiles("GET_/users")
+	assert.InEpsilon(t, float64(500*time.Millisecond), float64(p50), 0.05)
+	assert.InEpsilon(t, float64(950*time.Millisecond), float64(p95), 0.05)
+	assert.InEpsilon(t, float64(990*time.Millisecond), float64(p99), 0.05)
+	assert.LessOrEqual(t, p99, 1000*time.Millisecond)
+}
+
+func TestDurationHistogram_Extremes(t *testing.T) {
+	var h durationHistogram
+	assert.Equal(t, time.Duration(0), h.percentile(0.5))
+
+	h.observe(0)
+	h.observe(time.Hour)
+	assert.LessOrEqual(t, h.percentile(0.5), histogramMinDuration)
+	assert.Equal(t, time.Hour, h.percentile(0.99))
+
+	p50, p95, p99 := NewDefaultMetricsCollector().GetPercentiles("GET_/missing")
+	assert.Zero(t, p50)
+	assert.Zero(t, p95)
+	assert.Zero(t, p99)
+}
+
+func TestDurationHistogram_MemoryBounded(t *testing.T) {
+	collector := NewDefaultMetricsCollector()
+	collector.ObserveLatency("GET", "/test", time.Millisecond)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	for i := 0; i < 1_000_000; i++ {
+		collector.ObserveLatency("GET", "/test", time.Duration(i%5000)*time.Microsecond)
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	// A slice of one million durations alone would retain 8MB
+	assert.Less(t, int64(after.HeapAlloc)-int64(before.HeapAlloc), int64(512*1024))
+	assert.Equal(t, uint64(1_000_001), collector.latencyHistogram["GET_/test"].count)
+}
+
+func TestDurationHistogram_GenerationPercentiles(t *testing.T) {
+	collector := NewDefaultMetricsCollector()
+
+	for i := 1; i <= 1000; i++ {
+		collector.ObserveGenerationDuration("GET", "/users", time.Duration(i)*time.Millisecond)
+	}
+
+	percentiles := collector.GetGenerationPercentiles("GET", "/users")
+	assert.Equal(t, uint64(1000), percentiles.Count)
+	assert.InEpsilon(t, float64(500*time.Millisecond), float64(percentiles.P50), 0.05)
+	assert.InEpsilon(t, float64(990*time.Millisecond), float64(percentiles.P99), 0.05)
+	assert.Equal(t, percentiles, collector.GetMetrics()["generation_percentiles"].(map[string]LatencyPercentiles)["GET_/users"])
+	assert.Zero(t, collector.GetGenerationPercentiles("GET", "/missing").Count)
+}
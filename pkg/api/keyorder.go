@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"vanta/pkg/openapi"
+)
+
+// KeyOrderSeedKey is the user value key holding the seed used to shuffle the
+// keys of the JSON objects in a response
+const KeyOrderSeedKey = "key_order_seed"
+
+// SetRandomizeKeyOrder enables shuffling the key order of JSON objects in mock
+// responses. Each response gets its own order; a non-zero seed makes the
+// sequence of orders reproducible.
+func (r *Router) SetRandomizeKeyOrder(enabled bool, seed int64) {
+	r.keyOrderMu.Lock()
+	defer r.keyOrderMu.Unlock()
+
+	if !enabled {
+		r.keyOrderRand = nil
+		return
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r.keyOrderRand = rand.New(rand.NewSource(seed))
+}
+
+// assignKeyOrderSeed draws the key order seed for a response, if randomization
+// is enabled. In per-request deterministic mode the seed comes from the
+// request, so identical requests keep identical key orders.
+func (r *Router) assignKeyOrderSeed(ctx *fasthttp.RequestCtx) {
+	r.keyOrderMu.Lock()
+	defer r.keyOrderMu.Unlock()
+
+	if r.keyOrderRand == nil {
+		return
+	}
+	if defaultGen, ok := r.generator.(*openapi.DefaultDataGenerator); ok && defaultGen.DeterministicPerRequest() {
+		ctx.SetUserValue(KeyOrderSeedKey, defaultGen.RequestSeed(RequestFingerprint(ctx)))
+		return
+	}
+	ctx.SetUserValue(KeyOrderSeedKey, r.keyOrderRand.Int63())
+}
+
+// marshalResponse serializes response data as JSON, shuffling object keys
+// when the request carries a key order seed
+func marshalResponse(ctx *fasthttp.RequestCtx, data interface{}) ([]byte, error) {
+	seed, ok := ctx.UserValue(KeyOrderSeedKey).(int64)
+	if !ok {
+		return json.Marshal(data)
+	}
+
+	var buf bytes.Buffer
+	if err := writeShuffledJSON(&buf, data, rand.New(rand.NewSource(seed))); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeShuffledJSON encodes value like json.Marshal but writes the keys of
+// every generic object in random order. Keys are sorted before shuffling so the
+// result depends only on the random source.
+func writeShuffledJSON(buf *bytes.Buffer, value interface{}, rng *rand.Rand) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		rng.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			encodedKey, err := json.Marshal(key)
+			if err != nil {
+				return err
+			}
+			buf.Write(encodedKey)
+			buf.WriteByte(':')
+			if err := writeShuffledJSON(buf, v[key], rng); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeShuffledJSON(buf, item, rng); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case []map[string]interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return writeShuffledJSON(buf, items, rng)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+		return nil
+	}
+}
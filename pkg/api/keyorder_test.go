@@ -0,0 +1,128 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"vanta/pkg/openapi"
+)
+
+func createWideObjectSpec() *openapi.Specification {
+	properties := make(map[string]*openapi.Schema)
+	required := make([]string, 0)
+	for _, name := range []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel"} {
+		properties[name] = &openapi.Schema{Type: "string"}
+		required = append(required, name)
+	}
+
+	return &openapi.Specification{
+		Version: "3.0.0",
+		Info:    openapi.InfoObject{Title: "Wide API", Version: "1.0.0"},
+		Paths: map[string]openapi.PathItem{
+			"/wide": {
+				GET: &openapi.Operation{
+					OperationID: "getWide",
+					Responses: map[string]openapi.Response{
+						"200": {
+							Description: "OK",
+							Content: map[string]openapi.MediaTypeObject{
+								"application/json": {Schema: &openapi.Schema{Type: "object", Required: required, Properties: properties}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// topLevelKeys returns the keys of a JSON object in the order they appear
+func topLevelKeys(t *testing.T, body []byte) []string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	token, err := decoder.Token()
+	require.NoError(t, err)
+	require.Equal(t, json.Delim('{'), token)
+
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		require.NoError(t, err)
+		keys = append(keys, token.(string))
+
+		var skip json.RawMessage
+		require.NoError(t, decoder.Decode(&skip))
+	}
+	return keys
+}
+
+func keyOrders(t *testing.T, seed int64, requests int) []string {
+	router, err := NewRouterWithGenerator(createWideObjectSpec(), openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	router.SetRandomizeKeyOrder(true, seed)
+
+	orders := make([]string, 0, requests)
+	for i := 0; i < requests; i++ {
+		ctx := createTestRequestCtx("GET", "/wide", nil)
+		router.Handler(ctx)
+		require.Equal(t, 200, ctx.Response.StatusCode())
+		require.True(t, json.Valid(ctx.Response.Body()), "response must remain valid JSON")
+
+		keys := topLevelKeys(t, ctx.Response.Body())
+		assert.ElementsMatch(t, []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel"}, keys)
+		orders = append(orders, strings.Join(keys, ","))
+	}
+	return orders
+}
+
+func TestRandomizeKeyOrder_VariesAcrossResponses(t *testing.T) {
+	orders := keyOrders(t, 42, 5)
+
+	distinct := make(map[string]bool)
+	for _, order := range orders {
+		distinct[order] = true
+	}
+	assert.Greater(t, len(distinct), 1, "key order should vary between responses")
+}
+
+func TestRandomizeKeyOrder_DeterministicUnderSeed(t *testing.T) {
+	assert.Equal(t, keyOrders(t, 42, 5), keyOrders(t, 42, 5))
+	assert.NotEqual(t, keyOrders(t, 42, 5), keyOrders(t, 7, 5))
+}
+
+func TestWriteShuffledJSON_NestedValues(t *testing.T) {
+	ctx := createTestRequestCtx("GET", "/", nil)
+	ctx.SetUserValue(KeyOrderSeedKey, int64(3))
+
+	data := map[string]interface{}{
+		"name":  "widget",
+		"tags":  []interface{}{"a", map[string]interface{}{"x": 1, "y": true}},
+		"owner": map[string]interface{}{"id": 7, "email": "a@example.com"},
+		"items": []map[string]interface{}{{"sku": "s1", "qty": 2}},
+		"none":  nil,
+	}
+
+	body, err := marshalResponse(ctx, data)
+	require.NoError(t, err)
+
+	var decoded, expected interface{}
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	plain, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(plain, &expected))
+	assert.Equal(t, expected, decoded)
+}
+
+func TestMarshalResponse_WithoutSeedKeepsDefaultEncoding(t *testing.T) {
+	ctx := createTestRequestCtx("GET", "/", nil)
+	data := map[string]interface{}{"b": 1, "a": 2}
+
+	body, err := marshalResponse(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":2,"b":1}`, string(body))
+}
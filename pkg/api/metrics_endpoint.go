@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// prometheusContentType is the content type of the text exposition format
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// startMetrics binds the metrics listener when metrics are enabled and
+// metrics.listen asks for it. Callers hold s.mu.
+func (s *Server) startMetrics() error {
+	metricsCfg := &s.fullConfig.Metrics
+	if !metricsCfg.Enabled || !metricsCfg.Listen {
+		return nil
+	}
+
+	addr := net.JoinHostPort(s.config.Host, fmt.Sprintf("%d", metricsCfg.Port))
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on metrics address %s: %w", addr, err)
+	}
+
+	s.metricsServer = &fasthttp.Server{
+		Handler:      s.metricsHandler(),
+		ReadTimeout:  s.config.ReadTimeout,
+		WriteTimeout: s.config.WriteTimeout,
+	}
+	s.metricsAddr = ln.Addr().String()
+
+	s.logger.Info("Starting metrics listener",
+		zap.String("address", s.metricsAddr),
+		zap.String("path", metricsCfg.Path),
+		zap.Bool("prometheus", metricsCfg.Prometheus),
+	)
+
+	go func(server *fasthttp.Server) {
+		if err := server.Serve(ln); err != nil {
+			s.logger.Error("Metrics listener stopped with error", zap.Error(err))
+		}
+	}(s.metricsServer)
+	return nil
+}
+
+// stopMetrics closes the metrics listener if it is running
+func (s *Server) stopMetrics() {
+	s.mu.Lock()
+	metricsServer := s.metricsServer
+	s.metricsServer = nil
+	s.metricsAddr = ""
+	s.mu.Unlock()
+
+	if metricsServer == nil {
+		return
+	}
+	if err := metricsServer.Shutdown(); err != nil {
+		s.logger.Warn("Failed to shutdown metrics listener", zap.Error(err))
+	}
+}
+
+// MetricsAddr returns the address bound by the metrics listener, or "" when it is not running
+func (s *Server) MetricsAddr() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.metricsAddr
+}
+
+// metricsHandler serves the collector at metrics.path, in the Prometheus text
+// format when metrics.prometheus is set and as JSON otherwise
+func (s *Server) metricsHandler() fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		s.mu.RLock()
+		metricsCfg := s.fullConfig.Metrics
+		collector := s.metricsCollector
+		s.mu.RUnlock()
+
+		if string(ctx.Path()) != metricsCfg.Path {
+			ctx.SetStatusCode(fasthttp.StatusNotFound)
+			return
+		}
+		if !ctx.IsGet() && !ctx.IsHead() {
+			ctx.SetStatusCode(fasthttp.StatusMethodNotAllowed)
+			return
+		}
+		if collector == nil {
+			ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+			return
+		}
+
+		if metricsCfg.Prometheus {
+			var buf bytes.Buffer
+			collector.WritePrometheus(&buf)
+			ctx.SetContentType(prometheusContentType)
+			ctx.SetBody(buf.Bytes())
+			return
+		}
+
+		responseBytes, err := json.Marshal(collector.GetMetrics())
+		if err != nil {
+			ctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+			return
+		}
+		ctx.SetContentType("application/json")
+		ctx.SetBody(responseBytes)
+	}
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"vanta/pkg/config"
+)
+
+// promSample is one parsed sample line of the text exposition format
+type promSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// parsePrometheusText parses the subset of the text exposition format the
+// collector writes, failing the test on any malformed line
+func parsePrometheusText(t *testing.T, body string) (types map[string]string, samples []promSample) {
+	types = make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "# TYPE "):
+			fields := strings.Fields(line)
+			require.Len(t, fields, 4, line)
+			types[fields[2]] = fields[3]
+			continue
+		case strings.HasPrefix(line, "# HELP "), line == "":
+			continue
+		}
+
+		sample := promSample{labels: make(map[string]string)}
+		series, value, ok := strings.Cut(line, " ")
+		require.True(t, ok, "sample without value: %s", line)
+
+		name, labels, hasLabels := strings.Cut(series, "{")
+		sample.name = name
+		if hasLabels {
+			require.True(t, strings.HasSuffix(labels, "}"), line)
+			for _, pair := range strings.Split(strings.TrimSuffix(labels, "}"), ",") {
+				key, quoted, ok := strings.Cut(pair, "=")
+				require.True(t, ok, line)
+				unquoted, err := strconv.Unquote(quoted)
+				require.NoError(t, err, line)
+				sample.labels[key] = unquoted
+			}
+		}
+
+		parsed, err := strconv.ParseFloat(value, 64)
+		require.NoError(t, err, line)
+		sample.value = parsed
+		samples = append(samples, sample)
+	}
+	return types, samples
+}
+
+func findSample(samples []promSample, name string, labels map[string]string) (float64, bool) {
+	for _, sample := range samples {
+		if sample.name != name {
+			continue
+		}
+		matches := true
+		for key, value := range labels {
+			if sample.labels[key] != value {
+				matches = false
+			}
+		}
+		if matches {
+			return sample.value, true
+		}
+	}
+	return 0, false
+}
+
+func scrapeMetrics(t *testing.T, prometheus bool) (*http.Response, string) {
+	cfg := newTestServerConfig()
+	cfg.Metrics.Enabled = true
+	cfg.Metrics.Listen = true
+	cfg.Metrics.Port = 0
+	cfg.Metrics.Prometheus = prometheus
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { assert.NoError(t, server.Stop()) })
+	require.NotEmpty(t, server.MetricsAddr())
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get("http://" + server.ListenAddrs()[0] + "/users")
+		require.NoError(t, err)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	resp, err := client.Get("http://" + server.MetricsAddr() + cfg.Metrics.Path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestMetricsEndpoint_NoListenerByDefault(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Metrics = config.DefaultConfig().Metrics
+	require.True(t, cfg.Metrics.Enabled)
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+
+	// Metrics are collected, but no port is opened for them
+	assert.Empty(t, server.MetricsAddr())
+	assert.NotNil(t, server.GetMetrics())
+}
+
+func TestMetricsEndpoint_Prometheus(t *testing.T) {
+	resp, body := scrapeMetrics(t, true)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, prometheusContentType, resp.Header.Get("Content-Type"))
+
+	types, samples := parsePrometheusText(t, body)
+	assert.Equal(t, "counter", types["http_requests_total"])
+	assert.Equal(t, "histogram", types["http_request_duration_seconds"])
+	assert.Equal(t, "gauge", types["active_connections"])
+
+	requests, ok := findSample(samples, "http_requests_total", map[string]string{"method": "GET", "path": "/users", "status": "200"})
+	require.True(t, ok, body)
+	assert.Equal(t, 3.0, requests)
+
+	route := map[string]string{"method": "GET", "path": "/users"}
+	count, ok := findSample(samples, "http_request_duration_seconds_count", route)
+	require.True(t, ok, body)
+	assert.Equal(t, 3.0, count)
+
+	inf, ok := findSample(samples, "http_request_duration_seconds_bucket", map[string]string{"method": "GET", "path": "/users", "le": "+Inf"})
+	require.True(t, ok, body)
+	assert.Equal(t, 3.0, inf)
+
+	// Buckets are cumulative
+	previous := 0.0
+	for _, sample := range samples {
+		if sample.name == "http_request_duration_seconds_bucket" && sample.labels["path"] == "/users" {
+			assert.GreaterOrEqual(t, sample.value, previous)
+			previous = sample.value
+		}
+	}
+
+	_, ok = findSample(samples, "active_connections", nil)
+	assert.True(t, ok, body)
+}
+
+func TestMetricsEndpoint_JSON(t *testing.T) {
+	resp, body := scrapeMetrics(t, false)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var metrics map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(body), &metrics))
+	assert.Equal(t, 3.0, metrics["request_counter"].(map[string]interface{})["GET_/users_200"])
+}
+
+func TestWritePrometheus_EscapesLabels(t *testing.T) {
+	collector := NewDefaultMetricsCollector()
+	collector.IncRequestCounter("GET", `/a_b/"quoted"\`, 404)
+
+	var buf strings.Builder
+	collector.WritePrometheus(&buf)
+
+	_, samples := parsePrometheusText(t, buf.String())
+	value, ok := findSample(samples, "http_requests_total", map[string]string{
+		"method": "GET",
+		"path":   `/a_b/"quoted"\`,
+		"status": "404",
+	})
+	require.True(t, ok, buf.String())
+	assert.Equal(t, 1.0, value)
+}
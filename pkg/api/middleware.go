@@ -2,7 +2,13 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
@@ -14,7 +20,9 @@ import (
 	"go.uber.org/zap"
 	"vanta/pkg/chaos"
 	"vanta/pkg/config"
+	"vanta/pkg/plugins"
 	"vanta/pkg/recorder"
+	"vanta/pkg/tracing"
 )
 
 // MiddlewareFunc is the type of function for FastHTTP middleware
@@ -80,6 +88,240 @@ func RequestID(enabled bool) MiddlewareFunc {
 	}
 }
 
+// ClientIP resolves the client address once, believing forwarding headers
+// only from trusted proxies, and stores it for logging and recording
+func ClientIP(proxies plugins.TrustedProxies) MiddlewareFunc {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			ctx.SetUserValue(config.ClientIPKey, proxies.ClientIP(ctx))
+			
+			next(ctx)
+		}
+	}
+}
+
+// BodyCapture marks requests on streaming paths so logging and recording
+// skip reading the body and capture metadata only
+func BodyCapture(streamingPaths []string) MiddlewareFunc {
+	if len(streamingPaths) == 0 {
+		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return next
+		}
+	}
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			if matchesPathPattern(string(ctx.Path()), streamingPaths) {
+				ctx.SetUserValue(config.BodyCaptureDisabledKey, true)
+			}
+			
+			next(ctx)
+		}
+	}
+}
+
+// matchesPathPattern reports whether path matches one of patterns. A trailing
+// "*" matches any path with the given prefix.
+func matchesPathPattern(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyCaptureDisabled reports whether the request was marked as streaming
+func bodyCaptureDisabled(ctx *fasthttp.RequestCtx) bool {
+	disabled, _ := ctx.UserValue(config.BodyCaptureDisabledKey).(bool)
+	return disabled
+}
+
+// ResponseCapture snapshots the response body once the handler returns and
+// stores it under config.ResponseBodyKey. It sits innermost in the stack so
+// logging, recording and ETags all read that one copy instead of each
+// buffering fasthttp's reusable body. Streamed bodies are not captured.
+func ResponseCapture() MiddlewareFunc {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			next(ctx)
+
+			if bodyCaptureDisabled(ctx) || ctx.Response.IsBodyStream() {
+				return
+			}
+			ctx.SetUserValue(config.ResponseBodyKey, append([]byte(nil), ctx.Response.Body()...))
+		}
+	}
+}
+
+// capturedResponseBody returns the body snapshot taken by ResponseCapture.
+// Without one (the handler never ran, or a middleware replaced the body after
+// it) the live body is returned, which is only valid until the handler returns.
+func capturedResponseBody(ctx *fasthttp.RequestCtx) (body []byte, captured bool) {
+	if body, ok := ctx.UserValue(config.ResponseBodyKey).([]byte); ok {
+		return body, true
+	}
+	return ctx.Response.Body(), false
+}
+
+// dropCapturedResponseBody discards the snapshot once a middleware replaces
+// the response the handler wrote
+func dropCapturedResponseBody(ctx *fasthttp.RequestCtx) {
+	ctx.RemoveUserValue(config.ResponseBodyKey)
+}
+
+// BodyLimit middleware rejects requests whose body is larger than maxBytes
+// with 413 before the handler or any body-reading plugin runs. Streamed bodies
+// are checked against their declared Content-Length; chunked streams, which
+// declare none, are buffered up to the limit. A maxBytes of 0 disables it.
+func BodyLimit(maxBytes int64) MiddlewareFunc {
+	if maxBytes <= 0 {
+		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return next
+		}
+	}
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			size := int64(ctx.Request.Header.ContentLength())
+			switch {
+			case !ctx.Request.IsBodyStream():
+				// Chunked bodies carry no Content-Length but are already buffered
+				size = int64(len(ctx.Request.Body()))
+			case size < 0:
+				// Reading one byte past the limit is enough to tell, and keeps
+				// later Body() calls from pulling an unbounded stream into memory
+				body, err := io.ReadAll(io.LimitReader(ctx.Request.BodyStream(), maxBytes+1))
+				if err != nil {
+					ctx.SetStatusCode(fasthttp.StatusBadRequest)
+					ctx.SetContentType("application/json")
+					ctx.SetBody([]byte(`{"error": "Failed to read request body"}`))
+					return
+				}
+				size = int64(len(body))
+				if size <= maxBytes {
+					ctx.Request.SetBody(body)
+				}
+			}
+
+			if size > maxBytes {
+				writeBodyTooLarge(ctx, maxBytes)
+				return
+			}
+
+			next(ctx)
+		}
+	}
+}
+
+// writeBodyTooLarge sends the 413 response shared by BodyLimit and the
+// server's own body size check
+func writeBodyTooLarge(ctx *fasthttp.RequestCtx, maxBytes int64) {
+	ctx.SetStatusCode(fasthttp.StatusRequestEntityTooLarge)
+	ctx.SetContentType("application/json")
+	ctx.SetBody([]byte(fmt.Sprintf(`{"error": "Request body too large", "max_body_bytes": %d}`, maxBytes)))
+}
+
+// SpecPublicKey is the user value key set to true when the spec's security
+// requirements let the request's operation be called without credentials
+const SpecPublicKey = "spec_public"
+
+// SpecSecurity middleware marks requests whose operation the spec declares
+// public, so the auth plugin can skip them when spec_security is enabled.
+// Requests no spec operation serves are left unmarked.
+func SpecSecurity(router *Router) MiddlewareFunc {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			if required, known := router.RequiresAuth(string(ctx.Method()), string(ctx.Path())); known && !required {
+				ctx.SetUserValue(SpecPublicKey, true)
+			}
+			next(ctx)
+		}
+	}
+}
+
+// inFlightCountedKey marks requests whose active connection was already
+// counted by MaxInFlight so Metrics does not count them twice
+const inFlightCountedKey = "in_flight_counted"
+
+// MaxInFlight middleware admits at most limit requests at once. Requests over
+// the limit get a 503 with Retry-After instead of waiting, simulating a backend
+// at capacity. Admitted requests are counted as active connections on collector
+// when it is set. A limit of 0 disables it.
+func MaxInFlight(limit int, collector MetricsCollector) MiddlewareFunc {
+	if limit <= 0 {
+		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return next
+		}
+	}
+
+	slots := make(chan struct{}, limit)
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			select {
+			case slots <- struct{}{}:
+			default:
+				ctx.Response.Header.Set("Retry-After", "1")
+				ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+				ctx.SetContentType("application/json")
+				ctx.SetBody([]byte(fmt.Sprintf(`{"error": "Server at capacity", "max_in_flight": %d}`, limit)))
+				return
+			}
+			defer func() { <-slots }()
+
+			if collector != nil {
+				collector.IncActiveConnections()
+				defer collector.DecActiveConnections()
+				ctx.SetUserValue(inFlightCountedKey, true)
+			}
+
+			next(ctx)
+		}
+	}
+}
+
+// Tracing middleware starts a server span for every request, continuing the
+// trace of an incoming traceparent header. The span's context is stored in the
+// "trace_context" user value so plugin spans become its children.
+func Tracing(tracer *tracing.Tracer) MiddlewareFunc {
+	if tracer == nil {
+		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return next
+		}
+	}
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			parent := context.Background()
+			if sc, ok := tracing.ParseTraceparent(string(ctx.Request.Header.Peek(tracing.TraceparentHeader))); ok {
+				parent = tracing.ContextWithRemoteSpanContext(parent, sc)
+			}
+
+			traceCtx, span := tracer.Start(parent, string(ctx.Method())+" "+string(ctx.Path()), tracing.SpanKindServer)
+			span.SetAttribute("http.request.method", string(ctx.Method()))
+			span.SetAttribute("url.path", string(ctx.Path()))
+			if requestID, ok := ctx.UserValue("request_id").(string); ok && requestID != "" {
+				span.SetAttribute("request_id", requestID)
+			}
+			ctx.SetUserValue("trace_context", traceCtx)
+
+			next(ctx)
+
+			status := ctx.Response.StatusCode()
+			span.SetAttribute("http.response.status_code", status)
+			if status >= 500 {
+				span.SetStatus(tracing.StatusError, fasthttp.StatusMessage(status))
+			}
+			span.End()
+		}
+	}
+}
+
 // Logger middleware provides request/response logging with zap integration
 func Logger(logger *zap.Logger, loggingCfg *config.LoggingConfig) MiddlewareFunc {
 	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
@@ -106,8 +348,20 @@ func Logger(logger *zap.Logger, loggingCfg *config.LoggingConfig) MiddlewareFunc
 				zap.Duration("duration", duration),
 				zap.String("remote_addr", ctx.RemoteAddr().String()),
 				zap.String("user_agent", string(ctx.UserAgent())),
-				zap.Int("request_size", len(ctx.Request.Body())),
-				zap.Int("response_size", len(ctx.Response.Body())),
+			}
+			if clientIP, ok := ctx.UserValue(config.ClientIPKey).(string); ok {
+				fields = append(fields, zap.String("client_ip", clientIP))
+			}
+			
+			// Avoid pulling streamed bodies into memory just to measure them
+			if bodyCaptureDisabled(ctx) {
+				fields = append(fields,
+					zap.Int("request_size", ctx.Request.Header.ContentLength()),
+					zap.Int("response_size", ctx.Response.Header.ContentLength()))
+			} else {
+				fields = append(fields,
+					zap.Int("request_size", len(ctx.Request.Body())),
+					zap.Int("response_size", len(ctx.Response.Body())))
 			}
 			
 			// Add request ID if available
@@ -115,6 +369,16 @@ func Logger(logger *zap.Logger, loggingCfg *config.LoggingConfig) MiddlewareFunc
 				fields = append(fields, zap.String("request_id", requestID))
 			}
 			
+			// Add request fingerprint if available
+			if fingerprint, ok := ctx.UserValue(RequestFingerprintKey).(string); ok {
+				fields = append(fields, zap.String("fingerprint", fingerprint))
+			}
+			
+			// Add mock data generation time if the request reached the generator
+			if generation, ok := ctx.UserValue(GenerationDurationKey).(time.Duration); ok {
+				fields = append(fields, zap.Duration("generation_duration", generation))
+			}
+			
 			// Log based on status code
 			status := ctx.Response.StatusCode()
 			switch {
@@ -178,6 +442,7 @@ func Recovery(logger *zap.Logger, recoveryCfg *config.RecoveryConfig) Middleware
 					}
 					
 					// Set error response
+					dropCapturedResponseBody(ctx)
 					ctx.SetStatusCode(fasthttp.StatusInternalServerError)
 					ctx.SetContentType("application/json")
 					
@@ -191,7 +456,10 @@ func Recovery(logger *zap.Logger, recoveryCfg *config.RecoveryConfig) Middleware
 	}
 }
 
-// CORS middleware handles Cross-Origin Resource Sharing
+// CORS middleware handles Cross-Origin Resource Sharing. Origins listed in
+// AllowOrigins or matching AllowOriginPatterns are reflected with Vary: Origin.
+// A "*" entry answers "*" only without credentials, since browsers reject a
+// wildcard origin on credentialed requests.
 func CORS(corsCfg *config.CORSConfig) MiddlewareFunc {
 	if !corsCfg.Enabled {
 		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
@@ -199,27 +467,42 @@ func CORS(corsCfg *config.CORSConfig) MiddlewareFunc {
 		}
 	}
 
+	wildcard := false
+	allowedOrigins := make(map[string]bool, len(corsCfg.AllowOrigins))
+	for _, allowedOrig := range corsCfg.AllowOrigins {
+		if allowedOrig == "*" {
+			wildcard = !corsCfg.AllowCredentials
+			continue
+		}
+		allowedOrigins[allowedOrig] = true
+	}
+
+	// Patterns are checked by config validation; invalid ones never match
+	var originPatterns []*regexp.Regexp
+	for _, pattern := range corsCfg.AllowOriginPatterns {
+		if regex, err := regexp.Compile(pattern); err == nil {
+			originPatterns = append(originPatterns, regex)
+		}
+	}
+
 	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 		return func(ctx *fasthttp.RequestCtx) {
 			origin := string(ctx.Request.Header.Peek("Origin"))
 			
 			// Check if origin is allowed
 			allowedOrigin := ""
-			if len(corsCfg.AllowOrigins) > 0 {
-				for _, allowedOrig := range corsCfg.AllowOrigins {
-					if allowedOrig == "*" || allowedOrig == origin {
-						allowedOrigin = allowedOrig
-						break
-					}
-				}
+			if wildcard {
+				allowedOrigin = "*"
+			} else if origin != "" && (allowedOrigins[origin] || matchesOriginPattern(originPatterns, origin)) {
+				allowedOrigin = origin
 			}
 			
 			// Set CORS headers if origin is allowed
 			if allowedOrigin != "" {
-				if allowedOrigin == "*" {
-					ctx.Response.Header.Set("Access-Control-Allow-Origin", "*")
-				} else {
-					ctx.Response.Header.Set("Access-Control-Allow-Origin", origin)
+				ctx.Response.Header.Set("Access-Control-Allow-Origin", allowedOrigin)
+				if allowedOrigin != "*" {
+					// The response differs per origin, so caches must key on it
+					ctx.Response.Header.Add("Vary", "Origin")
 				}
 				
 				// Set allowed methods
@@ -256,6 +539,16 @@ func CORS(corsCfg *config.CORSConfig) MiddlewareFunc {
 	}
 }
 
+// matchesOriginPattern reports whether origin matches any of patterns
+func matchesOriginPattern(patterns []*regexp.Regexp, origin string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
 // TimeoutConfig holds timeout configuration for the middleware
 type TimeoutConfig struct {
 	Enabled  bool
@@ -307,6 +600,7 @@ func Timeout(timeoutCfg *config.TimeoutConfig) MiddlewareFunc {
 				}
 				
 				// Set timeout response
+				dropCapturedResponseBody(ctx)
 				ctx.SetStatusCode(fasthttp.StatusRequestTimeout)
 				ctx.SetContentType("application/json")
 				
@@ -322,6 +616,122 @@ func Timeout(timeoutCfg *config.TimeoutConfig) MiddlewareFunc {
 	}
 }
 
+// ETag middleware sets a weak ETag computed from the body of successful GET
+// responses and answers 304 Not Modified when If-None-Match matches it. The
+// tag is weak because it hashes the body before compression, so gzip, deflate
+// and identity responses all carry it and are not byte-for-byte equal.
+func ETag(enabled bool) MiddlewareFunc {
+	if !enabled {
+		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return next
+		}
+	}
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			next(ctx)
+
+			status := ctx.Response.StatusCode()
+			if !ctx.IsGet() || status < 200 || status >= 300 || ctx.Response.IsBodyStream() {
+				return
+			}
+
+			etag := string(ctx.Response.Header.Peek("ETag"))
+			if etag == "" {
+				body, _ := capturedResponseBody(ctx)
+				sum := sha256.Sum256(body)
+				etag = `W/"` + hex.EncodeToString(sum[:16]) + `"`
+				ctx.Response.Header.Set("ETag", etag)
+			}
+
+			if etagMatches(string(ctx.Request.Header.Peek("If-None-Match")), etag) {
+				ctx.Response.ResetBody()
+				ctx.SetStatusCode(fasthttp.StatusNotModified)
+			}
+		}
+	}
+}
+
+// etagMatches applies the weak comparison If-None-Match calls for
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Compression middleware gzip- or deflate-encodes response bodies of at least
+// MinSize bytes for clients that accept it
+func Compression(compressionCfg *config.CompressionConfig) MiddlewareFunc {
+	if !compressionCfg.Enabled {
+		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return next
+		}
+	}
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			next(ctx)
+
+			if !shouldCompress(ctx, compressionCfg.MinSize) {
+				return
+			}
+
+			// The response depends on Accept-Encoding even when we send it as-is
+			ctx.Response.Header.Add("Vary", "Accept-Encoding")
+
+			switch {
+			case ctx.Request.Header.HasAcceptEncoding("gzip"):
+				body := fasthttp.AppendGzipBytesLevel(nil, ctx.Response.Body(), compressionCfg.Level)
+				ctx.Response.SetBodyRaw(body)
+				ctx.Response.Header.Set("Content-Encoding", "gzip")
+			case ctx.Request.Header.HasAcceptEncoding("deflate"):
+				body := fasthttp.AppendDeflateBytesLevel(nil, ctx.Response.Body(), compressionCfg.Level)
+				ctx.Response.SetBodyRaw(body)
+				ctx.Response.Header.Set("Content-Encoding", "deflate")
+			}
+		}
+	}
+}
+
+// shouldCompress reports whether the response body is worth compressing
+func shouldCompress(ctx *fasthttp.RequestCtx, minSize int) bool {
+	if ctx.IsHead() || ctx.Response.IsBodyStream() {
+		return false
+	}
+	if len(ctx.Response.Header.Peek("Content-Encoding")) > 0 {
+		return false
+	}
+	if len(ctx.Response.Body()) < minSize {
+		return false
+	}
+	return !isCompressedContentType(string(ctx.Response.Header.ContentType()))
+}
+
+// isCompressedContentType reports whether contentType is already compressed,
+// so encoding it again would only cost CPU
+func isCompressedContentType(contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	for _, prefix := range []string{"image/", "video/", "audio/"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return contentType != "image/svg+xml"
+		}
+	}
+	switch contentType {
+	case "application/gzip", "application/x-gzip", "application/zip",
+		"application/x-bzip2", "application/x-7z-compressed", "application/zstd", "font/woff2":
+		return true
+	}
+	return false
+}
+
 // MetricsCollector interface for collecting HTTP metrics
 type MetricsCollector interface {
 	IncRequestCounter(method, path string, status int)
@@ -330,19 +740,28 @@ type MetricsCollector interface {
 	DecActiveConnections()
 }
 
+// GenerationMetricsCollector records how long mock data generation takes per route
+type GenerationMetricsCollector interface {
+	ObserveGenerationDuration(method, route string, duration time.Duration)
+}
+
 // DefaultMetricsCollector provides a simple metrics implementation
 type DefaultMetricsCollector struct {
-	requestCounter    map[string]int64
-	latencyHistogram  map[string][]time.Duration
-	activeConnections int64
-	mu                sync.RWMutex
+	requestCounter      map[string]int64
+	statusClassCounter  map[string]int64 // METHOD_path_class, e.g. GET_/users/{id}_2xx
+	latencyHistogram    map[string]*durationHistogram
+	generationHistogram map[string]*durationHistogram
+	activeConnections   int64
+	mu                  sync.RWMutex
 }
 
 // NewDefaultMetricsCollector creates a new default metrics collector
 func NewDefaultMetricsCollector() *DefaultMetricsCollector {
 	return &DefaultMetricsCollector{
-		requestCounter:   make(map[string]int64),
-		latencyHistogram: make(map[string][]time.Duration),
+		requestCounter:      make(map[string]int64),
+		statusClassCounter:  make(map[string]int64),
+		latencyHistogram:    make(map[string]*durationHistogram),
+		generationHistogram: make(map[string]*durationHistogram),
 	}
 }
 
@@ -352,6 +771,43 @@ func (m *DefaultMetricsCollector) IncRequestCounter(method, path string, status
 	defer m.mu.Unlock()
 	key := fmt.Sprintf("%s_%s_%d", method, path, status)
 	m.requestCounter[key]++
+	m.statusClassCounter[fmt.Sprintf("%s_%s_%s", method, path, statusClass(status))]++
+}
+
+// statusClass returns the class of an HTTP status code, such as "2xx"
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "other"
+	}
+	return fmt.Sprintf("%dxx", status/100)
+}
+
+// GetStatusClassCount returns how many responses of a status class ("2xx",
+// "3xx", "4xx" or "5xx") were served for a route
+func (m *DefaultMetricsCollector) GetStatusClassCount(method, path, class string) int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.statusClassCounter[fmt.Sprintf("%s_%s_%s", method, path, class)]
+}
+
+// GetErrorRate returns the share of a route's responses that were 5xx, or 0
+// when the route has not been requested
+func (m *DefaultMetricsCollector) GetErrorRate(method, path string) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.errorRate(fmt.Sprintf("%s_%s", method, path))
+}
+
+// errorRate computes the 5xx share for a METHOD_path key. Callers hold m.mu.
+func (m *DefaultMetricsCollector) errorRate(route string) float64 {
+	var total int64
+	for _, class := range []string{"1xx", "2xx", "3xx", "4xx", "5xx", "other"} {
+		total += m.statusClassCounter[route+"_"+class]
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(m.statusClassCounter[route+"_5xx"]) / float64(total)
 }
 
 // ObserveLatency records request latency
@@ -359,7 +815,67 @@ func (m *DefaultMetricsCollector) ObserveLatency(method, path string, duration t
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	key := fmt.Sprintf("%s_%s", method, path)
-	m.latencyHistogram[key] = append(m.latencyHistogram[key], duration)
+	histogram, exists := m.latencyHistogram[key]
+	if !exists {
+		histogram = &durationHistogram{}
+		m.latencyHistogram[key] = histogram
+	}
+	histogram.observe(duration)
+}
+
+// LatencyPercentiles summarizes the latencies observed for one route
+type LatencyPercentiles struct {
+	Count uint64        `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	P99   time.Duration `json:"p99"`
+}
+
+// GetPercentiles returns the latency percentiles for a "METHOD_path" key, or
+// zeros when nothing was observed for it
+func (m *DefaultMetricsCollector) GetPercentiles(key string) (p50, p95, p99 time.Duration) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	histogram, exists := m.latencyHistogram[key]
+	if !exists {
+		return 0, 0, 0
+	}
+	return histogram.percentile(0.50), histogram.percentile(0.95), histogram.percentile(0.99)
+}
+
+// summary reports the count and percentiles of a histogram
+func (h *durationHistogram) summary() LatencyPercentiles {
+	return LatencyPercentiles{
+		Count: h.count,
+		P50:   h.percentile(0.50),
+		P95:   h.percentile(0.95),
+		P99:   h.percentile(0.99),
+	}
+}
+
+// ObserveGenerationDuration records mock data generation time for a route
+func (m *DefaultMetricsCollector) ObserveGenerationDuration(method, route string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := fmt.Sprintf("%s_%s", method, route)
+	histogram, exists := m.generationHistogram[key]
+	if !exists {
+		histogram = &durationHistogram{}
+		m.generationHistogram[key] = histogram
+	}
+	histogram.observe(duration)
+}
+
+// GetGenerationPercentiles returns the count and percentiles of the
+// generation durations recorded for a route, or zeros when there are none
+func (m *DefaultMetricsCollector) GetGenerationPercentiles(method, route string) LatencyPercentiles {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	histogram, exists := m.generationHistogram[fmt.Sprintf("%s_%s", method, route)]
+	if !exists {
+		return LatencyPercentiles{}
+	}
+	return histogram.summary()
 }
 
 // IncActiveConnections increments active connection count
@@ -376,18 +892,51 @@ func (m *DefaultMetricsCollector) DecActiveConnections() {
 	m.activeConnections--
 }
 
+// ActiveConnections returns the number of requests currently being served
+func (m *DefaultMetricsCollector) ActiveConnections() int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.activeConnections
+}
+
 // GetMetrics returns current metrics (for debugging/monitoring)
 func (m *DefaultMetricsCollector) GetMetrics() map[string]interface{} {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
+	latencies := make(map[string]LatencyPercentiles, len(m.latencyHistogram))
+	for key, histogram := range m.latencyHistogram {
+		latencies[key] = histogram.summary()
+	}
+	generations := make(map[string]LatencyPercentiles, len(m.generationHistogram))
+	for key, histogram := range m.generationHistogram {
+		generations[key] = histogram.summary()
+	}
+
+	// Error rates per METHOD_path route
+	errorRates := make(map[string]float64)
+	for key := range m.statusClassCounter {
+		route := key[:strings.LastIndex(key, "_")]
+		if _, done := errorRates[route]; !done {
+			errorRates[route] = m.errorRate(route)
+		}
+	}
+
 	return map[string]interface{}{
-		"request_counter":    m.requestCounter,
-		"active_connections": m.activeConnections,
-		"latency_count":      len(m.latencyHistogram),
+		"request_counter":        maps.Clone(m.requestCounter),
+		"status_class_counter":   maps.Clone(m.statusClassCounter),
+		"error_rates":            errorRates,
+		"active_connections":     m.activeConnections,
+		"latency_percentiles":    latencies,
+		"generation_count":       len(m.generationHistogram),
+		"generation_percentiles": generations,
 	}
 }
 
+// UnmatchedRoute is the path metrics are recorded under for requests no
+// route matched
+const UnmatchedRoute = "unmatched"
+
 // Metrics middleware collects HTTP request metrics
 func Metrics(metricsCfg *config.MetricsConfig, collector MetricsCollector) MiddlewareFunc {
 	if !metricsCfg.Enabled || collector == nil {
@@ -400,17 +949,24 @@ func Metrics(metricsCfg *config.MetricsConfig, collector MetricsCollector) Middl
 		return func(ctx *fasthttp.RequestCtx) {
 			start := time.Now()
 			
-			// Increment active connections
-			collector.IncActiveConnections()
-			defer collector.DecActiveConnections()
+			// Increment active connections unless MaxInFlight already did
+			if counted, _ := ctx.UserValue(inFlightCountedKey).(bool); !counted {
+				collector.IncActiveConnections()
+				defer collector.DecActiveConnections()
+			}
 			
 			// Execute next handler
 			next(ctx)
 			
-			// Record metrics
+			// Record metrics under the matched route template so
+			// /users/1 and /users/2 share /users/{id}, and every request no
+			// route matched under one key so scanners cannot grow the maps
 			duration := time.Since(start)
 			method := string(ctx.Method())
-			path := string(ctx.Path())
+			path := RouteTemplate(ctx)
+			if path == "" {
+				path = UnmatchedRoute
+			}
 			status := ctx.Response.StatusCode()
 			
 			collector.IncRequestCounter(method, path, status)
@@ -419,12 +975,28 @@ func Metrics(metricsCfg *config.MetricsConfig, collector MetricsCollector) Middl
 	}
 }
 
-// Chaos returns a chaos engineering middleware that injects faults based on configuration
-func Chaos(chaosEngine chaos.ChaosEngine, logger *zap.Logger) MiddlewareFunc {
+// Chaos returns a chaos engineering middleware that injects faults based on
+// configuration. When cfg.AllowHeaderTrigger is set, a request carrying the
+// X-Chaos header gets exactly the chaos it asks for instead.
+func Chaos(chaosEngine chaos.ChaosEngine, cfg *config.ChaosConfig, logger *zap.Logger) MiddlewareFunc {
+	allowHeaderTrigger := cfg != nil && cfg.AllowHeaderTrigger
+	
 	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 		return func(ctx *fasthttp.RequestCtx) {
+			if chaosEngine == nil {
+				next(ctx)
+				return
+			}
+			
+			if allowHeaderTrigger {
+				if trigger := ctx.Request.Header.Peek(chaos.TriggerHeader); len(trigger) > 0 {
+					applyChaosTrigger(chaosEngine, string(trigger), ctx, next, logger)
+					return
+				}
+			}
+			
 			// Check if chaos engine is enabled
-			if chaosEngine == nil || !chaosEngine.IsEnabled() {
+			if !chaosEngine.IsEnabled() {
 				next(ctx)
 				return
 			}
@@ -433,7 +1005,7 @@ func Chaos(chaosEngine chaos.ChaosEngine, logger *zap.Logger) MiddlewareFunc {
 			path := string(ctx.Path())
 			
 			// Check if chaos should be applied to this endpoint
-			shouldApply, action := chaosEngine.ShouldApplyChaos(path)
+			shouldApply, action := chaosEngine.ShouldApplyChaosToRequest(string(ctx.Method()), path)
 			if !shouldApply {
 				next(ctx)
 				return
@@ -463,14 +1035,106 @@ func Chaos(chaosEngine chaos.ChaosEngine, logger *zap.Logger) MiddlewareFunc {
 			
 			// For other types of chaos (like latency), continue with normal processing
 			next(ctx)
+			
+			// Body corruption mangles the body produced by the handler, so the
+			// snapshot no longer matches what the client receives
+			if chaos.ApplyBodyCorruption(ctx) {
+				dropCapturedResponseBody(ctx)
+			}
+			
+			// Connection resets drop the client mid-response
+			chaos.ApplyConnectionReset(ctx)
+		}
+	}
+}
+
+// ChaosBandwidth applies a bandwidth throttle injected by the Chaos
+// middleware. The throttle turns the response into a body stream, which
+// logging, compression, ETags and plugins would drain by reading the body,
+// so this runs outside all of them, once the response is final.
+func ChaosBandwidth() MiddlewareFunc {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			next(ctx)
+			
+			chaos.ApplyBandwidthThrottle(ctx)
+		}
+	}
+}
+
+// applyChaosTrigger applies the chaos requested by an X-Chaos header. An
+// invalid header is answered with 400 so testers notice the typo.
+func applyChaosTrigger(chaosEngine chaos.ChaosEngine, trigger string, ctx *fasthttp.RequestCtx, next fasthttp.RequestHandler, logger *zap.Logger) {
+	path := string(ctx.Path())
+	
+	actions, err := chaos.ParseTrigger(trigger)
+	if err == nil {
+		for _, action := range actions {
+			if err = chaosEngine.ApplyTrigger(action, ctx); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		logger.Warn("Rejected chaos header",
+			zap.String("path", path),
+			zap.String("trigger", trigger),
+			zap.Error(err))
+		ctx.ResetBody()
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetContentType("application/json")
+		body, _ := json.Marshal(map[string]string{
+			"error":   "invalid_chaos_header",
+			"message": err.Error(),
+		})
+		ctx.SetBody(body)
+		return
+	}
+	
+	fields := []zap.Field{
+		zap.String("path", path),
+		zap.String("trigger", trigger),
+	}
+	if requestID, ok := ctx.UserValue("request_id").(string); ok {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	logger.Info("Header-triggered chaos applied", fields...)
+	
+	// An injected error replaces the response
+	for _, action := range actions {
+		if action.Type == "error" {
+			return
 		}
 	}
+	
+	next(ctx)
 }
 
 // Recording returns a middleware that records HTTP requests and responses
 func Recording(recordingEngine recorder.RecordingEngine, logger *zap.Logger) MiddlewareFunc {
+	return recordingFrom(func() recorder.RecordingEngine { return recordingEngine }, logger)
+}
+
+// detachRequestCtx copies the request, response headers and user values of
+// ctx into a context that stays valid after the handler returns. The response
+// body is left out; recorders take it from the captured snapshot.
+func detachRequestCtx(ctx *fasthttp.RequestCtx) *fasthttp.RequestCtx {
+	detached := &fasthttp.RequestCtx{}
+	detached.Init(&ctx.Request, ctx.RemoteAddr(), nil)
+	ctx.Response.Header.CopyTo(&detached.Response.Header)
+	ctx.VisitUserValuesAll(func(key, value interface{}) {
+		detached.SetUserValue(key, value)
+	})
+	return detached
+}
+
+// recordingFrom is Recording with the engine looked up on every request, so
+// recording can be started and stopped on a running server
+func recordingFrom(current func() recorder.RecordingEngine, logger *zap.Logger) MiddlewareFunc {
 	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 		return func(ctx *fasthttp.RequestCtx) {
+			recordingEngine := current()
+
 			// Check if recording engine is enabled
 			if recordingEngine == nil || !recordingEngine.IsEnabled() {
 				next(ctx)
@@ -486,18 +1150,39 @@ func Recording(recordingEngine recorder.RecordingEngine, logger *zap.Logger) Mid
 			// Calculate request duration
 			duration := time.Since(startTime)
 			
-			// Get response body (make a copy since fasthttp reuses buffers)
-			responseBody := make([]byte, len(ctx.Response.Body()))
-			copy(responseBody, ctx.Response.Body())
+			// Drop the capture rather than buffer it when too many are in flight
+			release, ok := recordingEngine.AcquireCapture()
+			if !ok {
+				logger.Debug("Recording capture dropped, too many in flight",
+					zap.String("method", string(ctx.Method())),
+					zap.String("path", string(ctx.Path())))
+				return
+			}
 			
-			// Record the request/response in a goroutine to avoid blocking
+			// Get response body (make a copy since fasthttp reuses buffers).
+			// Streaming paths only record metadata.
+			var responseBody []byte
+			if !bodyCaptureDisabled(ctx) {
+				body, captured := capturedResponseBody(ctx)
+				if !captured {
+					body = append([]byte(nil), body...)
+				}
+				responseBody = body
+			}
+			
+			// Record the request/response in a goroutine to avoid blocking.
+			// fasthttp recycles ctx once the handler returns, so the
+			// goroutine works on a detached copy.
+			detached := detachRequestCtx(ctx)
 			go func() {
-				if err := recordingEngine.Record(ctx, responseBody, duration); err != nil {
+				defer release()
+				
+				if err := recordingEngine.Record(detached, responseBody, duration); err != nil {
 					logger.Error("Failed to record request",
 						zap.Error(err),
-						zap.String("method", string(ctx.Method())),
-						zap.String("path", string(ctx.Path())),
-						zap.Int("status", ctx.Response.StatusCode()))
+						zap.String("method", string(detached.Method())),
+						zap.String("path", string(detached.Path())),
+						zap.Int("status", detached.Response.StatusCode()))
 				}
 			}()
 		}
@@ -1,8 +1,14 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -11,11 +17,18 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
 	"go.uber.org/zap/zaptest/observer"
 
+	"vanta/pkg/chaos"
 	"vanta/pkg/config"
+	"vanta/pkg/openapi"
+	"vanta/pkg/plugins"
+	"vanta/pkg/recorder"
+	"vanta/pkg/tracing"
 )
 
 // Test helpers
@@ -548,7 +561,7 @@ func TestCORS_WildcardOrigin(t *testing.T) {
 func TestCORS_PreflightRequest(t *testing.T) {
 	cfg := &config.CORSConfig{
 		Enabled:          true,
-		AllowOrigins:     []string{"*"},
+		AllowOrigins:     []string{"https://example.com"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
 		AllowHeaders:     []string{"Content-Type", "Authorization"},
 		AllowCredentials: true,
@@ -563,7 +576,7 @@ func TestCORS_PreflightRequest(t *testing.T) {
 	wrappedHandler(ctx)
 
 	assert.Equal(t, fasthttp.StatusNoContent, ctx.Response.StatusCode())
-	assert.Equal(t, "*", string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
+	assert.Equal(t, "https://example.com", string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
 	assert.Equal(t, "GET, POST, PUT, DELETE", string(ctx.Response.Header.Peek("Access-Control-Allow-Methods")))
 	assert.Equal(t, "Content-Type, Authorization", string(ctx.Response.Header.Peek("Access-Control-Allow-Headers")))
 	assert.Equal(t, "true", string(ctx.Response.Header.Peek("Access-Control-Allow-Credentials")))
@@ -594,6 +607,98 @@ func TestCORS_Headers(t *testing.T) {
 	assert.Equal(t, "1800", string(ctx.Response.Header.Peek("Access-Control-Max-Age")))
 }
 
+func TestCORS_OriginPatternWithCredentials(t *testing.T) {
+	cfg := &config.CORSConfig{
+		Enabled:             true,
+		AllowOriginPatterns: []string{`^https://[a-z0-9-]+\.example\.com$`},
+		AllowCredentials:    true,
+	}
+	wrappedHandler := CORS(cfg)((&testHandler{statusCode: fasthttp.StatusOK}).handle)
+
+	ctx := createTestRequestCtx("GET", "/test", nil)
+	ctx.Request.Header.Set("Origin", "https://app.example.com")
+	wrappedHandler(ctx)
+
+	assert.Equal(t, "https://app.example.com", string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
+	assert.Equal(t, "true", string(ctx.Response.Header.Peek("Access-Control-Allow-Credentials")))
+	assert.Equal(t, "Origin", string(ctx.Response.Header.Peek("Vary")))
+
+	ctx = createTestRequestCtx("GET", "/test", nil)
+	ctx.Request.Header.Set("Origin", "https://app.example.com.attacker.com")
+	wrappedHandler(ctx)
+
+	assert.Empty(t, string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
+}
+
+func TestCORS_WildcardWithCredentialsRejected(t *testing.T) {
+	cfg := &config.CORSConfig{
+		Enabled:          true,
+		AllowOrigins:     []string{"*", "https://trusted.com"},
+		AllowCredentials: true,
+	}
+	wrappedHandler := CORS(cfg)((&testHandler{statusCode: fasthttp.StatusOK}).handle)
+
+	ctx := createTestRequestCtx("GET", "/test", nil)
+	ctx.Request.Header.Set("Origin", "https://any-domain.com")
+	wrappedHandler(ctx)
+
+	// Neither "*" nor the reflected origin: the wildcard is ignored with credentials
+	assert.Empty(t, string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
+	assert.Empty(t, string(ctx.Response.Header.Peek("Access-Control-Allow-Credentials")))
+
+	ctx = createTestRequestCtx("GET", "/test", nil)
+	ctx.Request.Header.Set("Origin", "https://trusted.com")
+	wrappedHandler(ctx)
+
+	assert.Equal(t, "https://trusted.com", string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
+
+	fullCfg := config.DefaultConfig()
+	fullCfg.Middleware.CORS = *cfg
+	var validationErrs config.ValidationErrors
+	require.ErrorAs(t, config.Validate(fullCfg), &validationErrs)
+	fields := make([]string, 0, len(validationErrs))
+	for _, validationErr := range validationErrs {
+		fields = append(fields, validationErr.Field)
+	}
+	assert.Contains(t, fields, "middleware.cors.allow_origins")
+}
+
+func TestCORS_MockResponsesKeepReflectedOrigin(t *testing.T) {
+	router, err := NewRouterWithGenerator(createFixedResponseSpec(), openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	cfg := &config.CORSConfig{
+		Enabled:          true,
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowCredentials: true,
+	}
+	handler := CORS(cfg)(router.Handler)
+
+	ctx := createTestRequestCtx("GET", "/users", nil)
+	ctx.Request.Header.Set("Origin", "https://app.example.com")
+	handler(ctx)
+
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "https://app.example.com", string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
+	assert.Equal(t, "true", string(ctx.Response.Header.Peek("Access-Control-Allow-Credentials")))
+	assert.Equal(t, "Origin", string(ctx.Response.Header.Peek("Vary")))
+
+	// A rejected origin must not be let in by the mock response either
+	ctx = createTestRequestCtx("GET", "/users", nil)
+	ctx.Request.Header.Set("Origin", "https://attacker.com")
+	handler(ctx)
+
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	assert.Empty(t, string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
+
+	// Nor by the router's OPTIONS answer to its preflight
+	ctx = createTestRequestCtx("OPTIONS", "/users", nil)
+	ctx.Request.Header.Set("Origin", "https://attacker.com")
+	ctx.Request.Header.Set("Access-Control-Request-Method", "POST")
+	handler(ctx)
+
+	assert.Empty(t, string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
+}
+
 // Timeout Middleware Tests
 func TestTimeout_Disabled(t *testing.T) {
 	cfg := &config.TimeoutConfig{Enabled: false}
@@ -692,8 +797,8 @@ func TestMetrics_RequestCounting(t *testing.T) {
 		wrappedHandler(ctx)
 	}
 
-	// Check metrics
-	assert.Equal(t, int64(3), collector.requestCounter["GET_/test_200"])
+	// Check metrics; no route matched, so they share the unmatched key
+	assert.Equal(t, int64(3), collector.requestCounter["GET_unmatched_200"])
 	metrics := collector.GetMetrics()
 	assert.Equal(t, int64(0), metrics["active_connections"]) // Should be 0 after completion
 }
@@ -708,9 +813,10 @@ func TestMetrics_LatencyMeasurement(t *testing.T) {
 
 	wrappedHandler(ctx)
 
-	latencies := collector.latencyHistogram["GET_/test"]
-	require.Len(t, latencies, 1)
-	assert.Greater(t, latencies[0], 40*time.Millisecond)
+	require.Contains(t, collector.latencyHistogram, "GET_unmatched")
+	assert.Equal(t, uint64(1), collector.latencyHistogram["GET_unmatched"].count)
+	p50, _, _ := collector.GetPercentiles("GET_unmatched")
+	assert.Greater(t, p50, 40*time.Millisecond)
 }
 
 func TestMetrics_StatusCodeTracking(t *testing.T) {
@@ -726,9 +832,69 @@ func TestMetrics_StatusCodeTracking(t *testing.T) {
 		wrappedHandler(ctx)
 	}
 
-	assert.Equal(t, int64(1), collector.requestCounter["GET_/test_200"])
-	assert.Equal(t, int64(1), collector.requestCounter["GET_/test_404"])
-	assert.Equal(t, int64(1), collector.requestCounter["GET_/test_500"])
+	assert.Equal(t, int64(1), collector.requestCounter["GET_unmatched_200"])
+	assert.Equal(t, int64(1), collector.requestCounter["GET_unmatched_404"])
+	assert.Equal(t, int64(1), collector.requestCounter["GET_unmatched_500"])
+}
+
+func TestMetrics_GroupsByRouteTemplate(t *testing.T) {
+	okResponse := map[string]openapi.Response{
+		"200": {
+			Description: "OK",
+			Content: map[string]openapi.MediaTypeObject{
+				"application/json": {Schema: &openapi.Schema{Type: "string"}},
+			},
+		},
+	}
+	spec := createTestSpec()
+	spec.Paths["/users/{id}"] = openapi.PathItem{GET: &openapi.Operation{OperationID: "getUser", Responses: okResponse}}
+
+	router, err := NewRouterWithGenerator(spec, openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	collector := NewDefaultMetricsCollector()
+	handler := Metrics(&config.MetricsConfig{Enabled: true}, collector)(router.Handler)
+	for _, path := range []string{"/users/1", "/users/2"} {
+		handler(createTestRequestCtx("GET", path, nil))
+	}
+
+	assert.Equal(t, int64(2), collector.requestCounter["GET_/users/{id}_200"])
+	assert.Equal(t, int64(2), collector.GetStatusClassCount("GET", "/users/{id}", "2xx"))
+	assert.NotContains(t, collector.requestCounter, "GET_/users/1_200")
+	assert.Equal(t, uint64(2), collector.latencyHistogram["GET_/users/{id}"].count)
+
+	// Paths no route serves share one key however many there are
+	for _, path := range []string{"/wp-login.php", "/.env", "/admin/config.php"} {
+		handler(createTestRequestCtx("GET", path, nil))
+	}
+	assert.Equal(t, int64(3), collector.requestCounter["GET_unmatched_404"])
+	assert.NotContains(t, collector.requestCounter, "GET_/.env_404")
+	assert.Len(t, collector.requestCounter, 2)
+}
+
+func TestMetricsCollector_StatusClassesAndErrorRate(t *testing.T) {
+	collector := NewDefaultMetricsCollector()
+	for _, status := range []int{200, 201, 404, 500, 503} {
+		collector.IncRequestCounter("GET", "/orders", status)
+	}
+
+	assert.Equal(t, int64(2), collector.GetStatusClassCount("GET", "/orders", "2xx"))
+	assert.Equal(t, int64(1), collector.GetStatusClassCount("GET", "/orders", "4xx"))
+	assert.Equal(t, int64(2), collector.GetStatusClassCount("GET", "/orders", "5xx"))
+	assert.InDelta(t, 0.4, collector.GetErrorRate("GET", "/orders"), 1e-9)
+	assert.Zero(t, collector.GetErrorRate("GET", "/missing"))
+
+	metrics := collector.GetMetrics()
+	assert.Equal(t, int64(2), metrics["status_class_counter"].(map[string]int64)["GET_/orders_5xx"])
+	assert.InDelta(t, 0.4, metrics["error_rates"].(map[string]float64)["GET_/orders"], 1e-9)
+
+	// The returned counters are copies the collector keeps updating apart from
+	requestCounter := metrics["request_counter"].(map[string]int64)
+	collector.IncRequestCounter("GET", "/orders", 500)
+	assert.Equal(t, int64(1), requestCounter["GET_/orders_500"])
+	assert.Equal(t, int64(2), metrics["status_class_counter"].(map[string]int64)["GET_/orders_5xx"])
+	requestCounter["GET_/orders_500"] = 100
+	assert.Equal(t, int64(2), collector.requestCounter["GET_/orders_500"])
 }
 
 func TestMetrics_NilCollector(t *testing.T) {
@@ -745,6 +911,336 @@ func TestMetrics_NilCollector(t *testing.T) {
 	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
 }
 
+// Body Capture Middleware Tests
+
+// countingReader records how much of a streamed request body was consumed
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+func createStreamingRequestCtx(method, path, body string) (*fasthttp.RequestCtx, *countingReader) {
+	ctx := createTestRequestCtx(method, path, nil)
+	reader := &countingReader{r: strings.NewReader(body)}
+	ctx.Request.Header.SetContentType("text/plain")
+	ctx.Request.SetBodyStream(reader, len(body))
+	return ctx, reader
+}
+
+func TestBodyCapture_StreamingPathNotBuffered(t *testing.T) {
+	logger, logs := createTestLogger()
+
+	manager := plugins.NewManager(logger)
+	require.NoError(t, plugins.RegisterBuiltinPlugins(manager.GetRegistry()))
+	require.NoError(t, manager.LoadFromConfig([]config.PluginConfig{
+		{
+			Name:    "logging",
+			Enabled: true,
+			Config: map[string]interface{}{
+				"log_request_body": true,
+				"include_metrics":  true,
+			},
+		},
+	}))
+
+	cfg := createTestConfig()
+	handler := NewStack(
+		BodyCapture([]string{"/uploads/*"}),
+		manager.CreateMiddlewareFunc(),
+		Logger(logger, &cfg.Logging),
+	).Apply(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusAccepted)
+	})
+
+	payload := strings.Repeat("chunk", 1000)
+
+	// Streaming path: neither the logging plugin nor the logger touch the body
+	ctx, reader := createStreamingRequestCtx("POST", "/uploads/video", payload)
+	handler(ctx)
+	assert.Equal(t, fasthttp.StatusAccepted, ctx.Response.StatusCode())
+	assert.Equal(t, 0, reader.read, "streamed upload should not be buffered")
+
+	pluginLogs := logs.FilterMessage("HTTP request").FilterField(zap.String("plugin", "logging")).All()
+	require.Len(t, pluginLogs, 1)
+	fields := pluginLogs[0].ContextMap()
+	assert.NotContains(t, fields, "body")
+	assert.Equal(t, int64(len(payload)), fields["content_length"])
+	assert.Equal(t, true, fields["body_capture_disabled"])
+
+	// Normal path: the body is still read and logged
+	ctx, reader = createStreamingRequestCtx("POST", "/users", "hello")
+	handler(ctx)
+	assert.Equal(t, len("hello"), reader.read)
+
+	pluginLogs = logs.FilterMessage("HTTP request").FilterField(zap.String("plugin", "logging")).All()
+	require.Len(t, pluginLogs, 2)
+	assert.Equal(t, "hello", pluginLogs[1].ContextMap()["body"])
+}
+
+func TestClientIP_TrustsForwardedHeadersOnlyFromProxies(t *testing.T) {
+	_, proxyNet, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		peer string
+		want string
+	}{
+		{"untrusted peer cannot spoof its address", "203.0.113.5", "203.0.113.5"},
+		{"trusted proxy forwards the client address", "10.0.0.2", "198.51.100.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := createTestLogger()
+			cfg := createTestConfig()
+			handler := NewStack(
+				ClientIP(plugins.TrustedProxies{Networks: []*net.IPNet{proxyNet}}),
+				Logger(logger, &cfg.Logging),
+			).Apply(func(ctx *fasthttp.RequestCtx) {
+				ctx.SetStatusCode(fasthttp.StatusOK)
+			})
+
+			ctx := createTestRequestCtx("GET", "/orders", nil)
+			ctx.SetRemoteAddr(&net.TCPAddr{IP: net.ParseIP(tt.peer), Port: 40000})
+			ctx.Request.Header.Set("X-Forwarded-For", "198.51.100.7")
+			handler(ctx)
+
+			assert.Equal(t, tt.want, ctx.UserValue(config.ClientIPKey))
+			require.Equal(t, 1, logs.Len())
+			assert.Equal(t, tt.want, logs.All()[0].ContextMap()["client_ip"])
+		})
+	}
+}
+
+func TestBodyCapture_PathMatching(t *testing.T) {
+	patterns := []string{"/uploads/*", "/stream"}
+
+	assert.True(t, matchesPathPattern("/uploads/a/b", patterns))
+	assert.True(t, matchesPathPattern("/stream", patterns))
+	assert.False(t, matchesPathPattern("/stream/more", patterns))
+	assert.False(t, matchesPathPattern("/users", patterns))
+}
+
+// Chaos Middleware Tests
+func TestChaos_ErrorInjectionShortCircuits(t *testing.T) {
+	logger, _ := createTestLogger()
+	engine := chaos.NewDefaultChaosEngine(logger)
+	err := engine.LoadScenarios([]config.ScenarioConfig{
+		{
+			Name:        "payments_unavailable",
+			Type:        "error",
+			Endpoints:   []string{"/api/payments"},
+			Methods:     []string{"POST"},
+			Probability: 1.0,
+			Parameters: map[string]interface{}{
+				"error_codes": []interface{}{503},
+				"custom_body": `{"error": "unavailable"}`,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	handlerCalls := 0
+	next := func(ctx *fasthttp.RequestCtx) {
+		handlerCalls++
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	}
+	wrapped := Chaos(engine, nil, logger)(next)
+
+	ctx := createTestRequestCtx("POST", "/api/payments", nil)
+	wrapped(ctx)
+	assert.Equal(t, fasthttp.StatusServiceUnavailable, ctx.Response.StatusCode())
+	assert.Equal(t, `{"error": "unavailable"}`, string(ctx.Response.Body()))
+	assert.Equal(t, 0, handlerCalls)
+
+	// Other methods on the same path reach the real handler
+	ctx = createTestRequestCtx("GET", "/api/payments", nil)
+	wrapped(ctx)
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, 1, handlerCalls)
+}
+
+func TestChaos_ConnectionResetClientSeesUnexpectedEOF(t *testing.T) {
+	logger, logs := createTestLogger()
+	engine := chaos.NewDefaultChaosEngine(logger)
+	err := engine.LoadScenarios([]config.ScenarioConfig{
+		{
+			Name:        "drop_orders",
+			Type:        "connection_reset",
+			Endpoints:   []string{"/api/orders"},
+			Probability: 1.0,
+			Parameters: map[string]interface{}{
+				"after_bytes": 5,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	handler := NewStack(RequestID(true), Chaos(engine, nil, logger)).Apply(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("application/json")
+		ctx.SetBodyString(`{"orders": [1, 2, 3]}`)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fasthttp.Server{Handler: handler}
+	go server.Serve(ln)
+	defer server.Shutdown()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get("http://" + ln.Addr().String() + "/api/orders")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, `{"ord`, string(body))
+
+	resetLogs := logs.FilterMessage("Injecting connection reset chaos").All()
+	require.Len(t, resetLogs, 1)
+	assert.Equal(t, resp.Header.Get("X-Request-ID"), resetLogs[0].ContextMap()["request_id"])
+	assert.NotEmpty(t, resp.Header.Get("X-Request-ID"))
+}
+
+func TestChaos_BodyCorruptionDesyncsContentLength(t *testing.T) {
+	logger, logs := createTestLogger()
+	engine := chaos.NewDefaultChaosEngine(logger)
+	err := engine.LoadScenarios([]config.ScenarioConfig{
+		{
+			Name:        "truncate_orders",
+			Type:        "body_corruption",
+			Endpoints:   []string{"/api/orders"},
+			Probability: 1.0,
+			Parameters: map[string]interface{}{
+				"mode":                  "truncate",
+				"truncate_bytes":        5,
+				"desync_content_length": true,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	handler := NewStack(RequestID(true), Chaos(engine, nil, logger)).Apply(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("application/json")
+		ctx.SetBodyString(`{"orders": [1, 2, 3]}`)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fasthttp.Server{Handler: handler}
+	go server.Serve(ln)
+	defer server.Shutdown()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get("http://" + ln.Addr().String() + "/api/orders")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// The original length is still advertised, so the client notices
+	assert.Equal(t, int64(len(`{"orders": [1, 2, 3]}`)), resp.ContentLength)
+	body, err := io.ReadAll(resp.Body)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, `{"ord`, string(body))
+
+	applied := logs.FilterMessage("Applied body corruption chaos").All()
+	require.Len(t, applied, 1)
+	assert.Equal(t, resp.Header.Get("X-Request-ID"), applied[0].ContextMap()["request_id"])
+}
+
+func TestChaos_HeaderTriggerLatency(t *testing.T) {
+	logger, logs := createTestLogger()
+	engine := chaos.NewDefaultChaosEngine(logger)
+	require.NoError(t, engine.LoadScenarios(nil))
+
+	handlerCalls := 0
+	wrapped := Chaos(engine, &config.ChaosConfig{Enabled: true, AllowHeaderTrigger: true}, logger)(func(ctx *fasthttp.RequestCtx) {
+		handlerCalls++
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	ctx := createTestRequestCtx("GET", "/api/users", nil)
+	ctx.Request.Header.Set("X-Chaos", "latency=50ms")
+
+	start := time.Now()
+	wrapped(ctx)
+	elapsed := time.Since(start)
+
+	assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, 1, handlerCalls)
+	assert.Equal(t, int64(1), engine.GetStats().ChaosApplied)
+
+	applied := logs.FilterMessage("Header-triggered chaos applied").All()
+	require.Len(t, applied, 1)
+	assert.Equal(t, "latency=50ms", applied[0].ContextMap()["trigger"])
+}
+
+func TestChaos_HeaderTriggerStatus(t *testing.T) {
+	logger, _ := createTestLogger()
+	engine := chaos.NewDefaultChaosEngine(logger)
+
+	// A scenario that never fires: the header bypasses its probability
+	require.NoError(t, engine.LoadScenarios([]config.ScenarioConfig{
+		{
+			Name:        "rare_latency",
+			Type:        "latency",
+			Endpoints:   []string{"/api/*"},
+			Probability: 0,
+			Parameters:  map[string]interface{}{"min_delay": "1s", "max_delay": "1s"},
+		},
+	}))
+
+	handlerCalls := 0
+	wrapped := Chaos(engine, &config.ChaosConfig{Enabled: true, AllowHeaderTrigger: true}, logger)(func(ctx *fasthttp.RequestCtx) {
+		handlerCalls++
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	ctx := createTestRequestCtx("POST", "/api/orders", nil)
+	ctx.Request.Header.Set("X-Chaos", "status=503")
+	wrapped(ctx)
+	assert.Equal(t, fasthttp.StatusServiceUnavailable, ctx.Response.StatusCode())
+	assert.Equal(t, 0, handlerCalls)
+
+	// Without the header the request is served normally
+	ctx = createTestRequestCtx("POST", "/api/orders", nil)
+	wrapped(ctx)
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, 1, handlerCalls)
+
+	// Invalid triggers are rejected rather than ignored
+	ctx = createTestRequestCtx("POST", "/api/orders", nil)
+	ctx.Request.Header.Set("X-Chaos", "status=200")
+	wrapped(ctx)
+	assert.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode())
+	assert.Contains(t, string(ctx.Response.Body()), "invalid_chaos_header")
+	assert.Equal(t, 1, handlerCalls)
+}
+
+func TestChaos_HeaderTriggerRequiresOptIn(t *testing.T) {
+	logger, _ := createTestLogger()
+	engine := chaos.NewDefaultChaosEngine(logger)
+	require.NoError(t, engine.LoadScenarios(nil))
+
+	wrapped := Chaos(engine, &config.ChaosConfig{Enabled: true}, logger)(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	ctx := createTestRequestCtx("GET", "/api/users", nil)
+	ctx.Request.Header.Set("X-Chaos", "status=503")
+	wrapped(ctx)
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, int64(0), engine.GetStats().ChaosApplied)
+}
+
 // DefaultMetricsCollector Tests
 func TestDefaultMetricsCollector_IncrementRequests(t *testing.T) {
 	collector := NewDefaultMetricsCollector()
@@ -764,14 +1260,16 @@ func TestDefaultMetricsCollector_RecordLatency(t *testing.T) {
 	collector.ObserveLatency("GET", "/api/users", 150*time.Millisecond)
 	collector.ObserveLatency("POST", "/api/users", 200*time.Millisecond)
 
-	getLatencies := collector.latencyHistogram["GET_/api/users"]
-	assert.Len(t, getLatencies, 2)
-	assert.Contains(t, getLatencies, 100*time.Millisecond)
-	assert.Contains(t, getLatencies, 150*time.Millisecond)
+	assert.Equal(t, uint64(2), collector.latencyHistogram["GET_/api/users"].count)
+	p50, _, p99 := collector.GetPercentiles("GET_/api/users")
+	assert.InEpsilon(t, float64(100*time.Millisecond), float64(p50), 0.05)
+	assert.Equal(t, 150*time.Millisecond, p99)
 
-	postLatencies := collector.latencyHistogram["POST_/api/users"]
-	assert.Len(t, postLatencies, 1)
-	assert.Contains(t, postLatencies, 200*time.Millisecond)
+	assert.Equal(t, uint64(1), collector.latencyHistogram["POST_/api/users"].count)
+	p50, p95, p99 := collector.GetPercentiles("POST_/api/users")
+	assert.Equal(t, 200*time.Millisecond, p50)
+	assert.Equal(t, 200*time.Millisecond, p95)
+	assert.Equal(t, 200*time.Millisecond, p99)
 }
 
 func TestDefaultMetricsCollector_ActiveConnections(t *testing.T) {
@@ -797,10 +1295,16 @@ func TestDefaultMetricsCollector_GetMetrics(t *testing.T) {
 	metrics := collector.GetMetrics()
 	require.Contains(t, metrics, "request_counter")
 	require.Contains(t, metrics, "active_connections")
-	require.Contains(t, metrics, "latency_count")
+	require.Contains(t, metrics, "latency_percentiles")
 
 	assert.Equal(t, int64(1), metrics["active_connections"])
-	assert.Equal(t, 1, metrics["latency_count"])
+	latencies := metrics["latency_percentiles"].(map[string]LatencyPercentiles)
+	assert.Equal(t, LatencyPercentiles{
+		Count: 1,
+		P50:   100 * time.Millisecond,
+		P95:   100 * time.Millisecond,
+		P99:   100 * time.Millisecond,
+	}, latencies["GET_/test"])
 
 	requestCounter := metrics["request_counter"].(map[string]int64)
 	assert.Equal(t, int64(1), requestCounter["GET_/test_200"])
@@ -832,8 +1336,7 @@ func TestDefaultMetricsCollector_ThreadSafety(t *testing.T) {
 	expectedRequests := int64(numGoroutines * numOperations)
 	assert.Equal(t, expectedRequests, collector.requestCounter["GET_/test_200"])
 	
-	latencies := collector.latencyHistogram["GET_/test"]
-	assert.Len(t, latencies, int(expectedRequests))
+	assert.Equal(t, uint64(expectedRequests), collector.latencyHistogram["GET_/test"].count)
 	
 	assert.Equal(t, int64(0), collector.activeConnections)
 }
@@ -877,8 +1380,8 @@ func TestMiddlewareStack_Integration(t *testing.T) {
 	assert.Equal(t, requestID, fields["request_id"])
 
 	// Verify Metrics
-	assert.Equal(t, int64(1), collector.requestCounter["POST_/api/integration_200"])
-	assert.Len(t, collector.latencyHistogram["POST_/api/integration"], 1)
+	assert.Equal(t, int64(1), collector.requestCounter["POST_unmatched_200"])
+	assert.Equal(t, uint64(1), collector.latencyHistogram["POST_unmatched"].count)
 }
 
 func TestMiddlewareStack_PanicRecovery(t *testing.T) {
@@ -965,4 +1468,518 @@ func BenchmarkFullStack(b *testing.B) {
 		ctx := createTestRequestCtx("GET", "/benchmark", nil)
 		wrappedHandler(ctx)
 	}
-}
\ No newline at end of file
+}
+func TestRecording_DropsCapturesUnderSaturation(t *testing.T) {
+	logger, _ := createTestLogger()
+	storage := recorder.NewMemoryStorage()
+	engine := recorder.NewDefaultRecordingEngine(storage, logger)
+	require.NoError(t, engine.Start(&config.RecordingConfig{
+		Enabled:               true,
+		MaxConcurrentCaptures: 1,
+	}))
+
+	handler := Recording(engine, logger)(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		ctx.SetBodyString(`{"ok":true}`)
+	})
+
+	// Occupy the only capture slot to simulate a slow in-flight capture
+	release, ok := engine.AcquireCapture()
+	require.True(t, ok)
+
+	for i := 0; i < 5; i++ {
+		ctx := createTestRequestCtx("GET", "/api/users", nil)
+		handler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+		assert.Equal(t, `{"ok":true}`, string(ctx.Response.Body()))
+	}
+
+	assert.Equal(t, int64(5), engine.GetStats().DroppedCaptures)
+	assert.Equal(t, int64(0), engine.GetStats().TotalRequests)
+
+	// Once the slot frees up captures resume
+	release()
+	ctx := createTestRequestCtx("GET", "/api/users", nil)
+	handler(ctx)
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+	assert.Eventually(t, func() bool {
+		return engine.GetStats().RecordedRequests == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(5), engine.GetStats().DroppedCaptures)
+}
+
+func TestResponseCapture_ConcurrentBodiesMatchOwnResponse(t *testing.T) {
+	logger, _ := createTestLogger()
+	storage := recorder.NewMemoryStorage()
+	engine := recorder.NewDefaultRecordingEngine(storage, logger)
+	require.NoError(t, engine.Start(&config.RecordingConfig{Enabled: true}))
+
+	stack := NewStack()
+	stack.Use(ETag(true))
+	stack.Use(Recording(engine, logger))
+	stack.Use(ResponseCapture())
+	handler := stack.Apply(func(ctx *fasthttp.RequestCtx) {
+		// Bodies of different lengths make reused buffers show up as corruption
+		id := string(ctx.QueryArgs().Peek("id"))
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		ctx.SetBodyString(`{"id":"` + id + `","pad":"` + strings.Repeat(id, len(id)*7) + `"}`)
+	})
+
+	ln := fasthttputil.NewInmemoryListener()
+	server := &fasthttp.Server{Handler: handler}
+	go server.Serve(ln) //nolint:errcheck
+	defer ln.Close()
+	client := &fasthttp.Client{Dial: func(addr string) (net.Conn, error) { return ln.Dial() }}
+
+	const requests = 200
+	bodies := make([]string, requests)
+	etags := make([]string, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := fasthttp.AcquireRequest()
+			defer fasthttp.ReleaseRequest(req)
+			resp := &fasthttp.Response{}
+			req.SetRequestURI(fmt.Sprintf("http://vanta/items?id=%d", i))
+			if assert.NoError(t, client.Do(req, resp)) {
+				bodies[i] = string(resp.Body())
+				etags[i] = string(resp.Header.Peek("ETag"))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.Eventually(t, func() bool {
+		return engine.GetStats().RecordedRequests == requests
+	}, 5*time.Second, 10*time.Millisecond)
+
+	recordings, err := storage.List(recorder.ListFilter{})
+	require.NoError(t, err)
+	require.Len(t, recordings, requests)
+	for _, recording := range recordings {
+		var i int
+		_, err := fmt.Sscanf(recording.Request.URI, "/items?id=%d", &i)
+		require.NoError(t, err)
+		assert.Equal(t, bodies[i], string(recording.Response.Body), "recording for request %d", i)
+	}
+	for i, body := range bodies {
+		sum := sha256.Sum256([]byte(body))
+		assert.Equal(t, `W/"`+hex.EncodeToString(sum[:16])+`"`, etags[i], "etag for request %d", i)
+	}
+}
+
+func TestResponseCapture_SkipsStreamingPaths(t *testing.T) {
+	handler := BodyCapture([]string{"/stream"})(ResponseCapture()(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString("payload")
+	}))
+
+	ctx := createTestRequestCtx("GET", "/users", nil)
+	handler(ctx)
+	body, captured := capturedResponseBody(ctx)
+	assert.True(t, captured)
+	assert.Equal(t, "payload", string(body))
+
+	ctx = createTestRequestCtx("GET", "/stream", nil)
+	handler(ctx)
+	_, captured = capturedResponseBody(ctx)
+	assert.False(t, captured)
+}
+
+// Compression Middleware Tests
+func TestCompression_GzipsLargeBody(t *testing.T) {
+	cfg := &config.CompressionConfig{Enabled: true, Level: 6, MinSize: 1024}
+	body := []byte(strings.Repeat(`{"id":1,"name":"compressible"},`, 200))
+	handler := &testHandler{statusCode: fasthttp.StatusOK, response: body}
+	wrappedHandler := Compression(cfg)(handler.handle)
+	ctx := createTestRequestCtx("GET", "/test", nil)
+	ctx.Request.Header.Set("Accept-Encoding", "gzip, deflate")
+
+	wrappedHandler(ctx)
+
+	assert.Equal(t, "gzip", string(ctx.Response.Header.Peek("Content-Encoding")))
+	assert.Equal(t, "Accept-Encoding", string(ctx.Response.Header.Peek("Vary")))
+	assert.Less(t, len(ctx.Response.Body()), len(body))
+
+	decoded, err := fasthttp.AppendGunzipBytes(nil, ctx.Response.Body())
+	require.NoError(t, err)
+	assert.Equal(t, body, decoded)
+}
+
+func TestCompression_Deflate(t *testing.T) {
+	cfg := &config.CompressionConfig{Enabled: true, Level: 6, MinSize: 10}
+	body := []byte(strings.Repeat("deflate me ", 100))
+	handler := &testHandler{statusCode: fasthttp.StatusOK, response: body}
+	wrappedHandler := Compression(cfg)(handler.handle)
+	ctx := createTestRequestCtx("GET", "/test", nil)
+	ctx.Request.Header.Set("Accept-Encoding", "deflate")
+
+	wrappedHandler(ctx)
+
+	assert.Equal(t, "deflate", string(ctx.Response.Header.Peek("Content-Encoding")))
+	decoded, err := fasthttp.AppendInflateBytes(nil, ctx.Response.Body())
+	require.NoError(t, err)
+	assert.Equal(t, body, decoded)
+}
+
+func TestCompression_LeavesResponseAlone(t *testing.T) {
+	cfg := &config.CompressionConfig{Enabled: true, Level: 6, MinSize: 1024}
+	large := []byte(strings.Repeat("a", 4096))
+
+	tests := []struct {
+		name           string
+		body           []byte
+		contentType    string
+		acceptEncoding string
+	}{
+		{name: "small body", body: []byte(`{"ok":true}`), contentType: "application/json", acceptEncoding: "gzip"},
+		{name: "client does not accept", body: large, contentType: "application/json", acceptEncoding: ""},
+		{name: "image", body: large, contentType: "image/png", acceptEncoding: "gzip"},
+		{name: "already gzipped", body: large, contentType: "application/gzip", acceptEncoding: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrappedHandler := Compression(cfg)(func(ctx *fasthttp.RequestCtx) {
+				ctx.SetContentType(tt.contentType)
+				ctx.SetBody(tt.body)
+			})
+			ctx := createTestRequestCtx("GET", "/test", nil)
+			if tt.acceptEncoding != "" {
+				ctx.Request.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+
+			wrappedHandler(ctx)
+
+			assert.Empty(t, string(ctx.Response.Header.Peek("Content-Encoding")))
+			assert.Equal(t, tt.body, ctx.Response.Body())
+		})
+	}
+}
+
+func TestCompression_Disabled(t *testing.T) {
+	cfg := &config.CompressionConfig{Enabled: false, Level: 6}
+	body := []byte(strings.Repeat("a", 4096))
+	handler := &testHandler{statusCode: fasthttp.StatusOK, response: body}
+	wrappedHandler := Compression(cfg)(handler.handle)
+	ctx := createTestRequestCtx("GET", "/test", nil)
+	ctx.Request.Header.Set("Accept-Encoding", "gzip")
+
+	wrappedHandler(ctx)
+
+	assert.Empty(t, string(ctx.Response.Header.Peek("Content-Encoding")))
+	assert.Equal(t, body, ctx.Response.Body())
+}
+
+// Body Limit Middleware Tests
+func TestBodyLimit_RejectsOversizedBody(t *testing.T) {
+	handler := &testHandler{statusCode: fasthttp.StatusOK}
+	called := false
+	wrappedHandler := BodyLimit(16)(func(ctx *fasthttp.RequestCtx) {
+		called = true
+		handler.handle(ctx)
+	})
+	ctx := createTestRequestCtx("POST", "/test", []byte(strings.Repeat("x", 17)))
+
+	wrappedHandler(ctx)
+
+	assert.False(t, called)
+	assert.Equal(t, fasthttp.StatusRequestEntityTooLarge, ctx.Response.StatusCode())
+	assert.Equal(t, "application/json", string(ctx.Response.Header.ContentType()))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(ctx.Response.Body(), &body))
+	assert.Equal(t, "Request body too large", body["error"])
+	assert.Equal(t, 16.0, body["max_body_bytes"])
+}
+
+func TestBodyLimit_AllowsBodyWithinLimit(t *testing.T) {
+	handler := &testHandler{statusCode: fasthttp.StatusCreated, response: []byte("ok")}
+	wrappedHandler := BodyLimit(16)(handler.handle)
+	ctx := createTestRequestCtx("POST", "/test", []byte(strings.Repeat("x", 16)))
+
+	wrappedHandler(ctx)
+
+	assert.Equal(t, fasthttp.StatusCreated, ctx.Response.StatusCode())
+	assert.Equal(t, "ok", string(ctx.Response.Body()))
+}
+
+func TestBodyLimit_ChecksDeclaredLengthOfStreamedBody(t *testing.T) {
+	wrappedHandler := BodyLimit(16)((&testHandler{statusCode: fasthttp.StatusOK}).handle)
+	ctx := createTestRequestCtx("POST", "/test", nil)
+	ctx.Request.SetBodyStream(strings.NewReader(strings.Repeat("x", 64)), 64)
+
+	wrappedHandler(ctx)
+
+	assert.Equal(t, fasthttp.StatusRequestEntityTooLarge, ctx.Response.StatusCode())
+}
+
+func TestBodyLimit_ReadsChunkedStreamOnlyUpToLimit(t *testing.T) {
+	handler := &testHandler{statusCode: fasthttp.StatusOK}
+	wrappedHandler := BodyLimit(16)(handler.handle)
+
+	// A chunked upload declares no length, so fasthttp's own limit never sees it
+	stream := &countingReader{r: strings.NewReader(strings.Repeat("x", 1<<20))}
+	ctx := createTestRequestCtx("POST", "/test", nil)
+	ctx.Request.SetBodyStream(stream, -1)
+
+	wrappedHandler(ctx)
+
+	assert.Equal(t, fasthttp.StatusRequestEntityTooLarge, ctx.Response.StatusCode())
+	assert.LessOrEqual(t, stream.read, 17, "the stream must not be read past the limit")
+
+	ctx = createTestRequestCtx("POST", "/test", nil)
+	ctx.Request.SetBodyStream(strings.NewReader("small"), -1)
+
+	wrappedHandler(ctx)
+
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "small", string(ctx.Request.Body()))
+}
+
+func TestBodyLimit_Disabled(t *testing.T) {
+	wrappedHandler := BodyLimit(0)((&testHandler{statusCode: fasthttp.StatusOK}).handle)
+	ctx := createTestRequestCtx("POST", "/test", []byte(strings.Repeat("x", 4096)))
+
+	wrappedHandler(ctx)
+
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+}
+
+// MaxInFlight Middleware Tests
+func TestMaxInFlight_RejectsWhenSaturated(t *testing.T) {
+	const limit = 3
+	collector := NewDefaultMetricsCollector()
+
+	started := make(chan struct{}, limit)
+	release := make(chan struct{})
+	slow := func(ctx *fasthttp.RequestCtx) {
+		started <- struct{}{}
+		<-release
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	}
+	gate := MaxInFlight(limit, collector)
+	wrappedHandler := gate(Metrics(&config.MetricsConfig{Enabled: true}, collector)(slow))
+
+	var wg sync.WaitGroup
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wrappedHandler(createTestRequestCtx("GET", "/slow", nil))
+		}()
+	}
+	for i := 0; i < limit; i++ {
+		<-started
+	}
+	assert.Equal(t, int64(limit), collector.ActiveConnections(), "admitted requests are counted once")
+
+	rejected := createTestRequestCtx("GET", "/slow", nil)
+	wrappedHandler(rejected)
+	assert.Equal(t, fasthttp.StatusServiceUnavailable, rejected.Response.StatusCode())
+	assert.Equal(t, "1", string(rejected.Response.Header.Peek("Retry-After")))
+	assert.JSONEq(t, `{"error": "Server at capacity", "max_in_flight": 3}`, string(rejected.Response.Body()))
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int64(0), collector.ActiveConnections())
+
+	// Freed slots admit new requests
+	admitted := createTestRequestCtx("GET", "/slow", nil)
+	wrappedHandler(admitted)
+	assert.Equal(t, fasthttp.StatusOK, admitted.Response.StatusCode())
+}
+
+func TestMaxInFlight_Disabled(t *testing.T) {
+	wrappedHandler := MaxInFlight(0, nil)((&testHandler{statusCode: fasthttp.StatusOK}).handle)
+	ctx := createTestRequestCtx("GET", "/test", nil)
+
+	wrappedHandler(ctx)
+
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+}
+
+// ETag Middleware Tests
+func TestETag_ConditionalRequest(t *testing.T) {
+	handler := &testHandler{statusCode: fasthttp.StatusOK, response: []byte(`{"id":1}`)}
+	wrappedHandler := ETag(true)(handler.handle)
+
+	first := createTestRequestCtx("GET", "/test", nil)
+	wrappedHandler(first)
+
+	assert.Equal(t, fasthttp.StatusOK, first.Response.StatusCode())
+	etag := string(first.Response.Header.Peek("ETag"))
+	require.NotEmpty(t, etag)
+	assert.True(t, strings.HasPrefix(etag, `W/"`) && strings.HasSuffix(etag, `"`), "weak ETag expected, got %s", etag)
+	assert.Equal(t, `{"id":1}`, string(first.Response.Body()))
+
+	second := createTestRequestCtx("GET", "/test", nil)
+	second.Request.Header.Set("If-None-Match", etag)
+	wrappedHandler(second)
+
+	assert.Equal(t, fasthttp.StatusNotModified, second.Response.StatusCode())
+	assert.Equal(t, etag, string(second.Response.Header.Peek("ETag")))
+	assert.Empty(t, second.Response.Body())
+}
+
+func TestETag_IfNoneMatch(t *testing.T) {
+	handler := &testHandler{statusCode: fasthttp.StatusOK, response: []byte(`{"id":1}`)}
+	wrappedHandler := ETag(true)(handler.handle)
+
+	probe := createTestRequestCtx("GET", "/test", nil)
+	wrappedHandler(probe)
+	etag := string(probe.Response.Header.Peek("ETag"))
+
+	tests := []struct {
+		ifNoneMatch string
+		status      int
+	}{
+		{ifNoneMatch: `"stale"`, status: fasthttp.StatusOK},
+		{ifNoneMatch: `"stale", ` + etag, status: fasthttp.StatusNotModified},
+		{ifNoneMatch: strings.TrimPrefix(etag, "W/"), status: fasthttp.StatusNotModified},
+		{ifNoneMatch: "*", status: fasthttp.StatusNotModified},
+	}
+
+	for _, tt := range tests {
+		ctx := createTestRequestCtx("GET", "/test", nil)
+		ctx.Request.Header.Set("If-None-Match", tt.ifNoneMatch)
+		wrappedHandler(ctx)
+		assert.Equal(t, tt.status, ctx.Response.StatusCode(), "If-None-Match: %s", tt.ifNoneMatch)
+	}
+}
+
+func TestETag_WeakAcrossEncodings(t *testing.T) {
+	handler := &testHandler{statusCode: fasthttp.StatusOK, response: []byte(strings.Repeat(`{"id":1}`, 32))}
+	compression := &config.CompressionConfig{Enabled: true, MinSize: 1, Level: 6}
+	wrappedHandler := NewStack(Compression(compression), ETag(true)).Apply(handler.handle)
+
+	identity := createTestRequestCtx("GET", "/test", nil)
+	wrappedHandler(identity)
+	gzipped := createTestRequestCtx("GET", "/test", nil)
+	gzipped.Request.Header.Set("Accept-Encoding", "gzip")
+	wrappedHandler(gzipped)
+
+	// The bodies differ, so the shared tag must not claim byte equality
+	require.Equal(t, "gzip", string(gzipped.Response.Header.Peek("Content-Encoding")))
+	etag := string(identity.Response.Header.Peek("ETag"))
+	assert.True(t, strings.HasPrefix(etag, `W/"`), "weak ETag expected, got %s", etag)
+	assert.Equal(t, etag, string(gzipped.Response.Header.Peek("ETag")))
+
+	revalidate := createTestRequestCtx("GET", "/test", nil)
+	revalidate.Request.Header.Set("Accept-Encoding", "gzip")
+	revalidate.Request.Header.Set("If-None-Match", etag)
+	wrappedHandler(revalidate)
+	assert.Equal(t, fasthttp.StatusNotModified, revalidate.Response.StatusCode())
+}
+
+func TestETag_IgnoresSnapshotAfterBodyCorruption(t *testing.T) {
+	logger, _ := createTestLogger()
+	engine := chaos.NewDefaultChaosEngine(logger)
+	err := engine.LoadScenarios([]config.ScenarioConfig{
+		{
+			Name:        "truncate_orders",
+			Type:        "body_corruption",
+			Endpoints:   []string{"/api/orders"},
+			Probability: 1.0,
+			Parameters:  map[string]interface{}{"mode": "truncate", "truncate_bytes": 5},
+		},
+	})
+	require.NoError(t, err)
+
+	original := `{"orders": [1, 2, 3]}`
+	handler := func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("application/json")
+		ctx.SetBodyString(original)
+	}
+	clean := NewStack(ETag(true), ResponseCapture()).Apply(handler)
+	corrupted := NewStack(ETag(true), Chaos(engine, nil, logger), ResponseCapture()).Apply(handler)
+
+	probe := createTestRequestCtx("GET", "/api/orders", nil)
+	clean(probe)
+	etag := string(probe.Response.Header.Peek("ETag"))
+	require.NotEmpty(t, etag)
+
+	// The client holds the ETag of the intact body; the corrupted one must not match it
+	ctx := createTestRequestCtx("GET", "/api/orders", nil)
+	ctx.Request.Header.Set("If-None-Match", etag)
+	corrupted(ctx)
+
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, `{"ord`, string(ctx.Response.Body()))
+	assert.NotEqual(t, etag, string(ctx.Response.Header.Peek("ETag")))
+}
+
+func TestETag_SkipsNonGetAndErrors(t *testing.T) {
+	post := createTestRequestCtx("POST", "/test", nil)
+	ETag(true)((&testHandler{statusCode: fasthttp.StatusCreated, response: []byte("created")}).handle)(post)
+	assert.Empty(t, post.Response.Header.Peek("ETag"))
+
+	notFound := createTestRequestCtx("GET", "/test", nil)
+	ETag(true)((&testHandler{statusCode: fasthttp.StatusNotFound, response: []byte("missing")}).handle)(notFound)
+	assert.Empty(t, notFound.Response.Header.Peek("ETag"))
+
+	disabled := createTestRequestCtx("GET", "/test", nil)
+	ETag(false)((&testHandler{statusCode: fasthttp.StatusOK, response: []byte("ok")}).handle)(disabled)
+	assert.Empty(t, disabled.Response.Header.Peek("ETag"))
+}
+
+// Tracing Middleware Tests
+func TestTracing_RequestSpanWithPluginChildren(t *testing.T) {
+	logger, _ := createTestLogger()
+	exporter := tracing.NewInMemoryExporter()
+	tracer := tracing.NewTracer("vanta-test", exporter)
+
+	manager := plugins.NewManager(logger)
+	defer manager.Shutdown()
+	manager.SetTracer(tracer)
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("example-middleware", plugins.NewExampleMiddlewarePlugin))
+	require.NoError(t, manager.LoadPlugin("example-middleware", map[string]interface{}{}))
+	require.NoError(t, manager.EnablePlugin("example-middleware"))
+
+	handler := NewStack(
+		RequestID(true),
+		Tracing(tracer),
+		manager.CreateMiddlewareFunc(),
+	).Apply((&testHandler{statusCode: fasthttp.StatusOK, response: []byte("ok")}).handle)
+
+	ctx := createTestRequestCtx("GET", "/users", nil)
+	ctx.Request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler(ctx)
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 3)
+
+	// The request span ends last and continues the incoming trace
+	requestSpan := spans[2]
+	assert.Equal(t, "GET /users", requestSpan.Name)
+	assert.Equal(t, tracing.SpanKindServer, requestSpan.Kind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", requestSpan.SpanContext.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", requestSpan.Parent.SpanID.String())
+	assert.Equal(t, string(ctx.Response.Header.Peek("X-Request-ID")), requestSpan.Attributes["request_id"])
+	assert.Equal(t, fasthttp.StatusOK, requestSpan.Attributes["http.response.status_code"])
+	assert.Greater(t, requestSpan.Duration(), time.Duration(0))
+
+	assert.Equal(t, "plugin.example-middleware.pre_process", spans[0].Name)
+	assert.Equal(t, "plugin.example-middleware.post_process", spans[1].Name)
+	for _, pluginSpan := range spans[:2] {
+		assert.Equal(t, requestSpan.SpanContext, pluginSpan.Parent, pluginSpan.Name)
+		assert.Equal(t, "example-middleware", pluginSpan.Attributes["plugin.name"])
+		assert.Equal(t, requestSpan.Attributes["request_id"], pluginSpan.Attributes["request_id"])
+	}
+}
+
+func TestTracing_ServerErrorMarksSpan(t *testing.T) {
+	exporter := tracing.NewInMemoryExporter()
+	handler := Tracing(tracing.NewTracer("vanta-test", exporter))(
+		(&testHandler{statusCode: fasthttp.StatusServiceUnavailable}).handle)
+
+	handler(createTestRequestCtx("GET", "/users", nil))
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, tracing.StatusError, spans[0].Status)
+	assert.False(t, spans[0].Parent.IsValid())
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"vanta/pkg/config"
+)
+
+// overridePlaceholder matches ${param} placeholders in override files
+var overridePlaceholder = regexp.MustCompile(`\$\{([A-Za-z0-9_.-]+)\}`)
+
+// responseOverride is a canned response loaded from an override file
+type responseOverride struct {
+	method      string
+	path        string
+	file        string
+	body        []byte
+	status      int
+	contentType string
+}
+
+// SetOverrides loads the configured override files, replacing any overrides
+// loaded before. Files are read once here; a reload re-reads them.
+func (r *Router) SetOverrides(overrides []config.ResponseOverrideConfig) error {
+	loaded := make([]*responseOverride, 0, len(overrides))
+	for _, cfg := range overrides {
+		body, err := os.ReadFile(cfg.File)
+		if err != nil {
+			return fmt.Errorf("failed to load override for %s %s: %w", cfg.Method, cfg.Path, err)
+		}
+
+		override := &responseOverride{
+			method:      strings.ToUpper(cfg.Method),
+			path:        cfg.Path,
+			file:        cfg.File,
+			body:        body,
+			status:      cfg.Status,
+			contentType: cfg.ContentType,
+		}
+		if override.status == 0 {
+			override.status = fasthttp.StatusOK
+		}
+		if override.contentType == "" {
+			override.contentType = "application/json"
+		}
+		loaded = append(loaded, override)
+	}
+
+	r.overridesMu.Lock()
+	defer r.overridesMu.Unlock()
+	r.overrides = loaded
+	return nil
+}
+
+// findOverride returns the override matching the request, preferring exact
+// paths over patterns. HEAD requests use GET overrides.
+func (r *Router) findOverride(method, path string) (*responseOverride, map[string]string, bool) {
+	r.overridesMu.RLock()
+	defer r.overridesMu.RUnlock()
+
+	if len(r.overrides) == 0 {
+		return nil, nil, false
+	}
+
+	methods := []string{method}
+	if method == fasthttp.MethodHead {
+		methods = append(methods, fasthttp.MethodGet)
+	}
+
+	for _, m := range methods {
+		for _, override := range r.overrides {
+			if override.method == m && override.path == path {
+				return override, nil, true
+			}
+		}
+		for _, override := range r.overrides {
+			if override.method != m {
+				continue
+			}
+			if params := r.matchPath(override.path, path); params != nil {
+				return override, params, true
+			}
+		}
+	}
+
+	return nil, nil, false
+}
+
+// writeOverride sends an override's body with its placeholders filled in
+func (r *Router) writeOverride(ctx *fasthttp.RequestCtx, override *responseOverride, params map[string]string) {
+	ctx.SetStatusCode(override.status)
+	ctx.SetContentType(override.contentType)
+	ctx.Response.Header.Set("X-Mock-Response", "true")
+	ctx.Response.Header.Set("X-Mock-Override", "true")
+	ctx.SetBody(renderOverride(override.body, params, ctx.QueryArgs()))
+
+	r.logger.Debug("Served response override",
+		zap.String("method", string(ctx.Method())),
+		zap.String("path", string(ctx.Path())),
+		zap.String("file", override.file),
+	)
+}
+
+// renderOverride replaces ${param} placeholders with path parameters, falling
+// back to query parameters. Unknown placeholders are left untouched.
+func renderOverride(body []byte, params map[string]string, query *fasthttp.Args) []byte {
+	return overridePlaceholder.ReplaceAllFunc(body, func(match []byte) []byte {
+		name := string(overridePlaceholder.FindSubmatch(match)[1])
+		if value, ok := params[name]; ok {
+			return []byte(value)
+		}
+		if query != nil && query.Has(name) {
+			return query.Peek(name)
+		}
+		return match
+	})
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"vanta/pkg/config"
+	"vanta/pkg/openapi"
+)
+
+func writeOverrideFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func newOverrideRouter(t *testing.T, overrides []config.ResponseOverrideConfig) *Router {
+	router, err := NewRouterWithGenerator(createThingsSpec(), openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, router.SetOverrides(overrides))
+	return router
+}
+
+func TestOverrides_ExactPath(t *testing.T) {
+	router := newOverrideRouter(t, []config.ResponseOverrideConfig{{
+		Method: "GET",
+		Path:   "/things",
+		File:   writeOverrideFile(t, "things.json", `[{"id":1,"name":"demo"}]`),
+	}})
+
+	ctx := createTestRequestCtx("GET", "/things", nil)
+	router.Handler(ctx)
+
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "application/json", string(ctx.Response.Header.ContentType()))
+	assert.JSONEq(t, `[{"id":1,"name":"demo"}]`, string(ctx.Response.Body()))
+
+	// Other methods on the same path still reach the generator
+	ctx = createTestRequestCtx("POST", "/things", []byte(`{}`))
+	router.Handler(ctx)
+	assert.Equal(t, 201, ctx.Response.StatusCode())
+	assert.Empty(t, ctx.Response.Header.Peek("X-Mock-Override"))
+}
+
+func TestOverrides_PathParameterSubstitution(t *testing.T) {
+	router := newOverrideRouter(t, []config.ResponseOverrideConfig{{
+		Method:      "get",
+		Path:        "/things/{id}",
+		File:        writeOverrideFile(t, "thing.xml", `<thing id="${id}" view="${view}" missing="${other}"/>`),
+		Status:      203,
+		ContentType: "application/xml",
+	}})
+
+	ctx := createTestRequestCtx("GET", "/things/42?view=full", nil)
+	router.Handler(ctx)
+
+	assert.Equal(t, 203, ctx.Response.StatusCode())
+	assert.Equal(t, "application/xml", string(ctx.Response.Header.ContentType()))
+	assert.Equal(t, `<thing id="42" view="full" missing="${other}"/>`, string(ctx.Response.Body()))
+	assert.Equal(t, "true", string(ctx.Response.Header.Peek("X-Mock-Override")))
+}
+
+func TestOverrides_ExactPathWinsOverPattern(t *testing.T) {
+	router := newOverrideRouter(t, []config.ResponseOverrideConfig{
+		{Method: "GET", Path: "/things/{id}", File: writeOverrideFile(t, "pattern.json", `{"source":"pattern"}`)},
+		{Method: "GET", Path: "/things/special", File: writeOverrideFile(t, "exact.json", `{"source":"exact"}`)},
+	})
+
+	ctx := createTestRequestCtx("GET", "/things/special", nil)
+	router.Handler(ctx)
+	assert.JSONEq(t, `{"source":"exact"}`, string(ctx.Response.Body()))
+}
+
+func TestOverrides_MissingFile(t *testing.T) {
+	router, err := NewRouterWithGenerator(createThingsSpec(), openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	err = router.SetOverrides([]config.ResponseOverrideConfig{{
+		Method: "GET",
+		Path:   "/things",
+		File:   filepath.Join(t.TempDir(), "missing.json"),
+	}})
+	assert.ErrorContains(t, err, "failed to load override for GET /things")
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"vanta/pkg/plugins"
+)
+
+// withProbes answers the health, readiness and route catalog paths, and the
+// documentation paths when enabled, before next, so plugins such as auth or
+// rate limiting never block them
+func (s *Server) withProbes(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if ctx.IsGet() || ctx.IsHead() {
+			switch path := string(ctx.Path()); {
+			case s.config.HealthPath != "" && path == s.config.HealthPath:
+				s.handleHealth(ctx)
+				return
+			case s.config.ReadyPath != "" && path == s.config.ReadyPath:
+				s.handleReady(ctx)
+				return
+			case s.config.RoutesPath != "" && path == s.config.RoutesPath:
+				s.handleRoutes(ctx)
+				return
+			case s.fullConfig.Mock.ServeDocs && isDocsPath(path):
+				s.handleDocs(ctx, path)
+				return
+			}
+		}
+		next(ctx)
+	}
+}
+
+// handleHealth reports liveness: the process is up and serving
+func (s *Server) handleHealth(ctx *fasthttp.RequestCtx) {
+	s.mu.RLock()
+	startTime := s.startTime
+	s.mu.RUnlock()
+
+	writeProbeResponse(ctx, fasthttp.StatusOK, map[string]interface{}{
+		"status":         "ok",
+		"uptime_seconds": uptimeSeconds(startTime),
+	})
+}
+
+// handleReady reports readiness: the server is running, every plugin enabled
+// in the configuration is enabled, and recording has started when configured
+func (s *Server) handleReady(ctx *fasthttp.RequestCtx) {
+	s.mu.RLock()
+	running := s.running
+	startTime := s.startTime
+	cfg := s.fullConfig
+	pluginsManager := s.pluginsManager
+	recordingEngine := s.recording.current()
+	s.mu.RUnlock()
+
+	ready := running
+
+	pluginStates := make(map[string]plugins.PluginState)
+	if pluginsManager != nil {
+		for _, info := range pluginsManager.ListPlugins() {
+			pluginStates[info.Name] = info.State
+		}
+	}
+	for _, pluginConfig := range cfg.Plugins {
+		if pluginConfig.Enabled && pluginStates[pluginConfig.Name] != plugins.StateEnabled {
+			ready = false
+		}
+	}
+
+	recording := "disabled"
+	if cfg.Recording.Enabled {
+		recording = "stopped"
+		if recordingEngine != nil && recordingEngine.IsEnabled() {
+			recording = "started"
+		} else {
+			ready = false
+		}
+	} else if recordingEngine != nil && recordingEngine.IsEnabled() {
+		// Started through the admin API
+		recording = "started"
+	}
+
+	status, statusCode := "ready", fasthttp.StatusOK
+	if !ready {
+		status, statusCode = "not_ready", fasthttp.StatusServiceUnavailable
+	}
+
+	writeProbeResponse(ctx, statusCode, map[string]interface{}{
+		"status":         status,
+		"running":        running,
+		"uptime_seconds": uptimeSeconds(startTime),
+		"plugins":        pluginStates,
+		"recording":      recording,
+	})
+}
+
+// uptimeSeconds returns the seconds elapsed since startTime, or 0 before start
+func uptimeSeconds(startTime time.Time) float64 {
+	if startTime.IsZero() {
+		return 0
+	}
+	return time.Since(startTime).Seconds()
+}
+
+func writeProbeResponse(ctx *fasthttp.RequestCtx, statusCode int, body map[string]interface{}) {
+	ctx.SetContentType("application/json")
+	ctx.Response.Header.Set("Cache-Control", "no-store")
+	ctx.SetStatusCode(statusCode)
+
+	responseBytes, _ := json.Marshal(body)
+	ctx.SetBody(responseBytes)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// prometheusLatencyBuckets are the upper bounds of the exported
+// http_request_duration_seconds buckets
+var prometheusLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// WritePrometheus writes the collected metrics in the Prometheus text
+// exposition format
+func (m *DefaultMetricsCollector) WritePrometheus(w io.Writer) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	fmt.Fprintln(w, "# HELP http_requests_total Total number of HTTP requests.")
+	fmt.Fprintln(w, "# TYPE http_requests_total counter")
+	for _, key := range sortedKeys(m.requestCounter) {
+		// Keys are METHOD_path_status; paths may themselves contain underscores
+		method, rest, _ := strings.Cut(key, "_")
+		separator := strings.LastIndex(rest, "_")
+		if separator < 0 {
+			continue
+		}
+		fmt.Fprintf(w, "http_requests_total{method=%s,path=%s,status=%s} %d\n",
+			promLabel(method), promLabel(rest[:separator]), promLabel(rest[separator+1:]), m.requestCounter[key])
+	}
+
+	fmt.Fprintln(w, "# HELP http_responses_by_class_total Total number of HTTP responses by status class.")
+	fmt.Fprintln(w, "# TYPE http_responses_by_class_total counter")
+	for _, key := range sortedKeys(m.statusClassCounter) {
+		// Keys are METHOD_path_class
+		method, rest, _ := strings.Cut(key, "_")
+		separator := strings.LastIndex(rest, "_")
+		if separator < 0 {
+			continue
+		}
+		fmt.Fprintf(w, "http_responses_by_class_total{method=%s,path=%s,class=%s} %d\n",
+			promLabel(method), promLabel(rest[:separator]), promLabel(rest[separator+1:]), m.statusClassCounter[key])
+	}
+
+	fmt.Fprintln(w, "# HELP http_request_duration_seconds HTTP request latency in seconds.")
+	fmt.Fprintln(w, "# TYPE http_request_duration_seconds histogram")
+	for _, key := range sortedKeys(m.latencyHistogram) {
+		histogram := m.latencyHistogram[key]
+		method, path, _ := strings.Cut(key, "_")
+		labels := fmt.Sprintf("method=%s,path=%s", promLabel(method), promLabel(path))
+
+		for _, bound := range prometheusLatencyBuckets {
+			fmt.Fprintf(w, "http_request_duration_seconds_bucket{%s,le=%q} %d\n",
+				labels, strconv.FormatFloat(bound.Seconds(), 'g', -1, 64), histogram.countAtMost(bound))
+		}
+		fmt.Fprintf(w, "http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, histogram.count)
+		fmt.Fprintf(w, "http_request_duration_seconds_sum{%s} %s\n",
+			labels, strconv.FormatFloat(histogram.sum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(w, "http_request_duration_seconds_count{%s} %d\n", labels, histogram.count)
+	}
+
+	fmt.Fprintln(w, "# HELP active_connections Number of requests currently being served.")
+	fmt.Fprintln(w, "# TYPE active_connections gauge")
+	fmt.Fprintf(w, "active_connections %d\n", m.activeConnections)
+}
+
+// promLabel quotes a label value, escaping what the exposition format requires
+func promLabel(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+// sortedKeys returns the keys of m in order so output is stable between scrapes
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
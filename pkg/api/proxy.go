@@ -0,0 +1,113 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"vanta/pkg/config"
+)
+
+// defaultProxyTimeout bounds upstream requests when recording.proxy_timeout is 0
+const defaultProxyTimeout = 30 * time.Second
+
+// recordingProxy forwards requests on the configured proxy paths to the
+// recording upstream so real backend traffic is returned and recorded
+type recordingProxy struct {
+	upstream    string
+	paths       []string
+	maxBodySize int64
+	client      *fasthttp.Client
+	logger      *zap.Logger
+}
+
+// newRecordingProxy builds the proxy described by cfg, or returns nil when
+// no upstream is configured
+func newRecordingProxy(cfg *config.RecordingConfig, logger *zap.Logger) (*recordingProxy, error) {
+	if cfg.Upstream == "" {
+		return nil, nil
+	}
+
+	upstream, err := url.Parse(cfg.Upstream)
+	if err != nil || (upstream.Scheme != "http" && upstream.Scheme != "https") || upstream.Host == "" {
+		return nil, fmt.Errorf("invalid recording upstream %q: must be an http or https URL", cfg.Upstream)
+	}
+
+	timeout := cfg.ProxyTimeout
+	if timeout <= 0 {
+		timeout = defaultProxyTimeout
+	}
+
+	return &recordingProxy{
+		upstream:    strings.TrimSuffix(upstream.String(), "/"),
+		paths:       cfg.ProxyPaths,
+		maxBodySize: cfg.MaxBodySize,
+		client: &fasthttp.Client{
+			ReadTimeout:  timeout,
+			WriteTimeout: timeout,
+			// Bodies above MaxBodySize, and chunked ones, are streamed
+			// through instead of buffered
+			MaxResponseBodySize:           int(cfg.MaxBodySize),
+			StreamResponseBody:            true,
+			DisableHeaderNamesNormalizing: true,
+			DisablePathNormalizing:        true,
+		},
+		logger: logger,
+	}, nil
+}
+
+// Handler serves matching paths from the upstream and hands the rest to next
+func (p *recordingProxy) Handler(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !matchesPathPattern(string(ctx.Path()), p.paths) {
+			next(ctx)
+			return
+		}
+		p.forward(ctx)
+	}
+}
+
+// forward sends the request to the upstream and copies the response back.
+// Responses larger than MaxBodySize are streamed to the client and recorded
+// without their body.
+func (p *recordingProxy) forward(ctx *fasthttp.RequestCtx) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	ctx.Request.Header.CopyTo(&req.Header)
+	req.SetRequestURI(p.upstream + string(ctx.RequestURI()))
+	req.Header.Del("Connection")
+	if bodyCaptureDisabled(ctx) {
+		req.SetBodyStream(ctx.RequestBodyStream(), ctx.Request.Header.ContentLength())
+	} else {
+		req.SetBody(ctx.Request.Body())
+	}
+
+	// Not pooled: a streamed body still reads through the response after
+	// this handler returns
+	resp := &fasthttp.Response{}
+	if err := p.client.Do(req, resp); err != nil {
+		p.logger.Warn("Recording upstream request failed",
+			zap.String("upstream", p.upstream),
+			zap.String("path", string(ctx.Path())),
+			zap.Error(err))
+		ctx.SetStatusCode(fasthttp.StatusBadGateway)
+		ctx.SetContentType("application/json")
+		ctx.SetBodyString(`{"error": "Upstream request failed"}`)
+		return
+	}
+
+	ctx.SetUserValue(config.ProxiedKey, true)
+	resp.Header.CopyTo(&ctx.Response.Header)
+
+	contentLength := resp.Header.ContentLength()
+	if contentLength < 0 || (p.maxBodySize > 0 && int64(contentLength) > p.maxBodySize) {
+		ctx.SetUserValue(config.BodyCaptureDisabledKey, true)
+		ctx.Response.SetBodyStream(resp.BodyStream(), contentLength)
+		return
+	}
+	ctx.Response.SetBody(resp.Body())
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"vanta/pkg/config"
+	"vanta/pkg/recorder"
+)
+
+func startProxyTestServer(t *testing.T, upstream string, maxBodySize int64) *Server {
+	cfg := newTestServerConfig()
+	cfg.Recording.Enabled = true
+	cfg.Recording.Storage.Directory = t.TempDir()
+	cfg.Recording.MaxBodySize = maxBodySize
+	cfg.Recording.Upstream = upstream
+	cfg.Recording.ProxyPaths = []string{"/api/*"}
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { assert.NoError(t, server.Stop()) })
+	return server
+}
+
+func proxyGet(t *testing.T, server *Server, path string) (*http.Response, string) {
+	resp, err := (&http.Client{Timeout: 2 * time.Second}).Get("http://" + server.ListenAddrs()[0] + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func waitForRecordings(t *testing.T, server *Server, count int) []*recorder.Recording {
+	var recordings []*recorder.Recording
+	require.Eventually(t, func() bool {
+		var err error
+		recordings, err = server.GetRecordingEngine().GetStorage().List(recorder.ListFilter{})
+		return err == nil && len(recordings) == count
+	}, 2*time.Second, 20*time.Millisecond)
+	return recordings
+}
+
+func TestRecordingProxy_ForwardsAndRecords(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Upstream", "real")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"path":"`+r.URL.Path+`","query":"`+r.URL.RawQuery+`"}`)
+	}))
+	defer upstream.Close()
+
+	server := startProxyTestServer(t, upstream.URL, 1024*1024)
+
+	resp, body := proxyGet(t, server, "/api/items?page=2")
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "real", resp.Header.Get("X-Upstream"))
+	assert.JSONEq(t, `{"path":"/api/items","query":"page=2"}`, body)
+
+	// Paths outside proxy_paths are still mocked
+	resp, body = proxyGet(t, server, "/users")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, "fixed response")
+
+	recordings := waitForRecordings(t, server, 2)
+	var proxied *recorder.Recording
+	for _, recording := range recordings {
+		if recording.Metadata.Source == "proxy" {
+			proxied = recording
+		}
+	}
+	require.NotNil(t, proxied)
+	assert.Equal(t, "/api/items?page=2", proxied.Request.URI)
+	assert.Equal(t, http.StatusCreated, proxied.Response.StatusCode)
+	assert.JSONEq(t, `{"path":"/api/items","query":"page=2"}`, string(proxied.Response.Body))
+}
+
+func TestRecordingProxy_StreamsLargeBodies(t *testing.T) {
+	large := strings.Repeat("x", 64*1024)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, large)
+	}))
+	defer upstream.Close()
+
+	server := startProxyTestServer(t, upstream.URL, 1024)
+
+	resp, body := proxyGet(t, server, "/api/download")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, large, body)
+
+	recordings := waitForRecordings(t, server, 1)
+	assert.True(t, recordings[0].Metadata.BodyOmitted)
+	assert.Empty(t, recordings[0].Response.Body)
+}
+
+func TestRecordingProxy_UpstreamDown(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstreamURL := upstream.URL
+	upstream.Close()
+
+	server := startProxyTestServer(t, upstreamURL, 1024)
+
+	resp, _ := proxyGet(t, server, "/api/items")
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
+
+func TestRecordingProxy_DisabledWithoutUpstream(t *testing.T) {
+	proxy, err := newRecordingProxy(&config.RecordingConfig{ProxyPaths: []string{"/api/*"}}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Nil(t, proxy)
+
+	_, err = newRecordingProxy(&config.RecordingConfig{Upstream: "ftp://example.com"}, zaptest.NewLogger(t))
+	assert.Error(t, err)
+}
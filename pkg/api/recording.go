@@ -0,0 +1,168 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	"vanta/pkg/config"
+	"vanta/pkg/recorder"
+)
+
+// recordingSlot holds the recording engine consulted by the recording
+// middleware, so the admin API can swap the engine of a running server.
+// A restart builds a new slot and hands the old one over to it.
+type recordingSlot struct {
+	mu     sync.RWMutex
+	engine recorder.RecordingEngine
+	config *config.RecordingConfig
+
+	// started is set when the engine was started through the admin API
+	// rather than by the configuration
+	started bool
+}
+
+// current returns the active engine, or nil when recording was never started
+func (r *recordingSlot) current() recorder.RecordingEngine {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.engine
+}
+
+// handOver takes over the engine of the slot a restart replaces. An engine
+// started through the admin API keeps recording unless the new
+// configuration brings its own; an engine that is not carried over is
+// stopped and its storage closed.
+func (r *recordingSlot) handOver(previous *recordingSlot, logger *zap.Logger) {
+	previous.mu.Lock()
+	engine, recordingCfg, started := previous.engine, previous.config, previous.started
+	previous.engine, previous.config, previous.started = nil, nil, false
+	previous.mu.Unlock()
+
+	if engine == nil {
+		return
+	}
+
+	r.mu.Lock()
+	if started && r.engine == nil {
+		r.engine, r.config, r.started = engine, recordingCfg, true
+		r.mu.Unlock()
+		return
+	}
+	r.mu.Unlock()
+
+	stopRecordingEngine(engine, logger)
+}
+
+// RecordingStartRequest overrides the configured recording settings when
+// recording is started on a running server. Zero values keep the
+// configuration; filters are added to the configured ones.
+type RecordingStartRequest struct {
+	Directory     string                   `json:"directory,omitempty"`
+	MaxRecordings int                      `json:"max_recordings,omitempty"`
+	MaxBodySize   int64                    `json:"max_body_size,omitempty"`
+	Filters       []config.RecordingFilter `json:"filters,omitempty"`
+}
+
+// RecordingStatus reports whether a running server is recording traffic
+type RecordingStatus struct {
+	Recording     bool                     `json:"recording"`
+	Directory     string                   `json:"directory,omitempty"`
+	MaxRecordings int                      `json:"max_recordings"`
+	MaxBodySize   int64                    `json:"max_body_size"`
+	Filters       int                      `json:"filters"`
+	Stats         *recorder.RecordingStats `json:"stats,omitempty"`
+}
+
+// StartRecording starts recording traffic on the running server with the
+// configured recording settings and the overrides in req. A recording
+// session that is already active is replaced.
+func (s *Server) StartRecording(req RecordingStartRequest) error {
+	s.mu.RLock()
+	recordingCfg := s.fullConfig.Recording
+	slot := s.recording
+	s.mu.RUnlock()
+
+	if req.Directory != "" {
+		recordingCfg.Storage.Directory = req.Directory
+	}
+	if req.MaxRecordings > 0 {
+		recordingCfg.MaxRecordings = req.MaxRecordings
+	}
+	if req.MaxBodySize > 0 {
+		recordingCfg.MaxBodySize = req.MaxBodySize
+	}
+	recordingCfg.Filters = append(append([]config.RecordingFilter(nil), recordingCfg.Filters...), req.Filters...)
+	recordingCfg.Enabled = true
+
+	storage, err := recorder.NewFileStorage(&recordingCfg.Storage, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create recording storage: %w", err)
+	}
+	engine := recorder.NewDefaultRecordingEngine(storage, s.logger)
+	if err := engine.Start(&recordingCfg); err != nil {
+		storage.Close()
+		return fmt.Errorf("failed to start recording engine: %w", err)
+	}
+
+	slot.mu.Lock()
+	previous := slot.engine
+	slot.engine = engine
+	slot.config = &recordingCfg
+	slot.started = true
+	slot.mu.Unlock()
+
+	if previous != nil {
+		stopRecordingEngine(previous, s.logger)
+	}
+	return nil
+}
+
+// StopRecording stops recording traffic on the running server. The stopped
+// engine is kept so its statistics remain visible in RecordingStatus.
+func (s *Server) StopRecording() error {
+	s.mu.RLock()
+	slot := s.recording
+	s.mu.RUnlock()
+
+	engine := slot.current()
+	if engine == nil || !engine.IsEnabled() {
+		return fmt.Errorf("recording is not active")
+	}
+	return engine.Stop()
+}
+
+// RecordingStatus reports the live recording state of the server
+func (s *Server) RecordingStatus() RecordingStatus {
+	s.mu.RLock()
+	slot := s.recording
+	s.mu.RUnlock()
+
+	slot.mu.RLock()
+	engine, recordingCfg := slot.engine, slot.config
+	slot.mu.RUnlock()
+
+	status := RecordingStatus{}
+	if engine == nil {
+		return status
+	}
+	status.Recording = engine.IsEnabled()
+	status.Stats = engine.GetStats()
+	if recordingCfg != nil {
+		status.Directory = recordingCfg.Storage.Directory
+		status.MaxRecordings = recordingCfg.MaxRecordings
+		status.MaxBodySize = recordingCfg.MaxBodySize
+		status.Filters = len(recordingCfg.Filters)
+	}
+	return status
+}
+
+// stopRecordingEngine stops a replaced engine and flushes its storage
+func stopRecordingEngine(engine recorder.RecordingEngine, logger *zap.Logger) {
+	if err := engine.Stop(); err != nil {
+		logger.Warn("Failed to stop recording engine", zap.Error(err))
+	}
+	if err := engine.GetStorage().Close(); err != nil {
+		logger.Warn("Failed to close recording storage", zap.Error(err))
+	}
+}
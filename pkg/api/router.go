@@ -3,10 +3,14 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
+	"vanta/pkg/config"
 	"vanta/pkg/openapi"
 )
 
@@ -16,11 +20,73 @@ type Router struct {
 	spec      *openapi.Specification
 	generator openapi.DataGenerator
 	logger    *zap.Logger
+
+	// Cold-start latency ramp support
+	latencyRamp *config.LatencyRampConfig
+	reloadedAt  time.Time
+	rampMu      sync.RWMutex
+	now         func() time.Time
+	sleep       func(time.Duration)
+
+	generationMetrics GenerationMetricsCollector
+
+	// In-memory CRUD store, set when stateful mode is enabled
+	store *ResourceStore
+
+	// Reject request bodies that violate the operation's schema
+	validateRequests bool
+
+	// Source of per-response key orders when key order randomization is enabled
+	keyOrderRand *rand.Rand
+	keyOrderMu   sync.Mutex
+
+	// Canned responses that bypass routing and generation
+	overrides   []*responseOverride
+	overridesMu sync.RWMutex
+
+	// Responses for requests no route serves
+	notFoundResponse         config.UnmatchedResponseConfig
+	methodNotAllowedResponse config.UnmatchedResponseConfig
+
+	// Methods the spec defines for each path, in Allow header order
+	allowed map[string][]string
 }
 
 // HandlerFunc represents a route handler function
 type HandlerFunc func(ctx *fasthttp.RequestCtx) error
 
+// PathParamsKey is the user value key holding the path parameters of the
+// matched route as a map[string]string
+const PathParamsKey = "path_params"
+
+// PathParams returns the path parameters of the route matched for the request,
+// or nil when no route has been matched yet
+func PathParams(ctx *fasthttp.RequestCtx) map[string]string {
+	params, _ := ctx.UserValue(PathParamsKey).(map[string]string)
+	return params
+}
+
+// RouteTemplateKey is the user value key holding the spec path of the matched
+// route, such as "/users/{id}"
+const RouteTemplateKey = "route_template"
+
+// RouteTemplate returns the spec path of the route matched for the request,
+// or "" when no route has been matched
+func RouteTemplate(ctx *fasthttp.RequestCtx) string {
+	template, _ := ctx.UserValue(RouteTemplateKey).(string)
+	return template
+}
+
+// setPathParams stores the matched route's path parameters on the request,
+// always as a non-nil map so handlers can tell a match without parameters
+// from no match at all
+func setPathParams(ctx *fasthttp.RequestCtx, params map[string]string) {
+	if params == nil {
+		params = map[string]string{}
+	}
+	ctx.SetUserValue(PathParamsKey, params)
+}
+
 // NewRouter creates a new router instance
 func NewRouter(spec *openapi.Specification, logger *zap.Logger) (*Router, error) {
 	if spec == nil {
@@ -34,7 +100,10 @@ func NewRouter(spec *openapi.Specification, logger *zap.Logger) (*Router, error)
 		routes: make(map[string]map[string]HandlerFunc),
 		spec:   spec,
 		logger: logger,
+		now:    time.Now,
+		sleep:  time.Sleep,
 	}
+	router.reloadedAt = router.now()
 
 	// Register routes from OpenAPI spec
 	if err := router.loadFromSpec(); err != nil {
@@ -61,7 +130,10 @@ func NewRouterWithGenerator(spec *openapi.Specification, generator openapi.DataG
 		spec:      spec,
 		generator: generator,
 		logger:    logger,
+		now:       time.Now,
+		sleep:     time.Sleep,
 	}
+	router.reloadedAt = router.now()
 
 	// Register routes from OpenAPI spec with generator
 	if err := router.loadFromSpecWithGenerator(); err != nil {
@@ -83,21 +155,88 @@ func (r *Router) Handler(ctx *fasthttp.RequestCtx) {
 		zap.String("remote_addr", ctx.RemoteAddr().String()),
 	)
 
+	// Canned override responses bypass routing and generation
+	if override, params, ok := r.findOverride(method, path); ok {
+		if method == fasthttp.MethodHead {
+			defer stripHeadBody(ctx)
+		}
+		setPathParams(ctx, params)
+		r.writeOverride(ctx, override, params)
+		return
+	}
+
 	// Find matching route
-	handler, params, found := r.findRoute(method, path)
+	handler, routePath, params, found := r.findRoute(method, path)
+	if !found && method == fasthttp.MethodHead {
+		// HEAD is answered by the GET route with the body stripped
+		handler, routePath, params, found = r.findRoute(fasthttp.MethodGet, path)
+	}
 	if !found {
+		if allowed := r.allowedMethods(path); len(allowed) > 0 {
+			if method == fasthttp.MethodOptions {
+				r.handleOptions(ctx, allowed)
+				return
+			}
+			r.handleMethodNotAllowed(ctx, allowed)
+			return
+		}
 		r.handleNotFound(ctx)
 		return
 	}
+	if method == fasthttp.MethodHead {
+		defer stripHeadBody(ctx)
+	}
 
-	// Set path parameters in context (if any)
+	// Simulate cold-cache slowness after a start or reload
+	if !strings.HasPrefix(path, "/__") {
+		if delay := r.RampLatency(); delay > 0 {
+			r.sleep(delay)
+		}
+	}
+
+	// Model the backend timing the spec declares for the operation
+	if delay := r.operationLatency(method, routePath); delay > 0 {
+		r.sleep(delay)
+	}
+
+	// Expose path parameters and the route template to middleware and handlers
+	setPathParams(ctx, params)
+	ctx.SetUserValue(RouteTemplateKey, routePath)
 	if len(params) > 0 {
-		// Store params for handler use (simplified for now)
 		r.logger.Debug("Path parameters found", zap.Any("params", params))
 	}
 
+	r.assignKeyOrderSeed(ctx)
+
+	// Check the request body against the operation's schema
+	if r.validateRequests && r.rejectInvalidRequest(ctx, method, routePath) {
+		return
+	}
+
+	// Serve collection and resource paths from the store in stateful mode,
+	// unless the client forced a specific response
+	if r.store != nil && !r.hasRequestedResponse(ctx, method, routePath) {
+		if route, ok := r.resolveStatefulRoute(routePath, path); ok {
+			handled, err := r.handleStateful(ctx, route)
+			if err != nil {
+				r.handleError(ctx, err)
+				return
+			}
+			if handled {
+				return
+			}
+		}
+	}
+
 	// Execute handler
-	if err := handler(ctx); err != nil {
+	err := handler(ctx)
+
+	// Record generation time separately from the overall processing time
+	if generation, ok := ctx.UserValue(GenerationDurationKey).(time.Duration); ok && r.generationMetrics != nil {
+		r.generationMetrics.ObserveGenerationDuration(method, routePath, generation)
+	}
+
+	if err != nil {
 		r.handleError(ctx, err)
 		return
 	}
@@ -109,8 +248,72 @@ func (r *Router) Handler(ctx *fasthttp.RequestCtx) {
 	)
 }
 
+// SetLatencyRamp configures the cold-start latency ramp
+func (r *Router) SetLatencyRamp(cfg *config.LatencyRampConfig) {
+	r.rampMu.Lock()
+	defer r.rampMu.Unlock()
+	r.latencyRamp = cfg
+}
+
+// SetRequestValidation enables or disables request body validation against the spec
+func (r *Router) SetRequestValidation(enabled bool) {
+	r.validateRequests = enabled
+}
+
+// SetUnmatchedResponses sets the responses sent when no route matches the
+// path and when the path only has routes for other methods. Empty fields
+// keep the defaults.
+func (r *Router) SetUnmatchedResponses(notFound, methodNotAllowed config.UnmatchedResponseConfig) {
+	r.notFoundResponse = notFound
+	r.methodNotAllowedResponse = methodNotAllowed
+}
+
+// SetGenerationMetrics configures where per-route generation durations are recorded
+func (r *Router) SetGenerationMetrics(collector GenerationMetricsCollector) {
+	r.generationMetrics = collector
+}
+
+// MarkReloaded records the time of the last start or reload, restarting the latency ramp
+func (r *Router) MarkReloaded() {
+	r.rampMu.Lock()
+	defer r.rampMu.Unlock()
+	r.reloadedAt = r.now()
+}
+
+// ReloadedAt returns the time of the last start or reload
+func (r *Router) ReloadedAt() time.Time {
+	r.rampMu.RLock()
+	defer r.rampMu.RUnlock()
+	return r.reloadedAt
+}
+
+// RampLatency returns the extra latency currently applied by the ramp.
+// The initial latency decays linearly to zero over the ramp duration.
+func (r *Router) RampLatency() time.Duration {
+	r.rampMu.RLock()
+	ramp := r.latencyRamp
+	reloadedAt := r.reloadedAt
+	r.rampMu.RUnlock()
+
+	if ramp == nil || !ramp.Enabled || ramp.InitialLatency <= 0 || ramp.Duration <= 0 {
+		return 0
+	}
+
+	elapsed := r.now().Sub(reloadedAt)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	if elapsed >= ramp.Duration {
+		return 0
+	}
+
+	remaining := float64(ramp.Duration-elapsed) / float64(ramp.Duration)
+	return time.Duration(float64(ramp.InitialLatency) * remaining)
+}
+
 // loadFromSpec loads routes from OpenAPI specification
 func (r *Router) loadFromSpec() error {
+	r.allowed = specAllowedMethods(r.spec)
 	for path, pathItem := range r.spec.Paths {
 		if pathItem.GET != nil {
 			r.registerRoute("GET", path, r.createMockHandler(path, "GET", pathItem.GET))
@@ -138,6 +341,7 @@ func (r *Router) loadFromSpec() error {
 
 // loadFromSpecWithGenerator loads routes from OpenAPI specification using the data generator
 func (r *Router) loadFromSpecWithGenerator() error {
+	r.allowed = specAllowedMethods(r.spec)
 	for path, pathItem := range r.spec.Paths {
 		if pathItem.GET != nil {
 			r.registerRoute("GET", path, MockHandler(r.spec, r.generator, r.logger))
@@ -169,6 +373,12 @@ func (r *Router) loadFromSpecWithGenerator() error {
 	return nil
 }
 
+// AddRoute registers an additional handler, such as an admin endpoint,
+// alongside the routes loaded from the specification
+func (r *Router) AddRoute(method, path string, handler HandlerFunc) {
+	r.registerRoute(method, path, handler)
+}
+
 // registerRoute registers a route with the router
 func (r *Router) registerRoute(method, path string, handler HandlerFunc) {
 	if r.routes[method] == nil {
@@ -182,26 +392,119 @@ func (r *Router) registerRoute(method, path string, handler HandlerFunc) {
 	)
 }
 
-// findRoute finds a matching route for the given method and path
-func (r *Router) findRoute(method, path string) (HandlerFunc, map[string]string, bool) {
+// rejectInvalidRequest validates the request body against the operation
+// registered for the route and responds with 400 when it does not conform
+func (r *Router) rejectInvalidRequest(ctx *fasthttp.RequestCtx, method, routePath string) bool {
+	pathItem, exists := r.spec.Paths[routePath]
+	if !exists {
+		return false
+	}
+	operation := getOperationFromPathItem(pathItem, method)
+	if operation == nil || operation.RequestBody == nil {
+		return false
+	}
+
+	validationErrors := openapi.ValidateRequestBody(operation.RequestBody,
+		string(ctx.Request.Header.ContentType()), ctx.PostBody(), r.spec.Schemas)
+	if len(validationErrors) == 0 {
+		return false
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusBadRequest)
+	ctx.SetContentType("application/json")
+	responseBytes, _ := json.Marshal(map[string]interface{}{
+		"error":   "Request validation failed",
+		"message": fmt.Sprintf("Request body does not match the schema for %s %s", method, routePath),
+		"errors":  validationErrors,
+	})
+	ctx.SetBody(responseBytes)
+
+	r.logger.Debug("Request body failed validation",
+		zap.String("method", method),
+		zap.String("path", routePath),
+		zap.Int("errors", len(validationErrors)),
+	)
+	return true
+}
+
+// hasRequestedResponse reports whether the request forces a response code
+// the route's operation defines
+func (r *Router) hasRequestedResponse(ctx *fasthttp.RequestCtx, method, routePath string) bool {
+	pathItem, exists := r.spec.Paths[routePath]
+	if !exists {
+		return false
+	}
+	_, requested := requestedResponseCode(ctx, getOperationFromPathItem(pathItem, method))
+	return requested
+}
+
+// operationLatency samples the x-mock-latency of the operation a route
+// serves, or returns 0 when it declares none
+func (r *Router) operationLatency(method, routePath string) time.Duration {
+	pathItem, exists := r.spec.Paths[routePath]
+	if !exists {
+		return 0
+	}
+	operation := getOperationFromPathItem(pathItem, method)
+	if operation == nil {
+		return 0
+	}
+	return operation.MockLatency.Sample()
+}
+
+// RequiresAuth reports whether the spec's security requirements demand
+// credentials for the operation serving method and path. known is false when
+// no operation in the spec serves the request.
+func (r *Router) RequiresAuth(method, path string) (required, known bool) {
+	_, routePath, _, found := r.findRoute(method, path)
+	if !found && method == fasthttp.MethodHead {
+		method = fasthttp.MethodGet
+		_, routePath, _, found = r.findRoute(method, path)
+	}
+	if !found {
+		return false, false
+	}
+	pathItem, exists := r.spec.Paths[routePath]
+	if !exists {
+		return false, false
+	}
+	operation := getOperationFromPathItem(pathItem, method)
+	if operation == nil {
+		return false, false
+	}
+	return r.spec.RequiresAuth(operation), true
+}
+
+// stripHeadBody drops the body of a HEAD response while keeping the
+// Content-Length the equivalent GET response would have sent
+func stripHeadBody(ctx *fasthttp.RequestCtx) {
+	length := len(ctx.Response.Body())
+	ctx.Response.SkipBody = true
+	ctx.Response.ResetBody()
+	ctx.Response.Header.SetContentLength(length)
+}
+
+// findRoute finds a matching route for the given method and path, returning
+// the registered route pattern alongside the handler
+func (r *Router) findRoute(method, path string) (HandlerFunc, string, map[string]string, bool) {
 	methodRoutes, exists := r.routes[method]
 	if !exists {
-		return nil, nil, false
+		return nil, "", nil, false
 	}
 
 	// Try exact match first
 	if handler, exists := methodRoutes[path]; exists {
-		return handler, nil, true
+		return handler, path, nil, true
 	}
 
 	// Try pattern matching for parameterized paths
 	for routePath, handler := range methodRoutes {
 		if params := r.matchPath(routePath, path); params != nil {
-			return handler, params, true
+			return handler, routePath, params, true
 		}
 	}
 
-	return nil, nil, false
+	return nil, "", nil, false
 }
 
 // matchPath matches a route path pattern against an actual path
@@ -270,17 +573,7 @@ func (r *Router) createMockHandler(path, method string, operation *openapi.Opera
 
 // handleNotFound handles 404 responses
 func (r *Router) handleNotFound(ctx *fasthttp.RequestCtx) {
-	ctx.SetStatusCode(fasthttp.StatusNotFound)
-	ctx.SetContentType("application/json")
-	
-	response := map[string]interface{}{
-		"error": "Not Found",
-		"message": fmt.Sprintf("Endpoint %s %s not found", 
-			string(ctx.Method()), string(ctx.Path())),
-	}
-
-	responseData, _ := json.Marshal(response)
-	ctx.SetBody(responseData)
+	writeUnmatchedResponse(ctx, r.notFoundResponse, fasthttp.StatusNotFound, "not_found")
 
 	r.logger.Warn("Route not found",
 		zap.String("method", string(ctx.Method())),
@@ -288,6 +581,104 @@ func (r *Router) handleNotFound(ctx *fasthttp.RequestCtx) {
 	)
 }
 
+// handleMethodNotAllowed answers a request for a known path with a method
+// none of its routes serve, listing the ones they do in Allow
+func (r *Router) handleMethodNotAllowed(ctx *fasthttp.RequestCtx, allowed []string) {
+	writeUnmatchedResponse(ctx, r.methodNotAllowedResponse, fasthttp.StatusMethodNotAllowed, "method_not_allowed")
+	ctx.Response.Header.Set("Allow", strings.Join(allowed, ", "))
+
+	r.logger.Warn("Method not allowed",
+		zap.String("method", string(ctx.Method())),
+		zap.String("path", string(ctx.Path())),
+		zap.Strings("allowed", allowed),
+	)
+}
+
+// writeUnmatchedResponse writes a configured unmatched response, filling
+// empty fields with the default status and a JSON error body
+func writeUnmatchedResponse(ctx *fasthttp.RequestCtx, response config.UnmatchedResponseConfig, status int, errorCode string) {
+	if response.Status != 0 {
+		status = response.Status
+	}
+	contentType := response.ContentType
+	body := response.Body
+	if body == "" {
+		body = fmt.Sprintf(`{"error":%q}`, errorCode)
+		if contentType == "" {
+			contentType = "application/json"
+		}
+	}
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+
+	ctx.SetStatusCode(status)
+	ctx.SetContentType(contentType)
+	ctx.SetBodyString(body)
+}
+
+// handleOptions answers OPTIONS on a known path with 204 and the allowed
+// methods. CORS preflights are answered by the CORS middleware or plugin
+// before they get here; the router never grants an origin itself.
+func (r *Router) handleOptions(ctx *fasthttp.RequestCtx, allowed []string) {
+	ctx.Response.Header.Set("Allow", strings.Join(allowed, ", "))
+	ctx.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+// allowedMethods returns the methods the spec defines across every path
+// pattern matching path, since any of them may serve the request, or nil when
+// no spec path matches. The order is fixed so the Allow header is stable.
+func (r *Router) allowedMethods(path string) []string {
+	defined := make(map[string]bool)
+	for pattern, methods := range r.allowed {
+		if pattern == path || r.matchPath(pattern, path) != nil {
+			for _, method := range methods {
+				defined[method] = true
+			}
+		}
+	}
+
+	var allowed []string
+	for _, method := range specMethods {
+		if defined[method] {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// specMethods are the methods a spec path can define, in Allow header order
+var specMethods = []string{
+	fasthttp.MethodGet,
+	fasthttp.MethodPost,
+	fasthttp.MethodPut,
+	fasthttp.MethodDelete,
+	fasthttp.MethodPatch,
+}
+
+// specAllowedMethods lists the methods each spec path defines
+func specAllowedMethods(spec *openapi.Specification) map[string][]string {
+	allowed := make(map[string][]string, len(spec.Paths))
+	for path, pathItem := range spec.Paths {
+		operations := []struct {
+			method    string
+			operation *openapi.Operation
+		}{
+			{fasthttp.MethodGet, pathItem.GET},
+			{fasthttp.MethodPost, pathItem.POST},
+			{fasthttp.MethodPut, pathItem.PUT},
+			{fasthttp.MethodDelete, pathItem.DELETE},
+			{fasthttp.MethodPatch, pathItem.PATCH},
+		}
+		for _, entry := range operations {
+			if entry.operation != nil {
+				allowed[path] = append(allowed[path], entry.method)
+			}
+		}
+	}
+	return allowed
+}
+
 // handleError handles error responses
 func (r *Router) handleError(ctx *fasthttp.RequestCtx, err error) {
 	ctx.SetStatusCode(fasthttp.StatusInternalServerError)
@@ -0,0 +1,663 @@
+package api
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"go.uber.org/zap/zaptest"
+
+	"vanta/pkg/config"
+	"vanta/pkg/openapi"
+	"vanta/pkg/plugins"
+)
+
+func createTestSpec() *openapi.Specification {
+	return &openapi.Specification{
+		Version: "3.0.0",
+		Info: openapi.InfoObject{
+			Title:   "Test API",
+			Version: "1.0.0",
+		},
+		Paths: map[string]openapi.PathItem{
+			"/users": {
+				GET: &openapi.Operation{
+					OperationID: "listUsers",
+					Responses: map[string]openapi.Response{
+						"200": {
+							Description: "OK",
+							Content: map[string]openapi.MediaTypeObject{
+								"application/json": {
+									Schema: &openapi.Schema{Type: "string"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// fakeClock drives the router's latency ramp without real sleeps
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(d time.Duration) { c.sleeps = append(c.sleeps, d) }
+
+func newRampRouter(t *testing.T, ramp *config.LatencyRampConfig) (*Router, *fakeClock) {
+	router, err := NewRouterWithGenerator(createTestSpec(), openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	router.now = clock.Now
+	router.sleep = clock.Sleep
+	router.SetLatencyRamp(ramp)
+	router.MarkReloaded()
+
+	return router, clock
+}
+
+func TestRouter_LatencyRampDecays(t *testing.T) {
+	router, clock := newRampRouter(t, &config.LatencyRampConfig{
+		Enabled:        true,
+		InitialLatency: 400 * time.Millisecond,
+		Duration:       10 * time.Second,
+	})
+
+	assert.Equal(t, 400*time.Millisecond, router.RampLatency())
+
+	clock.now = clock.now.Add(5 * time.Second)
+	assert.Equal(t, 200*time.Millisecond, router.RampLatency())
+
+	clock.now = clock.now.Add(5 * time.Second)
+	assert.Equal(t, time.Duration(0), router.RampLatency())
+
+	// A reload restarts the ramp
+	router.MarkReloaded()
+	assert.Equal(t, clock.now, router.ReloadedAt())
+	assert.Equal(t, 400*time.Millisecond, router.RampLatency())
+}
+
+func TestRouter_LatencyRampAppliedToRequests(t *testing.T) {
+	router, clock := newRampRouter(t, &config.LatencyRampConfig{
+		Enabled:        true,
+		InitialLatency: time.Second,
+		Duration:       time.Minute,
+	})
+
+	router.Handler(createTestRequestCtx("GET", "/users", nil))
+	require.Len(t, clock.sleeps, 1)
+	assert.Equal(t, time.Second, clock.sleeps[0])
+
+	clock.now = clock.now.Add(59 * time.Second)
+	router.Handler(createTestRequestCtx("GET", "/users", nil))
+	require.Len(t, clock.sleeps, 2)
+	assert.Less(t, clock.sleeps[1], clock.sleeps[0])
+	assert.LessOrEqual(t, clock.sleeps[1], 20*time.Millisecond)
+
+	// Past the ramp duration requests run at baseline
+	clock.now = clock.now.Add(time.Second)
+	router.Handler(createTestRequestCtx("GET", "/users", nil))
+	assert.Len(t, clock.sleeps, 2)
+
+	// Internal endpoints are never slowed down
+	router.MarkReloaded()
+	router.Handler(createTestRequestCtx("GET", "/__health", nil))
+	assert.Len(t, clock.sleeps, 2)
+}
+
+func TestRouter_LatencyRampDisabled(t *testing.T) {
+	router, clock := newRampRouter(t, &config.LatencyRampConfig{
+		Enabled:        false,
+		InitialLatency: time.Second,
+		Duration:       time.Minute,
+	})
+
+	router.Handler(createTestRequestCtx("GET", "/users", nil))
+	assert.Empty(t, clock.sleeps)
+	assert.Equal(t, time.Duration(0), router.RampLatency())
+}
+
+func TestRouter_OperationMockLatency(t *testing.T) {
+	spec := createTestSpec()
+	spec.Paths["/users"].GET.MockLatency = &openapi.LatencyRange{Min: 100 * time.Millisecond, Max: 100 * time.Millisecond}
+	router, err := NewRouterWithGenerator(spec, openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	for _, method := range []string{"GET", "HEAD"} {
+		ctx := createTestRequestCtx(method, "/users", nil)
+		start := time.Now()
+		router.Handler(ctx)
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, method)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode(), method)
+	}
+}
+
+func TestRouter_OperationWithoutMockLatency(t *testing.T) {
+	router, clock := newRampRouter(t, nil)
+
+	router.Handler(createTestRequestCtx("GET", "/users", nil))
+	assert.Empty(t, clock.sleeps)
+}
+
+func TestRouter_RecordsGenerationDuration(t *testing.T) {
+	logger, logs := createTestLogger()
+	router, err := NewRouterWithGenerator(createTestSpec(), openapi.NewDefaultDataGeneratorWithSeed(1), logger)
+	require.NoError(t, err)
+
+	collector := NewDefaultMetricsCollector()
+	router.SetGenerationMetrics(collector)
+
+	cfg := createTestConfig()
+	handler := Logger(logger, &cfg.Logging)(router.Handler)
+
+	ctx := createTestRequestCtx("GET", "/users", nil)
+	handler(ctx)
+	require.Equal(t, 200, ctx.Response.StatusCode())
+
+	percentiles := collector.GetGenerationPercentiles("GET", "/users")
+	require.Equal(t, uint64(1), percentiles.Count)
+	assert.Equal(t, 1, collector.GetMetrics()["generation_count"])
+
+	requestLogs := logs.FilterMessage("HTTP request").All()
+	require.Len(t, requestLogs, 1)
+	fields := requestLogs[0].ContextMap()
+
+	generation, ok := fields["generation_duration"].(time.Duration)
+	require.True(t, ok, "generation_duration should be logged")
+	total, ok := fields["duration"].(time.Duration)
+	require.True(t, ok)
+
+	assert.Equal(t, percentiles.P50, generation)
+	assert.Less(t, generation, total)
+}
+
+func TestRouter_NoGenerationDurationForInternalRoutes(t *testing.T) {
+	router, err := NewRouterWithGenerator(createTestSpec(), openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	collector := NewDefaultMetricsCollector()
+	router.SetGenerationMetrics(collector)
+
+	router.Handler(createTestRequestCtx("GET", "/__health", nil))
+	assert.Zero(t, collector.GetGenerationPercentiles("GET", "/__health").Count)
+}
+
+func TestRouter_RequestValidation(t *testing.T) {
+	spec := createThingsSpec()
+	spec.Paths["/things"].POST.RequestBody = &openapi.RequestBody{
+		Required: true,
+		Content: map[string]openapi.MediaTypeObject{
+			"application/json": {
+				Schema: &openapi.Schema{
+					Type:     "object",
+					Required: []string{"name"},
+					Properties: map[string]*openapi.Schema{
+						"name":  {Type: "string"},
+						"count": {Type: "integer"},
+					},
+				},
+			},
+		},
+	}
+
+	router, err := NewRouterWithGenerator(spec, openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	router.SetRequestValidation(true)
+
+	tests := []struct {
+		name       string
+		body       []byte
+		wantStatus int
+		wantErrors map[string]string
+	}{
+		{"valid body", []byte(`{"name":"widget","count":2}`), 201, nil},
+		{"missing required field", []byte(`{"count":2}`), 400, map[string]string{"name": "required"}},
+		{"wrong type", []byte(`{"name":"widget","count":"two"}`), 400, map[string]string{"count": "type"}},
+		{"empty required body", nil, 400, map[string]string{"body": "required"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := createTestRequestCtx("POST", "/things", tt.body)
+			ctx.Request.Header.SetContentType("application/json")
+			router.Handler(ctx)
+
+			require.Equal(t, tt.wantStatus, ctx.Response.StatusCode())
+			if tt.wantErrors == nil {
+				return
+			}
+
+			var response struct {
+				Error  string                           `json:"error"`
+				Errors []openapi.RequestValidationError `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(ctx.Response.Body(), &response))
+			assert.Equal(t, "Request validation failed", response.Error)
+
+			got := make(map[string]string)
+			for _, e := range response.Errors {
+				got[e.Field] = e.Rule
+			}
+			assert.Equal(t, tt.wantErrors, got)
+		})
+	}
+
+	// Validation is off by default
+	router.SetRequestValidation(false)
+	ctx := createTestRequestCtx("POST", "/things", []byte(`{"count":"two"}`))
+	router.Handler(ctx)
+	assert.Equal(t, 201, ctx.Response.StatusCode())
+}
+
+// createFixedResponseSpec returns the test spec with a constant /users
+// response, keeping GET and HEAD lengths comparable across requests
+func createFixedResponseSpec() *openapi.Specification {
+	spec := createTestSpec()
+	spec.Paths["/users"].GET.Responses["200"].Content["application/json"].Schema.Enum = []interface{}{"fixed response"}
+	return spec
+}
+
+func TestRouter_HeadMirrorsGetWithoutBody(t *testing.T) {
+	router, err := NewRouterWithGenerator(createFixedResponseSpec(), openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	get := createTestRequestCtx("GET", "/users", nil)
+	router.Handler(get)
+	require.Equal(t, 200, get.Response.StatusCode())
+	require.NotEmpty(t, get.Response.Body())
+
+	head := createTestRequestCtx("HEAD", "/users", nil)
+	router.Handler(head)
+	assert.Equal(t, 200, head.Response.StatusCode())
+	assert.Empty(t, head.Response.Body())
+	assert.Equal(t, len(get.Response.Body()), head.Response.Header.ContentLength())
+	assert.Equal(t, string(get.Response.Header.ContentType()), string(head.Response.Header.ContentType()))
+
+	missing := createTestRequestCtx("HEAD", "/missing", nil)
+	router.Handler(missing)
+	assert.Equal(t, 404, missing.Response.StatusCode())
+}
+
+func TestRouter_UnmatchedRequestsDefaults(t *testing.T) {
+	spec := createTestSpec()
+	spec.Paths["/users/{id}"] = openapi.PathItem{
+		DELETE: &openapi.Operation{Responses: map[string]openapi.Response{"204": {Description: "Deleted"}}},
+		PATCH:  &openapi.Operation{Responses: map[string]openapi.Response{"200": {Description: "Updated"}}},
+	}
+	router, err := NewRouterWithGenerator(spec, openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	// No route at all for the path
+	missing := createTestRequestCtx("GET", "/orders", nil)
+	router.Handler(missing)
+	assert.Equal(t, fasthttp.StatusNotFound, missing.Response.StatusCode())
+	assert.Equal(t, "application/json", string(missing.Response.Header.ContentType()))
+	assert.JSONEq(t, `{"error":"not_found"}`, string(missing.Response.Body()))
+	assert.Empty(t, missing.Response.Header.Peek("Allow"))
+
+	// The path exists, only under other methods
+	wrongMethod := createTestRequestCtx("DELETE", "/users", nil)
+	router.Handler(wrongMethod)
+	assert.Equal(t, fasthttp.StatusMethodNotAllowed, wrongMethod.Response.StatusCode())
+	assert.JSONEq(t, `{"error":"method_not_allowed"}`, string(wrongMethod.Response.Body()))
+	assert.Equal(t, "GET", string(wrongMethod.Response.Header.Peek("Allow")))
+
+	parameterized := createTestRequestCtx("PUT", "/users/42", nil)
+	router.Handler(parameterized)
+	assert.Equal(t, fasthttp.StatusMethodNotAllowed, parameterized.Response.StatusCode())
+	assert.Equal(t, "DELETE, PATCH", string(parameterized.Response.Header.Peek("Allow")))
+}
+
+func newAllowTestRouter(t *testing.T) *Router {
+	spec := createTestSpec()
+	users := spec.Paths["/users"]
+	users.POST = &openapi.Operation{Responses: map[string]openapi.Response{"201": {Description: "Created"}}}
+	spec.Paths["/users"] = users
+
+	router, err := NewRouterWithGenerator(spec, openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	return router
+}
+
+func TestRouter_AllowListsSpecMethods(t *testing.T) {
+	router := newAllowTestRouter(t)
+
+	for _, method := range []string{"PUT", "DELETE", "PATCH"} {
+		ctx := createTestRequestCtx(method, "/users", nil)
+		router.Handler(ctx)
+		assert.Equal(t, fasthttp.StatusMethodNotAllowed, ctx.Response.StatusCode(), method)
+		assert.Equal(t, "GET, POST", string(ctx.Response.Header.Peek("Allow")), method)
+	}
+}
+
+func TestRouter_AllowUnitesOverlappingPaths(t *testing.T) {
+	ok := map[string]openapi.Response{"200": {Description: "OK"}}
+	spec := createTestSpec()
+	spec.Paths["/users/me"] = openapi.PathItem{GET: &openapi.Operation{Responses: ok}}
+	spec.Paths["/users/{id}"] = openapi.PathItem{
+		DELETE: &openapi.Operation{Responses: ok},
+		PATCH:  &openapi.Operation{Responses: ok},
+	}
+	spec.Paths["/{collection}/{id}"] = openapi.PathItem{PUT: &openapi.Operation{Responses: ok}}
+
+	router, err := NewRouterWithGenerator(spec, openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	// /users/me matches three spec paths; the header lists every method any
+	// of them serves, the same way on every request
+	for i := 0; i < 50; i++ {
+		ctx := createTestRequestCtx("POST", "/users/me", nil)
+		router.Handler(ctx)
+		require.Equal(t, fasthttp.StatusMethodNotAllowed, ctx.Response.StatusCode())
+		require.Equal(t, "GET, PUT, DELETE, PATCH", string(ctx.Response.Header.Peek("Allow")))
+	}
+
+	// Each advertised method is served
+	for _, method := range []string{"GET", "PUT", "DELETE", "PATCH"} {
+		ctx := createTestRequestCtx(method, "/users/me", nil)
+		router.Handler(ctx)
+		assert.NotEqual(t, fasthttp.StatusMethodNotAllowed, ctx.Response.StatusCode(), method)
+	}
+}
+
+func TestRouter_OptionsAnswersWithAllow(t *testing.T) {
+	router := newAllowTestRouter(t)
+
+	// A bare OPTIONS is capability discovery
+	ctx := createTestRequestCtx("OPTIONS", "/users", nil)
+	router.Handler(ctx)
+	assert.Equal(t, fasthttp.StatusNoContent, ctx.Response.StatusCode())
+	assert.Equal(t, "GET, POST", string(ctx.Response.Header.Peek("Allow")))
+	assert.Empty(t, ctx.Response.Header.Peek("Access-Control-Allow-Origin"))
+	assert.Empty(t, ctx.Response.Body())
+
+	// Without CORS middleware a preflight is not granted any origin
+	preflight := createTestRequestCtx("OPTIONS", "/users", nil)
+	preflight.Request.Header.Set("Origin", "https://app.example.com")
+	preflight.Request.Header.Set("Access-Control-Request-Method", "POST")
+	router.Handler(preflight)
+	assert.Equal(t, fasthttp.StatusNoContent, preflight.Response.StatusCode())
+	assert.Equal(t, "GET, POST", string(preflight.Response.Header.Peek("Allow")))
+	assert.Empty(t, preflight.Response.Header.Peek("Access-Control-Allow-Origin"))
+	assert.Empty(t, preflight.Response.Header.Peek("Access-Control-Allow-Methods"))
+
+	// Unknown paths stay unknown
+	missing := createTestRequestCtx("OPTIONS", "/orders", nil)
+	router.Handler(missing)
+	assert.Equal(t, fasthttp.StatusNotFound, missing.Response.StatusCode())
+}
+
+func TestRouter_HeadOverHTTPRunsPlugins(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	router, err := NewRouterWithGenerator(createFixedResponseSpec(), openapi.NewDefaultDataGeneratorWithSeed(1), logger)
+	require.NoError(t, err)
+
+	manager := plugins.NewManager(logger)
+	defer manager.Shutdown()
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("example-middleware", plugins.NewExampleMiddlewarePlugin))
+	require.NoError(t, manager.LoadPlugin("example-middleware", map[string]interface{}{}))
+	require.NoError(t, manager.EnablePlugin("example-middleware"))
+
+	ln := fasthttputil.NewInmemoryListener()
+	server := &fasthttp.Server{Handler: manager.CreateMiddlewareFunc()(router.Handler)}
+	go server.Serve(ln) //nolint:errcheck
+	defer ln.Close()
+	client := &fasthttp.Client{Dial: func(addr string) (net.Conn, error) { return ln.Dial() }}
+
+	do := func(method string) *fasthttp.Response {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		resp := &fasthttp.Response{}
+		req.Header.SetMethod(method)
+		req.SetRequestURI("http://vanta/users")
+		require.NoError(t, client.Do(req, resp))
+		return resp
+	}
+
+	get := do("GET")
+	head := do("HEAD")
+
+	assert.Equal(t, 200, head.StatusCode())
+	assert.Empty(t, head.Body())
+	assert.Equal(t, len(get.Body()), head.Header.ContentLength())
+
+	breakdown := manager.GetLatencyBreakdown()
+	require.Len(t, breakdown.Plugins, 1)
+	assert.Equal(t, int64(2), breakdown.Plugins[0].PreProcess.Count, "plugins must run for HEAD requests")
+}
+
+func createMultiResponseSpec() *openapi.Specification {
+	errorSchema := &openapi.Schema{
+		Type:     "object",
+		Required: []string{"error"},
+		Properties: map[string]*openapi.Schema{
+			"error": {Type: "string", Enum: []interface{}{"not found"}},
+		},
+	}
+
+	return &openapi.Specification{
+		Version: "3.0.0",
+		Info:    openapi.InfoObject{Title: "Users API", Version: "1.0.0"},
+		Paths: map[string]openapi.PathItem{
+			"/users/{id}": {
+				GET: &openapi.Operation{
+					OperationID: "getUser",
+					Responses: map[string]openapi.Response{
+						"200": {
+							Description: "User",
+							Content: map[string]openapi.MediaTypeObject{
+								"application/json": {Schema: &openapi.Schema{
+									Type:       "object",
+									Required:   []string{"name"},
+									Properties: map[string]*openapi.Schema{"name": {Type: "string"}},
+								}},
+							},
+						},
+						"404": {
+							Description: "Not found",
+							Content: map[string]openapi.MediaTypeObject{
+								"application/json": {Schema: errorSchema},
+							},
+						},
+						"500": {
+							Description: "Server error",
+							Content: map[string]openapi.MediaTypeObject{
+								"application/json": {Example: map[string]interface{}{"error": "boom"}},
+							},
+						},
+						"503": {Description: "Unavailable"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRouter_RequestedResponseCode(t *testing.T) {
+	router, err := NewRouterWithGenerator(createMultiResponseSpec(), openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+		wantBody   map[string]interface{}
+	}{
+		{"prefer 404", map[string]string{"Prefer": "code=404"}, 404, map[string]interface{}{"error": "not found"}},
+		{"x-mock-status 500", map[string]string{"X-Mock-Status": "500"}, 500, map[string]interface{}{"error": "boom"}},
+		{"prefer alongside example", map[string]string{"Prefer": "example=none, code=404"}, 404, map[string]interface{}{"error": "not found"}},
+		{"undefined code falls back", map[string]string{"Prefer": "code=418"}, 200, nil},
+		{"no header", nil, 200, nil},
+		{"response without content", map[string]string{"X-Mock-Status": "503"}, 503, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := createTestRequestCtx("GET", "/users/7", nil)
+			for name, value := range tt.headers {
+				ctx.Request.Header.Set(name, value)
+			}
+			router.Handler(ctx)
+
+			require.Equal(t, tt.wantStatus, ctx.Response.StatusCode())
+			if tt.wantBody != nil {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(ctx.Response.Body(), &body))
+				assert.Equal(t, tt.wantBody, body)
+			}
+		})
+	}
+}
+
+func TestRouter_NamedExampleSelection(t *testing.T) {
+	spec := &openapi.Specification{
+		Version: "3.0.0",
+		Info:    openapi.InfoObject{Title: "Orders API", Version: "1.0.0"},
+		Paths: map[string]openapi.PathItem{
+			"/orders": {
+				GET: &openapi.Operation{
+					OperationID: "listOrders",
+					Responses: map[string]openapi.Response{
+						"200": {
+							Description: "Orders",
+							Content: map[string]openapi.MediaTypeObject{
+								"application/json": {Examples: map[string]*openapi.Example{
+									"empty": {Value: []interface{}{}},
+									"full":  {Value: []interface{}{"order-1", "order-2"}},
+								}},
+							},
+						},
+					},
+				},
+			},
+			"/status": {
+				GET: &openapi.Operation{
+					OperationID: "getStatus",
+					Responses: map[string]openapi.Response{
+						"200": {
+							Description: "Status",
+							Content: map[string]openapi.MediaTypeObject{
+								"application/json": {Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"ok"}}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	router, err := NewRouterWithGenerator(spec, openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		uri     string
+		headers map[string]string
+		want    string
+	}{
+		{"x-mock-example header", "/orders", map[string]string{"X-Mock-Example": "full"}, `["order-1","order-2"]`},
+		{"query parameter", "/orders?__example=full", nil, `["order-1","order-2"]`},
+		{"prefer header", "/orders", map[string]string{"Prefer": "example=full"}, `["order-1","order-2"]`},
+		{"unknown name falls back to first", "/orders", map[string]string{"X-Mock-Example": "missing"}, `[]`},
+		{"no name serves first", "/orders", nil, `[]`},
+		{"no examples generates", "/status", map[string]string{"X-Mock-Example": "full"}, `"ok"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := createTestRequestCtx("GET", tt.uri, nil)
+			for name, value := range tt.headers {
+				ctx.Request.Header.Set(name, value)
+			}
+			router.Handler(ctx)
+
+			require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+			assert.JSONEq(t, tt.want, string(ctx.Response.Body()))
+		})
+	}
+}
+
+func TestRouter_PathParams(t *testing.T) {
+	okResponse := map[string]openapi.Response{
+		"200": {
+			Description: "OK",
+			Content: map[string]openapi.MediaTypeObject{
+				"application/json": {Schema: &openapi.Schema{Type: "string"}},
+			},
+		},
+	}
+	spec := createTestSpec()
+	spec.Paths["/api/users/{id}"] = openapi.PathItem{GET: &openapi.Operation{OperationID: "getUser", Responses: okResponse}}
+	spec.Paths["/api/orgs/{org}/users/{id}"] = openapi.PathItem{GET: &openapi.Operation{OperationID: "getOrgUser", Responses: okResponse}}
+
+	router, err := NewRouterWithGenerator(spec, openapi.NewDefaultDataGeneratorWithSeed(1), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		path string
+		want map[string]string
+	}{
+		{"single parameter", "/api/users/42", map[string]string{"id": "42"}},
+		{"multiple parameters", "/api/orgs/acme/users/7", map[string]string{"org": "acme", "id": "7"}},
+		{"no parameters", "/users", map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := createTestRequestCtx("GET", tt.path, nil)
+			router.Handler(ctx)
+
+			require.Equal(t, 200, ctx.Response.StatusCode())
+			assert.Equal(t, tt.want, ctx.UserValue("path_params"))
+			assert.Equal(t, tt.want, PathParams(ctx))
+		})
+	}
+
+	t.Run("unmatched route", func(t *testing.T) {
+		ctx := createTestRequestCtx("GET", "/missing/1", nil)
+		router.Handler(ctx)
+
+		assert.Nil(t, PathParams(ctx))
+	})
+}
+
+func newDeterministicTestRouter(t *testing.T) *Router {
+	generator := openapi.NewDefaultDataGeneratorWithSeed(1)
+	generator.SetDeterministicPerRequest(true)
+	router, err := NewRouterWithGenerator(createWideObjectSpec(), generator, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	router.SetRandomizeKeyOrder(true, 1)
+	return router
+}
+
+func serveBody(router *Router, uri string) string {
+	ctx := createTestRequestCtx("GET", uri, nil)
+	router.Handler(ctx)
+	return string(ctx.Response.Body())
+}
+
+func TestRouter_DeterministicPerRequest(t *testing.T) {
+	router := newDeterministicTestRouter(t)
+
+	first := serveBody(router, "/wide?page=1")
+	other := serveBody(router, "/wide?page=2")
+	again := serveBody(router, "/wide?page=1")
+
+	assert.Equal(t, first, again, "identical requests must get byte-identical bodies")
+	assert.NotEqual(t, first, other, "requests with different queries must differ")
+
+	// A fresh generator with the same seed stands in for a restart
+	restarted := newDeterministicTestRouter(t)
+	assert.Equal(t, first, serveBody(restarted, "/wide?page=1"))
+}
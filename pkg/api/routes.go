@@ -0,0 +1,78 @@
+package api
+
+import (
+	"sort"
+
+	"github.com/valyala/fasthttp"
+	"vanta/pkg/openapi"
+)
+
+// RouteInfo describes one operation of the loaded specification
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	OperationID string   `json:"operation_id,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Responses   []string `json:"responses"`
+}
+
+// BuildRouteCatalog lists every operation in spec, sorted by path and then
+// method, with the response codes each one defines
+func BuildRouteCatalog(spec *openapi.Specification) []RouteInfo {
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	routes := make([]RouteInfo, 0, len(paths))
+	for _, path := range paths {
+		pathItem := spec.Paths[path]
+		operations := []struct {
+			method    string
+			operation *openapi.Operation
+		}{
+			{"GET", pathItem.GET},
+			{"POST", pathItem.POST},
+			{"PUT", pathItem.PUT},
+			{"PATCH", pathItem.PATCH},
+			{"DELETE", pathItem.DELETE},
+		}
+
+		for _, op := range operations {
+			if op.operation == nil {
+				continue
+			}
+
+			codes := make([]string, 0, len(op.operation.Responses))
+			for code := range op.operation.Responses {
+				codes = append(codes, code)
+			}
+			sort.Strings(codes)
+
+			routes = append(routes, RouteInfo{
+				Method:      op.method,
+				Path:        path,
+				OperationID: op.operation.OperationID,
+				Summary:     op.operation.Summary,
+				Responses:   codes,
+			})
+		}
+	}
+	return routes
+}
+
+// handleRoutes serves the route catalog of the loaded specification
+func (s *Server) handleRoutes(ctx *fasthttp.RequestCtx) {
+	s.mu.RLock()
+	spec := s.spec
+	s.mu.RUnlock()
+
+	routes := BuildRouteCatalog(spec)
+	writeProbeResponse(ctx, fasthttp.StatusOK, map[string]interface{}{
+		"title":   spec.Info.Title,
+		"version": spec.Info.Version,
+		"count":   len(routes),
+		"routes":  routes,
+	})
+}
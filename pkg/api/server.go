@@ -1,8 +1,15 @@
 package api
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
+	"os"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/valyala/fasthttp"
@@ -12,26 +19,48 @@ import (
 	"vanta/pkg/openapi"
 	"vanta/pkg/plugins"
 	"vanta/pkg/recorder"
+	"vanta/pkg/tracing"
 )
 
+// defaultShutdownTimeout bounds the drain when no shutdown timeout is configured
+const defaultShutdownTimeout = 30 * time.Second
+
 // Server represents the HTTP server
 type Server struct {
 	config           *config.ServerConfig
 	fullConfig       *config.Config  // Added for hot reload
 	router           *Router
 	server           *fasthttp.Server
+	handler          fasthttp.RequestHandler // middleware stack and router, without probes
 	logger           *zap.Logger
 	spec             *openapi.Specification
 	generator        openapi.DataGenerator
 	metricsCollector *DefaultMetricsCollector
 	chaosEngine      chaos.ChaosEngine
-	recordingEngine  recorder.RecordingEngine
+	recording        *recordingSlot
 	pluginsManager   *plugins.Manager
 	
 	// Hot reload support
 	mu       sync.RWMutex
 	running  bool
 	startTime time.Time
+
+	// Addresses actually bound by the running server, primary first
+	listenAddrs []string
+
+	// HTTPS configuration, nil when serving plain HTTP
+	tlsConfig *tls.Config
+
+	// Admin API listener, nil unless admin.enabled
+	adminServer *fasthttp.Server
+	adminAddr   string
+
+	// Metrics listener, nil unless metrics.enabled
+	metricsServer *fasthttp.Server
+	metricsAddr   string
+
+	// Span tracer, nil unless tracing.enabled
+	tracer *tracing.Tracer
 }
 
 // NewServer creates a new HTTP server instance
@@ -48,7 +77,7 @@ func NewServer(cfg *config.Config, spec *openapi.Specification, logger *zap.Logg
 
 	// Initialize data generator with mock configuration
 	var generator openapi.DataGenerator
-	if cfg.Mock.Seed != 0 {
+	if cfg.Mock.Seed != 0 || cfg.Mock.DeterministicPerRequest {
 		generator = openapi.NewDefaultDataGeneratorWithSeed(cfg.Mock.Seed)
 	} else {
 		generator = openapi.NewDefaultDataGenerator()
@@ -58,6 +87,12 @@ func NewServer(cfg *config.Config, spec *openapi.Specification, logger *zap.Logg
 	if cfg.Mock.Locale != "" {
 		generator.SetLocale(cfg.Mock.Locale)
 	}
+	if defaultGen, ok := generator.(*openapi.DefaultDataGenerator); ok {
+		defaultGen.SetPreferExamples(cfg.Mock.PreferExamples)
+		defaultGen.SetRandomExamples(cfg.Mock.ExampleSelection == "random")
+		defaultGen.SetNullableProbability(cfg.Mock.NullableProbability)
+		defaultGen.SetDeterministicPerRequest(cfg.Mock.DeterministicPerRequest)
+	}
 
 	// Create router with generator
 	router, err := NewRouterWithGenerator(spec, generator, logger)
@@ -65,19 +100,66 @@ func NewServer(cfg *config.Config, spec *openapi.Specification, logger *zap.Logg
 		return nil, fmt.Errorf("failed to create router: %w", err)
 	}
 
+	// Prepare HTTPS before starting engines so certificate problems fail early
+	var tlsConfig *tls.Config
+	if cfg.Server.TLS.Enabled {
+		tlsConfig, err = buildTLSConfig(&cfg.Server.TLS, cfg.Server.Host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure TLS: %w", err)
+		}
+		if cfg.Server.TLS.CertFile == "" {
+			logger.Warn("Serving HTTPS with a generated self-signed certificate")
+		}
+	}
+
+	// Configure cold-start latency ramp
+	if cfg.Mock.LatencyRamp.Enabled {
+		router.SetLatencyRamp(&cfg.Mock.LatencyRamp)
+	}
+
+	// Keep created resources in memory for collection/resource paths
+	if cfg.Mock.Stateful {
+		router.SetStateful(true)
+	}
+
+	// Reject request bodies that violate the spec
+	if cfg.Mock.ValidateRequests {
+		router.SetRequestValidation(true)
+	}
+
+	// Load canned responses pinned to specific endpoints
+	if len(cfg.Mock.Overrides) > 0 {
+		if err := router.SetOverrides(cfg.Mock.Overrides); err != nil {
+			return nil, fmt.Errorf("failed to load response overrides: %w", err)
+		}
+	}
+
+	// Consistent error envelope for requests no route serves
+	router.SetUnmatchedResponses(cfg.Mock.NotFoundResponse, cfg.Mock.MethodNotAllowedResponse)
+
+	// Shuffle JSON key order so clients cannot depend on it
+	if cfg.Mock.RandomizeKeyOrder {
+		router.SetRandomizeKeyOrder(true, cfg.Mock.Seed)
+	}
+
 	// Create metrics collector if enabled
 	var metricsCollector *DefaultMetricsCollector
 	if cfg.Metrics.Enabled {
 		metricsCollector = NewDefaultMetricsCollector()
+		router.SetGenerationMetrics(metricsCollector)
 	}
 
 	// Create chaos engine if enabled
 	var chaosEngine chaos.ChaosEngine
-	if cfg.Chaos.Enabled && len(cfg.Chaos.Scenarios) > 0 {
+	if cfg.Chaos.Enabled && (len(cfg.Chaos.Scenarios) > 0 || cfg.Chaos.AllowHeaderTrigger) {
 		chaosEngine = chaos.NewDefaultChaosEngine(logger)
 		if err := chaosEngine.LoadScenarios(cfg.Chaos.Scenarios); err != nil {
 			logger.Warn("Failed to load chaos scenarios", zap.Error(err))
-			chaosEngine = nil
+			// Keep the engine running with whichever scenarios did load, or
+			// for header triggers alone
+			if !chaosEngine.IsEnabled() && !cfg.Chaos.AllowHeaderTrigger {
+				chaosEngine = nil
+			}
 		}
 	}
 
@@ -96,6 +178,16 @@ func NewServer(cfg *config.Config, spec *openapi.Specification, logger *zap.Logg
 		}
 	}
 
+	// Forwarding headers are only believed from these peers
+	trustedProxyNetworks, err := cfg.Server.TrustedProxyNetworks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trusted proxies: %w", err)
+	}
+	trustedProxies := plugins.TrustedProxies{
+		Networks: trustedProxyNetworks,
+		Header:   cfg.Server.TrustedProxyHeader,
+	}
+
 	// Create and configure plugin manager
 	pluginsManager := plugins.NewManager(logger)
 	
@@ -111,25 +203,119 @@ func NewServer(cfg *config.Config, spec *openapi.Specification, logger *zap.Logg
 		return nil, fmt.Errorf("failed to register built-in plugins: %w", err)
 	}
 	
+	// Fail fast on plugin names nothing provides, such as typos
+	if cfg.Server.StrictPlugins {
+		registry := pluginsManager.GetRegistry()
+		if unknown := registry.UnknownPlugins(cfg.Plugins); len(unknown) > 0 {
+			return nil, fmt.Errorf("unknown plugins in configuration: %s (available: %s): %w",
+				strings.Join(unknown, ", "), strings.Join(registry.ListFactories(), ", "), plugins.ErrPluginNotFound)
+		}
+	}
+
 	// Load plugins from configuration
+	pluginsManager.SetStrictPriorities(cfg.PluginOptions.StrictPriorities)
+	pluginsManager.SetExecutionBudget(cfg.PluginOptions.ExecutionBudget)
+	pluginsManager.SetAsyncPostProcess(cfg.PluginOptions.AsyncWorkers, cfg.PluginOptions.AsyncQueueSize)
+	pluginsManager.SetAtomicLoad(cfg.PluginOptions.AtomicLoad)
+	pluginsManager.SetAllowedPlugins(cfg.PluginOptions.AllowedPlugins)
+	pluginsManager.SetDeniedPlugins(cfg.PluginOptions.DeniedPlugins)
+	pluginsManager.SetTrustedProxies(trustedProxies)
 	if len(cfg.Plugins) > 0 {
 		if err := pluginsManager.LoadFromConfig(cfg.Plugins); err != nil {
+			// Missing ${VAR:?message} variables, in strict mode ambiguous plugin
+			// priorities, forbidden plugins, and any failure in atomic mode are
+			// hard requirements
+			if cfg.PluginOptions.AtomicLoad || errors.Is(err, plugins.ErrRequiredEnvVarUnset) ||
+				errors.Is(err, plugins.ErrPriorityConflict) || errors.Is(err, plugins.ErrPluginNotPermitted) {
+				return nil, fmt.Errorf("failed to load plugins: %w", err)
+			}
 			logger.Warn("Failed to load plugins from configuration", zap.Error(err))
 			// Continue with server creation even if some plugins fail to load
 		}
 	}
 
+	// Export request and plugin spans when tracing is enabled
+	var tracer *tracing.Tracer
+	if cfg.Tracing.Enabled {
+		tracer = tracing.NewTracer(cfg.Tracing.ServiceName,
+			tracing.NewOTLPExporter(cfg.Tracing.Endpoint, cfg.Tracing.ServiceName, logger))
+		if pluginsManager != nil {
+			pluginsManager.SetTracer(tracer)
+		}
+	}
+
+	// The exporter runs from here on; stop it if creating the server fails
+	created := false
+	defer func() {
+		if tracer != nil && !created {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tracer.Shutdown(shutdownCtx); err != nil {
+				logger.Warn("Failed to stop trace exporter", zap.Error(err))
+			}
+		}
+	}()
+
 	// Create and configure middleware stack
 	stack := NewStack()
 
 	// Add middleware in proper order:
 	// Request ID → Auth → Rate Limit → CORS → Logger → Recovery → Chaos → Metrics → Recording → Logging
 	
+	// Bandwidth throttling streams the final response, so it wraps everything
+	if chaosEngine != nil {
+		stack.Use(ChaosBandwidth())
+	}
+
+	// Compression wraps everything else so logging, recording and plugins see
+	// the uncompressed body; it encodes the response on the way out
+	if cfg.Middleware.Compression.Enabled {
+		stack.Use(Compression(&cfg.Middleware.Compression))
+	}
+
+	// ETags hash the uncompressed body, so they sit inside compression and
+	// are weak: every encoding of a response shares the one tag
+	if cfg.Middleware.ETag {
+		stack.Use(ETag(true))
+	}
+
 	// 1. Request ID middleware (highest priority)
 	if cfg.Middleware.RequestID {
 		stack.Use(RequestID(true))
 	}
 
+	// Server span for the request; after request ID so the span carries it
+	if tracer != nil {
+		stack.Use(Tracing(tracer))
+	}
+
+	// Shed load once the in-flight limit is reached, before any work is done
+	if cfg.Server.MaxInFlight > 0 {
+		var collector MetricsCollector
+		if metricsCollector != nil {
+			collector = metricsCollector
+		}
+		stack.Use(MaxInFlight(cfg.Server.MaxInFlight, collector))
+	}
+
+	// Reject oversized bodies before anything reads them
+	stack.Use(BodyLimit(cfg.Middleware.MaxBodyBytes))
+
+	// Resolve the client address before anything logs or records it
+	stack.Use(ClientIP(trustedProxies))
+
+	// Fingerprint every request so logs, recordings and plugins share one key
+	stack.Use(Fingerprint())
+
+	// Mark streaming paths before any plugin can read the body
+	if len(cfg.Server.StreamingPaths) > 0 {
+		stack.Use(BodyCapture(cfg.Server.StreamingPaths))
+	}
+
+	// Mark operations the spec declares public for the auth plugin. Always
+	// installed since the plugin may be loaded later through the admin API.
+	stack.Use(SpecSecurity(router))
+
 	// 2. Plugin middleware (Auth, Rate Limit, CORS plugins with priority ordering)
 	if pluginsManager != nil {
 		stack.Use(pluginsManager.CreateMiddlewareFunc())
@@ -159,7 +345,7 @@ func NewServer(cfg *config.Config, spec *openapi.Specification, logger *zap.Logg
 
 	// 7. Chaos middleware (before metrics to capture chaos effects)
 	if chaosEngine != nil {
-		stack.Use(Chaos(chaosEngine, logger))
+		stack.Use(Chaos(chaosEngine, &cfg.Chaos, logger))
 	}
 
 	// 8. Metrics middleware
@@ -167,23 +353,57 @@ func NewServer(cfg *config.Config, spec *openapi.Specification, logger *zap.Logg
 		stack.Use(Metrics(&cfg.Metrics, metricsCollector))
 	}
 
-	// 9. Recording middleware (after metrics to capture complete response)
+	// 9. Recording middleware (after metrics to capture complete response).
+	// Always installed so the admin API can start recording later.
+	recording := &recordingSlot{engine: recordingEngine}
 	if recordingEngine != nil {
-		stack.Use(Recording(recordingEngine, logger))
+		recording.config = &cfg.Recording
+	}
+	stack.Use(recordingFrom(recording.current, logger))
+
+	// 10. Snapshot the response body once for logging, recording and ETags;
+	// innermost so it sees the handler's response first
+	stack.Use(ResponseCapture())
+
+	// Forward the recording proxy paths to the upstream instead of the mock
+	handler := router.Handler
+	proxy, err := newRecordingProxy(&cfg.Recording, logger)
+	if err != nil {
+		return nil, err
+	}
+	if proxy != nil {
+		handler = proxy.Handler(handler)
 	}
 
 	// Apply middleware stack to router
-	finalHandler := stack.Apply(router.Handler)
+	finalHandler := stack.Apply(handler)
 
-	// Create FastHTTP server with configuration
+	// Let fasthttp refuse oversized bodies before buffering them, using the
+	// tighter of the server and middleware limits
+	maxRequestBodySize := fasthttp.DefaultMaxRequestBodySize
+	serverLimit := cfg.Server.MaxRequestBodyBytes()
+	if serverLimit > 0 {
+		maxRequestBodySize = int(serverLimit)
+	}
+	if limit := cfg.Middleware.MaxBodyBytes; limit > 0 && (serverLimit <= 0 || limit < serverLimit) {
+		maxRequestBodySize = int(limit)
+	}
+
+	// Create FastHTTP server with configuration; the handler is set below once
+	// the health and readiness probes can reference the server
 	server := &fasthttp.Server{
-		Handler:               finalHandler,
 		ReadTimeout:           cfg.Server.ReadTimeout,
 		WriteTimeout:          cfg.Server.WriteTimeout,
 		MaxConnsPerIP:         cfg.Server.MaxConnsPerIP,
 		Concurrency:          cfg.Server.Concurrency,
-		DisableKeepalive:     false,
+		IdleTimeout:          cfg.Server.IdleTimeout,
+		DisableKeepalive:     cfg.Server.DisableKeepalive,
+		TCPKeepalive:         cfg.Server.TCPKeepalive,
+		TCPKeepalivePeriod:   cfg.Server.TCPKeepalivePeriod,
+		MaxRequestsPerConn:   cfg.Server.MaxRequestsPerConn,
 		DisablePreParseMultipartForm: false,
+		MaxRequestBodySize:   maxRequestBodySize,
+		StreamRequestBody:    len(cfg.Server.StreamingPaths) > 0,
 		LogAllErrors:         false,
 		ErrorHandler: func(ctx *fasthttp.RequestCtx, err error) {
 			logger.Error("FastHTTP error", 
@@ -191,22 +411,32 @@ func NewServer(cfg *config.Config, spec *openapi.Specification, logger *zap.Logg
 				zap.String("path", string(ctx.Path())),
 				zap.String("method", string(ctx.Method())),
 			)
+			if errors.Is(err, fasthttp.ErrBodyTooLarge) {
+				writeBodyTooLarge(ctx, int64(maxRequestBodySize))
+			}
 		},
 	}
 
-	return &Server{
+	s := &Server{
 		config:           &cfg.Server,
 		fullConfig:       cfg,  // Store full config for hot reload
 		router:           router,
 		server:           server,
+		handler:          finalHandler,
 		logger:           logger,
 		spec:             spec,
 		generator:        generator,
 		metricsCollector: metricsCollector,
+		tracer:           tracer,
 		chaosEngine:      chaosEngine,
-		recordingEngine:  recordingEngine,
+		recording:        recording,
 		pluginsManager:   pluginsManager,
-	}, nil
+		tlsConfig:        tlsConfig,
+	}
+	server.Handler = s.withProbes(finalHandler)
+
+	created = true
+	return s, nil
 }
 
 // Start starts the HTTP server
@@ -221,26 +451,59 @@ func (s *Server) Start() error {
 	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
 	
 	s.logger.Info("Starting HTTP server",
-		zap.String("address", addr),
+		zap.String("address", s.GetAddr()),
+		zap.String("url", s.GetURL()),
 		zap.Int("concurrency", s.config.Concurrency),
 		zap.Duration("read_timeout", s.config.ReadTimeout),
 		zap.Duration("write_timeout", s.config.WriteTimeout),
 	)
 
+	// Bind every address up front so a failure is reported before serving
+	listeners, err := s.listen(addr)
+	if err != nil {
+		return err
+	}
+	err = s.startAdmin()
+	if err == nil {
+		if err = s.startMetrics(); err != nil && s.adminServer != nil {
+			// stopAdmin needs s.mu, which we hold
+			go s.adminServer.Shutdown()
+			s.adminServer, s.adminAddr = nil, ""
+		}
+	}
+	if err != nil {
+		for _, ln := range listeners {
+			ln.Close()
+		}
+		s.listenAddrs = nil
+		return err
+	}
+
 	s.running = true
 	s.startTime = time.Now()
-	
-	// Start server in goroutine to allow non-blocking start
+
+	// Restart the latency ramp on every start/reload
+	s.router.MarkReloaded()
+	
+	// Serve each listener in its own goroutine; the server stops running once all return
+	var wg sync.WaitGroup
+	for _, ln := range listeners {
+		wg.Add(1)
+		go func(ln net.Listener) {
+			defer wg.Done()
+			if err := s.server.Serve(ln); err != nil {
+				s.logger.Error("Server stopped with error",
+					zap.String("address", ln.Addr().String()),
+					zap.Error(err),
+				)
+			}
+		}(ln)
+	}
 	go func() {
-		defer func() {
-			s.mu.Lock()
-			s.running = false
-			s.mu.Unlock()
-		}()
-		
-		if err := s.server.ListenAndServe(addr); err != nil {
-			s.logger.Error("Server stopped with error", zap.Error(err))
-		}
+		wg.Wait()
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
 	}()
 	
 	// Give server a moment to start
@@ -248,46 +511,218 @@ func (s *Server) Start() error {
 	return nil
 }
 
-// Stop stops the HTTP server gracefully
+// listen binds the primary address and any extra listeners. The primary
+// address is IPv4 as with fasthttp's ListenAndServe; extra listeners accept
+// any TCP address so IPv6 can be served alongside.
+func (s *Server) listen(addr string) ([]net.Listener, error) {
+	primary, err := s.listenPrimary(addr)
+	if err != nil {
+		return nil, err
+	}
+	listeners := []net.Listener{primary}
+
+	for _, extra := range s.config.ExtraListeners {
+		ln, err := net.Listen("tcp", extra)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("failed to listen on %s: %w", extra, err)
+		}
+		s.logger.Info("Starting extra HTTP listener",
+			zap.String("address", ln.Addr().String()),
+			zap.String("scheme", s.Scheme()),
+		)
+		listeners = append(listeners, ln)
+	}
+
+	if s.tlsConfig != nil {
+		for i, ln := range listeners {
+			listeners[i] = tls.NewListener(ln, s.tlsConfig)
+		}
+	}
+
+	s.listenAddrs = make([]string, len(listeners))
+	for i, ln := range listeners {
+		s.listenAddrs[i] = ln.Addr().String()
+	}
+	return listeners, nil
+}
+
+// listenPrimary binds Host:Port over IPv4, or the Unix socket when one is
+// configured
+func (s *Server) listenPrimary(addr string) (net.Listener, error) {
+	path := s.config.UnixSocket
+	if path == "" {
+		ln, err := net.Listen("tcp4", addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		return ln, nil
+	}
+
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on unix socket %s: %w", path, err)
+	}
+	return ln, nil
+}
+
+// staleSocketDialTimeout bounds the probe that tells a stale socket from a
+// live one
+const staleSocketDialTimeout = time.Second
+
+// removeStaleSocket deletes a socket file left behind by a server that did
+// not shut down cleanly. A socket is only stale when dialing it is refused;
+// one a live server still accepts on, and anything that is not a socket, is
+// left alone.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to inspect unix socket %s: %w", path, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("failed to listen on unix socket %s: path exists and is not a socket", path)
+	}
+	
+	conn, err := net.DialTimeout("unix", path, staleSocketDialTimeout)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("failed to listen on unix socket %s: another server is listening on it", path)
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("failed to probe unix socket %s: %w", path, err)
+	}
+	
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale unix socket %s: %w", path, err)
+	}
+	return nil
+}
+
+// ListenAddrs returns the addresses bound by the running server, primary first
+func (s *Server) ListenAddrs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.listenAddrs...)
+}
+
+// Stop stops the HTTP server gracefully: it stops accepting connections,
+// drains in-flight requests for up to ShutdownTimeout, and only then shuts
+// down plugins so no request reaches an unloaded plugin. It returns an error
+// if the drain times out.
 func (s *Server) Stop() error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	
 	if !s.running {
+		s.mu.Unlock()
 		s.logger.Debug("Server is not running")
 		return nil
 	}
+	server := s.server
+	pluginsManager := s.pluginsManager
+	metricsCollector := s.metricsCollector
+	tracer := s.tracer
+	timeout := s.config.ShutdownTimeout
+	s.mu.Unlock()
+
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	// Operators cannot toggle plugins once shutdown has begun
+	s.stopAdmin()
 	
-	s.logger.Info("Stopping HTTP server...")
+	s.logger.Info("Stopping HTTP server...", zap.Duration("shutdown_timeout", timeout))
 	
-	// Shutdown plugins first to stop processing new requests
-	if s.pluginsManager != nil {
-		if err := s.pluginsManager.Shutdown(); err != nil {
-			s.logger.Warn("Failed to shutdown plugins gracefully", zap.Error(err))
+	// The lock is released while draining so in-flight requests, including
+	// readiness probes, can still read server state
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	drainErr := server.ShutdownWithContext(ctx)
+	s.stopMetrics()
+	if path := s.config.UnixSocket; path != "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			s.logger.Warn("Failed to remove unix socket", zap.String("path", path), zap.Error(err))
 		}
 	}
+	if drainErr != nil {
+		fields := []zap.Field{zap.Duration("shutdown_timeout", timeout), zap.Error(drainErr)}
+		if metricsCollector != nil {
+			fields = append(fields, zap.Int64("active_connections", metricsCollector.ActiveConnections()))
+		}
+		s.logger.Warn("Timed out draining active requests", fields...)
+	}
 	
-	if err := s.server.Shutdown(); err != nil {
-		return fmt.Errorf("failed to shutdown server: %w", err)
+	// Shutdown plugins once no request can reach them
+	if pluginsManager != nil {
+		if err := pluginsManager.Shutdown(); err != nil {
+			s.logger.Warn("Failed to shutdown plugins gracefully", zap.Error(err))
+		}
 	}
 
+	// Flush spans of the requests that were just drained
+	if tracer != nil {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := tracer.Shutdown(flushCtx); err != nil {
+			s.logger.Warn("Failed to flush trace spans", zap.Error(err))
+		}
+		flushCancel()
+	}
+
+	s.mu.Lock()
 	s.running = false
+	s.mu.Unlock()
+
+	if drainErr != nil {
+		return fmt.Errorf("failed to drain active requests within %s: %w", timeout, drainErr)
+	}
+
 	s.logger.Info("HTTP server stopped successfully")
 	return nil
 }
 
-// GetAddr returns the server address
+// GetAddr returns the server address, or the socket path when serving on a
+// Unix socket
 func (s *Server) GetAddr() string {
+	if s.config.UnixSocket != "" {
+		return s.config.UnixSocket
+	}
 	return fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
 }
 
-// Restart restarts the server with new configuration and/or specification
+// Scheme returns "https" when TLS is configured and "http" otherwise
+func (s *Server) Scheme() string {
+	if s.tlsConfig != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// GetURL returns the server's base URL including the scheme, or a unix:// URL
+// naming the socket
+func (s *Server) GetURL() string {
+	if s.config.UnixSocket != "" {
+		return "unix://" + s.config.UnixSocket
+	}
+	return s.Scheme() + "://" + s.GetAddr()
+}
+
+// Restart restarts the server with new configuration and/or specification.
+// A recording session started through the admin API carries over unless the
+// new configuration enables recording itself.
 func (s *Server) Restart(newConfig *config.Config, newSpec *openapi.Specification) error {
 	s.logger.Info("Restarting server with new configuration/specification")
 	
-	// Stop current server
+	// Stop current server; a request that outlives the drain must not
+	// block the reload, so carry on once the server has stopped
 	if err := s.Stop(); err != nil {
-		return fmt.Errorf("failed to stop server: %w", err)
+		s.logger.Warn("Server did not drain cleanly before restart", zap.Error(err))
 	}
 	
 	// Wait a moment for shutdown to complete
@@ -311,12 +746,17 @@ func (s *Server) Restart(newConfig *config.Config, newSpec *openapi.Specificatio
 	s.fullConfig = newServer.fullConfig
 	s.router = newServer.router
 	s.server = newServer.server
+	s.handler = newServer.handler
+	s.server.Handler = s.withProbes(s.handler)
 	s.spec = newServer.spec
 	s.generator = newServer.generator
 	s.metricsCollector = newServer.metricsCollector
 	s.chaosEngine = newServer.chaosEngine
-	s.recordingEngine = newServer.recordingEngine
+	newServer.recording.handOver(s.recording, s.logger)
+	s.recording = newServer.recording
 	s.pluginsManager = newServer.pluginsManager
+	s.tlsConfig = newServer.tlsConfig
+	s.tracer = newServer.tracer
 	s.mu.Unlock()
 	
 	// Start with new configuration
@@ -384,7 +824,7 @@ func (s *Server) GetMetrics() map[string]interface{} {
 func (s *Server) GetRecordingEngine() recorder.RecordingEngine {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.recordingEngine
+	return s.recording.current()
 }
 
 // GetPluginsManager returns the plugins manager if available
@@ -465,7 +905,7 @@ type PluginMetricsAdapter struct {
 	metricsCollector *DefaultMetricsCollector
 	logger           *zap.Logger
 	counters         map[string]int64
-	latencies        map[string][]time.Duration
+	latencies        map[string]*durationHistogram
 	gauges           map[string]float64
 	mu               sync.RWMutex
 }
@@ -476,7 +916,7 @@ func NewPluginMetricsAdapter(metricsCollector *DefaultMetricsCollector, logger *
 		metricsCollector: metricsCollector,
 		logger:           logger,
 		counters:         make(map[string]int64),
-		latencies:        make(map[string][]time.Duration),
+		latencies:        make(map[string]*durationHistogram),
 		gauges:           make(map[string]float64),
 	}
 }
@@ -512,7 +952,12 @@ func (p *PluginMetricsAdapter) ObservePluginLatency(pluginName, operation string
 	
 	// Track in our internal latencies for plugin-specific metrics
 	key := fmt.Sprintf("plugin_%s_%s_duration", pluginName, operation)
-	p.latencies[key] = append(p.latencies[key], duration)
+	histogram, ok := p.latencies[key]
+	if !ok {
+		histogram = &durationHistogram{}
+		p.latencies[key] = histogram
+	}
+	histogram.observe(duration)
 	
 	// Map to existing metrics collector using generic path
 	if p.metricsCollector != nil {
@@ -564,9 +1009,14 @@ func (p *PluginMetricsAdapter) GetPluginMetrics() map[string]interface{} {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 	
+	latencies := make(map[string]LatencyPercentiles, len(p.latencies))
+	for key, histogram := range p.latencies {
+		latencies[key] = histogram.summary()
+	}
+	
 	return map[string]interface{}{
 		"counters":  p.counters,
-		"latencies": p.latencies,
+		"latencies": latencies,
 		"gauges":    p.gauges,
 	}
 }
\ No newline at end of file
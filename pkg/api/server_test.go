@@ -0,0 +1,837 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap/zaptest"
+
+	"vanta/pkg/config"
+	"vanta/pkg/openapi"
+	"vanta/pkg/plugins"
+	"vanta/pkg/recorder"
+)
+
+func newTestServerConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = 0
+	cfg.Metrics.Enabled = false
+	return cfg
+}
+
+func TestServer_ExtraListenersServeSameRoutes(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Server.ExtraListeners = []string{"127.0.0.1:0"}
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+
+	addrs := server.ListenAddrs()
+	require.Len(t, addrs, 2)
+	assert.NotEqual(t, addrs[0], addrs[1])
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	var bodies []string
+	for _, addr := range addrs {
+		resp, err := client.Get("http://" + addr + "/users")
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "listener %s", addr)
+		bodies = append(bodies, string(body))
+	}
+	assert.Equal(t, bodies[0], bodies[1])
+}
+
+func TestServer_ExtraListenerBindFailure(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Server.ExtraListeners = []string{"256.0.0.1:0"}
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	err = server.Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "256.0.0.1:0")
+	assert.Empty(t, server.ListenAddrs())
+}
+
+func TestServer_UnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "vanta.sock")
+
+	// A socket left behind by a crashed server must not block startup
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+	_, err = os.Stat(socket)
+	require.NoError(t, err)
+
+	cfg := newTestServerConfig()
+	cfg.Server.UnixSocket = socket
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Equal(t, socket, server.GetAddr())
+	require.NoError(t, server.Start())
+
+	client := &http.Client{
+		Timeout: 2 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	resp, err := client.Get("http://vanta/users")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, server.Stop())
+	_, err = os.Stat(socket)
+	assert.True(t, os.IsNotExist(err), "socket file should be removed on stop")
+}
+
+func TestServer_UnixSocketRefusesToReplaceLiveSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "vanta.sock")
+
+	// Another process is still serving on the socket
+	live, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer live.Close()
+
+	cfg := newTestServerConfig()
+	cfg.Server.UnixSocket = socket
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	err = server.Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "another server is listening")
+
+	// The live socket is untouched and still accepts connections
+	conn, err := net.Dial("unix", socket)
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestServer_UnixSocketRefusesToReplaceRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not-a-socket")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o644))
+
+	cfg := newTestServerConfig()
+	cfg.Server.UnixSocket = path
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	err = server.Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a socket")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+}
+
+// tlsClient trusts only the certificate the server is configured with
+func tlsClient(t *testing.T, server *Server) *http.Client {
+	require.NotNil(t, server.tlsConfig)
+	leaf, err := x509.ParseCertificate(server.tlsConfig.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return &http.Client{
+		Timeout:   2 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+	}
+}
+
+func TestServer_TLSSelfSigned(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Server.TLS = config.TLSConfig{Enabled: true, MinVersion: "1.3"}
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Equal(t, "https", server.Scheme())
+	assert.Equal(t, "https://127.0.0.1:0", server.GetURL())
+
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+
+	resp, err := tlsClient(t, server).Get("https://" + server.ListenAddrs()[0] + "/users")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotNil(t, resp.TLS)
+	assert.Equal(t, uint16(tls.VersionTLS13), resp.TLS.Version)
+}
+
+func TestServer_RestartTogglesTLS(t *testing.T) {
+	server, err := NewServer(newTestServerConfig(), createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+
+	tlsCfg := newTestServerConfig()
+	tlsCfg.Server.TLS = config.TLSConfig{Enabled: true}
+	require.NoError(t, server.Restart(tlsCfg, createFixedResponseSpec()))
+	assert.Equal(t, "https", server.Scheme())
+
+	resp, err := tlsClient(t, server).Get("https://" + server.ListenAddrs()[0] + "/users")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, server.Restart(newTestServerConfig(), createFixedResponseSpec()))
+	assert.Equal(t, "http", server.Scheme())
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err = client.Get("http://" + server.ListenAddrs()[0] + "/users")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServer_RestartHandsOverRecording(t *testing.T) {
+	server, err := NewServer(newTestServerConfig(), createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+
+	require.NoError(t, server.StartRecording(RecordingStartRequest{Directory: t.TempDir()}))
+	started := server.GetRecordingEngine()
+
+	// A session started through the admin API keeps recording
+	require.NoError(t, server.Restart(newTestServerConfig(), createFixedResponseSpec()))
+	require.Same(t, started, server.GetRecordingEngine())
+	assert.True(t, server.RecordingStatus().Recording)
+
+	resp, err := (&http.Client{Timeout: 2 * time.Second}).Get("http://" + server.ListenAddrs()[0] + "/users")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Eventually(t, func() bool {
+		recordings, err := started.GetStorage().List(recorder.ListFilter{})
+		return err == nil && len(recordings) == 1
+	}, 2*time.Second, 20*time.Millisecond)
+
+	// Recording configured by the new configuration replaces it
+	recordingCfg := newTestServerConfig()
+	recordingCfg.Recording.Enabled = true
+	recordingCfg.Recording.Storage.Directory = t.TempDir()
+	require.NoError(t, server.Restart(recordingCfg, createFixedResponseSpec()))
+	assert.NotSame(t, started, server.GetRecordingEngine())
+	assert.False(t, started.IsEnabled(), "the replaced engine is stopped")
+	assert.Equal(t, recordingCfg.Recording.Storage.Directory, server.RecordingStatus().Directory)
+}
+
+func TestServer_TLSFromFiles(t *testing.T) {
+	certPEM, keyPEM, err := generateSelfSignedCert("127.0.0.1")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	cfg := newTestServerConfig()
+	cfg.Server.TLS = config.TLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile}
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+
+	resp, err := tlsClient(t, server).Get("https://" + server.ListenAddrs()[0] + "/users")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Plain HTTP clients cannot talk to the HTTPS listener
+	_, err = (&http.Client{Timeout: 2 * time.Second}).Get("http://" + server.ListenAddrs()[0] + "/users")
+	assert.Error(t, err)
+}
+
+func TestServer_TLSMissingCertificate(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Server.TLS = config.TLSConfig{Enabled: true, CertFile: "missing.pem", KeyFile: "missing-key.pem"}
+
+	_, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	assert.ErrorContains(t, err, "failed to configure TLS")
+}
+
+func TestServer_AtomicPluginLoadFailsStartup(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Plugins = []config.PluginConfig{
+		{Name: "rate_limit", Enabled: true, Config: map[string]interface{}{"ip_requests_per_second": 100.0}},
+		{Name: "not_a_plugin", Enabled: true},
+	}
+
+	// Best effort: the server starts without the failed plugin
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Len(t, server.GetPluginsManager().ListPlugins(), 1)
+
+	cfg.PluginOptions.AtomicLoad = true
+	_, err = NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	assert.ErrorContains(t, err, "rolled back 1")
+}
+
+func TestServer_StrictPluginsRejectsUnknownNames(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Plugins = []config.PluginConfig{
+		{Name: "rate_limit", Enabled: true, Config: map[string]interface{}{"ip_requests_per_second": 100.0}},
+		{Name: "ratelimit", Enabled: true},
+		{Name: "loging", Enabled: false},
+	}
+
+	// Without strict mode the typos are only logged
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Len(t, server.GetPluginsManager().ListPlugins(), 1)
+
+	cfg.Server.StrictPlugins = true
+	_, err = NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.Error(t, err)
+	assert.ErrorIs(t, err, plugins.ErrPluginNotFound)
+	assert.ErrorContains(t, err, "unknown plugins in configuration: ratelimit, loging")
+	assert.ErrorContains(t, err, "rate_limit")
+
+	// Every configured name is known, so strict mode starts normally
+	cfg.Plugins = cfg.Plugins[:1]
+	server, err = NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Len(t, server.GetPluginsManager().ListPlugins(), 1)
+}
+
+func getProbe(t *testing.T, url string) (int, map[string]interface{}) {
+	resp, err := (&http.Client{Timeout: 2 * time.Second}).Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, body
+}
+
+// exporterGoroutines counts the running OTLP exporter loops
+func exporterGoroutines() int {
+	buf := make([]byte, 1<<20)
+	return strings.Count(string(buf[:runtime.Stack(buf, true)]), "tracing.(*OTLPExporter).run(")
+}
+
+func TestServer_CreationFailureStopsTraceExporter(t *testing.T) {
+	before := exporterGoroutines()
+
+	cfg := newTestServerConfig()
+	cfg.Tracing.Enabled = true
+	cfg.Tracing.ServiceName = "vanta-test"
+	cfg.Tracing.Endpoint = "http://127.0.0.1:1"
+	cfg.Recording.Upstream = "not a url"
+
+	_, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.Error(t, err)
+
+	assert.Eventually(t, func() bool { return exporterGoroutines() <= before },
+		time.Second, 10*time.Millisecond, "the exporter started for the failed server is still running")
+}
+
+func TestServer_ReadinessFlipsAfterStart(t *testing.T) {
+	cfg := newTestServerConfig()
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	ctx := createTestRequestCtx("GET", "/_ready", nil)
+	server.server.Handler(ctx)
+	assert.Equal(t, 503, ctx.Response.StatusCode())
+	assert.Contains(t, string(ctx.Response.Body()), `"status":"not_ready"`)
+
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+	baseURL := "http://" + server.ListenAddrs()[0]
+
+	status, body := getProbe(t, baseURL+"/_ready")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ready", body["status"])
+	assert.Equal(t, "disabled", body["recording"])
+	assert.Contains(t, body, "plugins")
+	assert.Greater(t, body["uptime_seconds"], 0.0)
+
+	status, body = getProbe(t, baseURL+"/_health")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", body["status"])
+}
+
+func TestServer_ProbesBypassPlugins(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Plugins = []config.PluginConfig{{Name: "auth", Enabled: true, Config: map[string]interface{}{}}}
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+	baseURL := "http://" + server.ListenAddrs()[0]
+
+	resp, err := (&http.Client{Timeout: 2 * time.Second}).Get(baseURL + "/users")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	status, body := getProbe(t, baseURL+"/_ready")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]interface{}{"auth": "enabled"}, body["plugins"])
+}
+
+const specSecuritySpec = `
+openapi: 3.0.3
+info:
+  title: Secured
+  version: 1.0.0
+security:
+  - apiKey: []
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-API-Key
+paths:
+  /status:
+    get:
+      security: []
+      responses:
+        "200":
+          description: Status
+  /orders/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          description: Order
+`
+
+func TestServer_AuthHonorsSpecSecurity(t *testing.T) {
+	spec, err := openapi.NewParser().Parse([]byte(specSecuritySpec))
+	require.NoError(t, err)
+
+	cfg := newTestServerConfig()
+	cfg.Plugins = []config.PluginConfig{{Name: "auth", Enabled: true, Config: map[string]interface{}{
+		"api_keys":      map[string]interface{}{"key-1": "service-a"},
+		"auth_header":   "X-API-Key",
+		"spec_security": true,
+	}}}
+
+	server, err := NewServer(cfg, spec, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer server.pluginsManager.Shutdown()
+
+	tests := []struct {
+		name   string
+		path   string
+		apiKey string
+		status int
+	}{
+		{"empty security is public", "/status", "", fasthttp.StatusOK},
+		{"global security requires credentials", "/orders/1", "", fasthttp.StatusUnauthorized},
+		{"global security accepts credentials", "/orders/1", "key-1", fasthttp.StatusOK},
+		{"unknown routes still require credentials", "/missing", "", fasthttp.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := createTestRequestCtx("GET", tt.path, nil)
+			if tt.apiKey != "" {
+				ctx.Request.Header.Set("X-API-Key", tt.apiKey)
+			}
+			server.server.Handler(ctx)
+			assert.Equal(t, tt.status, ctx.Response.StatusCode())
+		})
+	}
+}
+
+func TestServer_ProbePathsConfigurable(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Server.HealthPath = "/-/live"
+	cfg.Server.ReadyPath = ""
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	ctx := createTestRequestCtx("GET", "/-/live", nil)
+	server.server.Handler(ctx)
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+
+	// Disabled and default paths fall through to the mocked routes
+	for _, path := range []string{"/_health", "/_ready"} {
+		ctx = createTestRequestCtx("GET", path, nil)
+		server.server.Handler(ctx)
+		assert.Equal(t, 404, ctx.Response.StatusCode(), path)
+	}
+}
+
+// countOperations counts the operations defined in spec
+func countOperations(spec *openapi.Specification) int {
+	count := 0
+	for _, pathItem := range spec.Paths {
+		for _, operation := range []*openapi.Operation{pathItem.GET, pathItem.POST, pathItem.PUT, pathItem.PATCH, pathItem.DELETE} {
+			if operation != nil {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestServer_RouteCatalog(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Plugins = []config.PluginConfig{{Name: "auth", Enabled: true, Config: map[string]interface{}{}}}
+	spec := createFixedResponseSpec()
+
+	server, err := NewServer(cfg, spec, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+
+	// The catalog is served even though auth rejects the mocked routes
+	status, body := getProbe(t, "http://"+server.ListenAddrs()[0]+"/_routes")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(countOperations(spec)), body["count"])
+
+	routes, ok := body["routes"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, routes, countOperations(spec))
+	assert.Contains(t, routes, map[string]interface{}{
+		"method":       "GET",
+		"path":         "/users",
+		"operation_id": "listUsers",
+		"responses":    []interface{}{"200"},
+	})
+}
+
+func TestServer_BandwidthThrottleStreamsPastOtherMiddleware(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Middleware.ETag = true
+	cfg.Middleware.Compression = config.CompressionConfig{Enabled: true, MinSize: 1, Level: 6}
+	cfg.Chaos = config.ChaosConfig{
+		Enabled: true,
+		Scenarios: []config.ScenarioConfig{{
+			Name:        "slow_users",
+			Type:        "bandwidth",
+			Endpoints:   []string{"/users"},
+			Probability: 1.0,
+			Parameters:  map[string]interface{}{"bytes_per_second": 40, "chunk_size": 4},
+		}},
+	}
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	// Nothing in the stack may drain the stream, which would sleep through
+	// the throttle before the handler returns
+	ctx := createTestRequestCtx("GET", "/users", nil)
+	start := time.Now()
+	server.server.Handler(ctx)
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+	require.True(t, ctx.Response.IsBodyStream())
+	assert.NotEmpty(t, ctx.Response.Header.Peek("ETag"))
+
+	var out strings.Builder
+	start = time.Now()
+	_, err = ctx.Response.WriteTo(&out)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	assert.Contains(t, out.String(), "Transfer-Encoding: chunked")
+	assert.Contains(t, out.String(), "4\r\nespo\r\n", "the body arrives in throttled chunks")
+}
+
+func TestServer_RouteCatalogPathConfigurable(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Server.RoutesPath = "/-/routes"
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	ctx := createTestRequestCtx("GET", "/-/routes", nil)
+	server.server.Handler(ctx)
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+
+	ctx = createTestRequestCtx("GET", "/_routes", nil)
+	server.server.Handler(ctx)
+	assert.Equal(t, 404, ctx.Response.StatusCode())
+}
+
+func TestServer_ServeDocs(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Mock.ServeDocs = true
+	cfg.Plugins = []config.PluginConfig{{Name: "auth", Enabled: true, Config: map[string]interface{}{}}}
+	spec := createFixedResponseSpec()
+
+	server, err := NewServer(cfg, spec, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+	baseURL := "http://" + server.ListenAddrs()[0]
+	client := &http.Client{Timeout: 2 * time.Second}
+
+	// The served document parses back into the same spec, despite auth
+	resp, err := client.Get(baseURL + "/openapi.json")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	parsed, err := openapi.NewParser().Parse(body)
+	require.NoError(t, err)
+	expected, err := spec.MarshalDocument("json")
+	require.NoError(t, err)
+	actual, err := parsed.MarshalDocument("json")
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(actual))
+
+	resp, err = client.Get(baseURL + "/openapi.yaml")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/yaml", resp.Header.Get("Content-Type"))
+
+	resp, err = client.Get(baseURL + "/docs")
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+	assert.Contains(t, string(body), `url: "/openapi.json"`)
+	assert.Contains(t, string(body), "<title>Test API</title>")
+	assert.NotContains(t, string(body), "https://")
+
+	// The page's assets are embedded rather than loaded from a CDN
+	for path, contentType := range map[string]string{
+		"/docs/swagger-ui.css":       "text/css",
+		"/docs/swagger-ui-bundle.js": "application/javascript",
+	} {
+		assert.Contains(t, string(body), `"`+path+`"`)
+		resp, err = client.Get(baseURL + path)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+		assert.Contains(t, resp.Header.Get("Content-Type"), contentType, path)
+		assert.NotEmpty(t, body, path)
+	}
+
+	resp, err = client.Get(baseURL + "/docs/missing.js")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "other /docs/ paths are not exempt from auth")
+}
+
+func TestServer_DocsDisabledByDefault(t *testing.T) {
+	server, err := NewServer(newTestServerConfig(), createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	for _, path := range []string{"/openapi.json", "/openapi.yaml", "/docs", "/docs/swagger-ui-bundle.js"} {
+		ctx := createTestRequestCtx("GET", path, nil)
+		server.server.Handler(ctx)
+		assert.Equal(t, 404, ctx.Response.StatusCode(), path)
+	}
+}
+
+// startSlowServer serves /slow, which signals once it is running and then
+// sleeps for delay before answering
+func startSlowServer(t *testing.T, cfg *config.Config, delay time.Duration) (*Server, <-chan struct{}) {
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	started := make(chan struct{}, 1)
+	server.router.AddRoute("GET", "/slow", func(ctx *fasthttp.RequestCtx) error {
+		started <- struct{}{}
+		time.Sleep(delay)
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		ctx.SetBodyString("done")
+		return nil
+	})
+	require.NoError(t, server.Start())
+	return server, started
+}
+
+type slowResult struct {
+	resp *http.Response
+	body string
+	err  error
+}
+
+func getSlow(url string) <-chan slowResult {
+	result := make(chan slowResult, 1)
+	go func() {
+		resp, err := (&http.Client{Timeout: 5 * time.Second}).Get(url)
+		if err != nil {
+			result <- slowResult{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		result <- slowResult{resp: resp, body: string(body), err: err}
+	}()
+	return result
+}
+
+func TestServer_StopDrainsInFlightRequests(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Plugins = []config.PluginConfig{{
+		Name:    "rate_limit",
+		Enabled: true,
+		Config:  map[string]interface{}{"ip_requests_per_second": 100.0},
+	}}
+
+	server, started := startSlowServer(t, cfg, 300*time.Millisecond)
+	result := getSlow("http://" + server.ListenAddrs()[0] + "/slow")
+	<-started
+
+	require.NoError(t, server.Stop())
+	assert.False(t, server.IsRunning())
+
+	res := <-result
+	require.NoError(t, res.err)
+	assert.Equal(t, http.StatusOK, res.resp.StatusCode)
+	assert.Equal(t, "done", res.body)
+	// The rate limit plugin was still loaded when the request finished
+	assert.Equal(t, "100", res.resp.Header.Get("X-RateLimit-Limit"))
+}
+
+func TestServer_StopDrainTimeout(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Server.ShutdownTimeout = 50 * time.Millisecond
+
+	server, started := startSlowServer(t, cfg, 500*time.Millisecond)
+	result := getSlow("http://" + server.ListenAddrs()[0] + "/slow")
+	<-started
+
+	err := server.Stop()
+	assert.ErrorContains(t, err, "failed to drain active requests within 50ms")
+	assert.False(t, server.IsRunning())
+	<-result
+}
+
+func TestServer_RejectsOversizedBody(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Middleware.MaxBodyBytes = 64
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+
+	post := func(size int) (int, []byte) {
+		resp, err := (&http.Client{Timeout: 2 * time.Second}).Post(
+			"http://"+server.ListenAddrs()[0]+"/users", "application/json", strings.NewReader(strings.Repeat("x", size)))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, body
+	}
+
+	status, body := post(1024)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+	assert.JSONEq(t, `{"error": "Request body too large", "max_body_bytes": 64}`, string(body))
+
+	status, _ = post(32)
+	assert.NotEqual(t, http.StatusRequestEntityTooLarge, status)
+}
+
+func TestServer_MaxRequestSizeRejectsOversizedBody(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Server.MaxRequestSize = "1KB"
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer func() { assert.NoError(t, server.Stop()) }()
+
+	post := func(size int) int {
+		resp, err := (&http.Client{Timeout: 2 * time.Second}).Post(
+			"http://"+server.ListenAddrs()[0]+"/users", "application/json", strings.NewReader(strings.Repeat("x", size)))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post(2048))
+	assert.NotEqual(t, http.StatusRequestEntityTooLarge, post(512))
+}
+
+func TestServer_ConnectionTuningPassThrough(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Server.IdleTimeout = 45 * time.Second
+	cfg.Server.DisableKeepalive = true
+	cfg.Server.TCPKeepalive = true
+	cfg.Server.TCPKeepalivePeriod = 15 * time.Second
+	cfg.Server.MaxRequestsPerConn = 100
+
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, 45*time.Second, server.server.IdleTimeout)
+	assert.True(t, server.server.DisableKeepalive)
+	assert.True(t, server.server.TCPKeepalive)
+	assert.Equal(t, 15*time.Second, server.server.TCPKeepalivePeriod)
+	assert.Equal(t, 100, server.server.MaxRequestsPerConn)
+	assert.Equal(t, 10*1024*1024, server.server.MaxRequestBodySize)
+}
+
+func TestServer_CustomUnmatchedResponses(t *testing.T) {
+	cfg := newTestServerConfig()
+	cfg.Mock.NotFoundResponse = config.UnmatchedResponseConfig{
+		ContentType: "application/problem+json",
+		Body:        `{"type":"about:blank","title":"No such resource","status":404}`,
+	}
+	cfg.Mock.MethodNotAllowedResponse = config.UnmatchedResponseConfig{
+		Status: fasthttp.StatusNotImplemented,
+		Body:   "unsupported",
+	}
+	server, err := NewServer(cfg, createFixedResponseSpec(), zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	ctx := createTestRequestCtx("GET", "/nowhere", nil)
+	server.server.Handler(ctx)
+	assert.Equal(t, fasthttp.StatusNotFound, ctx.Response.StatusCode())
+	assert.Equal(t, "application/problem+json", string(ctx.Response.Header.ContentType()))
+	assert.Equal(t, cfg.Mock.NotFoundResponse.Body, string(ctx.Response.Body()))
+
+	ctx = createTestRequestCtx("DELETE", "/users", nil)
+	server.server.Handler(ctx)
+	assert.Equal(t, fasthttp.StatusNotImplemented, ctx.Response.StatusCode())
+	assert.Equal(t, "text/plain; charset=utf-8", string(ctx.Response.Header.ContentType()))
+	assert.Equal(t, "unsupported", string(ctx.Response.Body()))
+	assert.Contains(t, string(ctx.Response.Header.Peek("Allow")), "GET")
+}
@@ -0,0 +1,408 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"vanta/pkg/openapi"
+)
+
+// resourceIDField is the object field holding a stored resource's identifier
+const resourceIDField = "id"
+
+// ResourceStore is an in-memory keyed store backing the stateful mock mode.
+// Objects are grouped by collection path (e.g. "/things") and keyed by id.
+type ResourceStore struct {
+	mu          sync.RWMutex
+	collections map[string]*resourceCollection
+}
+
+// resourceCollection holds the objects stored under a single collection path
+type resourceCollection struct {
+	items  map[string]map[string]interface{}
+	order  []string
+	nextID int
+}
+
+// NewResourceStore creates an empty resource store
+func NewResourceStore() *ResourceStore {
+	return &ResourceStore{
+		collections: make(map[string]*resourceCollection),
+	}
+}
+
+// Create stores a new object in the collection under a generated id and returns the id
+func (s *ResourceStore) Create(collection string, object map[string]interface{}) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, exists := s.collections[collection]
+	if !exists {
+		c = &resourceCollection{items: make(map[string]map[string]interface{})}
+		s.collections[collection] = c
+	}
+
+	c.nextID++
+	id := strconv.Itoa(c.nextID)
+	c.items[id] = object
+	c.order = append(c.order, id)
+	return id
+}
+
+// Get returns the object stored under id in the collection
+func (s *ResourceStore) Get(collection, id string) (map[string]interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, exists := s.collections[collection]
+	if !exists {
+		return nil, false
+	}
+	object, exists := c.items[id]
+	return object, exists
+}
+
+// List returns the objects in the collection in creation order
+func (s *ResourceStore) List(collection string) []map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	objects := make([]map[string]interface{}, 0)
+	if c, exists := s.collections[collection]; exists {
+		for _, id := range c.order {
+			objects = append(objects, c.items[id])
+		}
+	}
+	return objects
+}
+
+// Update replaces the object stored under id, returning false if it does not exist
+func (s *ResourceStore) Update(collection, id string, object map[string]interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, exists := s.collections[collection]
+	if !exists {
+		return false
+	}
+	if _, exists := c.items[id]; !exists {
+		return false
+	}
+	c.items[id] = object
+	return true
+}
+
+// Delete removes the object stored under id, returning false if it does not exist
+func (s *ResourceStore) Delete(collection, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, exists := s.collections[collection]
+	if !exists {
+		return false
+	}
+	if _, exists := c.items[id]; !exists {
+		return false
+	}
+	delete(c.items, id)
+	for i, existing := range c.order {
+		if existing == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// statefulRoute describes how a matched route maps onto the resource store
+type statefulRoute struct {
+	collection     string
+	collectionPath string
+	id             string
+}
+
+// SetStateful enables or disables the in-memory CRUD store for collection
+// and resource paths. Disabling it discards any stored objects.
+func (r *Router) SetStateful(enabled bool) {
+	if enabled {
+		r.store = NewResourceStore()
+	} else {
+		r.store = nil
+	}
+}
+
+// resolveStatefulRoute determines whether a route is a collection ("/things")
+// or a resource ("/things/{id}") path. A collection must have a sibling
+// resource path in the spec and vice versa.
+func (r *Router) resolveStatefulRoute(routePath, path string) (*statefulRoute, bool) {
+	if strings.HasPrefix(path, "/__") {
+		return nil, false
+	}
+
+	routePath = strings.TrimSuffix(routePath, "/")
+	path = strings.TrimSuffix(path, "/")
+
+	// Resource path: the last segment is a parameter and its parent is a collection
+	if i := strings.LastIndex(routePath, "/"); i > 0 {
+		last := routePath[i+1:]
+		if strings.HasPrefix(last, "{") && strings.HasSuffix(last, "}") {
+			if _, exists := r.spec.Paths[routePath[:i]]; exists {
+				j := strings.LastIndex(path, "/")
+				return &statefulRoute{
+					collection:     path[:j],
+					collectionPath: routePath[:i],
+					id:             path[j+1:],
+				}, true
+			}
+			return nil, false
+		}
+	}
+
+	// Collection path: some parameterized child path exists in the spec
+	for specPath := range r.spec.Paths {
+		parent, last, found := cutLast(strings.TrimSuffix(specPath, "/"))
+		if found && parent == routePath && strings.HasPrefix(last, "{") && strings.HasSuffix(last, "}") {
+			return &statefulRoute{collection: path, collectionPath: routePath}, true
+		}
+	}
+
+	return nil, false
+}
+
+// cutLast splits a path around its last slash
+func cutLast(path string) (string, string, bool) {
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return "", "", false
+	}
+	return path[:i], path[i+1:], true
+}
+
+// handleStateful serves a request from the resource store. It reports false
+// when the method is not one the store handles, leaving it to the mock handler.
+func (r *Router) handleStateful(ctx *fasthttp.RequestCtx, route *statefulRoute) (bool, error) {
+	method := string(ctx.Method())
+
+	if route.id == "" {
+		switch method {
+		case "GET", "HEAD":
+			return true, writeStatefulResponse(ctx, fasthttp.StatusOK, r.store.List(route.collection))
+		case "POST":
+			return true, r.createResource(ctx, route)
+		}
+		return false, nil
+	}
+
+	switch method {
+	case "GET", "HEAD":
+		object, exists := r.store.Get(route.collection, route.id)
+		if !exists {
+			return true, writeResourceNotFound(ctx, route)
+		}
+		return true, writeStatefulResponse(ctx, fasthttp.StatusOK, object)
+	case "PUT", "PATCH":
+		return true, r.updateResource(ctx, route, method == "PATCH")
+	case "DELETE":
+		if !r.store.Delete(route.collection, route.id) {
+			return true, writeResourceNotFound(ctx, route)
+		}
+		ctx.SetStatusCode(fasthttp.StatusNoContent)
+		return true, nil
+	}
+	return false, nil
+}
+
+// createResource stores the request body as a new object. Fields the client
+// leaves out are seeded from the schema of the collection's POST response.
+func (r *Router) createResource(ctx *fasthttp.RequestCtx, route *statefulRoute) error {
+	body, err := decodeResourceBody(ctx)
+	if err != nil {
+		return writeStatefulError(ctx, fasthttp.StatusBadRequest, err.Error())
+	}
+
+	object := r.seedResource(ctx, route.collectionPath)
+	for key, value := range body {
+		object[key] = value
+	}
+
+	// Stored objects are never mutated in place, so the id is attached to a copy
+	delete(object, resourceIDField)
+	id := r.store.Create(route.collection, object)
+	created := make(map[string]interface{}, len(object)+1)
+	for key, value := range object {
+		created[key] = value
+	}
+	created[resourceIDField] = r.typedResourceID(route.collectionPath, id)
+	r.store.Update(route.collection, id, created)
+
+	ctx.Response.Header.Set("Location", route.collection+"/"+id)
+	r.logger.Debug("Stateful resource created",
+		zap.String("collection", route.collection),
+		zap.String("id", id),
+	)
+	return writeStatefulResponse(ctx, fasthttp.StatusCreated, created)
+}
+
+// updateResource replaces (PUT) or merges into (PATCH) a stored object.
+// The identifier is preserved regardless of the request body.
+func (r *Router) updateResource(ctx *fasthttp.RequestCtx, route *statefulRoute, merge bool) error {
+	existing, exists := r.store.Get(route.collection, route.id)
+	if !exists {
+		return writeResourceNotFound(ctx, route)
+	}
+
+	body, err := decodeResourceBody(ctx)
+	if err != nil {
+		return writeStatefulError(ctx, fasthttp.StatusBadRequest, err.Error())
+	}
+
+	object := make(map[string]interface{}, len(body)+1)
+	if merge {
+		for key, value := range existing {
+			object[key] = value
+		}
+	}
+	for key, value := range body {
+		object[key] = value
+	}
+	object[resourceIDField] = existing[resourceIDField]
+
+	if !r.store.Update(route.collection, route.id, object) {
+		return writeResourceNotFound(ctx, route)
+	}
+	return writeStatefulResponse(ctx, fasthttp.StatusOK, object)
+}
+
+// seedResource generates a new object from the collection's resource schema.
+// It returns an empty object when no generator or object schema is available.
+func (r *Router) seedResource(ctx *fasthttp.RequestCtx, collectionPath string) map[string]interface{} {
+	object := make(map[string]interface{})
+
+	schema := r.resourceSchema(collectionPath)
+	if schema == nil || r.generator == nil {
+		return object
+	}
+
+	genCtx := &openapi.GenerationContext{
+		MaxDepth:   5,
+		Visited:    make(map[string]bool),
+		ArraySizes: make(map[string]int),
+		Locale:     "en",
+		Timestamp:  ctx.Time(),
+		Schemas:    r.spec.Schemas,
+	}
+	if seeded, ok := r.generator.(*openapi.DefaultDataGenerator); ok {
+		genCtx.Seed = seeded.GetSeed()
+	}
+
+	generated, err := r.generator.Generate(schema, genCtx)
+	if err != nil {
+		r.logger.Warn("Failed to seed stateful resource", zap.Error(err))
+		return object
+	}
+	if fields, ok := generated.(map[string]interface{}); ok {
+		for key, value := range fields {
+			object[key] = value
+		}
+	}
+	return object
+}
+
+// resourceSchema finds the schema describing a single resource of a collection,
+// preferring the POST response, then the item GET response, then the POST request body
+func (r *Router) resourceSchema(collectionPath string) *openapi.Schema {
+	candidates := make([]*openapi.Schema, 0, 3)
+
+	if pathItem, exists := r.spec.Paths[collectionPath]; exists && pathItem.POST != nil {
+		if media, _ := getResponseMedia(pathItem.POST, determineResponseCode(pathItem.POST)); media != nil {
+			candidates = append(candidates, media.Schema)
+		}
+	}
+	for specPath, pathItem := range r.spec.Paths {
+		parent, _, found := cutLast(strings.TrimSuffix(specPath, "/"))
+		if found && parent == collectionPath && pathItem.GET != nil {
+			if media, _ := getResponseMedia(pathItem.GET, determineResponseCode(pathItem.GET)); media != nil {
+				candidates = append(candidates, media.Schema)
+			}
+			break
+		}
+	}
+	if pathItem, exists := r.spec.Paths[collectionPath]; exists && pathItem.POST != nil && pathItem.POST.RequestBody != nil {
+		if media, exists := pathItem.POST.RequestBody.Content["application/json"]; exists {
+			candidates = append(candidates, media.Schema)
+		}
+	}
+
+	for _, schema := range candidates {
+		if schema != nil {
+			return schema
+		}
+	}
+	return nil
+}
+
+// typedResourceID converts a generated id to an integer when the resource
+// schema declares a numeric id field
+func (r *Router) typedResourceID(collectionPath, id string) interface{} {
+	schema := r.resourceSchema(collectionPath)
+	if schema != nil && schema.Ref != "" {
+		schema = r.spec.Schemas[strings.TrimPrefix(schema.Ref, openapi.ComponentSchemaRefPrefix)]
+	}
+	if schema == nil {
+		return id
+	}
+	if property, exists := schema.Properties[resourceIDField]; exists && property != nil {
+		if property.Type == "integer" || property.Type == "number" {
+			if n, err := strconv.Atoi(id); err == nil {
+				return n
+			}
+		}
+	}
+	return id
+}
+
+// decodeResourceBody parses a JSON object request body; an empty body yields an empty object
+func decodeResourceBody(ctx *fasthttp.RequestCtx) (map[string]interface{}, error) {
+	body := make(map[string]interface{})
+	raw := ctx.PostBody()
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		return body, nil
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, fmt.Errorf("request body must be a JSON object: %w", err)
+	}
+	return body, nil
+}
+
+// writeStatefulResponse serializes a stored object or list as JSON
+func writeStatefulResponse(ctx *fasthttp.RequestCtx, statusCode int, data interface{}) error {
+	responseBytes, err := marshalResponse(ctx, data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	ctx.SetStatusCode(statusCode)
+	ctx.SetContentType("application/json")
+	ctx.Response.Header.Set("X-Mock-Response", "true")
+	ctx.Response.Header.Set("X-Mock-Generator", "vanta")
+	ctx.SetBody(responseBytes)
+	return nil
+}
+
+// writeResourceNotFound responds with 404 for an unknown resource id
+func writeResourceNotFound(ctx *fasthttp.RequestCtx, route *statefulRoute) error {
+	return writeStatefulError(ctx, fasthttp.StatusNotFound,
+		fmt.Sprintf("No resource with id %q in %s", route.id, route.collection))
+}
+
+// writeStatefulError responds with a JSON error body
+func writeStatefulError(ctx *fasthttp.RequestCtx, statusCode int, message string) error {
+	return writeStatefulResponse(ctx, statusCode, map[string]interface{}{
+		"error":   fasthttp.StatusMessage(statusCode),
+		"message": message,
+	})
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"go.uber.org/zap/zaptest"
+
+	"vanta/pkg/openapi"
+)
+
+func createThingsSpec() *openapi.Specification {
+	thing := &openapi.Schema{
+		Type:     "object",
+		Required: []string{"id", "name", "color"},
+		Properties: map[string]*openapi.Schema{
+			"id":    {Type: "integer"},
+			"name":  {Type: "string"},
+			"color": {Type: "string", Enum: []interface{}{"red", "green", "blue"}},
+		},
+	}
+	thingResponse := func(code string) map[string]openapi.Response {
+		return map[string]openapi.Response{
+			code: {
+				Description: "Thing",
+				Content: map[string]openapi.MediaTypeObject{
+					"application/json": {Schema: thing},
+				},
+			},
+		}
+	}
+
+	return &openapi.Specification{
+		Version: "3.0.0",
+		Info:    openapi.InfoObject{Title: "Things API", Version: "1.0.0"},
+		Paths: map[string]openapi.PathItem{
+			"/things": {
+				GET: &openapi.Operation{
+					OperationID: "listThings",
+					Responses: map[string]openapi.Response{
+						"200": {
+							Description: "Things",
+							Content: map[string]openapi.MediaTypeObject{
+								"application/json": {Schema: &openapi.Schema{Type: "array", Items: thing}},
+							},
+						},
+					},
+				},
+				POST: &openapi.Operation{OperationID: "createThing", Responses: thingResponse("201")},
+			},
+			"/things/{id}": {
+				GET:    &openapi.Operation{OperationID: "getThing", Responses: thingResponse("200")},
+				PUT:    &openapi.Operation{OperationID: "replaceThing", Responses: thingResponse("200")},
+				PATCH:  &openapi.Operation{OperationID: "updateThing", Responses: thingResponse("200")},
+				DELETE: &openapi.Operation{OperationID: "deleteThing", Responses: map[string]openapi.Response{"204": {Description: "Deleted"}}},
+			},
+		},
+	}
+}
+
+// newStatefulClient serves a stateful router over an in-memory listener
+func newStatefulClient(t *testing.T, stateful bool) *fasthttp.Client {
+	router, err := NewRouterWithGenerator(createThingsSpec(), openapi.NewDefaultDataGeneratorWithSeed(7), zaptest.NewLogger(t))
+	require.NoError(t, err)
+	router.SetStateful(stateful)
+
+	ln := fasthttputil.NewInmemoryListener()
+	server := &fasthttp.Server{Handler: router.Handler}
+	go server.Serve(ln) //nolint:errcheck
+	t.Cleanup(func() { ln.Close() })
+
+	return &fasthttp.Client{
+		Dial: func(addr string) (net.Conn, error) { return ln.Dial() },
+	}
+}
+
+func doStatefulRequest(t *testing.T, client *fasthttp.Client, method, path, body string) (int, map[string]interface{}) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.Header.SetMethod(method)
+	req.SetRequestURI("http://vanta" + path)
+	if body != "" {
+		req.Header.SetContentType("application/json")
+		req.SetBodyString(body)
+	}
+	require.NoError(t, client.Do(req, resp))
+
+	var decoded map[string]interface{}
+	if len(resp.Body()) > 0 && resp.Body()[0] == '{' {
+		require.NoError(t, json.Unmarshal(resp.Body(), &decoded))
+	}
+	return resp.StatusCode(), decoded
+}
+
+func TestStateful_CRUDCycle(t *testing.T) {
+	client := newStatefulClient(t, true)
+
+	// Create: the client supplies a name, the color is seeded from the schema
+	status, created := doStatefulRequest(t, client, "POST", "/things", `{"name":"widget"}`)
+	require.Equal(t, fasthttp.StatusCreated, status)
+	assert.Equal(t, "widget", created["name"])
+	assert.Contains(t, []interface{}{"red", "green", "blue"}, created["color"])
+	assert.Equal(t, float64(1), created["id"])
+
+	// Read
+	status, fetched := doStatefulRequest(t, client, "GET", "/things/1", "")
+	require.Equal(t, fasthttp.StatusOK, status)
+	assert.Equal(t, created, fetched)
+
+	// Partial update keeps the untouched fields
+	status, patched := doStatefulRequest(t, client, "PATCH", "/things/1", `{"name":"gadget"}`)
+	require.Equal(t, fasthttp.StatusOK, status)
+	assert.Equal(t, "gadget", patched["name"])
+	assert.Equal(t, created["color"], patched["color"])
+
+	// Full replacement cannot change the id
+	status, replaced := doStatefulRequest(t, client, "PUT", "/things/1", `{"id":99,"name":"gizmo","color":"blue"}`)
+	require.Equal(t, fasthttp.StatusOK, status)
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "name": "gizmo", "color": "blue"}, replaced)
+
+	status, fetched = doStatefulRequest(t, client, "GET", "/things/1", "")
+	require.Equal(t, fasthttp.StatusOK, status)
+	assert.Equal(t, replaced, fetched)
+
+	// Delete
+	status, _ = doStatefulRequest(t, client, "DELETE", "/things/1", "")
+	assert.Equal(t, fasthttp.StatusNoContent, status)
+
+	status, _ = doStatefulRequest(t, client, "GET", "/things/1", "")
+	assert.Equal(t, fasthttp.StatusNotFound, status)
+
+	status, _ = doStatefulRequest(t, client, "DELETE", "/things/1", "")
+	assert.Equal(t, fasthttp.StatusNotFound, status)
+}
+
+func TestStateful_ListAndUnknownIDs(t *testing.T) {
+	client := newStatefulClient(t, true)
+
+	doStatefulRequest(t, client, "POST", "/things", `{"name":"a"}`)
+	doStatefulRequest(t, client, "POST", "/things", `{"name":"b"}`)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI("http://vanta/things")
+	require.NoError(t, client.Do(req, resp))
+
+	var things []map[string]interface{}
+	require.NoError(t, json.Unmarshal(resp.Body(), &things))
+	require.Len(t, things, 2)
+	assert.Equal(t, "a", things[0]["name"])
+	assert.Equal(t, "b", things[1]["name"])
+
+	status, _ := doStatefulRequest(t, client, "GET", "/things/42", "")
+	assert.Equal(t, fasthttp.StatusNotFound, status)
+
+	status, _ = doStatefulRequest(t, client, "PUT", "/things/42", `{"name":"c"}`)
+	assert.Equal(t, fasthttp.StatusNotFound, status)
+
+	status, _ = doStatefulRequest(t, client, "POST", "/things", `not json`)
+	assert.Equal(t, fasthttp.StatusBadRequest, status)
+}
+
+func TestStateful_DisabledGeneratesMockResponses(t *testing.T) {
+	client := newStatefulClient(t, false)
+
+	status, _ := doStatefulRequest(t, client, "POST", "/things", `{"name":"widget"}`)
+	require.Equal(t, fasthttp.StatusCreated, status)
+
+	// Without the store every id resolves to a generated object
+	status, fetched := doStatefulRequest(t, client, "GET", "/things/12345", "")
+	assert.Equal(t, fasthttp.StatusOK, status)
+	assert.NotNil(t, fetched["name"])
+}
@@ -0,0 +1,190 @@
+package chaos
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// BandwidthThrottleKey is the request user value key holding the pending bandwidth throttle
+const BandwidthThrottleKey = "chaos_bandwidth_throttle"
+
+// defaultChunksPerSecond controls the default chunk size derived from the rate
+const defaultChunksPerSecond = 10
+
+// BandwidthInjector implements chaos injection by trickling the response body
+// to the client at a capped number of bytes per second
+type BandwidthInjector struct {
+	logger *zap.Logger
+	rng    *rand.Rand
+}
+
+// BandwidthThrottle streams a response body in chunks at a fixed rate
+type BandwidthThrottle struct {
+	BytesPerSecond int
+	ChunkSize      int
+	Jitter         time.Duration
+
+	rng   *rand.Rand
+	sleep func(time.Duration)
+}
+
+// NewBandwidthInjector creates a new bandwidth injector
+func NewBandwidthInjector(logger *zap.Logger, rng *rand.Rand) *BandwidthInjector {
+	return &BandwidthInjector{
+		logger: logger,
+		rng:    rng,
+	}
+}
+
+// Type returns the type of chaos this injector handles
+func (b *BandwidthInjector) Type() string {
+	return "bandwidth"
+}
+
+// Validate validates the parameters for bandwidth throttling
+func (b *BandwidthInjector) Validate(params map[string]interface{}) error {
+	_, err := b.parseThrottle(params)
+	return err
+}
+
+// Inject marks the request so the response body is throttled once the handler has run.
+// The ChaosBandwidth middleware applies the throttle via ApplyBandwidthThrottle.
+func (b *BandwidthInjector) Inject(ctx *fasthttp.RequestCtx, params map[string]interface{}) error {
+	throttle, err := b.parseThrottle(params)
+	if err != nil {
+		return fmt.Errorf("failed to parse bandwidth parameters: %w", err)
+	}
+
+	b.logger.Debug("Injecting bandwidth chaos",
+		zap.String("path", string(ctx.Path())),
+		zap.Int("bytes_per_second", throttle.BytesPerSecond),
+		zap.Int("chunk_size", throttle.ChunkSize),
+		zap.Duration("jitter", throttle.Jitter))
+
+	ctx.SetUserValue(BandwidthThrottleKey, throttle)
+	return nil
+}
+
+// parseThrottle builds a throttle from scenario parameters
+func (b *BandwidthInjector) parseThrottle(params map[string]interface{}) (*BandwidthThrottle, error) {
+	rateRaw, ok := params["bytes_per_second"]
+	if !ok {
+		return nil, fmt.Errorf("bandwidth injector requires 'bytes_per_second' parameter")
+	}
+
+	rate, ok := toInt(rateRaw)
+	if !ok {
+		return nil, fmt.Errorf("bytes_per_second must be a number")
+	}
+	if rate <= 0 {
+		return nil, fmt.Errorf("bytes_per_second must be greater than 0")
+	}
+
+	throttle := &BandwidthThrottle{
+		BytesPerSecond: rate,
+		ChunkSize:      rate / defaultChunksPerSecond,
+		rng:            b.rng,
+		sleep:          time.Sleep,
+	}
+
+	if chunkRaw, ok := params["chunk_size"]; ok {
+		chunk, ok := toInt(chunkRaw)
+		if !ok || chunk <= 0 {
+			return nil, fmt.Errorf("chunk_size must be a positive number")
+		}
+		throttle.ChunkSize = chunk
+	}
+	if throttle.ChunkSize < 1 {
+		throttle.ChunkSize = 1
+	}
+
+	if jitterRaw, ok := params["jitter"]; ok {
+		jitterStr, ok := jitterRaw.(string)
+		if !ok {
+			return nil, fmt.Errorf("jitter must be a string duration (e.g., '20ms')")
+		}
+		jitter, err := time.ParseDuration(jitterStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jitter format: %w", err)
+		}
+		if jitter < 0 {
+			return nil, fmt.Errorf("jitter cannot be negative")
+		}
+		throttle.Jitter = jitter
+	}
+
+	return throttle, nil
+}
+
+// ChunkDelay returns the pause after writing a chunk of n bytes, including jitter
+func (t *BandwidthThrottle) ChunkDelay(n int) time.Duration {
+	delay := time.Duration(float64(n) / float64(t.BytesPerSecond) * float64(time.Second))
+	if t.Jitter > 0 && t.rng != nil {
+		delay += time.Duration(t.rng.Int63n(int64(t.Jitter) + 1))
+	}
+	return delay
+}
+
+// Wrap replaces the response body with a stream that trickles it at the configured rate
+func (t *BandwidthThrottle) Wrap(ctx *fasthttp.RequestCtx) {
+	body := append([]byte(nil), ctx.Response.Body()...)
+	if len(body) == 0 {
+		return
+	}
+
+	ctx.Response.SetBodyStreamWriter(func(w *bufio.Writer) {
+		for offset := 0; offset < len(body); offset += t.ChunkSize {
+			end := offset + t.ChunkSize
+			if end > len(body) {
+				end = len(body)
+			}
+			if _, err := w.Write(body[offset:end]); err != nil {
+				return
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+			t.sleep(t.ChunkDelay(end - offset))
+		}
+	})
+}
+
+// ApplyBandwidthThrottle wraps the response body if a bandwidth scenario was
+// injected for this request. It must run after the handler has produced the body.
+func ApplyBandwidthThrottle(ctx *fasthttp.RequestCtx) bool {
+	throttle, ok := ctx.UserValue(BandwidthThrottleKey).(*BandwidthThrottle)
+	if !ok || throttle == nil {
+		return false
+	}
+	throttle.Wrap(ctx)
+	return true
+}
+
+// toInt converts numeric parameter values decoded from YAML/JSON to int
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case uint:
+		return int(v), true
+	case uint32:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case float32:
+		return int(v), true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
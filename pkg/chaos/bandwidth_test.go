@@ -0,0 +1,81 @@
+package chaos
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBandwidthInjectorValidate(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	injector := NewBandwidthInjector(logger, rand.New(rand.NewSource(1)))
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr bool
+	}{
+		{"valid rate", map[string]interface{}{"bytes_per_second": 1024}, false},
+		{"valid float rate with jitter", map[string]interface{}{"bytes_per_second": 512.0, "jitter": "10ms"}, false},
+		{"valid chunk size", map[string]interface{}{"bytes_per_second": 1024, "chunk_size": 64}, false},
+		{"missing rate", map[string]interface{}{}, true},
+		{"zero rate", map[string]interface{}{"bytes_per_second": 0}, true},
+		{"non numeric rate", map[string]interface{}{"bytes_per_second": "fast"}, true},
+		{"invalid jitter", map[string]interface{}{"bytes_per_second": 1024, "jitter": "soon"}, true},
+		{"invalid chunk size", map[string]interface{}{"bytes_per_second": 1024, "chunk_size": -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := injector.Validate(tt.params)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBandwidthThrottleRespectsRate(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	injector := NewBandwidthInjector(logger, rand.New(rand.NewSource(1)))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/download")
+	require.NoError(t, injector.Inject(ctx, map[string]interface{}{
+		"bytes_per_second": 2000,
+		"jitter":           "5ms",
+	}))
+
+	body := strings.Repeat("x", 1000)
+	ctx.SetBodyString(body)
+	require.True(t, ApplyBandwidthThrottle(ctx))
+
+	var out bytes.Buffer
+	start := time.Now()
+	_, err := ctx.Response.WriteTo(&out)
+	elapsed := time.Since(start)
+	require.NoError(t, err)
+
+	// 1000 bytes at 2000 B/s must take at least half a second
+	assert.GreaterOrEqual(t, elapsed, 500*time.Millisecond)
+	assert.Less(t, elapsed, 2*time.Second)
+	assert.Contains(t, out.String(), "Transfer-Encoding: chunked")
+	assert.Equal(t, 5, strings.Count(out.String(), strings.Repeat("x", 200)), "body should arrive in rate-sized chunks")
+}
+
+func TestApplyBandwidthThrottleWithoutScenario(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.SetBodyString("untouched")
+
+	assert.False(t, ApplyBandwidthThrottle(ctx))
+	assert.Equal(t, "untouched", string(ctx.Response.Body()))
+}
@@ -0,0 +1,223 @@
+package chaos
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// BodyCorruptionKey is the request user value key holding the pending body corruption
+const BodyCorruptionKey = "chaos_body_corruption"
+
+// Body corruption modes
+const (
+	CorruptTruncate    = "truncate"
+	CorruptFlipBytes   = "flip_bytes"
+	CorruptInvalidJSON = "invalid_json"
+)
+
+// invalidJSONSuffix ends a corrupted body with a dangling key. Whether the cut
+// lands inside a string or not, the document can no longer parse.
+const invalidJSONSuffix = `{"chaos":`
+
+// BodyCorruptionInjector implements chaos injection by mangling the response
+// body the handler produced
+type BodyCorruptionInjector struct {
+	logger *zap.Logger
+	rng    *rand.Rand
+}
+
+// BodyCorruption mutates a response body once the handler has run
+type BodyCorruption struct {
+	// Mode is one of truncate, flip_bytes or invalid_json
+	Mode string
+	// TruncateBytes is the number of body bytes kept by truncate
+	TruncateBytes int
+	// FlipCount is the number of bytes altered by flip_bytes
+	FlipCount int
+	// DesyncContentLength keeps advertising the original body length
+	DesyncContentLength bool
+
+	logger *zap.Logger
+	rng    *rand.Rand
+}
+
+// NewBodyCorruptionInjector creates a new body corruption injector
+func NewBodyCorruptionInjector(logger *zap.Logger, rng *rand.Rand) *BodyCorruptionInjector {
+	return &BodyCorruptionInjector{
+		logger: logger,
+		rng:    rng,
+	}
+}
+
+// Type returns the type of chaos this injector handles
+func (b *BodyCorruptionInjector) Type() string {
+	return "body_corruption"
+}
+
+// Validate validates the parameters for body corruption
+func (b *BodyCorruptionInjector) Validate(params map[string]interface{}) error {
+	_, err := b.parseCorruption(params)
+	return err
+}
+
+// Inject marks the request so its response body is corrupted once the handler has run.
+// The Chaos middleware applies the corruption via ApplyBodyCorruption.
+func (b *BodyCorruptionInjector) Inject(ctx *fasthttp.RequestCtx, params map[string]interface{}) error {
+	corruption, err := b.parseCorruption(params)
+	if err != nil {
+		return fmt.Errorf("failed to parse body corruption parameters: %w", err)
+	}
+
+	b.logger.Debug("Injecting body corruption chaos",
+		zap.String("path", string(ctx.Path())),
+		zap.String("mode", corruption.Mode),
+		zap.Bool("desync_content_length", corruption.DesyncContentLength))
+
+	ctx.SetUserValue(BodyCorruptionKey, corruption)
+	return nil
+}
+
+// parseCorruption builds a body corruption from scenario parameters
+func (b *BodyCorruptionInjector) parseCorruption(params map[string]interface{}) (*BodyCorruption, error) {
+	mode, ok := params["mode"].(string)
+	if !ok {
+		return nil, fmt.Errorf("body corruption injector requires 'mode' parameter (truncate, flip_bytes, invalid_json)")
+	}
+
+	corruption := &BodyCorruption{
+		Mode:      mode,
+		FlipCount: 1,
+		logger:    b.logger,
+		rng:       b.rng,
+	}
+
+	switch mode {
+	case CorruptTruncate:
+		truncateRaw, ok := params["truncate_bytes"]
+		if !ok {
+			return nil, fmt.Errorf("truncate mode requires 'truncate_bytes' parameter")
+		}
+		truncate, ok := toInt(truncateRaw)
+		if !ok {
+			return nil, fmt.Errorf("truncate_bytes must be a number")
+		}
+		if truncate < 0 {
+			return nil, fmt.Errorf("truncate_bytes cannot be negative")
+		}
+		corruption.TruncateBytes = truncate
+	case CorruptFlipBytes:
+		if flipRaw, ok := params["flip_count"]; ok {
+			flips, ok := toInt(flipRaw)
+			if !ok || flips <= 0 {
+				return nil, fmt.Errorf("flip_count must be a positive number")
+			}
+			corruption.FlipCount = flips
+		}
+	case CorruptInvalidJSON:
+	default:
+		return nil, fmt.Errorf("unknown mode %q (supported: truncate, flip_bytes, invalid_json)", mode)
+	}
+
+	if desyncRaw, ok := params["desync_content_length"]; ok {
+		desync, ok := desyncRaw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("desync_content_length must be a boolean")
+		}
+		corruption.DesyncContentLength = desync
+	}
+
+	return corruption, nil
+}
+
+// Corrupt returns a corrupted copy of body
+func (c *BodyCorruption) Corrupt(body []byte) []byte {
+	corrupted := append([]byte(nil), body...)
+
+	switch c.Mode {
+	case CorruptTruncate:
+		if len(corrupted) > c.TruncateBytes {
+			corrupted = corrupted[:c.TruncateBytes]
+		}
+	case CorruptFlipBytes:
+		if len(corrupted) == 0 {
+			return corrupted
+		}
+		for i := 0; i < c.FlipCount; i++ {
+			// XOR with a non-zero mask so the byte always changes
+			corrupted[c.rng.Intn(len(corrupted))] ^= byte(1 + c.rng.Intn(255))
+		}
+	case CorruptInvalidJSON:
+		cut := c.rng.Intn(len(corrupted) + 1)
+		corrupted = append(corrupted[:cut], invalidJSONSuffix...)
+	}
+
+	return corrupted
+}
+
+// Apply replaces the response body with its corrupted form. Content-Length
+// follows the new body unless DesyncContentLength is set, in which case the
+// response is written by hand with the original length and the connection
+// closed. Streamed bodies are left alone rather than read into memory.
+func (c *BodyCorruption) Apply(ctx *fasthttp.RequestCtx) {
+	requestID := ""
+	if val, ok := ctx.UserValue("request_id").(string); ok {
+		requestID = val
+	}
+
+	if ctx.Response.IsBodyStream() {
+		if c.logger != nil {
+			c.logger.Debug("Skipped body corruption chaos for a streamed response",
+				zap.String("request_id", requestID),
+				zap.String("method", string(ctx.Method())),
+				zap.String("path", string(ctx.Path())))
+		}
+		return
+	}
+
+	body := ctx.Response.Body()
+	corrupted := c.Corrupt(body)
+
+	if c.logger != nil {
+		c.logger.Warn("Applied body corruption chaos",
+			zap.String("request_id", requestID),
+			zap.String("method", string(ctx.Method())),
+			zap.String("path", string(ctx.Path())),
+			zap.String("mode", c.Mode),
+			zap.Int("original_bytes", len(body)),
+			zap.Int("corrupted_bytes", len(corrupted)),
+			zap.Bool("desync_content_length", c.DesyncContentLength))
+	}
+
+	if !c.DesyncContentLength {
+		ctx.Response.SetBody(corrupted)
+		return
+	}
+
+	ctx.Response.Header.SetContentLength(len(body))
+	header := append([]byte(nil), ctx.Response.Header.Header()...)
+
+	ctx.HijackSetNoResponse(true)
+	ctx.Hijack(func(conn net.Conn) {
+		if _, err := conn.Write(header); err == nil && len(corrupted) > 0 {
+			conn.Write(corrupted)
+		}
+		if err := conn.Close(); err != nil && c.logger != nil {
+			c.logger.Debug("Failed to close connection after body corruption", zap.Error(err))
+		}
+	})
+}
+
+// ApplyBodyCorruption corrupts the response body if a body corruption scenario
+// was injected for this request. It must run after the handler has produced the body.
+func ApplyBodyCorruption(ctx *fasthttp.RequestCtx) bool {
+	corruption, ok := ctx.UserValue(BodyCorruptionKey).(*BodyCorruption)
+	if !ok || corruption == nil {
+		return false
+	}
+	corruption.Apply(ctx)
+	return true
+}
@@ -0,0 +1,141 @@
+package chaos
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+	"vanta/pkg/config"
+)
+
+const corruptionTestBody = `{"orders": [{"id": 1, "total": 9.5}, {"id": 2, "total": 12}]}`
+
+func TestBodyCorruptionInjectorValidate(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	injector := NewBodyCorruptionInjector(logger, rand.New(rand.NewSource(1)))
+
+	assert.Equal(t, "body_corruption", injector.Type())
+	assert.NoError(t, injector.Validate(map[string]interface{}{"mode": "truncate", "truncate_bytes": 10}))
+	assert.NoError(t, injector.Validate(map[string]interface{}{"mode": "flip_bytes", "flip_count": 3}))
+	assert.NoError(t, injector.Validate(map[string]interface{}{"mode": "invalid_json", "desync_content_length": true}))
+
+	assert.Error(t, injector.Validate(map[string]interface{}{}))
+	assert.Error(t, injector.Validate(map[string]interface{}{"mode": "shuffle"}))
+	assert.Error(t, injector.Validate(map[string]interface{}{"mode": "truncate"}))
+	assert.Error(t, injector.Validate(map[string]interface{}{"mode": "truncate", "truncate_bytes": -1}))
+	assert.Error(t, injector.Validate(map[string]interface{}{"mode": "flip_bytes", "flip_count": 0}))
+	assert.Error(t, injector.Validate(map[string]interface{}{"mode": "invalid_json", "desync_content_length": "yes"}))
+}
+
+// corruptResponse runs a response through the body corruption injector
+func corruptResponse(t *testing.T, logger *zap.Logger, params map[string]interface{}) *fasthttp.RequestCtx {
+	t.Helper()
+	injector := NewBodyCorruptionInjector(logger, rand.New(rand.NewSource(1)))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/orders")
+	ctx.SetUserValue("request_id", "req-123")
+	require.NoError(t, injector.Inject(ctx, params))
+
+	ctx.SetBodyString(corruptionTestBody)
+	require.True(t, ApplyBodyCorruption(ctx))
+	return ctx
+}
+
+func TestBodyCorruptionTruncate(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	ctx := corruptResponse(t, zap.New(core), map[string]interface{}{"mode": "truncate", "truncate_bytes": 12})
+
+	assert.Equal(t, corruptionTestBody[:12], string(ctx.Response.Body()))
+
+	// Content-Length follows the shortened body
+	var response fasthttp.Response
+	ctx.Response.CopyTo(&response)
+	assert.Contains(t, response.String(), "Content-Length: 12\r\n")
+
+	applied := logs.FilterMessage("Applied body corruption chaos").All()
+	require.Len(t, applied, 1)
+	assert.Equal(t, "req-123", applied[0].ContextMap()["request_id"])
+	assert.Equal(t, "truncate", applied[0].ContextMap()["mode"])
+}
+
+func TestBodyCorruptionSkipsStreamedBodies(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	injector := NewBodyCorruptionInjector(zap.New(core), rand.New(rand.NewSource(1)))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/export")
+	require.NoError(t, injector.Inject(ctx, map[string]interface{}{"mode": "truncate", "truncate_bytes": 4}))
+
+	stream := strings.NewReader(corruptionTestBody)
+	ctx.SetBodyStream(stream, -1)
+	require.True(t, ApplyBodyCorruption(ctx))
+
+	// The stream is neither read nor replaced
+	assert.True(t, ctx.Response.IsBodyStream())
+	assert.Equal(t, len(corruptionTestBody), stream.Len())
+	assert.Equal(t, 1, logs.FilterMessage("Skipped body corruption chaos for a streamed response").Len())
+	assert.Zero(t, logs.FilterMessage("Applied body corruption chaos").Len())
+}
+
+func TestBodyCorruptionFlipBytes(t *testing.T) {
+	ctx := corruptResponse(t, zaptest.NewLogger(t), map[string]interface{}{"mode": "flip_bytes", "flip_count": 1})
+
+	body := ctx.Response.Body()
+	require.Len(t, body, len(corruptionTestBody))
+	changed := 0
+	for i := range body {
+		if body[i] != corruptionTestBody[i] {
+			changed++
+		}
+	}
+	assert.Equal(t, 1, changed)
+}
+
+func TestBodyCorruptionInvalidJSON(t *testing.T) {
+	corruption := &BodyCorruption{Mode: CorruptInvalidJSON, rng: rand.New(rand.NewSource(1))}
+
+	for _, body := range []string{corruptionTestBody, `"text"`, `[]`, `42`, ``} {
+		for i := 0; i < 50; i++ {
+			corrupted := corruption.Corrupt([]byte(body))
+			assert.False(t, json.Valid(corrupted), "%q corrupted to valid JSON %q", body, corrupted)
+		}
+	}
+}
+
+func TestBodyCorruptionAppliesAtScenarioRate(t *testing.T) {
+	engine := NewDefaultChaosEngine(zap.NewNop())
+	require.NoError(t, engine.LoadScenarios([]config.ScenarioConfig{
+		{
+			Name:        "broken_json",
+			Type:        "body_corruption",
+			Endpoints:   []string{"/api/*"},
+			Probability: 0.3,
+			Parameters:  map[string]interface{}{"mode": "invalid_json"},
+		},
+	}))
+
+	const requests = 2000
+	broken := 0
+	for i := 0; i < requests; i++ {
+		ctx := &fasthttp.RequestCtx{}
+		if apply, action := engine.ShouldApplyChaos("/api/orders"); apply {
+			require.NoError(t, engine.ApplyChaos(action, ctx))
+		}
+		ctx.SetBodyString(corruptionTestBody)
+		ApplyBodyCorruption(ctx)
+
+		if !json.Valid(ctx.Response.Body()) {
+			broken++
+		}
+	}
+
+	assert.InDelta(t, 0.3*requests, broken, 0.05*requests)
+}
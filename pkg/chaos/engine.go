@@ -1,9 +1,12 @@
 package chaos
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"regexp"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -31,6 +34,7 @@ type DefaultChaosEngine struct {
 	logger    *zap.Logger
 	rng       *rand.Rand
 	startTime time.Time
+	now       func() time.Time
 }
 
 // ChaosScenario represents an active chaos scenario
@@ -38,6 +42,8 @@ type ChaosScenario struct {
 	Config       config.ScenarioConfig
 	Injector     Injector
 	Matcher      *EndpointMatcher
+	Methods      map[string]bool // Empty means all methods
+	Schedule     *Schedule       // Nil means always active
 	LastApplied  time.Time
 	ApplyCount   int64
 	FailedCount  int64
@@ -49,6 +55,25 @@ type EndpointMatcher struct {
 	compiled []*regexp.Regexp
 }
 
+// lockedSource makes a rand.Source safe for concurrent use, since the
+// engine and its injectors share one generator across request goroutines
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
 // NewDefaultChaosEngine creates a new default chaos engine
 func NewDefaultChaosEngine(logger *zap.Logger) *DefaultChaosEngine {
 	engine := &DefaultChaosEngine{
@@ -56,8 +81,9 @@ func NewDefaultChaosEngine(logger *zap.Logger) *DefaultChaosEngine {
 		injectors: make(map[string]Injector),
 		enabled:   false,
 		logger:    logger,
-		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		rng:       rand.New(&lockedSource{src: rand.NewSource(time.Now().UnixNano())}),
 		startTime: time.Now(),
+		now:       time.Now,
 	}
 	
 	// Register built-in injectors
@@ -71,6 +97,16 @@ func (e *DefaultChaosEngine) registerBuiltinInjectors() {
 	// These will be implemented in separate files
 	e.injectors["latency"] = NewLatencyInjector(e.logger, e.rng)
 	e.injectors["error"] = NewErrorInjector(e.logger, e.rng)
+	e.injectors["bandwidth"] = NewBandwidthInjector(e.logger, e.rng)
+	e.injectors["connection_reset"] = NewConnectionResetInjector(e.logger, e.rng)
+	e.injectors["body_corruption"] = NewBodyCorruptionInjector(e.logger, e.rng)
+}
+
+// SetClock overrides the time source used to evaluate scenario schedules
+func (e *DefaultChaosEngine) SetClock(now func() time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.now = now
 }
 
 // LoadScenarios loads chaos scenarios from configuration
@@ -83,21 +119,26 @@ func (e *DefaultChaosEngine) LoadScenarios(scenarios []config.ScenarioConfig) er
 	// Clear existing scenarios
 	e.scenarios = make(map[string]*ChaosScenario)
 	
+	// Valid scenarios are still loaded when others fail; the failures are
+	// reported together so callers can surface the misconfiguration.
+	var loadErrs []error
 	for _, scenarioConfig := range scenarios {
 		if err := e.loadScenario(scenarioConfig); err != nil {
 			e.logger.Error("Failed to load scenario", 
 				zap.String("name", scenarioConfig.Name),
 				zap.Error(err))
+			loadErrs = append(loadErrs, fmt.Errorf("scenario %q: %w", scenarioConfig.Name, err))
 			continue
 		}
 	}
 	
 	e.enabled = len(e.scenarios) > 0
-	e.logger.Info("Chaos scenarios loaded successfully", 
+	e.logger.Info("Chaos scenarios loaded", 
 		zap.Int("active_scenarios", len(e.scenarios)),
+		zap.Int("failed_scenarios", len(loadErrs)),
 		zap.Bool("enabled", e.enabled))
 	
-	return nil
+	return errors.Join(loadErrs...)
 }
 
 // loadScenario loads a single scenario
@@ -132,11 +173,32 @@ func (e *DefaultChaosEngine) loadScenario(scenarioConfig config.ScenarioConfig)
 		return fmt.Errorf("failed to create endpoint matcher: %w", err)
 	}
 	
+	// Build method selector
+	var methods map[string]bool
+	if len(scenarioConfig.Methods) > 0 {
+		methods = make(map[string]bool, len(scenarioConfig.Methods))
+		for _, method := range scenarioConfig.Methods {
+			method = strings.ToUpper(strings.TrimSpace(method))
+			if method == "" {
+				return fmt.Errorf("scenario methods cannot contain empty values")
+			}
+			methods[method] = true
+		}
+	}
+	
+	// Parse activation schedule
+	schedule, err := NewSchedule(scenarioConfig.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+	
 	// Create and store scenario
 	scenario := &ChaosScenario{
 		Config:   scenarioConfig,
 		Injector: injector,
 		Matcher:  matcher,
+		Methods:  methods,
+		Schedule: schedule,
 	}
 	
 	e.scenarios[scenarioConfig.Name] = scenario
@@ -145,13 +207,21 @@ func (e *DefaultChaosEngine) loadScenario(scenarioConfig config.ScenarioConfig)
 		zap.String("name", scenarioConfig.Name),
 		zap.String("type", scenarioConfig.Type),
 		zap.Float64("probability", scenarioConfig.Probability),
-		zap.Strings("endpoints", scenarioConfig.Endpoints))
+		zap.Strings("endpoints", scenarioConfig.Endpoints),
+		zap.Strings("methods", scenarioConfig.Methods))
 	
 	return nil
 }
 
 // ShouldApplyChaos determines if chaos should be applied to the given endpoint
+// regardless of the request method
 func (e *DefaultChaosEngine) ShouldApplyChaos(endpoint string) (bool, ChaosAction) {
+	return e.ShouldApplyChaosToRequest("", endpoint)
+}
+
+// ShouldApplyChaosToRequest determines if chaos should be applied to a request
+// with the given method and endpoint. An empty method matches every scenario.
+func (e *DefaultChaosEngine) ShouldApplyChaosToRequest(method, endpoint string) (bool, ChaosAction) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 	
@@ -161,16 +231,21 @@ func (e *DefaultChaosEngine) ShouldApplyChaos(endpoint string) (bool, ChaosActio
 		return false, ChaosAction{}
 	}
 	
+	now := e.now()
+	
 	// Check each scenario
 	for _, scenario := range e.scenarios {
-		if scenario.Matcher.Matches(endpoint) {
+		if !scenario.Schedule.IsActive(now) {
+			continue
+		}
+		if scenario.Matcher.Matches(endpoint) && scenario.MatchesMethod(method) {
 			// Check probability
-			if e.rng.Float64() <= scenario.Config.Probability {
+			if e.rng.Float64() < scenario.Config.Probability {
 				action := ChaosAction{
 					Type:       scenario.Config.Type,
 					Scenario:   scenario.Config.Name,
 					Parameters: scenario.Config.Parameters,
-					Timestamp:  time.Now(),
+					Timestamp:  now,
 				}
 				return true, action
 			}
@@ -219,15 +294,20 @@ func (e *DefaultChaosEngine) ApplyChaos(action ChaosAction, ctx *fasthttp.Reques
 	return nil
 }
 
-// GetActiveScenarios returns a list of currently active scenario names
+// GetActiveScenarios returns the names of loaded scenarios whose schedule
+// is active right now. Unscheduled scenarios are always active.
 func (e *DefaultChaosEngine) GetActiveScenarios() []string {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 	
+	now := e.now()
 	scenarios := make([]string, 0, len(e.scenarios))
-	for name := range e.scenarios {
-		scenarios = append(scenarios, name)
+	for name, scenario := range e.scenarios {
+		if scenario.Schedule.IsActive(now) {
+			scenarios = append(scenarios, name)
+		}
 	}
+	sort.Strings(scenarios)
 	return scenarios
 }
 
@@ -243,10 +323,12 @@ func (e *DefaultChaosEngine) GetStats() EngineStats {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 	
+	now := e.now()
 	scenarioStats := make(map[string]ScenarioStats)
 	for name, scenario := range e.scenarios {
 		scenarioStats[name] = ScenarioStats{
 			Name:         name,
+			Active:       scenario.Schedule.IsActive(now),
 			AppliedCount: atomic.LoadInt64(&scenario.ApplyCount),
 			FailedCount:  atomic.LoadInt64(&scenario.FailedCount),
 			LastApplied:  scenario.LastApplied,
@@ -274,6 +356,14 @@ func (e *DefaultChaosEngine) Stop() error {
 	return nil
 }
 
+// MatchesMethod checks if the scenario applies to the given HTTP method
+func (s *ChaosScenario) MatchesMethod(method string) bool {
+	if len(s.Methods) == 0 || method == "" {
+		return true
+	}
+	return s.Methods[strings.ToUpper(method)]
+}
+
 // NewEndpointMatcher creates a new endpoint matcher
 func NewEndpointMatcher(patterns []string) (*EndpointMatcher, error) {
 	matcher := &EndpointMatcher{
@@ -282,11 +372,10 @@ func NewEndpointMatcher(patterns []string) (*EndpointMatcher, error) {
 	}
 	
 	for _, pattern := range patterns {
-		// Convert glob-like patterns to regex
-		// Replace * with .* and escape other regex characters
-		regexPattern := regexp.QuoteMeta(pattern)
-		regexPattern = "^" + regexPattern + "$"
-		regexPattern = regexp.MustCompile(`\\\*`).ReplaceAllString(regexPattern, ".*")
+		regexPattern, err := globToRegex(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
 		
 		compiled, err := regexp.Compile(regexPattern)
 		if err != nil {
@@ -299,6 +388,46 @@ func NewEndpointMatcher(patterns []string) (*EndpointMatcher, error) {
 	return matcher, nil
 }
 
+// globToRegex converts a glob-like path pattern into an anchored regex.
+// Supported syntax: * (any characters), ? (one non-slash character) and
+// [...] character classes, with [!...] for negation.
+func globToRegex(pattern string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end == -1 {
+				return "", fmt.Errorf("unterminated character class")
+			}
+			class := pattern[i+1 : i+1+end]
+			if class == "" {
+				return "", fmt.Errorf("empty character class")
+			}
+			sb.WriteByte('[')
+			if class[0] == '!' {
+				sb.WriteByte('^')
+				class = class[1:]
+			}
+			sb.WriteString(strings.ReplaceAll(class, `\`, `\\`))
+			sb.WriteByte(']')
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	
+	sb.WriteString("$")
+	return sb.String(), nil
+}
+
 // Matches checks if the given endpoint matches any of the patterns
 func (m *EndpointMatcher) Matches(endpoint string) bool {
 	for _, regex := range m.compiled {
@@ -307,4 +436,4 @@ func (m *EndpointMatcher) Matches(endpoint string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
@@ -178,6 +178,75 @@ func TestShouldApplyChaos(t *testing.T) {
 	assert.Empty(t, action.Type)
 }
 
+func TestShouldApplyChaosToRequestMethodSelector(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	engine := NewDefaultChaosEngine(logger)
+	
+	err := engine.LoadScenarios([]config.ScenarioConfig{
+		{
+			Name:        "payments_errors",
+			Type:        "error",
+			Endpoints:   []string{"/api/payments"},
+			Methods:     []string{"post"},
+			Probability: 1.0,
+			Parameters: map[string]interface{}{
+				"error_codes": []interface{}{503},
+			},
+		},
+	})
+	require.NoError(t, err)
+	
+	should, action := engine.ShouldApplyChaosToRequest("POST", "/api/payments")
+	assert.True(t, should)
+	assert.Equal(t, "error", action.Type)
+	
+	should, _ = engine.ShouldApplyChaosToRequest("GET", "/api/payments")
+	assert.False(t, should)
+	
+	should, _ = engine.ShouldApplyChaosToRequest("POST", "/api/orders")
+	assert.False(t, should)
+}
+
+func TestErrorInjectionRateWithinTolerance(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	engine := NewDefaultChaosEngine(logger)
+	
+	err := engine.LoadScenarios([]config.ScenarioConfig{
+		{
+			Name:        "payments_errors",
+			Type:        "error",
+			Endpoints:   []string{"/api/payments"},
+			Methods:     []string{"POST"},
+			Probability: 0.3,
+			Parameters: map[string]interface{}{
+				"error_codes": []interface{}{500, 503},
+				"custom_body": `{"error": "payment backend unavailable"}`,
+			},
+		},
+	})
+	require.NoError(t, err)
+	
+	const requests = 10000
+	injected := 0
+	for i := 0; i < requests; i++ {
+		should, action := engine.ShouldApplyChaosToRequest("POST", "/api/payments")
+		if !should {
+			continue
+		}
+		injected++
+		
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/api/payments")
+		require.NoError(t, engine.ApplyChaos(action, ctx))
+		assert.Contains(t, []int{500, 503}, ctx.Response.StatusCode())
+		assert.Equal(t, `{"error": "payment backend unavailable"}`, string(ctx.Response.Body()))
+	}
+	
+	rate := float64(injected) / requests
+	assert.InDelta(t, 0.3, rate, 0.03, "injected rate %.3f outside tolerance", rate)
+	assert.Equal(t, int64(injected), engine.GetStats().ChaosApplied)
+}
+
 func TestApplyChaos(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	engine := NewDefaultChaosEngine(logger)
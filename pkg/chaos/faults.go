@@ -44,6 +44,10 @@ func (e *ErrorInjector) Validate(params map[string]interface{}) error {
 	// Validate each error code
 	for i := 0; i < errorCodesValue.Len(); i++ {
 		codeValue := errorCodesValue.Index(i)
+		// Values decoded from YAML/JSON arrive as []interface{}
+		if codeValue.Kind() == reflect.Interface {
+			codeValue = codeValue.Elem()
+		}
 		
 		var code int
 		switch codeValue.Kind() {
@@ -113,6 +117,10 @@ func (e *ErrorInjector) parseErrorCodes(errorCodesRaw interface{}) ([]int, error
 	codes := make([]int, errorCodesValue.Len())
 	for i := 0; i < errorCodesValue.Len(); i++ {
 		codeValue := errorCodesValue.Index(i)
+		// Values decoded from YAML/JSON arrive as []interface{}
+		if codeValue.Kind() == reflect.Interface {
+			codeValue = codeValue.Elem()
+		}
 		
 		var code int
 		switch codeValue.Kind() {
@@ -0,0 +1,124 @@
+package chaos
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// ConnectionResetKey is the request user value key holding the pending connection abort
+const ConnectionResetKey = "chaos_connection_reset"
+
+// ConnectionResetInjector implements chaos injection by dropping the client
+// connection part-way through the response
+type ConnectionResetInjector struct {
+	logger *zap.Logger
+	rng    *rand.Rand
+}
+
+// ConnectionReset aborts a response after writing the headers and a prefix of the body
+type ConnectionReset struct {
+	// AfterBytes is the number of body bytes written before the connection is closed
+	AfterBytes int
+
+	logger *zap.Logger
+}
+
+// NewConnectionResetInjector creates a new connection reset injector
+func NewConnectionResetInjector(logger *zap.Logger, rng *rand.Rand) *ConnectionResetInjector {
+	return &ConnectionResetInjector{
+		logger: logger,
+		rng:    rng,
+	}
+}
+
+// Type returns the type of chaos this injector handles
+func (c *ConnectionResetInjector) Type() string {
+	return "connection_reset"
+}
+
+// Validate validates the parameters for connection reset injection
+func (c *ConnectionResetInjector) Validate(params map[string]interface{}) error {
+	_, err := c.parseReset(params)
+	return err
+}
+
+// Inject marks the request so its connection is dropped once the handler has run.
+// The Chaos middleware performs the abort via ApplyConnectionReset.
+func (c *ConnectionResetInjector) Inject(ctx *fasthttp.RequestCtx, params map[string]interface{}) error {
+	reset, err := c.parseReset(params)
+	if err != nil {
+		return fmt.Errorf("failed to parse connection reset parameters: %w", err)
+	}
+
+	requestID := ""
+	if val, ok := ctx.UserValue("request_id").(string); ok {
+		requestID = val
+	}
+
+	c.logger.Warn("Injecting connection reset chaos",
+		zap.String("request_id", requestID),
+		zap.String("method", string(ctx.Method())),
+		zap.String("path", string(ctx.Path())),
+		zap.Int("after_bytes", reset.AfterBytes))
+
+	ctx.SetUserValue(ConnectionResetKey, reset)
+	return nil
+}
+
+// parseReset builds a connection reset from scenario parameters
+func (c *ConnectionResetInjector) parseReset(params map[string]interface{}) (*ConnectionReset, error) {
+	reset := &ConnectionReset{logger: c.logger}
+
+	if afterRaw, ok := params["after_bytes"]; ok {
+		after, ok := toInt(afterRaw)
+		if !ok {
+			return nil, fmt.Errorf("after_bytes must be a number")
+		}
+		if after < 0 {
+			return nil, fmt.Errorf("after_bytes cannot be negative")
+		}
+		reset.AfterBytes = after
+	}
+
+	return reset, nil
+}
+
+// Abort hijacks the connection, writes the response headers and at most
+// AfterBytes of the body, then closes the connection without completing it
+func (r *ConnectionReset) Abort(ctx *fasthttp.RequestCtx) {
+	body := append([]byte(nil), ctx.Response.Body()...)
+
+	// Advertise the full body so the client notices the truncation
+	ctx.Response.Header.SetContentLength(len(body))
+	header := append([]byte(nil), ctx.Response.Header.Header()...)
+
+	partial := body
+	if len(partial) > r.AfterBytes {
+		partial = partial[:r.AfterBytes]
+	}
+
+	ctx.HijackSetNoResponse(true)
+	ctx.Hijack(func(conn net.Conn) {
+		if _, err := conn.Write(header); err == nil && len(partial) > 0 {
+			conn.Write(partial)
+		}
+		if err := conn.Close(); err != nil && r.logger != nil {
+			r.logger.Debug("Failed to close connection after chaos reset", zap.Error(err))
+		}
+	})
+}
+
+// ApplyConnectionReset aborts the connection if a connection reset scenario
+// was injected for this request. It must run after the handler has produced the response.
+func ApplyConnectionReset(ctx *fasthttp.RequestCtx) bool {
+	reset, ok := ctx.UserValue(ConnectionResetKey).(*ConnectionReset)
+	if !ok || reset == nil {
+		return false
+	}
+	reset.Abort(ctx)
+	return true
+}
@@ -0,0 +1,40 @@
+package chaos
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestConnectionResetInjectorValidate(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	injector := NewConnectionResetInjector(logger, rand.New(rand.NewSource(1)))
+
+	assert.Equal(t, "connection_reset", injector.Type())
+	assert.NoError(t, injector.Validate(map[string]interface{}{}))
+	assert.NoError(t, injector.Validate(map[string]interface{}{"after_bytes": 16}))
+	assert.Error(t, injector.Validate(map[string]interface{}{"after_bytes": -1}))
+	assert.Error(t, injector.Validate(map[string]interface{}{"after_bytes": "half"}))
+}
+
+func TestConnectionResetInjectorMarksRequest(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	injector := NewConnectionResetInjector(logger, rand.New(rand.NewSource(1)))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/orders")
+	ctx.SetUserValue("request_id", "req-123")
+
+	require.NoError(t, injector.Inject(ctx, map[string]interface{}{"after_bytes": 4}))
+
+	reset, ok := ctx.UserValue(ConnectionResetKey).(*ConnectionReset)
+	require.True(t, ok)
+	assert.Equal(t, 4, reset.AfterBytes)
+
+	// Nothing happens for requests without an injected reset
+	assert.False(t, ApplyConnectionReset(&fasthttp.RequestCtx{}))
+}
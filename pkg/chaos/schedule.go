@@ -0,0 +1,98 @@
+package chaos
+
+import (
+	"fmt"
+	"time"
+
+	"vanta/pkg/config"
+)
+
+// timeOfDayLayouts are the accepted formats for daily schedule starts
+var timeOfDayLayouts = []string{"15:04", "15:04:05"}
+
+// Schedule restricts a scenario to recurring activation windows
+type Schedule struct {
+	start     time.Time     // Absolute anchor; zero when timeOfDay is used
+	timeOfDay time.Duration // Offset from midnight for daily schedules
+	daily     bool
+	duration  time.Duration
+	period    time.Duration
+}
+
+// NewSchedule parses a schedule configuration
+func NewSchedule(cfg *config.ScheduleConfig) (*Schedule, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	if cfg.Duration <= 0 {
+		return nil, fmt.Errorf("schedule duration must be greater than 0")
+	}
+	if cfg.Period < 0 || (cfg.Period > 0 && cfg.Period < cfg.Duration) {
+		return nil, fmt.Errorf("schedule period must be at least the schedule duration")
+	}
+
+	schedule := &Schedule{
+		duration: cfg.Duration,
+		period:   cfg.Period,
+	}
+
+	switch {
+	case cfg.Start == "":
+		if cfg.Period == 0 {
+			return nil, fmt.Errorf("schedule without a start requires a period")
+		}
+		schedule.start = time.Unix(0, 0).UTC()
+
+	default:
+		if start, err := time.Parse(time.RFC3339, cfg.Start); err == nil {
+			schedule.start = start
+			break
+		}
+
+		parsed := false
+		for _, layout := range timeOfDayLayouts {
+			if tod, err := time.Parse(layout, cfg.Start); err == nil {
+				schedule.timeOfDay = time.Duration(tod.Hour())*time.Hour +
+					time.Duration(tod.Minute())*time.Minute +
+					time.Duration(tod.Second())*time.Second
+				schedule.daily = true
+				parsed = true
+				break
+			}
+		}
+		if !parsed {
+			return nil, fmt.Errorf("invalid schedule start %q: use HH:MM, HH:MM:SS or RFC3339", cfg.Start)
+		}
+		if schedule.period == 0 {
+			schedule.period = 24 * time.Hour
+		}
+	}
+
+	return schedule, nil
+}
+
+// IsActive reports whether now falls inside one of the schedule windows
+func (s *Schedule) IsActive(now time.Time) bool {
+	if s == nil {
+		return true
+	}
+
+	anchor := s.start
+	if s.daily {
+		year, month, day := now.Date()
+		anchor = time.Date(year, month, day, 0, 0, 0, 0, now.Location()).Add(s.timeOfDay)
+		if anchor.After(now) {
+			anchor = anchor.AddDate(0, 0, -1)
+		}
+	}
+
+	elapsed := now.Sub(anchor)
+	if elapsed < 0 {
+		return false
+	}
+	if s.period > 0 {
+		elapsed %= s.period
+	}
+	return elapsed < s.duration
+}
@@ -0,0 +1,142 @@
+package chaos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"vanta/pkg/config"
+)
+
+func TestNewScheduleValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.ScheduleConfig
+		wantErr bool
+	}{
+		{"nil schedule", nil, false},
+		{"time of day", &config.ScheduleConfig{Start: "10:00", Duration: 5 * time.Minute}, false},
+		{"rfc3339 start", &config.ScheduleConfig{Start: "2026-01-01T10:00:00Z", Duration: time.Minute}, false},
+		{"period only", &config.ScheduleConfig{Duration: time.Minute, Period: 3 * time.Minute}, false},
+		{"missing duration", &config.ScheduleConfig{Start: "10:00"}, true},
+		{"period shorter than duration", &config.ScheduleConfig{Duration: time.Hour, Period: time.Minute}, true},
+		{"no start and no period", &config.ScheduleConfig{Duration: time.Minute}, true},
+		{"invalid start", &config.ScheduleConfig{Start: "ten o'clock", Duration: time.Minute}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSchedule(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestScheduleTimeOfDayWindow(t *testing.T) {
+	schedule, err := NewSchedule(&config.ScheduleConfig{Start: "10:00", Duration: 5 * time.Minute})
+	require.NoError(t, err)
+
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	assert.False(t, schedule.IsActive(day.Add(9*time.Hour+59*time.Minute)))
+	assert.True(t, schedule.IsActive(day.Add(10*time.Hour)))
+	assert.True(t, schedule.IsActive(day.Add(10*time.Hour+4*time.Minute)))
+	assert.False(t, schedule.IsActive(day.Add(10*time.Hour+5*time.Minute)))
+
+	// The window repeats the next day
+	assert.True(t, schedule.IsActive(day.Add(34*time.Hour+time.Minute)))
+}
+
+func TestSchedulePeriodicWindow(t *testing.T) {
+	// Active for the first minute of every third minute
+	schedule, err := NewSchedule(&config.ScheduleConfig{Duration: time.Minute, Period: 3 * time.Minute})
+	require.NoError(t, err)
+
+	base := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	assert.True(t, schedule.IsActive(base.Add(30*time.Second)))
+	assert.False(t, schedule.IsActive(base.Add(90*time.Second)))
+	assert.False(t, schedule.IsActive(base.Add(150*time.Second)))
+	assert.True(t, schedule.IsActive(base.Add(3*time.Minute)))
+}
+
+func TestScheduleAbsoluteWindow(t *testing.T) {
+	schedule, err := NewSchedule(&config.ScheduleConfig{Start: "2026-03-10T10:00:00Z", Duration: time.Minute})
+	require.NoError(t, err)
+
+	start := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	assert.False(t, schedule.IsActive(start.Add(-time.Second)))
+	assert.True(t, schedule.IsActive(start))
+	assert.False(t, schedule.IsActive(start.Add(time.Minute)))
+	assert.False(t, schedule.IsActive(start.Add(24*time.Hour)))
+}
+
+func TestEngineScheduledScenarioActivation(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	engine := NewDefaultChaosEngine(logger)
+
+	now := time.Date(2026, 3, 10, 9, 59, 0, 0, time.UTC)
+	engine.SetClock(func() time.Time { return now })
+
+	err := engine.LoadScenarios([]config.ScenarioConfig{
+		{
+			Name:        "morning_degradation",
+			Type:        "latency",
+			Endpoints:   []string{"/api/*"},
+			Probability: 1.0,
+			Parameters:  map[string]interface{}{"min_delay": "1ms", "max_delay": "2ms"},
+			Schedule:    &config.ScheduleConfig{Start: "10:00", Duration: 5 * time.Minute},
+		},
+		{
+			Name:        "always_on",
+			Type:        "latency",
+			Endpoints:   []string{"/health"},
+			Probability: 1.0,
+			Parameters:  map[string]interface{}{"min_delay": "1ms", "max_delay": "2ms"},
+		},
+	})
+	require.NoError(t, err)
+
+	// Before the window only the unscheduled scenario is active
+	assert.Equal(t, []string{"always_on"}, engine.GetActiveScenarios())
+	shouldApply, _ := engine.ShouldApplyChaos("/api/users")
+	assert.False(t, shouldApply)
+	assert.False(t, engine.GetStats().ScenarioStats["morning_degradation"].Active)
+
+	// Inside the window the scheduled scenario fires
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, []string{"always_on", "morning_degradation"}, engine.GetActiveScenarios())
+	shouldApply, action := engine.ShouldApplyChaos("/api/users")
+	assert.True(t, shouldApply)
+	assert.Equal(t, "morning_degradation", action.Scenario)
+	assert.Equal(t, now, action.Timestamp)
+	assert.True(t, engine.GetStats().ScenarioStats["morning_degradation"].Active)
+
+	// After the window it is dormant again
+	now = now.Add(5 * time.Minute)
+	shouldApply, _ = engine.ShouldApplyChaos("/api/users")
+	assert.False(t, shouldApply)
+	assert.Equal(t, []string{"always_on"}, engine.GetActiveScenarios())
+}
+
+func TestEngineRejectsInvalidSchedule(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	engine := NewDefaultChaosEngine(logger)
+
+	err := engine.LoadScenarios([]config.ScenarioConfig{
+		{
+			Name:        "bad_schedule",
+			Type:        "latency",
+			Endpoints:   []string{"/api/*"},
+			Probability: 1.0,
+			Parameters:  map[string]interface{}{"min_delay": "1ms", "max_delay": "2ms"},
+			Schedule:    &config.ScheduleConfig{Start: "25:99", Duration: time.Minute},
+		},
+	})
+	assert.Error(t, err)
+	assert.Empty(t, engine.GetActiveScenarios())
+}
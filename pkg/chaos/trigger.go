@@ -0,0 +1,108 @@
+package chaos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// TriggerHeader is the request header that injects chaos into that single
+// request, e.g. "X-Chaos: latency=2s" or "X-Chaos: status=503"
+const TriggerHeader = "X-Chaos"
+
+// TriggerScenario is the scenario name reported for header-triggered actions
+const TriggerScenario = "header_trigger"
+
+// ParseTrigger parses an X-Chaos header value into the actions it requests.
+// Directives are comma separated: latency=<duration> delays the request and
+// status=<code> replaces the response with an error.
+func ParseTrigger(value string) ([]ChaosAction, error) {
+	var actions []ChaosAction
+	now := time.Now()
+
+	for _, directive := range strings.Split(value, ",") {
+		directive = strings.TrimSpace(directive)
+		if directive == "" {
+			continue
+		}
+
+		key, arg, ok := strings.Cut(directive, "=")
+		if !ok {
+			return nil, fmt.Errorf("directive %q must be key=value", directive)
+		}
+		arg = strings.TrimSpace(arg)
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "latency":
+			delay, err := time.ParseDuration(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid latency %q: %w", arg, err)
+			}
+			actions = append(actions, ChaosAction{
+				Type:     "latency",
+				Scenario: TriggerScenario,
+				Parameters: map[string]interface{}{
+					"min_delay": delay.String(),
+					"max_delay": delay.String(),
+				},
+				Timestamp: now,
+			})
+		case "status":
+			code, err := strconv.Atoi(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid status %q: must be an HTTP status code", arg)
+			}
+			actions = append(actions, ChaosAction{
+				Type:     "error",
+				Scenario: TriggerScenario,
+				Parameters: map[string]interface{}{
+					"error_codes": []interface{}{code},
+				},
+				Timestamp: now,
+			})
+		default:
+			return nil, fmt.Errorf("unsupported directive %q (supported: latency, status)", key)
+		}
+	}
+
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("no chaos directives given")
+	}
+
+	return actions, nil
+}
+
+// ApplyTrigger applies an action requested for a single request, such as one
+// parsed from the X-Chaos header, bypassing scenarios and their probabilities
+func (e *DefaultChaosEngine) ApplyTrigger(action ChaosAction, ctx *fasthttp.RequestCtx) error {
+	e.mu.RLock()
+	injector, exists := e.injectors[action.Type]
+	e.mu.RUnlock()
+
+	if !exists {
+		atomic.AddInt64(&e.failedInjections, 1)
+		return fmt.Errorf("unknown chaos type: %s", action.Type)
+	}
+
+	if err := injector.Validate(action.Parameters); err != nil {
+		atomic.AddInt64(&e.failedInjections, 1)
+		return fmt.Errorf("invalid %s trigger: %w", action.Type, err)
+	}
+
+	if err := injector.Inject(ctx, action.Parameters); err != nil {
+		atomic.AddInt64(&e.failedInjections, 1)
+		e.logger.Error("Chaos injection failed",
+			zap.String("scenario", action.Scenario),
+			zap.String("type", action.Type),
+			zap.Error(err))
+		return err
+	}
+
+	atomic.AddInt64(&e.chaosApplied, 1)
+	return nil
+}
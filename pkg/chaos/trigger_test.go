@@ -0,0 +1,35 @@
+package chaos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrigger(t *testing.T) {
+	actions, err := ParseTrigger("latency=2s, status=503")
+	require.NoError(t, err)
+	require.Len(t, actions, 2)
+
+	assert.Equal(t, "latency", actions[0].Type)
+	assert.Equal(t, TriggerScenario, actions[0].Scenario)
+	assert.Equal(t, "2s", actions[0].Parameters["min_delay"])
+	assert.Equal(t, "2s", actions[0].Parameters["max_delay"])
+
+	assert.Equal(t, "error", actions[1].Type)
+	assert.Equal(t, []interface{}{503}, actions[1].Parameters["error_codes"])
+}
+
+func TestParseTrigger_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"latency",
+		"latency=soon",
+		"status=unavailable",
+		"timeout=1s",
+	} {
+		_, err := ParseTrigger(value)
+		assert.Error(t, err, value)
+	}
+}
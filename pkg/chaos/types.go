@@ -14,10 +14,18 @@ type ChaosEngine interface {
 	// ShouldApplyChaos determines if chaos should be applied to the given endpoint
 	ShouldApplyChaos(endpoint string) (bool, ChaosAction)
 	
+	// ShouldApplyChaosToRequest determines if chaos should be applied to a request,
+	// honoring the method selectors of each scenario
+	ShouldApplyChaosToRequest(method, endpoint string) (bool, ChaosAction)
+	
 	// ApplyChaos applies the specified chaos action to the request context
 	ApplyChaos(action ChaosAction, ctx *fasthttp.RequestCtx) error
 	
-	// GetActiveScenarios returns a list of currently active scenario names
+	// ApplyTrigger applies an action requested by a single request, such as
+	// one parsed from the X-Chaos header, without a configured scenario
+	ApplyTrigger(action ChaosAction, ctx *fasthttp.RequestCtx) error
+	
+	// GetActiveScenarios returns the names of scenarios whose schedule is currently active
 	GetActiveScenarios() []string
 	
 	// Stop stops the chaos engine and cleans up resources
@@ -62,6 +70,7 @@ type EngineStats struct {
 // ScenarioStats contains statistics for a specific scenario
 type ScenarioStats struct {
 	Name         string    `json:"name"`
+	Active       bool      `json:"active"` // Whether the scenario schedule is currently active
 	AppliedCount int64     `json:"applied_count"`
 	FailedCount  int64     `json:"failed_count"`
 	LastApplied  time.Time `json:"last_applied"`
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"net"
+	"strings"
 	"time"
 )
 
@@ -11,10 +14,13 @@ type Config struct {
 	Chaos      ChaosConfig      `yaml:"chaos"`
 	Recording  RecordingConfig  `yaml:"recording"`
 	Plugins    []PluginConfig   `yaml:"plugins"`
+	PluginOptions PluginOptionsConfig `yaml:"plugin_options"`
 	Logging    LoggingConfig    `yaml:"logging"`
 	Metrics    MetricsConfig    `yaml:"metrics"`
 	Middleware MiddlewareConfig `yaml:"middleware"`
 	HotReload  HotReloadConfig  `yaml:"hotreload"`
+	Admin      AdminConfig      `yaml:"admin"`
+	Tracing    TracingConfig    `yaml:"tracing"`
 }
 
 // ServerConfig holds HTTP server configuration
@@ -24,18 +30,184 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration `yaml:"read_timeout"`
 	WriteTimeout    time.Duration `yaml:"write_timeout"`
 	MaxConnsPerIP   int           `yaml:"max_conns_per_ip"`
-	MaxRequestSize  string        `yaml:"max_request_size"`
+	MaxRequestSize  string        `yaml:"max_request_size"` // "10MB" by default; see MiddlewareConfig.MaxBodyBytes
 	Concurrency     int           `yaml:"concurrency"`
 	ReusePort       bool          `yaml:"reuse_port"`
+
+	// StreamingPaths lists paths whose bodies are streamed instead of buffered.
+	// Logging and recording only capture metadata for these paths.
+	// A trailing "*" matches any path with the given prefix.
+	StreamingPaths []string `yaml:"streaming_paths"`
+
+	// ExtraListeners lists additional host:port addresses served alongside
+	// Host:Port with the same handler and plugins (e.g. "[::1]:8080").
+	ExtraListeners []string `yaml:"extra_listeners"`
+
+	// UnixSocket serves on a Unix domain socket at this path instead of
+	// Host:Port. A stale socket file left at the path is removed on startup.
+	UnixSocket string `yaml:"unix_socket"`
+
+	// TLS serves HTTPS on every listener when enabled
+	TLS TLSConfig `yaml:"tls"`
+
+	// HealthPath and ReadyPath are the liveness and readiness endpoints,
+	// answered ahead of plugins and middleware. Empty disables an endpoint.
+	HealthPath string `yaml:"health_path"`
+	ReadyPath  string `yaml:"ready_path"`
+
+	// RoutesPath serves a JSON catalog of the operations in the loaded spec,
+	// answered ahead of plugins and middleware. Empty disables it.
+	RoutesPath string `yaml:"routes_path"`
+
+	// TrustedProxies lists the CIDR ranges (or single IPs) of reverse proxies
+	// whose forwarding header is believed. Requests from any other peer are
+	// attributed to the socket address.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+
+	// TrustedProxyHeader is the one header the trusted proxies set:
+	// X-Forwarded-For (the default), Forwarded or X-Real-IP. The others are
+	// never read, since a proxy passes them through from the client.
+	TrustedProxyHeader string `yaml:"trusted_proxy_header"`
+
+	// StrictPlugins makes startup fail when the configuration names a plugin
+	// that is not registered, instead of logging a warning and running
+	// without it
+	StrictPlugins bool `yaml:"strict_plugins"`
+
+	// MaxInFlight caps the requests handled at once; further requests get a
+	// 503 with Retry-After until one finishes. 0 disables the limit.
+	MaxInFlight int `yaml:"max_in_flight"`
+
+	// ShutdownTimeout bounds how long Stop waits for in-flight requests to
+	// finish before plugins are shut down (0 uses the 30s default)
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// Connection tuning passed through to fasthttp. Zero values keep
+	// fasthttp's defaults: IdleTimeout falls back to ReadTimeout and
+	// MaxRequestsPerConn is unlimited.
+	IdleTimeout        time.Duration `yaml:"idle_timeout"`
+	DisableKeepalive   bool          `yaml:"disable_keepalive"`
+	TCPKeepalive       bool          `yaml:"tcp_keepalive"`
+	TCPKeepalivePeriod time.Duration `yaml:"tcp_keepalive_period"`
+	MaxRequestsPerConn int           `yaml:"max_requests_per_conn"`
+}
+
+// MaxRequestBodyBytes returns MaxRequestSize in bytes, or 0 when it is unset
+// or invalid
+func (c *ServerConfig) MaxRequestBodyBytes() int64 {
+	if c.MaxRequestSize == "" {
+		return 0
+	}
+	size, err := parseSize(c.MaxRequestSize)
+	if err != nil || size < 0 {
+		return 0
+	}
+	return size
 }
 
+// TrustedProxyNetworks parses TrustedProxies. A bare IP address is a
+// single-host network.
+func (c *ServerConfig) TrustedProxyNetworks() ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(c.TrustedProxies))
+	for _, entry := range c.TrustedProxies {
+		entry = strings.TrimSpace(entry)
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: must be an IP address or CIDR range", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// TLSConfig holds HTTPS configuration. When enabled without CertFile and
+// KeyFile, a self-signed certificate is generated at startup for local use.
+type TLSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	MinVersion string `yaml:"min_version"` // "1.0", "1.1", "1.2" or "1.3" (default "1.2")
+}
+
+// BodyCaptureDisabledKey is the request user value set on streaming paths
+// to tell body-reading middleware and plugins to skip the body
+const BodyCaptureDisabledKey = "body_capture_disabled"
+
+// ResponseBodyKey is the request user value holding an immutable copy of the
+// response body, captured once after the handler so logging, recording and
+// ETags read the same bytes
+const ResponseBodyKey = "response_body"
+
+// ClientIPKey is the request user value holding the client address resolved
+// against the trusted proxies
+const ClientIPKey = "client_ip"
+
+// ProxiedKey is the request user value set when the response came from the
+// recording upstream rather than the mock
+const ProxiedKey = "proxied"
+
 // MockConfig holds mock data generation configuration
 type MockConfig struct {
-	Seed             int64  `yaml:"seed"`               // Random seed for reproducible data generation
-	Locale           string `yaml:"locale"`             // Locale for data generation (e.g., "en", "es", "fr")
-	MaxDepth         int    `yaml:"max_depth"`          // Maximum depth for nested object generation
-	DefaultArraySize int    `yaml:"default_array_size"` // Default size for arrays when not specified
-	PreferExamples   bool   `yaml:"prefer_examples"`    // Prefer examples from OpenAPI spec when available
+	Seed                int64   `yaml:"seed"`                 // Random seed for reproducible data generation
+	Locale              string  `yaml:"locale"`               // Locale for data generation (e.g., "en", "es", "fr")
+	MaxDepth            int     `yaml:"max_depth"`            // Maximum depth for nested object generation
+	DefaultArraySize    int     `yaml:"default_array_size"`   // Default size for arrays when not specified
+	PreferExamples      bool    `yaml:"prefer_examples"`      // Prefer examples from OpenAPI spec when available
+	ExampleSelection    string  `yaml:"example_selection"`    // Named example served when the request names none: first or random
+	Stateful            bool    `yaml:"stateful"`             // Keep created resources in memory for CRUD on collection paths
+	ValidateRequests    bool    `yaml:"validate_requests"`    // Reject request bodies that violate the operation's schema
+	RandomizeKeyOrder   bool    `yaml:"randomize_key_order"`  // Shuffle JSON object keys per response (seeded by Seed)
+	ServeDocs           bool    `yaml:"serve_docs"`           // Serve the spec at /openapi.json and /openapi.yaml and a Swagger UI at /docs
+	NullableProbability float64 `yaml:"nullable_probability"` // Chance (0-1) that a nullable schema generates null
+
+	// DeterministicPerRequest seeds generation per request from its
+	// fingerprint (method, path, sorted query and the headers that select a
+	// response) plus Seed, so identical requests always get identical bodies,
+	// across restarts too
+	DeterministicPerRequest bool `yaml:"deterministic_per_request"`
+
+	LatencyRamp LatencyRampConfig        `yaml:"latency_ramp"` // Simulated cold-start latency after start/reload
+	Overrides   []ResponseOverrideConfig `yaml:"overrides"`    // Canned responses that bypass the generator
+
+	NotFoundResponse         UnmatchedResponseConfig `yaml:"not_found_response"`          // Sent when no route matches the path
+	MethodNotAllowedResponse UnmatchedResponseConfig `yaml:"method_not_allowed_response"` // Sent when the path only exists under other methods
+}
+
+// UnmatchedResponseConfig is the response sent for requests no route
+// serves. Empty fields keep the defaults: a JSON {"error":"not_found"} or
+// {"error":"method_not_allowed"} body with status 404 or 405.
+type UnmatchedResponseConfig struct {
+	Status      int    `yaml:"status"`       // Response status
+	ContentType string `yaml:"content_type"` // Response content type
+	Body        string `yaml:"body"`         // Response body
+}
+
+// ResponseOverrideConfig pins requests matching Method and Path to the contents
+// of File. Path may contain {param} segments; ${param} placeholders in the file
+// are replaced with path parameters, then query parameters.
+type ResponseOverrideConfig struct {
+	Method      string `yaml:"method"`       // HTTP method, e.g. GET
+	Path        string `yaml:"path"`         // Path pattern, e.g. /users/{id}
+	File        string `yaml:"file"`         // File holding the response body
+	Status      int    `yaml:"status"`       // Response status (default 200)
+	ContentType string `yaml:"content_type"` // Response content type (default application/json)
+}
+
+// LatencyRampConfig holds the cold-start latency ramp configuration.
+// InitialLatency is added to responses right after a start or reload and
+// decays linearly to zero over Duration.
+type LatencyRampConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	InitialLatency time.Duration `yaml:"initial_latency"`
+	Duration       time.Duration `yaml:"duration"`
 }
 
 // LoggingConfig holds logging configuration
@@ -49,7 +221,10 @@ type LoggingConfig struct {
 
 // MetricsConfig holds metrics configuration
 type MetricsConfig struct {
-	Enabled    bool   `yaml:"enabled"`
+	Enabled bool `yaml:"enabled"`
+	// Listen serves Path on its own Port. Off by default, so collecting
+	// metrics never opens a port nobody asked for.
+	Listen     bool   `yaml:"listen"`
 	Port       int    `yaml:"port"`
 	Path       string `yaml:"path"`
 	Prometheus bool   `yaml:"prometheus"`
@@ -59,15 +234,30 @@ type MetricsConfig struct {
 type ChaosConfig struct {
 	Enabled   bool             `yaml:"enabled"`
 	Scenarios []ScenarioConfig `yaml:"scenarios"`
+	// AllowHeaderTrigger lets a request inject chaos into itself with the
+	// X-Chaos header, bypassing scenario probabilities. Keep off outside testing.
+	AllowHeaderTrigger bool `yaml:"allow_header_trigger"`
 }
 
 // ScenarioConfig represents a single chaos scenario
 type ScenarioConfig struct {
 	Name        string                 `yaml:"name"`
-	Type        string                 `yaml:"type"` // latency, error, timeout
+	Type        string                 `yaml:"type"` // latency, error, timeout, bandwidth, connection_reset, body_corruption
 	Endpoints   []string               `yaml:"endpoints"`
+	Methods     []string               `yaml:"methods"` // Empty means all methods
 	Probability float64                `yaml:"probability"`
 	Parameters  map[string]interface{} `yaml:"parameters"`
+	Schedule    *ScheduleConfig        `yaml:"schedule"` // Nil means always active
+}
+
+// ScheduleConfig restricts a chaos scenario to recurring time windows.
+// Start is a time of day ("15:04", "15:04:05") or an RFC3339 timestamp;
+// when empty, windows are aligned to the Unix epoch. Each window lasts
+// Duration and repeats every Period. Time-of-day starts repeat daily by default.
+type ScheduleConfig struct {
+	Start    string        `yaml:"start"`
+	Duration time.Duration `yaml:"duration"`
+	Period   time.Duration `yaml:"period"`
 }
 
 // PluginConfig holds plugin configuration
@@ -75,24 +265,62 @@ type PluginConfig struct {
 	Name    string                 `yaml:"name"`
 	Enabled bool                   `yaml:"enabled"`
 	Config  map[string]interface{} `yaml:"config"`
+	// Order breaks ties between middleware plugins sharing a priority; lower runs first. 0 means unset.
+	Order int `yaml:"order"`
+}
+
+// PluginOptionsConfig holds settings that apply to all plugins
+type PluginOptionsConfig struct {
+	// StrictPriorities fails enabling a middleware plugin that shares its priority
+	// with another enabled one unless both set a distinct Order. Otherwise a warning is logged.
+	StrictPriorities bool `yaml:"strict_priorities"`
+
+	// ExecutionBudget caps the time spent in middleware pre-processing per request.
+	// Once exceeded, remaining low-priority plugins are skipped. 0 disables the budget.
+	ExecutionBudget time.Duration `yaml:"execution_budget"`
+
+	// AsyncWorkers and AsyncQueueSize size the pool that runs PostProcess for
+	// plugins that opt into async post-processing. 0 uses the defaults.
+	AsyncWorkers   int `yaml:"async_workers"`
+	AsyncQueueSize int `yaml:"async_queue_size"`
+
+	// AtomicLoad makes plugin loading all-or-nothing: if any configured plugin
+	// fails to load, the others are unloaded and the server does not start.
+	// Otherwise failed plugins are logged and skipped.
+	AtomicLoad bool `yaml:"atomic_load"`
+
+	// AllowedPlugins, when non-empty, is the only plugins that may be loaded.
+	// DeniedPlugins may never be loaded and take precedence over AllowedPlugins.
+	// A configured plugin that is not permitted stops the server from starting.
+	AllowedPlugins []string `yaml:"allowed_plugins"`
+	DeniedPlugins  []string `yaml:"denied_plugins"`
 }
 
 // MiddlewareConfig holds middleware configuration
 type MiddlewareConfig struct {
-	CORS      CORSConfig     `yaml:"cors"`
-	Timeout   TimeoutConfig  `yaml:"timeout"`
-	Recovery  RecoveryConfig `yaml:"recovery"`
-	RequestID bool           `yaml:"request_id"` // Simple flag for request ID middleware
+	CORS        CORSConfig        `yaml:"cors"`
+	Timeout     TimeoutConfig     `yaml:"timeout"`
+	Recovery    RecoveryConfig    `yaml:"recovery"`
+	Compression CompressionConfig `yaml:"compression"`
+	RequestID   bool              `yaml:"request_id"` // Simple flag for request ID middleware
+	ETag        bool              `yaml:"etag"`       // Weak ETags and 304 responses for GET requests
+
+	// MaxBodyBytes rejects larger request bodies with 413; 0 disables the
+	// limit. Defaults to 10MB. fasthttp enforces the tighter of this and
+	// server.max_request_size (also 10MB by default), so accepting larger
+	// bodies means raising both.
+	MaxBodyBytes int64 `yaml:"max_body_bytes"`
 }
 
 // CORSConfig holds CORS middleware configuration
 type CORSConfig struct {
-	Enabled          bool     `yaml:"enabled"`
-	AllowOrigins     []string `yaml:"allow_origins"`
-	AllowMethods     []string `yaml:"allow_methods"`
-	AllowHeaders     []string `yaml:"allow_headers"`
-	AllowCredentials bool     `yaml:"allow_credentials"`
-	MaxAge           int      `yaml:"max_age"`
+	Enabled             bool     `yaml:"enabled"`
+	AllowOrigins        []string `yaml:"allow_origins"`
+	AllowOriginPatterns []string `yaml:"allow_origin_patterns"` // Regular expressions matched against the Origin header
+	AllowMethods        []string `yaml:"allow_methods"`
+	AllowHeaders        []string `yaml:"allow_headers"`
+	AllowCredentials    bool     `yaml:"allow_credentials"`
+	MaxAge              int      `yaml:"max_age"`
 }
 
 // TimeoutConfig holds timeout middleware configuration
@@ -108,6 +336,13 @@ type RecoveryConfig struct {
 	LogStack   bool `yaml:"log_stack"`
 }
 
+// CompressionConfig holds response compression middleware configuration
+type CompressionConfig struct {
+	Enabled bool `yaml:"enabled"`
+	Level   int  `yaml:"level"`    // 1 (fastest) to 9 (smallest)
+	MinSize int  `yaml:"min_size"` // Responses smaller than this many bytes are sent uncompressed
+}
+
 // HotReloadConfig holds hot reload configuration
 type HotReloadConfig struct {
 	Enabled       bool          `yaml:"enabled"`
@@ -116,6 +351,23 @@ type HotReloadConfig struct {
 	DebounceDelay time.Duration `yaml:"debounce_delay"`
 }
 
+// AdminConfig holds the admin API listener configuration. The admin API is
+// served on its own port and every request must carry Token.
+type AdminConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Host    string `yaml:"host"`
+	Port    int    `yaml:"port"`
+	Token   string `yaml:"token"`
+}
+
+// TracingConfig holds distributed tracing configuration. Spans are exported
+// to an OTLP/HTTP collector at Endpoint (for example http://localhost:4318).
+type TracingConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	ServiceName string `yaml:"service_name"`
+	Endpoint    string `yaml:"endpoint"`
+}
+
 // RecordingConfig holds recording system configuration
 type RecordingConfig struct {
 	Enabled        bool              `yaml:"enabled"`
@@ -123,10 +375,45 @@ type RecordingConfig struct {
 	Filters        []RecordingFilter `yaml:"filters"`
 	MaxRecordings  int               `yaml:"max_recordings"`
 	MaxBodySize    int64             `yaml:"max_body_size"`
+	// MaxAge deletes recordings older than this, checked at least once a
+	// minute; MaxTotalSize caps the bytes all recordings use in storage,
+	// evicting the oldest first as they are saved. 0 disables either.
+	MaxAge       time.Duration `yaml:"max_age"`
+	MaxTotalSize int64         `yaml:"max_total_size"`
+	// MaxConcurrentCaptures bounds in-flight captures; extra captures are dropped. 0 means unlimited.
+	MaxConcurrentCaptures int      `yaml:"max_concurrent_captures"`
 	IncludeHeaders []string          `yaml:"include_headers"`
 	ExcludeHeaders []string          `yaml:"exclude_headers"`
+	Redact         RedactConfig      `yaml:"redact"`
+	// Dedup stores byte-identical exchanges once and counts repeats in the
+	// recording's metadata instead of saving each one
+	Dedup bool `yaml:"dedup"`
+	// Upstream is the base URL (e.g. "https://api.example.com") that requests
+	// matching ProxyPaths are forwarded to. The real response is returned to
+	// the client and recorded. Empty disables proxying.
+	Upstream string `yaml:"upstream"`
+	// ProxyPaths selects the paths forwarded to Upstream. A trailing "*"
+	// matches any path with the given prefix.
+	ProxyPaths []string `yaml:"proxy_paths"`
+	// ProxyTimeout bounds each upstream request. 0 uses 30s.
+	ProxyTimeout time.Duration `yaml:"proxy_timeout"`
+}
+
+// RedactConfig lists sensitive data replaced with [REDACTED] before a
+// recording is stored. The names add to DefaultSensitiveHeaders and
+// DefaultSensitiveFields.
+type RedactConfig struct {
+	Headers []string `yaml:"headers"` // Header names, case-insensitive
+	Fields  []string `yaml:"fields"`  // JSON body fields whose name contains any of these, case-insensitive
 }
 
+// DefaultSensitiveHeaders and DefaultSensitiveFields are always redacted from
+// request logs and recordings
+var (
+	DefaultSensitiveHeaders = []string{"authorization", "cookie", "x-api-key", "x-auth-token"}
+	DefaultSensitiveFields  = []string{"password", "secret", "token", "key", "credential"}
+)
+
 // StorageConfig defines storage backend configuration
 type StorageConfig struct {
 	Type      string `yaml:"type"`      // "file", "memory"
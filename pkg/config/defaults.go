@@ -6,21 +6,36 @@ import "time"
 func DefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:            8080,
-			Host:            "0.0.0.0",
-			ReadTimeout:     30 * time.Second,
-			WriteTimeout:    30 * time.Second,
-			MaxConnsPerIP:   100,
-			MaxRequestSize:  "10MB",
-			Concurrency:     256000,
-			ReusePort:       true,
+			Port:               8080,
+			Host:               "0.0.0.0",
+			ReadTimeout:        30 * time.Second,
+			WriteTimeout:       30 * time.Second,
+			MaxConnsPerIP:      100,
+			MaxRequestSize:     "10MB",
+			Concurrency:        256000,
+			ReusePort:          true,
+			HealthPath:         "/_health",
+			ReadyPath:          "/_ready",
+			RoutesPath:         "/_routes",
+			TrustedProxyHeader: "X-Forwarded-For",
+			ShutdownTimeout:    30 * time.Second,
 		},
 		Mock: MockConfig{
-			Seed:             0,     // 0 means use current timestamp
-			Locale:           "en",  // English by default
-			MaxDepth:         5,     // Reasonable depth to prevent infinite recursion
-			DefaultArraySize: 2,     // Small default array size
-			PreferExamples:   true,  // Prefer OpenAPI examples when available
+			Seed:                0,       // 0 means use current timestamp
+			Locale:              "en",    // English by default
+			MaxDepth:            5,       // Reasonable depth to prevent infinite recursion
+			DefaultArraySize:    2,       // Small default array size
+			PreferExamples:      true,    // Prefer OpenAPI examples when available
+			ExampleSelection:    "first", // Serve the first named example by default
+			Stateful:            false,   // Stateless mock responses by default
+			ValidateRequests:    false,   // Accept any request body by default
+			RandomizeKeyOrder:   false,   // Keep generated key order by default
+			NullableProbability: 0.1,     // Occasionally emit null for nullable schemas
+			LatencyRamp: LatencyRampConfig{
+				Enabled:        false, // Disabled by default
+				InitialLatency: 500 * time.Millisecond,
+				Duration:       30 * time.Second,
+			},
 		},
 		Logging: LoggingConfig{
 			Level:     "info",
@@ -31,6 +46,7 @@ func DefaultConfig() *Config {
 		},
 		Metrics: MetricsConfig{
 			Enabled:    true,
+			Listen:     false,
 			Port:       9090,
 			Path:       "/metrics",
 			Prometheus: true,
@@ -42,6 +58,7 @@ func DefaultConfig() *Config {
 		Plugins: []PluginConfig{},
 		Middleware: MiddlewareConfig{
 			RequestID: true, // Enable request ID by default for traceability
+			MaxBodyBytes: 10 * 1024 * 1024, // 10MB default request body limit
 			CORS: CORSConfig{
 				Enabled:          false, // Disabled by default for security
 				AllowOrigins:     []string{"*"},
@@ -59,6 +76,11 @@ func DefaultConfig() *Config {
 				PrintStack: false, // Don't print to stdout by default
 				LogStack:   true,  // Log stack traces for debugging
 			},
+			Compression: CompressionConfig{
+				Enabled: false, // Disabled by default
+				Level:   6,     // gzip default level
+				MinSize: 1024,  // Small bodies rarely shrink enough to be worth it
+			},
 		},
 		HotReload: HotReloadConfig{
 			Enabled:       false, // Disabled by default
@@ -66,10 +88,21 @@ func DefaultConfig() *Config {
 			WatchSpec:     true,  // Watch OpenAPI spec file when enabled
 			DebounceDelay: 500 * time.Millisecond, // Default debounce delay
 		},
+		Admin: AdminConfig{
+			Enabled: false,       // Disabled by default
+			Host:    "127.0.0.1", // Local operators only
+			Port:    9091,
+		},
+		Tracing: TracingConfig{
+			Enabled:     false, // Disabled by default
+			ServiceName: "vanta",
+			Endpoint:    "http://localhost:4318", // OTLP/HTTP collector default
+		},
 		Recording: RecordingConfig{
 			Enabled:       false, // Disabled by default
 			MaxRecordings: 1000,  // Default max recordings
 			MaxBodySize:   1024 * 1024, // 1MB default max body size
+			MaxConcurrentCaptures: 100, // Drop captures beyond 100 in flight
 			Storage: StorageConfig{
 				Type:      "file",      // File storage by default
 				Directory: "./recordings", // Default directory
@@ -6,17 +6,39 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
 // LoadFromFile loads configuration from a YAML file
 func LoadFromFile(configPath string) (*Config, error) {
+	return LoadConfigs(configPath)
+}
+
+// LoadConfigs loads configuration from one or more YAML files, deep-merging
+// them in order so later files override earlier ones. Environment variable
+// references are expanded once all files are merged.
+func LoadConfigs(paths ...string) (*Config, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no configuration file given")
+	}
+
+	merged := make(map[string]interface{})
+	for _, path := range paths {
+		values, err := readConfigMap(path)
+		if err != nil {
+			return nil, err
+		}
+		mergeConfigMaps(merged, values)
+	}
+
+	if err := substituteConfigEnv(merged); err != nil {
+		return nil, fmt.Errorf("environment substitution failed: %w", err)
+	}
+
 	v := viper.New()
-	
-	// Set config file path
-	v.SetConfigFile(configPath)
-	
+
 	// Set environment variable prefix
 	v.SetEnvPrefix("VANTA")
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
@@ -25,14 +47,13 @@ func LoadFromFile(configPath string) (*Config, error) {
 	// Set defaults
 	setDefaults(v)
 
-	// Read config file
-	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+	if err := v.MergeConfigMap(merged); err != nil {
+		return nil, fmt.Errorf("failed to merge config: %w", err)
 	}
 
 	// Unmarshal into config struct
 	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
+	if err := v.Unmarshal(&cfg, func(dc *mapstructure.DecoderConfig) { dc.TagName = "yaml" }); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
@@ -69,6 +90,31 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.max_request_size", "10MB")
 	v.SetDefault("server.concurrency", 256000)
 	v.SetDefault("server.reuse_port", true)
+	v.SetDefault("server.health_path", "/_health")
+	v.SetDefault("server.ready_path", "/_ready")
+	v.SetDefault("server.routes_path", "/_routes")
+	v.SetDefault("server.max_in_flight", 0)
+	v.SetDefault("server.trusted_proxy_header", "X-Forwarded-For")
+	v.SetDefault("server.strict_plugins", false)
+	v.SetDefault("server.shutdown_timeout", time.Duration(30*time.Second))
+	v.SetDefault("server.idle_timeout", time.Duration(0))
+	v.SetDefault("server.disable_keepalive", false)
+	v.SetDefault("server.tcp_keepalive", false)
+	v.SetDefault("server.tcp_keepalive_period", time.Duration(0))
+	v.SetDefault("server.max_requests_per_conn", 0)
+
+	// Mock defaults
+	v.SetDefault("mock.prefer_examples", true)
+	v.SetDefault("mock.example_selection", "first")
+	v.SetDefault("mock.stateful", false)
+	v.SetDefault("mock.validate_requests", false)
+	v.SetDefault("mock.randomize_key_order", false)
+	v.SetDefault("mock.deterministic_per_request", false)
+	v.SetDefault("mock.serve_docs", false)
+	v.SetDefault("mock.nullable_probability", 0.1)
+	v.SetDefault("mock.latency_ramp.enabled", false)
+	v.SetDefault("mock.latency_ramp.initial_latency", 500*time.Millisecond)
+	v.SetDefault("mock.latency_ramp.duration", 30*time.Second)
 
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
@@ -79,6 +125,7 @@ func setDefaults(v *viper.Viper) {
 
 	// Metrics defaults
 	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.listen", false)
 	v.SetDefault("metrics.port", 9090)
 	v.SetDefault("metrics.path", "/metrics")
 	v.SetDefault("metrics.prometheus", true)
@@ -91,4 +138,24 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("hotreload.watch_config", true)
 	v.SetDefault("hotreload.watch_spec", true)
 	v.SetDefault("hotreload.debounce_delay", 500*time.Millisecond)
+
+	// Request body limit default
+	v.SetDefault("middleware.max_body_bytes", 10*1024*1024)
+
+	// Compression defaults
+	v.SetDefault("middleware.compression.enabled", false)
+	v.SetDefault("middleware.compression.level", 6)
+	v.SetDefault("middleware.compression.min_size", 1024)
+
+	// Admin defaults
+	v.SetDefault("admin.enabled", false)
+	v.SetDefault("admin.host", "127.0.0.1")
+	v.SetDefault("admin.port", 9091)
+	// Registered so VANTA_ADMIN_TOKEN is picked up; AutomaticEnv only sees known keys
+	v.SetDefault("admin.token", "")
+
+	// Tracing defaults
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.service_name", "vanta")
+	v.SetDefault("tracing.endpoint", "http://localhost:4318")
 }
\ No newline at end of file
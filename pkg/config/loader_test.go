@@ -0,0 +1,265 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Multi-word keys only decode when fields are matched by their yaml tags;
+// matched by field name, "read_timeout" never reaches ReadTimeout.
+func TestLoadFromFile_DecodesSnakeCaseKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+server:
+  read_timeout: 5s
+  max_conns_per_ip: 7
+  max_request_size: 2MB
+mock:
+  default_array_size: 4
+  prefer_examples: true
+logging:
+  add_caller: true
+middleware:
+  max_body_bytes: 1024
+  cors:
+    allow_credentials: true
+recording:
+  max_body_size: 2048
+`), 0o644))
+
+	cfg, err := LoadFromFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, 7, cfg.Server.MaxConnsPerIP)
+	assert.Equal(t, "2MB", cfg.Server.MaxRequestSize)
+	assert.Equal(t, 4, cfg.Mock.DefaultArraySize)
+	assert.True(t, cfg.Mock.PreferExamples)
+	assert.True(t, cfg.Logging.AddCaller)
+	assert.Equal(t, int64(1024), cfg.Middleware.MaxBodyBytes)
+	assert.True(t, cfg.Middleware.CORS.AllowCredentials)
+	assert.Equal(t, int64(2048), cfg.Recording.MaxBodySize)
+
+	// Defaults registered under snake_case keys apply as well
+	assert.Equal(t, 30*time.Second, cfg.Server.WriteTimeout)
+	assert.False(t, cfg.Metrics.Listen, "the metrics listener stays off unless asked for")
+}
+
+// Both body limits default to 10MB; changing either default changes which
+// uploads a stock install accepts
+func TestLoadFromFile_BodyLimitDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("server:\n  port: 8080\n"), 0o644))
+
+	cfg, err := LoadFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10*1024*1024), cfg.Middleware.MaxBodyBytes)
+	assert.Equal(t, int64(10*1024*1024), cfg.Server.MaxRequestBodyBytes())
+	assert.Equal(t, DefaultConfig().Middleware.MaxBodyBytes, cfg.Middleware.MaxBodyBytes)
+}
+
+func TestLoadFromFile_AdminTokenFromEnvironment(t *testing.T) {
+	t.Setenv("VANTA_ADMIN_TOKEN", "from-env")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("admin:\n  enabled: true\n"), 0o644))
+
+	cfg, err := LoadFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cfg.Admin.Token)
+}
+
+func TestParseSize_MultiLetterUnits(t *testing.T) {
+	tests := []struct {
+		size string
+		want int64
+	}{
+		{"10MB", 10 * 1024 * 1024},
+		{"512kb", 512 * 1024},
+		{"1.5GB", 1536 * 1024 * 1024},
+		{"100B", 100},
+		{"4096", 4096},
+	}
+
+	// Units used to be tried in map order, so "10MB" sometimes matched "B"
+	for i := 0; i < 20; i++ {
+		for _, tt := range tests {
+			got, err := parseSize(tt.size)
+			require.NoError(t, err, tt.size)
+			assert.Equal(t, tt.want, got, tt.size)
+		}
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+const baseConfig = `
+server:
+  host: 127.0.0.1
+  port: 8080
+  extra_listeners: ["127.0.0.1:8081", "127.0.0.1:8082"]
+middleware:
+  cors:
+    enabled: true
+    allow_origins: ["https://app.example.com"]
+    allow_credentials: true
+plugins:
+  - name: cors
+    enabled: true
+`
+
+func TestLoadConfigs_ScalarOverride(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "base.yaml", baseConfig)
+	prod := writeFile(t, dir, "prod.yaml", `
+server:
+  port: 9000
+`)
+
+	cfg, err := LoadConfigs(base, prod)
+	require.NoError(t, err)
+
+	assert.Equal(t, 9000, cfg.Server.Port)
+	assert.Equal(t, "127.0.0.1", cfg.Server.Host, "keys the override does not set are kept")
+	assert.Equal(t, 256000, cfg.Server.Concurrency, "defaults still apply")
+}
+
+func TestLoadConfigs_NestedMapMerge(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "base.yaml", baseConfig)
+	staging := writeFile(t, dir, "staging.yaml", `
+middleware:
+  cors:
+    allow_credentials: false
+`)
+
+	cfg, err := LoadConfigs(base, staging)
+	require.NoError(t, err)
+
+	assert.True(t, cfg.Middleware.CORS.Enabled)
+	assert.Equal(t, []string{"https://app.example.com"}, cfg.Middleware.CORS.AllowOrigins)
+	assert.False(t, cfg.Middleware.CORS.AllowCredentials)
+}
+
+func TestLoadConfigs_SliceReplaceAndAppend(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "base.yaml", baseConfig)
+
+	replace := writeFile(t, dir, "replace.yaml", `
+server:
+  extra_listeners: ["127.0.0.1:9001"]
+plugins:
+  - name: logging
+    enabled: true
+`)
+	cfg, err := LoadConfigs(base, replace)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1:9001"}, cfg.Server.ExtraListeners)
+	require.Len(t, cfg.Plugins, 1)
+	assert.Equal(t, "logging", cfg.Plugins[0].Name)
+
+	appendFile := writeFile(t, dir, "append.yaml", `
+server:
+  extra_listeners+: ["127.0.0.1:9001"]
+plugins+:
+  - name: logging
+    enabled: true
+`)
+	cfg, err = LoadConfigs(base, appendFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1:8081", "127.0.0.1:8082", "127.0.0.1:9001"}, cfg.Server.ExtraListeners)
+	require.Len(t, cfg.Plugins, 2)
+	assert.Equal(t, "cors", cfg.Plugins[0].Name)
+	assert.Equal(t, "logging", cfg.Plugins[1].Name)
+}
+
+func TestLoadConfigs_IncludeDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "plugins.d/20-logging.yaml", `
+name: logging
+enabled: true
+config:
+  log_level: debug
+`)
+	writeFile(t, dir, "plugins.d/10-limits.yml", `
+- name: rate_limit
+  enabled: true
+  config:
+    global_requests_per_second: 100
+- name: auth
+  enabled: false
+`)
+	writeFile(t, dir, "plugins.d/README.md", "not a plugin")
+	base := writeFile(t, dir, "base.yaml", baseConfig+"include: plugins.d\n")
+
+	cfg, err := LoadConfigs(base)
+	require.NoError(t, err)
+
+	var names []string
+	for _, plugin := range cfg.Plugins {
+		names = append(names, plugin.Name)
+	}
+	assert.Equal(t, []string{"cors", "rate_limit", "auth", "logging"}, names)
+	assert.Equal(t, "debug", cfg.Plugins[3].Config["log_level"])
+	assert.Equal(t, 100, cfg.Plugins[1].Config["global_requests_per_second"])
+}
+
+func TestLoadConfigs_EnvironmentSubstitutionAfterMerge(t *testing.T) {
+	t.Setenv("TEST_TRACING_SERVICE", "orders-mock")
+
+	dir := t.TempDir()
+	base := writeFile(t, dir, "base.yaml", `
+tracing:
+  service_name: "${TEST_TRACING_SERVICE:vanta}"
+server:
+  host: "${TEST_UNSET_HOST:0.0.0.0}"
+plugins:
+  - name: auth
+    config:
+      jwt_secret: "${TEST_JWT_SECRET}"
+`)
+	prod := writeFile(t, dir, "prod.yaml", `
+server:
+  host: "${TEST_UNSET_HOST:10.0.0.1}"
+`)
+
+	cfg, err := LoadConfigs(base, prod)
+	require.NoError(t, err)
+
+	assert.Equal(t, "orders-mock", cfg.Tracing.ServiceName)
+	assert.Equal(t, "10.0.0.1", cfg.Server.Host, "the merged value is substituted")
+	assert.Equal(t, "${TEST_JWT_SECRET}", cfg.Plugins[0].Config["jwt_secret"], "plugin settings are substituted by the plugin registry")
+
+	missing := writeFile(t, dir, "missing.yaml", `
+admin:
+  token: "${TEST_UNSET_ADMIN_TOKEN:?admin token is required}"
+`)
+	_, err = LoadConfigs(base, missing)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "admin token is required")
+}
+
+func TestLoadConfigs_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := LoadConfigs()
+	assert.Error(t, err)
+
+	_, err = LoadConfigs(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+
+	badInclude := writeFile(t, dir, "bad.yaml", "include: does-not-exist\n")
+	_, err = LoadConfigs(badInclude)
+	assert.Error(t, err)
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// includeKey names directories (or files) of plugin entries to add to a
+	// file's plugins list
+	includeKey = "include"
+	// appendSuffix on a key appends its list to the earlier one instead of
+	// replacing it, e.g. "plugins+"
+	appendSuffix = "+"
+)
+
+// readConfigMap reads a YAML (or JSON) configuration file into a map and
+// resolves its include directive
+func readConfigMap(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	include, ok := values[includeKey]
+	if !ok {
+		return values, nil
+	}
+	delete(values, includeKey)
+
+	var includes []string
+	switch v := include.(type) {
+	case string:
+		includes = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: include entries must be paths, got %v", path, item)
+			}
+			includes = append(includes, s)
+		}
+	default:
+		return nil, fmt.Errorf("%s: include must be a path or a list of paths", path)
+	}
+
+	// Included plugins extend this file's own list, whether it replaces or
+	// appends to the lists of earlier files
+	pluginsKey := "plugins"
+	if _, appends := values[pluginsKey+appendSuffix]; appends {
+		pluginsKey += appendSuffix
+	}
+	plugins, _ := values[pluginsKey].([]interface{})
+
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		entries, err := readPluginIncludes(include)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		plugins = append(plugins, entries...)
+	}
+	values[pluginsKey] = plugins
+
+	return values, nil
+}
+
+// readPluginIncludes reads plugin entries from a file, or from every YAML
+// file in a directory in name order. A file holds one entry or a list.
+func readPluginIncludes(path string) ([]interface{}, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read include: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read include directory: %w", err)
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var plugins []interface{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read include: %w", err)
+		}
+
+		var content interface{}
+		if err := yaml.Unmarshal(data, &content); err != nil {
+			return nil, fmt.Errorf("failed to parse include %s: %w", file, err)
+		}
+
+		switch v := content.(type) {
+		case map[string]interface{}:
+			plugins = append(plugins, v)
+		case []interface{}:
+			plugins = append(plugins, v...)
+		case nil:
+			// Empty file
+		default:
+			return nil, fmt.Errorf("include %s must hold a plugin entry or a list of them", file)
+		}
+	}
+
+	return plugins, nil
+}
+
+// mergeConfigMaps merges src into dst. Maps merge key by key, anything else
+// from src replaces the value in dst, and a key ending in "+" appends its
+// list to the list under the key without the suffix.
+func mergeConfigMaps(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if base, appends := strings.CutSuffix(key, appendSuffix); appends && base != "" {
+			if items, ok := value.([]interface{}); ok {
+				existing, _ := dst[base].([]interface{})
+				dst[base] = append(append([]interface{}{}, existing...), items...)
+				continue
+			}
+			key = base
+		}
+
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			dstMap, ok := dst[key].(map[string]interface{})
+			if !ok {
+				dstMap = make(map[string]interface{})
+			}
+			mergeConfigMaps(dstMap, srcMap)
+			dst[key] = dstMap
+			continue
+		}
+
+		dst[key] = value
+	}
+}
+
+// configEnvPattern matches ${VAR}, ${VAR:default} and ${VAR:?error message}
+var configEnvPattern = regexp.MustCompile(`\$\{([^}:]+)(?::([^}]*))?\}`)
+
+// substituteConfigEnv expands environment variable references in the string
+// values of merged configuration. Plugin settings are left alone: the plugin
+// registry substitutes them against each plugin's schema when it loads them.
+func substituteConfigEnv(values map[string]interface{}) error {
+	for key, value := range values {
+		if key == "plugins" {
+			continue
+		}
+		substituted, err := substituteConfigValue(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		values[key] = substituted
+	}
+	return nil
+}
+
+func substituteConfigValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return expandConfigEnv(v)
+	case map[string]interface{}:
+		if err := substituteConfigEnv(v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	case []interface{}:
+		for i, item := range v {
+			substituted, err := substituteConfigValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			v[i] = substituted
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+// expandConfigEnv expands the references in s. A ${VAR:?message} reference
+// fails with message when VAR is unset or empty.
+func expandConfigEnv(s string) (string, error) {
+	var expandErr error
+
+	expanded := configEnvPattern.ReplaceAllStringFunc(s, func(match string) string {
+		parts := configEnvPattern.FindStringSubmatch(match)
+		name, fallback := parts[1], parts[2]
+
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+		if message, required := strings.CutPrefix(fallback, "?"); required {
+			if message == "" {
+				message = "must be set"
+			}
+			if expandErr == nil {
+				expandErr = fmt.Errorf("environment variable %s: %s", name, message)
+			}
+			return ""
+		}
+		return fallback
+	})
+
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return expanded, nil
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -47,13 +49,18 @@ func Validate(cfg *Config) error {
 		errors = append(errors, errs...)
 	}
 
+	// Validate mock configuration
+	if errs := validateMock(&cfg.Mock); len(errs) > 0 {
+		errors = append(errors, errs...)
+	}
+
 	// Validate logging configuration
 	if errs := validateLogging(&cfg.Logging); len(errs) > 0 {
 		errors = append(errors, errs...)
 	}
 
 	// Validate metrics configuration
-	if errs := validateMetrics(&cfg.Metrics); len(errs) > 0 {
+	if errs := validateMetrics(&cfg.Metrics, &cfg.Server); len(errs) > 0 {
 		errors = append(errors, errs...)
 	}
 
@@ -62,6 +69,31 @@ func Validate(cfg *Config) error {
 		errors = append(errors, errs...)
 	}
 
+	// Validate recording configuration
+	if errs := validateRecording(&cfg.Recording); len(errs) > 0 {
+		errors = append(errors, errs...)
+	}
+
+	// Validate plugin options
+	if errs := validatePluginOptions(&cfg.PluginOptions); len(errs) > 0 {
+		errors = append(errors, errs...)
+	}
+
+	// Validate middleware configuration
+	if errs := validateMiddleware(&cfg.Middleware); len(errs) > 0 {
+		errors = append(errors, errs...)
+	}
+
+	// Validate admin API configuration
+	if errs := validateAdmin(&cfg.Admin, &cfg.Server); len(errs) > 0 {
+		errors = append(errors, errs...)
+	}
+
+	// Validate tracing configuration
+	if errs := validateTracing(&cfg.Tracing); len(errs) > 0 {
+		errors = append(errors, errs...)
+	}
+
 	if len(errors) > 0 {
 		return errors
 	}
@@ -116,6 +148,38 @@ func validateServer(cfg *ServerConfig) ValidationErrors {
 		})
 	}
 
+	if cfg.ShutdownTimeout < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "server.shutdown_timeout",
+			Value:   cfg.ShutdownTimeout,
+			Message: "cannot be negative",
+		})
+	}
+
+	if cfg.IdleTimeout < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "server.idle_timeout",
+			Value:   cfg.IdleTimeout,
+			Message: "cannot be negative",
+		})
+	}
+
+	if cfg.TCPKeepalivePeriod < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "server.tcp_keepalive_period",
+			Value:   cfg.TCPKeepalivePeriod,
+			Message: "cannot be negative",
+		})
+	}
+
+	if cfg.MaxRequestsPerConn < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "server.max_requests_per_conn",
+			Value:   cfg.MaxRequestsPerConn,
+			Message: "cannot be negative",
+		})
+	}
+
 	// Validate max request size
 	if cfg.MaxRequestSize != "" {
 		if _, err := parseSize(cfg.MaxRequestSize); err != nil {
@@ -136,6 +200,195 @@ func validateServer(cfg *ServerConfig) ValidationErrors {
 		})
 	}
 
+	if cfg.MaxInFlight < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "server.max_in_flight",
+			Value:   cfg.MaxInFlight,
+			Message: "cannot be negative",
+		})
+	}
+
+	if _, err := cfg.TrustedProxyNetworks(); err != nil {
+		errors = append(errors, ValidationError{
+			Field:   "server.trusted_proxies",
+			Value:   cfg.TrustedProxies,
+			Message: err.Error(),
+		})
+	}
+
+	switch strings.ToLower(cfg.TrustedProxyHeader) {
+	case "", "x-forwarded-for", "forwarded", "x-real-ip":
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "server.trusted_proxy_header",
+			Value:   cfg.TrustedProxyHeader,
+			Message: "must be one of: X-Forwarded-For, Forwarded, X-Real-IP",
+		})
+	}
+
+	// Validate extra listeners
+	for i, addr := range cfg.ExtraListeners {
+		if _, port, err := net.SplitHostPort(addr); err != nil || port == "" {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("server.extra_listeners[%d]", i),
+				Value:   addr,
+				Message: "must be a host:port address",
+			})
+		}
+	}
+
+	// Validate TLS
+	if cfg.TLS.Enabled {
+		if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+			errors = append(errors, ValidationError{
+				Field:   "server.tls",
+				Value:   fmt.Sprintf("cert_file=%q key_file=%q", cfg.TLS.CertFile, cfg.TLS.KeyFile),
+				Message: "cert_file and key_file must be set together (leave both empty for a self-signed certificate)",
+			})
+		}
+
+		switch cfg.TLS.MinVersion {
+		case "", "1.0", "1.1", "1.2", "1.3":
+		default:
+			errors = append(errors, ValidationError{
+				Field:   "server.tls.min_version",
+				Value:   cfg.TLS.MinVersion,
+				Message: "must be one of: 1.0, 1.1, 1.2, 1.3",
+			})
+		}
+	}
+
+	// Validate streaming paths
+	for i, path := range cfg.StreamingPaths {
+		if !strings.HasPrefix(path, "/") {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("server.streaming_paths[%d]", i),
+				Value:   path,
+				Message: "must start with '/'",
+			})
+		}
+	}
+
+	// Validate health, readiness and route catalog paths
+	probes := []struct{ field, path string }{
+		{"server.health_path", cfg.HealthPath},
+		{"server.ready_path", cfg.ReadyPath},
+		{"server.routes_path", cfg.RoutesPath},
+	}
+	usedBy := make(map[string]string)
+	for _, probe := range probes {
+		if probe.path == "" {
+			continue
+		}
+		if !strings.HasPrefix(probe.path, "/") {
+			errors = append(errors, ValidationError{
+				Field:   probe.field,
+				Value:   probe.path,
+				Message: "must start with '/'",
+			})
+		}
+		if field, exists := usedBy[probe.path]; exists {
+			errors = append(errors, ValidationError{
+				Field:   probe.field,
+				Value:   probe.path,
+				Message: fmt.Sprintf("must differ from %s", field),
+			})
+			continue
+		}
+		usedBy[probe.path] = probe.field
+	}
+
+	return errors
+}
+
+func validateMock(cfg *MockConfig) ValidationErrors {
+	var errors ValidationErrors
+
+	if cfg.NullableProbability < 0 || cfg.NullableProbability > 1 {
+		errors = append(errors, ValidationError{
+			Field:   "mock.nullable_probability",
+			Value:   cfg.NullableProbability,
+			Message: "must be between 0 and 1",
+		})
+	}
+
+	if cfg.ExampleSelection != "" && cfg.ExampleSelection != "first" && cfg.ExampleSelection != "random" {
+		errors = append(errors, ValidationError{
+			Field:   "mock.example_selection",
+			Value:   cfg.ExampleSelection,
+			Message: "must be one of: first, random",
+		})
+	}
+
+	if cfg.LatencyRamp.Enabled {
+		if cfg.LatencyRamp.InitialLatency < 0 {
+			errors = append(errors, ValidationError{
+				Field:   "mock.latency_ramp.initial_latency",
+				Value:   cfg.LatencyRamp.InitialLatency,
+				Message: "cannot be negative",
+			})
+		}
+
+		if cfg.LatencyRamp.Duration <= 0 {
+			errors = append(errors, ValidationError{
+				Field:   "mock.latency_ramp.duration",
+				Value:   cfg.LatencyRamp.Duration,
+				Message: "must be greater than 0",
+			})
+		}
+	}
+
+	for i, override := range cfg.Overrides {
+		if override.Method == "" {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("mock.overrides[%d].method", i),
+				Value:   override.Method,
+				Message: "cannot be empty",
+			})
+		}
+
+		if !strings.HasPrefix(override.Path, "/") {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("mock.overrides[%d].path", i),
+				Value:   override.Path,
+				Message: "must start with /",
+			})
+		}
+
+		if override.File == "" {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("mock.overrides[%d].file", i),
+				Value:   override.File,
+				Message: "cannot be empty",
+			})
+		}
+
+		if override.Status != 0 && (override.Status < 100 || override.Status > 599) {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("mock.overrides[%d].status", i),
+				Value:   override.Status,
+				Message: "must be between 100 and 599",
+			})
+		}
+	}
+
+	unmatched := []struct {
+		field    string
+		response UnmatchedResponseConfig
+	}{
+		{"mock.not_found_response.status", cfg.NotFoundResponse},
+		{"mock.method_not_allowed_response.status", cfg.MethodNotAllowedResponse},
+	}
+	for _, entry := range unmatched {
+		if status := entry.response.Status; status != 0 && (status < 100 || status > 599) {
+			errors = append(errors, ValidationError{
+				Field:   entry.field,
+				Value:   status,
+				Message: "must be between 100 and 599",
+			})
+		}
+	}
+
 	return errors
 }
 
@@ -171,10 +424,10 @@ func validateLogging(cfg *LoggingConfig) ValidationErrors {
 	return errors
 }
 
-func validateMetrics(cfg *MetricsConfig) ValidationErrors {
+func validateMetrics(cfg *MetricsConfig, server *ServerConfig) ValidationErrors {
 	var errors ValidationErrors
 
-	if cfg.Enabled {
+	if cfg.Enabled && cfg.Listen {
 		// Validate metrics port
 		if cfg.Port < 1 || cfg.Port > 65535 {
 			errors = append(errors, ValidationError{
@@ -182,6 +435,12 @@ func validateMetrics(cfg *MetricsConfig) ValidationErrors {
 				Value:   cfg.Port,
 				Message: "must be between 1 and 65535",
 			})
+		} else if cfg.Port == server.Port {
+			errors = append(errors, ValidationError{
+				Field:   "metrics.port",
+				Value:   cfg.Port,
+				Message: "must differ from server.port",
+			})
 		}
 
 		// Validate metrics path
@@ -197,6 +456,217 @@ func validateMetrics(cfg *MetricsConfig) ValidationErrors {
 	return errors
 }
 
+func validateMiddleware(cfg *MiddlewareConfig) ValidationErrors {
+	var errors ValidationErrors
+
+	if cfg.CORS.Enabled {
+		for _, origin := range cfg.CORS.AllowOrigins {
+			if origin == "*" && cfg.CORS.AllowCredentials {
+				errors = append(errors, ValidationError{
+					Field:   "middleware.cors.allow_origins",
+					Value:   origin,
+					Message: "cannot be \"*\" when allow_credentials is true; list the allowed origins instead",
+				})
+			}
+		}
+
+		for i, pattern := range cfg.CORS.AllowOriginPatterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				errors = append(errors, ValidationError{
+					Field:   fmt.Sprintf("middleware.cors.allow_origin_patterns[%d]", i),
+					Value:   pattern,
+					Message: fmt.Sprintf("invalid regular expression: %v", err),
+				})
+			}
+		}
+	}
+
+	if cfg.MaxBodyBytes < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "middleware.max_body_bytes",
+			Value:   cfg.MaxBodyBytes,
+			Message: "cannot be negative",
+		})
+	}
+
+	if cfg.Compression.Enabled {
+		if cfg.Compression.Level < 1 || cfg.Compression.Level > 9 {
+			errors = append(errors, ValidationError{
+				Field:   "middleware.compression.level",
+				Value:   cfg.Compression.Level,
+				Message: "must be between 1 and 9",
+			})
+		}
+
+		if cfg.Compression.MinSize < 0 {
+			errors = append(errors, ValidationError{
+				Field:   "middleware.compression.min_size",
+				Value:   cfg.Compression.MinSize,
+				Message: "cannot be negative",
+			})
+		}
+	}
+
+	return errors
+}
+
+func validateAdmin(cfg *AdminConfig, server *ServerConfig) ValidationErrors {
+	var errors ValidationErrors
+
+	if cfg.Enabled {
+		if cfg.Port < 1 || cfg.Port > 65535 {
+			errors = append(errors, ValidationError{
+				Field:   "admin.port",
+				Value:   cfg.Port,
+				Message: "must be between 1 and 65535",
+			})
+		} else if cfg.Port == server.Port {
+			errors = append(errors, ValidationError{
+				Field:   "admin.port",
+				Value:   cfg.Port,
+				Message: "must differ from server.port",
+			})
+		}
+
+		if cfg.Host == "" {
+			errors = append(errors, ValidationError{
+				Field:   "admin.host",
+				Value:   cfg.Host,
+				Message: "cannot be empty",
+			})
+		}
+
+		if cfg.Token == "" {
+			errors = append(errors, ValidationError{
+				Field:   "admin.token",
+				Value:   "",
+				Message: "is required when the admin API is enabled",
+			})
+		}
+	}
+
+	return errors
+}
+
+func validateTracing(cfg *TracingConfig) ValidationErrors {
+	var errors ValidationErrors
+
+	if cfg.Enabled {
+		if cfg.ServiceName == "" {
+			errors = append(errors, ValidationError{
+				Field:   "tracing.service_name",
+				Value:   cfg.ServiceName,
+				Message: "cannot be empty",
+			})
+		}
+
+		if endpoint, err := url.Parse(cfg.Endpoint); err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			errors = append(errors, ValidationError{
+				Field:   "tracing.endpoint",
+				Value:   cfg.Endpoint,
+				Message: "must be an http or https URL",
+			})
+		}
+	}
+
+	return errors
+}
+
+func validatePluginOptions(cfg *PluginOptionsConfig) ValidationErrors {
+	var errors ValidationErrors
+
+	if cfg.ExecutionBudget < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "plugin_options.execution_budget",
+			Value:   cfg.ExecutionBudget,
+			Message: "cannot be negative (0 disables the budget)",
+		})
+	}
+
+	if cfg.AsyncWorkers < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "plugin_options.async_workers",
+			Value:   cfg.AsyncWorkers,
+			Message: "cannot be negative (0 uses the default)",
+		})
+	}
+
+	if cfg.AsyncQueueSize < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "plugin_options.async_queue_size",
+			Value:   cfg.AsyncQueueSize,
+			Message: "cannot be negative (0 uses the default)",
+		})
+	}
+
+	return errors
+}
+
+func validateRecording(cfg *RecordingConfig) ValidationErrors {
+	var errors ValidationErrors
+
+	if cfg.MaxConcurrentCaptures < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "recording.max_concurrent_captures",
+			Value:   cfg.MaxConcurrentCaptures,
+			Message: "cannot be negative (0 means unlimited)",
+		})
+	}
+
+	if cfg.MaxAge < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "recording.max_age",
+			Value:   cfg.MaxAge,
+			Message: "cannot be negative (0 keeps recordings indefinitely)",
+		})
+	}
+
+	if cfg.MaxTotalSize < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "recording.max_total_size",
+			Value:   cfg.MaxTotalSize,
+			Message: "cannot be negative (0 means unlimited)",
+		})
+	}
+
+	if cfg.Upstream != "" {
+		if upstream, err := url.Parse(cfg.Upstream); err != nil || (upstream.Scheme != "http" && upstream.Scheme != "https") || upstream.Host == "" {
+			errors = append(errors, ValidationError{
+				Field:   "recording.upstream",
+				Value:   cfg.Upstream,
+				Message: "must be an http or https URL",
+			})
+		}
+		if len(cfg.ProxyPaths) == 0 {
+			errors = append(errors, ValidationError{
+				Field:   "recording.proxy_paths",
+				Value:   cfg.ProxyPaths,
+				Message: "must list at least one path when recording.upstream is set",
+			})
+		}
+	}
+
+	for i, path := range cfg.ProxyPaths {
+		if !strings.HasPrefix(path, "/") {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("recording.proxy_paths[%d]", i),
+				Value:   path,
+				Message: "must start with '/'",
+			})
+		}
+	}
+
+	if cfg.ProxyTimeout < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "recording.proxy_timeout",
+			Value:   cfg.ProxyTimeout,
+			Message: "cannot be negative (0 uses the default)",
+		})
+	}
+
+	return errors
+}
+
 func validateChaos(cfg *ChaosConfig) ValidationErrors {
 	var errors ValidationErrors
 
@@ -212,7 +682,7 @@ func validateChaos(cfg *ChaosConfig) ValidationErrors {
 			}
 
 			// Validate scenario type
-			validTypes := []string{"latency", "error", "timeout"}
+			validTypes := []string{"latency", "error", "timeout", "bandwidth", "connection_reset", "body_corruption"}
 			typeValid := false
 			for _, t := range validTypes {
 				if scenario.Type == t {
@@ -236,6 +706,24 @@ func validateChaos(cfg *ChaosConfig) ValidationErrors {
 					Message: "must be between 0 and 1",
 				})
 			}
+
+			// Validate schedule
+			if scenario.Schedule != nil {
+				if scenario.Schedule.Duration <= 0 {
+					errors = append(errors, ValidationError{
+						Field:   fmt.Sprintf("chaos.scenarios[%d].schedule.duration", i),
+						Value:   scenario.Schedule.Duration,
+						Message: "must be greater than 0",
+					})
+				}
+				if scenario.Schedule.Period < 0 || (scenario.Schedule.Period > 0 && scenario.Schedule.Period < scenario.Schedule.Duration) {
+					errors = append(errors, ValidationError{
+						Field:   fmt.Sprintf("chaos.scenarios[%d].schedule.period", i),
+						Value:   scenario.Schedule.Period,
+						Message: "must be at least the schedule duration",
+					})
+				}
+			}
 		}
 	}
 
@@ -251,22 +739,26 @@ func ValidateConfig(cfg *Config) error {
 func parseSize(size string) (int64, error) {
 	size = strings.TrimSpace(strings.ToUpper(size))
 	
-	units := map[string]int64{
-		"B":  1,
-		"KB": 1024,
-		"MB": 1024 * 1024,
-		"GB": 1024 * 1024 * 1024,
-		"TB": 1024 * 1024 * 1024 * 1024,
+	// Multi-letter units come first so "10MB" is not read as "10M" bytes
+	units := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"KB", 1024},
+		{"MB", 1024 * 1024},
+		{"GB", 1024 * 1024 * 1024},
+		{"TB", 1024 * 1024 * 1024 * 1024},
+		{"B", 1},
 	}
 
-	for unit, multiplier := range units {
-		if strings.HasSuffix(size, unit) {
-			numStr := strings.TrimSuffix(size, unit)
+	for _, unit := range units {
+		if strings.HasSuffix(size, unit.suffix) {
+			numStr := strings.TrimSuffix(size, unit.suffix)
 			num, err := strconv.ParseFloat(numStr, 64)
 			if err != nil {
 				return 0, err
 			}
-			return int64(num * float64(multiplier)), nil
+			return int64(num * float64(unit.multiplier)), nil
 		}
 	}
 
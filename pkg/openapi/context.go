@@ -58,6 +58,8 @@ func (ctx *GenerationContext) Clone() *GenerationContext {
 		Locale:       ctx.Locale,
 		Seed:         ctx.Seed,
 		Timestamp:    ctx.Timestamp,
+		Schemas:      ctx.Schemas,
+		RefDepth:     ctx.RefDepth,
 		Visited:      make(map[string]bool),
 		ArraySizes:   make(map[string]int),
 	}
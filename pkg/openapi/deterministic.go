@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// deterministicClock centers generated dates in per-request mode, so they
+// don't drift with the day the server runs
+var deterministicClock = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// SetDeterministicPerRequest makes each request generate from its own seed,
+// derived from the request and the base seed, so identical requests get
+// byte-identical data across restarts regardless of what was served before.
+// Generated dates are centered on a fixed day instead of the current time.
+func (g *DefaultDataGenerator) SetDeterministicPerRequest(enabled bool) {
+	g.deterministic = enabled
+}
+
+// DeterministicPerRequest reports whether each request generates from its
+// own seed
+func (g *DefaultDataGenerator) DeterministicPerRequest() bool {
+	return g.deterministic
+}
+
+// RequestSeed derives the seed for a request from the base seed and a key
+// identifying the request, such as a hash of its method, path and query
+func (g *DefaultDataGenerator) RequestSeed(requestKey string) int64 {
+	hash := fnv.New64a()
+	var base [8]byte
+	binary.BigEndian.PutUint64(base[:], uint64(g.seed))
+	hash.Write(base[:])
+	hash.Write([]byte(requestKey))
+	return int64(hash.Sum64())
+}
+
+// WithSeed returns a copy of the generator with the same settings and
+// format generators that draws from its own source seeded with seed, for
+// generating one request's data without advancing the shared source. Its
+// dates are centered on a fixed day, so its output depends only on the seed.
+// Custom format generators are shared with the original as registered.
+func (g *DefaultDataGenerator) WithSeed(seed int64) *DefaultDataGenerator {
+	clone := *g
+	clone.seed = seed
+	clone.faker = gofakeit.New(seed)
+	clone.clock = deterministicClock
+
+	// Built-in format generators are bound to the generator that registered
+	// them, so the copy registers its own and keeps everything else
+	clone.formatGenerators = make(map[string]FormatGenerator, len(g.formatGenerators))
+	clone.builtinFormats = nil
+	clone.registerDefaultFormats()
+	for format := range clone.builtinFormats {
+		if !g.builtinFormats[format] {
+			delete(clone.formatGenerators, format)
+			delete(clone.builtinFormats, format)
+		}
+	}
+	for format, generator := range g.formatGenerators {
+		if !g.builtinFormats[format] {
+			clone.formatGenerators[format] = generator
+		}
+	}
+
+	return &clone
+}
+
+// currentTime returns the time generated dates are centered on
+func (g *DefaultDataGenerator) currentTime() time.Time {
+	if !g.clock.IsZero() {
+		return g.clock
+	}
+	return time.Now()
+}
@@ -0,0 +1,92 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func deterministicTestSchema() *Schema {
+	return &Schema{
+		Type:     "object",
+		Required: []string{"id", "email", "created_at"},
+		Properties: map[string]*Schema{
+			"id":         {Type: "string", Format: "uuid"},
+			"email":      {Type: "string", Format: "email"},
+			"created_at": {Type: "string", Format: "date-time"},
+			"birthday":   {Type: "string", Format: "date"},
+			"score":      {Type: "number"},
+			"tags": {
+				Type:  "array",
+				Items: &Schema{Type: "string"},
+			},
+		},
+	}
+}
+
+func generateJSON(t *testing.T, generator *DefaultDataGenerator) string {
+	t.Helper()
+	value, err := generator.Generate(deterministicTestSchema(), nil)
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	return string(data)
+}
+
+func TestRequestSeed(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(7)
+
+	if generator.RequestSeed("GET /users") != NewDefaultDataGeneratorWithSeed(7).RequestSeed("GET /users") {
+		t.Error("RequestSeed() differs for the same base seed and key")
+	}
+	if generator.RequestSeed("GET /users") == generator.RequestSeed("GET /orders") {
+		t.Error("RequestSeed() is the same for different keys")
+	}
+	if generator.RequestSeed("GET /users") == NewDefaultDataGeneratorWithSeed(8).RequestSeed("GET /users") {
+		t.Error("RequestSeed() is the same for different base seeds")
+	}
+}
+
+func TestWithSeedIsIndependentOfSharedSource(t *testing.T) {
+	first := NewDefaultDataGeneratorWithSeed(7)
+	second := NewDefaultDataGeneratorWithSeed(7)
+
+	// Advance one generator's shared source, as earlier requests would
+	for i := 0; i < 5; i++ {
+		generateJSON(t, first)
+	}
+
+	seed := first.RequestSeed("GET /users")
+	got := generateJSON(t, first.WithSeed(seed))
+	want := generateJSON(t, second.WithSeed(seed))
+	if got != want {
+		t.Errorf("WithSeed() output differs for the same seed:\n%s\n%s", got, want)
+	}
+
+	other := generateJSON(t, second.WithSeed(second.RequestSeed("GET /orders")))
+	if other == want {
+		t.Errorf("WithSeed() output is the same for different seeds: %s", other)
+	}
+}
+
+func TestWithSeedKeepsFormatGenerators(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(7)
+	generator.RegisterFormatGenerator("sku", func(_ *Schema, _ *GenerationContext) (interface{}, error) {
+		return "SKU-1", nil
+	})
+	generator.RemoveFormatGenerator("uuid")
+
+	clone := generator.WithSeed(1)
+	if _, ok := clone.formatGenerators["sku"]; !ok {
+		t.Error("WithSeed() dropped a custom format generator")
+	}
+	if _, ok := clone.formatGenerators["uuid"]; ok {
+		t.Error("WithSeed() restored a removed format generator")
+	}
+	if _, ok := clone.formatGenerators["email"]; !ok {
+		t.Error("WithSeed() dropped a built-in format generator")
+	}
+}
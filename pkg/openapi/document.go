@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/invopop/yaml"
+)
+
+// Document is the OpenAPI 3 document form of a Specification, with component
+// schemas moved back under components so $ref pointers resolve
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       InfoObject            `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components *DocumentComponents   `json:"components,omitempty"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+}
+
+// DocumentComponents holds the reusable objects of a Document
+type DocumentComponents struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// ToDocument converts the specification back into an OpenAPI 3 document
+func (s *Specification) ToDocument() *Document {
+	doc := &Document{
+		OpenAPI:  s.Version,
+		Info:     s.Info,
+		Paths:    s.Paths,
+		Security: s.Security,
+	}
+	if doc.Paths == nil {
+		doc.Paths = map[string]PathItem{}
+	}
+	if len(s.Schemas) > 0 {
+		doc.Components = &DocumentComponents{Schemas: s.Schemas}
+	}
+	return doc
+}
+
+// MarshalDocument encodes the specification as an OpenAPI 3 document in
+// JSON, or in YAML when format is "yaml" or "yml". A parsed specification
+// returns the document it was parsed from, only converted between JSON and
+// YAML, so extensions and fields the specification does not model survive;
+// one built in code is rebuilt with ToDocument.
+func (s *Specification) MarshalDocument(format string) ([]byte, error) {
+	asYAML := false
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		asYAML = true
+	}
+
+	if len(s.source) > 0 {
+		isJSON := json.Valid(s.source)
+		switch {
+		case asYAML && isJSON:
+			return yaml.JSONToYAML(s.source)
+		case !asYAML && !isJSON:
+			return yaml.YAMLToJSON(s.source)
+		default:
+			return s.source, nil
+		}
+	}
+
+	data, err := json.Marshal(s.ToDocument())
+	if err != nil {
+		return nil, err
+	}
+	if asYAML {
+		return yaml.JSONToYAML(data)
+	}
+	return data, nil
+}
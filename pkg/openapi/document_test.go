@@ -0,0 +1,123 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const documentTestSpec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: 2.1.0
+  x-owner: pets-team
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      summary: Fetch a pet
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+            minimum: 1
+      responses:
+        "200":
+          description: The pet
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+        "404":
+          description: Not found
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        friends:
+          type: array
+          items:
+            $ref: "#/components/schemas/Pet"
+`
+
+func TestSpecification_MarshalDocumentRoundTrips(t *testing.T) {
+	spec, err := NewParser().Parse([]byte(documentTestSpec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		data, err := spec.MarshalDocument(format)
+		if err != nil {
+			t.Fatalf("MarshalDocument(%s): %v", format, err)
+		}
+
+		parsed, err := NewParser().Parse(data)
+		if err != nil {
+			t.Fatalf("Parse(%s document): %v\n%s", format, err, data)
+		}
+		if !reflect.DeepEqual(spec.ToDocument(), parsed.ToDocument()) {
+			t.Errorf("%s document did not round-trip:\n%s", format, data)
+		}
+	}
+}
+
+func TestSpecification_MarshalDocumentServesSource(t *testing.T) {
+	spec, err := NewParser().Parse([]byte(documentTestSpec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	data, err := spec.MarshalDocument("yaml")
+	if err != nil {
+		t.Fatalf("MarshalDocument(yaml): %v", err)
+	}
+	if !bytes.Equal(data, []byte(documentTestSpec)) {
+		t.Errorf("yaml document is not the parsed source:\n%s", data)
+	}
+
+	// The JSON form is a conversion of the source, so fields the
+	// specification does not model are kept
+	data, err = spec.MarshalDocument("json")
+	if err != nil {
+		t.Fatalf("MarshalDocument(json): %v", err)
+	}
+	var doc struct {
+		Info  map[string]interface{}            `json:"info"`
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("json document does not decode: %v\n%s", err, data)
+	}
+	if doc.Info["x-owner"] != "pets-team" {
+		t.Errorf("x-owner = %v, want pets-team", doc.Info["x-owner"])
+	}
+	if _, ok := doc.Paths["/pets/{id}"]["get"]; !ok {
+		t.Errorf("json document lost /pets/{id}:\n%s", data)
+	}
+}
+
+func TestSpecification_MarshalDocumentWithoutSource(t *testing.T) {
+	spec := &Specification{
+		Version: "3.0.3",
+		Info:    InfoObject{Title: "Built", Version: "1.0.0"},
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		data, err := spec.MarshalDocument(format)
+		if err != nil {
+			t.Fatalf("MarshalDocument(%s): %v", format, err)
+		}
+		if !bytes.Contains(data, []byte("Built")) {
+			t.Errorf("%s document is missing the title:\n%s", format, data)
+		}
+	}
+}
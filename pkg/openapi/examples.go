@@ -0,0 +1,183 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SetPreferExamples controls whether schema and media type examples take
+// precedence over random generation. Disable it to force purely random data,
+// e.g. for fuzzing clients.
+func (g *DefaultDataGenerator) SetPreferExamples(prefer bool) {
+	g.preferExamples = prefer
+}
+
+// PreferExamples reports whether examples take precedence over random generation
+func (g *DefaultDataGenerator) PreferExamples() bool {
+	return g.preferExamples
+}
+
+// SetRandomExamples controls which named example is served when the request
+// names none: a random one instead of the first by name
+func (g *DefaultDataGenerator) SetRandomExamples(random bool) {
+	g.randomExamples = random
+}
+
+// RandomExamples reports whether unrequested named examples are picked at random
+func (g *DefaultDataGenerator) RandomExamples() bool {
+	return g.randomExamples
+}
+
+// GenerateForMediaType generates mock data for a response media type.
+// When examples are preferred, the named entry from the media type's examples
+// is used if present, then its example, then the first (or, with
+// SetRandomExamples, a random) named example. Fields the example leaves out
+// are generated from the schema.
+func (g *DefaultDataGenerator) GenerateForMediaType(media *MediaTypeObject, exampleName string, ctx *GenerationContext) (interface{}, error) {
+	if media == nil {
+		return nil, fmt.Errorf("media type cannot be nil")
+	}
+
+	if g.preferExamples {
+		if example, ok := g.selectMediaExample(media, exampleName); ok {
+			if media.Schema == nil {
+				return example, nil
+			}
+			if ctx == nil {
+				ctx = NewGenerationContext()
+				ctx.Locale = g.locale
+				ctx.Seed = g.seed
+			}
+			return g.completeExample(media.Schema, example, ctx)
+		}
+	}
+
+	return g.Generate(media.Schema, ctx)
+}
+
+// selectMediaExample picks the example value to serve for a media type
+func (g *DefaultDataGenerator) selectMediaExample(media *MediaTypeObject, name string) (interface{}, bool) {
+	if name != "" {
+		if example, exists := media.Examples[name]; exists && example != nil && example.Value != nil {
+			return example.Value, true
+		}
+	}
+
+	if media.Example != nil {
+		return media.Example, true
+	}
+
+	names := make([]string, 0, len(media.Examples))
+	for exampleName, example := range media.Examples {
+		if example != nil && example.Value != nil {
+			names = append(names, exampleName)
+		}
+	}
+	if len(names) == 0 {
+		return nil, false
+	}
+	sort.Strings(names)
+
+	if g.randomExamples {
+		return media.Examples[names[g.faker.IntRange(0, len(names)-1)]].Value, true
+	}
+	return media.Examples[names[0]].Value, true
+}
+
+// completeExample returns a copy of example with any schema properties it
+// lacks generated randomly, recursing into nested objects and array items.
+// The spec's example is never modified.
+func (g *DefaultDataGenerator) completeExample(schema *Schema, example interface{}, ctx *GenerationContext) (interface{}, error) {
+	if schema.Ref != "" {
+		target, err := resolveRef(schema.Ref, ctx)
+		if err != nil {
+			return nil, err
+		}
+		schema = target
+	}
+	if len(schema.AllOf) > 0 {
+		merged, err := g.mergeAllOf(schema, ctx, make(map[string]bool))
+		if err != nil {
+			return nil, err
+		}
+		schema = merged
+	}
+
+	switch value := example.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, field := range value {
+			result[key] = field
+		}
+		if len(schema.Properties) == 0 {
+			return result, nil
+		}
+
+		newCtx := *ctx
+		newCtx.CurrentDepth++
+
+		requiredFields := make(map[string]bool)
+		for _, field := range schema.Required {
+			requiredFields[field] = true
+		}
+
+		// Iterate in a stable order so seeded generation stays reproducible
+		propNames := make([]string, 0, len(schema.Properties))
+		for propName := range schema.Properties {
+			propNames = append(propNames, propName)
+		}
+		sort.Strings(propNames)
+
+		for _, propName := range propNames {
+			propSchema := schema.Properties[propName]
+			if propSchema == nil {
+				continue
+			}
+
+			newCtx.Required = requiredFields[propName]
+			newCtx.Parent = propName
+
+			if existing, exists := result[propName]; exists {
+				completed, err := g.completeExample(propSchema, existing, &newCtx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to complete example property '%s': %w", propName, err)
+				}
+				result[propName] = completed
+				continue
+			}
+
+			generated, err := g.Generate(propSchema, &newCtx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate property '%s': %w", propName, err)
+			}
+			if generated != nil {
+				result[propName] = generated
+			}
+		}
+
+		return result, nil
+
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		if schema.Items == nil {
+			copy(result, value)
+			return result, nil
+		}
+
+		newCtx := *ctx
+		newCtx.CurrentDepth++
+
+		for i, item := range value {
+			completed, err := g.completeExample(schema.Items, item, &newCtx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to complete example item %d: %w", i, err)
+			}
+			result[i] = completed
+		}
+
+		return result, nil
+
+	default:
+		return example, nil
+	}
+}
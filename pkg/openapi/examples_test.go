@@ -0,0 +1,301 @@
+package openapi
+
+import (
+	"fmt"
+	"testing"
+)
+
+func userSchema() *Schema {
+	return &Schema{
+		Type:     "object",
+		Required: []string{"id", "name", "email"},
+		Properties: map[string]*Schema{
+			"id":    {Type: "integer"},
+			"name":  {Type: "string"},
+			"email": {Type: "string"},
+			"address": {
+				Type:     "object",
+				Required: []string{"city", "zip"},
+				Properties: map[string]*Schema{
+					"city": {Type: "string"},
+					"zip":  {Type: "string"},
+				},
+			},
+		},
+	}
+}
+
+func TestGenerateObjectExampleFillsMissingFields(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(42)
+
+	schema := userSchema()
+	schema.Example = map[string]interface{}{
+		"name": "Ada Lovelace",
+		"address": map[string]interface{}{
+			"city": "London",
+		},
+	}
+
+	result, err := generator.Generate(schema, NewGenerationContext())
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	obj, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected object, got %T", result)
+	}
+
+	if obj["name"] != "Ada Lovelace" {
+		t.Errorf("expected example name to be kept, got %v", obj["name"])
+	}
+	if _, ok := obj["id"].(int); !ok {
+		t.Errorf("expected missing id to be generated as int, got %T", obj["id"])
+	}
+	if _, ok := obj["email"].(string); !ok {
+		t.Errorf("expected missing email to be generated as string, got %T", obj["email"])
+	}
+
+	address, ok := obj["address"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected nested address object, got %T", obj["address"])
+	}
+	if address["city"] != "London" {
+		t.Errorf("expected nested example city to be kept, got %v", address["city"])
+	}
+	if _, ok := address["zip"].(string); !ok {
+		t.Errorf("expected missing nested zip to be generated, got %T", address["zip"])
+	}
+
+	// The spec's example must not be modified
+	original := schema.Example.(map[string]interface{})
+	if _, exists := original["id"]; exists {
+		t.Error("schema example was mutated")
+	}
+	if _, exists := original["address"].(map[string]interface{})["zip"]; exists {
+		t.Error("nested schema example was mutated")
+	}
+}
+
+func TestGenerateArrayExampleCompletesItems(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(42)
+
+	schema := &Schema{
+		Type:  "array",
+		Items: userSchema(),
+		Example: []interface{}{
+			map[string]interface{}{"id": 1, "name": "Ada", "email": "ada@example.com"},
+			map[string]interface{}{"id": 2, "name": "Grace"},
+		},
+	}
+
+	result, err := generator.Generate(schema, NewGenerationContext())
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	items, ok := result.([]interface{})
+	if !ok {
+		t.Fatalf("expected array, got %T", result)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected example length 2, got %d", len(items))
+	}
+
+	first := items[0].(map[string]interface{})
+	if first["email"] != "ada@example.com" {
+		t.Errorf("expected example email to be kept, got %v", first["email"])
+	}
+
+	second := items[1].(map[string]interface{})
+	if second["name"] != "Grace" {
+		t.Errorf("expected example name to be kept, got %v", second["name"])
+	}
+	if _, ok := second["email"].(string); !ok {
+		t.Errorf("expected missing email to be generated, got %T", second["email"])
+	}
+}
+
+func TestGenerateNestedPropertyExample(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(42)
+
+	schema := &Schema{
+		Type:     "object",
+		Required: []string{"status"},
+		Properties: map[string]*Schema{
+			"status": {Type: "string", Example: "active"},
+		},
+	}
+
+	result, err := generator.Generate(schema, NewGenerationContext())
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	if status := result.(map[string]interface{})["status"]; status != "active" {
+		t.Errorf("expected nested property example 'active', got %v", status)
+	}
+}
+
+func TestGenerateIgnoresExamplesWhenDisabled(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(42)
+	if !generator.PreferExamples() {
+		t.Fatal("expected examples to be preferred by default")
+	}
+	generator.SetPreferExamples(false)
+
+	schema := &Schema{
+		Type:      "string",
+		Example:   "fixed example",
+		MinLength: intPtr(20),
+		MaxLength: intPtr(20),
+	}
+
+	result, err := generator.Generate(schema, NewGenerationContext())
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if result == "fixed example" {
+		t.Error("expected random data when examples are disabled")
+	}
+
+	media := &MediaTypeObject{
+		Schema:  &Schema{Type: "integer"},
+		Example: "fixed example",
+	}
+	result, err = generator.GenerateForMediaType(media, "", NewGenerationContext())
+	if err != nil {
+		t.Fatalf("GenerateForMediaType() error: %v", err)
+	}
+	if _, ok := result.(int); !ok {
+		t.Errorf("expected random integer when examples are disabled, got %T", result)
+	}
+}
+
+func TestGenerateForMediaTypeExamples(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(42)
+
+	media := &MediaTypeObject{
+		Schema: userSchema(),
+		Examples: map[string]*Example{
+			"admin": {
+				Summary: "An administrator",
+				Value:   map[string]interface{}{"id": 1, "name": "Root", "email": "root@example.com"},
+			},
+			"guest": {
+				Value: map[string]interface{}{"name": "Guest"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		exampleName  string
+		expectedName string
+	}{
+		{"named example", "guest", "Guest"},
+		{"first example by name when unnamed", "", "Root"},
+		{"unknown name falls back", "missing", "Root"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := generator.GenerateForMediaType(media, tt.exampleName, NewGenerationContext())
+			if err != nil {
+				t.Fatalf("GenerateForMediaType() error: %v", err)
+			}
+
+			obj, ok := result.(map[string]interface{})
+			if !ok {
+				t.Fatalf("expected object, got %T", result)
+			}
+			if obj["name"] != tt.expectedName {
+				t.Errorf("expected name %q, got %v", tt.expectedName, obj["name"])
+			}
+			if _, ok := obj["email"].(string); !ok {
+				t.Errorf("expected email to be present, got %T", obj["email"])
+			}
+		})
+	}
+}
+
+func TestGenerateForMediaTypeExamplePrecedence(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(42)
+
+	media := &MediaTypeObject{
+		Schema:  &Schema{Type: "string", Example: "schema example"},
+		Example: "media example",
+		Examples: map[string]*Example{
+			"named": {Value: "named example"},
+		},
+	}
+
+	result, err := generator.GenerateForMediaType(media, "", NewGenerationContext())
+	if err != nil {
+		t.Fatalf("GenerateForMediaType() error: %v", err)
+	}
+	if result != "media example" {
+		t.Errorf("expected media type example, got %v", result)
+	}
+
+	result, err = generator.GenerateForMediaType(media, "named", NewGenerationContext())
+	if err != nil {
+		t.Fatalf("GenerateForMediaType() error: %v", err)
+	}
+	if result != "named example" {
+		t.Errorf("expected named example, got %v", result)
+	}
+
+	// Without media-level examples the schema example is used
+	result, err = generator.GenerateForMediaType(&MediaTypeObject{Schema: media.Schema}, "", NewGenerationContext())
+	if err != nil {
+		t.Fatalf("GenerateForMediaType() error: %v", err)
+	}
+	if result != "schema example" {
+		t.Errorf("expected schema example, got %v", result)
+	}
+}
+
+func TestGenerateForMediaTypeRandomExamples(t *testing.T) {
+	media := &MediaTypeObject{
+		Examples: map[string]*Example{
+			"empty": {Value: []interface{}{}},
+			"full":  {Value: []interface{}{"a", "b"}},
+			"error": {Value: map[string]interface{}{"error": "boom"}},
+		},
+	}
+
+	first := NewDefaultDataGeneratorWithSeed(42)
+	random := NewDefaultDataGeneratorWithSeed(42)
+	random.SetRandomExamples(true)
+
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		result, err := first.GenerateForMediaType(media, "", NewGenerationContext())
+		if err != nil {
+			t.Fatalf("GenerateForMediaType() error: %v", err)
+		}
+		if items, ok := result.([]interface{}); !ok || len(items) != 0 {
+			t.Fatalf("expected the first example by name, got %v", result)
+		}
+
+		result, err = random.GenerateForMediaType(media, "", NewGenerationContext())
+		if err != nil {
+			t.Fatalf("GenerateForMediaType() error: %v", err)
+		}
+		seen[fmt.Sprint(result)] = true
+
+		// A requested name still wins over random selection
+		result, err = random.GenerateForMediaType(media, "full", NewGenerationContext())
+		if err != nil {
+			t.Fatalf("GenerateForMediaType() error: %v", err)
+		}
+		if items, ok := result.([]interface{}); !ok || len(items) != 2 {
+			t.Fatalf("expected the requested example, got %v", result)
+		}
+	}
+
+	if len(seen) != len(media.Examples) {
+		t.Errorf("random selection served %d of %d examples: %v", len(seen), len(media.Examples), seen)
+	}
+}
@@ -1,6 +1,7 @@
 package openapi
 
 import (
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"time"
@@ -64,20 +65,25 @@ func (g *DefaultDataGenerator) registerDefaultFormats() {
 	g.RegisterFormatGenerator("phone", g.generatePhone)
 	g.RegisterFormatGenerator("credit-card", g.generateCreditCard)
 	g.RegisterFormatGenerator("iban", g.generateIBAN)
+	
+	g.builtinFormats = make(map[string]bool, len(g.formatGenerators))
+	for format := range g.formatGenerators {
+		g.builtinFormats[format] = true
+	}
 }
 
 // Date/Time format generators
 
 func (g *DefaultDataGenerator) generateDateTime(schema *Schema, ctx *GenerationContext) (interface{}, error) {
 	// Generate RFC3339 formatted datetime
-	now := time.Now()
+	now := g.currentTime()
 	randomTime := g.faker.DateRange(now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0))
 	return randomTime.Format(time.RFC3339), nil
 }
 
 func (g *DefaultDataGenerator) generateDate(schema *Schema, ctx *GenerationContext) (interface{}, error) {
 	// Generate RFC3339 date (YYYY-MM-DD)
-	now := time.Now()
+	now := g.currentTime()
 	randomTime := g.faker.DateRange(now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0))
 	return randomTime.Format("2006-01-02"), nil
 }
@@ -147,7 +153,7 @@ func (g *DefaultDataGenerator) generateByte(schema *Schema, ctx *GenerationConte
 	for i := range bytes {
 		bytes[i] = byte(g.faker.IntRange(0, 255))
 	}
-	return fmt.Sprintf("%x", bytes), nil
+	return base64.StdEncoding.EncodeToString(bytes), nil
 }
 
 func (g *DefaultDataGenerator) generateBinary(schema *Schema, ctx *GenerationContext) (interface{}, error) {
@@ -295,6 +301,7 @@ func (g *DefaultDataGenerator) HasFormatGenerator(format string) bool {
 // RemoveFormatGenerator removes a format generator
 func (g *DefaultDataGenerator) RemoveFormatGenerator(format string) {
 	delete(g.formatGenerators, format)
+	delete(g.builtinFormats, format)
 }
 
 // FormatGeneratorInfo provides information about format generators
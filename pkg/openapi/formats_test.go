@@ -1,11 +1,16 @@
 package openapi
 
 import (
+	"encoding/base64"
 	"net"
+	"net/mail"
+	"net/url"
 	"regexp"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 func TestFormatGenerators(t *testing.T) {
@@ -157,6 +162,111 @@ func TestFormatGenerators(t *testing.T) {
 	}
 }
 
+func TestFormatValuesParse(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(12345)
+	ctx := NewGenerationContext()
+
+	tests := []struct {
+		format string
+		parse  func(string) error
+	}{
+		{FormatEmail, func(s string) error { _, err := mail.ParseAddress(s); return err }},
+		{FormatUUID, func(s string) error { _, err := uuid.Parse(s); return err }},
+		{FormatURI, func(s string) error { _, err := url.ParseRequestURI(s); return err }},
+		{FormatByte, func(s string) error { _, err := base64.StdEncoding.DecodeString(s); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				result, err := generator.Generate(&Schema{Type: "string", Format: tt.format}, ctx)
+				if err != nil {
+					t.Fatalf("Generate() error: %v", err)
+				}
+				str, ok := result.(string)
+				if !ok {
+					t.Fatalf("expected string, got %T", result)
+				}
+				if err := tt.parse(str); err != nil {
+					t.Errorf("generated %s value %q does not parse: %v", tt.format, str, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatValuesDeterministic(t *testing.T) {
+	formats := []string{FormatEmail, FormatUUID, FormatURI, FormatIPv4, FormatHostname, FormatByte}
+
+	generate := func() []interface{} {
+		generator := NewDefaultDataGeneratorWithSeed(777)
+		values := make([]interface{}, 0, len(formats))
+		for _, format := range formats {
+			value, err := generator.Generate(&Schema{Type: "string", Format: format}, NewGenerationContext())
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			values = append(values, value)
+		}
+		return values
+	}
+
+	first, second := generate(), generate()
+	for i := range formats {
+		if first[i] != second[i] {
+			t.Errorf("format %s not deterministic for a fixed seed: %v != %v", formats[i], first[i], second[i])
+		}
+	}
+}
+
+func TestPatternConstrainedStrings(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(12345)
+	ctx := NewGenerationContext()
+
+	patterns := []string{
+		`^[A-Z]{3}-\d{4}$`,
+		`^(foo|bar)_[a-z0-9]+$`,
+		`^\+?[0-9]{10,12}$`,
+		`^[^@\s]+@example\.com$`,
+		`^v[0-9]+(\.[0-9]+){2}(-beta)?$`,
+	}
+
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			re := regexp.MustCompile(pattern)
+			for i := 0; i < 20; i++ {
+				result, err := generator.Generate(&Schema{Type: "string", Pattern: pattern}, ctx)
+				if err != nil {
+					t.Fatalf("Generate() error: %v", err)
+				}
+				if !re.MatchString(result.(string)) {
+					t.Errorf("generated %q does not match %s", result, pattern)
+				}
+			}
+		})
+	}
+}
+
+func TestPatternTakesPrecedenceOverFormat(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(12345)
+
+	pattern := `^user[0-9]{3}@corp\.test$`
+	schema := &Schema{Type: "string", Format: FormatEmail, Pattern: pattern}
+
+	result, err := generator.Generate(schema, NewGenerationContext())
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	str := result.(string)
+	if !regexp.MustCompile(pattern).MatchString(str) {
+		t.Errorf("generated %q does not match %s", str, pattern)
+	}
+	if _, err := mail.ParseAddress(str); err != nil {
+		t.Errorf("generated %q is not a valid email: %v", str, err)
+	}
+}
+
 func TestNumericFormats(t *testing.T) {
 	generator := NewDefaultDataGenerator()
 	generator.SetSeed(12345)
@@ -2,6 +2,10 @@ package openapi
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/brianvoe/gofakeit/v6"
@@ -12,6 +16,10 @@ type DataGenerator interface {
 	// Generate creates mock data based on the provided schema and context
 	Generate(schema *Schema, ctx *GenerationContext) (interface{}, error)
 	
+	// GenerateForMediaType creates mock data for a response media type,
+	// using its examples when available
+	GenerateForMediaType(media *MediaTypeObject, exampleName string, ctx *GenerationContext) (interface{}, error)
+	
 	// SetSeed sets the random seed for deterministic generation
 	SetSeed(seed int64)
 	
@@ -22,12 +30,27 @@ type DataGenerator interface {
 // FormatGenerator defines a function type for format-specific data generation
 type FormatGenerator func(schema *Schema, ctx *GenerationContext) (interface{}, error)
 
+// DefaultNullableProbability is the chance that a nullable schema generates null
+const DefaultNullableProbability = 0.1
+
 // DefaultDataGenerator is the default implementation of DataGenerator
 type DefaultDataGenerator struct {
-	faker            *gofakeit.Faker
-	formatGenerators map[string]FormatGenerator
-	locale           string
-	seed             int64
+	faker               *gofakeit.Faker
+	formatGenerators    map[string]FormatGenerator
+	locale              string
+	seed                int64
+	preferExamples      bool
+	randomExamples      bool
+	nullableProbability float64
+
+	// builtinFormats names the formats still served by the generators
+	// registerDefaultFormats installed, which copies rebind to themselves
+	builtinFormats map[string]bool
+
+	// deterministic derives a seed per request; clock, when set, replaces
+	// the current time as the center of generated date ranges
+	deterministic bool
+	clock         time.Time
 }
 
 // NewDefaultDataGenerator creates a new DefaultDataGenerator instance
@@ -36,10 +59,12 @@ func NewDefaultDataGenerator() *DefaultDataGenerator {
 	faker := gofakeit.New(seed)
 	
 	generator := &DefaultDataGenerator{
-		faker:            faker,
-		formatGenerators: make(map[string]FormatGenerator),
-		locale:           "en",
-		seed:             seed,
+		faker:               faker,
+		formatGenerators:    make(map[string]FormatGenerator),
+		locale:              "en",
+		seed:                seed,
+		preferExamples:      true,
+		nullableProbability: DefaultNullableProbability,
 	}
 	
 	// Register default format generators
@@ -53,10 +78,12 @@ func NewDefaultDataGeneratorWithSeed(seed int64) *DefaultDataGenerator {
 	faker := gofakeit.New(seed)
 	
 	generator := &DefaultDataGenerator{
-		faker:            faker,
-		formatGenerators: make(map[string]FormatGenerator),
-		locale:           "en",
-		seed:             seed,
+		faker:               faker,
+		formatGenerators:    make(map[string]FormatGenerator),
+		locale:              "en",
+		seed:                seed,
+		preferExamples:      true,
+		nullableProbability: DefaultNullableProbability,
 	}
 	
 	// Register default format generators
@@ -88,9 +115,32 @@ func (g *DefaultDataGenerator) Generate(schema *Schema, ctx *GenerationContext)
 		return nil, nil
 	}
 	
-	// Prioritize example if available
-	if schema.Example != nil {
-		return schema.Example, nil
+	if ctx.Visited == nil {
+		ctx.Visited = make(map[string]bool)
+	}
+	
+	// Resolve references to component schemas
+	if schema.Ref != "" {
+		return g.generateRef(schema, ctx)
+	}
+	
+	// Flatten allOf into a single schema
+	if len(schema.AllOf) > 0 {
+		merged, err := g.mergeAllOf(schema, ctx, make(map[string]bool))
+		if err != nil {
+			return nil, err
+		}
+		return g.Generate(merged, ctx)
+	}
+	
+	// Prioritize example if available, filling in any fields it leaves out
+	if g.preferExamples && schema.Example != nil {
+		return g.completeExample(schema, schema.Example, ctx)
+	}
+	
+	// Occasionally emit null for nullable schemas
+	if schema.Nullable && g.nullableProbability > 0 && g.faker.Float64Range(0, 1) < g.nullableProbability {
+		return nil, nil
 	}
 	
 	// Handle enum values
@@ -98,10 +148,22 @@ func (g *DefaultDataGenerator) Generate(schema *Schema, ctx *GenerationContext)
 		return schema.Enum[g.faker.IntRange(0, len(schema.Enum)-1)], nil
 	}
 	
+	// Pick one branch of oneOf/anyOf
+	if len(schema.OneOf) > 0 {
+		return g.generateBranch(schema.OneOf, ctx)
+	}
+	if len(schema.AnyOf) > 0 {
+		return g.generateBranch(schema.AnyOf, ctx)
+	}
+	
 	// Check for format-specific generators first
 	if schema.Format != "" {
 		if formatGen, exists := g.formatGenerators[schema.Format]; exists {
-			return formatGen(schema, ctx)
+			value, err := formatGen(schema, ctx)
+			if err != nil || schema.Pattern == "" || matchesPattern(schema.Pattern, value) {
+				return value, err
+			}
+			// The pattern is the stricter constraint, so generate from it instead
 		}
 	}
 	
@@ -152,9 +214,21 @@ func (g *DefaultDataGenerator) GetSeed() int64 {
 	return g.seed
 }
 
+// SetNullableProbability sets the chance, between 0 and 1, that a nullable
+// schema generates null instead of a value. Zero disables null generation.
+func (g *DefaultDataGenerator) SetNullableProbability(probability float64) {
+	g.nullableProbability = math.Max(0, math.Min(1, probability))
+}
+
+// NullableProbability returns the chance that a nullable schema generates null
+func (g *DefaultDataGenerator) NullableProbability() float64 {
+	return g.nullableProbability
+}
+
 // RegisterFormatGenerator registers a custom format generator
 func (g *DefaultDataGenerator) RegisterFormatGenerator(format string, generator FormatGenerator) {
 	g.formatGenerators[format] = generator
+	delete(g.builtinFormats, format)
 }
 
 // generateString generates a string value based on schema constraints
@@ -162,28 +236,19 @@ func (g *DefaultDataGenerator) generateString(schema *Schema, ctx *GenerationCon
 	var result string
 	
 	// Determine length constraints
-	minLength := 1
-	maxLength := 50
+	minLength, maxLength := lengthBounds(schema.MinLength, schema.MaxLength, 1, 50)
 	
-	if schema.MinLength != nil && *schema.MinLength > 0 {
-		minLength = *schema.MinLength
-	}
-	if schema.MaxLength != nil && *schema.MaxLength > 0 {
-		maxLength = *schema.MaxLength
-		// Ensure maxLength is not less than minLength
-		if maxLength < minLength {
-			maxLength = minLength
+	// Handle pattern constraint
+	if schema.Pattern != "" {
+		if generated, ok := g.generateFromPattern(schema.Pattern, minLength, maxLength); ok {
+			return generated, nil
 		}
+		// Unsupported pattern, fall back to a plain string
 	}
 	
-	// Handle pattern constraint
-	if schema.Pattern != "" {
-		// For patterns, we'll generate a simple string and hope it matches
-		// In a production system, you might want to use a regex-based generator
-		result = g.faker.LetterN(uint(g.faker.IntRange(minLength, maxLength)))
-	} else {
-		// Generate a random string within length constraints
-		length := g.faker.IntRange(minLength, maxLength)
+	// Generate a random string within length constraints. LetterN never
+	// returns an empty string, so a zero length is handled here.
+	if length := g.faker.IntRange(minLength, maxLength); length > 0 {
 		result = g.faker.LetterN(uint(length))
 	}
 	
@@ -192,18 +257,18 @@ func (g *DefaultDataGenerator) generateString(schema *Schema, ctx *GenerationCon
 
 // generateInteger generates an integer value based on schema constraints
 func (g *DefaultDataGenerator) generateInteger(schema *Schema, ctx *GenerationContext) (interface{}, error) {
-	min := -1000
-	max := 1000
+	lower, upper := numberBounds(schema, -1000, 1000)
 	
-	if schema.Minimum != nil {
-		min = int(*schema.Minimum)
+	// Fractional bounds narrow the range to the integers inside it
+	min := int(math.Ceil(lower))
+	max := int(math.Floor(upper))
+	if max < min {
+		max = min
 	}
-	if schema.Maximum != nil {
-		max = int(*schema.Maximum)
-		// Ensure max is not less than min
-		if max < min {
-			max = min
-		}
+	
+	// Only whole multipleOf values can produce integers
+	if schema.MultipleOf != nil && *schema.MultipleOf >= 1 && *schema.MultipleOf == math.Trunc(*schema.MultipleOf) {
+		return int(g.multipleInRange(*schema.MultipleOf, float64(min), float64(max))), nil
 	}
 	
 	return g.faker.IntRange(min, max), nil
@@ -211,21 +276,85 @@ func (g *DefaultDataGenerator) generateInteger(schema *Schema, ctx *GenerationCo
 
 // generateNumber generates a float64 value based on schema constraints
 func (g *DefaultDataGenerator) generateNumber(schema *Schema, ctx *GenerationContext) (interface{}, error) {
-	min := -1000.0
-	max := 1000.0
+	min, max := numberBounds(schema, -1000, 1000)
+	
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		return g.multipleInRange(*schema.MultipleOf, min, max), nil
+	}
 	
+	return g.faker.Float64Range(min, max), nil
+}
+
+// multipleInRange picks a random multiple of step within [min, max]. When the
+// range holds no multiple, the one just above min is returned.
+func (g *DefaultDataGenerator) multipleInRange(step, min, max float64) float64 {
+	first := math.Ceil(min / step)
+	last := math.Floor(max / step)
+	if last < first {
+		last = first
+	}
+	
+	value := float64(g.faker.IntRange(int(first), int(last))) * step
+	
+	// Trim floating point noise, e.g. 3 * 0.1 = 0.30000000000000004
+	text := strconv.FormatFloat(step, 'f', -1, 64)
+	if dot := strings.IndexByte(text, '.'); dot >= 0 {
+		scale := math.Pow10(len(text) - dot - 1)
+		value = math.Round(value*scale) / scale
+	}
+	return value
+}
+
+// numberBounds returns the range to generate numbers in. When only one bound
+// is set and it falls outside the default range, the other bound is moved
+// so the range keeps its default width.
+func numberBounds(schema *Schema, defaultMin, defaultMax float64) (float64, float64) {
+	min, max := defaultMin, defaultMax
 	if schema.Minimum != nil {
 		min = *schema.Minimum
 	}
 	if schema.Maximum != nil {
 		max = *schema.Maximum
-		// Ensure max is not less than min
-		if max < min {
+	}
+	
+	if max < min {
+		switch {
+		case schema.Maximum == nil:
+			max = min + (defaultMax - defaultMin)
+		case schema.Minimum == nil:
+			min = max - (defaultMax - defaultMin)
+		default:
+			// Contradictory bounds, honor the minimum
 			max = min
 		}
 	}
+	return min, max
+}
+
+// lengthBounds returns the length range for strings and arrays given their
+// optional min/max constraints, adjusting the defaults the same way
+// numberBounds does
+func lengthBounds(minValue, maxValue *int, defaultMin, defaultMax int) (int, int) {
+	min, max := defaultMin, defaultMax
+	if minValue != nil && *minValue >= 0 {
+		min = *minValue
+	}
+	if maxValue != nil && *maxValue >= 0 {
+		max = *maxValue
+	}
 	
-	return g.faker.Float64Range(min, max), nil
+	if max < min {
+		switch {
+		case maxValue == nil || *maxValue < 0:
+			max = min + (defaultMax - defaultMin)
+		case minValue == nil || *minValue < 0:
+			min = max
+		default:
+			// Contradictory bounds, honor the minimum
+			max = min
+		}
+	}
+	return min, max
 }
 
 // generateBoolean generates a boolean value
@@ -240,21 +369,9 @@ func (g *DefaultDataGenerator) generateArray(schema *Schema, ctx *GenerationCont
 	}
 	
 	// Determine array size constraints
-	minItems := 1
-	maxItems := 3
+	minItems, maxItems := lengthBounds(schema.MinItems, schema.MaxItems, 1, 3)
 	defaultArraySize := 2
 	
-	if schema.MinItems != nil && *schema.MinItems >= 0 {
-		minItems = *schema.MinItems
-	}
-	if schema.MaxItems != nil && *schema.MaxItems >= 0 {
-		maxItems = *schema.MaxItems
-		// Ensure maxItems is not less than minItems
-		if maxItems < minItems {
-			maxItems = minItems
-		}
-	}
-	
 	// Use configured default array size if within constraints
 	if defaultArraySize >= minItems && defaultArraySize <= maxItems {
 		maxItems = defaultArraySize
@@ -297,8 +414,16 @@ func (g *DefaultDataGenerator) generateObject(schema *Schema, ctx *GenerationCon
 		requiredFields[field] = true
 	}
 	
+	// Iterate in a stable order so seeded generation stays reproducible
+	propNames := make([]string, 0, len(schema.Properties))
+	for propName := range schema.Properties {
+		propNames = append(propNames, propName)
+	}
+	sort.Strings(propNames)
+	
 	// Generate properties
-	for propName, propSchema := range schema.Properties {
+	for _, propName := range propNames {
+		propSchema := schema.Properties[propName]
 		// Skip if we've hit depth limit and this is not a required field
 		if newCtx.CurrentDepth >= newCtx.MaxDepth && !requiredFields[propName] {
 			continue
@@ -318,7 +443,8 @@ func (g *DefaultDataGenerator) generateObject(schema *Schema, ctx *GenerationCon
 			return nil, fmt.Errorf("failed to generate property '%s': %w", propName, err)
 		}
 		
-		if value != nil {
+		// Keep explicit nulls for nullable properties
+		if value != nil || propSchema.Nullable {
 			result[propName] = value
 		}
 	}
@@ -1,6 +1,7 @@
 package openapi
 
 import (
+	"math"
 	"testing"
 	"time"
 )
@@ -421,4 +422,209 @@ func TestGenerateWithMaxDepth(t *testing.T) {
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+// generateSamples generates a value for schema with n different seeds
+func generateSamples(t *testing.T, schema *Schema, n int) []interface{} {
+	t.Helper()
+	samples := make([]interface{}, 0, n)
+	for seed := int64(1); seed <= int64(n); seed++ {
+		generator := NewDefaultDataGeneratorWithSeed(seed)
+		value, err := generator.Generate(schema, nil)
+		if err != nil {
+			t.Fatalf("Generate() error: %v", err)
+		}
+		samples = append(samples, value)
+	}
+	return samples
+}
+
+func TestGenerateIntegerRespectsBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   *Schema
+		min, max int
+		multiple int
+	}{
+		{"minimum only", &Schema{Type: "integer", Minimum: floatPtr(18)}, 18, math.MaxInt, 1},
+		{"minimum above default range", &Schema{Type: "integer", Minimum: floatPtr(5000)}, 5000, math.MaxInt, 1},
+		{"maximum below default range", &Schema{Type: "integer", Maximum: floatPtr(-5000)}, math.MinInt, -5000, 1},
+		{"fractional bounds", &Schema{Type: "integer", Minimum: floatPtr(1.5), Maximum: floatPtr(3.5)}, 2, 3, 1},
+		{"multipleOf", &Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(100), MultipleOf: floatPtr(7)}, 7, 98, 7},
+	}
+
+	for _, tt := range tests {
+		for _, sample := range generateSamples(t, tt.schema, 50) {
+			value, ok := sample.(int)
+			if !ok {
+				t.Fatalf("%s: expected int, got %T", tt.name, sample)
+			}
+			if value < tt.min || value > tt.max {
+				t.Errorf("%s: %d not within [%d, %d]", tt.name, value, tt.min, tt.max)
+			}
+			if value%tt.multiple != 0 {
+				t.Errorf("%s: %d is not a multiple of %d", tt.name, value, tt.multiple)
+			}
+		}
+	}
+}
+
+func TestGenerateNumberRespectsMultipleOf(t *testing.T) {
+	schema := &Schema{Type: "number", Minimum: floatPtr(0.5), Maximum: floatPtr(2), MultipleOf: floatPtr(0.25)}
+
+	for _, sample := range generateSamples(t, schema, 50) {
+		value, ok := sample.(float64)
+		if !ok {
+			t.Fatalf("expected float64, got %T", sample)
+		}
+		if value < 0.5 || value > 2 {
+			t.Errorf("%v not within [0.5, 2]", value)
+		}
+		if quotient := value / 0.25; quotient != math.Trunc(quotient) {
+			t.Errorf("%v is not a multiple of 0.25", value)
+		}
+	}
+}
+
+func TestGenerateStringRespectsLengthBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   *Schema
+		min, max int
+	}{
+		{"minLength above default maximum", &Schema{Type: "string", MinLength: intPtr(60)}, 60, 110},
+		{"maxLength only", &Schema{Type: "string", MaxLength: intPtr(4)}, 1, 4},
+		{"empty only", &Schema{Type: "string", MaxLength: intPtr(0)}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		for _, sample := range generateSamples(t, tt.schema, 20) {
+			str, ok := sample.(string)
+			if !ok {
+				t.Fatalf("%s: expected string, got %T", tt.name, sample)
+			}
+			if len(str) < tt.min || len(str) > tt.max {
+				t.Errorf("%s: length %d not within [%d, %d]", tt.name, len(str), tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestGenerateArrayRespectsItemBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   *Schema
+		min, max int
+	}{
+		{"minItems and maxItems", &Schema{Type: "array", Items: &Schema{Type: "integer"}, MinItems: intPtr(5), MaxItems: intPtr(7)}, 5, 7},
+		{"minItems only", &Schema{Type: "array", Items: &Schema{Type: "integer"}, MinItems: intPtr(4)}, 4, 6},
+		{"maxItems only", &Schema{Type: "array", Items: &Schema{Type: "integer"}, MaxItems: intPtr(1)}, 1, 1},
+	}
+
+	for _, tt := range tests {
+		for _, sample := range generateSamples(t, tt.schema, 20) {
+			items, ok := sample.([]interface{})
+			if !ok {
+				t.Fatalf("%s: expected array, got %T", tt.name, sample)
+			}
+			if len(items) < tt.min || len(items) > tt.max {
+				t.Errorf("%s: %d items not within [%d, %d]", tt.name, len(items), tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestGenerateEnumStaysWithinSet(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema *Schema
+	}{
+		{"string enum", &Schema{Type: "string", Enum: []interface{}{"draft", "published", "archived"}}},
+		{"integer enum", &Schema{Type: "integer", Enum: []interface{}{1, 2, 3}}},
+		{"enum with format", &Schema{Type: "string", Format: "email", Enum: []interface{}{"a@example.com"}}},
+	}
+
+	for _, tt := range tests {
+		for _, sample := range generateSamples(t, tt.schema, 30) {
+			if !enumContains(tt.schema.Enum, sample) {
+				t.Errorf("%s: %v is not one of %v", tt.name, sample, tt.schema.Enum)
+			}
+		}
+	}
+}
+
+func TestGenerateEnumIsDeterministicForSeed(t *testing.T) {
+	schema := &Schema{Type: "string", Enum: []interface{}{"a", "b", "c", "d", "e"}}
+
+	first := generateSamples(t, schema, 10)
+	second := generateSamples(t, schema, 10)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("seed %d: got %v then %v", i+1, first[i], second[i])
+		}
+	}
+}
+
+func TestGenerateNullable(t *testing.T) {
+	schema := &Schema{Type: "string", Nullable: true}
+
+	nulls := 0
+	for _, sample := range generateSamples(t, schema, 200) {
+		if sample == nil {
+			nulls++
+		}
+	}
+	if nulls == 0 || nulls == 200 {
+		t.Errorf("expected some but not all values to be null, got %d of 200", nulls)
+	}
+}
+
+func TestGenerateNullableProbability(t *testing.T) {
+	schema := &Schema{Type: "integer", Nullable: true}
+
+	for _, tt := range []struct {
+		probability float64
+		wantNull    bool
+	}{
+		{0, false},
+		{1, true},
+	} {
+		generator := NewDefaultDataGeneratorWithSeed(42)
+		generator.SetNullableProbability(tt.probability)
+		for i := 0; i < 20; i++ {
+			value, err := generator.Generate(schema, nil)
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			if (value == nil) != tt.wantNull {
+				t.Fatalf("probability %v: got %v", tt.probability, value)
+			}
+		}
+	}
+
+	generator := NewDefaultDataGenerator()
+	generator.SetNullableProbability(3)
+	if generator.NullableProbability() != 1 {
+		t.Errorf("expected probability to be clamped to 1, got %v", generator.NullableProbability())
+	}
+}
+
+func TestGenerateObjectKeepsNullProperties(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(42)
+	generator.SetNullableProbability(1)
+	schema := &Schema{
+		Type:     "object",
+		Required: []string{"deleted_at"},
+		Properties: map[string]*Schema{
+			"deleted_at": {Type: "string", Format: "date-time", Nullable: true},
+		},
+	}
+
+	value, err := generator.Generate(schema, nil)
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	object := value.(map[string]interface{})
+	if got, ok := object["deleted_at"]; !ok || got != nil {
+		t.Errorf("expected deleted_at to be present and null, got %v (present: %t)", got, ok)
+	}
+}
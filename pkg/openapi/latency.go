@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// MockLatencyExtension is the operation extension that sets how long the
+// mock takes to respond, as a duration such as "100ms", a number of
+// milliseconds, or a {min, max} range
+const MockLatencyExtension = "x-mock-latency"
+
+// LatencyRange is a simulated response delay drawn uniformly between Min and
+// Max. A fixed latency has Min equal to Max.
+type LatencyRange struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Sample returns a delay within the range
+func (l *LatencyRange) Sample() time.Duration {
+	if l == nil {
+		return 0
+	}
+	if l.Max <= l.Min {
+		return l.Min
+	}
+	return l.Min + time.Duration(rand.Int63n(int64(l.Max-l.Min)+1))
+}
+
+// MarshalJSON writes the range back in extension form, so exported
+// documents keep it
+func (l LatencyRange) MarshalJSON() ([]byte, error) {
+	if l.Min == l.Max {
+		return json.Marshal(l.Min.String())
+	}
+	return json.Marshal(map[string]string{"min": l.Min.String(), "max": l.Max.String()})
+}
+
+// parseMockLatency reads an x-mock-latency value. A missing value means no
+// latency. In a range, a missing min is 0 and a missing max equals min.
+func parseMockLatency(value interface{}) (*LatencyRange, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	var latency LatencyRange
+	if bounds, ok := value.(map[string]interface{}); ok {
+		for key := range bounds {
+			if key != "min" && key != "max" {
+				return nil, fmt.Errorf("%s: unknown field %q, expected min and max", MockLatencyExtension, key)
+			}
+		}
+		var err error
+		if latency.Min, err = parseLatencyDuration(bounds["min"]); err != nil {
+			return nil, fmt.Errorf("%s min: %w", MockLatencyExtension, err)
+		}
+		latency.Max = latency.Min
+		if _, set := bounds["max"]; set {
+			if latency.Max, err = parseLatencyDuration(bounds["max"]); err != nil {
+				return nil, fmt.Errorf("%s max: %w", MockLatencyExtension, err)
+			}
+		}
+		if latency.Max < latency.Min {
+			return nil, fmt.Errorf("%s: max %s is below min %s", MockLatencyExtension, latency.Max, latency.Min)
+		}
+	} else {
+		fixed, err := parseLatencyDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", MockLatencyExtension, err)
+		}
+		latency = LatencyRange{Min: fixed, Max: fixed}
+	}
+
+	if latency.Max == 0 {
+		return nil, nil
+	}
+	return &latency, nil
+}
+
+// parseLatencyDuration accepts a Go duration string or a number of milliseconds
+func parseLatencyDuration(value interface{}) (time.Duration, error) {
+	var duration time.Duration
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", v)
+		}
+		duration = parsed
+	case float64:
+		duration = time.Duration(v * float64(time.Millisecond))
+	default:
+		return 0, fmt.Errorf("expected a duration string or milliseconds, got %T", value)
+	}
+
+	if duration < 0 {
+		return 0, fmt.Errorf("duration %s is negative", duration)
+	}
+	return duration, nil
+}
+
+// convertMockLatencies reads the x-mock-latency extension of each operation
+// on a path into the converted operations
+func convertMockLatencies(path string, source *openapi3.PathItem, target *PathItem) error {
+	operations := []struct {
+		method string
+		source *openapi3.Operation
+		target *Operation
+	}{
+		{"GET", source.Get, target.GET},
+		{"POST", source.Post, target.POST},
+		{"PUT", source.Put, target.PUT},
+		{"DELETE", source.Delete, target.DELETE},
+		{"PATCH", source.Patch, target.PATCH},
+	}
+
+	for _, operation := range operations {
+		if operation.source == nil || operation.target == nil {
+			continue
+		}
+		latency, err := parseMockLatency(operation.source.Extensions[MockLatencyExtension])
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", operation.method, path, err)
+		}
+		operation.target.MockLatency = latency
+	}
+	return nil
+}
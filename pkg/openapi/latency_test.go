@@ -0,0 +1,123 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func latencySpec(extension string) string {
+	return `
+openapi: 3.0.3
+info:
+  title: Latency
+  version: 1.0.0
+paths:
+  /orders:
+    get:
+` + extension + `
+      responses:
+        "200":
+          description: Orders
+    post:
+      responses:
+        "201":
+          description: Created
+`
+}
+
+func TestParse_MockLatency(t *testing.T) {
+	tests := []struct {
+		name      string
+		extension string
+		want      *LatencyRange
+	}{
+		{"absent", "", nil},
+		{"duration", "      x-mock-latency: 100ms", &LatencyRange{Min: 100 * time.Millisecond, Max: 100 * time.Millisecond}},
+		{"milliseconds", "      x-mock-latency: 250", &LatencyRange{Min: 250 * time.Millisecond, Max: 250 * time.Millisecond}},
+		{"range", "      x-mock-latency: {min: 50ms, max: 1s}", &LatencyRange{Min: 50 * time.Millisecond, Max: time.Second}},
+		{"max only", "      x-mock-latency: {max: 200ms}", &LatencyRange{Min: 0, Max: 200 * time.Millisecond}},
+		{"zero", "      x-mock-latency: 0s", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := NewParser().Parse([]byte(latencySpec(tt.extension)))
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+
+			got := spec.Paths["/orders"].GET.MockLatency
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("MockLatency = %+v, want %+v", got, tt.want)
+			}
+			if spec.Paths["/orders"].POST.MockLatency != nil {
+				t.Errorf("operation without the extension got latency %+v", spec.Paths["/orders"].POST.MockLatency)
+			}
+		})
+	}
+}
+
+func TestParse_MockLatencyInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		extension string
+		wantErr   string
+	}{
+		{"bad duration", "      x-mock-latency: soon", `invalid duration "soon"`},
+		{"negative", "      x-mock-latency: -5ms", "negative"},
+		{"inverted range", "      x-mock-latency: {min: 2s, max: 1s}", "below min"},
+		{"unknown field", "      x-mock-latency: {mean: 1s}", `unknown field "mean"`},
+		{"wrong type", "      x-mock-latency: [1, 2]", "expected a duration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParser().Parse([]byte(latencySpec(tt.extension)))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), "GET /orders") || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want GET /orders and %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLatencyRange_Sample(t *testing.T) {
+	var none *LatencyRange
+	if got := none.Sample(); got != 0 {
+		t.Errorf("nil range sampled %s", got)
+	}
+
+	fixed := &LatencyRange{Min: 100 * time.Millisecond, Max: 100 * time.Millisecond}
+	if got := fixed.Sample(); got != 100*time.Millisecond {
+		t.Errorf("fixed range sampled %s", got)
+	}
+
+	span := &LatencyRange{Min: 10 * time.Millisecond, Max: 20 * time.Millisecond}
+	for i := 0; i < 100; i++ {
+		if got := span.Sample(); got < span.Min || got > span.Max {
+			t.Fatalf("sample %s outside [%s, %s]", got, span.Min, span.Max)
+		}
+	}
+}
+
+func TestSpecification_MarshalDocumentKeepsMockLatency(t *testing.T) {
+	spec, err := NewParser().Parse([]byte(latencySpec("      x-mock-latency: {min: 50ms, max: 1s}")))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	data, err := spec.MarshalDocument("yaml")
+	if err != nil {
+		t.Fatalf("MarshalDocument: %v", err)
+	}
+	parsed, err := NewParser().Parse(data)
+	if err != nil {
+		t.Fatalf("Parse(document): %v\n%s", err, data)
+	}
+	if got := parsed.Paths["/orders"].GET.MockLatency; got == nil || *got != *spec.Paths["/orders"].GET.MockLatency {
+		t.Errorf("MockLatency did not round-trip: %+v\n%s", got, data)
+	}
+}
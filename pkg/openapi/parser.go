@@ -1,6 +1,7 @@
 package openapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -59,6 +60,7 @@ func (p *OpenAPIParser) Parse(data []byte) (*Specification, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert spec: %w", err)
 	}
+	result.source = append([]byte(nil), data...)
 
 	// Extract endpoints and schemas
 	p.extractEndpoints(spec)
@@ -74,7 +76,7 @@ func (p *OpenAPIParser) Validate(spec *Specification) error {
 	}
 
 	// Use kin-openapi's validation
-	err := p.spec.Validate(nil)
+	err := p.spec.Validate(context.Background())
 	if err != nil {
 		return fmt.Errorf("OpenAPI validation failed: %w", err)
 	}
@@ -103,7 +105,7 @@ func (p *OpenAPIParser) convertToInternalSpec(spec *openapi3.T) (*Specification,
 		},
 		Paths:    make(map[string]PathItem),
 		Schemas:  make(map[string]*Schema),
-		Security: make([]SecurityRequirement, 0),
+		Security: convertSecurity(spec.Security),
 	}
 
 	// Convert paths
@@ -125,6 +127,9 @@ func (p *OpenAPIParser) convertToInternalSpec(spec *openapi3.T) (*Specification,
 		if pathItem.Patch != nil {
 			pi.PATCH = p.convertOperation(pathItem.Patch)
 		}
+		if err := convertMockLatencies(path, pathItem, &pi); err != nil {
+			return nil, err
+		}
 
 		result.Paths[path] = pi
 	}
@@ -149,6 +154,10 @@ func (p *OpenAPIParser) convertOperation(op *openapi3.Operation) *Operation {
 		Parameters:  make([]Parameter, 0),
 		Responses:   make(map[string]Response),
 	}
+	if op.Security != nil {
+		security := convertSecurity(*op.Security)
+		operation.Security = &security
+	}
 
 	// Convert parameters
 	for _, paramRef := range op.Parameters {
@@ -160,7 +169,7 @@ func (p *OpenAPIParser) convertOperation(op *openapi3.Operation) *Operation {
 				Required:    paramRef.Value.Required,
 			}
 			if paramRef.Value.Schema != nil {
-				param.Schema = p.convertSchema(paramRef.Value.Schema.Value)
+				param.Schema = p.convertSchemaRef(paramRef.Value.Schema)
 			}
 			operation.Parameters = append(operation.Parameters, param)
 		}
@@ -183,11 +192,24 @@ func (p *OpenAPIParser) convertOperation(op *openapi3.Operation) *Operation {
 			for mediaType, mediaTypeObj := range responseRef.Value.Content {
 				mto := MediaTypeObject{}
 				if mediaTypeObj.Schema != nil {
-					mto.Schema = p.convertSchema(mediaTypeObj.Schema.Value)
+					mto.Schema = p.convertSchemaRef(mediaTypeObj.Schema)
 				}
 				if mediaTypeObj.Example != nil {
 					mto.Example = mediaTypeObj.Example
 				}
+				for name, exampleRef := range mediaTypeObj.Examples {
+					if exampleRef == nil || exampleRef.Value == nil {
+						continue
+					}
+					if mto.Examples == nil {
+						mto.Examples = make(map[string]*Example)
+					}
+					mto.Examples[name] = &Example{
+						Summary:     exampleRef.Value.Summary,
+						Description: exampleRef.Value.Description,
+						Value:       exampleRef.Value.Value,
+					}
+				}
 				response.Content[mediaType] = mto
 			}
 
@@ -215,6 +237,7 @@ func (p *OpenAPIParser) convertSchema(schema *openapi3.Schema) *Schema {
 	if schema.Enum != nil {
 		result.Enum = schema.Enum
 	}
+	result.Nullable = schema.Nullable
 
 	// Convert default and example
 	result.Default = schema.Default
@@ -223,6 +246,7 @@ func (p *OpenAPIParser) convertSchema(schema *openapi3.Schema) *Schema {
 	// Convert numeric constraints
 	result.Minimum = schema.Min
 	result.Maximum = schema.Max
+	result.MultipleOf = schema.MultipleOf
 
 	// Convert string constraints
 	if schema.MinLength > 0 {
@@ -248,23 +272,55 @@ func (p *OpenAPIParser) convertSchema(schema *openapi3.Schema) *Schema {
 	if schema.Properties != nil {
 		result.Properties = make(map[string]*Schema)
 		for name, propRef := range schema.Properties {
-			if propRef.Value != nil {
-				result.Properties[name] = p.convertSchema(propRef.Value)
+			if converted := p.convertSchemaRef(propRef); converted != nil {
+				result.Properties[name] = converted
 			}
 		}
 	}
 
 	// Convert items (for array types)
-	if schema.Items != nil && schema.Items.Value != nil {
-		result.Items = p.convertSchema(schema.Items.Value)
+	if schema.Items != nil {
+		result.Items = p.convertSchemaRef(schema.Items)
 	}
 
+	// Convert combinators
+	result.AllOf = p.convertSchemaRefs(schema.AllOf)
+	result.OneOf = p.convertSchemaRefs(schema.OneOf)
+	result.AnyOf = p.convertSchemaRefs(schema.AnyOf)
+
 	// Convert required fields
 	result.Required = schema.Required
 
 	return result
 }
 
+// convertSchemaRef converts a schema reference. References to component
+// schemas are kept as $ref so recursive schemas don't expand forever; the
+// generator resolves them against Specification.Schemas.
+func (p *OpenAPIParser) convertSchemaRef(ref *openapi3.SchemaRef) *Schema {
+	if ref == nil {
+		return nil
+	}
+	if strings.HasPrefix(ref.Ref, ComponentSchemaRefPrefix) {
+		return &Schema{Ref: ref.Ref}
+	}
+	return p.convertSchema(ref.Value)
+}
+
+// convertSchemaRefs converts a list of schema references, e.g. allOf members
+func (p *OpenAPIParser) convertSchemaRefs(refs openapi3.SchemaRefs) []*Schema {
+	if len(refs) == 0 {
+		return nil
+	}
+	result := make([]*Schema, 0, len(refs))
+	for _, ref := range refs {
+		if converted := p.convertSchemaRef(ref); converted != nil {
+			result = append(result, converted)
+		}
+	}
+	return result
+}
+
 // extractEndpoints extracts all endpoints from the specification
 func (p *OpenAPIParser) extractEndpoints(spec *openapi3.T) {
 	p.endpoints = make([]Endpoint, 0)
@@ -299,7 +355,7 @@ func (p *OpenAPIParser) extractEndpoints(spec *openapi3.T) {
 							Required:    paramRef.Value.Required,
 						}
 						if paramRef.Value.Schema != nil {
-							param.Schema = p.convertSchema(paramRef.Value.Schema.Value)
+							param.Schema = p.convertSchemaRef(paramRef.Value.Schema)
 						}
 						endpoint.Parameters = append(endpoint.Parameters, param)
 					}
@@ -320,7 +376,7 @@ func (p *OpenAPIParser) extractEndpoints(spec *openapi3.T) {
 						for mediaType, mediaTypeObj := range responseRef.Value.Content {
 							mto := MediaTypeObject{}
 							if mediaTypeObj.Schema != nil {
-								mto.Schema = p.convertSchema(mediaTypeObj.Schema.Value)
+								mto.Schema = p.convertSchemaRef(mediaTypeObj.Schema)
 							}
 							response.Content[mediaType] = mto
 						}
@@ -0,0 +1,152 @@
+package openapi
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxPatternAttempts bounds retries when a generated string misses the length constraints
+	maxPatternAttempts = 10
+	// maxUnboundedRepeat caps how often *, + and open-ended {n,} repeat
+	maxUnboundedRepeat = 5
+)
+
+// generateFromPattern generates a string matching pattern using the seeded faker.
+// It returns false when the pattern cannot be parsed or no matching string was produced.
+func (g *DefaultDataGenerator) generateFromPattern(pattern string, minLength, maxLength int) (string, bool) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", false
+	}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+
+	var fallback string
+	found := false
+	for attempt := 0; attempt < maxPatternAttempts; attempt++ {
+		var sb strings.Builder
+		if !g.writePattern(&sb, parsed) {
+			return "", false
+		}
+
+		candidate := sb.String()
+		if !re.MatchString(candidate) {
+			continue
+		}
+
+		length := utf8.RuneCountInString(candidate)
+		if length >= minLength && length <= maxLength {
+			return candidate, true
+		}
+		if !found {
+			fallback, found = candidate, true
+		}
+	}
+
+	// Matching the pattern matters more than the length hint
+	return fallback, found
+}
+
+// writePattern appends a random string matching the parsed expression
+func (g *DefaultDataGenerator) writePattern(sb *strings.Builder, re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine,
+		syntax.OpBeginText, syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			sb.WriteRune(r)
+		}
+		return true
+	case syntax.OpCharClass:
+		r, ok := g.pickFromClass(re.Rune)
+		if ok {
+			sb.WriteRune(r)
+		}
+		return ok
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteString(g.faker.LetterN(1))
+		return true
+	case syntax.OpCapture:
+		return g.writePattern(sb, re.Sub[0])
+	case syntax.OpStar:
+		return g.writeRepeat(sb, re.Sub[0], 0, maxUnboundedRepeat)
+	case syntax.OpPlus:
+		return g.writeRepeat(sb, re.Sub[0], 1, maxUnboundedRepeat)
+	case syntax.OpQuest:
+		return g.writeRepeat(sb, re.Sub[0], 0, 1)
+	case syntax.OpRepeat:
+		max := re.Max
+		if max < 0 {
+			max = re.Min + maxUnboundedRepeat
+		}
+		return g.writeRepeat(sb, re.Sub[0], re.Min, max)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !g.writePattern(sb, sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlternate:
+		return g.writePattern(sb, re.Sub[g.faker.IntRange(0, len(re.Sub)-1)])
+	default:
+		return false
+	}
+}
+
+// writeRepeat appends between min and max matches of sub
+func (g *DefaultDataGenerator) writeRepeat(sb *strings.Builder, sub *syntax.Regexp, min, max int) bool {
+	count := g.faker.IntRange(min, max)
+	for i := 0; i < count; i++ {
+		if !g.writePattern(sb, sub) {
+			return false
+		}
+	}
+	return true
+}
+
+// pickFromClass picks a rune from a character class given as [lo, hi] pairs,
+// preferring printable ASCII so negated classes don't yield exotic code points
+func (g *DefaultDataGenerator) pickFromClass(ranges []rune) (rune, bool) {
+	if len(ranges) == 0 {
+		return 0, false
+	}
+
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < ' ' {
+			lo = ' '
+		}
+		if hi > '~' {
+			hi = '~'
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+
+	pair := g.faker.IntRange(0, len(ranges)/2-1) * 2
+	return rune(g.faker.IntRange(int(ranges[pair]), int(ranges[pair+1]))), true
+}
+
+// matchesPattern reports whether value is a string matching pattern
+func matchesPattern(pattern string, value interface{}) bool {
+	str, ok := value.(string)
+	if !ok {
+		return false
+	}
+	matched, err := regexp.MatchString(pattern, str)
+	return err == nil && matched
+}
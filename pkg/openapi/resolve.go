@@ -0,0 +1,164 @@
+package openapi
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ComponentSchemaRefPrefix prefixes local references to component schemas
+const ComponentSchemaRefPrefix = "#/components/schemas/"
+
+// maxRefDepth caps how many references are followed along a single path
+const maxRefDepth = 32
+
+// resolveRef looks up the component schema a $ref points to
+func resolveRef(ref string, ctx *GenerationContext) (*Schema, error) {
+	name, ok := strings.CutPrefix(ref, ComponentSchemaRefPrefix)
+	if !ok {
+		return nil, fmt.Errorf("unsupported schema reference %q: only %s* is supported", ref, ComponentSchemaRefPrefix)
+	}
+
+	target, exists := ctx.Schemas[name]
+	if !exists || target == nil {
+		return nil, fmt.Errorf("unresolvable schema reference %q", ref)
+	}
+
+	return target, nil
+}
+
+// generateRef generates data for a referenced schema. A reference already
+// being expanded further up the path is circular and yields nil.
+func (g *DefaultDataGenerator) generateRef(schema *Schema, ctx *GenerationContext) (interface{}, error) {
+	target, err := resolveRef(schema.Ref, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.IsCircularReference(schema.Ref) || ctx.RefDepth >= maxRefDepth {
+		return nil, nil
+	}
+
+	ctx.MarkVisited(schema.Ref)
+	defer ctx.UnmarkVisited(schema.Ref)
+
+	newCtx := *ctx
+	newCtx.RefDepth++
+
+	return g.Generate(target, &newCtx)
+}
+
+// mergeAllOf flattens a schema's allOf members, following references, into a
+// single schema. Fields set on the schema itself take precedence over members.
+func (g *DefaultDataGenerator) mergeAllOf(schema *Schema, ctx *GenerationContext, seen map[string]bool) (*Schema, error) {
+	merged := *schema
+	merged.AllOf = nil
+	merged.Properties = make(map[string]*Schema, len(schema.Properties))
+	for name, prop := range schema.Properties {
+		merged.Properties[name] = prop
+	}
+	merged.Required = append([]string(nil), schema.Required...)
+
+	for i, member := range schema.AllOf {
+		if member == nil {
+			continue
+		}
+
+		ref := member.Ref
+		if ref != "" {
+			if seen[ref] {
+				return nil, fmt.Errorf("circular allOf reference %q", ref)
+			}
+			target, err := resolveRef(ref, ctx)
+			if err != nil {
+				return nil, fmt.Errorf("allOf[%d]: %w", i, err)
+			}
+			seen[ref] = true
+			member = target
+		}
+
+		if len(member.AllOf) > 0 {
+			flattened, err := g.mergeAllOf(member, ctx, seen)
+			if err != nil {
+				return nil, err
+			}
+			member = flattened
+		}
+
+		mergeSchemaInto(&merged, member)
+		delete(seen, ref)
+	}
+
+	if len(merged.Properties) == 0 {
+		merged.Properties = nil
+	}
+
+	return &merged, nil
+}
+
+// mergeSchemaInto copies fields from src that dst does not already define
+func mergeSchemaInto(dst, src *Schema) {
+	if dst.Type == "" {
+		dst.Type = src.Type
+	}
+	if dst.Format == "" {
+		dst.Format = src.Format
+	}
+	if dst.Pattern == "" {
+		dst.Pattern = src.Pattern
+	}
+	if dst.Example == nil {
+		dst.Example = src.Example
+	}
+	if dst.Enum == nil {
+		dst.Enum = src.Enum
+	}
+	if dst.Items == nil {
+		dst.Items = src.Items
+	}
+	if dst.Minimum == nil {
+		dst.Minimum = src.Minimum
+	}
+	if dst.Maximum == nil {
+		dst.Maximum = src.Maximum
+	}
+	if dst.MinLength == nil {
+		dst.MinLength = src.MinLength
+	}
+	if dst.MaxLength == nil {
+		dst.MaxLength = src.MaxLength
+	}
+	if dst.MinItems == nil {
+		dst.MinItems = src.MinItems
+	}
+	if dst.MaxItems == nil {
+		dst.MaxItems = src.MaxItems
+	}
+	if dst.OneOf == nil {
+		dst.OneOf = src.OneOf
+	}
+	if dst.AnyOf == nil {
+		dst.AnyOf = src.AnyOf
+	}
+
+	for name, prop := range src.Properties {
+		if _, exists := dst.Properties[name]; !exists {
+			dst.Properties[name] = prop
+		}
+	}
+
+	for _, field := range src.Required {
+		if !slices.Contains(dst.Required, field) {
+			dst.Required = append(dst.Required, field)
+		}
+	}
+}
+
+// generateBranch generates data from one oneOf/anyOf branch chosen with the seeded faker
+func (g *DefaultDataGenerator) generateBranch(branches []*Schema, ctx *GenerationContext) (interface{}, error) {
+	branch := branches[g.faker.IntRange(0, len(branches)-1)]
+	if branch == nil {
+		return nil, nil
+	}
+	return g.Generate(branch, ctx)
+}
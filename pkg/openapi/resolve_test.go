@@ -0,0 +1,274 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func componentSchemas() map[string]*Schema {
+	return map[string]*Schema{
+		"Pet": {
+			Type:     "object",
+			Required: []string{"id", "name"},
+			Properties: map[string]*Schema{
+				"id":    {Type: "integer"},
+				"name":  {Type: "string"},
+				"owner": {Ref: "#/components/schemas/Owner"},
+			},
+		},
+		"Owner": {
+			Type:     "object",
+			Required: []string{"email"},
+			Properties: map[string]*Schema{
+				"email": {Type: "string", Format: FormatEmail},
+			},
+		},
+		"Node": {
+			Type:     "object",
+			Required: []string{"value", "children"},
+			Properties: map[string]*Schema{
+				"value":    {Type: "string"},
+				"children": {Type: "array", Items: &Schema{Ref: "#/components/schemas/Node"}},
+			},
+		},
+		"Cat": {
+			Type:     "object",
+			Required: []string{"kind", "lives"},
+			Properties: map[string]*Schema{
+				"kind":  {Type: "string", Enum: []interface{}{"cat"}},
+				"lives": {Type: "integer"},
+			},
+		},
+		"Dog": {
+			Type:     "object",
+			Required: []string{"kind", "breed"},
+			Properties: map[string]*Schema{
+				"kind":  {Type: "string", Enum: []interface{}{"dog"}},
+				"breed": {Type: "string"},
+			},
+		},
+	}
+}
+
+func refContext() *GenerationContext {
+	ctx := NewGenerationContext()
+	ctx.Schemas = componentSchemas()
+	return ctx
+}
+
+func TestGenerateResolvesRef(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(42)
+
+	result, err := generator.Generate(&Schema{Ref: "#/components/schemas/Pet"}, refContext())
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	pet, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected object, got %T", result)
+	}
+	if _, ok := pet["id"].(int); !ok {
+		t.Errorf("expected integer id, got %T", pet["id"])
+	}
+	if _, ok := pet["name"].(string); !ok {
+		t.Errorf("expected string name, got %T", pet["name"])
+	}
+	if owner, exists := pet["owner"]; exists {
+		email := owner.(map[string]interface{})["email"]
+		if !strings.Contains(email.(string), "@") {
+			t.Errorf("expected nested referenced email, got %v", email)
+		}
+	}
+}
+
+func TestGenerateCircularRefTerminates(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(42)
+
+	ctx := refContext()
+	ctx.MaxDepth = 50
+
+	result, err := generator.Generate(&Schema{Ref: "#/components/schemas/Node"}, ctx)
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	node := result.(map[string]interface{})
+	if _, ok := node["value"].(string); !ok {
+		t.Errorf("expected string value, got %T", node["value"])
+	}
+	// The recursive reference is not expanded again
+	for _, child := range node["children"].([]interface{}) {
+		if child != nil {
+			t.Errorf("expected circular child to be cut off, got %v", child)
+		}
+	}
+	if len(ctx.Visited) != 0 {
+		t.Errorf("expected visited refs to be cleared after generation, got %v", ctx.Visited)
+	}
+}
+
+func TestGenerateUnresolvableRef(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(42)
+
+	schema := &Schema{
+		Type:     "object",
+		Required: []string{"pet"},
+		Properties: map[string]*Schema{
+			"pet": {Ref: "#/components/schemas/Missing"},
+		},
+	}
+
+	_, err := generator.Generate(schema, refContext())
+	if err == nil {
+		t.Fatal("expected error for unresolvable reference")
+	}
+	if !strings.Contains(err.Error(), `unresolvable schema reference "#/components/schemas/Missing"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_, err = generator.Generate(&Schema{Ref: "other.yaml#/Pet"}, refContext())
+	if err == nil || !strings.Contains(err.Error(), "unsupported schema reference") {
+		t.Errorf("expected unsupported reference error, got %v", err)
+	}
+}
+
+func TestGenerateAllOfMergesMembers(t *testing.T) {
+	generator := NewDefaultDataGeneratorWithSeed(42)
+
+	schema := &Schema{
+		AllOf: []*Schema{
+			{Ref: "#/components/schemas/Pet"},
+			{
+				Type:     "object",
+				Required: []string{"tag"},
+				Properties: map[string]*Schema{
+					"tag": {Type: "string", Enum: []interface{}{"indoor"}},
+				},
+			},
+		},
+	}
+
+	result, err := generator.Generate(schema, refContext())
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	obj := result.(map[string]interface{})
+	for _, field := range []string{"id", "name", "tag"} {
+		if _, exists := obj[field]; !exists {
+			t.Errorf("expected merged required field %q, got %v", field, obj)
+		}
+	}
+	if obj["tag"] != "indoor" {
+		t.Errorf("expected tag 'indoor', got %v", obj["tag"])
+	}
+}
+
+func TestGenerateOneOfPicksSingleBranchDeterministically(t *testing.T) {
+	schema := &Schema{
+		OneOf: []*Schema{
+			{Ref: "#/components/schemas/Cat"},
+			{Ref: "#/components/schemas/Dog"},
+		},
+	}
+
+	kinds := make(map[interface{}]bool)
+	for seed := int64(1); seed <= 20; seed++ {
+		first, err := NewDefaultDataGeneratorWithSeed(seed).Generate(schema, refContext())
+		if err != nil {
+			t.Fatalf("Generate() error: %v", err)
+		}
+		second, err := NewDefaultDataGeneratorWithSeed(seed).Generate(schema, refContext())
+		if err != nil {
+			t.Fatalf("Generate() error: %v", err)
+		}
+		if !reflect.DeepEqual(first, second) {
+			t.Errorf("seed %d: expected identical output, got %v and %v", seed, first, second)
+		}
+
+		obj := first.(map[string]interface{})
+		switch obj["kind"] {
+		case "cat":
+			if _, exists := obj["breed"]; exists {
+				t.Errorf("cat branch mixed with dog fields: %v", obj)
+			}
+		case "dog":
+			if _, exists := obj["lives"]; exists {
+				t.Errorf("dog branch mixed with cat fields: %v", obj)
+			}
+		default:
+			t.Fatalf("unexpected kind: %v", obj["kind"])
+		}
+		kinds[obj["kind"]] = true
+	}
+
+	if len(kinds) != 2 {
+		t.Errorf("expected both branches across seeds, got %v", kinds)
+	}
+}
+
+func TestParserKeepsRefsAndCombinators(t *testing.T) {
+	specYAML := `
+openapi: 3.0.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+    Cat:
+      type: object
+      required: [kind, lives]
+      properties:
+        kind: {type: string, enum: [cat]}
+        lives: {type: integer}
+        friend: {$ref: '#/components/schemas/Pet'}
+    Dog:
+      type: object
+      required: [kind]
+      properties:
+        kind: {type: string, enum: [dog]}
+`
+	spec, err := NewParser().Parse([]byte(specYAML))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	schema := spec.Paths["/pets"].GET.Responses["200"].Content["application/json"].Schema
+	if schema.Items == nil || schema.Items.Ref != "#/components/schemas/Pet" {
+		t.Fatalf("expected items to keep $ref, got %+v", schema.Items)
+	}
+	if len(spec.Schemas["Pet"].OneOf) != 2 {
+		t.Fatalf("expected Pet to have 2 oneOf branches, got %+v", spec.Schemas["Pet"])
+	}
+
+	ctx := NewGenerationContext()
+	ctx.Schemas = spec.Schemas
+	result, err := NewDefaultDataGeneratorWithSeed(7).Generate(schema, ctx)
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	for _, item := range result.([]interface{}) {
+		kind := item.(map[string]interface{})["kind"]
+		if kind != "cat" && kind != "dog" {
+			t.Errorf("unexpected item kind %v", kind)
+		}
+	}
+}
@@ -9,6 +9,9 @@ type Specification struct {
 	Paths     map[string]PathItem   `json:"paths"`
 	Schemas   map[string]*Schema    `json:"schemas"`
 	Security  []SecurityRequirement `json:"security"`
+
+	// source holds the document the specification was parsed from
+	source []byte
 }
 
 // InfoObject represents the info section of OpenAPI spec
@@ -35,6 +38,10 @@ type Operation struct {
 	Parameters  []Parameter         `json:"parameters,omitempty"`
 	RequestBody *RequestBody        `json:"requestBody,omitempty"`
 	Responses   map[string]Response `json:"responses"`
+	MockLatency *LatencyRange       `json:"x-mock-latency,omitempty"` // Simulated response delay
+	// Security overrides the specification's global requirements; nil
+	// inherits them and an empty list makes the operation public
+	Security *[]SecurityRequirement `json:"security,omitempty"`
 }
 
 // Parameter represents a parameter in an operation
@@ -62,8 +69,16 @@ type Response struct {
 
 // MediaTypeObject represents a media type
 type MediaTypeObject struct {
-	Schema  *Schema     `json:"schema,omitempty"`
-	Example interface{} `json:"example,omitempty"`
+	Schema   *Schema             `json:"schema,omitempty"`
+	Example  interface{}         `json:"example,omitempty"`
+	Examples map[string]*Example `json:"examples,omitempty"`
+}
+
+// Example represents a named media type example
+type Example struct {
+	Summary     string      `json:"summary,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Value       interface{} `json:"value,omitempty"`
 }
 
 // Header represents a header
@@ -78,6 +93,7 @@ type Schema struct {
 	Format               string            `json:"format,omitempty"`
 	Description          string            `json:"description,omitempty"`
 	Enum                 []interface{}     `json:"enum,omitempty"`
+	Nullable             bool              `json:"nullable,omitempty"`
 	Default              interface{}       `json:"default,omitempty"`
 	Example              interface{}       `json:"example,omitempty"`
 	Properties           map[string]*Schema `json:"properties,omitempty"`
@@ -87,10 +103,15 @@ type Schema struct {
 	Pattern              string            `json:"pattern,omitempty"`
 	Minimum              *float64          `json:"minimum,omitempty"`
 	Maximum              *float64          `json:"maximum,omitempty"`
+	MultipleOf           *float64          `json:"multipleOf,omitempty"`
 	MinItems             *int              `json:"minItems,omitempty"`
 	MaxItems             *int              `json:"maxItems,omitempty"`
 	MinLength            *int              `json:"minLength,omitempty"`
 	MaxLength            *int              `json:"maxLength,omitempty"`
+	Ref                  string            `json:"$ref,omitempty"`
+	AllOf                []*Schema         `json:"allOf,omitempty"`
+	OneOf                []*Schema         `json:"oneOf,omitempty"`
+	AnyOf                []*Schema         `json:"anyOf,omitempty"`
 }
 
 // SecurityRequirement represents a security requirement
@@ -117,4 +138,6 @@ type GenerationContext struct {
 	Locale       string
 	Seed         int64
 	Timestamp    time.Time
+	Schemas      map[string]*Schema // Component schemas used to resolve $ref
+	RefDepth     int                // Number of references followed to reach the current schema
 }
\ No newline at end of file
@@ -0,0 +1,42 @@
+package openapi
+
+import "github.com/getkin/kin-openapi/openapi3"
+
+// convertSecurity copies kin-openapi security requirements, keeping an
+// empty list distinct from an absent one
+func convertSecurity(source openapi3.SecurityRequirements) []SecurityRequirement {
+	requirements := make([]SecurityRequirement, 0, len(source))
+	for _, requirement := range source {
+		converted := make(SecurityRequirement, len(requirement))
+		for scheme, scopes := range requirement {
+			converted[scheme] = append([]string{}, scopes...)
+		}
+		requirements = append(requirements, converted)
+	}
+	return requirements
+}
+
+// EffectiveSecurity returns the security requirements that apply to an
+// operation: its own when it declares any, otherwise the global ones
+func (s *Specification) EffectiveSecurity(operation *Operation) []SecurityRequirement {
+	if operation != nil && operation.Security != nil {
+		return *operation.Security
+	}
+	return s.Security
+}
+
+// RequiresAuth reports whether an operation needs credentials. Requirements
+// are alternatives, so an empty list or an empty requirement such as
+// `security: [{}]` lets anonymous callers through.
+func (s *Specification) RequiresAuth(operation *Operation) bool {
+	requirements := s.EffectiveSecurity(operation)
+	if len(requirements) == 0 {
+		return false
+	}
+	for _, requirement := range requirements {
+		if len(requirement) == 0 {
+			return false
+		}
+	}
+	return true
+}
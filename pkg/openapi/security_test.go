@@ -0,0 +1,86 @@
+package openapi
+
+import "testing"
+
+const securityTestSpec = `
+openapi: 3.0.3
+info:
+  title: Security
+  version: 1.0.0
+security:
+  - bearer: []
+components:
+  securitySchemes:
+    bearer:
+      type: http
+      scheme: bearer
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-API-Key
+paths:
+  /health:
+    get:
+      security: []
+      responses:
+        "200":
+          description: OK
+  /feed:
+    get:
+      security:
+        - {}
+        - bearer: []
+      responses:
+        "200":
+          description: OK
+  /reports:
+    get:
+      security:
+        - apiKey: []
+      responses:
+        "200":
+          description: OK
+  /orders:
+    get:
+      responses:
+        "200":
+          description: OK
+`
+
+func TestSpecification_RequiresAuth(t *testing.T) {
+	spec, err := NewParser().Parse([]byte(securityTestSpec))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/health", false},
+		{"/feed", false},
+		{"/reports", true},
+		{"/orders", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			operation := spec.Paths[tt.path].GET
+			if got := spec.RequiresAuth(operation); got != tt.want {
+				t.Errorf("RequiresAuth(%s) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	reports := spec.EffectiveSecurity(spec.Paths["/reports"].GET)
+	if len(reports) != 1 || reports[0]["apiKey"] == nil {
+		t.Errorf("EffectiveSecurity(/reports) = %v, want the operation's own apiKey requirement", reports)
+	}
+}
+
+func TestSpecification_RequiresAuthWithoutGlobalSecurity(t *testing.T) {
+	spec := &Specification{Paths: map[string]PathItem{"/orders": {GET: &Operation{}}}}
+	if spec.RequiresAuth(spec.Paths["/orders"].GET) {
+		t.Error("RequiresAuth() = true for a spec without security requirements")
+	}
+}
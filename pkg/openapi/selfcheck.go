@@ -0,0 +1,155 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// responseBodyField names the generated response in self-check errors
+const responseBodyField = "response"
+
+// SelfCheckFailure reports an operation whose generated response could not be
+// produced or does not satisfy the operation's own response schema
+type SelfCheckFailure struct {
+	Method     string                   `json:"method"`
+	Path       string                   `json:"path"`
+	StatusCode string                   `json:"status_code"`
+	MediaType  string                   `json:"media_type"`
+	Err        error                    `json:"-"`
+	Errors     []RequestValidationError `json:"errors,omitempty"`
+}
+
+func (f *SelfCheckFailure) Error() string {
+	operation := fmt.Sprintf("%s %s -> %s (%s)", f.Method, f.Path, f.StatusCode, f.MediaType)
+	if f.Err != nil {
+		return fmt.Sprintf("%s: generation failed: %v", operation, f.Err)
+	}
+
+	messages := make([]string, 0, len(f.Errors))
+	for i := range f.Errors {
+		messages = append(messages, f.Errors[i].Error())
+	}
+	return fmt.Sprintf("%s: %s", operation, strings.Join(messages, "; "))
+}
+
+// SelfCheck generates a sample response for every JSON response of every
+// operation and validates it against the response schema it was generated
+// from. Failures point at generator bugs or schemas it cannot satisfy.
+func SelfCheck(spec *Specification, generator DataGenerator) []SelfCheckFailure {
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var failures []SelfCheckFailure
+	for _, path := range paths {
+		pathItem := spec.Paths[path]
+		operations := []struct {
+			method    string
+			operation *Operation
+		}{
+			{"GET", pathItem.GET},
+			{"POST", pathItem.POST},
+			{"PUT", pathItem.PUT},
+			{"PATCH", pathItem.PATCH},
+			{"DELETE", pathItem.DELETE},
+		}
+
+		for _, op := range operations {
+			if op.operation == nil {
+				continue
+			}
+			failures = append(failures, selfCheckOperation(spec, generator, op.method, path, op.operation)...)
+		}
+	}
+	return failures
+}
+
+// selfCheckOperation checks every JSON response an operation defines
+func selfCheckOperation(spec *Specification, generator DataGenerator, method, path string, operation *Operation) []SelfCheckFailure {
+	codes := make([]string, 0, len(operation.Responses))
+	for code := range operation.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var failures []SelfCheckFailure
+	for _, code := range codes {
+		response := operation.Responses[code]
+
+		mediaTypes := make([]string, 0, len(response.Content))
+		for mediaType := range response.Content {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+		sort.Strings(mediaTypes)
+
+		for _, mediaType := range mediaTypes {
+			media := response.Content[mediaType]
+			if media.Schema == nil || !isJSONMediaType(mediaType) {
+				continue
+			}
+
+			errors, err := selfCheckMedia(spec, generator, &media)
+			if err != nil || len(errors) > 0 {
+				failures = append(failures, SelfCheckFailure{
+					Method:     method,
+					Path:       path,
+					StatusCode: code,
+					MediaType:  mediaType,
+					Err:        err,
+					Errors:     errors,
+				})
+			}
+		}
+	}
+	return failures
+}
+
+// selfCheckMedia generates a response for media the way the mock handler
+// does and validates its JSON encoding against the media schema
+func selfCheckMedia(spec *Specification, generator DataGenerator, media *MediaTypeObject) ([]RequestValidationError, error) {
+	genCtx := &GenerationContext{
+		MaxDepth:   5,
+		Visited:    make(map[string]bool),
+		ArraySizes: make(map[string]int),
+		Locale:     "en",
+		Timestamp:  time.Now(),
+		Schemas:    spec.Schemas,
+	}
+
+	data, err := generator.GenerateForMediaType(media, "", genCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("generated data is not JSON-serializable: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	v := &bodyValidator{schemas: spec.Schemas}
+	v.validate(media.Schema, value, responseBodyField, 0)
+	return v.errors, nil
+}
+
+// isJSONMediaType reports whether a response media type carries JSON
+func isJSONMediaType(mediaType string) bool {
+	parsed, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return false
+	}
+	return parsed == "application/json" || strings.HasSuffix(parsed, "+json")
+}
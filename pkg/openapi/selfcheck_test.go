@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func selfCheckSpec(quantity *Schema) *Specification {
+	minLength := 3
+	return &Specification{
+		Version: "3.0.0",
+		Info:    InfoObject{Title: "Self-check API", Version: "1.0.0"},
+		Paths: map[string]PathItem{
+			"/orders": {
+				GET: &Operation{
+					Responses: map[string]Response{
+						"200": {
+							Content: map[string]MediaTypeObject{
+								"application/json": {Schema: &Schema{
+									Type:  "array",
+									Items: &Schema{Ref: "#/components/schemas/Order"},
+								}},
+								"text/plain": {Schema: &Schema{Type: "integer", Minimum: floatPtr(10), Maximum: floatPtr(5)}},
+							},
+						},
+					},
+				},
+			},
+		},
+		Schemas: map[string]*Schema{
+			"Order": {
+				Type:     "object",
+				Required: []string{"id", "status", "quantity"},
+				Properties: map[string]*Schema{
+					"id":       {Type: "string", MinLength: &minLength},
+					"status":   {Type: "string", Enum: []interface{}{"open", "closed"}},
+					"quantity": quantity,
+				},
+			},
+		},
+	}
+}
+
+func TestSelfCheck_SatisfiableSchema(t *testing.T) {
+	spec := selfCheckSpec(&Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(10)})
+
+	if failures := SelfCheck(spec, NewDefaultDataGeneratorWithSeed(1)); len(failures) != 0 {
+		t.Errorf("expected no failures, got %v", failures)
+	}
+}
+
+func TestSelfCheck_ReportsUnsatisfiableSchema(t *testing.T) {
+	// The generator clamps maximum up to minimum, so every quantity is 10
+	spec := selfCheckSpec(&Schema{Type: "integer", Minimum: floatPtr(10), Maximum: floatPtr(5)})
+
+	failures := SelfCheck(spec, NewDefaultDataGeneratorWithSeed(1))
+	if len(failures) != 1 {
+		t.Fatalf("expected 1 failure, got %d: %v", len(failures), failures)
+	}
+
+	failure := failures[0]
+	if failure.Method != "GET" || failure.Path != "/orders" || failure.StatusCode != "200" || failure.MediaType != "application/json" {
+		t.Errorf("unexpected operation in failure: %+v", failure)
+	}
+	if len(failure.Errors) == 0 {
+		t.Fatal("expected validation errors")
+	}
+	for _, err := range failure.Errors {
+		if !strings.HasSuffix(err.Field, ".quantity") || err.Rule != "maximum" {
+			t.Errorf("unexpected validation error: %+v", err)
+		}
+	}
+	if !strings.Contains(failure.Error(), "GET /orders -> 200 (application/json)") {
+		t.Errorf("unexpected failure message: %s", failure.Error())
+	}
+}
@@ -0,0 +1,315 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// RequestValidationError represents a request body value that violates the
+// operation's schema, with the path of the offending field
+type RequestValidationError struct {
+	Field   string      `json:"field"`
+	Value   interface{} `json:"value,omitempty"`
+	Message string      `json:"message"`
+	Rule    string      `json:"rule"`
+}
+
+func (e *RequestValidationError) Error() string {
+	return fmt.Sprintf("validation failed for field '%s': %s (value: %v)", e.Field, e.Message, e.Value)
+}
+
+// requestBodyField names the body itself in validation errors
+const requestBodyField = "body"
+
+// ValidateRequestBody validates a raw request body against an operation's
+// request body definition. Only JSON media types are checked; an empty body
+// is rejected when the request body is required and accepted otherwise.
+func ValidateRequestBody(requestBody *RequestBody, contentType string, body []byte, schemas map[string]*Schema) []RequestValidationError {
+	if requestBody == nil {
+		return nil
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if requestBody.Required {
+			return []RequestValidationError{{
+				Field:   requestBodyField,
+				Message: "request body is required",
+				Rule:    "required",
+			}}
+		}
+		return nil
+	}
+
+	schema := jsonRequestSchema(requestBody, contentType)
+	if schema == nil {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []RequestValidationError{{
+			Field:   requestBodyField,
+			Message: fmt.Sprintf("request body is not valid JSON: %v", err),
+			Rule:    "json",
+		}}
+	}
+
+	v := &bodyValidator{schemas: schemas}
+	v.validate(schema, value, requestBodyField, 0)
+	return v.errors
+}
+
+// jsonRequestSchema returns the schema for the request's JSON content type,
+// falling back to application/json when the request does not name one
+func jsonRequestSchema(requestBody *RequestBody, contentType string) *Schema {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" {
+		mediaType = "application/json"
+	}
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+
+	if media, exists := requestBody.Content[mediaType]; exists {
+		return media.Schema
+	}
+	if media, exists := requestBody.Content["application/json"]; exists {
+		return media.Schema
+	}
+	return nil
+}
+
+// bodyValidator walks a decoded JSON value alongside its schema, collecting errors
+type bodyValidator struct {
+	schemas map[string]*Schema
+	errors  []RequestValidationError
+}
+
+func (v *bodyValidator) fail(field string, value interface{}, rule, format string, args ...interface{}) {
+	v.errors = append(v.errors, RequestValidationError{
+		Field:   field,
+		Value:   value,
+		Message: fmt.Sprintf(format, args...),
+		Rule:    rule,
+	})
+}
+
+// validate checks value against schema. References that cannot be resolved
+// are not checked, since the mock cannot know what they should contain.
+func (v *bodyValidator) validate(schema *Schema, value interface{}, field string, refDepth int) {
+	if schema == nil {
+		return
+	}
+
+	if schema.Ref != "" {
+		name, ok := strings.CutPrefix(schema.Ref, ComponentSchemaRefPrefix)
+		target, exists := v.schemas[name]
+		if !ok || !exists || target == nil || refDepth >= maxRefDepth {
+			return
+		}
+		v.validate(target, value, field, refDepth+1)
+		return
+	}
+
+	for _, member := range schema.AllOf {
+		v.validate(member, value, field, refDepth)
+	}
+	if len(schema.OneOf) > 0 {
+		if matched := v.countMatches(schema.OneOf, value, field, refDepth); matched != 1 {
+			v.fail(field, value, "oneOf", "must match exactly one schema in oneOf, matched %d", matched)
+		}
+	}
+	if len(schema.AnyOf) > 0 {
+		if v.countMatches(schema.AnyOf, value, field, refDepth) == 0 {
+			v.fail(field, value, "anyOf", "must match at least one schema in anyOf")
+		}
+	}
+
+	// JSON null carries no type information to check
+	if value == nil {
+		return
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		v.fail(field, value, "enum", "must be one of %v", schema.Enum)
+		return
+	}
+
+	if schema.Type != "" && !matchesType(schema.Type, value) {
+		v.fail(field, value, "type", "must be of type %s", schema.Type)
+		return
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, typed, field, refDepth)
+	case []interface{}:
+		v.validateArray(schema, typed, field, refDepth)
+	case string:
+		v.validateString(schema, typed, field)
+	case json.Number:
+		v.validateNumber(schema, typed, field)
+	}
+}
+
+// countMatches reports how many of the branches value satisfies
+func (v *bodyValidator) countMatches(branches []*Schema, value interface{}, field string, refDepth int) int {
+	matched := 0
+	for _, branch := range branches {
+		sub := &bodyValidator{schemas: v.schemas}
+		sub.validate(branch, value, field, refDepth)
+		if len(sub.errors) == 0 {
+			matched++
+		}
+	}
+	return matched
+}
+
+func (v *bodyValidator) validateObject(schema *Schema, object map[string]interface{}, field string, refDepth int) {
+	for _, name := range schema.Required {
+		if _, exists := object[name]; !exists {
+			v.fail(joinField(field, name), nil, "required", "is required")
+		}
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if value, exists := object[name]; exists {
+			v.validate(schema.Properties[name], value, joinField(field, name), refDepth)
+		}
+	}
+}
+
+func (v *bodyValidator) validateArray(schema *Schema, array []interface{}, field string, refDepth int) {
+	if schema.MinItems != nil && len(array) < *schema.MinItems {
+		v.fail(field, len(array), "minItems", "must contain at least %d items", *schema.MinItems)
+	}
+	if schema.MaxItems != nil && len(array) > *schema.MaxItems {
+		v.fail(field, len(array), "maxItems", "must contain at most %d items", *schema.MaxItems)
+	}
+
+	for i, item := range array {
+		v.validate(schema.Items, item, fmt.Sprintf("%s[%d]", field, i), refDepth)
+	}
+}
+
+func (v *bodyValidator) validateString(schema *Schema, value, field string) {
+	length := utf8.RuneCountInString(value)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		v.fail(field, value, "minLength", "must be at least %d characters long", *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		v.fail(field, value, "maxLength", "must be at most %d characters long", *schema.MaxLength)
+	}
+	if schema.Pattern != "" && !matchesPattern(schema.Pattern, value) {
+		v.fail(field, value, "pattern", "must match pattern %s", schema.Pattern)
+	}
+}
+
+func (v *bodyValidator) validateNumber(schema *Schema, value json.Number, field string) {
+	n, err := value.Float64()
+	if err != nil {
+		return
+	}
+	if schema.Minimum != nil && n < *schema.Minimum {
+		v.fail(field, value, "minimum", "must be greater than or equal to %v", *schema.Minimum)
+	}
+	if schema.Maximum != nil && n > *schema.Maximum {
+		v.fail(field, value, "maximum", "must be less than or equal to %v", *schema.Maximum)
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		quotient := n / *schema.MultipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.fail(field, value, "multipleOf", "must be a multiple of %v", *schema.MultipleOf)
+		}
+	}
+}
+
+// matchesType reports whether a decoded JSON value has the given schema type
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		n, err := number.Float64()
+		return err == nil && n == math.Trunc(n)
+	default:
+		return true
+	}
+}
+
+// enumContains reports whether value equals one of the enum entries,
+// comparing numbers by value regardless of how they were decoded
+func enumContains(enum []interface{}, value interface{}) bool {
+	number, isNumber := value.(json.Number)
+	for _, allowed := range enum {
+		if isNumber {
+			n, err := number.Float64()
+			if expected, ok := toFloat(allowed); ok && err == nil && n == expected {
+				return true
+			}
+			continue
+		}
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// toFloat converts numeric enum values decoded from YAML/JSON to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// joinField appends a property name to a field path
+func joinField(parent, name string) string {
+	if parent == requestBodyField {
+		return name
+	}
+	return parent + "." + name
+}
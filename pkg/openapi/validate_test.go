@@ -0,0 +1,107 @@
+package openapi
+
+import (
+	"testing"
+)
+
+func petRequestBody(required bool) *RequestBody {
+	minAge, maxAge := 0.0, 30.0
+	return &RequestBody{
+		Required: required,
+		Content: map[string]MediaTypeObject{
+			"application/json": {
+				Schema: &Schema{
+					Type:     "object",
+					Required: []string{"name", "species"},
+					Properties: map[string]*Schema{
+						"name":    {Type: "string"},
+						"species": {Type: "string", Enum: []interface{}{"cat", "dog"}},
+						"age":     {Type: "integer", Minimum: &minAge, Maximum: &maxAge},
+						"owner":   {Ref: "#/components/schemas/Owner"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func validationRules(errs []RequestValidationError) map[string]string {
+	rules := make(map[string]string, len(errs))
+	for _, err := range errs {
+		rules[err.Field] = err.Rule
+	}
+	return rules
+}
+
+func TestValidateRequestBody_Valid(t *testing.T) {
+	body := `{"name":"Tom","species":"cat","age":3,"owner":{"email":"a@example.com"}}`
+	if errs := ValidateRequestBody(petRequestBody(true), "application/json", []byte(body), componentSchemas()); len(errs) != 0 {
+		t.Errorf("expected no errors, got %+v", errs)
+	}
+}
+
+func TestValidateRequestBody_MissingRequiredField(t *testing.T) {
+	errs := ValidateRequestBody(petRequestBody(true), "application/json", []byte(`{"name":"Tom"}`), componentSchemas())
+
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %+v", errs)
+	}
+	if errs[0].Field != "species" || errs[0].Rule != "required" {
+		t.Errorf("expected required error for species, got %+v", errs[0])
+	}
+}
+
+func TestValidateRequestBody_WrongType(t *testing.T) {
+	body := `{"name":42,"species":"cat","age":2.5}`
+	rules := validationRules(ValidateRequestBody(petRequestBody(true), "application/json", []byte(body), componentSchemas()))
+
+	if rules["name"] != "type" {
+		t.Errorf("expected type error for name, got %v", rules)
+	}
+	if rules["age"] != "type" {
+		t.Errorf("expected type error for non-integral age, got %v", rules)
+	}
+}
+
+func TestValidateRequestBody_EnumAndRange(t *testing.T) {
+	body := `{"name":"Rex","species":"horse","age":31}`
+	rules := validationRules(ValidateRequestBody(petRequestBody(true), "application/json", []byte(body), componentSchemas()))
+
+	if rules["species"] != "enum" {
+		t.Errorf("expected enum error for species, got %v", rules)
+	}
+	if rules["age"] != "maximum" {
+		t.Errorf("expected maximum error for age, got %v", rules)
+	}
+}
+
+func TestValidateRequestBody_FollowsReferences(t *testing.T) {
+	body := `{"name":"Tom","species":"cat","owner":{}}`
+	rules := validationRules(ValidateRequestBody(petRequestBody(true), "application/json", []byte(body), componentSchemas()))
+
+	if rules["owner.email"] != "required" {
+		t.Errorf("expected required error for owner.email, got %v", rules)
+	}
+}
+
+func TestValidateRequestBody_EmptyBody(t *testing.T) {
+	errs := ValidateRequestBody(petRequestBody(true), "application/json", nil, componentSchemas())
+	if len(errs) != 1 || errs[0].Field != "body" || errs[0].Rule != "required" {
+		t.Errorf("expected required body error, got %+v", errs)
+	}
+
+	if errs := ValidateRequestBody(petRequestBody(false), "application/json", nil, componentSchemas()); len(errs) != 0 {
+		t.Errorf("optional body should accept an empty request, got %+v", errs)
+	}
+}
+
+func TestValidateRequestBody_MalformedAndNonJSON(t *testing.T) {
+	errs := ValidateRequestBody(petRequestBody(true), "application/json", []byte(`{"name":`), componentSchemas())
+	if len(errs) != 1 || errs[0].Rule != "json" {
+		t.Errorf("expected json error, got %+v", errs)
+	}
+
+	if errs := ValidateRequestBody(petRequestBody(true), "text/plain", []byte("hello"), componentSchemas()); len(errs) != 0 {
+		t.Errorf("non-JSON bodies should not be validated, got %+v", errs)
+	}
+}
@@ -4,11 +4,19 @@
 package plugins
 
 import (
+	"bytes"
+	"container/list"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"net"
+	"net/url"
+	"os"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -16,10 +24,14 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/sync/singleflight"
 	"golang.org/x/time/rate"
+	"vanta/pkg/config"
+	"vanta/pkg/tracing"
 )
 
 // Version constants for built-in plugins
@@ -29,10 +41,11 @@ const (
 
 // Common error responses
 var (
-	unauthorizedResponse = []byte(`{"error":"unauthorized","message":"Authentication required"}`)
-	forbiddenResponse    = []byte(`{"error":"forbidden","message":"Access denied"}`)
-	rateLimitResponse    = []byte(`{"error":"rate_limit_exceeded","message":"Too many requests"}`)
-	corsErrorResponse    = []byte(`{"error":"cors_error","message":"CORS policy violation"}`)
+	unauthorizedResponse  = []byte(`{"error":"unauthorized","message":"Authentication required"}`)
+	forbiddenResponse     = []byte(`{"error":"forbidden","message":"Access denied"}`)
+	rateLimitResponse     = []byte(`{"error":"rate_limit_exceeded","message":"Too many requests"}`)
+	rateLimitTextResponse = []byte("Too many requests")
+	corsErrorResponse     = []byte(`{"error":"cors_error","message":"CORS policy violation"}`)
 )
 
 // =============================================================================
@@ -47,18 +60,25 @@ type AuthPlugin struct {
 	logger      *zap.Logger
 	
 	// Configuration
-	jwtSecret       []byte
-	jwtPublicKey    interface{}
-	apiKeys         map[string]string // key -> user_id
-	publicEndpoints map[string]bool   // path patterns that don't require auth
-	authHeader      string            // header name for API key auth
-	authQuery       string            // query param name for API key auth
-	authCookie      string            // cookie name for API key auth
+	jwtSecret         []byte
+	jwtPublicKey      interface{}
+	apiKeys           map[string]string // key -> user_id
+	publicEndpoints   map[string]bool   // path patterns that don't require auth
+	optionalEndpoints map[string]bool   // paths where missing credentials proceed anonymously
+	specSecurity      bool              // skip operations the OpenAPI spec declares public
+	authHeader        string            // header name for API key auth
+	authQuery         string            // query param name for API key auth
+	jwtCookie         string            // cookie name for JWT auth
+	apiKeyCookie      string            // cookie name for API key auth
+	forwardHeaders    map[string]string // identity field -> request header set for the handler
 	
 	// JWT configuration
 	jwtSigningMethod jwt.SigningMethod
-	jwtIssuer        string
-	jwtAudience      string
+	jwtIssuers       []string // accepted issuers; empty skips the check
+	jwtAudiences     []string // accepted audiences; empty skips the check
+	
+	// Token introspection for opaque tokens, nil when not configured
+	introspector *tokenIntrospector
 	
 	mu sync.RWMutex
 }
@@ -66,33 +86,62 @@ type AuthPlugin struct {
 // AuthConfig defines configuration for the AuthPlugin
 type AuthConfig struct {
 	// JWT configuration
-	JWTSecret       string `json:"jwt_secret" yaml:"jwt_secret"`
-	JWTPublicKey    string `json:"jwt_public_key" yaml:"jwt_public_key"`
-	JWTMethod       string `json:"jwt_method" yaml:"jwt_method"`           // HS256, RS256, etc.
-	JWTIssuer       string `json:"jwt_issuer" yaml:"jwt_issuer"`
-	JWTAudience     string `json:"jwt_audience" yaml:"jwt_audience"`
+	JWTSecret        string   `json:"jwt_secret" yaml:"jwt_secret"`
+	JWTSecretFile    string   `json:"jwt_secret_file" yaml:"jwt_secret_file"` // overrides JWTSecret
+	JWTPublicKey     string   `json:"jwt_public_key" yaml:"jwt_public_key"`
+	JWTPublicKeyFile string   `json:"jwt_public_key_file" yaml:"jwt_public_key_file"` // overrides JWTPublicKey
+	JWTMethod        string   `json:"jwt_method" yaml:"jwt_method"`                   // HS256, RS256, ES256, EdDSA, etc.
+	JWTIssuer        string   `json:"jwt_issuer" yaml:"jwt_issuer"`
+	JWTIssuers       []string `json:"jwt_issuers" yaml:"jwt_issuers"` // any of these is accepted, along with JWTIssuer
+	JWTAudience      string   `json:"jwt_audience" yaml:"jwt_audience"`
+	JWTAudiences     []string `json:"jwt_audiences" yaml:"jwt_audiences"` // any of these is accepted, along with JWTAudience
 	
 	// API Key configuration
 	APIKeys         map[string]string `json:"api_keys" yaml:"api_keys"`   // key -> user_id
 	AuthHeader      string            `json:"auth_header" yaml:"auth_header"`
 	AuthQuery       string            `json:"auth_query" yaml:"auth_query"`
-	AuthCookie      string            `json:"auth_cookie" yaml:"auth_cookie"`
+	AuthCookie      string            `json:"auth_cookie" yaml:"auth_cookie"`       // fallback for JWTCookie and APIKeyCookie
+	JWTCookie       string            `json:"jwt_cookie" yaml:"jwt_cookie"`         // only read for JWTs
+	APIKeyCookie    string            `json:"api_key_cookie" yaml:"api_key_cookie"` // only read for API keys
 	
 	// Public endpoints (no auth required)
 	PublicEndpoints []string `json:"public_endpoints" yaml:"public_endpoints"`
+	
+	// Optional endpoints honor valid credentials but let requests without
+	// any through anonymously. Invalid credentials are still rejected.
+	OptionalEndpoints []string `json:"optional_endpoints" yaml:"optional_endpoints"`
+	
+	// SpecSecurity treats operations whose OpenAPI security requirements
+	// allow anonymous calls (e.g. `security: []`) as public endpoints
+	SpecSecurity bool `json:"spec_security" yaml:"spec_security"`
+	
+	// Request headers carrying the authenticated identity to the handler,
+	// keyed by identity field (see forwardableIdentityFields). Client-sent
+	// values of these headers are always removed.
+	ForwardHeaders map[string]string `json:"forward_headers" yaml:"forward_headers"`
+	
+	// OAuth2 token introspection (RFC 7662) for opaque bearer tokens
+	IntrospectionURL                string `json:"introspection_url" yaml:"introspection_url"`
+	IntrospectionClientID           string `json:"introspection_client_id" yaml:"introspection_client_id"`
+	IntrospectionClientSecret       string `json:"introspection_client_secret" yaml:"introspection_client_secret"`
+	IntrospectionCacheTTLSeconds    int    `json:"introspection_cache_ttl_seconds" yaml:"introspection_cache_ttl_seconds"`       // max time an active token is cached
+	IntrospectionNegativeTTLSeconds int    `json:"introspection_negative_ttl_seconds" yaml:"introspection_negative_ttl_seconds"` // how long rejections and failures are cached
+	IntrospectionTimeoutSeconds     int    `json:"introspection_timeout_seconds" yaml:"introspection_timeout_seconds"`
 }
 
 // NewAuthPlugin creates a new AuthPlugin instance
 func NewAuthPlugin() Plugin {
 	return &AuthPlugin{
-		name:            "auth",
-		version:         BuiltinVersion,
-		description:     "JWT and API key authentication plugin",
-		apiKeys:         make(map[string]string),
-		publicEndpoints: make(map[string]bool),
-		authHeader:      "Authorization",
-		authQuery:       "api_key",
-		authCookie:      "auth_token",
+		name:              "auth",
+		version:           BuiltinVersion,
+		description:       "JWT and API key authentication plugin",
+		apiKeys:           make(map[string]string),
+		publicEndpoints:   make(map[string]bool),
+		optionalEndpoints: make(map[string]bool),
+		authHeader:        "Authorization",
+		authQuery:         "api_key",
+		jwtCookie:         "auth_token",
+		apiKeyCookie:      "auth_token",
 	}
 }
 
@@ -112,6 +161,22 @@ func (p *AuthPlugin) Init(ctx context.Context, config map[string]interface{}, lo
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	
+	// Key material from files takes precedence over inline values
+	if authConfig.JWTSecretFile != "" {
+		secret, err := readJWTKeyFile("jwt_secret_file", authConfig.JWTSecretFile)
+		if err != nil {
+			return fmt.Errorf("invalid auth config: %w", err)
+		}
+		authConfig.JWTSecret = secret
+	}
+	if authConfig.JWTPublicKeyFile != "" {
+		publicKey, err := readJWTKeyFile("jwt_public_key_file", authConfig.JWTPublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("invalid auth config: %w", err)
+		}
+		authConfig.JWTPublicKey = publicKey
+	}
+
 	// Configure JWT
 	if authConfig.JWTSecret != "" {
 		p.jwtSecret = []byte(authConfig.JWTSecret)
@@ -131,15 +196,32 @@ func (p *AuthPlugin) Init(ctx context.Context, config map[string]interface{}, lo
 			p.jwtSigningMethod = jwt.SigningMethodRS384
 		case "RS512":
 			p.jwtSigningMethod = jwt.SigningMethodRS512
+		case "ES256":
+			p.jwtSigningMethod = jwt.SigningMethodES256
+		case "ES384":
+			p.jwtSigningMethod = jwt.SigningMethodES384
+		case "ES512":
+			p.jwtSigningMethod = jwt.SigningMethodES512
+		case "EdDSA":
+			p.jwtSigningMethod = jwt.SigningMethodEdDSA
 		default:
 			return fmt.Errorf("unsupported JWT method: %s", authConfig.JWTMethod)
 		}
 	} else {
 		p.jwtSigningMethod = jwt.SigningMethodHS256
 	}
-	
-	p.jwtIssuer = authConfig.JWTIssuer
-	p.jwtAudience = authConfig.JWTAudience
+
+	// Asymmetric methods verify with the configured public key
+	if _, isHMAC := p.jwtSigningMethod.(*jwt.SigningMethodHMAC); !isHMAC {
+		publicKey, err := parseJWTPublicKey(p.jwtSigningMethod, authConfig.JWTPublicKey)
+		if err != nil {
+			return fmt.Errorf("invalid auth config: %w", err)
+		}
+		p.jwtPublicKey = publicKey
+	}
+
+	p.jwtIssuers = appendNonEmpty(authConfig.JWTIssuers, authConfig.JWTIssuer)
+	p.jwtAudiences = appendNonEmpty(authConfig.JWTAudiences, authConfig.JWTAudience)
 	
 	// Configure API keys
 	if authConfig.APIKeys != nil {
@@ -156,7 +238,14 @@ func (p *AuthPlugin) Init(ctx context.Context, config map[string]interface{}, lo
 		p.authQuery = authConfig.AuthQuery
 	}
 	if authConfig.AuthCookie != "" {
-		p.authCookie = authConfig.AuthCookie
+		p.jwtCookie = authConfig.AuthCookie
+		p.apiKeyCookie = authConfig.AuthCookie
+	}
+	if authConfig.JWTCookie != "" {
+		p.jwtCookie = authConfig.JWTCookie
+	}
+	if authConfig.APIKeyCookie != "" {
+		p.apiKeyCookie = authConfig.APIKeyCookie
 	}
 	
 	// Configure public endpoints
@@ -164,10 +253,41 @@ func (p *AuthPlugin) Init(ctx context.Context, config map[string]interface{}, lo
 		p.publicEndpoints[endpoint] = true
 	}
 	
+	// Configure optional endpoints
+	for _, endpoint := range authConfig.OptionalEndpoints {
+		p.optionalEndpoints[endpoint] = true
+	}
+	p.specSecurity = authConfig.SpecSecurity
+	
+	// Configure identity forwarding
+	p.forwardHeaders = make(map[string]string, len(authConfig.ForwardHeaders))
+	for field, header := range authConfig.ForwardHeaders {
+		if !slices.Contains(forwardableIdentityFields, field) {
+			return fmt.Errorf("invalid auth config: unknown forward_headers field %q (expected one of %s)", field, strings.Join(forwardableIdentityFields, ", "))
+		}
+		if strings.TrimSpace(header) == "" {
+			return fmt.Errorf("invalid auth config: forward_headers.%s must name a header", field)
+		}
+		p.forwardHeaders[field] = strings.TrimSpace(header)
+	}
+	
+	// Configure token introspection
+	p.introspector = nil
+	if authConfig.IntrospectionURL != "" {
+		introspector, err := newTokenIntrospector(authConfig)
+		if err != nil {
+			return fmt.Errorf("invalid auth config: %w", err)
+		}
+		p.introspector = introspector
+	}
+	
 	p.logger.Info("Auth plugin initialized",
 		zap.Int("api_keys", len(p.apiKeys)),
 		zap.Int("public_endpoints", len(p.publicEndpoints)),
-		zap.String("jwt_method", authConfig.JWTMethod))
+		zap.Int("optional_endpoints", len(p.optionalEndpoints)),
+		zap.Bool("spec_security", p.specSecurity),
+		zap.String("jwt_method", authConfig.JWTMethod),
+		zap.Bool("introspection", p.introspector != nil))
 	
 	return nil
 }
@@ -184,29 +304,73 @@ func (p *AuthPlugin) Priority() Priority {
 func (p *AuthPlugin) PreProcess(ctx *RequestContext) (bool, error) {
 	path := ctx.Path()
 	
+	// Identity headers are only ever set by the plugin, never by the client
+	p.stripForwardHeaders(ctx)
+	
 	// Check if endpoint is public
 	p.mu.RLock()
 	isPublic := p.publicEndpoints[path]
+	isOptional := p.optionalEndpoints[path]
+	specSecurity := p.specSecurity
 	p.mu.RUnlock()
 	
+	// The server marks operations the spec declares public
+	if specSecurity {
+		if specPublic, _ := ctx.RequestCtx.UserValue("spec_public").(bool); specPublic {
+			isPublic = true
+		}
+	}
+	
 	if isPublic {
 		return true, nil
 	}
 	
+	// Browsers never send credentials on a CORS preflight, so rejecting it
+	// would block every cross-origin call; the CORS plugin answers it
+	if ctx.Method() == "OPTIONS" && ctx.Header("Origin") != "" && ctx.Header("Access-Control-Request-Method") != "" {
+		return true, nil
+	}
+	
+	token := p.extractJWT(ctx)
+	apiKey := p.extractAPIKey(ctx)
+	
+	// Optional endpoints serve callers without credentials anonymously
+	if isOptional && token == "" && apiKey == "" {
+		return true, nil
+	}
+	
 	// Try JWT authentication first
-	if token := p.extractJWT(ctx); token != "" {
-		if userID, err := p.validateJWT(token); err == nil {
-			ctx.SetUserValue("user_id", userID)
-			ctx.SetUserValue("auth_method", "jwt")
+	if token != "" {
+		if identity, err := p.validateJWT(token); err == nil {
+			p.authenticated(ctx, identity)
 			return true, nil
 		}
+		
+		// Fall back to introspection for tokens that can't be validated locally
+		p.mu.RLock()
+		introspector := p.introspector
+		p.mu.RUnlock()
+		
+		if introspector != nil {
+			result, err := introspector.introspect(token)
+			if err != nil {
+				p.logger.Warn("Token introspection failed", zap.Error(err))
+			} else if result.active {
+				p.authenticated(ctx, authIdentity{
+					userID:   result.userID,
+					username: result.username,
+					method:   "introspection",
+					scopes:   result.scopes,
+				})
+				return true, nil
+			}
+		}
 	}
 	
 	// Try API key authentication
-	if apiKey := p.extractAPIKey(ctx); apiKey != "" {
+	if apiKey != "" {
 		if userID, valid := p.validateAPIKey(apiKey); valid {
-			ctx.SetUserValue("user_id", userID)
-			ctx.SetUserValue("auth_method", "api_key")
+			p.authenticated(ctx, authIdentity{userID: userID, method: "api_key"})
 			return true, nil
 		}
 	}
@@ -224,6 +388,52 @@ func (p *AuthPlugin) PreProcess(ctx *RequestContext) (bool, error) {
 	return false, nil
 }
 
+// forwardableIdentityFields are the identity fields forward_headers can map
+var forwardableIdentityFields = []string{"user_id", "auth_method", "username", "scopes"}
+
+// authIdentity is the caller established by a successful authentication
+type authIdentity struct {
+	userID   string
+	username string
+	method   string
+	scopes   []string
+}
+
+// authenticated stores identity in user values and sets the configured
+// forward headers so the handler and upstreams can see who called
+func (p *AuthPlugin) authenticated(ctx *RequestContext, identity authIdentity) {
+	ctx.SetUserValue("user_id", identity.userID)
+	if identity.username != "" {
+		ctx.SetUserValue("username", identity.username)
+	}
+	ctx.SetUserValue("auth_method", identity.method)
+	
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	
+	values := map[string]string{
+		"user_id":     identity.userID,
+		"auth_method": identity.method,
+		"username":    identity.username,
+		"scopes":      strings.Join(identity.scopes, " "),
+	}
+	for field, header := range p.forwardHeaders {
+		if value := values[field]; value != "" {
+			ctx.RequestCtx.Request.Header.Set(header, value)
+		}
+	}
+}
+
+// stripForwardHeaders removes client-supplied copies of the forward headers
+func (p *AuthPlugin) stripForwardHeaders(ctx *RequestContext) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	
+	for _, header := range p.forwardHeaders {
+		ctx.RequestCtx.Request.Header.Del(header)
+	}
+}
+
 func (p *AuthPlugin) PostProcess(ctx *ResponseContext) error {
 	// No post-processing needed for auth
 	return nil
@@ -242,7 +452,7 @@ func (p *AuthPlugin) extractJWT(ctx *RequestContext) string {
 	}
 	
 	// Check cookie
-	return string(ctx.RequestCtx.Request.Header.Cookie(p.authCookie))
+	return string(ctx.RequestCtx.Request.Header.Cookie(p.jwtCookie))
 }
 
 func (p *AuthPlugin) extractAPIKey(ctx *RequestContext) string {
@@ -257,10 +467,10 @@ func (p *AuthPlugin) extractAPIKey(ctx *RequestContext) string {
 	}
 	
 	// Check cookie
-	return string(ctx.RequestCtx.Request.Header.Cookie(p.authCookie))
+	return string(ctx.RequestCtx.Request.Header.Cookie(p.apiKeyCookie))
 }
 
-func (p *AuthPlugin) validateJWT(tokenString string) (string, error) {
+func (p *AuthPlugin) validateJWT(tokenString string) (authIdentity, error) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 	
@@ -270,49 +480,355 @@ func (p *AuthPlugin) validateJWT(tokenString string) (string, error) {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		
-		// Return the appropriate key based on signing method
-		switch p.jwtSigningMethod {
-		case jwt.SigningMethodHS256, jwt.SigningMethodHS384, jwt.SigningMethodHS512:
+		// Return the appropriate key based on signing method family
+		switch p.jwtSigningMethod.(type) {
+		case *jwt.SigningMethodHMAC:
 			return p.jwtSecret, nil
-		default:
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA, *jwt.SigningMethodEd25519:
 			return p.jwtPublicKey, nil
+		default:
+			return nil, fmt.Errorf("unsupported signing method: %v", token.Header["alg"])
 		}
 	})
 	
 	if err != nil {
-		return "", err
+		return authIdentity{}, err
 	}
 	
 	if !token.Valid {
-		return "", fmt.Errorf("invalid token")
+		return authIdentity{}, fmt.Errorf("invalid token")
 	}
 	
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", fmt.Errorf("invalid claims")
+		return authIdentity{}, fmt.Errorf("invalid claims")
 	}
 	
 	// Verify issuer if configured
-	if p.jwtIssuer != "" {
-		if iss, ok := claims["iss"].(string); !ok || iss != p.jwtIssuer {
-			return "", fmt.Errorf("invalid issuer")
+	if len(p.jwtIssuers) > 0 {
+		if iss, err := claims.GetIssuer(); err != nil || !slices.Contains(p.jwtIssuers, iss) {
+			return authIdentity{}, fmt.Errorf("invalid issuer")
 		}
 	}
 	
-	// Verify audience if configured
-	if p.jwtAudience != "" {
-		if aud, ok := claims["aud"].(string); !ok || aud != p.jwtAudience {
-			return "", fmt.Errorf("invalid audience")
+	// Verify audience if configured. aud may be a string or an array; any
+	// accepted audience in it passes.
+	if len(p.jwtAudiences) > 0 {
+		aud, err := claims.GetAudience()
+		if err != nil || !slices.ContainsFunc(aud, func(a string) bool { return slices.Contains(p.jwtAudiences, a) }) {
+			return authIdentity{}, fmt.Errorf("invalid audience")
 		}
 	}
 	
 	// Extract user ID
 	userID, ok := claims["sub"].(string)
 	if !ok {
-		return "", fmt.Errorf("missing subject claim")
+		return authIdentity{}, fmt.Errorf("missing subject claim")
 	}
 	
-	return userID, nil
+	return authIdentity{userID: userID, method: "jwt", scopes: jwtScopes(claims)}, nil
+}
+
+// jwtScopes reads the space-separated scope claim, or the scp claim used by
+// some providers as a string or an array
+func jwtScopes(claims jwt.MapClaims) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+	switch scp := claims["scp"].(type) {
+	case string:
+		return strings.Fields(scp)
+	case []interface{}:
+		scopes := make([]string, 0, len(scp))
+		for _, value := range scp {
+			if scope, ok := value.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+		return scopes
+	}
+	return nil
+}
+
+// appendNonEmpty returns a copy of values with value added unless it is empty
+func appendNonEmpty(values []string, value string) []string {
+	result := append([]string(nil), values...)
+	if value != "" {
+		result = append(result, value)
+	}
+	return result
+}
+
+// readJWTKeyFile reads key material mounted as a file, such as a Kubernetes
+// secret. Trailing newlines are trimmed since editors and secret tooling
+// commonly add one.
+func readJWTKeyFile(field, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", field, err)
+	}
+
+	key := strings.TrimRight(string(data), "\r\n")
+	if key == "" {
+		return "", fmt.Errorf("%s %s is empty", field, path)
+	}
+
+	return key, nil
+}
+
+// parseJWTPublicKey parses a PEM-encoded public key into the key type the
+// signing method verifies with: *rsa.PublicKey, *ecdsa.PublicKey or
+// ed25519.PublicKey
+func parseJWTPublicKey(method jwt.SigningMethod, pemKey string) (interface{}, error) {
+	if pemKey == "" {
+		return nil, fmt.Errorf("jwt_public_key or jwt_public_key_file is required for %s", method.Alg())
+	}
+
+	var (
+		key interface{}
+		err error
+	)
+	switch method.(type) {
+	case *jwt.SigningMethodRSA:
+		key, err = jwt.ParseRSAPublicKeyFromPEM([]byte(pemKey))
+	case *jwt.SigningMethodECDSA:
+		key, err = jwt.ParseECPublicKeyFromPEM([]byte(pemKey))
+	case *jwt.SigningMethodEd25519:
+		key, err = jwt.ParseEdPublicKeyFromPEM([]byte(pemKey))
+	default:
+		return nil, fmt.Errorf("unsupported JWT method: %s", method.Alg())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse jwt_public_key for %s: %w", method.Alg(), err)
+	}
+
+	return key, nil
+}
+
+// Defaults for token introspection
+const (
+	defaultIntrospectionCacheTTL    = 60 * time.Second
+	defaultIntrospectionNegativeTTL = 5 * time.Second
+	defaultIntrospectionTimeout     = 5 * time.Second
+	
+	// maxIntrospectionCacheEntries bounds the cache; past it the least
+	// recently used token is evicted
+	maxIntrospectionCacheEntries = 10000
+)
+
+// tokenIntrospector validates opaque tokens against an OAuth2 introspection
+// endpoint (RFC 7662), caching results so each token is introspected at most
+// once per cache TTL. Concurrent requests carrying the same uncached token
+// share a single query.
+type tokenIntrospector struct {
+	url          string
+	clientID     string
+	clientSecret string
+	client       *fasthttp.Client
+	timeout      time.Duration
+	cacheTTL     time.Duration
+	negativeTTL  time.Duration
+	
+	cache      map[string]*list.Element // Values are *introspectionCacheEntry
+	lru        *list.List               // Most recently used first
+	maxEntries int
+	mu         sync.Mutex
+	inflight   singleflight.Group
+}
+
+// introspectionCacheEntry is an element of the introspection LRU list
+type introspectionCacheEntry struct {
+	token  string
+	result *introspectionResult
+}
+
+// introspectionResult is the cached outcome of introspecting a token
+type introspectionResult struct {
+	active    bool
+	userID    string
+	username  string
+	scopes    []string
+	expiresAt time.Time
+}
+
+// introspectionResponse is the subset of an RFC 7662 response the plugin uses
+type introspectionResponse struct {
+	Active   bool    `json:"active"`
+	Sub      string  `json:"sub"`
+	Username string  `json:"username"`
+	ClientID string  `json:"client_id"`
+	Scope    string  `json:"scope"`
+	Exp      float64 `json:"exp"`
+}
+
+func newTokenIntrospector(authConfig AuthConfig) (*tokenIntrospector, error) {
+	endpoint, err := url.Parse(authConfig.IntrospectionURL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("introspection_url must be an absolute http(s) URL: %q", authConfig.IntrospectionURL)
+	}
+	if authConfig.IntrospectionClientID == "" && authConfig.IntrospectionClientSecret != "" {
+		return nil, fmt.Errorf("introspection_client_secret requires introspection_client_id")
+	}
+	
+	i := &tokenIntrospector{
+		url:          authConfig.IntrospectionURL,
+		clientID:     authConfig.IntrospectionClientID,
+		clientSecret: authConfig.IntrospectionClientSecret,
+		client:       &fasthttp.Client{},
+		timeout:      defaultIntrospectionTimeout,
+		cacheTTL:     defaultIntrospectionCacheTTL,
+		negativeTTL:  defaultIntrospectionNegativeTTL,
+		cache:        make(map[string]*list.Element),
+		lru:          list.New(),
+		maxEntries:   maxIntrospectionCacheEntries,
+	}
+	if authConfig.IntrospectionTimeoutSeconds > 0 {
+		i.timeout = time.Duration(authConfig.IntrospectionTimeoutSeconds) * time.Second
+	}
+	if authConfig.IntrospectionCacheTTLSeconds > 0 {
+		i.cacheTTL = time.Duration(authConfig.IntrospectionCacheTTLSeconds) * time.Second
+	}
+	if authConfig.IntrospectionNegativeTTLSeconds > 0 {
+		i.negativeTTL = time.Duration(authConfig.IntrospectionNegativeTTLSeconds) * time.Second
+	}
+	
+	return i, nil
+}
+
+// introspect returns the cached result for a token, querying the endpoint
+// when there is none. Failures are treated as inactive tokens and cached for
+// the negative TTL so an unavailable endpoint isn't hammered.
+func (i *tokenIntrospector) introspect(token string) (*introspectionResult, error) {
+	if result, ok := i.cached(token, time.Now()); ok {
+		return result, nil
+	}
+	
+	value, err, _ := i.inflight.Do(token, func() (interface{}, error) {
+		// A query that finished while this one waited to start already
+		// cached the answer
+		now := time.Now()
+		if result, ok := i.cached(token, now); ok {
+			return result, nil
+		}
+		
+		result, err := i.query(token, now)
+		if err != nil {
+			result = &introspectionResult{expiresAt: now.Add(i.negativeTTL)}
+		}
+		i.store(token, result)
+		return result, err
+	})
+	return value.(*introspectionResult), err
+}
+
+// cached returns the unexpired cached result for a token and marks it as
+// recently used. Expired results are dropped.
+func (i *tokenIntrospector) cached(token string, now time.Time) (*introspectionResult, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	
+	elem, exists := i.cache[token]
+	if !exists {
+		return nil, false
+	}
+	entry := elem.Value.(*introspectionCacheEntry)
+	if !now.Before(entry.result.expiresAt) {
+		i.lru.Remove(elem)
+		delete(i.cache, token)
+		return nil, false
+	}
+	i.lru.MoveToFront(elem)
+	return entry.result, true
+}
+
+// store caches a result, evicting the least recently used tokens once the
+// cache is full
+func (i *tokenIntrospector) store(token string, result *introspectionResult) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	
+	if elem, exists := i.cache[token]; exists {
+		elem.Value.(*introspectionCacheEntry).result = result
+		i.lru.MoveToFront(elem)
+		return
+	}
+	i.cache[token] = i.lru.PushFront(&introspectionCacheEntry{token: token, result: result})
+	
+	for i.lru.Len() > i.maxEntries {
+		oldest := i.lru.Back()
+		i.lru.Remove(oldest)
+		delete(i.cache, oldest.Value.(*introspectionCacheEntry).token)
+	}
+}
+
+// query posts the token to the introspection endpoint
+func (i *tokenIntrospector) query(token string, now time.Time) (*introspectionResult, error) {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+	
+	req.SetRequestURI(i.url)
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.clientID != "" {
+		// RFC 6749 section 2.3.1: credentials are form-encoded before Basic encoding
+		credentials := url.QueryEscape(i.clientID) + ":" + url.QueryEscape(i.clientSecret)
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+	req.SetBodyString(url.Values{
+		"token":           {token},
+		"token_type_hint": {"access_token"},
+	}.Encode())
+	
+	if err := i.client.DoTimeout(req, resp, i.timeout); err != nil {
+		return nil, fmt.Errorf("introspection request failed: %w", err)
+	}
+	if resp.StatusCode() != fasthttp.StatusOK {
+		return nil, fmt.Errorf("introspection endpoint returned status %d", resp.StatusCode())
+	}
+	
+	var body introspectionResponse
+	if err := json.Unmarshal(resp.Body(), &body); err != nil {
+		return nil, fmt.Errorf("invalid introspection response: %w", err)
+	}
+	
+	result := &introspectionResult{
+		active:    body.Active,
+		username:  body.Username,
+		scopes:    strings.Fields(body.Scope),
+		expiresAt: now.Add(i.negativeTTL),
+	}
+	if !result.active {
+		return result, nil
+	}
+	
+	// Cache active tokens until they expire, capped at the cache TTL
+	ttl := i.cacheTTL
+	if body.Exp > 0 {
+		untilExpiry := time.Unix(int64(body.Exp), 0).Sub(now)
+		if untilExpiry <= 0 {
+			result.active = false
+			return result, nil
+		}
+		if untilExpiry < ttl {
+			ttl = untilExpiry
+		}
+	}
+	result.expiresAt = now.Add(ttl)
+	
+	// Client credentials tokens may carry no subject
+	switch {
+	case body.Sub != "":
+		result.userID = body.Sub
+	case body.Username != "":
+		result.userID = body.Username
+	default:
+		result.userID = body.ClientID
+	}
+	
+	return result, nil
 }
 
 func (p *AuthPlugin) validateAPIKey(apiKey string) (string, bool) {
@@ -348,6 +864,10 @@ type RateLimitPlugin struct {
 	userBurst     int
 	exemptIPs     map[string]bool
 	
+	// Custom 429 response, empty to negotiate the default body
+	responseBody        string
+	responseContentType string
+	
 	// Cleanup
 	cleanupInterval time.Duration
 	entryTTL        time.Duration
@@ -355,6 +875,9 @@ type RateLimitPlugin struct {
 	mu sync.RWMutex
 }
 
+// rateLimitIPLimiterKey holds the IP limiter PreProcess resolved for a request
+const rateLimitIPLimiterKey = "rate_limit_ip_limiter"
+
 type rateLimiterEntry struct {
 	limiter  *rate.Limiter
 	lastUsed time.Time
@@ -380,6 +903,11 @@ type RateLimitConfig struct {
 	// Cleanup configuration
 	CleanupIntervalSeconds int `json:"cleanup_interval_seconds" yaml:"cleanup_interval_seconds"`
 	EntryTTLSeconds        int `json:"entry_ttl_seconds" yaml:"entry_ttl_seconds"`
+	
+	// Custom 429 response. The body may reference {limit_type} and
+	// {retry_after}; the content type defaults to application/json.
+	ResponseBody        string `json:"response_body" yaml:"response_body"`
+	ResponseContentType string `json:"response_content_type" yaml:"response_content_type"`
 }
 
 // NewRateLimitPlugin creates a new RateLimitPlugin instance
@@ -453,6 +981,13 @@ func (p *RateLimitPlugin) Init(ctx context.Context, config map[string]interface{
 		p.entryTTL = time.Duration(rlConfig.EntryTTLSeconds) * time.Second
 	}
 	
+	// Configure the 429 response
+	p.responseBody = rlConfig.ResponseBody
+	p.responseContentType = rlConfig.ResponseContentType
+	if p.responseBody != "" && p.responseContentType == "" {
+		p.responseContentType = "application/json"
+	}
+	
 	// Start cleanup goroutine
 	go p.cleanupLoop(ctx)
 	
@@ -478,7 +1013,7 @@ func (p *RateLimitPlugin) Priority() Priority {
 }
 
 func (p *RateLimitPlugin) PreProcess(ctx *RequestContext) (bool, error) {
-	clientIP := p.getClientIP(ctx)
+	clientIP := ctx.ClientIP()
 	
 	// Check if IP is exempt
 	p.mu.RLock()
@@ -497,6 +1032,7 @@ func (p *RateLimitPlugin) PreProcess(ctx *RequestContext) (bool, error) {
 	// Check IP rate limit
 	if p.ipLimit > 0 {
 		ipLimiter := p.getIPLimiter(clientIP)
+		ctx.SetUserValue(rateLimitIPLimiterKey, ipLimiter)
 		if !ipLimiter.Allow() {
 			return p.rateLimitExceeded(ctx, "ip")
 		}
@@ -516,11 +1052,14 @@ func (p *RateLimitPlugin) PreProcess(ctx *RequestContext) (bool, error) {
 }
 
 func (p *RateLimitPlugin) PostProcess(ctx *ResponseContext) error {
-	// Add rate limit headers
-	clientIP := p.getClientIP(ctx.RequestContext)
+	// Add rate limit headers using the limiter resolved in PreProcess; requests
+	// that were never checked against an IP limiter get no headers
+	value, exists := ctx.GetUserValue(rateLimitIPLimiterKey)
+	if !exists {
+		return nil
+	}
 	
-	if p.ipLimit > 0 {
-		ipLimiter := p.getIPLimiter(clientIP)
+	if ipLimiter, ok := value.(*rate.Limiter); ok {
 		tokens := ipLimiter.Tokens()
 		
 		ctx.RequestCtx.Response.Header.Set("X-RateLimit-Limit", strconv.Itoa(p.ipBurst))
@@ -535,29 +1074,6 @@ func (p *RateLimitPlugin) ShouldApply(req *fasthttp.RequestCtx) bool {
 	return true
 }
 
-func (p *RateLimitPlugin) getClientIP(ctx *RequestContext) string {
-	// Check X-Forwarded-For header
-	if xff := ctx.Header("X-Forwarded-For"); xff != "" {
-		parts := strings.Split(xff, ",")
-		if len(parts) > 0 {
-			return strings.TrimSpace(parts[0])
-		}
-	}
-	
-	// Check X-Real-IP header
-	if xri := ctx.Header("X-Real-IP"); xri != "" {
-		return strings.TrimSpace(xri)
-	}
-	
-	// Use remote address
-	host, _, err := net.SplitHostPort(ctx.RemoteAddr())
-	if err != nil {
-		return ctx.RemoteAddr()
-	}
-	
-	return host
-}
-
 func (p *RateLimitPlugin) getIPLimiter(ip string) *rate.Limiter {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -595,21 +1111,64 @@ func (p *RateLimitPlugin) getUserLimiter(userID string) *rate.Limiter {
 }
 
 func (p *RateLimitPlugin) rateLimitExceeded(ctx *RequestContext, limitType string) (bool, error) {
+	const retryAfter = "1"
+	
 	ctx.RequestCtx.SetStatusCode(fasthttp.StatusTooManyRequests)
-	ctx.RequestCtx.SetContentType("application/json")
-	ctx.RequestCtx.SetBody(rateLimitResponse)
+	switch {
+	case p.responseBody != "":
+		ctx.RequestCtx.SetContentType(p.responseContentType)
+		ctx.RequestCtx.SetBodyString(strings.NewReplacer(
+			"{limit_type}", limitType,
+			"{retry_after}", retryAfter,
+		).Replace(p.responseBody))
+	case prefersPlainText(ctx.Header("Accept")):
+		ctx.RequestCtx.SetContentType("text/plain; charset=utf-8")
+		ctx.RequestCtx.SetBody(rateLimitTextResponse)
+	default:
+		ctx.RequestCtx.SetContentType("application/json")
+		ctx.RequestCtx.SetBody(rateLimitResponse)
+	}
 	
 	// Add Retry-After header
-	ctx.RequestCtx.Response.Header.Set("Retry-After", "1")
+	ctx.RequestCtx.Response.Header.Set("Retry-After", retryAfter)
 	
 	p.logger.Warn("Rate limit exceeded",
 		zap.String("limit_type", limitType),
-		zap.String("client_ip", p.getClientIP(ctx)),
+		zap.String("client_ip", ctx.ClientIP()),
 		zap.String("path", ctx.Path()))
 	
 	return false, nil
 }
 
+// prefersPlainText reports whether an Accept header ranks plain text above
+// JSON. JSON wins ties and is used when neither is acceptable.
+func prefersPlainText(accept string) bool {
+	jsonQuality, textQuality := -1.0, -1.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		quality := 1.0
+		for _, param := range params[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		
+		switch {
+		case mediaType == "application/json", mediaType == "application/*", mediaType == "*/*", strings.HasSuffix(mediaType, "+json"):
+			jsonQuality = max(jsonQuality, quality)
+		case mediaType == "text/plain", mediaType == "text/*":
+			textQuality = max(textQuality, quality)
+		}
+	}
+	return textQuality > jsonQuality
+}
+
 func (p *RateLimitPlugin) cleanupLoop(ctx context.Context) {
 	ticker := time.NewTicker(p.cleanupInterval)
 	defer ticker.Stop()
@@ -669,7 +1228,8 @@ type CORSPlugin struct {
 	maxAge           int
 	
 	// Dynamic origin validation
-	originPatterns []*regexp.Regexp
+	originPatterns  []*regexp.Regexp
+	wildcardOrigins []wildcardOrigin
 	originValidator func(string) bool
 	
 	mu sync.RWMutex
@@ -719,6 +1279,16 @@ func (p *CORSPlugin) Init(ctx context.Context, config map[string]interface{}, lo
 		p.allowOrigins = corsConfig.AllowOrigins
 	}
 	
+	// "*.example.com" entries allow every subdomain
+	p.wildcardOrigins = nil
+	for _, origin := range p.allowOrigins {
+		if wildcard, ok := parseWildcardOrigin(origin); ok {
+			p.wildcardOrigins = append(p.wildcardOrigins, wildcard)
+		} else if strings.Contains(origin, "*.") {
+			p.logger.Warn("Invalid wildcard origin", zap.String("origin", origin))
+		}
+	}
+	
 	// Configure allowed methods
 	if len(corsConfig.AllowMethods) > 0 {
 		p.allowMethods = corsConfig.AllowMethods
@@ -842,6 +1412,13 @@ func (p *CORSPlugin) isOriginAllowed(origin string) bool {
 		}
 	}
 	
+	// Check wildcard subdomain origins
+	for _, wildcard := range p.wildcardOrigins {
+		if wildcard.matches(origin) {
+			return true
+		}
+	}
+	
 	// Check origin patterns
 	for _, pattern := range p.originPatterns {
 		if pattern.MatchString(origin) {
@@ -857,6 +1434,67 @@ func (p *CORSPlugin) isOriginAllowed(origin string) bool {
 	return false
 }
 
+// wildcardOrigin is an allow_origins entry such as "*.example.com" or
+// "https://*.example.com" that allows every subdomain of its domain
+type wildcardOrigin struct {
+	scheme string // empty allows both http and https
+	suffix string // ".example.com"
+	port   string
+}
+
+// parseWildcardOrigin parses a "[scheme://]*.domain[:port]" origin entry
+func parseWildcardOrigin(entry string) (wildcardOrigin, bool) {
+	scheme, hostPort, hasScheme := strings.Cut(entry, "://")
+	if !hasScheme {
+		scheme, hostPort = "", entry
+	}
+	if !strings.HasPrefix(hostPort, "*.") {
+		return wildcardOrigin{}, false
+	}
+
+	host, port := strings.TrimPrefix(hostPort, "*."), ""
+	if strings.Contains(host, ":") {
+		var err error
+		if host, port, err = net.SplitHostPort(host); err != nil {
+			return wildcardOrigin{}, false
+		}
+	}
+	if host == "" || strings.ContainsAny(host, "*/") || strings.HasPrefix(host, ".") {
+		return wildcardOrigin{}, false
+	}
+
+	return wildcardOrigin{
+		scheme: strings.ToLower(scheme),
+		suffix: "." + strings.ToLower(host),
+		port:   port,
+	}, true
+}
+
+// matches reports whether origin's host is a subdomain of w's domain with the
+// same scheme and port. The domain itself does not match.
+func (w wildcardOrigin) matches(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if w.scheme != "" {
+		if scheme != w.scheme {
+			return false
+		}
+	} else if scheme != "http" && scheme != "https" {
+		return false
+	}
+
+	if u.Port() != w.port {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	return len(host) > len(w.suffix) && strings.HasSuffix(host, w.suffix)
+}
+
 func (p *CORSPlugin) isMethodAllowed(method string) bool {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
@@ -955,6 +1593,11 @@ type LoggingPlugin struct {
 	sensitiveFields  map[string]bool
 	logFormat        string
 	includeMetrics   bool
+	asyncPostProcess bool
+	sampleRate       float64
+	
+	// sink is the plugin's own log output when Output is set; closed on Cleanup
+	sink zapcore.WriteSyncer
 	
 	mu sync.RWMutex
 }
@@ -969,6 +1612,9 @@ type LoggingConfig struct {
 	SensitiveFields  []string `json:"sensitive_fields" yaml:"sensitive_fields"`
 	LogFormat        string   `json:"log_format" yaml:"log_format"` // "json" or "console"
 	IncludeMetrics   bool     `json:"include_metrics" yaml:"include_metrics"`
+	AsyncPostProcess bool     `json:"async_post_process" yaml:"async_post_process"` // Log responses after they are sent
+	SampleRate       *float64 `json:"sample_rate" yaml:"sample_rate"`               // Fraction of successful requests logged; errors always are
+	Output           string   `json:"output" yaml:"output"`                         // stdout, stderr, syslog or file path; empty uses the app logger
 }
 
 // NewLoggingPlugin creates a new LoggingPlugin instance
@@ -983,6 +1629,7 @@ func NewLoggingPlugin() Plugin {
 		sensitiveFields:  make(map[string]bool),
 		logFormat:        "json",
 		includeMetrics:   true,
+		sampleRate:       1,
 	}
 }
 
@@ -990,12 +1637,12 @@ func (p *LoggingPlugin) Name() string        { return p.name }
 func (p *LoggingPlugin) Version() string     { return p.version }
 func (p *LoggingPlugin) Description() string { return p.description }
 
-func (p *LoggingPlugin) Init(ctx context.Context, config map[string]interface{}, logger *zap.Logger) error {
+func (p *LoggingPlugin) Init(ctx context.Context, pluginConfig map[string]interface{}, logger *zap.Logger) error {
 	p.logger = logger.With(zap.String("plugin", p.name))
 	
 	// Parse configuration
 	var logConfig LoggingConfig
-	if err := mapToStruct(config, &logConfig); err != nil {
+	if err := mapToStruct(pluginConfig, &logConfig); err != nil {
 		return fmt.Errorf("invalid logging config: %w", err)
 	}
 	
@@ -1019,8 +1666,7 @@ func (p *LoggingPlugin) Init(ctx context.Context, config map[string]interface{},
 	}
 	
 	// Configure sensitive headers
-	defaultSensitive := []string{"authorization", "cookie", "x-api-key", "x-auth-token"}
-	for _, header := range defaultSensitive {
+	for _, header := range config.DefaultSensitiveHeaders {
 		p.sensitiveHeaders[strings.ToLower(header)] = true
 	}
 	for _, header := range logConfig.SensitiveHeaders {
@@ -1028,8 +1674,7 @@ func (p *LoggingPlugin) Init(ctx context.Context, config map[string]interface{},
 	}
 	
 	// Configure sensitive fields
-	defaultSensitiveFields := []string{"password", "secret", "token", "key", "credential"}
-	for _, field := range defaultSensitiveFields {
+	for _, field := range config.DefaultSensitiveFields {
 		p.sensitiveFields[strings.ToLower(field)] = true
 	}
 	for _, field := range logConfig.SensitiveFields {
@@ -1043,6 +1688,27 @@ func (p *LoggingPlugin) Init(ctx context.Context, config map[string]interface{},
 	
 	// Configure metrics
 	p.includeMetrics = logConfig.IncludeMetrics
+	p.asyncPostProcess = logConfig.AsyncPostProcess
+	
+	// Configure sampling
+	if logConfig.SampleRate != nil {
+		if *logConfig.SampleRate < 0 || *logConfig.SampleRate > 1 {
+			return fmt.Errorf("sample_rate must be between 0 and 1, got %v", *logConfig.SampleRate)
+		}
+		p.sampleRate = *logConfig.SampleRate
+	}
+	
+	// Route request logs to their own sink instead of the app logger
+	if logConfig.Output != "" {
+		sink, err := openLogSink(logConfig.Output)
+		if err != nil {
+			return fmt.Errorf("failed to open log output %q: %w", logConfig.Output, err)
+		}
+		p.sink = sink
+		p.logger = zap.New(zapcore.NewCore(logEncoder(p.logFormat), sink, p.logLevel)).
+			With(zap.String("plugin", p.name))
+		logger.Info("Logging plugin writing to separate output", zap.String("output", logConfig.Output))
+	}
 	
 	p.logger.Info("Logging plugin initialized",
 		zap.String("log_level", p.logLevel.String()),
@@ -1050,47 +1716,86 @@ func (p *LoggingPlugin) Init(ctx context.Context, config map[string]interface{},
 		zap.Bool("log_response_body", p.logResponseBody),
 		zap.Int64("max_body_size", p.maxBodySize),
 		zap.Int("sensitive_headers", len(p.sensitiveHeaders)),
-		zap.Int("sensitive_fields", len(p.sensitiveFields)))
+		zap.Int("sensitive_fields", len(p.sensitiveFields)),
+		zap.Float64("sample_rate", p.sampleRate))
 	
 	return nil
 }
 
 func (p *LoggingPlugin) Cleanup(ctx context.Context) error {
 	p.logger.Info("Logging plugin cleanup completed")
+	
+	p.mu.Lock()
+	sink := p.sink
+	p.sink = nil
+	p.mu.Unlock()
+	
+	if sink == nil {
+		return nil
+	}
+	// Syncing stdout/stderr fails on some platforms; only closing matters
+	_ = sink.Sync()
+	if closer, ok := sink.(io.Closer); ok {
+		return closer.Close()
+	}
 	return nil
 }
 
+// openLogSink opens the writer behind a LoggingConfig.Output value
+func openLogSink(output string) (zapcore.WriteSyncer, error) {
+	switch output {
+	case "stdout":
+		return zapcore.Lock(os.Stdout), nil
+	case "stderr":
+		return zapcore.Lock(os.Stderr), nil
+	case "syslog":
+		return openSyslogSink()
+	}
+	
+	file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// logEncoder builds the encoder for a log_format value
+func logEncoder(format string) zapcore.Encoder {
+	if format == "console" {
+		return zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	}
+	return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+}
+
 func (p *LoggingPlugin) Priority() Priority {
 	return PriorityLow // Logging should run last
 }
 
 func (p *LoggingPlugin) PreProcess(ctx *RequestContext) (bool, error) {
+	p.correlate(ctx)
+	
 	// Log request
 	if p.logger.Core().Enabled(p.logLevel) {
 		fields := p.buildRequestFields(ctx)
 		
-		switch p.logLevel {
-		case zapcore.DebugLevel:
-			p.logger.Debug("HTTP request", fields...)
-		case zapcore.InfoLevel:
-			p.logger.Info("HTTP request", fields...)
-		case zapcore.WarnLevel:
-			p.logger.Warn("HTTP request", fields...)
-		case zapcore.ErrorLevel:
-			p.logger.Error("HTTP request", fields...)
+		// Requests left out of the sample hold their line back in case the
+		// response turns out to be an error
+		if !p.sampled(ctx) {
+			ctx.SetPluginData(p.name, "request_fields", fields)
+			return true, nil
 		}
+		p.log(p.logLevel, "HTTP request", fields)
 	}
 	
 	return true, nil
 }
 
 func (p *LoggingPlugin) PostProcess(ctx *ResponseContext) error {
+	p.correlate(ctx.RequestContext)
+	
 	// Log response
 	if p.logger.Core().Enabled(p.logLevel) {
-		fields := p.buildResponseFields(ctx)
-		
 		statusCode := ctx.RequestCtx.Response.StatusCode()
-		message := "HTTP response"
 		
 		// Determine log level based on status code
 		var logLevel zapcore.Level
@@ -1103,25 +1808,101 @@ func (p *LoggingPlugin) PostProcess(ctx *ResponseContext) error {
 			logLevel = p.logLevel
 		}
 		
-		switch logLevel {
-		case zapcore.DebugLevel:
-			p.logger.Debug(message, fields...)
-		case zapcore.InfoLevel:
-			p.logger.Info(message, fields...)
-		case zapcore.WarnLevel:
-			p.logger.Warn(message, fields...)
-		case zapcore.ErrorLevel:
-			p.logger.Error(message, fields...)
+		// Errors are always logged, along with the request line they held back
+		if held, ok := ctx.GetPluginData(p.name, "request_fields"); ok {
+			if statusCode < 400 {
+				return nil
+			}
+			p.log(p.logLevel, "HTTP request", held.([]zap.Field))
 		}
+		
+		p.log(logLevel, "HTTP response", p.buildResponseFields(ctx))
 	}
 	
 	return nil
 }
 
+// log writes a line at one of the levels the plugin logs at
+func (p *LoggingPlugin) log(level zapcore.Level, message string, fields []zap.Field) {
+	switch level {
+	case zapcore.DebugLevel:
+		p.logger.Debug(message, fields...)
+	case zapcore.InfoLevel:
+		p.logger.Info(message, fields...)
+	case zapcore.WarnLevel:
+		p.logger.Warn(message, fields...)
+	case zapcore.ErrorLevel:
+		p.logger.Error(message, fields...)
+	}
+}
+
+// sampled reports whether the request falls in the logged sample. The
+// decision hashes the request ID, so every line of a request agrees on it.
+func (p *LoggingPlugin) sampled(ctx *RequestContext) bool {
+	p.mu.RLock()
+	rate := p.sampleRate
+	p.mu.RUnlock()
+	
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	requestID, _ := ctx.GetUserValue("request_id")
+	hash := fnv.New64a()
+	hash.Write([]byte(fmt.Sprint(requestID)))
+	// The low bits of FNV-1a spread well even for near-identical IDs
+	const buckets = 1 << 20
+	return float64(hash.Sum64()%buckets)/buckets < rate
+}
+
 func (p *LoggingPlugin) ShouldApply(req *fasthttp.RequestCtx) bool {
 	return true
 }
 
+// AsyncPostProcess reports whether response lines are logged in the
+// background, after the response has been sent
+func (p *LoggingPlugin) AsyncPostProcess() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.asyncPostProcess
+}
+
+// correlate makes sure the request carries a request ID and a W3C trace ID,
+// storing them in the "request_id" and "trace_id" user values so the request
+// and response log lines, and other plugins, share them
+func (p *LoggingPlugin) correlate(ctx *RequestContext) {
+	if requestID, _ := ctx.GetUserValue("request_id"); requestID == nil || requestID == "" {
+		id := ctx.RequestID
+		if id == "" || id == "unknown" {
+			id = ctx.Header("X-Request-ID")
+		}
+		if id == "" {
+			id = uuid.New().String()
+		}
+		ctx.SetUserValue("request_id", id)
+	}
+	
+	if traceID, _ := ctx.GetUserValue("trace_id"); traceID == nil || traceID == "" {
+		ctx.SetUserValue("trace_id", requestTraceID(ctx).String())
+	}
+}
+
+// requestTraceID returns the trace the request belongs to: the one the
+// tracing middleware started, else the incoming traceparent's, else a new one
+func requestTraceID(ctx *RequestContext) tracing.TraceID {
+	if ctx.Context != nil {
+		if sc := tracing.SpanContextFromContext(ctx.Context); sc.TraceID.IsValid() {
+			return sc.TraceID
+		}
+	}
+	if sc, ok := tracing.ParseTraceparent(ctx.Header(tracing.TraceparentHeader)); ok {
+		return sc.TraceID
+	}
+	return tracing.NewTraceID()
+}
+
 func (p *LoggingPlugin) buildRequestFields(ctx *RequestContext) []zap.Field {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
@@ -1131,14 +1912,23 @@ func (p *LoggingPlugin) buildRequestFields(ctx *RequestContext) []zap.Field {
 		zap.String("path", ctx.Path()),
 		zap.String("query", string(ctx.RequestCtx.QueryArgs().QueryString())),
 		zap.String("remote_addr", ctx.RemoteAddr()),
+		zap.String("client_ip", ctx.ClientIP()),
 		zap.String("user_agent", ctx.UserAgent()),
 		zap.Time("timestamp", ctx.StartTime),
 	}
 	
-	// Add request ID if available
+	// Add correlation IDs if available
 	if requestID, exists := ctx.GetUserValue("request_id"); exists {
 		fields = append(fields, zap.Any("request_id", requestID))
 	}
+	if traceID, exists := ctx.GetUserValue("trace_id"); exists {
+		fields = append(fields, zap.Any("trace_id", traceID))
+	}
+	
+	// Add request fingerprint if available
+	if ctx.Fingerprint != "" {
+		fields = append(fields, zap.String("fingerprint", ctx.Fingerprint))
+	}
 	
 	// Add user ID if available
 	if userID, exists := ctx.GetUserValue("user_id"); exists {
@@ -1157,16 +1947,33 @@ func (p *LoggingPlugin) buildRequestFields(ctx *RequestContext) []zap.Field {
 	})
 	fields = append(fields, zap.Any("headers", headers))
 	
+	// Streaming paths only log metadata so the upload is never buffered
+	if ctx.BodyCaptureDisabled() {
+		fields = append(fields,
+			zap.Int("content_length", ctx.RequestCtx.Request.Header.ContentLength()),
+			zap.Bool("body_capture_disabled", true))
+		return fields
+	}
+	
 	// Add request body if enabled
 	if p.logRequestBody && len(ctx.Body()) > 0 {
 		body := ctx.Body()
-		if int64(len(body)) > p.maxBodySize {
+		truncated := int64(len(body)) > p.maxBodySize
+		if truncated {
 			body = body[:p.maxBodySize]
 		}
 		
-		// Try to parse as JSON and filter sensitive fields
+		// Reuse the shared parsed body and filter sensitive fields
 		if strings.Contains(ctx.ContentType(), "application/json") {
-			if filteredBody := p.filterSensitiveJSON(body); filteredBody != nil {
+			var filteredBody []byte
+			if parsed, err := ctx.JSONBody(); err == nil && !truncated {
+				if obj, ok := parsed.(map[string]interface{}); ok {
+					filteredBody, _ = json.Marshal(p.filterSensitiveFields(obj))
+				}
+			} else {
+				filteredBody = p.filterSensitiveJSON(body)
+			}
+			if filteredBody != nil {
 				fields = append(fields, zap.Any("body", json.RawMessage(filteredBody)))
 			} else {
 				fields = append(fields, zap.String("body", string(body)))
@@ -1188,13 +1995,27 @@ func (p *LoggingPlugin) buildResponseFields(ctx *ResponseContext) []zap.Field {
 		zap.String("path", ctx.Path()),
 		zap.Int("status_code", ctx.RequestCtx.Response.StatusCode()),
 		zap.Duration("duration", ctx.ProcessingTime),
-		zap.Int("response_size", len(ctx.RequestCtx.Response.Body())),
 	}
 	
-	// Add request ID if available
+	streaming := ctx.BodyCaptureDisabled()
+	if streaming {
+		fields = append(fields, zap.Int("response_size", ctx.RequestCtx.Response.Header.ContentLength()))
+	} else {
+		fields = append(fields, zap.Int("response_size", len(ctx.ResponseBody)))
+	}
+	
+	// Add correlation IDs if available
 	if requestID, exists := ctx.GetUserValue("request_id"); exists {
 		fields = append(fields, zap.Any("request_id", requestID))
 	}
+	if traceID, exists := ctx.GetUserValue("trace_id"); exists {
+		fields = append(fields, zap.Any("trace_id", traceID))
+	}
+	
+	// Add request fingerprint if available
+	if ctx.Fingerprint != "" {
+		fields = append(fields, zap.String("fingerprint", ctx.Fingerprint))
+	}
 	
 	// Add user ID if available
 	if userID, exists := ctx.GetUserValue("user_id"); exists {
@@ -1207,9 +2028,9 @@ func (p *LoggingPlugin) buildResponseFields(ctx *ResponseContext) []zap.Field {
 	}
 	
 	// Add metrics if enabled
-	if p.includeMetrics {
+	if p.includeMetrics && !streaming {
 		fields = append(fields,
-			zap.Int64("bytes_sent", int64(len(ctx.RequestCtx.Response.Body()))),
+			zap.Int64("bytes_sent", int64(len(ctx.ResponseBody))),
 			zap.Int64("bytes_received", int64(len(ctx.RequestCtx.Request.Body()))))
 	}
 	
@@ -1226,7 +2047,7 @@ func (p *LoggingPlugin) buildResponseFields(ctx *ResponseContext) []zap.Field {
 	fields = append(fields, zap.Any("response_headers", responseHeaders))
 	
 	// Add response body if enabled
-	if p.logResponseBody && len(ctx.ResponseBody) > 0 {
+	if p.logResponseBody && !streaming && len(ctx.ResponseBody) > 0 {
 		body := ctx.ResponseBody
 		if int64(len(body)) > p.maxBodySize {
 			body = body[:p.maxBodySize]
@@ -1249,48 +2070,330 @@ func (p *LoggingPlugin) buildResponseFields(ctx *ResponseContext) []zap.Field {
 }
 
 func (p *LoggingPlugin) filterSensitiveJSON(data []byte) []byte {
-	var obj map[string]interface{}
-	if err := json.Unmarshal(data, &obj); err != nil {
+	return FilterSensitiveJSON(data, p.sensitiveFields)
+}
+
+// filterSensitiveFields returns a copy of obj with sensitive fields redacted,
+// leaving obj untouched since it may be the body shared with other plugins
+func (p *LoggingPlugin) filterSensitiveFields(obj map[string]interface{}) map[string]interface{} {
+	filtered, _ := filterSensitiveValue(obj, p.sensitiveFields)
+	return filtered.(map[string]interface{})
+}
+
+// FilterSensitiveJSON redacts the fields of a JSON object or array, at any
+// depth, whose lower-cased names contain one of sensitiveFields. It returns
+// nil when data is not a JSON object or array, and data itself when no field
+// was redacted. Numbers are decoded with UseNumber so they are re-encoded
+// exactly.
+func FilterSensitiveJSON(data []byte, sensitiveFields map[string]bool) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
 		return nil
 	}
-	
-	p.filterSensitiveFields(obj)
-	
-	filtered, err := json.Marshal(obj)
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil
+	}
+
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		return nil
+	}
+
+	filtered, redacted := filterSensitiveValue(value, sensitiveFields)
+	if !redacted {
+		return data
+	}
+	encoded, err := json.Marshal(filtered)
 	if err != nil {
 		return nil
 	}
-	
-	return filtered
+	return encoded
 }
 
-func (p *LoggingPlugin) filterSensitiveFields(obj map[string]interface{}) {
-	for key, value := range obj {
-		lowerKey := strings.ToLower(key)
-		
-		// Check if this field is sensitive
-		isSensitive := false
-		for sensitiveField := range p.sensitiveFields {
-			if strings.Contains(lowerKey, sensitiveField) {
-				isSensitive = true
-				break
+// filterSensitiveValue returns a copy of value with sensitive fields
+// redacted, and whether any was
+func filterSensitiveValue(value interface{}, sensitiveFields map[string]bool) (interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		filtered := make(map[string]interface{}, len(v))
+		redacted := false
+		for key, item := range v {
+			if isSensitiveField(key, sensitiveFields) {
+				filtered[key] = "[REDACTED]"
+				redacted = true
+				continue
 			}
+			var itemRedacted bool
+			filtered[key], itemRedacted = filterSensitiveValue(item, sensitiveFields)
+			redacted = redacted || itemRedacted
 		}
-		
-		if isSensitive {
-			obj[key] = "[REDACTED]"
-		} else if nestedObj, ok := value.(map[string]interface{}); ok {
-			// Recursively filter nested objects
-			p.filterSensitiveFields(nestedObj)
-		} else if slice, ok := value.([]interface{}); ok {
-			// Handle arrays
-			for _, item := range slice {
-				if nestedObj, ok := item.(map[string]interface{}); ok {
-					p.filterSensitiveFields(nestedObj)
-				}
+		return filtered, redacted
+	case []interface{}:
+		filtered := make([]interface{}, len(v))
+		redacted := false
+		for i, item := range v {
+			var itemRedacted bool
+			filtered[i], itemRedacted = filterSensitiveValue(item, sensitiveFields)
+			redacted = redacted || itemRedacted
+		}
+		return filtered, redacted
+	default:
+		return value, false
+	}
+}
+
+// isSensitiveField reports whether name contains one of sensitiveFields
+func isSensitiveField(name string, sensitiveFields map[string]bool) bool {
+	lowerName := strings.ToLower(name)
+	for sensitiveField := range sensitiveFields {
+		if strings.Contains(lowerName, sensitiveField) {
+			return true
+		}
+	}
+	return false
+}
+
+// =============================================================================
+// VERSIONING PLUGIN - API Version Extraction and Enforcement
+// =============================================================================
+
+// Version sources supported by the VersioningPlugin
+const (
+	VersionSourceAccept = "accept"
+	VersionSourceHeader = "header"
+	VersionSourcePath   = "path"
+)
+
+// APIVersionKey is the user value key holding the requested API version
+const APIVersionKey = "api_version"
+
+var (
+	acceptVersionPattern = regexp.MustCompile(`(?i)application/vnd\.[^;,\s]*?\.?(v\d+(?:\.\d+)?)\+json`)
+	pathVersionPattern   = regexp.MustCompile(`^/(v\d+(?:\.\d+)?)(/|$)`)
+)
+
+// VersioningPlugin extracts the requested API version, rejects unsupported
+// versions and serves version-specific response overrides
+type VersioningPlugin struct {
+	name        string
+	version     string
+	description string
+	logger      *zap.Logger
+	
+	// Configuration
+	source            string
+	header            string
+	defaultVersion    string
+	supportedVersions map[string]bool
+	stripPathPrefix   bool
+	overrides         map[string]map[string]VersionOverride
+	
+	mu sync.RWMutex
+}
+
+// VersioningConfig defines configuration for the VersioningPlugin
+type VersioningConfig struct {
+	Source            string                                `json:"source" yaml:"source"` // "accept", "header" or "path"
+	Header            string                                `json:"header" yaml:"header"`
+	DefaultVersion    string                                `json:"default_version" yaml:"default_version"`
+	SupportedVersions []string                              `json:"supported_versions" yaml:"supported_versions"`
+	StripPathPrefix   bool                                  `json:"strip_path_prefix" yaml:"strip_path_prefix"`
+	Overrides         map[string]map[string]VersionOverride `json:"overrides" yaml:"overrides"` // version -> path -> override
+}
+
+// VersionOverride is a canned response served for a path under a specific version
+type VersionOverride struct {
+	Status      int    `json:"status" yaml:"status"`
+	Body        string `json:"body" yaml:"body"`
+	ContentType string `json:"content_type" yaml:"content_type"`
+}
+
+// NewVersioningPlugin creates a new VersioningPlugin instance
+func NewVersioningPlugin() Plugin {
+	return &VersioningPlugin{
+		name:              "versioning",
+		version:           BuiltinVersion,
+		description:       "API version extraction and enforcement plugin",
+		source:            VersionSourceAccept,
+		header:            "X-API-Version",
+		supportedVersions: make(map[string]bool),
+		overrides:         make(map[string]map[string]VersionOverride),
+	}
+}
+
+func (p *VersioningPlugin) Name() string        { return p.name }
+func (p *VersioningPlugin) Version() string     { return p.version }
+func (p *VersioningPlugin) Description() string { return p.description }
+
+func (p *VersioningPlugin) Init(ctx context.Context, config map[string]interface{}, logger *zap.Logger) error {
+	p.logger = logger.With(zap.String("plugin", p.name))
+	
+	// Parse configuration
+	var versionConfig VersioningConfig
+	if err := mapToStruct(config, &versionConfig); err != nil {
+		return fmt.Errorf("invalid versioning config: %w", err)
+	}
+	
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	
+	// Configure version source
+	if versionConfig.Source != "" {
+		switch versionConfig.Source {
+		case VersionSourceAccept, VersionSourceHeader, VersionSourcePath:
+			p.source = versionConfig.Source
+		default:
+			return fmt.Errorf("invalid versioning source: %s", versionConfig.Source)
+		}
+	}
+	
+	if versionConfig.Header != "" {
+		p.header = versionConfig.Header
+	}
+	
+	// Configure supported versions
+	for _, version := range versionConfig.SupportedVersions {
+		p.supportedVersions[normalizeVersion(version)] = true
+	}
+	
+	p.defaultVersion = normalizeVersion(versionConfig.DefaultVersion)
+	p.stripPathPrefix = versionConfig.StripPathPrefix
+	
+	// Configure version-specific overrides
+	for version, paths := range versionConfig.Overrides {
+		p.overrides[normalizeVersion(version)] = paths
+	}
+	
+	p.logger.Info("Versioning plugin initialized",
+		zap.String("source", p.source),
+		zap.Int("supported_versions", len(p.supportedVersions)),
+		zap.String("default_version", p.defaultVersion),
+		zap.Int("overrides", len(p.overrides)))
+	
+	return nil
+}
+
+func (p *VersioningPlugin) Cleanup(ctx context.Context) error {
+	p.logger.Info("Versioning plugin cleanup completed")
+	return nil
+}
+
+func (p *VersioningPlugin) Priority() Priority {
+	return PriorityNormal
+}
+
+func (p *VersioningPlugin) PreProcess(ctx *RequestContext) (bool, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	
+	version := p.extractVersion(ctx)
+	if version == "" {
+		version = p.defaultVersion
+	}
+	if version == "" {
+		return true, nil
+	}
+	
+	if len(p.supportedVersions) > 0 && !p.supportedVersions[version] {
+		return p.unsupportedVersion(ctx, version)
+	}
+	
+	ctx.SetUserValue(APIVersionKey, version)
+	ctx.RequestCtx.SetUserValue(APIVersionKey, version)
+	ctx.RequestCtx.Response.Header.Set("X-API-Version", version)
+	
+	// Route the request to the unversioned spec path
+	if p.source == VersionSourcePath && p.stripPathPrefix {
+		if match := pathVersionPattern.FindStringSubmatch(ctx.Path()); match != nil {
+			stripped := strings.TrimPrefix(ctx.Path(), "/"+match[1])
+			if stripped == "" {
+				stripped = "/"
 			}
+			ctx.RequestCtx.URI().SetPath(stripped)
+		}
+	}
+	
+	// Serve version-specific override if configured
+	if override, ok := p.overrides[version][ctx.Path()]; ok {
+		status := override.Status
+		if status == 0 {
+			status = fasthttp.StatusOK
+		}
+		contentType := override.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		ctx.RequestCtx.SetStatusCode(status)
+		ctx.RequestCtx.SetContentType(contentType)
+		ctx.RequestCtx.SetBodyString(override.Body)
+		return false, nil
+	}
+	
+	return true, nil
+}
+
+func (p *VersioningPlugin) PostProcess(ctx *ResponseContext) error {
+	// Version handling is done in PreProcess
+	return nil
+}
+
+func (p *VersioningPlugin) ShouldApply(req *fasthttp.RequestCtx) bool {
+	return true
+}
+
+// extractVersion returns the normalized version requested by the client, if any
+func (p *VersioningPlugin) extractVersion(ctx *RequestContext) string {
+	switch p.source {
+	case VersionSourceHeader:
+		return normalizeVersion(ctx.Header(p.header))
+	case VersionSourcePath:
+		if match := pathVersionPattern.FindStringSubmatch(ctx.Path()); match != nil {
+			return normalizeVersion(match[1])
+		}
+	default:
+		if match := acceptVersionPattern.FindStringSubmatch(ctx.Header("Accept")); match != nil {
+			return normalizeVersion(match[1])
 		}
 	}
+	return ""
+}
+
+func (p *VersioningPlugin) unsupportedVersion(ctx *RequestContext, version string) (bool, error) {
+	supported := make([]string, 0, len(p.supportedVersions))
+	for v := range p.supportedVersions {
+		supported = append(supported, v)
+	}
+	sort.Strings(supported)
+	
+	body, _ := json.Marshal(map[string]interface{}{
+		"error":              "unsupported_version",
+		"message":            fmt.Sprintf("API version %s is not supported", version),
+		"supported_versions": supported,
+	})
+	
+	ctx.RequestCtx.SetStatusCode(fasthttp.StatusBadRequest)
+	ctx.RequestCtx.SetContentType("application/json")
+	ctx.RequestCtx.SetBody(body)
+	
+	p.logger.Warn("Unsupported API version",
+		zap.String("version", version),
+		zap.String("path", ctx.Path()),
+		zap.String("method", ctx.Method()))
+	
+	return false, nil
+}
+
+// normalizeVersion lowercases a version and adds the "v" prefix to bare numbers
+func normalizeVersion(version string) string {
+	version = strings.ToLower(strings.TrimSpace(version))
+	if version != "" && version[0] >= '0' && version[0] <= '9' {
+		version = "v" + version
+	}
+	return version
 }
 
 // =============================================================================
@@ -1317,6 +2420,7 @@ func RegisterBuiltinPlugins(registry *PluginRegistry) error {
 		"rate_limit": NewRateLimitPlugin,
 		"cors":       NewCORSPlugin,
 		"logging":    NewLoggingPlugin,
+		"versioning": NewVersioningPlugin,
 	}
 	
 	for name, factory := range plugins {
@@ -1335,6 +2439,7 @@ func GetBuiltinPluginFactories() map[string]PluginFactory {
 		"rate_limit": NewRateLimitPlugin,
 		"cors":       NewCORSPlugin,
 		"logging":    NewLoggingPlugin,
+		"versioning": NewVersioningPlugin,
 	}
 }
 
@@ -2,13 +2,33 @@ package plugins
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestBuiltinPluginRegistration(t *testing.T) {
@@ -16,7 +36,7 @@ func TestBuiltinPluginRegistration(t *testing.T) {
 	err := RegisterBuiltinPlugins(registry)
 	require.NoError(t, err)
 
-	expectedPlugins := []string{"auth", "cors", "logging", "rate_limit"}
+	expectedPlugins := []string{"auth", "cors", "logging", "rate_limit", "versioning"}
 	registeredPlugins := registry.ListFactories()
 
 	assert.ElementsMatch(t, expectedPlugins, registeredPlugins)
@@ -130,6 +150,623 @@ func TestAuthPlugin_PreProcess_Unauthorized(t *testing.T) {
 	assert.Equal(t, fasthttp.StatusUnauthorized, ctx.Response.StatusCode())
 }
 
+func publicKeyPEM(t *testing.T, key crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func authenticateBearer(t *testing.T, plugin *AuthPlugin, token string) (bool, *RequestContext) {
+	t.Helper()
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/protected")
+	ctx.Request.Header.SetMethod("GET")
+	ctx.Request.Header.Set("Authorization", "Bearer "+token)
+
+	requestCtx := &RequestContext{
+		RequestCtx: ctx,
+		StartTime:  time.Now(),
+		Logger:     plugin.logger,
+		Context:    context.Background(),
+		UserValues: make(map[string]interface{}),
+	}
+
+	shouldContinue, err := plugin.PreProcess(requestCtx)
+	require.NoError(t, err)
+	return shouldContinue, requestCtx
+}
+
+func TestAuthPlugin_PreProcess_ES256(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	plugin := NewAuthPlugin().(*AuthPlugin)
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	config := map[string]interface{}{
+		"jwt_method":     "ES256",
+		"jwt_public_key": publicKeyPEM(t, &privateKey.PublicKey),
+	}
+	require.NoError(t, plugin.Init(context.Background(), config, logger))
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"sub": "user123"}).SignedString(privateKey)
+	require.NoError(t, err)
+
+	shouldContinue, requestCtx := authenticateBearer(t, plugin, token)
+	assert.True(t, shouldContinue)
+	userID, _ := requestCtx.GetUserValue("user_id")
+	assert.Equal(t, "user123", userID)
+
+	// A token signed by a different key is rejected
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"sub": "user123"}).SignedString(otherKey)
+	require.NoError(t, err)
+
+	shouldContinue, _ = authenticateBearer(t, plugin, forged)
+	assert.False(t, shouldContinue)
+}
+
+func TestAuthPlugin_PreProcess_EdDSA(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	plugin := NewAuthPlugin().(*AuthPlugin)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	config := map[string]interface{}{
+		"jwt_method":     "EdDSA",
+		"jwt_public_key": publicKeyPEM(t, publicKey),
+	}
+	require.NoError(t, plugin.Init(context.Background(), config, logger))
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"sub": "user456"}).SignedString(privateKey)
+	require.NoError(t, err)
+
+	shouldContinue, requestCtx := authenticateBearer(t, plugin, token)
+	assert.True(t, shouldContinue)
+	userID, _ := requestCtx.GetUserValue("user_id")
+	assert.Equal(t, "user456", userID)
+}
+
+func authRequestWithHeaders(plugin *AuthPlugin, path string, headers map[string]string) (*RequestContext, bool, error) {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI(path)
+	ctx.Request.Header.SetMethod("GET")
+	for name, value := range headers {
+		ctx.Request.Header.Set(name, value)
+	}
+
+	requestCtx := &RequestContext{
+		RequestCtx: ctx,
+		StartTime:  time.Now(),
+		Logger:     plugin.logger,
+		Context:    context.Background(),
+		UserValues: make(map[string]interface{}),
+	}
+	shouldContinue, err := plugin.PreProcess(requestCtx)
+	return requestCtx, shouldContinue, err
+}
+
+func TestAuthPlugin_ForwardHeaders(t *testing.T) {
+	plugin := NewAuthPlugin().(*AuthPlugin)
+	require.NoError(t, plugin.Init(context.Background(), map[string]interface{}{
+		"jwt_secret":       "test-secret",
+		"api_keys":         map[string]interface{}{"key-1": "service-a"},
+		"public_endpoints": []interface{}{"/health"},
+		"forward_headers": map[string]interface{}{
+			"user_id":     "X-User-Id",
+			"auth_method": "X-Auth-Method",
+			"scopes":      "X-User-Scopes",
+		},
+	}, zaptest.NewLogger(t)))
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "user123",
+		"scope": "orders:read orders:write",
+	}).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	// A client-sent identity is replaced by the one from the token
+	ctx, shouldContinue, err := authRequestWithHeaders(plugin, "/orders", map[string]string{
+		"Authorization": "Bearer " + token,
+		"X-User-Id":     "admin",
+		"X-User-Scopes": "everything",
+	})
+	require.NoError(t, err)
+	require.True(t, shouldContinue)
+	headers := &ctx.RequestCtx.Request.Header
+	assert.Equal(t, "user123", string(headers.Peek("X-User-Id")))
+	assert.Equal(t, "jwt", string(headers.Peek("X-Auth-Method")))
+	assert.Equal(t, "orders:read orders:write", string(headers.Peek("X-User-Scopes")))
+
+	// Identities without scopes leave the scopes header unset
+	ctx, shouldContinue, err = authRequestWithHeaders(plugin, "/orders", map[string]string{
+		"Authorization": "key-1",
+		"X-User-Scopes": "everything",
+	})
+	require.NoError(t, err)
+	require.True(t, shouldContinue)
+	headers = &ctx.RequestCtx.Request.Header
+	assert.Equal(t, "service-a", string(headers.Peek("X-User-Id")))
+	assert.Equal(t, "api_key", string(headers.Peek("X-Auth-Method")))
+	assert.Nil(t, headers.Peek("X-User-Scopes"))
+
+	// Public endpoints never see a spoofed identity
+	ctx, shouldContinue, err = authRequestWithHeaders(plugin, "/health", map[string]string{"X-User-Id": "admin"})
+	require.NoError(t, err)
+	require.True(t, shouldContinue)
+	assert.Nil(t, ctx.RequestCtx.Request.Header.Peek("X-User-Id"))
+}
+
+func TestAuthPlugin_ForwardHeadersUnknownField(t *testing.T) {
+	config := map[string]interface{}{
+		"jwt_secret":      "test-secret",
+		"forward_headers": map[string]interface{}{"email": "X-User-Email"},
+	}
+
+	err := NewAuthPlugin().Init(context.Background(), config, zaptest.NewLogger(t))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown forward_headers field")
+
+	result := GetConfigRegistry().ValidateConfig("auth", config)
+	require.False(t, result.Valid)
+	fields := make([]string, 0, len(result.Errors))
+	for _, validationErr := range result.Errors {
+		fields = append(fields, validationErr.Field)
+	}
+	assert.Contains(t, fields, "forward_headers.email")
+}
+
+// newCookieAuthPlugin returns an auth plugin accepting the "key-1" API key
+// and HS256 tokens, with extra cookie settings from config
+func newCookieAuthPlugin(t *testing.T, config map[string]interface{}) (*AuthPlugin, string) {
+	t.Helper()
+	config["jwt_secret"] = "test-secret"
+	config["api_keys"] = map[string]interface{}{"key-1": "service-a"}
+
+	plugin := NewAuthPlugin().(*AuthPlugin)
+	require.NoError(t, plugin.Init(context.Background(), config, zaptest.NewLogger(t)))
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user123"}).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+	return plugin, token
+}
+
+func TestAuthPlugin_SeparateCredentialCookies(t *testing.T) {
+	plugin, token := newCookieAuthPlugin(t, map[string]interface{}{
+		"jwt_cookie":     "session",
+		"api_key_cookie": "api_key",
+	})
+
+	tests := []struct {
+		name   string
+		cookie string
+		want   string
+	}{
+		{"jwt in jwt cookie", "session=" + token, "jwt"},
+		{"api key in api key cookie", "api_key=key-1", "api_key"},
+		{"jwt in api key cookie", "api_key=" + token, ""},
+		{"api key in jwt cookie", "session=key-1", ""},
+		{"legacy cookie ignored", "auth_token=key-1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, shouldContinue, err := authRequestWithHeaders(plugin, "/orders", map[string]string{"Cookie": tt.cookie})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want != "", shouldContinue)
+			method, _ := ctx.GetUserValue("auth_method")
+			if tt.want == "" {
+				assert.Nil(t, method)
+			} else {
+				assert.Equal(t, tt.want, method)
+			}
+		})
+	}
+}
+
+func TestAuthPlugin_AuthCookieFallback(t *testing.T) {
+	plugin, token := newCookieAuthPlugin(t, map[string]interface{}{
+		"auth_cookie": "credentials",
+		"jwt_cookie":  "session",
+	})
+
+	// The unset API key cookie falls back to auth_cookie
+	_, shouldContinue, err := authRequestWithHeaders(plugin, "/orders", map[string]string{"Cookie": "credentials=key-1"})
+	require.NoError(t, err)
+	assert.True(t, shouldContinue)
+
+	// ...while JWTs are only read from their own cookie
+	_, shouldContinue, err = authRequestWithHeaders(plugin, "/orders", map[string]string{"Cookie": "credentials=" + token})
+	require.NoError(t, err)
+	assert.False(t, shouldContinue)
+
+	_, shouldContinue, err = authRequestWithHeaders(plugin, "/orders", map[string]string{"Cookie": "session=" + token})
+	require.NoError(t, err)
+	assert.True(t, shouldContinue)
+}
+
+func TestAuthPlugin_OptionalEndpoints(t *testing.T) {
+	plugin, token := newCookieAuthPlugin(t, map[string]interface{}{
+		"optional_endpoints": []interface{}{"/feed"},
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		headers  map[string]string
+		proceeds bool
+		userID   interface{}
+	}{
+		{"valid jwt is honored", "/feed", map[string]string{"Authorization": "Bearer " + token}, true, "user123"},
+		{"valid api key is honored", "/feed", map[string]string{"Authorization": "key-1"}, true, "service-a"},
+		{"no credentials proceed anonymously", "/feed", nil, true, nil},
+		{"invalid jwt is rejected", "/feed", map[string]string{"Authorization": "Bearer not-a-jwt"}, false, nil},
+		{"invalid api key is rejected", "/feed?api_key=wrong", nil, false, nil},
+		{"other endpoints still require credentials", "/orders", nil, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestCtx, shouldContinue, err := authRequestWithHeaders(plugin, tt.path, tt.headers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.proceeds, shouldContinue)
+
+			userID, _ := requestCtx.GetUserValue("user_id")
+			assert.Equal(t, tt.userID, userID)
+			if !tt.proceeds {
+				assert.Equal(t, fasthttp.StatusUnauthorized, requestCtx.RequestCtx.Response.StatusCode())
+			}
+		})
+	}
+}
+
+func TestAuthPlugin_CORSPreflightNeedsNoCredentials(t *testing.T) {
+	plugin, _ := newCookieAuthPlugin(t, map[string]interface{}{})
+
+	request := func(headers map[string]string) (*RequestContext, bool) {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/orders")
+		ctx.Request.Header.SetMethod("OPTIONS")
+		for name, value := range headers {
+			ctx.Request.Header.Set(name, value)
+		}
+		requestCtx := &RequestContext{
+			RequestCtx: ctx,
+			StartTime:  time.Now(),
+			Logger:     plugin.logger,
+			Context:    context.Background(),
+			UserValues: make(map[string]interface{}),
+		}
+		shouldContinue, err := plugin.PreProcess(requestCtx)
+		require.NoError(t, err)
+		return requestCtx, shouldContinue
+	}
+
+	_, shouldContinue := request(map[string]string{
+		"Origin":                        "https://app.example.com",
+		"Access-Control-Request-Method": "POST",
+	})
+	assert.True(t, shouldContinue)
+
+	// A plain OPTIONS request is not a preflight and still needs credentials
+	requestCtx, shouldContinue := request(map[string]string{"Origin": "https://app.example.com"})
+	assert.False(t, shouldContinue)
+	assert.Equal(t, fasthttp.StatusUnauthorized, requestCtx.RequestCtx.Response.StatusCode())
+}
+
+func TestAuthPlugin_PreProcess_AudienceArray(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	plugin := NewAuthPlugin().(*AuthPlugin)
+
+	config := map[string]interface{}{
+		"jwt_secret":    "test-secret",
+		"jwt_audience":  "api",
+		"jwt_audiences": []interface{}{"web"},
+	}
+	require.NoError(t, plugin.Init(context.Background(), config, logger))
+
+	sign := func(aud interface{}) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user123", "aud": aud}).SignedString([]byte("test-secret"))
+		require.NoError(t, err)
+		return token
+	}
+
+	for _, aud := range []interface{}{"api", "web", []string{"other", "api"}, []string{"web"}} {
+		shouldContinue, _ := authenticateBearer(t, plugin, sign(aud))
+		assert.True(t, shouldContinue, "aud %v", aud)
+	}
+	for _, aud := range []interface{}{"other", []string{"other", "another"}, []string{}} {
+		shouldContinue, _ := authenticateBearer(t, plugin, sign(aud))
+		assert.False(t, shouldContinue, "aud %v", aud)
+	}
+}
+
+func TestAuthPlugin_PreProcess_MultipleIssuers(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	plugin := NewAuthPlugin().(*AuthPlugin)
+
+	config := map[string]interface{}{
+		"jwt_secret":  "test-secret",
+		"jwt_issuers": []interface{}{"https://idp-a.example.com", "https://idp-b.example.com"},
+	}
+	require.NoError(t, plugin.Init(context.Background(), config, logger))
+
+	for iss, accepted := range map[string]bool{
+		"https://idp-a.example.com": true,
+		"https://idp-b.example.com": true,
+		"https://idp-c.example.com": false,
+	} {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user123", "iss": iss}).SignedString([]byte("test-secret"))
+		require.NoError(t, err)
+
+		shouldContinue, _ := authenticateBearer(t, plugin, token)
+		assert.Equal(t, accepted, shouldContinue, "iss %s", iss)
+	}
+
+	// A token without an issuer is rejected once issuers are configured
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user123"}).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+	shouldContinue, _ := authenticateBearer(t, plugin, token)
+	assert.False(t, shouldContinue)
+}
+
+func TestAuthPlugin_Init_InvalidPublicKey(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	err := NewAuthPlugin().Init(context.Background(), map[string]interface{}{
+		"jwt_method": "ES256",
+	}, logger)
+	assert.Error(t, err)
+
+	// An Ed25519 key cannot verify ECDSA signatures
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	err = NewAuthPlugin().Init(context.Background(), map[string]interface{}{
+		"jwt_method":     "ES256",
+		"jwt_public_key": publicKeyPEM(t, edKey.Public()),
+	}, logger)
+	assert.Error(t, err)
+}
+
+func TestAuthPlugin_Init_SecretFile(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	secret := "0123456789abcdef0123456789abcdef"
+
+	// Secret mounts usually end with a newline
+	secretFile := filepath.Join(t.TempDir(), "jwt-secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte(secret+"\n"), 0600))
+
+	inline := NewAuthPlugin().(*AuthPlugin)
+	require.NoError(t, inline.Init(context.Background(), map[string]interface{}{
+		"jwt_secret": secret,
+		"jwt_method": "HS256",
+	}, logger))
+
+	fromFile := NewAuthPlugin().(*AuthPlugin)
+	require.NoError(t, fromFile.Init(context.Background(), map[string]interface{}{
+		"jwt_secret":      "ignored-when-a-file-is-set",
+		"jwt_secret_file": secretFile,
+		"jwt_method":      "HS256",
+	}, logger))
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user123"}).SignedString([]byte(secret))
+	require.NoError(t, err)
+
+	for _, plugin := range []*AuthPlugin{inline, fromFile} {
+		shouldContinue, requestCtx := authenticateBearer(t, plugin, token)
+		assert.True(t, shouldContinue)
+		userID, _ := requestCtx.GetUserValue("user_id")
+		assert.Equal(t, "user123", userID)
+	}
+}
+
+func TestAuthPlugin_Init_PublicKeyFile(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	plugin := NewAuthPlugin().(*AuthPlugin)
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "jwt.pub")
+	require.NoError(t, os.WriteFile(keyFile, []byte(publicKeyPEM(t, &privateKey.PublicKey)), 0600))
+
+	require.NoError(t, plugin.Init(context.Background(), map[string]interface{}{
+		"jwt_method":          "ES256",
+		"jwt_public_key_file": keyFile,
+	}, logger))
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"sub": "user123"}).SignedString(privateKey)
+	require.NoError(t, err)
+
+	shouldContinue, _ := authenticateBearer(t, plugin, token)
+	assert.True(t, shouldContinue)
+}
+
+func TestAuthPlugin_Init_KeyFileErrors(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	dir := t.TempDir()
+
+	err := NewAuthPlugin().Init(context.Background(), map[string]interface{}{
+		"jwt_secret_file": filepath.Join(dir, "missing"),
+	}, logger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "jwt_secret_file")
+
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("\n"), 0600))
+	err = NewAuthPlugin().Init(context.Background(), map[string]interface{}{
+		"jwt_secret_file": empty,
+	}, logger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty")
+
+	notPEM := filepath.Join(dir, "not-pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a key"), 0600))
+	err = NewAuthPlugin().Init(context.Background(), map[string]interface{}{
+		"jwt_method":          "RS256",
+		"jwt_public_key_file": notPEM,
+	}, logger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse jwt_public_key")
+}
+
+// newIntrospectionServer starts a stub RFC 7662 endpoint that reports the
+// given tokens as active and counts the requests it receives
+func newIntrospectionServer(t *testing.T, active map[string]map[string]interface{}) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "gateway" || clientSecret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		response := map[string]interface{}{"active": false}
+		if claims, ok := active[r.PostFormValue("token")]; ok {
+			response = map[string]interface{}{"active": true}
+			for key, value := range claims {
+				response[key] = value
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestAuthPlugin_PreProcess_Introspection(t *testing.T) {
+	server, calls := newIntrospectionServer(t, map[string]map[string]interface{}{
+		"opaque-token": {"sub": "user789", "username": "alice", "exp": time.Now().Add(time.Hour).Unix()},
+		"expiring":     {"sub": "user790", "exp": time.Now().Add(2 * time.Second).Unix()},
+	})
+
+	plugin := NewAuthPlugin().(*AuthPlugin)
+	require.NoError(t, plugin.Init(context.Background(), map[string]interface{}{
+		"introspection_url":           server.URL,
+		"introspection_client_id":     "gateway",
+		"introspection_client_secret": "s3cret",
+	}, zaptest.NewLogger(t)))
+
+	shouldContinue, requestCtx := authenticateBearer(t, plugin, "opaque-token")
+	assert.True(t, shouldContinue)
+	userID, _ := requestCtx.GetUserValue("user_id")
+	assert.Equal(t, "user789", userID)
+	username, _ := requestCtx.GetUserValue("username")
+	assert.Equal(t, "alice", username)
+	method, _ := requestCtx.GetUserValue("auth_method")
+	assert.Equal(t, "introspection", method)
+
+	// Active results are cached
+	shouldContinue, _ = authenticateBearer(t, plugin, "opaque-token")
+	assert.True(t, shouldContinue)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	// ...but never past the token's expiry
+	shouldContinue, _ = authenticateBearer(t, plugin, "expiring")
+	assert.True(t, shouldContinue)
+	cached, ok := plugin.introspector.cached("expiring", time.Now())
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(2*time.Second), cached.expiresAt, 2*time.Second)
+
+	// Inactive tokens are rejected and the rejection is cached briefly
+	shouldContinue, _ = authenticateBearer(t, plugin, "revoked-token")
+	assert.False(t, shouldContinue)
+	shouldContinue, _ = authenticateBearer(t, plugin, "revoked-token")
+	assert.False(t, shouldContinue)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestTokenIntrospector_EvictsLeastRecentlyUsed(t *testing.T) {
+	server, calls := newIntrospectionServer(t, map[string]map[string]interface{}{
+		"a": {"sub": "a"}, "b": {"sub": "b"}, "c": {"sub": "c"},
+	})
+	introspector, err := newTokenIntrospector(AuthConfig{
+		IntrospectionURL:          server.URL,
+		IntrospectionClientID:     "gateway",
+		IntrospectionClientSecret: "s3cret",
+	})
+	require.NoError(t, err)
+	introspector.maxEntries = 2
+
+	for _, token := range []string{"a", "b", "a", "c"} {
+		result, err := introspector.introspect(token)
+		require.NoError(t, err)
+		assert.True(t, result.active)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	assert.Equal(t, 2, introspector.lru.Len())
+
+	// "b" was used least recently, so "c" pushed it out
+	_, err = introspector.introspect("a")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	_, err = introspector.introspect("b")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+	assert.Len(t, introspector.cache, 2)
+}
+
+func TestTokenIntrospector_SharesConcurrentQueries(t *testing.T) {
+	server, calls := newIntrospectionServer(t, map[string]map[string]interface{}{
+		"opaque-token": {"sub": "user789"},
+	})
+	introspector, err := newTokenIntrospector(AuthConfig{
+		IntrospectionURL:          server.URL,
+		IntrospectionClientID:     "gateway",
+		IntrospectionClientSecret: "s3cret",
+	})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for n := 0; n < 20; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := introspector.introspect("opaque-token")
+			if assert.NoError(t, err) {
+				assert.Equal(t, "user789", result.userID)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Callers either waited on the one query or found its cached result
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestAuthPlugin_PreProcess_IntrospectionFailsClosed(t *testing.T) {
+	// Wrong client credentials make every introspection request fail
+	server, calls := newIntrospectionServer(t, map[string]map[string]interface{}{
+		"opaque-token": {"sub": "user789"},
+	})
+
+	plugin := NewAuthPlugin().(*AuthPlugin)
+	require.NoError(t, plugin.Init(context.Background(), map[string]interface{}{
+		"introspection_url":       server.URL,
+		"introspection_client_id": "gateway",
+	}, zaptest.NewLogger(t)))
+
+	for i := 0; i < 3; i++ {
+		shouldContinue, _ := authenticateBearer(t, plugin, "opaque-token")
+		assert.False(t, shouldContinue)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestAuthPlugin_Init_InvalidIntrospectionURL(t *testing.T) {
+	err := NewAuthPlugin().Init(context.Background(), map[string]interface{}{
+		"introspection_url": "introspect.example.com/token",
+	}, zaptest.NewLogger(t))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "introspection_url")
+}
+
 func TestRateLimitPlugin_Init(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	plugin := NewRateLimitPlugin()
@@ -203,6 +840,65 @@ func TestCORSPlugin_PreProcess_Preflight(t *testing.T) {
 	assert.Contains(t, string(ctx.Response.Header.Peek("Access-Control-Allow-Methods")), "POST")
 }
 
+func TestCORSPlugin_WildcardSubdomainOrigins(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	plugin := NewCORSPlugin().(*CORSPlugin)
+
+	config := map[string]interface{}{
+		"allow_origins":     []string{"*.example.com", "https://*.tenant.io:8443"},
+		"allow_credentials": true,
+	}
+	require.NoError(t, plugin.Init(context.Background(), config, logger))
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"http://app.example.com", true},
+		{"https://a.b.example.com", true},
+		{"https://APP.Example.com", true},
+		{"https://example.com", false},
+		{"https://evil.com", false},
+		{"https://example.com.attacker.com", false},
+		{"https://attackerexample.com", false},
+		{"https://app.example.com:8080", false},
+		{"ftp://app.example.com", false},
+		{"https://acme.tenant.io:8443", true},
+		{"http://acme.tenant.io:8443", false},
+		{"https://acme.tenant.io", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, plugin.isOriginAllowed(tt.origin))
+		})
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/test")
+	ctx.Request.Header.SetMethod("GET")
+	ctx.Request.Header.Set("Origin", "https://app.example.com")
+
+	shouldContinue, err := plugin.PreProcess(&RequestContext{
+		RequestCtx: ctx,
+		StartTime:  time.Now(),
+		Logger:     logger,
+		Context:    context.Background(),
+	})
+	require.NoError(t, err)
+	assert.True(t, shouldContinue)
+	assert.Equal(t, "https://app.example.com", string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
+	assert.Equal(t, "Origin", string(ctx.Response.Header.Peek("Vary")))
+}
+
+func TestParseWildcardOrigin_Invalid(t *testing.T) {
+	for _, entry := range []string{"https://app.example.com", "*", "*.", "*.*.example.com", "https://*.example.com:bad:port", "*..example.com"} {
+		_, ok := parseWildcardOrigin(entry)
+		assert.False(t, ok, entry)
+	}
+}
+
 func TestLoggingPlugin_Init(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	plugin := NewLoggingPlugin()
@@ -223,10 +919,145 @@ func TestLoggingPlugin_Init(t *testing.T) {
 	assert.Equal(t, BuiltinVersion, plugin.Version())
 }
 
+func newVersioningRequestContext(path string, headers map[string]string) *RequestContext {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI(path)
+	ctx.Request.Header.SetMethod("GET")
+	for name, value := range headers {
+		ctx.Request.Header.Set(name, value)
+	}
+
+	return &RequestContext{
+		RequestCtx: ctx,
+		StartTime:  time.Now(),
+		Context:    context.Background(),
+	}
+}
+
+func TestVersioningPlugin_ExtractsVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]interface{}
+		path     string
+		headers  map[string]string
+		expected string
+		newPath  string
+	}{
+		{
+			name:     "accept media type",
+			config:   map[string]interface{}{"source": "accept"},
+			path:     "/users",
+			headers:  map[string]string{"Accept": "application/vnd.api.v2+json"},
+			expected: "v2",
+			newPath:  "/users",
+		},
+		{
+			name:     "custom header",
+			config:   map[string]interface{}{"source": "header", "header": "Api-Version"},
+			path:     "/users",
+			headers:  map[string]string{"Api-Version": "3"},
+			expected: "v3",
+			newPath:  "/users",
+		},
+		{
+			name:     "path prefix stripped",
+			config:   map[string]interface{}{"source": "path", "strip_path_prefix": true},
+			path:     "/v1/users",
+			expected: "v1",
+			newPath:  "/users",
+		},
+		{
+			name:     "default version",
+			config:   map[string]interface{}{"source": "accept", "default_version": "v1"},
+			path:     "/users",
+			headers:  map[string]string{"Accept": "application/json"},
+			expected: "v1",
+			newPath:  "/users",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := NewVersioningPlugin().(*VersioningPlugin)
+			require.NoError(t, plugin.Init(context.Background(), tt.config, zaptest.NewLogger(t)))
+
+			requestCtx := newVersioningRequestContext(tt.path, tt.headers)
+			shouldContinue, err := plugin.PreProcess(requestCtx)
+			require.NoError(t, err)
+			assert.True(t, shouldContinue)
+
+			version, ok := requestCtx.GetUserValue(APIVersionKey)
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, version)
+			assert.Equal(t, tt.expected, requestCtx.RequestCtx.UserValue(APIVersionKey))
+			assert.Equal(t, tt.newPath, requestCtx.Path())
+		})
+	}
+}
+
+func TestVersioningPlugin_RejectsUnsupportedVersion(t *testing.T) {
+	plugin := NewVersioningPlugin().(*VersioningPlugin)
+	err := plugin.Init(context.Background(), map[string]interface{}{
+		"source":             "path",
+		"supported_versions": []string{"v1", "v2"},
+	}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	requestCtx := newVersioningRequestContext("/v9/users", nil)
+	shouldContinue, err := plugin.PreProcess(requestCtx)
+	require.NoError(t, err)
+	assert.False(t, shouldContinue)
+
+	ctx := requestCtx.RequestCtx
+	assert.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"error":"unsupported_version","message":"API version v9 is not supported","supported_versions":["v1","v2"]}`, string(ctx.Response.Body()))
+
+	// Supported versions pass through
+	requestCtx = newVersioningRequestContext("/v2/users", nil)
+	shouldContinue, err = plugin.PreProcess(requestCtx)
+	require.NoError(t, err)
+	assert.True(t, shouldContinue)
+}
+
+func TestVersioningPlugin_ServesOverride(t *testing.T) {
+	plugin := NewVersioningPlugin().(*VersioningPlugin)
+	err := plugin.Init(context.Background(), map[string]interface{}{
+		"source": "header",
+		"overrides": map[string]interface{}{
+			"v1": map[string]interface{}{
+				"/users": map[string]interface{}{
+					"status": 410,
+					"body":   `{"error":"gone"}`,
+				},
+			},
+		},
+	}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	requestCtx := newVersioningRequestContext("/users", map[string]string{"X-API-Version": "v1"})
+	shouldContinue, err := plugin.PreProcess(requestCtx)
+	require.NoError(t, err)
+	assert.False(t, shouldContinue)
+	assert.Equal(t, fasthttp.StatusGone, requestCtx.RequestCtx.Response.StatusCode())
+	assert.Equal(t, `{"error":"gone"}`, string(requestCtx.RequestCtx.Response.Body()))
+
+	// Other versions reach the mock handler
+	requestCtx = newVersioningRequestContext("/users", map[string]string{"X-API-Version": "v2"})
+	shouldContinue, err = plugin.PreProcess(requestCtx)
+	require.NoError(t, err)
+	assert.True(t, shouldContinue)
+}
+
+func TestVersioningPlugin_InvalidSource(t *testing.T) {
+	plugin := NewVersioningPlugin()
+	err := plugin.Init(context.Background(), map[string]interface{}{"source": "cookie"}, zaptest.NewLogger(t))
+	assert.Error(t, err)
+}
+
 func TestGetBuiltinPluginFactories(t *testing.T) {
 	factories := GetBuiltinPluginFactories()
 	
-	expectedPlugins := []string{"auth", "cors", "logging", "rate_limit"}
+	expectedPlugins := []string{"auth", "cors", "logging", "rate_limit", "versioning"}
 	
 	assert.Len(t, factories, len(expectedPlugins))
 	
@@ -251,6 +1082,7 @@ func TestCreateBuiltinPlugin(t *testing.T) {
 		{"Valid rate_limit plugin", "rate_limit", false},
 		{"Valid cors plugin", "cors", false},
 		{"Valid logging plugin", "logging", false},
+		{"Valid versioning plugin", "versioning", false},
 		{"Invalid plugin", "nonexistent", true},
 	}
 
@@ -273,11 +1105,11 @@ func TestCreateBuiltinPlugin(t *testing.T) {
 func TestGetBuiltinPluginNames(t *testing.T) {
 	names := GetBuiltinPluginNames()
 	
-	expectedNames := []string{"auth", "cors", "logging", "rate_limit"}
+	expectedNames := []string{"auth", "cors", "logging", "rate_limit", "versioning"}
 	assert.ElementsMatch(t, expectedNames, names)
 	
 	// Check that names are sorted
-	assert.Equal(t, []string{"auth", "cors", "logging", "rate_limit"}, names)
+	assert.Equal(t, []string{"auth", "cors", "logging", "rate_limit", "versioning"}, names)
 }
 
 func TestMapToStruct(t *testing.T) {
@@ -317,4 +1149,400 @@ func TestPriorityOrdering(t *testing.T) {
 	
 	// Logging should have lowest priority (highest value)
 	assert.Equal(t, PriorityLow, loggingPlugin.Priority())
-}
\ No newline at end of file
+}
+func newRateLimitTestContext(ip string) *RequestContext {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/limited")
+	ctx.Request.Header.SetMethod("GET")
+	ctx.SetRemoteAddr(&net.TCPAddr{IP: net.ParseIP(ip), Port: 40000})
+
+	return &RequestContext{
+		RequestCtx: ctx,
+		StartTime:  time.Now(),
+		Context:    context.Background(),
+		UserValues: make(map[string]interface{}),
+	}
+}
+
+// exhaustRateLimit sends requests from one IP until the plugin rejects one
+// and returns the rejected request
+func exhaustRateLimit(t *testing.T, plugin *RateLimitPlugin, accept string) *RequestContext {
+	t.Helper()
+	for i := 0; i < 10; i++ {
+		requestCtx := newRateLimitTestContext("10.0.0.1")
+		if accept != "" {
+			requestCtx.RequestCtx.Request.Header.Set("Accept", accept)
+		}
+		shouldContinue, err := plugin.PreProcess(requestCtx)
+		require.NoError(t, err)
+		if !shouldContinue {
+			return requestCtx
+		}
+	}
+	t.Fatal("rate limit was never exceeded")
+	return nil
+}
+
+func TestRateLimitPlugin_CustomResponseBody(t *testing.T) {
+	plugin := NewRateLimitPlugin().(*RateLimitPlugin)
+	require.NoError(t, plugin.Init(context.Background(), map[string]interface{}{
+		"ip_requests_per_second": 1.0,
+		"ip_burst":               1,
+		"response_body":          "<error><type>{limit_type}</type><retry>{retry_after}</retry></error>",
+		"response_content_type":  "application/xml",
+	}, zaptest.NewLogger(t)))
+
+	response := &exhaustRateLimit(t, plugin, "application/json").RequestCtx.Response
+	assert.Equal(t, fasthttp.StatusTooManyRequests, response.StatusCode())
+	assert.Equal(t, "application/xml", string(response.Header.ContentType()))
+	assert.Equal(t, "<error><type>ip</type><retry>1</retry></error>", string(response.Body()))
+	assert.Equal(t, "1", string(response.Header.Peek("Retry-After")))
+}
+
+func TestRateLimitPlugin_DefaultResponseNegotiation(t *testing.T) {
+	for _, tc := range []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", "application/json", string(rateLimitResponse)},
+		{"application/json", "application/json", string(rateLimitResponse)},
+		{"text/plain", "text/plain; charset=utf-8", "Too many requests"},
+		{"application/json;q=0.5, text/plain", "text/plain; charset=utf-8", "Too many requests"},
+		{"text/html", "application/json", string(rateLimitResponse)},
+	} {
+		plugin := NewRateLimitPlugin().(*RateLimitPlugin)
+		require.NoError(t, plugin.Init(context.Background(), map[string]interface{}{
+			"ip_requests_per_second": 1.0,
+			"ip_burst":               1,
+		}, zaptest.NewLogger(t)))
+
+		response := &exhaustRateLimit(t, plugin, tc.accept).RequestCtx.Response
+		assert.Equal(t, tc.contentType, string(response.Header.ContentType()), tc.accept)
+		assert.Equal(t, tc.body, string(response.Body()), tc.accept)
+	}
+}
+
+func TestRateLimitPlugin_PostProcessReusesLimiter(t *testing.T) {
+	plugin := NewRateLimitPlugin().(*RateLimitPlugin)
+	err := plugin.Init(context.Background(), map[string]interface{}{
+		"ip_requests_per_second": 10.0,
+		"ip_burst":               5,
+		"exempt_ips":             []string{"10.0.0.9"},
+	}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	requestCtx := newRateLimitTestContext("10.0.0.1")
+	shouldContinue, err := plugin.PreProcess(requestCtx)
+	require.NoError(t, err)
+	require.True(t, shouldContinue)
+	require.Len(t, plugin.ipLimiters, 1)
+
+	require.NoError(t, plugin.PostProcess(&ResponseContext{RequestContext: requestCtx}))
+	assert.Len(t, plugin.ipLimiters, 1)
+	assert.Equal(t, "5", string(requestCtx.RequestCtx.Response.Header.Peek("X-RateLimit-Limit")))
+	assert.Equal(t, "4", string(requestCtx.RequestCtx.Response.Header.Peek("X-RateLimit-Remaining")))
+
+	// Requests PreProcess never rate-limited must not create limiter entries
+	for _, ip := range []string{"10.0.0.9", "10.0.0.2"} {
+		requestCtx := newRateLimitTestContext(ip)
+		if ip == "10.0.0.9" {
+			_, err := plugin.PreProcess(requestCtx)
+			require.NoError(t, err)
+		}
+		require.NoError(t, plugin.PostProcess(&ResponseContext{RequestContext: requestCtx}))
+		assert.Empty(t, requestCtx.RequestCtx.Response.Header.Peek("X-RateLimit-Limit"))
+	}
+	assert.Len(t, plugin.ipLimiters, 1)
+}
+
+func TestRateLimitPlugin_IgnoresSpoofedForwardedFor(t *testing.T) {
+	plugin := NewRateLimitPlugin().(*RateLimitPlugin)
+	require.NoError(t, plugin.Init(context.Background(), map[string]interface{}{
+		"ip_requests_per_second": 1.0,
+		"ip_burst":               1,
+		"exempt_ips":             []string{"10.0.0.9"},
+	}, zaptest.NewLogger(t)))
+
+	spoofed := func(trusted TrustedProxies) *RequestContext {
+		requestCtx := newRateLimitTestContext("203.0.113.5")
+		requestCtx.RequestCtx.Request.Header.Set("X-Forwarded-For", "10.0.0.9")
+		requestCtx.RequestCtx.Request.Header.Set("X-Real-IP", "10.0.0.9")
+		requestCtx.trustedProxies = trusted
+		return requestCtx
+	}
+
+	// An untrusted peer can neither claim the exemption nor dodge its limit
+	first, err := plugin.PreProcess(spoofed(TrustedProxies{}))
+	require.NoError(t, err)
+	assert.True(t, first)
+	second, err := plugin.PreProcess(spoofed(TrustedProxies{}))
+	require.NoError(t, err)
+	assert.False(t, second)
+	assert.Contains(t, plugin.ipLimiters, "203.0.113.5")
+	assert.NotContains(t, plugin.ipLimiters, "10.0.0.9")
+
+	// The same headers from a trusted proxy identify the client
+	_, proxyNet, err := net.ParseCIDR("203.0.113.0/24")
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		shouldContinue, err := plugin.PreProcess(spoofed(TrustedProxies{Networks: []*net.IPNet{proxyNet}}))
+		require.NoError(t, err)
+		assert.True(t, shouldContinue)
+	}
+}
+
+func BenchmarkRateLimitPlugin_PostProcess(b *testing.B) {
+	plugin := NewRateLimitPlugin().(*RateLimitPlugin)
+	err := plugin.Init(context.Background(), map[string]interface{}{
+		"ip_requests_per_second": 1000000.0,
+	}, zap.NewNop())
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		// Each goroutine owns its response; all share the plugin's limiter map
+		requestCtx := newRateLimitTestContext("10.0.0.1")
+		if _, err := plugin.PreProcess(requestCtx); err != nil {
+			b.Error(err)
+			return
+		}
+		responseCtx := &ResponseContext{RequestContext: requestCtx}
+
+		for pb.Next() {
+			_ = plugin.PostProcess(responseCtx)
+		}
+	})
+}
+
+// logCorrelatedRequest runs a request through the logging plugin and returns
+// the request and response log entries
+func logCorrelatedRequest(t *testing.T, headers map[string]string) (*RequestContext, observer.LoggedEntry, observer.LoggedEntry) {
+	t.Helper()
+	core, logs := observer.New(zap.InfoLevel)
+	plugin := NewLoggingPlugin().(*LoggingPlugin)
+	require.NoError(t, plugin.Init(context.Background(), map[string]interface{}{}, zap.New(core)))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/orders")
+	ctx.Request.Header.SetMethod("GET")
+	for name, value := range headers {
+		ctx.Request.Header.Set(name, value)
+	}
+	requestCtx := &RequestContext{RequestCtx: ctx, StartTime: time.Now(), Context: context.Background()}
+
+	_, err := plugin.PreProcess(requestCtx)
+	require.NoError(t, err)
+	require.NoError(t, plugin.PostProcess(&ResponseContext{RequestContext: requestCtx}))
+
+	requests := logs.FilterMessage("HTTP request").All()
+	responses := logs.FilterMessage("HTTP response").All()
+	require.Len(t, requests, 1)
+	require.Len(t, responses, 1)
+	return requestCtx, requests[0], responses[0]
+}
+
+func TestLoggingPlugin_CorrelatesWithIncomingTraceparent(t *testing.T) {
+	requestCtx, request, response := logCorrelatedRequest(t, map[string]string{
+		"traceparent":  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"X-Request-ID": "req-42",
+	})
+
+	for _, entry := range []observer.LoggedEntry{request, response} {
+		fields := entry.ContextMap()
+		assert.Equal(t, "req-42", fields["request_id"], entry.Message)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields["trace_id"], entry.Message)
+	}
+
+	traceID, _ := requestCtx.GetUserValue("trace_id")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+}
+
+func TestLoggingPlugin_GeneratesCorrelationIDs(t *testing.T) {
+	requestCtx, request, response := logCorrelatedRequest(t, map[string]string{
+		"traceparent": "not-a-traceparent",
+	})
+
+	requestFields := request.ContextMap()
+	responseFields := response.ContextMap()
+
+	requestID, _ := requestCtx.GetUserValue("request_id")
+	assert.NotEmpty(t, requestID)
+	assert.Equal(t, requestID, requestFields["request_id"])
+	assert.Equal(t, requestID, responseFields["request_id"])
+
+	traceID, _ := requestCtx.GetUserValue("trace_id")
+	assert.Regexp(t, "^[0-9a-f]{32}$", traceID)
+	assert.Equal(t, traceID, requestFields["trace_id"])
+	assert.Equal(t, traceID, responseFields["trace_id"])
+}
+
+func TestLoggingPlugin_RedactsSharedJSONBodyWithoutMutatingIt(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	plugin := NewLoggingPlugin().(*LoggingPlugin)
+	require.NoError(t, plugin.Init(context.Background(), map[string]interface{}{
+		"log_request_body": true,
+	}, zap.New(core)))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/login")
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.SetContentType("application/json")
+	ctx.Request.SetBodyString(`{"user":"ada","password":"hunter2","profile":{"api_key":"k"}}`)
+	requestCtx := &RequestContext{RequestCtx: ctx, StartTime: time.Now(), Context: context.Background()}
+
+	_, err := plugin.PreProcess(requestCtx)
+	require.NoError(t, err)
+
+	entries := logs.FilterMessage("HTTP request").All()
+	require.Len(t, entries, 1)
+	logged := fmt.Sprintf("%s", entries[0].ContextMap()["body"])
+	assert.JSONEq(t, `{"user":"ada","password":"[REDACTED]","profile":{"api_key":"[REDACTED]"}}`, logged)
+
+	// Later plugins still see the original values
+	body, err := requestCtx.JSONBody()
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", body.(map[string]interface{})["password"])
+}
+
+// logSampledRequests runs one request per status through a logging plugin
+// sampling at rate and returns the request and response lines by request ID
+func logSampledRequests(t *testing.T, rate float64, statuses []int) (map[string]int, map[string]int) {
+	t.Helper()
+	core, logs := observer.New(zap.InfoLevel)
+	plugin := NewLoggingPlugin().(*LoggingPlugin)
+	require.NoError(t, plugin.Init(context.Background(), map[string]interface{}{
+		"sample_rate": rate,
+	}, zap.New(core)))
+
+	for i, status := range statuses {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/orders")
+		ctx.Request.Header.SetMethod("GET")
+		ctx.Request.Header.Set("X-Request-ID", fmt.Sprintf("req-%d", i))
+		requestCtx := &RequestContext{RequestCtx: ctx, StartTime: time.Now(), Context: context.Background()}
+
+		_, err := plugin.PreProcess(requestCtx)
+		require.NoError(t, err)
+		ctx.Response.SetStatusCode(status)
+		require.NoError(t, plugin.PostProcess(&ResponseContext{RequestContext: requestCtx}))
+	}
+
+	requests := map[string]int{}
+	for _, entry := range logs.FilterMessage("HTTP request").All() {
+		requests[entry.ContextMap()["request_id"].(string)]++
+	}
+	responses := map[string]int{}
+	for _, entry := range logs.FilterMessage("HTTP response").All() {
+		responses[entry.ContextMap()["request_id"].(string)]++
+	}
+	return requests, responses
+}
+
+func TestLoggingPlugin_SamplingAlwaysLogsErrors(t *testing.T) {
+	statuses := []int{}
+	for i := 0; i < 200; i++ {
+		statuses = append(statuses, []int{400, 404, 500, 503}[i%4])
+	}
+
+	requests, responses := logSampledRequests(t, 0, statuses)
+	assert.Len(t, requests, len(statuses))
+	assert.Len(t, responses, len(statuses))
+	for id, count := range responses {
+		assert.Equal(t, 1, count, id)
+		assert.Equal(t, 1, requests[id], id)
+	}
+}
+
+func TestLoggingPlugin_SamplesSuccessfulRequests(t *testing.T) {
+	statuses := make([]int, 2000)
+	for i := range statuses {
+		statuses[i] = 200
+	}
+
+	requests, responses := logSampledRequests(t, 0.25, statuses)
+	assert.InDelta(t, 500, len(responses), 100)
+
+	// A request's lines are kept or dropped together
+	assert.Equal(t, len(responses), len(requests))
+	for id := range responses {
+		assert.Equal(t, 1, requests[id], id)
+	}
+
+	all, _ := logSampledRequests(t, 1, statuses[:100])
+	assert.Len(t, all, 100)
+	none, _ := logSampledRequests(t, 0, statuses[:100])
+	assert.Empty(t, none)
+}
+
+func TestLoggingPlugin_RejectsSampleRateOutOfRange(t *testing.T) {
+	for _, rate := range []float64{-0.1, 1.5} {
+		plugin := NewLoggingPlugin().(*LoggingPlugin)
+		err := plugin.Init(context.Background(), map[string]interface{}{"sample_rate": rate}, zap.NewNop())
+		assert.ErrorContains(t, err, "sample_rate", rate)
+	}
+}
+
+func TestLoggingPlugin_WritesToOutputFile(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "access.log")
+	core, appLogs := observer.New(zap.InfoLevel)
+	plugin := NewLoggingPlugin().(*LoggingPlugin)
+	require.NoError(t, plugin.Init(context.Background(), map[string]interface{}{
+		"output": output,
+	}, zap.New(core)))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/orders")
+	ctx.Request.Header.SetMethod("GET")
+	ctx.Request.Header.Set("X-Request-ID", "req-file")
+	requestCtx := &RequestContext{RequestCtx: ctx, StartTime: time.Now(), Context: context.Background()}
+	_, err := plugin.PreProcess(requestCtx)
+	require.NoError(t, err)
+	ctx.Response.SetStatusCode(fasthttp.StatusNotFound)
+	require.NoError(t, plugin.PostProcess(&ResponseContext{RequestContext: requestCtx}))
+	require.NoError(t, plugin.Cleanup(context.Background()))
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	messages := map[string]map[string]interface{}{}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		messages[entry["msg"].(string)] = entry
+	}
+	require.Contains(t, messages, "HTTP request")
+	require.Contains(t, messages, "HTTP response")
+	assert.Equal(t, "req-file", messages["HTTP request"]["request_id"])
+	assert.Equal(t, "logging", messages["HTTP response"]["plugin"])
+	assert.Equal(t, "warn", messages["HTTP response"]["level"])
+
+	// Request lines stay out of the app logger
+	assert.Empty(t, appLogs.FilterMessage("HTTP request").All())
+	assert.Empty(t, appLogs.FilterMessage("HTTP response").All())
+}
+
+func TestLoggingPlugin_OutputFileUsesConsoleFormat(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "access.log")
+	plugin := NewLoggingPlugin().(*LoggingPlugin)
+	require.NoError(t, plugin.Init(context.Background(), map[string]interface{}{
+		"output":     output,
+		"log_format": "console",
+	}, zap.NewNop()))
+	require.NoError(t, plugin.Cleanup(context.Background()))
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Logging plugin initialized")
+	assert.False(t, json.Valid([]byte(strings.Split(string(data), "\n")[0])), "console lines are not JSON")
+}
+
+func TestLoggingPlugin_RejectsUnopenableOutput(t *testing.T) {
+	plugin := NewLoggingPlugin().(*LoggingPlugin)
+	err := plugin.Init(context.Background(), map[string]interface{}{
+		"output": filepath.Join(t.TempDir(), "missing", "access.log"),
+	}, zap.NewNop())
+	assert.ErrorContains(t, err, "failed to open log output")
+}
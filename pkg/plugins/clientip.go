@@ -0,0 +1,114 @@
+package plugins
+
+import (
+	"net"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// TrustedProxies lists the networks of reverse proxies whose forwarding
+// header is believed. Forwarding headers are set by whoever sends the
+// request, so they are ignored unless the direct peer is one of these
+// proxies. Only Header is read: a proxy overwrites or appends to the header
+// it sets but passes the others through untouched, so falling back to a
+// different header would let a client supply the address.
+type TrustedProxies struct {
+	Networks []*net.IPNet
+
+	// Header names the forwarding header the proxies set: Forwarded,
+	// X-Forwarded-For or X-Real-IP. Empty means X-Forwarded-For.
+	Header string
+}
+
+// Forwarding headers TrustedProxies can read
+const (
+	HeaderForwarded     = "Forwarded"
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderXRealIP       = "X-Real-IP"
+)
+
+// Contains reports whether ip belongs to a trusted proxy network
+func (t TrustedProxies) Contains(ip net.IP) bool {
+	for _, network := range t.Networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client that sent the request. When
+// the direct peer is a trusted proxy, the forwarding chain is walked from
+// the nearest hop back and the first address not belonging to a trusted
+// proxy is returned, so entries a client prepends itself are never used.
+// Otherwise the socket's remote address is returned.
+func (t TrustedProxies) ClientIP(ctx *fasthttp.RequestCtx) string {
+	client := ctx.RemoteIP()
+	if !t.Contains(client) {
+		return client.String()
+	}
+
+	chain := forwardedChain(&ctx.Request.Header, t.Header)
+	for i := len(chain) - 1; i >= 0; i-- {
+		// An unparsable hop means nothing before it can be attributed
+		if chain[i] == nil {
+			break
+		}
+		client = chain[i]
+		if !t.Contains(client) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// forwardedChain returns the client addresses recorded by proxies in the
+// named header, the original client first. Entries that are not IP
+// addresses, such as "unknown" or obfuscated identifiers, are nil.
+func forwardedChain(header *fasthttp.RequestHeader, name string) []net.IP {
+	var chain []net.IP
+
+	switch {
+	case strings.EqualFold(name, HeaderForwarded):
+		for _, value := range header.PeekAll(HeaderForwarded) {
+			for _, element := range strings.Split(string(value), ",") {
+				chain = append(chain, parseForwardedFor(element))
+			}
+		}
+	case strings.EqualFold(name, HeaderXRealIP):
+		if value := header.Peek(HeaderXRealIP); len(value) > 0 {
+			chain = append(chain, parseForwardedAddr(string(value)))
+		}
+	default:
+		for _, value := range header.PeekAll(HeaderXForwardedFor) {
+			for _, hop := range strings.Split(string(value), ",") {
+				chain = append(chain, parseForwardedAddr(hop))
+			}
+		}
+	}
+	return chain
+}
+
+// parseForwardedFor returns the address in the for= parameter of one
+// Forwarded element, e.g. `for=192.0.2.60;proto=http` or
+// `for="[2001:db8::17]:4711"`
+func parseForwardedFor(element string) net.IP {
+	for _, pair := range strings.Split(element, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && strings.EqualFold(name, "for") {
+			return parseForwardedAddr(strings.Trim(value, `"`))
+		}
+	}
+	return nil
+}
+
+// parseForwardedAddr parses an IP address that may carry a port and, for
+// IPv6, square brackets
+func parseForwardedAddr(addr string) net.IP {
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(strings.Trim(addr, "[]"))
+}
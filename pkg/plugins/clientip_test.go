@@ -0,0 +1,73 @@
+package plugins
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	var networks []*net.IPNet
+	for _, cidr := range []string{"10.0.0.0/8", "2001:db8::/32"} {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		networks = append(networks, network)
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		peer    string
+		headers map[string]string
+		want    string
+	}{
+		{"untrusted peer ignores X-Forwarded-For", "", "203.0.113.5", map[string]string{"X-Forwarded-For": "198.51.100.7"}, "203.0.113.5"},
+		{"untrusted peer ignores Forwarded", "Forwarded", "203.0.113.5", map[string]string{"Forwarded": "for=198.51.100.7"}, "203.0.113.5"},
+		{"untrusted peer ignores X-Real-IP", "X-Real-IP", "203.0.113.5", map[string]string{"X-Real-IP": "198.51.100.7"}, "203.0.113.5"},
+		{"trusted peer without headers", "", "10.0.0.2", nil, "10.0.0.2"},
+		{"trusted peer honors X-Forwarded-For", "", "10.0.0.2", map[string]string{"X-Forwarded-For": "198.51.100.7"}, "198.51.100.7"},
+		{"client-supplied entries before the proxy are skipped", "", "10.0.0.2", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.7"}, "198.51.100.7"},
+		{"trusted hops are walked past", "", "10.0.0.2", map[string]string{"X-Forwarded-For": "198.51.100.7, 10.1.1.1"}, "198.51.100.7"},
+		{"client-sent Forwarded is ignored when the proxy sets X-Forwarded-For", "X-Forwarded-For", "10.0.0.2", map[string]string{
+			"Forwarded":       "for=1.2.3.4",
+			"X-Forwarded-For": "198.51.100.7",
+		}, "198.51.100.7"},
+		{"client-sent X-Forwarded-For is ignored when the proxy sets Forwarded", "Forwarded", "10.0.0.2", map[string]string{
+			"Forwarded":       `for=198.51.100.7;proto=https, for="[2001:db8::17]:4711"`,
+			"X-Forwarded-For": "1.2.3.4",
+		}, "198.51.100.7"},
+		{"no fallback when the configured header is missing", "X-Forwarded-For", "10.0.0.2", map[string]string{
+			"Forwarded": "for=1.2.3.4",
+			"X-Real-IP": "1.2.3.4",
+		}, "10.0.0.2"},
+		{"X-Real-IP from a trusted peer", "X-Real-IP", "10.0.0.2", map[string]string{
+			"X-Real-IP":       "198.51.100.7",
+			"X-Forwarded-For": "1.2.3.4",
+		}, "198.51.100.7"},
+		{"unparsable hop stops the walk", "Forwarded", "10.0.0.2", map[string]string{"Forwarded": "for=198.51.100.7, for=unknown"}, "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.SetRemoteAddr(&net.TCPAddr{IP: net.ParseIP(tt.peer), Port: 40000})
+			for name, value := range tt.headers {
+				ctx.Request.Header.Set(name, value)
+			}
+			proxies := TrustedProxies{Networks: networks, Header: tt.header}
+			assert.Equal(t, tt.want, proxies.ClientIP(ctx))
+		})
+	}
+}
+
+func TestTrustedProxies_NoneTrusted(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.SetRemoteAddr(&net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 40000})
+	ctx.Request.Header.Set("X-Forwarded-For", "198.51.100.7")
+
+	assert.Equal(t, "10.0.0.2", TrustedProxies{}.ClientIP(ctx))
+}
@@ -1,12 +1,11 @@
-package plugins
+package plugins_test
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,6 +17,7 @@ import (
 	"vanta/pkg/api"
 	"vanta/pkg/config"
 	"vanta/pkg/openapi"
+	"vanta/pkg/plugins"
 )
 
 // TestPluginSystemServerIntegration tests the complete plugin system integration with the server
@@ -26,19 +26,19 @@ func TestPluginSystemServerIntegration(t *testing.T) {
 
 	// Create a minimal OpenAPI spec for testing
 	spec := &openapi.Specification{
-		OpenAPI: "3.0.0",
+		Version: "3.0.0",
 		Info: openapi.InfoObject{
 			Title:   "Test API",
 			Version: "1.0.0",
 		},
 		Paths: map[string]openapi.PathItem{
 			"/api/users": {
-				Get: &openapi.Operation{
-					OperationId: "getUsers",
+				GET: &openapi.Operation{
+					OperationID: "getUsers",
 					Responses: map[string]openapi.Response{
 						"200": {
 							Description: "Success",
-							Content: map[string]openapi.MediaType{
+							Content: map[string]openapi.MediaTypeObject{
 								"application/json": {
 									Schema: &openapi.Schema{
 										Type: "array",
@@ -57,12 +57,12 @@ func TestPluginSystemServerIntegration(t *testing.T) {
 				},
 			},
 			"/health": {
-				Get: &openapi.Operation{
-					OperationId: "health",
+				GET: &openapi.Operation{
+					OperationID: "health",
 					Responses: map[string]openapi.Response{
 						"200": {
 							Description: "Health check",
-							Content: map[string]openapi.MediaType{
+							Content: map[string]openapi.MediaTypeObject{
 								"application/json": {
 									Schema: &openapi.Schema{
 										Type: "object",
@@ -84,7 +84,7 @@ func TestPluginSystemServerIntegration(t *testing.T) {
 		cfg := &config.Config{
 			Server: config.ServerConfig{
 				Host:         "127.0.0.1",
-				Port:         8899, // Use a different port for testing
+				Port:         0,
 				ReadTimeout:  30 * time.Second,
 				WriteTimeout: 30 * time.Second,
 				Concurrency:  256,
@@ -156,7 +156,7 @@ func TestPluginSystemServerIntegration(t *testing.T) {
 		// Wait for server to be ready
 		time.Sleep(100 * time.Millisecond)
 
-		baseURL := fmt.Sprintf("http://%s", server.GetAddr())
+		baseURL := "http://" + server.ListenAddrs()[0]
 
 		// Test 1: Verify plugins are loaded and enabled
 		pluginStats := server.GetPluginStats()
@@ -164,7 +164,7 @@ func TestPluginSystemServerIntegration(t *testing.T) {
 
 		enabledCount := 0
 		for _, stat := range pluginStats {
-			if stat.State == StateEnabled {
+			if stat.State == plugins.StateEnabled {
 				enabledCount++
 			}
 		}
@@ -184,7 +184,7 @@ func TestPluginSystemServerIntegration(t *testing.T) {
 
 		// Test 4: Verify API key authentication works
 		req, _ := http.NewRequest("GET", baseURL+"/api/users", nil)
-		req.Header.Set("Authorization", "Bearer test-api-key")
+		req.Header.Set("Authorization", "test-api-key")
 		client := &http.Client{Timeout: 5 * time.Second}
 		resp, err = client.Do(req)
 		require.NoError(t, err, "API key request should succeed")
@@ -194,9 +194,11 @@ func TestPluginSystemServerIntegration(t *testing.T) {
 		// Test 5: Verify CORS headers are added
 		req, _ = http.NewRequest("OPTIONS", baseURL+"/api/users", nil)
 		req.Header.Set("Origin", "http://localhost:3000")
+		req.Header.Set("Access-Control-Request-Method", "GET")
 		resp, err = client.Do(req)
 		require.NoError(t, err, "CORS preflight should succeed")
-		assert.Contains(t, resp.Header.Get("Access-Control-Allow-Origin"), "*", "CORS headers should be present")
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode, "Preflight should not require credentials")
+		assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"), "CORS headers should be present")
 		resp.Body.Close()
 
 		// Test 6: Verify plugin metrics are collected
@@ -219,7 +221,7 @@ func TestPluginSystemServerIntegration(t *testing.T) {
 		cfg := &config.Config{
 			Server: config.ServerConfig{
 				Host:         "127.0.0.1",
-				Port:         8898, // Different port
+				Port:         0,
 				ReadTimeout:  30 * time.Second,
 				WriteTimeout: 30 * time.Second,
 				Concurrency:  256,
@@ -251,7 +253,7 @@ func TestPluginSystemServerIntegration(t *testing.T) {
 		pluginStats := server.GetPluginStats()
 		require.Len(t, pluginStats, 1)
 		assert.Equal(t, "auth", pluginStats[0].Name)
-		assert.Equal(t, StateEnabled, pluginStats[0].State)
+		assert.Equal(t, plugins.StateEnabled, pluginStats[0].State)
 
 		// Test plugin hot reload
 		newConfig := map[string]interface{}{
@@ -267,20 +269,20 @@ func TestPluginSystemServerIntegration(t *testing.T) {
 		// Verify plugin is still enabled after reload
 		pluginStats = server.GetPluginStats()
 		require.Len(t, pluginStats, 1)
-		assert.Equal(t, StateEnabled, pluginStats[0].State)
+		assert.Equal(t, plugins.StateEnabled, pluginStats[0].State)
 
 		// Test enable/disable operations
 		err = server.DisablePlugin("auth")
 		assert.NoError(t, err, "Plugin disable should succeed")
 
 		pluginStats = server.GetPluginStats()
-		assert.Equal(t, StateDisabled, pluginStats[0].State)
+		assert.Equal(t, plugins.StateDisabled, pluginStats[0].State)
 
 		err = server.EnablePlugin("auth")
 		assert.NoError(t, err, "Plugin enable should succeed")
 
 		pluginStats = server.GetPluginStats()
-		assert.Equal(t, StateEnabled, pluginStats[0].State)
+		assert.Equal(t, plugins.StateEnabled, pluginStats[0].State)
 	})
 
 	t.Run("PluginMiddlewareChaining", func(t *testing.T) {
@@ -288,7 +290,7 @@ func TestPluginSystemServerIntegration(t *testing.T) {
 		cfg := &config.Config{
 			Server: config.ServerConfig{
 				Host:         "127.0.0.1",
-				Port:         8897,
+				Port:         0,
 				ReadTimeout:  30 * time.Second,
 				WriteTimeout: 30 * time.Second,
 				Concurrency:  256,
@@ -335,11 +337,11 @@ func TestPluginSystemServerIntegration(t *testing.T) {
 
 		time.Sleep(100 * time.Millisecond)
 
-		baseURL := fmt.Sprintf("http://%s", server.GetAddr())
+		baseURL := "http://" + server.ListenAddrs()[0]
 
 		// Test that middleware chain processes requests correctly
 		req, _ := http.NewRequest("GET", baseURL+"/api/users", nil)
-		req.Header.Set("Authorization", "Bearer chain-test-key")
+		req.Header.Set("Authorization", "chain-test-key")
 		req.Header.Set("Origin", "http://example.com")
 
 		client := &http.Client{Timeout: 5 * time.Second}
@@ -372,15 +374,15 @@ func TestPluginSystemConcurrency(t *testing.T) {
 	logger := zaptest.NewLogger(t, zaptest.Level(zap.WarnLevel))
 
 	spec := &openapi.Specification{
-		OpenAPI: "3.0.0",
+		Version: "3.0.0",
 		Info: openapi.InfoObject{
 			Title:   "Concurrency Test API",
 			Version: "1.0.0",
 		},
 		Paths: map[string]openapi.PathItem{
 			"/api/test": {
-				Get: &openapi.Operation{
-					OperationId: "test",
+				GET: &openapi.Operation{
+					OperationID: "test",
 					Responses: map[string]openapi.Response{
 						"200": {Description: "Success"},
 					},
@@ -392,7 +394,7 @@ func TestPluginSystemConcurrency(t *testing.T) {
 	cfg := &config.Config{
 		Server: config.ServerConfig{
 			Host:         "127.0.0.1",
-			Port:         8896,
+			Port:         0,
 			ReadTimeout:  30 * time.Second,
 			WriteTimeout: 30 * time.Second,
 			Concurrency:  1000,
@@ -430,15 +432,15 @@ func TestPluginSystemConcurrency(t *testing.T) {
 
 	time.Sleep(100 * time.Millisecond)
 
-	baseURL := fmt.Sprintf("http://%s", server.GetAddr())
+	baseURL := "http://" + server.ListenAddrs()[0]
 
 	t.Run("ConcurrentRequests", func(t *testing.T) {
 		const numGoroutines = 50
 		const requestsPerGoroutine = 10
 
 		var wg sync.WaitGroup
-		var successCount int64
-		var errorCount int64
+		var successCount atomic.Int64
+		var errorCount atomic.Int64
 
 		for i := 0; i < numGoroutines; i++ {
 			wg.Add(1)
@@ -448,19 +450,19 @@ func TestPluginSystemConcurrency(t *testing.T) {
 
 				for j := 0; j < requestsPerGoroutine; j++ {
 					req, _ := http.NewRequest("GET", baseURL+"/api/test", nil)
-					req.Header.Set("Authorization", "Bearer concurrent-key")
+					req.Header.Set("Authorization", "concurrent-key")
 
 					resp, err := client.Do(req)
 					if err != nil {
 						t.Logf("Worker %d request %d failed: %v", workerID, j, err)
-						errorCount++
+						errorCount.Add(1)
 						continue
 					}
 
 					if resp.StatusCode == http.StatusOK {
-						successCount++
+						successCount.Add(1)
 					} else {
-						errorCount++
+						errorCount.Add(1)
 					}
 					resp.Body.Close()
 				}
@@ -470,10 +472,10 @@ func TestPluginSystemConcurrency(t *testing.T) {
 		wg.Wait()
 
 		totalRequests := int64(numGoroutines * requestsPerGoroutine)
-		successRate := float64(successCount) / float64(totalRequests) * 100
+		successRate := float64(successCount.Load()) / float64(totalRequests) * 100
 
 		t.Logf("Concurrent test results: %d/%d successful (%.2f%%)", 
-			successCount, totalRequests, successRate)
+			successCount.Load(), totalRequests, successRate)
 
 		// Expect at least 90% success rate under concurrent load
 		assert.Greater(t, successRate, 90.0, "Success rate should be > 90%% under concurrent load")
@@ -503,15 +505,15 @@ func TestPluginSystemErrorRecovery(t *testing.T) {
 	}
 
 	spec := &openapi.Specification{
-		OpenAPI: "3.0.0",
+		Version: "3.0.0",
 		Info: openapi.InfoObject{
 			Title:   "Error Recovery Test API",
 			Version: "1.0.0",
 		},
 		Paths: map[string]openapi.PathItem{
 			"/api/test": {
-				Get: &openapi.Operation{
-					OperationId: "test",
+				GET: &openapi.Operation{
+					OperationID: "test",
 					Responses: map[string]openapi.Response{
 						"200": {Description: "Success"},
 					},
@@ -523,7 +525,7 @@ func TestPluginSystemErrorRecovery(t *testing.T) {
 	cfg := &config.Config{
 		Server: config.ServerConfig{
 			Host:         "127.0.0.1",
-			Port:         8895,
+			Port:         0,
 			ReadTimeout:  30 * time.Second,
 			WriteTimeout: 30 * time.Second,
 			Concurrency:  256,
@@ -552,7 +554,7 @@ func TestPluginSystemErrorRecovery(t *testing.T) {
 	require.NotNil(t, pluginManager)
 
 	registry := pluginManager.GetRegistry()
-	err = registry.RegisterPlugin("faulty", func() Plugin { return faultyPlugin })
+	err = registry.RegisterPlugin("faulty", func() plugins.Plugin { return faultyPlugin })
 	require.NoError(t, err)
 
 	err = server.Start()
@@ -575,9 +577,9 @@ func TestPluginSystemErrorRecovery(t *testing.T) {
 		}
 
 		// Server should still be functional
-		baseURL := fmt.Sprintf("http://%s", server.GetAddr())
+		baseURL := "http://" + server.ListenAddrs()[0]
 		req, _ := http.NewRequest("GET", baseURL+"/api/test", nil)
-		req.Header.Set("Authorization", "Bearer recovery-key")
+		req.Header.Set("Authorization", "recovery-key")
 
 		client := &http.Client{Timeout: 5 * time.Second}
 		resp, err := client.Do(req)
@@ -596,14 +598,14 @@ func TestPluginSystemErrorRecovery(t *testing.T) {
 		err = pluginManager.EnablePlugin("faulty")
 		require.NoError(t, err, "Should enable faulty plugin")
 
-		baseURL := fmt.Sprintf("http://%s", server.GetAddr())
+		baseURL := "http://" + server.ListenAddrs()[0]
 
 		// Make multiple requests to trigger plugin errors
 		successCount := 0
 		errorCount := 0
 		for i := 0; i < 20; i++ {
 			req, _ := http.NewRequest("GET", baseURL+"/api/test", nil)
-			req.Header.Set("Authorization", "Bearer recovery-key")
+			req.Header.Set("Authorization", "recovery-key")
 
 			client := &http.Client{Timeout: 5 * time.Second}
 			resp, err := client.Do(req)
@@ -626,7 +628,7 @@ func TestPluginSystemErrorRecovery(t *testing.T) {
 
 		// Check plugin metrics for error tracking
 		pluginStats := server.GetPluginStats()
-		var faultyStats *PluginInfo
+		var faultyStats *plugins.PluginInfo
 		for _, stat := range pluginStats {
 			if stat.Name == "faulty" {
 				faultyStats = &stat
@@ -677,11 +679,11 @@ func (p *FaultyTestPlugin) Cleanup(ctx context.Context) error {
 	return nil
 }
 
-func (p *FaultyTestPlugin) Priority() Priority {
-	return PriorityNormal
+func (p *FaultyTestPlugin) Priority() plugins.Priority {
+	return plugins.PriorityNormal
 }
 
-func (p *FaultyTestPlugin) PreProcess(ctx *RequestContext) (bool, error) {
+func (p *FaultyTestPlugin) PreProcess(ctx *plugins.RequestContext) (bool, error) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -697,7 +699,7 @@ func (p *FaultyTestPlugin) PreProcess(ctx *RequestContext) (bool, error) {
 	return true, nil
 }
 
-func (p *FaultyTestPlugin) PostProcess(ctx *ResponseContext) error {
+func (p *FaultyTestPlugin) PostProcess(ctx *plugins.ResponseContext) error {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -719,4 +721,4 @@ func (p *FaultyTestPlugin) ShouldApply(req *fasthttp.RequestCtx) bool {
 func (p *FaultyTestPlugin) shouldSimulateError() bool {
 	// Simple pseudo-random error simulation
 	return (time.Now().UnixNano()%100) < int64(p.errorRate*100)
-}
\ No newline at end of file
+}
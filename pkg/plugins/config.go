@@ -4,8 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -71,16 +75,20 @@ type JSONSchemaProperty struct {
 	Pattern     string                         `json:"pattern,omitempty"`
 	Properties  map[string]JSONSchemaProperty  `json:"properties,omitempty"`
 	Items       *JSONSchemaProperty            `json:"items,omitempty"`
+	// RequiredProperties lists child properties of an object that must be
+	// present, in addition to children whose own Required flag is set
+	RequiredProperties []string `json:"-"`
 }
 
 // JSONSchema represents a JSON schema for plugin configuration
 type JSONSchema struct {
-	Schema     string                        `json:"$schema"`
-	Type       string                        `json:"type"`
-	Title      string                        `json:"title"`
-	Properties map[string]JSONSchemaProperty `json:"properties"`
-	Required   []string                      `json:"required"`
-	Version    ConfigVersion                 `json:"version"`
+	Schema      string                        `json:"$schema"`
+	Type        string                        `json:"type"`
+	Title       string                        `json:"title"`
+	Description string                        `json:"description,omitempty"`
+	Properties  map[string]JSONSchemaProperty `json:"properties"`
+	Required    []string                      `json:"required"`
+	Version     ConfigVersion                 `json:"version"`
 }
 
 // PluginConfigRegistry manages plugin configuration schemas and validation
@@ -145,6 +153,48 @@ func (r *PluginConfigRegistry) GetSchema(pluginName string) (*JSONSchema, bool)
 	return schema, exists
 }
 
+// SchemaNames returns the names of all plugins with a registered schema, sorted
+func (r *PluginConfigRegistry) SchemaNames() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.schemas))
+	for name := range r.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SchemaJSON returns the registered schema for a plugin as indented JSON
+func (r *PluginConfigRegistry) SchemaJSON(pluginName string) ([]byte, error) {
+	schema, exists := r.GetSchema(pluginName)
+	if !exists {
+		return nil, fmt.Errorf("no schema registered for plugin %s", pluginName)
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// CombinedSchemaJSON returns a single JSON schema document, as indented JSON,
+// describing a plugins configuration section keyed by plugin name
+func (r *PluginConfigRegistry) CombinedSchemaJSON() ([]byte, error) {
+	r.mu.RLock()
+	properties := make(map[string]*JSONSchema, len(r.schemas))
+	for name, schema := range r.schemas {
+		properties[name] = schema
+	}
+	r.mu.RUnlock()
+
+	document := map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"type":        "object",
+		"title":       "Plugin Configuration",
+		"description": "Configuration for each plugin, keyed by plugin name",
+		"properties":  properties,
+	}
+	return json.MarshalIndent(document, "", "  ")
+}
+
 // ValidateConfig validates a plugin configuration against its schema
 func (r *PluginConfigRegistry) ValidateConfig(pluginName string, config map[string]interface{}) ConfigValidationResult {
 	r.mu.RLock()
@@ -170,6 +220,76 @@ func (r *PluginConfigRegistry) ValidateConfig(pluginName string, config map[stri
 	}
 }
 
+// ApplyDefaults returns config overlaid on the schema defaults for pluginName,
+// so omitted settings take the documented default rather than one hardcoded
+// in the plugin. Keys present in config keep their value, including zero
+// values such as false, 0 or ""; missing keys and nil values take the
+// default. Object properties are merged key by key. config is not modified.
+func (r *PluginConfigRegistry) ApplyDefaults(pluginName string, config map[string]interface{}) map[string]interface{} {
+	schema, exists := r.GetSchema(pluginName)
+	if !exists {
+		return config
+	}
+	return applyPropertyDefaults(schema.Properties, config)
+}
+
+// applyPropertyDefaults fills the defaults of properties missing from config
+func applyPropertyDefaults(properties map[string]JSONSchemaProperty, config map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(config)+len(properties))
+	for key, value := range config {
+		if value != nil {
+			result[key] = value
+		}
+	}
+
+	for key, property := range properties {
+		value, present := result[key]
+		switch {
+		case !present && property.Default != nil:
+			result[key] = copyDefaultValue(property.Default)
+		case property.Type == "object" && len(property.Properties) > 0:
+			nested, isMap := value.(map[string]interface{})
+			if present && !isMap {
+				continue // Left for validation to report
+			}
+			if merged := applyPropertyDefaults(property.Properties, nested); present || len(merged) > 0 {
+				result[key] = merged
+			}
+		}
+	}
+
+	return result
+}
+
+// copyDefaultValue copies list and map defaults so a plugin changing its
+// configuration cannot change the schema
+func copyDefaultValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyDefaultValue(item)
+		}
+		return copied
+	case []string:
+		return append([]string{}, v...)
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = copyDefaultValue(item)
+		}
+		return copied
+	case map[string]string:
+		copied := make(map[string]string, len(v))
+		for key, item := range v {
+			copied[key] = item
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
 // MigrateConfig migrates a configuration to the current version
 func (r *PluginConfigRegistry) MigrateConfig(pluginName string, config map[string]interface{}, currentVersion ConfigVersion) (map[string]interface{}, error) {
 	r.mu.RLock()
@@ -201,7 +321,7 @@ func (r *PluginConfigRegistry) validateAgainstSchema(schema *JSONSchema, config
 	var errors []ConfigValidationError
 	
 	// Check required fields
-	for _, required := range schema.Required {
+	for _, required := range requiredProperties(schema.Required, schema.Properties) {
 		if _, exists := config[required]; !exists {
 			errors = append(errors, ConfigValidationError{
 				Field:   r.buildFieldPath(fieldPath, required),
@@ -225,6 +345,25 @@ func (r *PluginConfigRegistry) validateAgainstSchema(schema *JSONSchema, config
 	return errors
 }
 
+// requiredProperties returns the explicitly required names followed by any
+// other properties flagged Required, in sorted order
+func requiredProperties(required []string, properties map[string]JSONSchemaProperty) []string {
+	seen := make(map[string]bool, len(required))
+	for _, name := range required {
+		seen[name] = true
+	}
+
+	var flagged []string
+	for name, property := range properties {
+		if property.Required && !seen[name] {
+			flagged = append(flagged, name)
+		}
+	}
+	sort.Strings(flagged)
+
+	return append(append([]string{}, required...), flagged...)
+}
+
 // validateProperty validates a single property against its schema
 func (r *PluginConfigRegistry) validateProperty(property JSONSchemaProperty, value interface{}, fieldPath string) []ConfigValidationError {
 	var errors []ConfigValidationError
@@ -305,6 +444,7 @@ func (r *PluginConfigRegistry) validateProperty(property JSONSchemaProperty, val
 		if obj, ok := value.(map[string]interface{}); ok {
 			schema := &JSONSchema{
 				Properties: property.Properties,
+				Required:   property.RequiredProperties,
 			}
 			errors = append(errors, r.validateAgainstSchema(schema, obj, fieldPath)...)
 		}
@@ -361,9 +501,12 @@ func (r *PluginConfigRegistry) isNumeric(value interface{}) bool {
 
 // isInteger checks if a value is an integer
 func (r *PluginConfigRegistry) isInteger(value interface{}) bool {
-	switch value.(type) {
+	switch v := value.(type) {
 	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 		return true
+	case float64:
+		// JSON-decoded numbers are float64; whole values are integers
+		return v == math.Trunc(v) && !math.IsInf(v, 0)
 	default:
 		return false
 	}
@@ -420,41 +563,116 @@ func (r *PluginConfigRegistry) buildFieldPath(parent, field string) string {
 }
 
 // substituteEnvironmentVariables performs environment variable substitution in configuration
-func (r *PluginConfigRegistry) substituteEnvironmentVariables(config map[string]interface{}) map[string]interface{} {
+func (r *PluginConfigRegistry) substituteEnvironmentVariables(config map[string]interface{}) (map[string]interface{}, error) {
+	return r.substituteWithProperties(config, nil)
+}
+
+// substitutePluginEnvironmentVariables performs environment variable
+// substitution using the plugin's schema, if any, so that values consisting
+// of a single ${VAR} reference take the type their property expects
+func (r *PluginConfigRegistry) substitutePluginEnvironmentVariables(pluginName string, config map[string]interface{}) (map[string]interface{}, error) {
+	var properties map[string]JSONSchemaProperty
+	if schema, exists := r.GetSchema(pluginName); exists {
+		properties = schema.Properties
+	}
+	return r.substituteWithProperties(config, properties)
+}
+
+// substituteWithProperties substitutes each value in config, typing it by the
+// matching entry in properties when there is one
+func (r *PluginConfigRegistry) substituteWithProperties(config map[string]interface{}, properties map[string]JSONSchemaProperty) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
 	
 	for key, value := range config {
-		result[key] = r.substituteValue(value)
+		var property *JSONSchemaProperty
+		if p, ok := properties[key]; ok {
+			property = &p
+		}
+		substituted, err := r.substituteValue(value, property)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		result[key] = substituted
 	}
 	
-	return result
+	return result, nil
 }
 
-// substituteValue performs environment variable substitution on a single value
-func (r *PluginConfigRegistry) substituteValue(value interface{}) interface{} {
+// substituteValue performs environment variable substitution on a single
+// value. property, which may be nil, is the schema the value must satisfy.
+func (r *PluginConfigRegistry) substituteValue(value interface{}, property *JSONSchemaProperty) (interface{}, error) {
 	switch v := value.(type) {
 	case string:
-		return r.expandEnvironmentVariables(v)
+		expanded, err := r.expandEnvironmentVariables(v)
+		if err != nil {
+			return nil, err
+		}
+		if property != nil && isWholeEnvReference(v) {
+			return coerceEnvValue(expanded, property.Type), nil
+		}
+		return expanded, nil
 	case map[string]interface{}:
-		return r.substituteEnvironmentVariables(v)
+		var properties map[string]JSONSchemaProperty
+		if property != nil {
+			properties = property.Properties
+		}
+		return r.substituteWithProperties(v, properties)
 	case []interface{}:
+		var items *JSONSchemaProperty
+		if property != nil {
+			items = property.Items
+		}
 		result := make([]interface{}, len(v))
 		for i, item := range v {
-			result[i] = r.substituteValue(item)
+			substituted, err := r.substituteValue(item, items)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			result[i] = substituted
 		}
-		return result
+		return result, nil
 	default:
-		return value
+		return value, nil
+	}
+}
+
+// isWholeEnvReference reports whether s is exactly one ${...} reference
+func isWholeEnvReference(s string) bool {
+	loc := envVarPattern.FindStringIndex(s)
+	return loc != nil && loc[0] == 0 && loc[1] == len(s)
+}
+
+// coerceEnvValue parses a substituted value into the JSON schema type
+// expected for it. Values that do not parse are left as strings so type
+// validation reports them.
+func coerceEnvValue(value, schemaType string) interface{} {
+	switch schemaType {
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "integer":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
 	}
+	return value
 }
 
-// expandEnvironmentVariables expands environment variables in a string
-func (r *PluginConfigRegistry) expandEnvironmentVariables(s string) string {
-	// Support ${VAR} and ${VAR:default} syntax
-	re := regexp.MustCompile(`\$\{([^}:]+)(?::([^}]*))?\}`)
+// envVarPattern matches ${VAR}, ${VAR:default} and ${VAR:?error message}
+var envVarPattern = regexp.MustCompile(`\$\{([^}:]+)(?::([^}]*))?\}`)
+
+// expandEnvironmentVariables expands environment variables in a string.
+// A ${VAR:?message} reference fails with message when VAR is unset or empty.
+func (r *PluginConfigRegistry) expandEnvironmentVariables(s string) (string, error) {
+	var expandErr error
 	
-	return re.ReplaceAllStringFunc(s, func(match string) string {
-		parts := re.FindStringSubmatch(match)
+	expanded := envVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+		parts := envVarPattern.FindStringSubmatch(match)
 		if len(parts) < 2 {
 			return match
 		}
@@ -468,8 +686,23 @@ func (r *PluginConfigRegistry) expandEnvironmentVariables(s string) string {
 		if envValue := os.Getenv(varName); envValue != "" {
 			return envValue
 		}
+		
+		if message, required := strings.CutPrefix(defaultValue, "?"); required {
+			if message == "" {
+				message = "must be set"
+			}
+			if expandErr == nil {
+				expandErr = fmt.Errorf("%w: %s: %s", ErrRequiredEnvVarUnset, varName, message)
+			}
+			return ""
+		}
 		return defaultValue
 	})
+	
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return expanded, nil
 }
 
 // Global configuration registry instance
@@ -480,7 +713,11 @@ var globalConfigRegistry = NewPluginConfigRegistry()
 // CreatePluginFromConfig creates a plugin instance from configuration
 func CreatePluginFromConfig(name string, config map[string]interface{}) (Plugin, error) {
 	// Substitute environment variables
-	config = globalConfigRegistry.substituteEnvironmentVariables(config)
+	config, err := globalConfigRegistry.substitutePluginEnvironmentVariables(name, config)
+	if err != nil {
+		return nil, fmt.Errorf("environment substitution failed: %w", err)
+	}
+	config = globalConfigRegistry.ApplyDefaults(name, config)
 	
 	// Validate configuration
 	validationResult := globalConfigRegistry.ValidateConfig(name, config)
@@ -514,7 +751,11 @@ func CreatePluginFromConfig(name string, config map[string]interface{}) (Plugin,
 // ValidatePluginConfig validates a plugin configuration without creating the plugin
 func ValidatePluginConfig(name string, config map[string]interface{}) error {
 	// Substitute environment variables
-	config = globalConfigRegistry.substituteEnvironmentVariables(config)
+	config, err := globalConfigRegistry.substitutePluginEnvironmentVariables(name, config)
+	if err != nil {
+		return fmt.Errorf("environment substitution failed: %w", err)
+	}
+	config = globalConfigRegistry.ApplyDefaults(name, config)
 	
 	// Validate configuration
 	validationResult := globalConfigRegistry.ValidateConfig(name, config)
@@ -615,6 +856,60 @@ func LoadPluginsIntoManager(manager *Manager, configs []config.PluginConfig) err
 	return nil
 }
 
+// PluginConfigReport is the validation outcome for one configured plugin
+type PluginConfigReport struct {
+	Name   string                  `json:"name"`
+	Valid  bool                    `json:"valid"`
+	Errors []ConfigValidationError `json:"errors"`
+}
+
+// ValidatePluginConfigs validates each plugin entry, after environment variable
+// substitution and schema defaults, and reports field-level errors per plugin. With strict set,
+// entries naming a plugin with neither a schema nor a built-in factory fail.
+func ValidatePluginConfigs(configs []config.PluginConfig, strict bool) []PluginConfigReport {
+	factories := GetBuiltinPluginFactories()
+	reports := make([]PluginConfigReport, 0, len(configs))
+
+	for _, pluginConfig := range configs {
+		report := PluginConfigReport{Name: pluginConfig.Name}
+
+		_, hasFactory := factories[pluginConfig.Name]
+		_, hasSchema := globalConfigRegistry.GetSchema(pluginConfig.Name)
+		if strict && !hasFactory && !hasSchema {
+			report.Errors = append(report.Errors, ConfigValidationError{
+				Field:   "name",
+				Value:   pluginConfig.Name,
+				Message: "unknown plugin",
+				Rule:    "strict",
+			})
+		}
+
+		// Normalize values decoded from YAML (ints, nested maps) to their JSON forms
+		normalized, err := ConvertFromTypedConfig(pluginConfig.Config)
+		if err == nil {
+			normalized, err = globalConfigRegistry.substitutePluginEnvironmentVariables(pluginConfig.Name, normalized)
+			if err != nil {
+				err = fmt.Errorf("environment substitution failed: %w", err)
+			}
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, ConfigValidationError{
+				Field:   "config",
+				Message: err.Error(),
+				Rule:    "env",
+			})
+		} else {
+			normalized = globalConfigRegistry.ApplyDefaults(pluginConfig.Name, normalized)
+			report.Errors = append(report.Errors, globalConfigRegistry.ValidateConfig(pluginConfig.Name, normalized).Errors...)
+		}
+
+		report.Valid = len(report.Errors) == 0
+		reports = append(reports, report)
+	}
+
+	return reports
+}
+
 // Hot-reload support functions
 
 // ValidateConfigForHotReload validates if a configuration can be hot-reloaded
@@ -754,34 +1049,59 @@ func ConvertLegacyConfig(pluginName string, legacyConfig map[string]interface{})
 func (r *PluginConfigRegistry) registerBuiltinSchemas() {
 	// Auth Plugin Schema
 	authSchema := &JSONSchema{
-		Schema:  "http://json-schema.org/draft-07/schema#",
-		Type:    "object",
-		Title:   "Auth Plugin Configuration",
-		Version: CurrentVersion,
+		Schema:      "http://json-schema.org/draft-07/schema#",
+		Type:        "object",
+		Title:       "Auth Plugin Configuration",
+		Description: "At least one authentication method must be configured (jwt_secret, jwt_public_key, their *_file variants, api_keys or introspection_url). HS* jwt_method values require jwt_secret or jwt_secret_file; RS*, ES* and EdDSA values require jwt_public_key or jwt_public_key_file.",
+		Version:     CurrentVersion,
 		Properties: map[string]JSONSchemaProperty{
 			"jwt_secret": {
 				Type:        "string",
 				Description: "Secret key for HMAC-based JWT signing",
 				MinLength:   intPtr(32),
 			},
+			"jwt_secret_file": {
+				Type:        "string",
+				Description: "Path to a file holding the JWT secret; overrides jwt_secret",
+				MinLength:   intPtr(1),
+			},
+			"jwt_public_key_file": {
+				Type:        "string",
+				Description: "Path to a PEM public key file; overrides jwt_public_key",
+				MinLength:   intPtr(1),
+			},
 			"jwt_public_key": {
 				Type:        "string",
-				Description: "Public key for RSA-based JWT verification",
+				Description: "PEM public key for RSA, ECDSA or EdDSA JWT verification",
 			},
 			"jwt_method": {
 				Type:        "string",
 				Description: "JWT signing method",
-				Enum:        []interface{}{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512"},
+				Enum:        []interface{}{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"},
 				Default:     "HS256",
 			},
 			"jwt_issuer": {
 				Type:        "string",
 				Description: "Expected JWT issuer",
 			},
+			"jwt_issuers": {
+				Type:        "array",
+				Description: "Accepted JWT issuers, in addition to jwt_issuer",
+				Items: &JSONSchemaProperty{
+					Type: "string",
+				},
+			},
 			"jwt_audience": {
 				Type:        "string",
 				Description: "Expected JWT audience",
 			},
+			"jwt_audiences": {
+				Type:        "array",
+				Description: "Accepted JWT audiences, in addition to jwt_audience; a token passes when its aud contains any of them",
+				Items: &JSONSchemaProperty{
+					Type: "string",
+				},
+			},
 			"api_keys": {
 				Type:        "object",
 				Description: "Map of API keys to user IDs",
@@ -799,9 +1119,17 @@ func (r *PluginConfigRegistry) registerBuiltinSchemas() {
 			},
 			"auth_cookie": {
 				Type:        "string",
-				Description: "Cookie name for authentication",
+				Description: "Cookie name for authentication, used for JWTs and API keys unless jwt_cookie or api_key_cookie is set",
 				Default:     "auth_token",
 			},
+			"jwt_cookie": {
+				Type:        "string",
+				Description: "Cookie name read for JWTs only; defaults to auth_cookie",
+			},
+			"api_key_cookie": {
+				Type:        "string",
+				Description: "Cookie name read for API keys only; defaults to auth_cookie",
+			},
 			"public_endpoints": {
 				Type:        "array",
 				Description: "List of endpoints that don't require authentication",
@@ -810,16 +1138,71 @@ func (r *PluginConfigRegistry) registerBuiltinSchemas() {
 				},
 				Default: []interface{}{},
 			},
+			"optional_endpoints": {
+				Type:        "array",
+				Description: "List of endpoints that honor valid credentials but let requests without any through anonymously",
+				Items: &JSONSchemaProperty{
+					Type: "string",
+				},
+				Default: []interface{}{},
+			},
+			"spec_security": {
+				Type:        "boolean",
+				Description: "Treat operations whose OpenAPI security requirements are empty as public",
+				Default:     false,
+			},
+			"forward_headers": {
+				Type:        "object",
+				Description: "Request headers set from the authenticated identity before the handler runs; client-sent copies are removed",
+				Properties: map[string]JSONSchemaProperty{
+					"user_id":     {Type: "string", Description: "Header for the user ID (JWT sub, introspection subject or API key owner)", MinLength: intPtr(1)},
+					"auth_method": {Type: "string", Description: "Header for the method used: jwt, introspection or api_key", MinLength: intPtr(1)},
+					"username":    {Type: "string", Description: "Header for the introspected username", MinLength: intPtr(1)},
+					"scopes":      {Type: "string", Description: "Header for the space-separated token scopes", MinLength: intPtr(1)},
+				},
+			},
+			"introspection_url": {
+				Type:        "string",
+				Description: "OAuth2 token introspection endpoint (RFC 7662) used for bearer tokens that can't be validated locally",
+				Pattern:     "^https?://",
+			},
+			"introspection_client_id": {
+				Type:        "string",
+				Description: "Client ID used to authenticate to the introspection endpoint",
+			},
+			"introspection_client_secret": {
+				Type:        "string",
+				Description: "Client secret used to authenticate to the introspection endpoint",
+			},
+			"introspection_cache_ttl_seconds": {
+				Type:        "integer",
+				Description: "Maximum time an active token is cached; tokens expiring sooner are cached until their exp",
+				Minimum:     float64Ptr(1),
+				Default:     60,
+			},
+			"introspection_negative_ttl_seconds": {
+				Type:        "integer",
+				Description: "Time inactive tokens and introspection failures are cached",
+				Minimum:     float64Ptr(1),
+				Default:     5,
+			},
+			"introspection_timeout_seconds": {
+				Type:        "integer",
+				Description: "Timeout for introspection requests",
+				Minimum:     float64Ptr(1),
+				Default:     5,
+			},
 		},
 	}
 	r.RegisterSchema("auth", authSchema)
 
 	// Rate Limit Plugin Schema
 	rateLimitSchema := &JSONSchema{
-		Schema:  "http://json-schema.org/draft-07/schema#",
-		Type:    "object",
-		Title:   "Rate Limit Plugin Configuration",
-		Version: CurrentVersion,
+		Schema:      "http://json-schema.org/draft-07/schema#",
+		Type:        "object",
+		Title:       "Rate Limit Plugin Configuration",
+		Description: "At least one of global_requests_per_second, ip_requests_per_second or user_requests_per_second must be greater than zero.",
+		Version:     CurrentVersion,
 		Properties: map[string]JSONSchemaProperty{
 			"global_requests_per_second": {
 				Type:        "number",
@@ -837,13 +1220,13 @@ func (r *PluginConfigRegistry) registerBuiltinSchemas() {
 				Type:        "number",
 				Description: "Per-IP requests per second limit",
 				Minimum:     float64Ptr(0),
-				Default:     10.0,
+				Default:     0.0,
 			},
 			"ip_burst": {
 				Type:        "integer",
-				Description: "Per-IP burst size",
-				Minimum:     float64Ptr(1),
-				Default:     20,
+				Description: "Per-IP burst size; 0 uses ip_requests_per_second",
+				Minimum:     float64Ptr(0),
+				Default:     0,
 			},
 			"user_requests_per_second": {
 				Type:        "number",
@@ -878,24 +1261,34 @@ func (r *PluginConfigRegistry) registerBuiltinSchemas() {
 				Minimum:     float64Ptr(1),
 				Default:     1800,
 			},
+			"response_body": {
+				Type:        "string",
+				Description: "Custom 429 response body; {limit_type} and {retry_after} are substituted. Defaults to JSON or plain text depending on the Accept header",
+			},
+			"response_content_type": {
+				Type:        "string",
+				Description: "Content type of the custom 429 response body",
+				Default:     "application/json",
+			},
 		},
 	}
 	r.RegisterSchema("rate_limit", rateLimitSchema)
 
 	// CORS Plugin Schema
 	corsSchema := &JSONSchema{
-		Schema:  "http://json-schema.org/draft-07/schema#",
-		Type:    "object",
-		Title:   "CORS Plugin Configuration",
-		Version: CurrentVersion,
+		Schema:      "http://json-schema.org/draft-07/schema#",
+		Type:        "object",
+		Title:       "CORS Plugin Configuration",
+		Description: "origin_patterns must be valid regular expressions. allow_origins entries may use *.example.com or https://*.example.com to match subdomains. The wildcard origin (*) cannot be combined with allow_credentials.",
+		Version:     CurrentVersion,
 		Properties: map[string]JSONSchemaProperty{
 			"allow_origins": {
 				Type:        "array",
-				Description: "List of allowed origins",
+				Description: "List of allowed origins; when empty only origin_patterns are allowed",
 				Items: &JSONSchemaProperty{
 					Type: "string",
 				},
-				Default: []interface{}{"*"},
+				Default: []interface{}{},
 			},
 			"allow_methods": {
 				Type:        "array",
@@ -947,10 +1340,11 @@ func (r *PluginConfigRegistry) registerBuiltinSchemas() {
 
 	// Logging Plugin Schema
 	loggingSchema := &JSONSchema{
-		Schema:  "http://json-schema.org/draft-07/schema#",
-		Type:    "object",
-		Title:   "Logging Plugin Configuration",
-		Version: CurrentVersion,
+		Schema:      "http://json-schema.org/draft-07/schema#",
+		Type:        "object",
+		Title:       "Logging Plugin Configuration",
+		Description: "When request or response body logging is enabled, max_body_size should not exceed 10MB.",
+		Version:     CurrentVersion,
 		Properties: map[string]JSONSchemaProperty{
 			"log_level": {
 				Type:        "string",
@@ -1001,15 +1395,78 @@ func (r *PluginConfigRegistry) registerBuiltinSchemas() {
 				Description: "Whether to include performance metrics in logs",
 				Default:     true,
 			},
+			"async_post_process": {
+				Type:        "boolean",
+				Description: "Log responses on a background worker after they are sent",
+				Default:     false,
+			},
+			"sample_rate": {
+				Type:        "number",
+				Description: "Fraction of successful requests logged; 4xx and 5xx are always logged",
+				Minimum:     float64Ptr(0),
+				Maximum:     float64Ptr(1),
+				Default:     1.0,
+			},
+			"output": {
+				Type:        "string",
+				Description: "Where request logs go: stdout, stderr, syslog or a file path; empty shares the server logger",
+			},
 		},
 	}
 	r.RegisterSchema("logging", loggingSchema)
 
+	// Versioning Plugin Schema
+	versioningSchema := &JSONSchema{
+		Schema:      "http://json-schema.org/draft-07/schema#",
+		Type:        "object",
+		Title:       "Versioning Plugin Configuration",
+		Description: "default_version must be one of supported_versions when both are set.",
+		Version:     CurrentVersion,
+		Properties: map[string]JSONSchemaProperty{
+			"source": {
+				Type:        "string",
+				Description: "Where the requested version is read from",
+				Enum:        []interface{}{"accept", "header", "path"},
+				Default:     "accept",
+			},
+			"header": {
+				Type:        "string",
+				Description: "Header carrying the version when source is 'header'",
+				Default:     "X-API-Version",
+			},
+			"default_version": {
+				Type:        "string",
+				Description: "Version assumed when the request does not specify one",
+			},
+			"supported_versions": {
+				Type:        "array",
+				Description: "List of accepted versions; other versions are rejected with 400",
+				Items: &JSONSchemaProperty{
+					Type:    "string",
+					Pattern: `^[vV]?\d+(\.\d+)?$`,
+				},
+				Default: []interface{}{},
+			},
+			"strip_path_prefix": {
+				Type:        "boolean",
+				Description: "Remove the /vN prefix before routing when source is 'path'",
+				Default:     false,
+			},
+			"overrides": {
+				Type:        "object",
+				Description: "Version-specific responses keyed by version and then path",
+				Default:     map[string]interface{}{},
+			},
+		},
+	}
+	r.RegisterSchema("versioning", versioningSchema)
+
 	// Register custom validators for more complex validation logic
 	r.RegisterValidator("auth", r.validateAuthConfig)
 	r.RegisterValidator("rate_limit", r.validateRateLimitConfig)
 	r.RegisterValidator("cors", r.validateCORSConfig)
 	r.RegisterValidator("logging", r.validateLoggingConfig)
+	r.RegisterValidator("versioning", r.validateVersioningConfig)
 }
 
 // Custom validation functions for built-in plugins
@@ -1019,16 +1476,19 @@ func (r *PluginConfigRegistry) validateAuthConfig(config map[string]interface{})
 	var errors []ConfigValidationError
 	
 	// Validate that at least one authentication method is configured
-	hasJWT := config["jwt_secret"] != nil || config["jwt_public_key"] != nil
+	hasSecret := config["jwt_secret"] != nil || config["jwt_secret_file"] != nil
+	hasPublicKey := config["jwt_public_key"] != nil || config["jwt_public_key_file"] != nil
+	hasJWT := hasSecret || hasPublicKey
 	hasAPIKeys := false
 	if apiKeys, ok := config["api_keys"].(map[string]interface{}); ok && len(apiKeys) > 0 {
 		hasAPIKeys = true
 	}
+	hasIntrospection := config["introspection_url"] != nil
 	
-	if !hasJWT && !hasAPIKeys {
+	if !hasJWT && !hasAPIKeys && !hasIntrospection {
 		errors = append(errors, ConfigValidationError{
 			Field:   "auth",
-			Message: "at least one authentication method must be configured (JWT or API keys)",
+			Message: "at least one authentication method must be configured (JWT, API keys or token introspection)",
 			Rule:    "custom",
 		})
 	}
@@ -1036,24 +1496,46 @@ func (r *PluginConfigRegistry) validateAuthConfig(config map[string]interface{})
 	// Validate JWT configuration consistency
 	if jwtMethod, ok := config["jwt_method"].(string); ok {
 		if strings.HasPrefix(jwtMethod, "HS") {
-			if config["jwt_secret"] == nil {
+			if !hasSecret {
 				errors = append(errors, ConfigValidationError{
 					Field:   "jwt_secret",
-					Message: "jwt_secret is required for HMAC-based JWT methods",
+					Message: "jwt_secret is required for HMAC-based JWT methods (inline or via jwt_secret_file)",
 					Rule:    "custom",
 				})
 			}
-		} else if strings.HasPrefix(jwtMethod, "RS") {
-			if config["jwt_public_key"] == nil {
+		} else if strings.HasPrefix(jwtMethod, "RS") || strings.HasPrefix(jwtMethod, "ES") || jwtMethod == "EdDSA" {
+			if !hasPublicKey {
 				errors = append(errors, ConfigValidationError{
 					Field:   "jwt_public_key",
-					Message: "jwt_public_key is required for RSA-based JWT methods",
+					Message: "jwt_public_key is required for RSA, ECDSA and EdDSA JWT methods (inline or via jwt_public_key_file)",
 					Rule:    "custom",
 				})
 			}
 		}
 	}
 	
+	// Only known identity fields can be forwarded
+	if forwardHeaders, ok := config["forward_headers"].(map[string]interface{}); ok {
+		for field := range forwardHeaders {
+			if !slices.Contains(forwardableIdentityFields, field) {
+				errors = append(errors, ConfigValidationError{
+					Field:   "forward_headers." + field,
+					Message: fmt.Sprintf("unknown identity field (expected one of %s)", strings.Join(forwardableIdentityFields, ", ")),
+					Rule:    "custom",
+				})
+			}
+		}
+	}
+	
+	// Introspection client credentials are sent together
+	if config["introspection_client_secret"] != nil && config["introspection_client_id"] == nil {
+		errors = append(errors, ConfigValidationError{
+			Field:   "introspection_client_id",
+			Message: "introspection_client_id is required when introspection_client_secret is set",
+			Rule:    "custom",
+		})
+	}
+	
 	return errors
 }
 
@@ -1097,6 +1579,22 @@ func (r *PluginConfigRegistry) validateCORSConfig(config map[string]interface{})
 		}
 	}
 	
+	// Validate wildcard subdomain origins
+	if origins, ok := config["allow_origins"].([]interface{}); ok {
+		for i, origin := range origins {
+			if originStr, ok := origin.(string); ok && strings.Contains(originStr, "*.") {
+				if _, valid := parseWildcardOrigin(originStr); !valid {
+					errors = append(errors, ConfigValidationError{
+						Field:   fmt.Sprintf("allow_origins[%d]", i),
+						Value:   origin,
+						Message: "wildcard origins must look like *.example.com or https://*.example.com",
+						Rule:    "custom",
+					})
+				}
+			}
+		}
+	}
+	
 	// Validate credentials and wildcard origin combination
 	if allowCredentials, ok := config["allow_credentials"].(bool); ok && allowCredentials {
 		if origins, ok := config["allow_origins"].([]interface{}); ok {
@@ -1140,6 +1638,34 @@ func (r *PluginConfigRegistry) validateLoggingConfig(config map[string]interface
 	return errors
 }
 
+// validateVersioningConfig provides custom validation for versioning plugin configuration
+func (r *PluginConfigRegistry) validateVersioningConfig(config map[string]interface{}) []ConfigValidationError {
+	var errors []ConfigValidationError
+	
+	// The default version must itself be accepted
+	defaultVersion, _ := config["default_version"].(string)
+	supported, _ := config["supported_versions"].([]interface{})
+	if defaultVersion != "" && len(supported) > 0 {
+		found := false
+		for _, version := range supported {
+			if versionStr, ok := version.(string); ok && normalizeVersion(versionStr) == normalizeVersion(defaultVersion) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errors = append(errors, ConfigValidationError{
+				Field:   "default_version",
+				Value:   defaultVersion,
+				Message: "default_version must be one of supported_versions",
+				Rule:    "custom",
+			})
+		}
+	}
+	
+	return errors
+}
+
 // Helper functions for schema definitions
 
 // intPtr returns a pointer to an int
@@ -1,9 +1,14 @@
 package plugins
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"go.uber.org/zap"
 )
 
 func TestPluginConfigRegistry(t *testing.T) {
@@ -14,6 +19,7 @@ func TestPluginConfigRegistry(t *testing.T) {
 		{"RegisterSchema", testRegisterSchema},
 		{"ValidateConfig", testValidateConfig},
 		{"EnvironmentSubstitution", testEnvironmentSubstitution},
+		{"RequiredEnvironmentVariables", testRequiredEnvironmentVariables},
 		{"DefaultConfig", testDefaultConfig},
 		{"ConfigMigration", testConfigMigration},
 		{"BuiltinPluginValidation", testBuiltinPluginValidation},
@@ -108,7 +114,10 @@ func testEnvironmentSubstitution(t *testing.T) {
 		},
 	}
 	
-	result := registry.substituteEnvironmentVariables(config)
+	result, err := registry.substituteEnvironmentVariables(config)
+	if err != nil {
+		t.Fatalf("substituteEnvironmentVariables() error: %v", err)
+	}
 	
 	if result["jwt_secret"] != "test-secret-value" {
 		t.Errorf("Expected 'test-secret-value', got '%v'", result["jwt_secret"])
@@ -128,6 +137,58 @@ func testEnvironmentSubstitution(t *testing.T) {
 	}
 }
 
+func testRequiredEnvironmentVariables(t *testing.T) {
+	registry := NewPluginConfigRegistry()
+	config := map[string]interface{}{
+		"jwt_secret": "${TEST_REQUIRED_SECRET:?JWT secret must be provided}",
+		"nested": map[string]interface{}{
+			"keys": []interface{}{"${TEST_REQUIRED_KEY:?}"},
+		},
+	}
+
+	// Unset required variables fail with the configured message
+	os.Unsetenv("TEST_REQUIRED_SECRET")
+	t.Setenv("TEST_REQUIRED_KEY", "key-value")
+
+	_, err := registry.substituteEnvironmentVariables(config)
+	if err == nil {
+		t.Fatal("Expected error for unset required variable")
+	}
+	if !errors.Is(err, ErrRequiredEnvVarUnset) {
+		t.Errorf("Expected ErrRequiredEnvVarUnset, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "TEST_REQUIRED_SECRET: JWT secret must be provided") {
+		t.Errorf("Expected error to name the variable and message, got %v", err)
+	}
+
+	// An empty value counts as unset
+	t.Setenv("TEST_REQUIRED_SECRET", "")
+	if _, err := registry.substituteEnvironmentVariables(config); !errors.Is(err, ErrRequiredEnvVarUnset) {
+		t.Errorf("Expected ErrRequiredEnvVarUnset for empty variable, got %v", err)
+	}
+
+	// Set variables substitute normally
+	t.Setenv("TEST_REQUIRED_SECRET", "super-secret")
+	result, err := registry.substituteEnvironmentVariables(config)
+	if err != nil {
+		t.Fatalf("Unexpected error when required variables are set: %v", err)
+	}
+	if result["jwt_secret"] != "super-secret" {
+		t.Errorf("Expected 'super-secret', got '%v'", result["jwt_secret"])
+	}
+	keys := result["nested"].(map[string]interface{})["keys"].([]interface{})
+	if keys[0] != "key-value" {
+		t.Errorf("Expected 'key-value', got '%v'", keys[0])
+	}
+
+	// A message-less required variable still fails clearly
+	os.Unsetenv("TEST_REQUIRED_KEY")
+	_, err = registry.substituteEnvironmentVariables(config)
+	if err == nil || !strings.Contains(err.Error(), "TEST_REQUIRED_KEY: must be set") {
+		t.Errorf("Expected default message for TEST_REQUIRED_KEY, got %v", err)
+	}
+}
+
 func testDefaultConfig(t *testing.T) {
 	// Test getting default config for auth plugin
 	defaults := GetDefaultConfig("auth")
@@ -260,6 +321,25 @@ func testBuiltinPluginValidation(t *testing.T) {
 			},
 			expectValid: true,
 		},
+		{
+			pluginName: "versioning",
+			config: map[string]interface{}{
+				"source":             "header",
+				"supported_versions": []interface{}{"v1", "v2"},
+				"default_version":    "v1",
+			},
+			expectValid: true,
+		},
+		{
+			pluginName: "versioning",
+			config: map[string]interface{}{
+				"source":             "path",
+				"supported_versions": []interface{}{"v1"},
+				"default_version":    "v3",
+			},
+			expectValid: false,
+			expectError: "default_version must be one of supported_versions",
+		},
 	}
 	
 	for _, tc := range testCases {
@@ -646,4 +726,333 @@ func BenchmarkCreatePluginFromConfig(b *testing.B) {
 		}
 		plugin.Cleanup(nil) // Clean up
 	}
-}
\ No newline at end of file
+}
+func TestPluginConfigRegistry_SchemaJSON(t *testing.T) {
+	registry := NewPluginConfigRegistry()
+
+	document, err := registry.SchemaJSON("auth")
+	if err != nil {
+		t.Fatalf("Expected auth schema, got error: %v", err)
+	}
+
+	var schema struct {
+		Description string `json:"description"`
+		Properties  map[string]struct {
+			Enum []string `json:"enum"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(document, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+
+	enum := schema.Properties["jwt_method"].Enum
+	if len(enum) == 0 {
+		t.Fatal("Expected jwt_method to have an enum")
+	}
+	for _, method := range []string{"HS256", "RS256"} {
+		found := false
+		for _, value := range enum {
+			if value == method {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected jwt_method enum to contain %s, got %v", method, enum)
+		}
+	}
+	if !strings.Contains(schema.Description, "jwt_secret") {
+		t.Errorf("Expected description to mention custom constraints, got %q", schema.Description)
+	}
+
+	if _, err := registry.SchemaJSON("missing"); err == nil {
+		t.Error("Expected error for unknown plugin")
+	}
+}
+
+func TestPluginConfigRegistry_CombinedSchemaJSON(t *testing.T) {
+	registry := NewPluginConfigRegistry()
+
+	document, err := registry.CombinedSchemaJSON()
+	if err != nil {
+		t.Fatalf("Expected combined schema, got error: %v", err)
+	}
+
+	var combined struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(document, &combined); err != nil {
+		t.Fatalf("Combined schema is not valid JSON: %v", err)
+	}
+
+	for _, name := range registry.SchemaNames() {
+		if _, ok := combined.Properties[name]; !ok {
+			t.Errorf("Expected combined schema to include %s", name)
+		}
+	}
+	if len(combined.Properties) == 0 {
+		t.Error("Expected combined schema to include plugin schemas")
+	}
+}
+
+func TestPluginConfigRegistry_NestedRequiredProperties(t *testing.T) {
+	registry := NewPluginConfigRegistry()
+	registry.RegisterSchema("nested", &JSONSchema{
+		Type: "object",
+		Properties: map[string]JSONSchemaProperty{
+			"upstream": {
+				Type: "object",
+				Properties: map[string]JSONSchemaProperty{
+					"url":     {Type: "string", Required: true},
+					"timeout": {Type: "integer"},
+					"tls": {
+						Type: "object",
+						Properties: map[string]JSONSchemaProperty{
+							"cert_file": {Type: "string"},
+							"key_file":  {Type: "string"},
+						},
+						RequiredProperties: []string{"cert_file", "key_file"},
+					},
+				},
+			},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		fields []string
+	}{
+		{
+			name:   "parent object absent",
+			config: map[string]interface{}{},
+		},
+		{
+			name: "all required children present",
+			config: map[string]interface{}{
+				"upstream": map[string]interface{}{
+					"url": "http://localhost:9000",
+					"tls": map[string]interface{}{"cert_file": "cert.pem", "key_file": "key.pem"},
+				},
+			},
+		},
+		{
+			name: "required flag child missing",
+			config: map[string]interface{}{
+				"upstream": map[string]interface{}{"timeout": float64(5)},
+			},
+			fields: []string{"upstream.url"},
+		},
+		{
+			name: "required list children missing",
+			config: map[string]interface{}{
+				"upstream": map[string]interface{}{
+					"url": "http://localhost:9000",
+					"tls": map[string]interface{}{"cert_file": "cert.pem"},
+				},
+			},
+			fields: []string{"upstream.tls.key_file"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := registry.ValidateConfig("nested", tt.config)
+
+			var fields []string
+			for _, err := range result.Errors {
+				if err.Rule != "required" {
+					t.Errorf("Unexpected %s error on %s: %s", err.Rule, err.Field, err.Message)
+				}
+				fields = append(fields, err.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("Expected required errors on %v, got %v", tt.fields, fields)
+			}
+			if result.Valid != (len(tt.fields) == 0) {
+				t.Errorf("Expected valid=%v, got %v", len(tt.fields) == 0, result.Valid)
+			}
+		})
+	}
+}
+
+func TestPluginConfigRegistry_TypedEnvironmentSubstitution(t *testing.T) {
+	t.Setenv("IP_RATE", "25")
+	t.Setenv("BURST", "40")
+	t.Setenv("VERBOSE", "true")
+	t.Setenv("NOT_A_NUMBER", "fast")
+	t.Setenv("OFFICE_IP", "10.0.0.1")
+
+	registry := NewPluginConfigRegistry()
+
+	result, err := registry.substitutePluginEnvironmentVariables("rate_limit", map[string]interface{}{
+		"ip_requests_per_second":   "${IP_RATE}",
+		"ip_burst":                 "${BURST}",
+		"user_requests_per_second": "${UNSET_USER_RATE:12.5}",
+		"exempt_ips":               []interface{}{"${OFFICE_IP}"},
+	})
+	if err != nil {
+		t.Fatalf("substitutePluginEnvironmentVariables() error: %v", err)
+	}
+
+	if rate, ok := result["ip_requests_per_second"].(float64); !ok || rate != 25 {
+		t.Errorf("Expected ip_requests_per_second to be float64 25, got %T %v", result["ip_requests_per_second"], result["ip_requests_per_second"])
+	}
+	if burst, ok := result["ip_burst"].(int64); !ok || burst != 40 {
+		t.Errorf("Expected ip_burst to be int64 40, got %T %v", result["ip_burst"], result["ip_burst"])
+	}
+	if rate, ok := result["user_requests_per_second"].(float64); !ok || rate != 12.5 {
+		t.Errorf("Expected default to be coerced to float64 12.5, got %T %v", result["user_requests_per_second"], result["user_requests_per_second"])
+	}
+	if ips := result["exempt_ips"].([]interface{}); ips[0] != "10.0.0.1" {
+		t.Errorf("Expected string array item to stay a string, got %T %v", ips[0], ips[0])
+	}
+	if validation := registry.ValidateConfig("rate_limit", result); !validation.Valid {
+		t.Errorf("Expected substituted config to be valid, got %+v", validation.Errors)
+	}
+
+	registry.RegisterSchema("typed", &JSONSchema{
+		Type: "object",
+		Properties: map[string]JSONSchemaProperty{
+			"verbose":  {Type: "boolean"},
+			"label":    {Type: "string"},
+			"rate":     {Type: "number"},
+			"embedded": {Type: "number"},
+		},
+	})
+
+	result, err = registry.substitutePluginEnvironmentVariables("typed", map[string]interface{}{
+		"verbose":  "${VERBOSE}",
+		"label":    "${IP_RATE}",
+		"rate":     "${NOT_A_NUMBER}",
+		"embedded": "${IP_RATE}0",
+	})
+	if err != nil {
+		t.Fatalf("substitutePluginEnvironmentVariables() error: %v", err)
+	}
+
+	if result["verbose"] != true {
+		t.Errorf("Expected verbose to be bool true, got %T %v", result["verbose"], result["verbose"])
+	}
+	if result["label"] != "25" {
+		t.Errorf("Expected string-typed field to stay a string, got %T %v", result["label"], result["label"])
+	}
+	if result["embedded"] != "250" {
+		t.Errorf("Expected embedded substitution to stay a string, got %T %v", result["embedded"], result["embedded"])
+	}
+	if result["rate"] != "fast" {
+		t.Errorf("Expected unparsable value to stay a string, got %T %v", result["rate"], result["rate"])
+	}
+	if validation := registry.ValidateConfig("typed", result); validation.Valid {
+		t.Error("Expected unparsable number to fail type validation")
+	}
+}
+
+func TestValidatePluginConfig_NumericEnvironmentVariable(t *testing.T) {
+	t.Setenv("IP_RATE", "25")
+
+	err := ValidatePluginConfig("rate_limit", map[string]interface{}{
+		"ip_requests_per_second": "${IP_RATE}",
+	})
+	if err != nil {
+		t.Errorf("Expected numeric env var to satisfy the number type, got %v", err)
+	}
+}
+
+func TestPluginConfigRegistry_ApplyDefaults(t *testing.T) {
+	registry := NewPluginConfigRegistry()
+	registry.RegisterSchema("defaults", &JSONSchema{
+		Type:    "object",
+		Version: CurrentVersion,
+		Properties: map[string]JSONSchemaProperty{
+			"enabled": {Type: "boolean", Default: true},
+			"name":    {Type: "string", Default: "fallback"},
+			"tags":    {Type: "array", Default: []interface{}{"a"}},
+			"limits": {
+				Type: "object",
+				Properties: map[string]JSONSchemaProperty{
+					"max": {Type: "integer", Default: 10},
+					"min": {Type: "integer", Default: 1},
+				},
+			},
+		},
+	})
+
+	user := map[string]interface{}{
+		"enabled": false,
+		"name":    nil,
+		"limits":  map[string]interface{}{"max": 0},
+		"extra":   "kept",
+	}
+	merged := registry.ApplyDefaults("defaults", user)
+
+	// Explicit zero values override, nil and missing keys take the default
+	if merged["enabled"] != false {
+		t.Errorf("Expected explicit false to be kept, got %v", merged["enabled"])
+	}
+	if merged["name"] != "fallback" {
+		t.Errorf("Expected nil name to take the default, got %v", merged["name"])
+	}
+	if merged["extra"] != "kept" {
+		t.Errorf("Expected unknown key to be kept, got %v", merged["extra"])
+	}
+	limits := merged["limits"].(map[string]interface{})
+	if limits["max"] != 0 || limits["min"] != 1 {
+		t.Errorf("Expected nested defaults merged under explicit values, got %v", limits)
+	}
+	if _, changed := user["limits"].(map[string]interface{})["min"]; changed {
+		t.Error("ApplyDefaults must not modify the user configuration")
+	}
+
+	// List defaults are copied per call
+	merged["tags"].([]interface{})[0] = "changed"
+	if tags := registry.ApplyDefaults("defaults", nil)["tags"].([]interface{}); tags[0] != "a" {
+		t.Errorf("Expected schema default to be unchanged, got %v", tags)
+	}
+
+	// Plugins without a schema get their configuration back as is
+	if result := registry.ApplyDefaults("unknown", user); len(result) != len(user) {
+		t.Errorf("Expected configuration without schema to be unchanged, got %v", result)
+	}
+}
+
+func TestLoadPlugin_AppliesSchemaDefaults(t *testing.T) {
+	authConfig := map[string]interface{}{
+		"jwt_secret": "this-is-a-very-long-secret-key-for-testing-purposes",
+		"api_keys":   map[string]interface{}{"test-key": "user-1"},
+	}
+
+	plugin, err := CreatePluginFromConfig("auth", authConfig)
+	if err != nil {
+		t.Fatalf("Failed to create auth plugin: %v", err)
+	}
+	if header := plugin.(*AuthPlugin).authHeader; header != "Authorization" {
+		t.Errorf("Expected omitted auth_header to take the schema default, got %q", header)
+	}
+
+	// The schema, not the plugin, decides the default
+	registry := NewPluginConfigRegistry()
+	schema, _ := registry.GetSchema("auth")
+	property := schema.Properties["auth_header"]
+	property.Default = "X-Api-Key"
+	schema.Properties["auth_header"] = property
+	SetConfigRegistry(registry)
+	defer resetConfigRegistry()
+
+	manager := NewManager(zap.NewNop())
+	if err := RegisterBuiltinPlugins(manager.GetRegistry()); err != nil {
+		t.Fatalf("Failed to register builtin plugins: %v", err)
+	}
+	if err := manager.LoadPlugin("auth", authConfig); err != nil {
+		t.Fatalf("Failed to load auth plugin: %v", err)
+	}
+	if err := manager.EnablePlugin("auth"); err != nil {
+		t.Fatalf("Failed to enable auth plugin: %v", err)
+	}
+	loaded, _ := manager.GetPlugin("auth")
+	if header := loaded.(*AuthPlugin).authHeader; header != "X-Api-Key" {
+		t.Errorf("Expected auth_header from the schema default, got %q", header)
+	}
+	if _, set := authConfig["auth_header"]; set {
+		t.Error("LoadPlugin must not modify the caller's configuration")
+	}
+}
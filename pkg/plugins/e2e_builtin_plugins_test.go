@@ -1,9 +1,7 @@
-package plugins
+package plugins_test
 
 import (
-	"bytes"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -13,10 +11,13 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 	"vanta/pkg/api"
 	"vanta/pkg/config"
 	"vanta/pkg/openapi"
+	"vanta/pkg/plugins"
 )
 
 // TestBuiltinPluginsE2E tests all built-in plugins working together in realistic scenarios
@@ -25,7 +26,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 
 	// Create a comprehensive OpenAPI spec for testing
 	spec := &openapi.Specification{
-		OpenAPI: "3.0.0",
+		Version: "3.0.0",
 		Info: openapi.InfoObject{
 			Title:       "E2E Test API",
 			Version:     "1.0.0",
@@ -33,14 +34,13 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		},
 		Paths: map[string]openapi.PathItem{
 			"/api/users": {
-				Get: &openapi.Operation{
-					OperationId: "getUsers",
+				GET: &openapi.Operation{
+					OperationID: "getUsers",
 					Summary:     "Get all users",
-					Tags:        []string{"users"},
 					Responses: map[string]openapi.Response{
 						"200": {
 							Description: "List of users",
-							Content: map[string]openapi.MediaType{
+							Content: map[string]openapi.MediaTypeObject{
 								"application/json": {
 									Schema: &openapi.Schema{
 										Type: "array",
@@ -60,13 +60,12 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 						"429": {Description: "Rate limit exceeded"},
 					},
 				},
-				Post: &openapi.Operation{
-					OperationId: "createUser",
+				POST: &openapi.Operation{
+					OperationID: "createUser",
 					Summary:     "Create a new user",
-					Tags:        []string{"users"},
 					RequestBody: &openapi.RequestBody{
 						Required: true,
-						Content: map[string]openapi.MediaType{
+						Content: map[string]openapi.MediaTypeObject{
 							"application/json": {
 								Schema: &openapi.Schema{
 									Type: "object",
@@ -80,20 +79,29 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 						},
 					},
 					Responses: map[string]openapi.Response{
-						"201": {Description: "User created"},
+						"201": {
+							Description: "User created",
+							Content: map[string]openapi.MediaTypeObject{
+								"application/json": {
+									Schema: &openapi.Schema{
+										Type: "object",
+										Properties: map[string]*openapi.Schema{
+											"id":   {Type: "integer"},
+											"name": {Type: "string"},
+										},
+									},
+								},
+							},
+						},
 						"400": {Description: "Bad request"},
 						"401": {Description: "Unauthorized"},
 					},
 				},
 			},
 			"/api/admin/users": {
-				Get: &openapi.Operation{
-					OperationId: "getAdminUsers",
+				GET: &openapi.Operation{
+					OperationID: "getAdminUsers",
 					Summary:     "Get users (admin only)",
-					Tags:        []string{"admin"},
-					Security: []map[string][]string{
-						{"bearerAuth": {"admin"}},
-					},
 					Responses: map[string]openapi.Response{
 						"200": {Description: "Admin users list"},
 						"401": {Description: "Unauthorized"},
@@ -102,8 +110,8 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 				},
 			},
 			"/health": {
-				Get: &openapi.Operation{
-					OperationId: "healthCheck",
+				GET: &openapi.Operation{
+					OperationID: "healthCheck",
 					Summary:     "Health check endpoint",
 					Responses: map[string]openapi.Response{
 						"200": {Description: "Service healthy"},
@@ -111,8 +119,8 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 				},
 			},
 			"/metrics": {
-				Get: &openapi.Operation{
-					OperationId: "getMetrics",
+				GET: &openapi.Operation{
+					OperationID: "getMetrics",
 					Summary:     "Get service metrics",
 					Responses: map[string]openapi.Response{
 						"200": {Description: "Service metrics"},
@@ -120,27 +128,13 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 				},
 			},
 		},
-		Components: &openapi.Components{
-			SecuritySchemes: map[string]openapi.SecurityScheme{
-				"bearerAuth": {
-					Type:         "http",
-					Scheme:       "bearer",
-					BearerFormat: "JWT",
-				},
-				"apiKey": {
-					Type: "apiKey",
-					In:   "header",
-					Name: "X-API-Key",
-				},
-			},
-		},
 	}
 
 	t.Run("AuthenticationFlows", func(t *testing.T) {
 		cfg := &config.Config{
 			Server: config.ServerConfig{
 				Host:         "127.0.0.1",
-				Port:         8894,
+				Port:         0,
 				ReadTimeout:  30 * time.Second,
 				WriteTimeout: 30 * time.Second,
 				Concurrency:  256,
@@ -183,7 +177,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		defer server.Stop()
 
 		time.Sleep(100 * time.Millisecond)
-		baseURL := fmt.Sprintf("http://%s", server.GetAddr())
+		baseURL := "http://" + server.ListenAddrs()[0]
 
 		// Test 1: Public endpoints are accessible without authentication
 		t.Run("PublicEndpoints", func(t *testing.T) {
@@ -215,7 +209,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		// Test 3: API Key authentication
 		t.Run("APIKeyAuthentication", func(t *testing.T) {
 			req, _ := http.NewRequest("GET", baseURL+"/api/users", nil)
-			req.Header.Set("Authorization", "Bearer user-api-key")
+			req.Header.Set("Authorization", "user-api-key")
 			
 			client := &http.Client{Timeout: 5 * time.Second}
 			resp, err := client.Do(req)
@@ -224,7 +218,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 			resp.Body.Close()
 
 			// Test invalid API key
-			req.Header.Set("Authorization", "Bearer invalid-key")
+			req.Header.Set("Authorization", "invalid-key")
 			resp, err = client.Do(req)
 			require.NoError(t, err)
 			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
@@ -279,7 +273,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		cfg := &config.Config{
 			Server: config.ServerConfig{
 				Host:         "127.0.0.1",
-				Port:         8893,
+				Port:         0,
 				ReadTimeout:  30 * time.Second,
 				WriteTimeout: 30 * time.Second,
 				Concurrency:  256,
@@ -318,7 +312,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		defer server.Stop()
 
 		time.Sleep(100 * time.Millisecond)
-		baseURL := fmt.Sprintf("http://%s", server.GetAddr())
+		baseURL := "http://" + server.ListenAddrs()[0]
 
 		// Test rate limiting
 		client := &http.Client{Timeout: 5 * time.Second}
@@ -329,7 +323,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 
 		for i := 0; i < 10; i++ {
 			req, _ := http.NewRequest("GET", baseURL+"/api/users", nil)
-			req.Header.Set("Authorization", "Bearer rate-test-key")
+			req.Header.Set("Authorization", "rate-test-key")
 
 			resp, err := client.Do(req)
 			require.NoError(t, err)
@@ -357,7 +351,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		cfg := &config.Config{
 			Server: config.ServerConfig{
 				Host:         "127.0.0.1",
-				Port:         8892,
+				Port:         0,
 				ReadTimeout:  30 * time.Second,
 				WriteTimeout: 30 * time.Second,
 				Concurrency:  256,
@@ -398,7 +392,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		defer server.Stop()
 
 		time.Sleep(100 * time.Millisecond)
-		baseURL := fmt.Sprintf("http://%s", server.GetAddr())
+		baseURL := "http://" + server.ListenAddrs()[0]
 
 		client := &http.Client{Timeout: 5 * time.Second}
 
@@ -412,7 +406,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 			resp, err := client.Do(req)
 			require.NoError(t, err)
 
-			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
 			assert.Equal(t, "http://localhost:3000", resp.Header.Get("Access-Control-Allow-Origin"))
 			assert.Contains(t, resp.Header.Get("Access-Control-Allow-Methods"), "POST")
 			assert.Contains(t, resp.Header.Get("Access-Control-Allow-Headers"), "Authorization")
@@ -424,7 +418,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		t.Run("ActualCORSRequest", func(t *testing.T) {
 			req, _ := http.NewRequest("GET", baseURL+"/api/users", nil)
 			req.Header.Set("Origin", "https://example.com")
-			req.Header.Set("Authorization", "Bearer cors-test-key")
+			req.Header.Set("Authorization", "cors-test-key")
 
 			resp, err := client.Do(req)
 			require.NoError(t, err)
@@ -438,27 +432,27 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		t.Run("DisallowedOrigin", func(t *testing.T) {
 			req, _ := http.NewRequest("GET", baseURL+"/api/users", nil)
 			req.Header.Set("Origin", "http://malicious-site.com")
-			req.Header.Set("Authorization", "Bearer cors-test-key")
+			req.Header.Set("Authorization", "cors-test-key")
 
 			resp, err := client.Do(req)
 			require.NoError(t, err)
 
-			// Request should succeed but without CORS headers
-			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			// The CORS plugin rejects the origin outright
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
 			assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
 			resp.Body.Close()
 		})
 	})
 
 	t.Run("LoggingFunctionality", func(t *testing.T) {
-		// Create a custom logger to capture log output
-		logCapture := &LogCapture{}
-		logger := zaptest.NewLogger(t, zaptest.WrapOptions(logCapture.Hook()))
+		// Capture log output
+		core, logs := observer.New(zap.DebugLevel)
+		logger := zap.New(core)
 
 		cfg := &config.Config{
 			Server: config.ServerConfig{
 				Host:         "127.0.0.1",
-				Port:         8891,
+				Port:         0,
 				ReadTimeout:  30 * time.Second,
 				WriteTimeout: 30 * time.Second,
 				Concurrency:  256,
@@ -499,7 +493,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		defer server.Stop()
 
 		time.Sleep(100 * time.Millisecond)
-		baseURL := fmt.Sprintf("http://%s", server.GetAddr())
+		baseURL := "http://" + server.ListenAddrs()[0]
 
 		client := &http.Client{Timeout: 5 * time.Second}
 
@@ -507,7 +501,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		requestBody := `{"name":"John Doe","email":"john@example.com"}`
 		req, _ := http.NewRequest("POST", baseURL+"/api/users", strings.NewReader(requestBody))
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer logging-test-key")
+		req.Header.Set("Authorization", "logging-test-key")
 
 		resp, err := client.Do(req)
 		require.NoError(t, err)
@@ -518,17 +512,13 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		time.Sleep(100 * time.Millisecond)
 
 		// Verify that request was logged
-		logs := logCapture.GetLogs()
-		assert.Greater(t, len(logs), 0, "Should have captured log entries")
-
-		foundRequestLog := false
-		for _, logEntry := range logs {
-			if strings.Contains(logEntry, "POST") && strings.Contains(logEntry, "/api/users") {
-				foundRequestLog = true
-				break
-			}
-		}
-		assert.True(t, foundRequestLog, "Should have logged the request")
+		assert.Greater(t, logs.Len(), 0, "Should have captured log entries")
+
+		requestLogs := logs.FilterMessage("HTTP request").
+			FilterField(zap.String("plugin", "logging")).
+			FilterField(zap.String("method", "POST")).
+			FilterField(zap.String("path", "/api/users"))
+		assert.Equal(t, 1, requestLogs.Len(), "Should have logged the request")
 	})
 
 	t.Run("AllPluginsIntegration", func(t *testing.T) {
@@ -536,7 +526,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		cfg := &config.Config{
 			Server: config.ServerConfig{
 				Host:         "127.0.0.1",
-				Port:         8890,
+				Port:         0,
 				ReadTimeout:  30 * time.Second,
 				WriteTimeout: 30 * time.Second,
 				Concurrency:  256,
@@ -596,14 +586,14 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		defer server.Stop()
 
 		time.Sleep(100 * time.Millisecond)
-		baseURL := fmt.Sprintf("http://%s", server.GetAddr())
+		baseURL := "http://" + server.ListenAddrs()[0]
 
 		client := &http.Client{Timeout: 5 * time.Second}
 
 		// Test complex request flow through all plugins
 		req, _ := http.NewRequest("POST", baseURL+"/api/users", strings.NewReader(`{"name":"Test User","email":"test@example.com"}`))
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer integration-key")
+		req.Header.Set("Authorization", "integration-key")
 		req.Header.Set("Origin", "http://localhost:3000")
 
 		resp, err := client.Do(req)
@@ -626,7 +616,7 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		assert.Len(t, pluginStats, 4, "Should have all 4 plugins loaded")
 
 		for _, stat := range pluginStats {
-			assert.Equal(t, StateEnabled, stat.State, "Plugin %s should be enabled", stat.Name)
+			assert.Equal(t, plugins.StateEnabled, stat.State, "Plugin %s should be enabled", stat.Name)
 			assert.Greater(t, stat.Metrics.RequestsProcessed, int64(0), "Plugin %s should have processed requests", stat.Name)
 		}
 
@@ -637,31 +627,3 @@ func TestBuiltinPluginsE2E(t *testing.T) {
 		// Logging should record the request
 	})
 }
-
-// LogCapture helps capture log output for testing
-type LogCapture struct {
-	logs []string
-	mu   sync.RWMutex
-}
-
-func (lc *LogCapture) Hook() zaptest.LoggerOption {
-	return zaptest.Hook(func(e zaptest.LoggedEntry) {
-		lc.mu.Lock()
-		defer lc.mu.Unlock()
-		lc.logs = append(lc.logs, e.Message)
-	})
-}
-
-func (lc *LogCapture) GetLogs() []string {
-	lc.mu.RLock()
-	defer lc.mu.RUnlock()
-	result := make([]string, len(lc.logs))
-	copy(result, lc.logs)
-	return result
-}
-
-func (lc *LogCapture) Clear() {
-	lc.mu.Lock()
-	defer lc.mu.Unlock()
-	lc.logs = lc.logs[:0]
-}
\ No newline at end of file
@@ -216,7 +216,10 @@ func TestPluginConfigurationSystemIntegration(t *testing.T) {
 			},
 		}
 
-		result := registry.substituteEnvironmentVariables(config)
+		result, err := registry.substituteEnvironmentVariables(config)
+		if err != nil {
+			t.Fatalf("substituteEnvironmentVariables() error: %v", err)
+		}
 
 		// Check that environment variables were substituted
 		if result["jwt_secret"] != "integration-test-secret-key-that-is-very-long-for-security" {
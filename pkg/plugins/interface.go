@@ -2,13 +2,16 @@ package plugins
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
+	"vanta/pkg/config"
 )
 
 // Plugin represents the base interface that all plugins must implement.
@@ -57,7 +60,9 @@ type Middleware interface {
 	PreProcess(ctx *RequestContext) (bool, error)
 
 	// PostProcess is called after the main handler has processed the request.
-	// It can modify the response or perform cleanup operations.
+	// It can modify the response or perform cleanup operations. It runs exactly
+	// once for every middleware whose PreProcess completed, in reverse order,
+	// including when a later middleware short-circuited or failed.
 	PostProcess(ctx *ResponseContext) error
 
 	// ShouldApply determines if this middleware should be applied to the given request.
@@ -103,9 +108,10 @@ type RequestContext struct {
 	RequestCtx *fasthttp.RequestCtx
 
 	// Request metadata and processing state
-	RequestID  string
-	StartTime  time.Time
-	UserValues map[string]interface{}
+	RequestID   string
+	Fingerprint string // Stable request hash shared with logs and recordings
+	StartTime   time.Time
+	UserValues  map[string]interface{}
 
 	// Plugin-specific context for sharing data between plugins
 	PluginData map[string]interface{}
@@ -116,6 +122,15 @@ type RequestContext struct {
 	// Cancellation context
 	Context context.Context
 
+	// JSON request body parsed once and shared between plugins
+	jsonBody       interface{}
+	jsonBodyErr    error
+	jsonBodyParsed bool
+	jsonBodyDirty  bool // set when a plugin replaced the body and it needs re-serializing
+
+	// Proxies whose forwarding headers ClientIP believes
+	trustedProxies TrustedProxies
+
 	// Thread-safe access to shared data
 	mu sync.RWMutex
 }
@@ -133,6 +148,10 @@ type ResponseContext struct {
 
 	// Error that occurred during request processing (if any)
 	ProcessingError error
+
+	// ShortCircuitedBy names the middleware whose PreProcess stopped the
+	// chain; empty when the main handler ran
+	ShortCircuitedBy string
 }
 
 // RequestResult represents the result of request processing by a plugin.
@@ -225,6 +244,10 @@ var (
 	ErrPluginConfigInvalid = errors.New("plugin configuration invalid")
 	ErrPluginNotEnabled    = errors.New("plugin not enabled")
 	ErrPluginTimeout       = errors.New("plugin operation timed out")
+	ErrRequiredEnvVarUnset = errors.New("required environment variable not set")
+	ErrPriorityConflict    = errors.New("plugin priority conflict")
+	ErrBodyNotJSON         = errors.New("request body is not JSON")
+	ErrPluginNotPermitted  = errors.New("plugin not permitted")
 )
 
 // NewPluginError creates a new plugin error.
@@ -303,16 +326,166 @@ func (rc *RequestContext) Query(name string) string {
 	return string(rc.RequestCtx.QueryArgs().Peek(name))
 }
 
-// Body returns the request body.
+// Body returns the request body, re-serialized first if a plugin replaced
+// the parsed JSON body.
 func (rc *RequestContext) Body() []byte {
+	if err := rc.syncJSONBody(); err != nil && rc.Logger != nil {
+		rc.Logger.Warn("Failed to re-serialize modified JSON body", zap.Error(err))
+	}
 	return rc.RequestCtx.Request.Body()
 }
 
+// JSONBody returns the request body decoded as JSON. The body is parsed on
+// first use and the same value is returned to every later caller, so plugins
+// must not modify it in place without calling SetJSONBody. An empty body
+// returns nil; a non-JSON content type returns ErrBodyNotJSON.
+func (rc *RequestContext) JSONBody() (interface{}, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if !rc.jsonBodyParsed {
+		rc.jsonBodyParsed = true
+		rc.jsonBody, rc.jsonBodyErr = rc.parseJSONBody()
+	}
+	return rc.jsonBody, rc.jsonBodyErr
+}
+
+// SetJSONBody replaces the parsed JSON body seen by later plugins. The raw
+// request body is re-serialized lazily, before the handler runs or the next
+// time Body is read.
+func (rc *RequestContext) SetJSONBody(value interface{}) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.jsonBody = value
+	rc.jsonBodyErr = nil
+	rc.jsonBodyParsed = true
+	rc.jsonBodyDirty = true
+}
+
+// parseJSONBody decodes the raw request body; callers hold rc.mu
+func (rc *RequestContext) parseJSONBody() (interface{}, error) {
+	if rc.BodyCaptureDisabled() || !isJSONContentType(rc.ContentType()) {
+		return nil, ErrBodyNotJSON
+	}
+
+	body := rc.RequestCtx.Request.Body()
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, fmt.Errorf("invalid JSON request body: %w", err)
+	}
+	return value, nil
+}
+
+// syncJSONBody writes a replaced JSON body back to the raw request
+func (rc *RequestContext) syncJSONBody() error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if !rc.jsonBodyDirty {
+		return nil
+	}
+	rc.jsonBodyDirty = false
+
+	body, err := json.Marshal(rc.jsonBody)
+	if err != nil {
+		return err
+	}
+	rc.RequestCtx.Request.SetBody(body)
+	return nil
+}
+
+// isJSONContentType reports whether a Content-Type header carries JSON
+func isJSONContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// BodyCaptureDisabled reports whether the request is on a streaming path whose
+// body must not be buffered for logging or recording.
+func (rc *RequestContext) BodyCaptureDisabled() bool {
+	disabled, _ := rc.RequestCtx.UserValue(config.BodyCaptureDisabledKey).(bool)
+	return disabled
+}
+
+// snapshot returns a copy of the request context backed by a detached
+// fasthttp context holding copies of the request, response and user values,
+// so it stays valid after fasthttp reuses the original's buffers.
+func (rc *RequestContext) snapshot() *RequestContext {
+	original := rc.RequestCtx
+	detached := &fasthttp.RequestCtx{}
+	detached.Init(&original.Request, original.RemoteAddr(), nil)
+	original.Response.CopyTo(&detached.Response)
+	original.VisitUserValuesAll(func(key, value interface{}) {
+		detached.SetUserValue(key, value)
+	})
+
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	snapshot := &RequestContext{
+		RequestCtx:     detached,
+		RequestID:      rc.RequestID,
+		Fingerprint:    rc.Fingerprint,
+		StartTime:      rc.StartTime,
+		UserValues:     make(map[string]interface{}, len(rc.UserValues)),
+		PluginData:     make(map[string]interface{}, len(rc.PluginData)),
+		Logger:         rc.Logger,
+		Context:        rc.Context,
+		jsonBody:       rc.jsonBody,
+		jsonBodyErr:    rc.jsonBodyErr,
+		jsonBodyParsed: rc.jsonBodyParsed,
+		trustedProxies: rc.trustedProxies,
+	}
+	for key, value := range rc.UserValues {
+		snapshot.UserValues[key] = value
+	}
+	for key, value := range rc.PluginData {
+		snapshot.PluginData[key] = value
+	}
+	return snapshot
+}
+
+// snapshot copies the response context so it can outlive the request
+func (rc *ResponseContext) snapshot() *ResponseContext {
+	requestCtx := rc.RequestContext.snapshot()
+	snapshot := &ResponseContext{
+		RequestContext:   requestCtx,
+		ProcessingTime:   rc.ProcessingTime,
+		ProcessingError:  rc.ProcessingError,
+		ShortCircuitedBy: rc.ShortCircuitedBy,
+	}
+	if rc.ResponseBody != nil {
+		// The captured body is immutable; only a live one needs the copy
+		snapshot.ResponseBody = requestCtx.responseBody()
+	}
+	return snapshot
+}
+
+// responseBody returns the immutable body snapshot taken by the server's
+// response capture step, or the live response body when there is none
+func (rc *RequestContext) responseBody() []byte {
+	if body, ok := rc.RequestCtx.UserValue(config.ResponseBodyKey).([]byte); ok {
+		return body
+	}
+	return rc.RequestCtx.Response.Body()
+}
+
 // RemoteAddr returns the remote address of the client.
 func (rc *RequestContext) RemoteAddr() string {
 	return rc.RequestCtx.RemoteAddr().String()
 }
 
+// ClientIP returns the client's IP address. Forwarding headers are only
+// honored when the direct peer is one of the server's trusted proxies.
+func (rc *RequestContext) ClientIP() string {
+	return rc.trustedProxies.ClientIP(rc.RequestCtx)
+}
+
 // UserAgent returns the User-Agent header value.
 func (rc *RequestContext) UserAgent() string {
 	return string(rc.RequestCtx.UserAgent())
@@ -491,4 +664,40 @@ type HotReloadable interface {
 
 	// CanReload returns true if the plugin can be safely reloaded.
 	CanReload() bool
+}
+
+// AsyncPostProcessor represents a middleware whose PostProcess can run after
+// the response has been sent. The manager runs it on a background worker
+// with a snapshot of the request and response, so it must not try to modify
+// the response. When the worker queue is full, PostProcess runs inline.
+type AsyncPostProcessor interface {
+	Middleware
+
+	// AsyncPostProcess returns true to run PostProcess in the background.
+	AsyncPostProcess() bool
+}
+
+// DependencyProvider represents a plugin that requires other plugins.
+// The manager checks that every dependency is registered when the plugin is
+// loaded, and that every dependency is enabled before the plugin is enabled.
+type DependencyProvider interface {
+	Plugin
+
+	// GetDependencies returns the names of the plugins this plugin requires.
+	GetDependencies() []string
+}
+
+// Dependencies can be embedded in a plugin struct to declare its
+// dependencies without implementing DependencyProvider by hand:
+//
+//	type MyPlugin struct {
+//		plugins.Dependencies
+//	}
+//
+//	plugin := &MyPlugin{Dependencies: plugins.Dependencies{"auth"}}
+type Dependencies []string
+
+// GetDependencies returns a copy of the declared plugin names.
+func (d Dependencies) GetDependencies() []string {
+	return append([]string(nil), d...)
 }
\ No newline at end of file
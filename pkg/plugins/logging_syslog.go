@@ -0,0 +1,25 @@
+//go:build !windows && !plan9
+
+package plugins
+
+import (
+	"log/syslog"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// syslogSink adapts a syslog writer to zap; syslog has nothing to flush
+type syslogSink struct {
+	*syslog.Writer
+}
+
+func (s syslogSink) Sync() error { return nil }
+
+// openSyslogSink connects to the local syslog daemon
+func openSyslogSink() (zapcore.WriteSyncer, error) {
+	writer, err := syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, "vanta")
+	if err != nil {
+		return nil, err
+	}
+	return syslogSink{writer}, nil
+}
@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package plugins
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// openSyslogSink reports that syslog is unavailable on this platform
+func openSyslogSink() (zapcore.WriteSyncer, error) {
+	return nil, fmt.Errorf("syslog output is not supported on this platform")
+}
@@ -2,8 +2,12 @@ package plugins
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,6 +15,7 @@ import (
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 	"vanta/pkg/config"
+	"vanta/pkg/tracing"
 )
 
 // PluginState represents the current state of a plugin
@@ -45,6 +50,42 @@ type PluginMetrics struct {
 	AverageLatency    time.Duration `json:"average_latency"`
 	LastUsed          time.Time     `json:"last_used"`
 	TotalLatency      time.Duration `json:"total_latency"`
+	PreProcess        PhaseLatency  `json:"pre_process"`
+	PostProcess       PhaseLatency  `json:"post_process"`
+}
+
+// Request processing phases tracked in plugin metrics
+const (
+	PhasePreProcess  = "pre_process"
+	PhasePostProcess = "post_process"
+)
+
+// PhaseLatency aggregates the time spent in one request processing phase
+type PhaseLatency struct {
+	Count          int64         `json:"count"`
+	TotalLatency   time.Duration `json:"total_latency"`
+	AverageLatency time.Duration `json:"average_latency"`
+}
+
+// observe records a single phase duration
+func (p *PhaseLatency) observe(duration time.Duration) {
+	p.Count++
+	p.TotalLatency += duration
+	p.AverageLatency = time.Duration(int64(p.TotalLatency) / p.Count)
+}
+
+// PluginLatency reports the per-phase overhead of a single plugin
+type PluginLatency struct {
+	Name        string       `json:"name"`
+	Priority    Priority     `json:"priority"`
+	PreProcess  PhaseLatency `json:"pre_process"`
+	PostProcess PhaseLatency `json:"post_process"`
+}
+
+// LatencyBreakdown reports where request time goes across the plugin chain
+type LatencyBreakdown struct {
+	Plugins []PluginLatency `json:"plugins"`
+	Handler PhaseLatency    `json:"handler"`
 }
 
 // PluginFactory is a function that creates a new plugin instance
@@ -111,6 +152,27 @@ func (r *PluginRegistry) ListFactories() []string {
 	return names
 }
 
+// UnknownPlugins returns the names in pluginConfigs that neither a built-in
+// plugin nor a registered factory provides, in configuration order
+func (r *PluginRegistry) UnknownPlugins(pluginConfigs []config.PluginConfig) []string {
+	builtins := GetBuiltinPluginFactories()
+	
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	var unknown []string
+	for _, pluginConfig := range pluginConfigs {
+		if _, ok := builtins[pluginConfig.Name]; ok {
+			continue
+		}
+		if _, ok := r.factories[pluginConfig.Name]; ok {
+			continue
+		}
+		unknown = append(unknown, pluginConfig.Name)
+	}
+	return unknown
+}
+
 // pluginEntry represents an active plugin instance with its metadata
 type pluginEntry struct {
 	plugin      Plugin
@@ -122,7 +184,53 @@ type pluginEntry struct {
 	health      *HealthStatus
 	healthTimer *time.Timer
 	dependencies []string
+	order       int // Tiebreaker among middlewares sharing a priority; 0 means unset
 	mu          sync.RWMutex
+	
+	// Requests still running this instance's hooks. A retired entry, one
+	// unloaded or replaced by a reload, is cleaned up when the last finishes.
+	refs      int
+	retired   bool
+	onRetired func()
+	refMu     sync.Mutex
+}
+
+// acquire marks a request as running the entry's plugin
+func (e *pluginEntry) acquire() {
+	e.refMu.Lock()
+	e.refs++
+	e.refMu.Unlock()
+}
+
+// release ends a request's use of the entry's plugin, running the retire
+// cleanup when it was the last one
+func (e *pluginEntry) release() {
+	e.refMu.Lock()
+	e.refs--
+	var cleanup func()
+	if e.refs == 0 && e.retired {
+		cleanup, e.onRetired = e.onRetired, nil
+	}
+	e.refMu.Unlock()
+	
+	if cleanup != nil {
+		cleanup()
+	}
+}
+
+// retire runs cleanup once no request uses the entry's plugin anymore,
+// immediately when none does
+func (e *pluginEntry) retire(cleanup func()) {
+	e.refMu.Lock()
+	e.retired = true
+	if e.refs > 0 {
+		e.onRetired = cleanup
+		e.refMu.Unlock()
+		return
+	}
+	e.refMu.Unlock()
+	
+	cleanup()
 }
 
 // Manager manages the lifecycle of plugins and provides thread-safe operations
@@ -138,6 +246,74 @@ type Manager struct {
 	}
 	mu               sync.RWMutex
 	metricsCollector MetricsCollector
+
+	// handlerLatency tracks time spent in the wrapped handler
+	handlerLatency PhaseLatency
+	handlerMu      sync.Mutex
+
+	// strictPriorities turns same-priority middleware warnings into enable errors
+	strictPriorities bool
+
+	// executionBudget caps pre-processing time before low-priority plugins are skipped
+	executionBudget time.Duration
+
+	// atomicLoad makes LoadFromConfig unload everything it loaded when any entry fails
+	atomicLoad bool
+
+	// tracer records a span around each plugin phase; nil disables tracing
+	tracer *tracing.Tracer
+
+	// asyncPool runs PostProcess for AsyncPostProcessor middlewares
+	asyncPool asyncPostProcessPool
+
+	// allowedPlugins, when set, is the only plugins LoadPlugin accepts;
+	// deniedPlugins are always rejected
+	allowedPlugins map[string]bool
+	deniedPlugins  map[string]bool
+
+	// trustedProxies decides when RequestContext.ClientIP believes
+	// forwarding headers
+	trustedProxies TrustedProxies
+
+	// middlewareCache holds the sorted enabled middlewares so requests don't
+	// rebuild them. It is valid while its generation matches middlewareGen,
+	// which every change to the middleware set bumps.
+	middlewareCache atomic.Pointer[middlewareSet]
+	middlewareGen   atomic.Uint64
+}
+
+// middlewareSet is the enabled middlewares, sorted, as of a generation
+type middlewareSet struct {
+	generation  uint64
+	middlewares []Middleware
+	entries     []*pluginEntry
+}
+
+// Default size of the background post-processing pool
+const (
+	DefaultAsyncPostProcessWorkers   = 4
+	DefaultAsyncPostProcessQueueSize = 1024
+)
+
+// asyncPostProcessPool is a fixed set of workers fed by a bounded queue,
+// started on the first async post-process
+type asyncPostProcessPool struct {
+	workers   int
+	queueSize int
+	jobs      chan asyncPostProcessJob
+	start     sync.Once
+	wg        sync.WaitGroup
+	closed    bool
+	mu        sync.RWMutex
+}
+
+// asyncPostProcessJob holds the middlewares to post-process, in order, and
+// the snapshot they run against
+type asyncPostProcessJob struct {
+	middlewares []Middleware
+	responseCtx *ResponseContext
+	tracer      *tracing.Tracer
+	release     func() // Ends the request's hold on the middlewares
 }
 
 // MetricsCollector interface for collecting plugin operation metrics
@@ -207,6 +383,9 @@ func NewManager(logger *zap.Logger) *Manager {
 		metricsCollector: NewDefaultMetricsCollector(),
 	}
 	
+	manager.asyncPool.workers = DefaultAsyncPostProcessWorkers
+	manager.asyncPool.queueSize = DefaultAsyncPostProcessQueueSize
+	
 	// Configure health checking
 	manager.healthCheck.interval = 30 * time.Second
 	manager.healthCheck.enabled = true
@@ -254,6 +433,13 @@ func (m *Manager) LoadPlugin(name string, config map[string]interface{}) error {
 	
 	// Check if plugin is already loaded
 	m.mu.RLock()
+	if reason := m.notPermitted(name); reason != "" {
+		m.mu.RUnlock()
+		if m.metricsCollector != nil {
+			m.metricsCollector.IncPluginOperation(name, "load", false)
+		}
+		return NewPluginError(name, "load", reason, ErrPluginNotPermitted)
+	}
 	if _, exists := m.plugins[name]; exists {
 		m.mu.RUnlock()
 		if m.metricsCollector != nil {
@@ -263,34 +449,60 @@ func (m *Manager) LoadPlugin(name string, config map[string]interface{}) error {
 	}
 	m.mu.RUnlock()
 	
-	// Get plugin factory
-	factory, exists := m.registry.GetFactory(name)
-	if !exists {
+	entry, err := m.initPlugin(name, "load", config)
+	if err != nil {
 		if m.metricsCollector != nil {
 			m.metricsCollector.IncPluginOperation(name, "load", false)
 		}
-		return NewPluginError(name, "load", "plugin factory not found", ErrPluginNotFound)
+		return err
+	}
+	
+	// Add to plugins map
+	m.mu.Lock()
+	m.plugins[name] = entry
+	m.mu.Unlock()
+	m.invalidateMiddlewares()
+	
+	// Update metrics
+	if m.metricsCollector != nil {
+		m.metricsCollector.IncPluginOperation(name, "load", true)
+		m.metricsCollector.SetPluginState(name, string(StateLoaded))
+	}
+	
+	m.logger.Info("Plugin loaded successfully",
+		zap.String("plugin", name),
+		zap.String("version", entry.plugin.Version()),
+		zap.Duration("load_time", time.Since(start)))
+	
+	return nil
+}
+
+// initPlugin creates and initializes a plugin instance with config, filled
+// in with its schema defaults, and wraps it in a new loaded entry. Errors are
+// reported against operation.
+func (m *Manager) initPlugin(name, operation string, config map[string]interface{}) (*pluginEntry, error) {
+	// Get plugin factory
+	factory, exists := m.registry.GetFactory(name)
+	if !exists {
+		return nil, NewPluginError(name, operation, "plugin factory not found", ErrPluginNotFound)
 	}
 	
 	// Create plugin instance
 	plugin := factory()
 	if plugin == nil {
-		if m.metricsCollector != nil {
-			m.metricsCollector.IncPluginOperation(name, "load", false)
-		}
-		return NewPluginError(name, "load", "factory returned nil plugin", ErrPluginInitFailed)
+		return nil, NewPluginError(name, operation, "factory returned nil plugin", ErrPluginInitFailed)
 	}
 	
 	// Validate plugin name matches
 	if plugin.Name() != name {
-		if m.metricsCollector != nil {
-			m.metricsCollector.IncPluginOperation(name, "load", false)
-		}
-		return NewPluginError(name, "load", 
+		return nil, NewPluginError(name, operation, 
 			fmt.Sprintf("plugin name mismatch: expected %s, got %s", name, plugin.Name()), 
 			ErrPluginConfigInvalid)
 	}
 	
+	// Omitted settings take their schema defaults
+	config = globalConfigRegistry.ApplyDefaults(name, config)
+	
 	// Create plugin context
 	pluginCtx, cancel := context.WithTimeout(m.shutdownCtx, 30*time.Second)
 	defer cancel()
@@ -299,28 +511,20 @@ func (m *Manager) LoadPlugin(name string, config map[string]interface{}) error {
 	pluginLogger := m.logger.With(zap.String("plugin", name), zap.String("version", plugin.Version()))
 	if err := plugin.Init(pluginCtx, config, pluginLogger); err != nil {
 		if m.metricsCollector != nil {
-			m.metricsCollector.IncPluginOperation(name, "load", false)
 			m.metricsCollector.IncPluginError(name, "init_failed")
 		}
-		return NewPluginError(name, "load", "plugin initialization failed", err)
+		return nil, NewPluginError(name, operation, "plugin initialization failed", err)
 	}
 	
 	// Resolve dependencies if plugin provides them
-	var dependencies []string
-	if metadataProvider, ok := plugin.(interface{ GetDependencies() []string }); ok {
-		dependencies = metadataProvider.GetDependencies()
-		if err := m.validateDependencies(dependencies); err != nil {
-			// Cleanup plugin
-			plugin.Cleanup(pluginCtx)
-			if m.metricsCollector != nil {
-				m.metricsCollector.IncPluginOperation(name, "load", false)
-			}
-			return NewPluginError(name, "load", "dependency validation failed", err)
-		}
+	dependencies := pluginDependencies(plugin)
+	if err := m.validateDependencies(name, dependencies); err != nil {
+		// Cleanup plugin
+		plugin.Cleanup(pluginCtx)
+		return nil, NewPluginError(name, operation, "dependency validation failed", err)
 	}
 	
-	// Create plugin entry
-	entry := &pluginEntry{
+	return &pluginEntry{
 		plugin:       plugin,
 		state:        StateLoaded,
 		config:       config,
@@ -332,25 +536,7 @@ func (m *Manager) LoadPlugin(name string, config map[string]interface{}) error {
 			AverageLatency:    0,
 			LastUsed:          time.Now(),
 		},
-	}
-	
-	// Add to plugins map
-	m.mu.Lock()
-	m.plugins[name] = entry
-	m.mu.Unlock()
-	
-	// Update metrics
-	if m.metricsCollector != nil {
-		m.metricsCollector.IncPluginOperation(name, "load", true)
-		m.metricsCollector.SetPluginState(name, string(StateLoaded))
-	}
-	
-	m.logger.Info("Plugin loaded successfully",
-		zap.String("plugin", name),
-		zap.String("version", plugin.Version()),
-		zap.Duration("load_time", time.Since(start)))
-	
-	return nil
+	}, nil
 }
 
 // UnloadPlugin gracefully shuts down and removes a plugin
@@ -375,27 +561,10 @@ func (m *Manager) UnloadPlugin(name string) error {
 	// Remove from map first to prevent new requests
 	delete(m.plugins, name)
 	m.mu.Unlock()
+	m.invalidateMiddlewares()
 	
-	// Cancel health check timer if exists
-	entry.mu.Lock()
-	if entry.healthTimer != nil {
-		entry.healthTimer.Stop()
-	}
-	entry.mu.Unlock()
-	
-	// Cleanup plugin
-	cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	
-	if err := entry.plugin.Cleanup(cleanupCtx); err != nil {
-		m.logger.Warn("Plugin cleanup failed",
-			zap.String("plugin", name),
-			zap.Error(err))
-		if m.metricsCollector != nil {
-			m.metricsCollector.IncPluginError(name, "cleanup_failed")
-		}
-		// Don't return error as plugin is already removed from active set
-	}
+	// Cleanup plugin once in-flight requests are done with it
+	m.retireEntry(name, entry)
 	
 	// Update metrics
 	if m.metricsCollector != nil {
@@ -410,6 +579,32 @@ func (m *Manager) UnloadPlugin(name string) error {
 	return nil
 }
 
+// retireEntry stops an entry removed from the active set and cleans up its
+// plugin once the requests still using it finish. Cleanup failures are only
+// logged as the plugin is already gone.
+func (m *Manager) retireEntry(name string, entry *pluginEntry) {
+	// Cancel health check timer if exists
+	entry.mu.Lock()
+	if entry.healthTimer != nil {
+		entry.healthTimer.Stop()
+	}
+	entry.mu.Unlock()
+	
+	entry.retire(func() {
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		
+		if err := entry.plugin.Cleanup(cleanupCtx); err != nil {
+			m.logger.Warn("Plugin cleanup failed",
+				zap.String("plugin", name),
+				zap.Error(err))
+			if m.metricsCollector != nil {
+				m.metricsCollector.IncPluginError(name, "cleanup_failed")
+			}
+		}
+	})
+}
+
 // GetPlugin retrieves a plugin by name
 func (m *Manager) GetPlugin(name string) (Plugin, bool) {
 	m.mu.RLock()
@@ -428,6 +623,22 @@ func (m *Manager) GetPlugin(name string) (Plugin, bool) {
 	return entry.plugin, true
 }
 
+// GetPluginEntry returns a loaded plugin and its state whatever that state
+// is, so management tooling can inspect or configure plugins that are
+// loaded but disabled. Request handling should use GetPlugin.
+func (m *Manager) GetPluginEntry(name string) (Plugin, PluginState, bool) {
+	m.mu.RLock()
+	entry, exists := m.plugins[name]
+	m.mu.RUnlock()
+	if !exists {
+		return nil, StateUnloaded, false
+	}
+
+	entry.mu.RLock()
+	defer entry.mu.RUnlock()
+	return entry.plugin, entry.state, true
+}
+
 // ListPlugins returns information about all plugins
 func (m *Manager) ListPlugins() []PluginInfo {
 	m.mu.RLock()
@@ -480,6 +691,26 @@ func (m *Manager) EnablePlugin(name string) error {
 		return NewPluginError(name, "enable", "plugin not found", ErrPluginNotFound)
 	}
 	
+	// Check for ambiguous ordering against other enabled middlewares.
+	// Done before locking the entry since it reads the other entries.
+	if conflicts := m.priorityConflicts(name, entry); len(conflicts) > 0 {
+		m.mu.RLock()
+		strict := m.strictPriorities
+		m.mu.RUnlock()
+		
+		if strict {
+			if m.metricsCollector != nil {
+				m.metricsCollector.IncPluginOperation(name, "enable", false)
+			}
+			return NewPluginError(name, "enable",
+				fmt.Sprintf("shares priority with %s without a distinct order", strings.Join(conflicts, ", ")),
+				ErrPriorityConflict)
+		}
+		m.logger.Warn("Middleware plugins share a priority without a distinct order; execution order is undefined",
+			zap.String("plugin", name),
+			zap.Strings("conflicts_with", conflicts))
+	}
+	
 	entry.mu.Lock()
 	defer entry.mu.Unlock()
 	
@@ -509,6 +740,7 @@ func (m *Manager) EnablePlugin(name string) error {
 	
 	entry.state = StateEnabled
 	entry.lastError = ""
+	m.invalidateMiddlewares()
 	
 	// Update metrics
 	if m.metricsCollector != nil {
@@ -554,6 +786,7 @@ func (m *Manager) DisablePlugin(name string) error {
 	}
 	
 	entry.state = StateDisabled
+	m.invalidateMiddlewares()
 	
 	// Update metrics
 	if m.metricsCollector != nil {
@@ -587,6 +820,7 @@ func (m *Manager) ReloadPlugin(name string, config map[string]interface{}) error
 		}
 		return NewPluginError(name, "reload", "plugin not found", ErrPluginNotFound)
 	}
+	config = globalConfigRegistry.ApplyDefaults(name, config)
 	
 	// Check if plugin supports hot reloading
 	if hotReloadable, ok := entry.plugin.(HotReloadable); ok {
@@ -617,6 +851,8 @@ func (m *Manager) ReloadPlugin(name string, config map[string]interface{}) error
 		entry.config = config
 		entry.lastError = ""
 		entry.mu.Unlock()
+		// A reload may change the plugin's priority
+		m.invalidateMiddlewares()
 		
 		if m.metricsCollector != nil {
 			m.metricsCollector.IncPluginOperation(name, "reload", true)
@@ -629,47 +865,56 @@ func (m *Manager) ReloadPlugin(name string, config map[string]interface{}) error
 		return nil
 	}
 	
-	// Fallback to unload/load cycle
-	originalState := entry.state
-	
-	// Disable first
-	if err := m.DisablePlugin(name); err != nil {
+	// Fallback: initialize a replacement instance and swap it in. Requests
+	// already running keep the old instance until they finish.
+	replacement, err := m.initPlugin(name, "reload", config)
+	if err != nil {
 		if m.metricsCollector != nil {
 			m.metricsCollector.IncPluginOperation(name, "reload", false)
 		}
-		return NewPluginError(name, "reload", "failed to disable for reload", err)
+		return NewPluginError(name, "reload", "failed to initialize replacement", err)
 	}
 	
-	// Unload
-	if err := m.UnloadPlugin(name); err != nil {
-		if m.metricsCollector != nil {
-			m.metricsCollector.IncPluginOperation(name, "reload", false)
+	entry.mu.RLock()
+	replacement.state = entry.state
+	replacement.order = entry.order
+	replacement.metrics = entry.metrics
+	entry.mu.RUnlock()
+	
+	// An enabled plugin stays enabled only while its dependencies are
+	if replacement.state == StateEnabled {
+		if err := m.validateEnabledDependencies(replacement.dependencies); err != nil {
+			m.logger.Warn("Failed to re-enable plugin after reload",
+				zap.String("plugin", name),
+				zap.Error(err))
+			replacement.state = StateLoaded
 		}
-		return NewPluginError(name, "reload", "failed to unload for reload", err)
 	}
 	
-	// Reload
-	if err := m.LoadPlugin(name, config); err != nil {
+	m.mu.Lock()
+	if m.plugins[name] != entry {
+		// Unloaded or reloaded concurrently; the replacement is not needed
+		m.mu.Unlock()
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		replacement.plugin.Cleanup(cleanupCtx)
+		cancel()
 		if m.metricsCollector != nil {
 			m.metricsCollector.IncPluginOperation(name, "reload", false)
 		}
-		return NewPluginError(name, "reload", "failed to load after reload", err)
+		return NewPluginError(name, "reload", "plugin changed during reload", ErrPluginNotFound)
 	}
+	m.plugins[name] = replacement
+	m.mu.Unlock()
+	m.invalidateMiddlewares()
 	
-	// Restore original state if it was enabled
-	if originalState == StateEnabled {
-		if err := m.EnablePlugin(name); err != nil {
-			m.logger.Warn("Failed to re-enable plugin after reload",
-				zap.String("plugin", name),
-				zap.Error(err))
-		}
-	}
+	m.retireEntry(name, entry)
 	
 	if m.metricsCollector != nil {
 		m.metricsCollector.IncPluginOperation(name, "reload", true)
+		m.metricsCollector.SetPluginState(name, string(replacement.state))
 	}
 	
-	m.logger.Info("Plugin reloaded via unload/load cycle",
+	m.logger.Info("Plugin reloaded by replacing its instance",
 		zap.String("plugin", name),
 		zap.Duration("reload_time", time.Since(start)))
 	
@@ -678,111 +923,550 @@ func (m *Manager) ReloadPlugin(name string, config map[string]interface{}) error
 
 // GetMiddlewares returns all enabled middleware plugins sorted by priority
 func (m *Manager) GetMiddlewares() []Middleware {
+	set := m.middlewares()
+	return append([]Middleware(nil), set.middlewares...)
+}
+
+// acquireMiddlewares returns the enabled middlewares sorted by priority and
+// holds a reference on each so a concurrent reload or unload leaves them
+// usable. The returned release must be called once the request is done.
+func (m *Manager) acquireMiddlewares() ([]Middleware, func()) {
+	for {
+		set := m.middlewares()
+		for _, entry := range set.entries {
+			entry.acquire()
+		}
+		release := func() {
+			for _, entry := range set.entries {
+				entry.release()
+			}
+		}
+		
+		// Entries are retired only after the set is invalidated, so a set
+		// still current once acquired cannot contain a cleaned up plugin
+		if m.middlewareGen.Load() == set.generation {
+			return set.middlewares, release
+		}
+		release()
+	}
+}
+
+// invalidateMiddlewares drops the cached middleware set. It must be called
+// after any change to which middlewares are enabled or how they sort, and
+// before an entry removed from the set is retired.
+func (m *Manager) invalidateMiddlewares() {
+	m.middlewareGen.Add(1)
+	m.middlewareCache.Store(nil)
+}
+
+// middlewares returns the current middleware set, rebuilding it when a
+// change has invalidated the cached one
+func (m *Manager) middlewares() *middlewareSet {
+	generation := m.middlewareGen.Load()
+	if set := m.middlewareCache.Load(); set != nil && set.generation == generation {
+		return set
+	}
+	
+	// A change racing the rebuild bumps the generation, so a stale set is
+	// never served from the cache
+	middlewares, entries := m.middlewareEntries()
+	set := &middlewareSet{generation: generation, middlewares: middlewares, entries: entries}
+	m.middlewareCache.Store(set)
+	return set
+}
+
+// middlewareEntries collects the enabled middlewares sorted by priority along
+// with their entries
+func (m *Manager) middlewareEntries() ([]Middleware, []*pluginEntry) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
-	var middlewares []Middleware
+	var entries []*pluginEntry
 	for _, entry := range m.plugins {
 		if entry.state == StateEnabled {
-			if middleware, ok := entry.plugin.(Middleware); ok {
-				middlewares = append(middlewares, middleware)
+			if _, ok := entry.plugin.(Middleware); ok {
+				entries = append(entries, entry)
 			}
 		}
 	}
 	
-	// Sort by priority (lower values = higher priority)
-	sort.Slice(middlewares, func(i, j int) bool {
-		return middlewares[i].Priority() < middlewares[j].Priority()
+	// Sort by priority (lower values = higher priority), then by explicit order
+	sort.SliceStable(entries, func(i, j int) bool {
+		left := entries[i].plugin.(Middleware)
+		right := entries[j].plugin.(Middleware)
+		if left.Priority() != right.Priority() {
+			return left.Priority() < right.Priority()
+		}
+		return m.pluginOrder(left.Name()) < m.pluginOrder(right.Name())
 	})
 	
-	return middlewares
+	middlewares := make([]Middleware, 0, len(entries))
+	for _, entry := range entries {
+		middlewares = append(middlewares, entry.plugin.(Middleware))
+	}
+	
+	return middlewares, entries
+}
+
+// SetStrictPriorities makes EnablePlugin fail, instead of warn, when a middleware
+// shares its priority with another enabled middleware without a distinct order
+func (m *Manager) SetStrictPriorities(strict bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.strictPriorities = strict
+}
+
+// SetExecutionBudget caps the time a request may spend in middleware
+// pre-processing. Once the budget is exceeded, remaining low-priority
+// middlewares are skipped. A zero budget disables the cap.
+func (m *Manager) SetExecutionBudget(budget time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.executionBudget = budget
+}
+
+// SetAtomicLoad makes LoadFromConfig all-or-nothing: when any entry fails,
+// the plugins loaded by that call are unloaded again. Otherwise loading is
+// best effort and the entries that succeeded stay loaded.
+func (m *Manager) SetAtomicLoad(atomic bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.atomicLoad = atomic
+}
+
+// SetAllowedPlugins restricts LoadPlugin to the named plugins. An empty list
+// lifts the restriction. Plugins that are already loaded are not affected.
+func (m *Manager) SetAllowedPlugins(names []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.allowedPlugins = pluginNameSet(names)
+}
+
+// SetTrustedProxies sets the proxies whose forwarding headers
+// RequestContext.ClientIP honors. With none, the socket address is used.
+func (m *Manager) SetTrustedProxies(proxies TrustedProxies) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.trustedProxies = proxies
+}
+
+// SetDeniedPlugins makes LoadPlugin reject the named plugins, even when they
+// are also allowed. Plugins that are already loaded are not affected.
+func (m *Manager) SetDeniedPlugins(names []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.deniedPlugins = pluginNameSet(names)
+}
+
+// pluginNameSet turns a list of plugin names into a set, nil when empty
+func pluginNameSet(names []string) map[string]bool {
+	if len(names) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// notPermitted returns why the plugin may not be loaded, or "" when it may.
+// The denylist wins over the allowlist. Callers must hold m.mu.
+func (m *Manager) notPermitted(name string) string {
+	if m.deniedPlugins[name] {
+		return "plugin is denied"
+	}
+	if m.allowedPlugins != nil && !m.allowedPlugins[name] {
+		return "plugin is not in the allowed list"
+	}
+	return ""
+}
+
+// SetAsyncPostProcess sizes the worker pool that runs PostProcess for
+// AsyncPostProcessor middlewares. It must be called before the first request;
+// non-positive values keep the defaults.
+func (m *Manager) SetAsyncPostProcess(workers, queueSize int) {
+	m.asyncPool.mu.Lock()
+	defer m.asyncPool.mu.Unlock()
+	
+	if workers > 0 {
+		m.asyncPool.workers = workers
+	}
+	if queueSize > 0 {
+		m.asyncPool.queueSize = queueSize
+	}
+}
+
+// SetTracer records a child span of the request span around every plugin
+// PreProcess and PostProcess call. A nil tracer disables plugin spans.
+func (m *Manager) SetTracer(tracer *tracing.Tracer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.tracer = tracer
+}
+
+// SetPluginOrder sets the tiebreaker used to order a loaded plugin among
+// middlewares with the same priority. Lower values run first; 0 means unset.
+func (m *Manager) SetPluginOrder(name string, order int) error {
+	m.mu.RLock()
+	entry, exists := m.plugins[name]
+	m.mu.RUnlock()
+	
+	if !exists {
+		return NewPluginError(name, "order", "plugin not found", ErrPluginNotFound)
+	}
+	
+	entry.mu.Lock()
+	entry.order = order
+	entry.mu.Unlock()
+	m.invalidateMiddlewares()
+	
+	return nil
+}
+
+// pluginOrder returns a plugin's explicit order. Callers must hold m.mu.
+func (m *Manager) pluginOrder(name string) int {
+	entry, exists := m.plugins[name]
+	if !exists {
+		return 0
+	}
+	
+	entry.mu.RLock()
+	defer entry.mu.RUnlock()
+	return entry.order
+}
+
+// priorityConflicts returns the enabled middlewares that share the plugin's
+// priority where the two have no distinct explicit order
+func (m *Manager) priorityConflicts(name string, entry *pluginEntry) []string {
+	middleware, ok := entry.plugin.(Middleware)
+	if !ok {
+		return nil
+	}
+	
+	entry.mu.RLock()
+	order := entry.order
+	alreadyEnabled := entry.state == StateEnabled
+	entry.mu.RUnlock()
+	if alreadyEnabled {
+		return nil
+	}
+	
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	var conflicts []string
+	for otherName, other := range m.plugins {
+		if otherName == name {
+			continue
+		}
+		
+		other.mu.RLock()
+		enabled := other.state == StateEnabled
+		otherOrder := other.order
+		other.mu.RUnlock()
+		
+		otherMiddleware, ok := other.plugin.(Middleware)
+		if !enabled || !ok || otherMiddleware.Priority() != middleware.Priority() {
+			continue
+		}
+		
+		if order == 0 || otherOrder == 0 || order == otherOrder {
+			conflicts = append(conflicts, otherName)
+		}
+	}
+	
+	sort.Strings(conflicts)
+	return conflicts
 }
 
 // CreateMiddlewareFunc creates a FastHTTP middleware function from plugin middlewares
 func (m *Manager) CreateMiddlewareFunc() func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 		return func(ctx *fasthttp.RequestCtx) {
-			middlewares := m.GetMiddlewares()
+			middlewares, release := m.acquireMiddlewares()
 			
 			// Create request context
+			fingerprint, _ := ctx.UserValue("request_fingerprint").(string)
+			traceCtx, ok := ctx.UserValue("trace_context").(context.Context)
+			if !ok {
+				traceCtx = context.Background()
+			}
+			m.mu.RLock()
+			trustedProxies := m.trustedProxies
+			m.mu.RUnlock()
 			requestCtx := &RequestContext{
-				RequestCtx: ctx,
-				RequestID:  m.getRequestID(ctx),
-				StartTime:  time.Now(),
-				UserValues: make(map[string]interface{}),
-				PluginData: make(map[string]interface{}),
-				Logger:     m.logger.With(zap.String("request_id", m.getRequestID(ctx))),
-				Context:    context.Background(),
+				RequestCtx:     ctx,
+				RequestID:      m.getRequestID(ctx),
+				Fingerprint:    fingerprint,
+				StartTime:      time.Now(),
+				UserValues:     make(map[string]interface{}),
+				PluginData:     make(map[string]interface{}),
+				Logger:         m.logger.With(zap.String("request_id", m.getRequestID(ctx)), zap.String("fingerprint", fingerprint)),
+				Context:        traceCtx,
+				trustedProxies: trustedProxies,
 			}
 			
 			// Process middleware chain
-			m.processMiddlewareChain(middlewares, requestCtx, next)
+			m.processMiddlewareChain(middlewares, requestCtx, next, release)
 		}
 	}
 }
 
-// processMiddlewareChain processes the middleware chain with proper error handling
-func (m *Manager) processMiddlewareChain(middlewares []Middleware, requestCtx *RequestContext, handler fasthttp.RequestHandler) {
+// processMiddlewareChain processes the middleware chain with proper error handling.
+// Once a middleware short-circuits or fails, later middlewares and the handler
+// are skipped, but every middleware whose pre-process completed still
+// post-processes, in reverse order. release is called once the middlewares
+// are done, after any async post-processing.
+func (m *Manager) processMiddlewareChain(middlewares []Middleware, requestCtx *RequestContext, handler fasthttp.RequestHandler, release func()) {
+	m.mu.RLock()
+	budget := m.executionBudget
+	tracer := m.tracer
+	m.mu.RUnlock()
+	
+	// Async post-processing takes over the release
+	releaseOnReturn := true
+	defer func() {
+		if releaseOnReturn {
+			release()
+		}
+	}()
+
+	// Middlewares whose pre-process completed, in execution order
+	ran := make([]Middleware, 0, len(middlewares))
+	var shortCircuitedBy string
+	var processingErr error
+
 	// Pre-process phase
 	for _, middleware := range middlewares {
 		if !middleware.ShouldApply(requestCtx.RequestCtx) {
 			continue
 		}
 		
+		// Middlewares skipped for exceeding the budget also skip post-processing
+		if budget > 0 && middleware.Priority() >= PriorityLow {
+			if elapsed := time.Since(requestCtx.StartTime); elapsed > budget {
+				m.logger.Warn("Skipping low-priority middleware: execution budget exceeded",
+					zap.String("plugin", middleware.Name()),
+					zap.Duration("elapsed", elapsed),
+					zap.Duration("budget", budget))
+				continue
+			}
+		}
+		
 		start := time.Now()
+		span := startPluginSpan(tracer, requestCtx, middleware.Name(), PhasePreProcess)
 		shouldContinue, err := m.safePreProcess(middleware, requestCtx)
+		span.RecordError(err)
+		span.End()
 		
 		// Update plugin metrics
 		pluginName := middleware.Name()
-		m.updatePluginMetrics(pluginName, time.Since(start), err)
+		m.updatePluginMetrics(pluginName, PhasePreProcess, time.Since(start), err)
 		
 		if err != nil {
 			m.logger.Error("Middleware pre-processing failed",
 				zap.String("plugin", pluginName),
+				zap.String("request_id", requestCtx.RequestID),
 				zap.Error(err))
-			requestCtx.RequestCtx.SetStatusCode(fasthttp.StatusInternalServerError)
-			return
+			writePluginError(requestCtx, pluginName)
+			processingErr = err
+			shortCircuitedBy = pluginName
+			break
 		}
 		
+		ran = append(ran, middleware)
+		
 		if !shouldContinue {
-			return // Middleware short-circuited the request
+			shortCircuitedBy = pluginName // Keep the response it wrote
+			break
 		}
 	}
 	
-	// Execute main handler
-	handler(requestCtx.RequestCtx)
+	if shortCircuitedBy == "" {
+		// Hand the handler any JSON body a plugin replaced
+		if err := requestCtx.syncJSONBody(); err != nil {
+			m.logger.Warn("Failed to re-serialize modified JSON body", zap.Error(err))
+		}
+		
+		// Execute main handler
+		handlerStart := time.Now()
+		handler(requestCtx.RequestCtx)
+		m.observeHandlerLatency(time.Since(handlerStart))
+	}
 	
 	// Create response context
 	responseCtx := &ResponseContext{
-		RequestContext:  requestCtx,
-		ProcessingTime:  time.Since(requestCtx.StartTime),
-		ResponseBody:    requestCtx.RequestCtx.Response.Body(),
-		ProcessingError: nil,
+		RequestContext:   requestCtx,
+		ProcessingTime:   time.Since(requestCtx.StartTime),
+		ProcessingError:  processingErr,
+		ShortCircuitedBy: shortCircuitedBy,
+	}
+	if !requestCtx.BodyCaptureDisabled() {
+		responseCtx.ResponseBody = requestCtx.responseBody()
 	}
 	
-	// Post-process phase (reverse order)
-	for i := len(middlewares) - 1; i >= 0; i-- {
-		middleware := middlewares[i]
-		if !middleware.ShouldApply(requestCtx.RequestCtx) {
+	// Post-process phase (reverse order). Async middlewares are collected and
+	// run in the background once the inline ones are done.
+	var async []Middleware
+	for i := len(ran) - 1; i >= 0; i-- {
+		middleware := ran[i]
+		if isAsyncPostProcessor(middleware) {
+			async = append(async, middleware)
 			continue
 		}
+		m.postProcess(middleware, responseCtx, tracer)
+	}
+	
+	if len(async) > 0 {
+		releaseOnReturn = false
+		m.dispatchAsyncPostProcess(asyncPostProcessJob{
+			middlewares: async,
+			responseCtx: responseCtx.snapshot(),
+			tracer:      tracer,
+			release:     release,
+		})
+	}
+}
+
+// pluginErrorResponse is the body sent when a plugin's pre-process fails.
+// The error itself is only logged.
+type pluginErrorResponse struct {
+	Error     string `json:"error"`
+	Plugin    string `json:"plugin"`
+	RequestID string `json:"request_id"`
+}
+
+// writePluginError answers a failed pre-process with a JSON 500 naming the plugin
+func writePluginError(requestCtx *RequestContext, pluginName string) {
+	body, _ := json.Marshal(pluginErrorResponse{
+		Error:     "plugin_error",
+		Plugin:    pluginName,
+		RequestID: requestCtx.RequestID,
+	})
+
+	ctx := requestCtx.RequestCtx
+	ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+	ctx.SetContentType("application/json")
+	ctx.SetBody(body)
+}
+
+// postProcess runs one middleware's PostProcess and records its metrics
+func (m *Manager) postProcess(middleware Middleware, responseCtx *ResponseContext, tracer *tracing.Tracer) {
+	start := time.Now()
+	span := startPluginSpan(tracer, responseCtx.RequestContext, middleware.Name(), PhasePostProcess)
+	err := m.safePostProcess(middleware, responseCtx)
+	span.RecordError(err)
+	span.End()
+	
+	// Update plugin metrics
+	pluginName := middleware.Name()
+	m.updatePluginMetrics(pluginName, PhasePostProcess, time.Since(start), err)
+	
+	if err != nil {
+		m.logger.Error("Middleware post-processing failed",
+			zap.String("plugin", pluginName),
+			zap.Error(err))
+		// Don't modify response on post-process errors
+	}
+}
+
+// isAsyncPostProcessor reports whether middleware asked for background post-processing
+func isAsyncPostProcessor(middleware Middleware) bool {
+	async, ok := middleware.(AsyncPostProcessor)
+	return ok && async.AsyncPostProcess()
+}
+
+// dispatchAsyncPostProcess queues job for the worker pool. When the queue is
+// full or the manager is shutting down, the job runs inline instead, so a
+// backlog slows responses down rather than dropping post-processing.
+func (m *Manager) dispatchAsyncPostProcess(job asyncPostProcessJob) {
+	pool := &m.asyncPool
+	pool.start.Do(func() {
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
 		
-		start := time.Now()
-		err := m.safePostProcess(middleware, responseCtx)
-		
-		// Update plugin metrics
-		pluginName := middleware.Name()
-		m.updatePluginMetrics(pluginName, time.Since(start), err)
-		
-		if err != nil {
-			m.logger.Error("Middleware post-processing failed",
-				zap.String("plugin", pluginName),
-				zap.Error(err))
-			// Don't modify response on post-process errors
+		if pool.closed {
+			return
+		}
+		pool.jobs = make(chan asyncPostProcessJob, pool.queueSize)
+		for i := 0; i < pool.workers; i++ {
+			pool.wg.Add(1)
+			go m.asyncPostProcessWorker(pool.jobs)
 		}
+	})
+	
+	pool.mu.RLock()
+	if !pool.closed {
+		select {
+		case pool.jobs <- job:
+			pool.mu.RUnlock()
+			return
+		default:
+		}
+	}
+	pool.mu.RUnlock()
+	
+	m.runAsyncPostProcess(job)
+}
+
+// asyncPostProcessWorker runs queued jobs until the queue is closed
+func (m *Manager) asyncPostProcessWorker(jobs <-chan asyncPostProcessJob) {
+	defer m.asyncPool.wg.Done()
+	for job := range jobs {
+		m.runAsyncPostProcess(job)
+	}
+}
+
+// runAsyncPostProcess post-processes every middleware of job in order
+func (m *Manager) runAsyncPostProcess(job asyncPostProcessJob) {
+	if job.release != nil {
+		defer job.release()
+	}
+	for _, middleware := range job.middlewares {
+		m.postProcess(middleware, job.responseCtx, job.tracer)
 	}
 }
 
+// drainAsyncPostProcess stops accepting async jobs and waits for the queued
+// ones to finish
+func (m *Manager) drainAsyncPostProcess() {
+	pool := &m.asyncPool
+	pool.mu.Lock()
+	if pool.closed || pool.jobs == nil {
+		pool.closed = true
+		pool.mu.Unlock()
+		return
+	}
+	pool.closed = true
+	close(pool.jobs)
+	pool.mu.Unlock()
+	
+	pool.wg.Wait()
+}
+
+// startPluginSpan starts a span for one plugin phase as a child of the
+// request span, or returns nil when tracing is disabled
+func startPluginSpan(tracer *tracing.Tracer, requestCtx *RequestContext, pluginName, phase string) *tracing.Span {
+	if tracer == nil {
+		return nil
+	}
+	_, span := tracer.Start(requestCtx.Context, "plugin."+pluginName+"."+phase, tracing.SpanKindInternal)
+	span.SetAttribute("plugin.name", pluginName)
+	span.SetAttribute("plugin.phase", phase)
+	if requestCtx.RequestID != "" {
+		span.SetAttribute("request_id", requestCtx.RequestID)
+	}
+	return span
+}
+
 // safePreProcess safely executes middleware pre-processing with panic recovery
 func (m *Manager) safePreProcess(middleware Middleware, ctx *RequestContext) (shouldContinue bool, err error) {
 	defer func() {
@@ -814,8 +1498,8 @@ func (m *Manager) safePostProcess(middleware Middleware, ctx *ResponseContext) (
 	return middleware.PostProcess(ctx)
 }
 
-// updatePluginMetrics updates metrics for a plugin operation
-func (m *Manager) updatePluginMetrics(pluginName string, duration time.Duration, err error) {
+// updatePluginMetrics updates metrics for a plugin operation in the given phase
+func (m *Manager) updatePluginMetrics(pluginName, phase string, duration time.Duration, err error) {
 	m.mu.RLock()
 	entry, exists := m.plugins[pluginName]
 	m.mu.RUnlock()
@@ -832,12 +1516,67 @@ func (m *Manager) updatePluginMetrics(pluginName string, duration time.Duration,
 	entry.metrics.AverageLatency = time.Duration(int64(entry.metrics.TotalLatency) / entry.metrics.RequestsProcessed)
 	entry.metrics.LastUsed = time.Now()
 	
+	switch phase {
+	case PhasePreProcess:
+		entry.metrics.PreProcess.observe(duration)
+	case PhasePostProcess:
+		entry.metrics.PostProcess.observe(duration)
+	}
+	
+	if m.metricsCollector != nil {
+		m.metricsCollector.ObservePluginLatency(pluginName, phase, duration)
+	}
+	
 	if err != nil {
 		atomic.AddInt64(&entry.metrics.ErrorCount, 1)
 		entry.lastError = err.Error()
 	}
 }
 
+// observeHandlerLatency records time spent in the handler wrapped by the plugin chain
+func (m *Manager) observeHandlerLatency(duration time.Duration) {
+	m.handlerMu.Lock()
+	defer m.handlerMu.Unlock()
+	
+	m.handlerLatency.observe(duration)
+}
+
+// GetLatencyBreakdown returns the average pre/post-process latency of each
+// enabled plugin, in execution order, alongside the wrapped handler time
+func (m *Manager) GetLatencyBreakdown() LatencyBreakdown {
+	middlewares := m.GetMiddlewares()
+	
+	breakdown := LatencyBreakdown{
+		Plugins: make([]PluginLatency, 0, len(middlewares)),
+	}
+	
+	for _, middleware := range middlewares {
+		latency := PluginLatency{
+			Name:     middleware.Name(),
+			Priority: middleware.Priority(),
+		}
+		
+		m.mu.RLock()
+		entry, exists := m.plugins[middleware.Name()]
+		m.mu.RUnlock()
+		
+		if exists {
+			entry.mu.RLock()
+			latency.PreProcess = entry.metrics.PreProcess
+			latency.PostProcess = entry.metrics.PostProcess
+			entry.mu.RUnlock()
+		}
+		
+		breakdown.Plugins = append(breakdown.Plugins, latency)
+	}
+	
+	m.handlerMu.Lock()
+	breakdown.Handler = m.handlerLatency
+	m.handlerMu.Unlock()
+	
+	return breakdown
+}
+
 // getRequestID extracts or generates a request ID
 func (m *Manager) getRequestID(ctx *fasthttp.RequestCtx) string {
 	if val := ctx.UserValue("request_id"); val != nil {
@@ -846,15 +1585,38 @@ func (m *Manager) getRequestID(ctx *fasthttp.RequestCtx) string {
 	return "unknown"
 }
 
-// LoadFromConfig loads plugins from configuration
+// LoadFromConfig loads plugins from configuration. Every entry is attempted
+// and the errors are combined; in atomic mode the plugins loaded by this call
+// are unloaded again when any entry fails.
 func (m *Manager) LoadFromConfig(pluginConfigs []config.PluginConfig) error {
-	var loadErrors []error
+	m.mu.RLock()
+	atomic := m.atomicLoad
+	m.mu.RUnlock()
+	
+	var (
+		loadErrors []error
+		loaded     []string
+	)
 	
 	for _, pluginConfig := range pluginConfigs {
-		if err := m.LoadPlugin(pluginConfig.Name, pluginConfig.Config); err != nil {
+		pluginSettings, err := globalConfigRegistry.substitutePluginEnvironmentVariables(pluginConfig.Name, pluginConfig.Config)
+		if err != nil {
+			loadErrors = append(loadErrors, NewPluginError(pluginConfig.Name, "load", "environment substitution failed", err))
+			continue
+		}
+		
+		if err := m.LoadPlugin(pluginConfig.Name, pluginSettings); err != nil {
 			loadErrors = append(loadErrors, err)
 			continue
 		}
+		loaded = append(loaded, pluginConfig.Name)
+		
+		if pluginConfig.Order != 0 {
+			if err := m.SetPluginOrder(pluginConfig.Name, pluginConfig.Order); err != nil {
+				loadErrors = append(loadErrors, err)
+				continue
+			}
+		}
 		
 		if pluginConfig.Enabled {
 			if err := m.EnablePlugin(pluginConfig.Name); err != nil {
@@ -863,16 +1625,167 @@ func (m *Manager) LoadFromConfig(pluginConfigs []config.PluginConfig) error {
 		}
 	}
 	
-	if len(loadErrors) > 0 {
-		return fmt.Errorf("failed to load %d plugins: %v", len(loadErrors), loadErrors)
+	if len(loadErrors) == 0 {
+		return nil
+	}
+	
+	failed := len(loadErrors)
+	if !atomic {
+		return fmt.Errorf("failed to load %d plugins: %w", failed, errors.Join(loadErrors...))
 	}
 	
+	// Unload in reverse so nothing is left depending on a removed plugin
+	for i := len(loaded) - 1; i >= 0; i-- {
+		if err := m.UnloadPlugin(loaded[i]); err != nil {
+			loadErrors = append(loadErrors, err)
+		}
+	}
+	m.logger.Warn("Plugin loading failed, unloaded the plugins loaded with it",
+		zap.Strings("plugins", loaded),
+		zap.Int("failed", failed))
+	
+	return fmt.Errorf("failed to load %d plugins, rolled back %d: %w", failed, len(loaded), errors.Join(loadErrors...))
+}
+
+// PluginSnapshot captures the loaded plugins with their configuration, state
+// and order, so Restore can bring a manager back to that set
+type PluginSnapshot struct {
+	Plugins []PluginSnapshotEntry `json:"plugins"`
+}
+
+// PluginSnapshotEntry is a single plugin captured in a PluginSnapshot
+type PluginSnapshotEntry struct {
+	Name   string                 `json:"name"`
+	State  PluginState            `json:"state"`
+	Config map[string]interface{} `json:"config"`
+	Order  int                    `json:"order,omitempty"`
+}
+
+// Snapshot captures every loaded plugin's configuration, state and order.
+// Configurations are copied, so later changes do not leak into the snapshot.
+func (m *Manager) Snapshot() PluginSnapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	snapshot := PluginSnapshot{Plugins: make([]PluginSnapshotEntry, 0, len(m.plugins))}
+	for name, entry := range m.plugins {
+		entry.mu.RLock()
+		snapshot.Plugins = append(snapshot.Plugins, PluginSnapshotEntry{
+			Name:   name,
+			State:  entry.state,
+			Config: copyDefaultValue(entry.config).(map[string]interface{}),
+			Order:  entry.order,
+		})
+		entry.mu.RUnlock()
+	}
+	
+	sort.Slice(snapshot.Plugins, func(i, j int) bool {
+		return snapshot.Plugins[i].Name < snapshot.Plugins[j].Name
+	})
+	
+	return snapshot
+}
+
+// Restore reconciles the live plugins with a snapshot: plugins missing from
+// it are unloaded, missing ones loaded, changed configurations reloaded and
+// orders reset. Plugins enabled in the snapshot are enabled, dependencies
+// first, and the others disabled if enabled. Every plugin is attempted and
+// the errors are combined.
+func (m *Manager) Restore(snapshot PluginSnapshot) error {
+	var restoreErrors []error
+	
+	wanted := make(map[string]bool, len(snapshot.Plugins))
+	for _, target := range snapshot.Plugins {
+		wanted[target.Name] = true
+	}
+	
+	for _, info := range m.ListPlugins() {
+		if !wanted[info.Name] {
+			if err := m.UnloadPlugin(info.Name); err != nil {
+				restoreErrors = append(restoreErrors, err)
+			}
+		}
+	}
+	
+	var toEnable []string
+	for _, target := range snapshot.Plugins {
+		m.mu.RLock()
+		entry, exists := m.plugins[target.Name]
+		m.mu.RUnlock()
+		
+		var err error
+		if !exists {
+			err = m.LoadPlugin(target.Name, target.Config)
+		} else {
+			entry.mu.RLock()
+			changed := !reflect.DeepEqual(entry.config, target.Config)
+			entry.mu.RUnlock()
+			if changed {
+				err = m.ReloadPlugin(target.Name, target.Config)
+			}
+		}
+		if err == nil {
+			err = m.SetPluginOrder(target.Name, target.Order)
+		}
+		if err != nil {
+			restoreErrors = append(restoreErrors, err)
+			continue
+		}
+		
+		if target.State == StateEnabled {
+			toEnable = append(toEnable, target.Name)
+		} else if err := m.restoreDisabled(target.Name); err != nil {
+			restoreErrors = append(restoreErrors, err)
+		}
+	}
+	
+	// Enable in passes until no more progress is made, so dependencies come
+	// up before the plugins that need them
+	for len(toEnable) > 0 {
+		var pending []string
+		var pendingErrors []error
+		for _, name := range toEnable {
+			if err := m.EnablePlugin(name); err != nil {
+				pending = append(pending, name)
+				pendingErrors = append(pendingErrors, err)
+			}
+		}
+		if len(pending) == len(toEnable) {
+			restoreErrors = append(restoreErrors, pendingErrors...)
+			break
+		}
+		toEnable = pending
+	}
+	
+	if len(restoreErrors) > 0 {
+		return fmt.Errorf("failed to restore %d plugins: %w", len(restoreErrors), errors.Join(restoreErrors...))
+	}
+	
+	m.logger.Info("Plugins restored from snapshot",
+		zap.Int("plugins", len(snapshot.Plugins)))
+	
 	return nil
 }
 
+// restoreDisabled disables a plugin that is enabled, leaving plugins that
+// are only loaded or already disabled as they are
+func (m *Manager) restoreDisabled(name string) error {
+	_, state, exists := m.GetPluginEntry(name)
+	if !exists {
+		return NewPluginError(name, "disable", "plugin not found", ErrPluginNotFound)
+	}
+	if state != StateEnabled {
+		return nil
+	}
+	return m.DisablePlugin(name)
+}
+
 // validateDependencies validates that all required dependencies exist and are registered
-func (m *Manager) validateDependencies(dependencies []string) error {
+func (m *Manager) validateDependencies(name string, dependencies []string) error {
 	for _, dep := range dependencies {
+		if dep == name {
+			return fmt.Errorf("plugin cannot depend on itself: %s", dep)
+		}
 		if _, exists := m.registry.GetFactory(dep); !exists {
 			return fmt.Errorf("dependency not found: %s", dep)
 		}
@@ -880,6 +1793,15 @@ func (m *Manager) validateDependencies(dependencies []string) error {
 	return nil
 }
 
+// pluginDependencies returns the dependencies a plugin declares through
+// DependencyProvider, or nil if it declares none
+func pluginDependencies(plugin Plugin) []string {
+	if provider, ok := plugin.(DependencyProvider); ok {
+		return provider.GetDependencies()
+	}
+	return nil
+}
+
 // validateEnabledDependencies validates that all dependencies are enabled
 func (m *Manager) validateEnabledDependencies(dependencies []string) error {
 	m.mu.RLock()
@@ -945,6 +1867,7 @@ func (m *Manager) performPluginHealthCheck(entry *pluginEntry, checker HealthChe
 		entry.lastError = health.Message
 		if entry.state == StateEnabled {
 			entry.state = StateError
+			m.invalidateMiddlewares()
 		}
 		if m.metricsCollector != nil {
 			m.metricsCollector.IncPluginError(entry.plugin.Name(), "health_check_failed")
@@ -967,6 +1890,9 @@ func (m *Manager) Shutdown() error {
 	// Cancel shutdown context to stop health checks
 	m.shutdownFunc()
 	
+	// Let queued async post-processing finish before plugins are unloaded
+	m.drainAsyncPostProcess()
+	
 	// Get all plugins
 	m.mu.RLock()
 	pluginNames := make([]string, 0, len(m.plugins))
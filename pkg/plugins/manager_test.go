@@ -1,7 +1,11 @@
 package plugins
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -9,6 +13,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 	"vanta/pkg/config"
 )
 
@@ -119,6 +125,40 @@ func TestPluginManager_LoadFromConfig(t *testing.T) {
 	}
 }
 
+func TestPluginManager_LoadFromConfigRequiredEnvVar(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := NewManager(logger)
+	defer manager.Shutdown()
+
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("example-middleware", NewExampleMiddlewarePlugin))
+
+	configs := []config.PluginConfig{
+		{
+			Name:    "example-middleware",
+			Enabled: true,
+			Config: map[string]interface{}{
+				"header_value": "${VANTA_TEST_HEADER_VALUE:?header value is required}",
+			},
+		},
+	}
+
+	// Unset: loading fails and the plugin is not registered
+	t.Setenv("VANTA_TEST_HEADER_VALUE", "")
+	err := manager.LoadFromConfig(configs)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrRequiredEnvVarUnset)
+	assert.Contains(t, err.Error(), "header value is required")
+	assert.Empty(t, manager.ListPlugins())
+
+	// Set: the substituted value reaches the plugin
+	t.Setenv("VANTA_TEST_HEADER_VALUE", "from-env")
+	require.NoError(t, manager.LoadFromConfig(configs))
+
+	plugins := manager.ListPlugins()
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "from-env", plugins[0].Config["header_value"])
+}
+
 func TestPluginManager_Middleware(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	manager := NewManager(logger)
@@ -242,6 +282,38 @@ func TestPluginManager_ReloadPlugin(t *testing.T) {
 	assert.Equal(t, "reloaded-value", configurablePlugin.headerValue)
 }
 
+func TestPluginManager_GetPluginEntryReturnsDisabledPlugins(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := NewManager(logger)
+	defer manager.Shutdown()
+
+	err := manager.GetRegistry().RegisterPlugin("example-middleware", NewExampleMiddlewarePlugin)
+	require.NoError(t, err)
+	require.NoError(t, manager.LoadPlugin("example-middleware", map[string]interface{}{}))
+
+	// Loaded but never enabled
+	plugin, state, found := manager.GetPluginEntry("example-middleware")
+	require.True(t, found)
+	assert.Equal(t, "example-middleware", plugin.Name())
+	assert.Equal(t, StateLoaded, state)
+
+	require.NoError(t, manager.EnablePlugin("example-middleware"))
+	require.NoError(t, manager.DisablePlugin("example-middleware"))
+
+	// The request path no longer sees it, management still does
+	_, found = manager.GetPlugin("example-middleware")
+	assert.False(t, found)
+
+	plugin, state, found = manager.GetPluginEntry("example-middleware")
+	require.True(t, found)
+	assert.Equal(t, "example-middleware", plugin.Name())
+	assert.Equal(t, StateDisabled, state)
+
+	_, state, found = manager.GetPluginEntry("missing")
+	assert.False(t, found)
+	assert.Equal(t, StateUnloaded, state)
+}
+
 func TestPluginManager_HealthCheck(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	manager := NewManager(logger)
@@ -343,6 +415,127 @@ func TestPluginManager_Metrics(t *testing.T) {
 	assert.True(t, stats.AverageLatency > 0)
 }
 
+func TestPluginManager_LatencyBreakdown(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	manager := NewManager(logger)
+	defer manager.Shutdown()
+
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("example-middleware", NewExampleMiddlewarePlugin))
+	require.NoError(t, manager.LoadPlugin("example-middleware", map[string]interface{}{}))
+	require.NoError(t, manager.EnablePlugin("example-middleware"))
+
+	// Nothing recorded before any traffic
+	breakdown := manager.GetLatencyBreakdown()
+	require.Len(t, breakdown.Plugins, 1)
+	assert.Equal(t, int64(0), breakdown.Plugins[0].PreProcess.Count)
+	assert.Equal(t, int64(0), breakdown.Handler.Count)
+
+	wrappedHandler := manager.CreateMiddlewareFunc()(func(ctx *fasthttp.RequestCtx) {
+		time.Sleep(2 * time.Millisecond)
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	for i := 0; i < 3; i++ {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/test")
+		ctx.Request.Header.SetMethod("GET")
+		ctx.SetUserValue("request_id", fmt.Sprintf("req-%d", i))
+		wrappedHandler(ctx)
+	}
+
+	breakdown = manager.GetLatencyBreakdown()
+	require.Len(t, breakdown.Plugins, 1)
+
+	plugin := breakdown.Plugins[0]
+	assert.Equal(t, "example-middleware", plugin.Name)
+	assert.Equal(t, PriorityNormal, plugin.Priority)
+	assert.Equal(t, int64(3), plugin.PreProcess.Count)
+	assert.Equal(t, int64(3), plugin.PostProcess.Count)
+	assert.True(t, plugin.PreProcess.AverageLatency > 0)
+	assert.True(t, plugin.PostProcess.AverageLatency > 0)
+
+	assert.Equal(t, int64(3), breakdown.Handler.Count)
+	assert.GreaterOrEqual(t, breakdown.Handler.AverageLatency, 2*time.Millisecond)
+
+	// Overall metrics count both phases
+	pluginStats := manager.GetPluginMetrics()["plugin_stats"].(map[string]PluginMetrics)
+	assert.Equal(t, int64(6), pluginStats["example-middleware"].RequestsProcessed)
+}
+
+func newPriorityTestManager(t *testing.T, strict bool) (*Manager, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.WarnLevel)
+	manager := NewManager(zap.New(core))
+	t.Cleanup(func() { manager.Shutdown() })
+
+	manager.SetStrictPriorities(strict)
+	require.NoError(t, RegisterBuiltinPlugins(manager.GetRegistry()))
+	require.NoError(t, manager.LoadPlugin("cors", map[string]interface{}{}))
+	require.NoError(t, manager.LoadPlugin("rate_limit", map[string]interface{}{}))
+	return manager, logs
+}
+
+func TestPluginManager_SamePriorityWarns(t *testing.T) {
+	manager, logs := newPriorityTestManager(t, false)
+
+	// cors and rate_limit both run at PriorityNormal
+	require.NoError(t, manager.EnablePlugin("cors"))
+	assert.Equal(t, 0, logs.Len(), "a single plugin at a priority is unambiguous")
+
+	require.NoError(t, manager.EnablePlugin("rate_limit"))
+
+	warnings := logs.FilterMessageSnippet("share a priority").All()
+	require.Len(t, warnings, 1)
+	fields := warnings[0].ContextMap()
+	assert.Equal(t, "rate_limit", fields["plugin"])
+	assert.Equal(t, []interface{}{"cors"}, fields["conflicts_with"])
+	assert.Len(t, manager.GetMiddlewares(), 2)
+}
+
+func TestPluginManager_SamePriorityStrictFails(t *testing.T) {
+	manager, _ := newPriorityTestManager(t, true)
+
+	require.NoError(t, manager.EnablePlugin("cors"))
+
+	err := manager.EnablePlugin("rate_limit")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPriorityConflict)
+	assert.Contains(t, err.Error(), "cors")
+	assert.Len(t, manager.GetMiddlewares(), 1)
+}
+
+func TestPluginManager_ExplicitOrderDisambiguates(t *testing.T) {
+	manager, logs := newPriorityTestManager(t, true)
+
+	require.NoError(t, manager.SetPluginOrder("rate_limit", 1))
+	require.NoError(t, manager.SetPluginOrder("cors", 2))
+	require.NoError(t, manager.EnablePlugin("cors"))
+	require.NoError(t, manager.EnablePlugin("rate_limit"))
+	assert.Equal(t, 0, logs.Len())
+
+	middlewares := manager.GetMiddlewares()
+	require.Len(t, middlewares, 2)
+	assert.Equal(t, "rate_limit", middlewares[0].Name())
+	assert.Equal(t, "cors", middlewares[1].Name())
+}
+
+func TestPluginManager_LoadFromConfigAppliesOrder(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	manager := NewManager(zap.New(core))
+	defer manager.Shutdown()
+	require.NoError(t, RegisterBuiltinPlugins(manager.GetRegistry()))
+
+	require.NoError(t, manager.LoadFromConfig([]config.PluginConfig{
+		{Name: "cors", Enabled: true, Order: 2},
+		{Name: "rate_limit", Enabled: true, Order: 1},
+	}))
+	assert.Equal(t, 0, logs.FilterMessageSnippet("share a priority").Len())
+
+	middlewares := manager.GetMiddlewares()
+	require.Len(t, middlewares, 2)
+	assert.Equal(t, "rate_limit", middlewares[0].Name())
+	assert.Equal(t, "cors", middlewares[1].Name())
+}
+
 func TestPluginManager_ErrorHandling(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	manager := NewManager(logger)
@@ -496,4 +689,912 @@ func BenchmarkPluginManager_CreateMiddlewareFunc(b *testing.B) {
 			wrappedHandler(ctx)
 		}
 	})
-}
\ No newline at end of file
+}
+
+// BenchmarkPluginManager_AcquireMiddlewares compares serving the cached
+// middleware set with rebuilding it for every request
+func BenchmarkPluginManager_AcquireMiddlewares(b *testing.B) {
+	manager := NewManager(zap.NewNop())
+	defer manager.Shutdown()
+
+	for i := 0; i < 8; i++ {
+		name := fmt.Sprintf("middleware%d", i)
+		require.NoError(b, manager.GetRegistry().RegisterPlugin(name, func() Plugin {
+			return &testMiddleware{name: name, priority: Priority(i * 10)}
+		}))
+		require.NoError(b, manager.LoadPlugin(name, map[string]interface{}{}))
+		require.NoError(b, manager.EnablePlugin(name))
+	}
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_, release := manager.acquireMiddlewares()
+				release()
+			}
+		})
+	})
+
+	b.Run("rebuilt", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_, entries := manager.middlewareEntries()
+				for _, entry := range entries {
+					entry.acquire()
+				}
+				for _, entry := range entries {
+					entry.release()
+				}
+			}
+		})
+	})
+}
+
+func TestPluginManager_MiddlewareCacheInvalidation(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	defer manager.Shutdown()
+
+	for _, plugin := range []*testMiddleware{
+		{name: "first", priority: PriorityHigh},
+		{name: "second", priority: PriorityNormal},
+	} {
+		require.NoError(t, manager.GetRegistry().RegisterPlugin(plugin.name, func() Plugin { return plugin }))
+		require.NoError(t, manager.LoadPlugin(plugin.name, map[string]interface{}{}))
+	}
+
+	names := func() []string {
+		var names []string
+		for _, middleware := range manager.GetMiddlewares() {
+			names = append(names, middleware.Name())
+		}
+		return names
+	}
+
+	assert.Empty(t, names())
+
+	require.NoError(t, manager.EnablePlugin("second"))
+	assert.Equal(t, []string{"second"}, names())
+
+	// Repeated reads are served from the same cached set
+	cached := manager.middlewareCache.Load()
+	require.NotNil(t, cached)
+	names()
+	assert.Same(t, cached, manager.middlewareCache.Load())
+
+	require.NoError(t, manager.EnablePlugin("first"))
+	assert.Equal(t, []string{"first", "second"}, names())
+
+	require.NoError(t, manager.DisablePlugin("first"))
+	assert.Equal(t, []string{"second"}, names())
+
+	middlewares, release := manager.acquireMiddlewares()
+	release()
+	require.Len(t, middlewares, 1)
+	assert.Equal(t, "second", middlewares[0].Name())
+
+	require.NoError(t, manager.UnloadPlugin("second"))
+	assert.Empty(t, names())
+}
+
+// testMiddleware is a middleware that records calls, optionally sleeping,
+// running a hook, short-circuiting or failing in PreProcess
+type testMiddleware struct {
+	name     string
+	priority Priority
+	delay    time.Duration
+	onPre    func(ctx *RequestContext)
+	stop     bool
+	fail     error
+	pre      int
+	post     int
+
+	lastResponse *ResponseContext
+}
+
+func (p *testMiddleware) Name() string        { return p.name }
+func (p *testMiddleware) Version() string     { return "1.0.0" }
+func (p *testMiddleware) Description() string { return "test middleware" }
+func (p *testMiddleware) Priority() Priority  { return p.priority }
+
+func (p *testMiddleware) Init(ctx context.Context, config map[string]interface{}, logger *zap.Logger) error {
+	return nil
+}
+
+func (p *testMiddleware) Cleanup(ctx context.Context) error { return nil }
+
+func (p *testMiddleware) ShouldApply(req *fasthttp.RequestCtx) bool { return true }
+
+func (p *testMiddleware) PreProcess(ctx *RequestContext) (bool, error) {
+	p.pre++
+	time.Sleep(p.delay)
+	if p.onPre != nil {
+		p.onPre(ctx)
+	}
+	if p.fail != nil {
+		return false, p.fail
+	}
+	return !p.stop, nil
+}
+
+func (p *testMiddleware) PostProcess(ctx *ResponseContext) error {
+	p.post++
+	p.lastResponse = ctx
+	return nil
+}
+
+func newBudgetTestManager(t *testing.T, budget time.Duration) (*Manager, *observer.ObservedLogs, *testMiddleware) {
+	core, logs := observer.New(zap.WarnLevel)
+	manager := NewManager(zap.New(core))
+	t.Cleanup(func() { manager.Shutdown() })
+	manager.SetExecutionBudget(budget)
+
+	slow := &testMiddleware{name: "slow", priority: PriorityHigh, delay: 20 * time.Millisecond}
+	late := &testMiddleware{name: "late", priority: PriorityLow}
+	enableTestMiddlewares(t, manager, slow, late)
+	return manager, logs, late
+}
+
+func enableTestMiddlewares(t *testing.T, manager *Manager, middlewares ...*testMiddleware) {
+	for _, plugin := range middlewares {
+		plugin := plugin
+		require.NoError(t, manager.GetRegistry().RegisterPlugin(plugin.name, func() Plugin { return plugin }))
+		require.NoError(t, manager.LoadPlugin(plugin.name, map[string]interface{}{}))
+		require.NoError(t, manager.EnablePlugin(plugin.name))
+	}
+}
+
+func runBudgetRequest(manager *Manager) bool {
+	handled := false
+	wrappedHandler := manager.CreateMiddlewareFunc()(func(ctx *fasthttp.RequestCtx) {
+		handled = true
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/test")
+	ctx.Request.Header.SetMethod("GET")
+	wrappedHandler(ctx)
+	return handled
+}
+
+func TestPluginManager_ExecutionBudgetSkipsLowPriority(t *testing.T) {
+	manager, logs, late := newBudgetTestManager(t, 5*time.Millisecond)
+
+	assert.True(t, runBudgetRequest(manager), "the handler still runs when plugins are skipped")
+	assert.Equal(t, 0, late.pre)
+	assert.Equal(t, 0, late.post, "skipped plugins are not post-processed")
+
+	warnings := logs.FilterMessageSnippet("execution budget exceeded").All()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "late", warnings[0].ContextMap()["plugin"])
+}
+
+func TestPluginManager_ExecutionBudgetDisabled(t *testing.T) {
+	manager, logs, late := newBudgetTestManager(t, 0)
+
+	assert.True(t, runBudgetRequest(manager))
+	assert.Equal(t, 1, late.pre)
+	assert.Equal(t, 1, late.post)
+	assert.Equal(t, 0, logs.FilterMessageSnippet("execution budget exceeded").Len())
+}
+
+func TestPluginManager_ShortCircuitPostProcessesPluginsThatRan(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	t.Cleanup(func() { manager.Shutdown() })
+
+	logging := &testMiddleware{name: "logging", priority: PriorityHigh}
+	auth := &testMiddleware{name: "auth", priority: PriorityNormal, stop: true, onPre: func(ctx *RequestContext) {
+		ctx.RequestCtx.SetStatusCode(fasthttp.StatusUnauthorized)
+		ctx.RequestCtx.SetBodyString(`{"error":"unauthorized"}`)
+	}}
+	later := &testMiddleware{name: "later", priority: PriorityLow}
+	enableTestMiddlewares(t, manager, logging, auth, later)
+
+	handled := false
+	wrappedHandler := manager.CreateMiddlewareFunc()(func(ctx *fasthttp.RequestCtx) {
+		handled = true
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/protected")
+	ctx.Request.Header.SetMethod("GET")
+	wrappedHandler(ctx)
+
+	assert.False(t, handled, "the handler is skipped")
+	assert.Equal(t, fasthttp.StatusUnauthorized, ctx.Response.StatusCode(), "the short-circuit response is kept")
+	assert.Equal(t, `{"error":"unauthorized"}`, string(ctx.Response.Body()))
+
+	assert.Equal(t, 1, logging.post, "plugins that ran before the short-circuit clean up once")
+	assert.Equal(t, 1, auth.post, "the short-circuiting plugin cleans up once")
+	assert.Equal(t, 0, later.pre)
+	assert.Equal(t, 0, later.post, "plugins that never ran are not post-processed")
+
+	require.NotNil(t, logging.lastResponse)
+	assert.Equal(t, "auth", logging.lastResponse.ShortCircuitedBy)
+	assert.Equal(t, `{"error":"unauthorized"}`, string(logging.lastResponse.ResponseBody))
+	assert.NoError(t, logging.lastResponse.ProcessingError)
+}
+
+func TestPluginManager_PreProcessErrorPostProcessesEarlierPlugins(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	t.Cleanup(func() { manager.Shutdown() })
+
+	logging := &testMiddleware{name: "logging", priority: PriorityHigh}
+	broken := &testMiddleware{name: "broken", priority: PriorityNormal, fail: fmt.Errorf("backend unavailable")}
+	later := &testMiddleware{name: "later", priority: PriorityLow}
+	enableTestMiddlewares(t, manager, logging, broken, later)
+
+	handled := false
+	wrappedHandler := manager.CreateMiddlewareFunc()(func(ctx *fasthttp.RequestCtx) {
+		handled = true
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/orders")
+	ctx.Request.Header.SetMethod("GET")
+	wrappedHandler(ctx)
+
+	assert.False(t, handled)
+	assert.Equal(t, fasthttp.StatusInternalServerError, ctx.Response.StatusCode())
+	assert.Equal(t, 1, logging.post)
+	assert.Equal(t, 0, broken.post, "a failed pre-process is not post-processed")
+	assert.Equal(t, 0, later.post)
+
+	require.NotNil(t, logging.lastResponse)
+	assert.EqualError(t, logging.lastResponse.ProcessingError, "backend unavailable")
+	assert.Equal(t, "broken", logging.lastResponse.ShortCircuitedBy)
+}
+
+func TestPluginManager_PreProcessErrorWritesStructuredBody(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	t.Cleanup(func() { manager.Shutdown() })
+
+	broken := &testMiddleware{name: "broken", priority: PriorityNormal, fail: fmt.Errorf("backend unavailable")}
+	enableTestMiddlewares(t, manager, broken)
+
+	wrappedHandler := manager.CreateMiddlewareFunc()(func(ctx *fasthttp.RequestCtx) {})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/orders")
+	ctx.Request.Header.SetMethod("GET")
+	ctx.SetUserValue("request_id", "req-123")
+	wrappedHandler(ctx)
+
+	assert.Equal(t, fasthttp.StatusInternalServerError, ctx.Response.StatusCode())
+	assert.Equal(t, "application/json", string(ctx.Response.Header.ContentType()))
+	assert.JSONEq(t, `{"error":"plugin_error","plugin":"broken","request_id":"req-123"}`, string(ctx.Response.Body()))
+	assert.NotContains(t, string(ctx.Response.Body()), "backend unavailable", "error details stay in the logs")
+}
+
+func TestPluginManager_HandlerRunPostProcessesEachPluginOnce(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	t.Cleanup(func() { manager.Shutdown() })
+
+	first := &testMiddleware{name: "first", priority: PriorityHigh}
+	second := &testMiddleware{name: "second", priority: PriorityLow}
+	enableTestMiddlewares(t, manager, first, second)
+
+	assert.True(t, runBudgetRequest(manager))
+	assert.Equal(t, 1, first.post)
+	assert.Equal(t, 1, second.post)
+	assert.Empty(t, first.lastResponse.ShortCircuitedBy)
+}
+
+// dependentMiddleware is a test middleware that declares dependencies
+type dependentMiddleware struct {
+	*testMiddleware
+	Dependencies
+}
+
+func findPluginInfo(t *testing.T, manager *Manager, name string) PluginInfo {
+	for _, info := range manager.ListPlugins() {
+		if info.Name == name {
+			return info
+		}
+	}
+	t.Fatalf("plugin %s not listed", name)
+	return PluginInfo{}
+}
+
+func TestPluginManager_DependencyProvider(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	t.Cleanup(func() { manager.Shutdown() })
+
+	auth := &testMiddleware{name: "auth", priority: PriorityHigh}
+	audit := &dependentMiddleware{
+		testMiddleware: &testMiddleware{name: "audit", priority: PriorityLow},
+		Dependencies:   Dependencies{"auth"},
+	}
+	registry := manager.GetRegistry()
+	require.NoError(t, registry.RegisterPlugin("audit", func() Plugin { return audit }))
+
+	// The dependency must be registered before the plugin can load
+	err := manager.LoadPlugin("audit", map[string]interface{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency not found: auth")
+
+	require.NoError(t, registry.RegisterPlugin("auth", func() Plugin { return auth }))
+	require.NoError(t, manager.LoadPlugin("audit", map[string]interface{}{}))
+
+	assert.Equal(t, []string{"auth"}, findPluginInfo(t, manager, "audit").Dependencies)
+
+	// ...and enabled before the plugin can be enabled
+	err = manager.EnablePlugin("audit")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency not loaded: auth")
+
+	require.NoError(t, manager.LoadPlugin("auth", map[string]interface{}{}))
+	err = manager.EnablePlugin("audit")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency not enabled: auth")
+
+	require.NoError(t, manager.EnablePlugin("auth"))
+	require.NoError(t, manager.EnablePlugin("audit"))
+}
+
+func TestPluginManager_DependencyProviderSelfDependency(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	t.Cleanup(func() { manager.Shutdown() })
+
+	loop := &dependentMiddleware{
+		testMiddleware: &testMiddleware{name: "loop"},
+		Dependencies:   Dependencies{"loop"},
+	}
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("loop", func() Plugin { return loop }))
+
+	err := manager.LoadPlugin("loop", map[string]interface{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot depend on itself")
+}
+
+func TestPluginManager_NoDependencyProvider(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	t.Cleanup(func() { manager.Shutdown() })
+
+	plain := &testMiddleware{name: "plain"}
+	enableTestMiddlewares(t, manager, plain)
+
+	assert.Empty(t, findPluginInfo(t, manager, "plain").Dependencies)
+}
+
+func newJSONRequestContext(contentType, body string) *RequestContext {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/orders")
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.SetContentType(contentType)
+	ctx.Request.SetBodyString(body)
+
+	return &RequestContext{RequestCtx: ctx, StartTime: time.Now(), Context: context.Background()}
+}
+
+func TestRequestContext_JSONBodyParsedOnce(t *testing.T) {
+	requestCtx := newJSONRequestContext("application/json; charset=utf-8", `{"item":"book","qty":2}`)
+
+	first, err := requestCtx.JSONBody()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"item": "book", "qty": 2.0}, first)
+
+	// A second parse would fail on this body; the cached value is returned instead
+	requestCtx.RequestCtx.Request.SetBodyString("not json")
+	second, err := requestCtx.JSONBody()
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestRequestContext_JSONBodyErrors(t *testing.T) {
+	_, err := newJSONRequestContext("text/plain", `{"a":1}`).JSONBody()
+	assert.ErrorIs(t, err, ErrBodyNotJSON)
+
+	_, err = newJSONRequestContext("application/json", `{"a":`).JSONBody()
+	assert.ErrorContains(t, err, "invalid JSON request body")
+
+	value, err := newJSONRequestContext("application/problem+json", "").JSONBody()
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestPluginManager_JSONBodySharedBetweenPlugins(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	t.Cleanup(func() { manager.Shutdown() })
+
+	var seenByLater interface{}
+	first := &testMiddleware{name: "first", priority: PriorityHigh, onPre: func(ctx *RequestContext) {
+		body, err := ctx.JSONBody()
+		require.NoError(t, err)
+		obj := body.(map[string]interface{})
+		obj["discount"] = "SPRING"
+		ctx.SetJSONBody(obj)
+	}}
+	later := &testMiddleware{name: "later", priority: PriorityLow, onPre: func(ctx *RequestContext) {
+		// Drop the raw body: the later plugin must not parse it again
+		ctx.RequestCtx.Request.Header.SetContentType("text/plain")
+		body, err := ctx.JSONBody()
+		require.NoError(t, err)
+		seenByLater = body
+	}}
+	enableTestMiddlewares(t, manager, first, later)
+
+	var handlerBody []byte
+	wrappedHandler := manager.CreateMiddlewareFunc()(func(ctx *fasthttp.RequestCtx) {
+		handlerBody = append([]byte(nil), ctx.Request.Body()...)
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/orders")
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.SetContentType("application/json")
+	ctx.Request.SetBodyString(`{"item":"book"}`)
+	wrappedHandler(ctx)
+
+	assert.Equal(t, map[string]interface{}{"item": "book", "discount": "SPRING"}, seenByLater)
+	assert.JSONEq(t, `{"item":"book","discount":"SPRING"}`, string(handlerBody), "the handler sees the re-serialized body")
+}
+
+func TestPluginManager_JSONBodyUnmodifiedKeepsRawBytes(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	t.Cleanup(func() { manager.Shutdown() })
+
+	reader := &testMiddleware{name: "reader", priority: PriorityHigh, onPre: func(ctx *RequestContext) {
+		_, err := ctx.JSONBody()
+		require.NoError(t, err)
+	}}
+	enableTestMiddlewares(t, manager, reader)
+
+	var handlerBody string
+	wrappedHandler := manager.CreateMiddlewareFunc()(func(ctx *fasthttp.RequestCtx) {
+		handlerBody = string(ctx.Request.Body())
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.SetContentType("application/json")
+	ctx.Request.SetBodyString(`{ "b": 1,  "a": 2 }`)
+	wrappedHandler(ctx)
+
+	assert.Equal(t, `{ "b": 1,  "a": 2 }`, handlerBody)
+}
+
+// asyncTestMiddleware post-processes in the background, blocking until released
+type asyncTestMiddleware struct {
+	testMiddleware
+	release chan struct{}
+	done    chan *ResponseContext
+}
+
+func newAsyncTestMiddleware(name string) *asyncTestMiddleware {
+	return &asyncTestMiddleware{
+		testMiddleware: testMiddleware{name: name, priority: PriorityLow},
+		release:        make(chan struct{}),
+		done:           make(chan *ResponseContext, 8),
+	}
+}
+
+func (p *asyncTestMiddleware) AsyncPostProcess() bool { return true }
+
+func (p *asyncTestMiddleware) PostProcess(ctx *ResponseContext) error {
+	<-p.release
+	p.done <- ctx
+	return nil
+}
+
+func enableAsyncTestMiddleware(t *testing.T, manager *Manager, plugin *asyncTestMiddleware) {
+	require.NoError(t, manager.GetRegistry().RegisterPlugin(plugin.name, func() Plugin { return plugin }))
+	require.NoError(t, manager.LoadPlugin(plugin.name, map[string]interface{}{}))
+	require.NoError(t, manager.EnablePlugin(plugin.name))
+}
+
+// runAsyncRequest runs one request through the manager and reports on the
+// returned channel once the wrapped handler has returned
+func runAsyncRequest(manager *Manager, ctx *fasthttp.RequestCtx) <-chan struct{} {
+	returned := make(chan struct{})
+	wrappedHandler := manager.CreateMiddlewareFunc()(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusCreated)
+		ctx.Response.Header.Set("X-Test", "handled")
+		ctx.SetBodyString(`{"id":1}`)
+	})
+	go func() {
+		wrappedHandler(ctx)
+		close(returned)
+	}()
+	return returned
+}
+
+func newAsyncRequestCtx() *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/test")
+	ctx.Request.Header.SetMethod("POST")
+	ctx.SetUserValue("request_id", "req-1")
+	return ctx
+}
+
+func TestPluginManager_AsyncPostProcessDoesNotBlockResponse(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	defer manager.Shutdown()
+
+	plugin := newAsyncTestMiddleware("async")
+	enableAsyncTestMiddleware(t, manager, plugin)
+
+	ctx := newAsyncRequestCtx()
+	select {
+	case <-runAsyncRequest(manager, ctx):
+	case <-time.After(time.Second):
+		t.Fatal("handler blocked on async post-processing")
+	}
+
+	// fasthttp reuses the context once the handler returns
+	ctx.Request.Reset()
+	ctx.Response.Reset()
+	ctx.ResetUserValues()
+	close(plugin.release)
+
+	select {
+	case responseCtx := <-plugin.done:
+		assert.Equal(t, "POST", responseCtx.Method())
+		assert.Equal(t, "/test", responseCtx.Path())
+		assert.Equal(t, fasthttp.StatusCreated, responseCtx.RequestCtx.Response.StatusCode())
+		assert.Equal(t, "handled", string(responseCtx.RequestCtx.Response.Header.Peek("X-Test")))
+		assert.Equal(t, `{"id":1}`, string(responseCtx.ResponseBody))
+		assert.Equal(t, "req-1", responseCtx.RequestCtx.UserValue("request_id"))
+	case <-time.After(time.Second):
+		t.Fatal("async post-process did not run")
+	}
+}
+
+func TestPluginManager_AsyncPostProcessBackpressure(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	defer manager.Shutdown()
+	manager.SetAsyncPostProcess(1, 1)
+
+	plugin := newAsyncTestMiddleware("async")
+	enableAsyncTestMiddleware(t, manager, plugin)
+
+	// The first request occupies the worker and the second fills the queue
+	<-runAsyncRequest(manager, newAsyncRequestCtx())
+	require.Eventually(t, func() bool { return len(manager.asyncPool.jobs) == 0 }, time.Second, time.Millisecond)
+	<-runAsyncRequest(manager, newAsyncRequestCtx())
+
+	// With the queue full, the third post-processes inline
+	returned := runAsyncRequest(manager, newAsyncRequestCtx())
+	select {
+	case <-returned:
+		t.Fatal("expected post-processing to run inline once the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(plugin.release)
+	<-returned
+	for i := 0; i < 3; i++ {
+		select {
+		case <-plugin.done:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d of 3 post-processes ran", i)
+		}
+	}
+}
+
+func TestPluginManager_AsyncLoggingRecordsResponse(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	manager := NewManager(zap.New(core))
+	require.NoError(t, RegisterBuiltinPlugins(manager.GetRegistry()))
+	require.NoError(t, manager.LoadPlugin("logging", map[string]interface{}{
+		"async_post_process": true,
+	}))
+	require.NoError(t, manager.EnablePlugin("logging"))
+
+	ctx := newAsyncRequestCtx()
+	select {
+	case <-runAsyncRequest(manager, ctx):
+	case <-time.After(time.Second):
+		t.Fatal("handler blocked on async logging")
+	}
+	ctx.Response.Reset()
+
+	// Shutdown waits for queued post-processing
+	manager.Shutdown()
+
+	responses := logs.FilterMessage("HTTP response").All()
+	require.Len(t, responses, 1)
+	fields := responses[0].ContextMap()
+	assert.Equal(t, int64(fasthttp.StatusCreated), fields["status_code"])
+	assert.Equal(t, "/test", fields["path"])
+	assert.Equal(t, "req-1", fields["request_id"])
+}
+
+func loadThreeWithSecondFailing(t *testing.T, atomic bool) (*Manager, error) {
+	manager := NewManager(zap.NewNop())
+	t.Cleanup(func() { manager.Shutdown() })
+	manager.SetAtomicLoad(atomic)
+
+	for _, name := range []string{"first", "third"} {
+		plugin := &testMiddleware{name: name, priority: PriorityNormal}
+		require.NoError(t, manager.GetRegistry().RegisterPlugin(name, func() Plugin { return plugin }))
+	}
+
+	// "second" has no registered factory, so it fails to load
+	err := manager.LoadFromConfig([]config.PluginConfig{
+		{Name: "first", Enabled: true},
+		{Name: "second", Enabled: true},
+		{Name: "third", Enabled: true},
+	})
+	return manager, err
+}
+
+func TestPluginManager_LoadFromConfigAtomicRollsBack(t *testing.T) {
+	manager, err := loadThreeWithSecondFailing(t, true)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPluginNotFound)
+	assert.Contains(t, err.Error(), "rolled back 2")
+	assert.Empty(t, manager.ListPlugins())
+	assert.Empty(t, manager.GetMiddlewares())
+}
+
+func TestPluginManager_LoadFromConfigBestEffortKeepsLoaded(t *testing.T) {
+	manager, err := loadThreeWithSecondFailing(t, false)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPluginNotFound)
+
+	var names []string
+	for _, info := range manager.ListPlugins() {
+		names = append(names, info.Name)
+		assert.Equal(t, StateEnabled, info.State)
+	}
+	assert.ElementsMatch(t, []string{"first", "third"}, names)
+}
+
+func TestPluginManager_LoadFromConfigAtomicKeepsPriorPlugins(t *testing.T) {
+	manager := NewManager(zap.NewNop())
+	defer manager.Shutdown()
+	manager.SetAtomicLoad(true)
+	enableTestMiddlewares(t, manager, &testMiddleware{name: "existing", priority: PriorityNormal})
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("added", func() Plugin {
+		return &testMiddleware{name: "added", priority: PriorityLow}
+	}))
+
+	err := manager.LoadFromConfig([]config.PluginConfig{
+		{Name: "added", Enabled: true},
+		{Name: "missing", Enabled: true},
+	})
+	require.Error(t, err)
+
+	plugins := manager.ListPlugins()
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "existing", plugins[0].Name)
+	assert.Equal(t, StateEnabled, plugins[0].State)
+}
+
+// teardownMiddleware fails any hook run after its Cleanup, as a plugin
+// releasing connections or buffers would
+type teardownMiddleware struct {
+	testMiddleware
+	closed   atomic.Bool
+	cleanups *atomic.Int64
+}
+
+var errUsedAfterCleanup = errors.New("plugin used after cleanup")
+
+func (p *teardownMiddleware) Cleanup(ctx context.Context) error {
+	p.closed.Store(true)
+	p.cleanups.Add(1)
+	return nil
+}
+
+func (p *teardownMiddleware) PreProcess(ctx *RequestContext) (bool, error) {
+	time.Sleep(time.Millisecond)
+	if p.closed.Load() {
+		return false, errUsedAfterCleanup
+	}
+	return true, nil
+}
+
+func (p *teardownMiddleware) PostProcess(ctx *ResponseContext) error {
+	if p.closed.Load() {
+		return errUsedAfterCleanup
+	}
+	return nil
+}
+
+func TestPluginManager_ReloadPreservesInFlightRequests(t *testing.T) {
+	manager := NewManager(zap.NewNop())
+	defer manager.Shutdown()
+
+	var cleanups atomic.Int64
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("teardown", func() Plugin {
+		return &teardownMiddleware{
+			testMiddleware: testMiddleware{name: "teardown", priority: PriorityNormal},
+			cleanups:       &cleanups,
+		}
+	}))
+	require.NoError(t, manager.LoadPlugin("teardown", map[string]interface{}{}))
+	require.NoError(t, manager.EnablePlugin("teardown"))
+
+	handler := manager.CreateMiddlewareFunc()(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	stop := make(chan struct{})
+	var failed, served atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				ctx := &fasthttp.RequestCtx{}
+				ctx.Request.SetRequestURI("/test")
+				handler(ctx)
+				served.Add(1)
+				if ctx.Response.StatusCode() != fasthttp.StatusOK {
+					failed.Add(1)
+				}
+			}
+		}()
+	}
+
+	const reloads = 50
+	for i := 0; i < reloads; i++ {
+		require.NoError(t, manager.ReloadPlugin("teardown", map[string]interface{}{}))
+		time.Sleep(2 * time.Millisecond)
+	}
+	close(stop)
+	wg.Wait()
+
+	assert.Positive(t, served.Load())
+	assert.Zero(t, failed.Load())
+	info := findPluginInfo(t, manager, "teardown")
+	assert.Equal(t, StateEnabled, info.State)
+	assert.Zero(t, info.Metrics.ErrorCount)
+
+	// Every replaced instance is cleaned up once its requests finish
+	assert.Equal(t, int64(reloads), cleanups.Load())
+}
+
+func TestPluginManager_UnloadWaitsForInFlightRequests(t *testing.T) {
+	manager := NewManager(zap.NewNop())
+	defer manager.Shutdown()
+
+	var cleanups atomic.Int64
+	plugin := &teardownMiddleware{
+		testMiddleware: testMiddleware{name: "teardown", priority: PriorityNormal},
+		cleanups:       &cleanups,
+	}
+	entered := make(chan struct{})
+	proceed := make(chan struct{})
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("teardown", func() Plugin { return plugin }))
+	require.NoError(t, manager.LoadPlugin("teardown", map[string]interface{}{}))
+	require.NoError(t, manager.EnablePlugin("teardown"))
+
+	handler := manager.CreateMiddlewareFunc()(func(ctx *fasthttp.RequestCtx) {
+		close(entered)
+		<-proceed
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+	ctx := &fasthttp.RequestCtx{}
+	done := make(chan struct{})
+	go func() {
+		handler(ctx)
+		close(done)
+	}()
+
+	<-entered
+	require.NoError(t, manager.UnloadPlugin("teardown"))
+	assert.Zero(t, cleanups.Load(), "cleanup ran while a request was using the plugin")
+
+	close(proceed)
+	<-done
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, int64(1), cleanups.Load())
+}
+
+func TestPluginManager_SnapshotRestore(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	defer manager.Shutdown()
+
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("example-middleware", NewExampleMiddlewarePlugin))
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("example-configurable", NewExampleConfigurablePlugin))
+	require.NoError(t, manager.LoadPlugin("example-middleware", map[string]interface{}{"header_value": "original"}))
+	require.NoError(t, manager.EnablePlugin("example-middleware"))
+	require.NoError(t, manager.DisablePlugin("example-middleware"))
+
+	snapshot := manager.Snapshot()
+	require.Len(t, snapshot.Plugins, 1)
+	assert.Equal(t, StateDisabled, snapshot.Plugins[0].State)
+
+	// The scenario enables the plugin, changes its config and loads another
+	require.NoError(t, manager.EnablePlugin("example-middleware"))
+	require.NoError(t, manager.ReloadPlugin("example-middleware", map[string]interface{}{"header_value": "changed"}))
+	require.NoError(t, manager.LoadPlugin("example-configurable", map[string]interface{}{}))
+	require.NoError(t, manager.EnablePlugin("example-configurable"))
+
+	require.NoError(t, manager.Restore(snapshot))
+
+	plugins := manager.ListPlugins()
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "example-middleware", plugins[0].Name)
+	assert.Equal(t, StateDisabled, plugins[0].State)
+	assert.Equal(t, "original", plugins[0].Config["header_value"])
+	assert.Empty(t, manager.GetMiddlewares())
+}
+
+func TestPluginManager_RestoreEnablesDependenciesFirst(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	defer manager.Shutdown()
+
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("base", func() Plugin {
+		return &testMiddleware{name: "base", priority: PriorityHigh}
+	}))
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("addon", func() Plugin {
+		return &dependentMiddleware{
+			testMiddleware: &testMiddleware{name: "addon", priority: PriorityLow},
+			Dependencies:   Dependencies{"base"},
+		}
+	}))
+	require.NoError(t, manager.LoadPlugin("base", nil))
+	require.NoError(t, manager.LoadPlugin("addon", nil))
+	require.NoError(t, manager.EnablePlugin("base"))
+	require.NoError(t, manager.EnablePlugin("addon"))
+
+	snapshot := manager.Snapshot()
+
+	// "addon" sorts first, so restoring must retry it once "base" is enabled
+	require.NoError(t, manager.UnloadPlugin("addon"))
+	require.NoError(t, manager.DisablePlugin("base"))
+
+	require.NoError(t, manager.Restore(snapshot))
+	assert.Equal(t, StateEnabled, findPluginInfo(t, manager, "base").State)
+	assert.Equal(t, StateEnabled, findPluginInfo(t, manager, "addon").State)
+}
+
+func TestPluginManager_DeniedPluginFailsToLoad(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	defer manager.Shutdown()
+
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("example-middleware", NewExampleMiddlewarePlugin))
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("example-configurable", NewExampleConfigurablePlugin))
+
+	// The denylist wins even when the plugin is also allowed
+	manager.SetAllowedPlugins([]string{"example-middleware", "example-configurable"})
+	manager.SetDeniedPlugins([]string{"example-middleware"})
+
+	err := manager.LoadPlugin("example-middleware", map[string]interface{}{})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPluginNotPermitted)
+	assert.Contains(t, err.Error(), "plugin is denied")
+	assert.NoError(t, manager.LoadPlugin("example-configurable", map[string]interface{}{}))
+
+	_, loaded := manager.GetPlugin("example-middleware")
+	assert.False(t, loaded)
+}
+
+func TestPluginManager_AllowlistBlocksUnlistedPlugins(t *testing.T) {
+	manager := NewManager(zaptest.NewLogger(t))
+	defer manager.Shutdown()
+
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("example-middleware", NewExampleMiddlewarePlugin))
+	require.NoError(t, manager.GetRegistry().RegisterPlugin("example-configurable", NewExampleConfigurablePlugin))
+	manager.SetAllowedPlugins([]string{"example-configurable"})
+
+	err := manager.LoadFromConfig([]config.PluginConfig{
+		{Name: "example-middleware", Enabled: true},
+		{Name: "example-configurable", Enabled: true},
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPluginNotPermitted)
+	assert.Contains(t, err.Error(), "not in the allowed list")
+
+	plugins := manager.ListPlugins()
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "example-configurable", plugins[0].Name)
+
+	// Clearing the allowlist lets every plugin load again
+	manager.SetAllowedPlugins(nil)
+	assert.NoError(t, manager.LoadPlugin("example-middleware", map[string]interface{}{}))
+}
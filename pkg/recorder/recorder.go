@@ -1,9 +1,13 @@
 package recorder
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +21,7 @@ type RecordingEngine interface {
 	Start(config *config.RecordingConfig) error
 	Stop() error
 	Record(ctx *fasthttp.RequestCtx, responseBody []byte, duration time.Duration) error
+	AcquireCapture() (release func(), ok bool)
 	IsEnabled() bool
 	GetStats() *RecordingStats
 	GetStorage() Storage
@@ -24,15 +29,36 @@ type RecordingEngine interface {
 
 // DefaultRecordingEngine implements the RecordingEngine interface
 type DefaultRecordingEngine struct {
-	storage Storage
-	config  *config.RecordingConfig
-	filters []Filter
-	enabled bool
-	logger  *zap.Logger
-	stats   *RecordingStats
-	mu      sync.RWMutex
+	storage  Storage
+	config   *config.RecordingConfig
+	filters  []Filter
+	redactor *Redactor
+	enabled  bool
+	logger   *zap.Logger
+	stats    *RecordingStats
+	mu       sync.RWMutex
+
+	// captureSlots bounds concurrent captures; nil means unlimited
+	captureSlots chan struct{}
+
+	// dedup maps content hashes to the ID of the recording that holds them;
+	// nil when deduplication is disabled
+	dedup   map[string]string
+	dedupMu sync.Mutex
+
+	// sweepStop ends the age retention sweep; nil when none is running
+	sweepStop chan struct{}
 }
 
+// maxRetentionSweepInterval bounds how long an expired recording can outlive
+// MaxAge before the sweep deletes it
+const maxRetentionSweepInterval = time.Minute
+
+// dedupHeaders are the request headers that take part in the content hash,
+// since they change the response; other headers such as request IDs vary
+// between otherwise identical requests
+var dedupHeaders = []string{"Accept", "Content-Type", "Prefer"}
+
 // NewDefaultRecordingEngine creates a new recording engine instance
 func NewDefaultRecordingEngine(storage Storage, logger *zap.Logger) *DefaultRecordingEngine {
 	return &DefaultRecordingEngine{
@@ -62,17 +88,47 @@ func (r *DefaultRecordingEngine) Start(config *config.RecordingConfig) error {
 		return fmt.Errorf("failed to create filters: %w", err)
 	}
 	r.filters = filters
+	r.redactor = NewRedactor(config.Redact)
+
+	// Bound concurrent captures
+	r.captureSlots = nil
+	if config.MaxConcurrentCaptures > 0 {
+		r.captureSlots = make(chan struct{}, config.MaxConcurrentCaptures)
+	}
+
+	// Forget hashes from a previous run
+	r.dedup = nil
+	if config.Dedup {
+		r.dedup = make(map[string]string)
+	}
 
 	// Reset stats
 	r.stats = &RecordingStats{
 		StartTime: time.Now(),
 	}
 
+	// Storage enforces the count and size limits as it saves
+	r.stopRetentionSweep()
+	r.storage.SetRetention(RetentionPolicy{
+		MaxRecordings: config.MaxRecordings,
+		MaxTotalSize:  config.MaxTotalSize,
+	})
+
 	if r.enabled {
 		r.logger.Info("Recording engine started",
 			zap.Int("filters", len(r.filters)),
 			zap.Int("max_recordings", config.MaxRecordings),
-			zap.Int64("max_body_size", config.MaxBodySize))
+			zap.Int("max_concurrent_captures", config.MaxConcurrentCaptures),
+			zap.Int64("max_body_size", config.MaxBodySize),
+			zap.Duration("max_age", config.MaxAge),
+			zap.Int64("max_total_size", config.MaxTotalSize))
+
+		// Drop what a previous session left beyond the limits, then keep
+		// deleting recordings as they expire
+		r.enforceRetention(r.retentionPolicy())
+		if config.MaxAge > 0 {
+			r.startRetentionSweep(config.MaxAge)
+		}
 	}
 
 	return nil
@@ -84,6 +140,7 @@ func (r *DefaultRecordingEngine) Stop() error {
 	defer r.mu.Unlock()
 
 	r.enabled = false
+	r.stopRetentionSweep()
 	r.logger.Info("Recording engine stopped",
 		zap.Int64("total_requests", r.stats.TotalRequests),
 		zap.Int64("recorded_requests", r.stats.RecordedRequests),
@@ -132,11 +189,27 @@ func (r *DefaultRecordingEngine) Record(ctx *fasthttp.RequestCtx, responseBody [
 		}
 	}
 
+	// Hash the exchange as received, before redaction
+	if r.dedup != nil {
+		recording.Metadata.ContentHash = contentHash(recording)
+		recording.Metadata.Count = 1
+	}
+
+	// Never persist credentials
+	if r.redactor != nil {
+		r.redactor.Redact(recording)
+	}
+
 	// Save recording
-	if err := r.storage.Save(recording); err != nil {
+	duplicate, err := r.save(recording)
+	if err != nil {
 		r.stats.Errors++
 		return fmt.Errorf("failed to save recording: %w", err)
 	}
+	if duplicate {
+		atomic.AddInt64(&r.stats.DuplicateRequests, 1)
+		return nil
+	}
 
 	// Update stats
 	r.stats.RecordedRequests++
@@ -152,6 +225,131 @@ func (r *DefaultRecordingEngine) Record(ctx *fasthttp.RequestCtx, responseBody [
 	return nil
 }
 
+// save stores recording. With deduplication enabled, a recording whose
+// content hash matches a stored one increments that recording's count
+// instead, and save reports it as a duplicate.
+func (r *DefaultRecordingEngine) save(recording *Recording) (bool, error) {
+	if r.dedup == nil {
+		return false, r.storage.Save(recording)
+	}
+
+	r.dedupMu.Lock()
+	defer r.dedupMu.Unlock()
+
+	hash := recording.Metadata.ContentHash
+	if id, ok := r.dedup[hash]; ok {
+		existing, err := r.storage.Load(id)
+		if err == nil {
+			existing.Metadata.Count = max(existing.Metadata.Count, 1) + 1
+			return true, r.storage.Save(existing)
+		}
+		// The original was deleted; store this one in its place
+		delete(r.dedup, hash)
+	}
+
+	if err := r.storage.Save(recording); err != nil {
+		return false, err
+	}
+	r.dedup[hash] = recording.ID
+	return false, nil
+}
+
+// retentionPolicy returns the retention limits from the configuration
+func (r *DefaultRecordingEngine) retentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		MaxRecordings: r.config.MaxRecordings,
+		MaxAge:        r.config.MaxAge,
+		MaxTotalSize:  r.config.MaxTotalSize,
+	}
+}
+
+// enforceRetention deletes the stored recordings beyond policy, oldest
+// first. Failures are logged since recording goes on regardless.
+func (r *DefaultRecordingEngine) enforceRetention(policy RetentionPolicy) {
+	if policy == (RetentionPolicy{}) {
+		return
+	}
+	if _, err := r.storage.Prune(policy); err != nil {
+		r.logger.Warn("Failed to enforce recording retention", zap.Error(err))
+	}
+}
+
+// startRetentionSweep deletes recordings older than maxAge on a ticker.
+// Callers must hold r.mu.
+func (r *DefaultRecordingEngine) startRetentionSweep(maxAge time.Duration) {
+	interval := min(max(maxAge/2, time.Millisecond), maxRetentionSweepInterval)
+	stop := make(chan struct{})
+	r.sweepStop = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				r.enforceRetention(RetentionPolicy{MaxAge: maxAge})
+			}
+		}
+	}()
+}
+
+// stopRetentionSweep stops the running sweep, if any. Callers must hold r.mu.
+func (r *DefaultRecordingEngine) stopRetentionSweep() {
+	if r.sweepStop != nil {
+		close(r.sweepStop)
+		r.sweepStop = nil
+	}
+}
+
+// contentHash identifies an exchange by its method, URI with sorted query,
+// the headers in dedupHeaders, request body, response status and body
+func contentHash(recording *Recording) string {
+	path, _, _ := strings.Cut(recording.Request.URI, "?")
+	query := make([]string, 0, len(recording.Request.QueryParams))
+	for key, value := range recording.Request.QueryParams {
+		query = append(query, key+"="+value)
+	}
+	sort.Strings(query)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s?%s\n", recording.Request.Method, path, strings.Join(query, "&"))
+	for _, name := range dedupHeaders {
+		for key, value := range recording.Request.Headers {
+			if strings.EqualFold(key, name) {
+				fmt.Fprintf(hash, "%s:%s\n", name, value)
+			}
+		}
+	}
+	fmt.Fprintf(hash, "%d:%x\n%d\n%d:%x", len(recording.Request.Body), recording.Request.Body,
+		recording.Response.StatusCode, len(recording.Response.Body), recording.Response.Body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// AcquireCapture reserves a capture slot before the response is buffered.
+// When MaxConcurrentCaptures captures are already in flight it returns false
+// and counts the capture as dropped, so request serving is never blocked.
+// The returned release func must be called once the capture has finished.
+func (r *DefaultRecordingEngine) AcquireCapture() (func(), bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	slots := r.captureSlots
+	if slots == nil {
+		return func() {}, true
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, true
+	default:
+		atomic.AddInt64(&r.stats.DroppedCaptures, 1)
+		return nil, false
+	}
+}
+
 // IsEnabled returns true if recording is enabled
 func (r *DefaultRecordingEngine) IsEnabled() bool {
 	r.mu.RLock()
@@ -166,12 +364,15 @@ func (r *DefaultRecordingEngine) GetStats() *RecordingStats {
 
 	// Create a copy to avoid race conditions
 	return &RecordingStats{
-		TotalRequests:    r.stats.TotalRequests,
-		RecordedRequests: r.stats.RecordedRequests,
-		FilteredRequests: r.stats.FilteredRequests,
-		Errors:           r.stats.Errors,
-		StartTime:        r.stats.StartTime,
-		LastRecording:    r.stats.LastRecording,
+		TotalRequests:     r.stats.TotalRequests,
+		RecordedRequests:  r.stats.RecordedRequests,
+		FilteredRequests:  r.stats.FilteredRequests,
+		Errors:            r.stats.Errors,
+		DroppedCaptures:   atomic.LoadInt64(&r.stats.DroppedCaptures),
+		DuplicateRequests: atomic.LoadInt64(&r.stats.DuplicateRequests),
+		PrunedRecordings:  r.storage.GetStats().PrunedRecordings,
+		StartTime:         r.stats.StartTime,
+		LastRecording:     r.stats.LastRecording,
 	}
 }
 
@@ -187,16 +388,22 @@ func (r *DefaultRecordingEngine) createRecording(ctx *fasthttp.RequestCtx, respo
 	// Generate unique ID
 	recordingID := uuid.New().String()
 
+	// Streaming paths only record metadata; never pull the body into memory
+	bodyOmitted, _ := ctx.UserValue(config.BodyCaptureDisabledKey).(bool)
+
 	// Extract request information
 	request := RecordedRequest{
 		Method:      string(ctx.Method()),
 		URI:         string(ctx.RequestURI()),
 		Headers:     r.extractHeaders(&ctx.Request.Header, r.config.IncludeHeaders, r.config.ExcludeHeaders),
-		Body:        ctx.Request.Body(),
 		QueryParams: r.extractQueryParams(ctx),
 		ContentType: string(ctx.Request.Header.ContentType()),
 	}
 
+	if !bodyOmitted {
+		request.Body = ctx.Request.Body()
+	}
+
 	// Extract response information
 	response := RecordedResponse{
 		StatusCode:  ctx.Response.StatusCode(),
@@ -205,11 +412,26 @@ func (r *DefaultRecordingEngine) createRecording(ctx *fasthttp.RequestCtx, respo
 		ContentType: string(ctx.Response.Header.ContentType()),
 	}
 
+	if bodyOmitted {
+		response.Body = nil
+	}
+
 	// Extract metadata
 	metadata := RecordingMetadata{
-		Source:    "live",
-		ClientIP:  ctx.RemoteIP().String(),
-		UserAgent: string(ctx.Request.Header.UserAgent()),
+		Source:      "live",
+		ClientIP:    ctx.RemoteIP().String(),
+		UserAgent:   string(ctx.Request.Header.UserAgent()),
+		BodyOmitted: bodyOmitted,
+	}
+
+	// Prefer the address resolved against the server's trusted proxies
+	if clientIP, ok := ctx.UserValue(config.ClientIPKey).(string); ok {
+		metadata.ClientIP = clientIP
+	}
+
+	// Responses forwarded from the recording upstream are real traffic
+	if proxied, _ := ctx.UserValue(config.ProxiedKey).(bool); proxied {
+		metadata.Source = "proxy"
 	}
 
 	// Get request ID if available
@@ -219,6 +441,11 @@ func (r *DefaultRecordingEngine) createRecording(ctx *fasthttp.RequestCtx, respo
 		}
 	}
 
+	// Get request fingerprint if available
+	if fingerprint, ok := ctx.UserValue("request_fingerprint").(string); ok {
+		metadata.Fingerprint = fingerprint
+	}
+
 	// Check if chaos was applied
 	if chaosApplied := ctx.UserValue("chaos_applied"); chaosApplied != nil {
 		if applied, ok := chaosApplied.(bool); ok {
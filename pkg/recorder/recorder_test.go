@@ -1,6 +1,8 @@
 package recorder
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -99,6 +101,305 @@ func TestRecordingEngine_Record(t *testing.T) {
 	assert.Equal(t, int64(0), stats.Errors)
 }
 
+func TestRecordingEngine_Dedup(t *testing.T) {
+	storage := NewMemoryStorage()
+	engine := NewDefaultRecordingEngine(storage, zaptest.NewLogger(t))
+	require.NoError(t, engine.Start(&config.RecordingConfig{Enabled: true, Dedup: true}))
+
+	record := func(uri, requestID, responseBody string) {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(uri)
+		ctx.Request.Header.SetMethod("GET")
+		ctx.Request.Header.Set("X-Request-ID", requestID)
+		ctx.Response.SetStatusCode(200)
+		require.NoError(t, engine.Record(ctx, []byte(responseBody), time.Millisecond))
+	}
+
+	// Query order and unrelated headers don't make a request different
+	record("http://example.com/api/status?a=1&b=2", "req-1", `{"status":"ok"}`)
+	record("http://example.com/api/status?b=2&a=1", "req-2", `{"status":"ok"}`)
+
+	recordings, err := storage.List(ListFilter{})
+	require.NoError(t, err)
+	require.Len(t, recordings, 1)
+	assert.Equal(t, 2, recordings[0].Metadata.Count)
+	assert.NotEmpty(t, recordings[0].Metadata.ContentHash)
+
+	// A different response is a different exchange
+	record("http://example.com/api/status?a=1&b=2", "req-3", `{"status":"degraded"}`)
+
+	recordings, err = storage.List(ListFilter{})
+	require.NoError(t, err)
+	assert.Len(t, recordings, 2)
+
+	stats := engine.GetStats()
+	assert.Equal(t, int64(2), stats.RecordedRequests)
+	assert.Equal(t, int64(1), stats.DuplicateRequests)
+}
+
+func TestRecordingEngine_DedupDisabled(t *testing.T) {
+	storage := NewMemoryStorage()
+	engine := NewDefaultRecordingEngine(storage, zaptest.NewLogger(t))
+	require.NoError(t, engine.Start(&config.RecordingConfig{Enabled: true}))
+
+	for i := 0; i < 2; i++ {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("http://example.com/api/status")
+		ctx.Response.SetStatusCode(200)
+		require.NoError(t, engine.Record(ctx, []byte(`{"status":"ok"}`), time.Millisecond))
+	}
+
+	recordings, err := storage.List(ListFilter{})
+	require.NoError(t, err)
+	require.Len(t, recordings, 2)
+	assert.Zero(t, recordings[0].Metadata.Count)
+}
+
+func TestRecordingEngine_RecordStreamingPathOmitsBody(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	storage := NewMemoryStorage()
+	engine := NewDefaultRecordingEngine(storage, logger)
+
+	require.NoError(t, engine.Start(&config.RecordingConfig{Enabled: true}))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/uploads/video")
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.SetBody([]byte("large upload"))
+	ctx.Response.SetStatusCode(201)
+	ctx.SetUserValue(config.BodyCaptureDisabledKey, true)
+
+	require.NoError(t, engine.Record(ctx, []byte(`{"id": 1}`), 10*time.Millisecond))
+
+	recordings, err := storage.List(ListFilter{})
+	require.NoError(t, err)
+	require.Len(t, recordings, 1)
+
+	recording := recordings[0]
+	assert.True(t, recording.Metadata.BodyOmitted)
+	assert.Empty(t, recording.Request.Body)
+	assert.Empty(t, recording.Response.Body)
+	assert.Equal(t, "POST", recording.Request.Method)
+	assert.Equal(t, 201, recording.Response.StatusCode)
+}
+
+func TestRecordingEngine_RedactsSensitiveData(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	dir := t.TempDir()
+	storage, err := NewFileStorage(&config.StorageConfig{Type: "file", Directory: dir, Format: "json"}, logger)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	engine := NewDefaultRecordingEngine(storage, logger)
+	require.NoError(t, engine.Start(&config.RecordingConfig{
+		Enabled: true,
+		Redact: config.RedactConfig{
+			Headers: []string{"X-Session"},
+			Fields:  []string{"ssn"},
+		},
+	}))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/login")
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.Set("Authorization", "Bearer super-secret-token")
+	ctx.Request.Header.Set("X-Session", "session-123")
+	ctx.Request.Header.Set("Accept", "application/json")
+	ctx.Request.SetBody([]byte(`{"username": "ada", "password": "hunter2", "profile": {"ssn": "123-45-6789"}}`))
+	ctx.Response.SetStatusCode(200)
+
+	require.NoError(t, engine.Record(ctx, []byte(`{"user": "ada", "access_token": "abc.def.ghi"}`), 10*time.Millisecond))
+
+	recordings, err := storage.List(ListFilter{})
+	require.NoError(t, err)
+	require.Len(t, recordings, 1)
+	recording := recordings[0]
+
+	assert.Equal(t, "[REDACTED]", recording.Request.Headers["Authorization"])
+	assert.Equal(t, "[REDACTED]", recording.Request.Headers["X-Session"])
+	assert.Equal(t, "application/json", recording.Request.Headers["Accept"])
+	assert.JSONEq(t, `{"username": "ada", "password": "[REDACTED]", "profile": {"ssn": "[REDACTED]"}}`, string(recording.Request.Body))
+	assert.JSONEq(t, `{"user": "ada", "access_token": "[REDACTED]"}`, string(recording.Response.Body))
+
+	// Nothing sensitive reaches the disk
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		for _, secret := range []string{"super-secret-token", "session-123", "hunter2", "123-45-6789", "abc.def.ghi"} {
+			assert.NotContains(t, string(data), secret, file)
+		}
+	}
+}
+
+func TestRedactor_LeavesNonJSONBodies(t *testing.T) {
+	redactor := NewRedactor(config.RedactConfig{})
+
+	recording := &Recording{
+		Request: RecordedRequest{Body: []byte("password=hunter2")},
+	}
+	redactor.Redact(recording)
+	assert.Equal(t, "password=hunter2", string(recording.Request.Body))
+}
+
+func TestRedactor_KeepsBodiesWithoutSensitiveFields(t *testing.T) {
+	redactor := NewRedactor(config.RedactConfig{})
+
+	// Bodies with nothing to redact are stored byte for byte
+	original := `{ "id": 9007199254740993, "items": [ {"qty": 1.50} ] }`
+	recording := &Recording{Request: RecordedRequest{Body: []byte(original)}}
+	redactor.Redact(recording)
+	assert.Equal(t, original, string(recording.Request.Body))
+
+	// Redacted bodies keep large integers exact
+	recording = &Recording{
+		Response: RecordedResponse{Body: []byte(`[{"id": 9007199254740993, "api_key": "k"}]`)},
+	}
+	redactor.Redact(recording)
+	assert.JSONEq(t, `[{"id": 9007199254740993, "api_key": "[REDACTED]"}]`, string(recording.Response.Body))
+	assert.Contains(t, string(recording.Response.Body), "9007199254740993")
+}
+
+func TestRecordingEngine_AcquireCaptureDropsWhenSaturated(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	engine := NewDefaultRecordingEngine(NewMemoryStorage(), logger)
+
+	require.NoError(t, engine.Start(&config.RecordingConfig{
+		Enabled:               true,
+		MaxConcurrentCaptures: 2,
+	}))
+
+	releaseFirst, ok := engine.AcquireCapture()
+	require.True(t, ok)
+	_, ok = engine.AcquireCapture()
+	require.True(t, ok)
+
+	// Saturated: further captures are dropped and counted
+	for i := 0; i < 3; i++ {
+		release, ok := engine.AcquireCapture()
+		assert.False(t, ok)
+		assert.Nil(t, release)
+	}
+	assert.Equal(t, int64(3), engine.GetStats().DroppedCaptures)
+
+	// Releasing a slot makes room for the next capture
+	releaseFirst()
+	_, ok = engine.AcquireCapture()
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), engine.GetStats().DroppedCaptures)
+}
+
+func TestRecordingEngine_AcquireCaptureUnlimited(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	engine := NewDefaultRecordingEngine(NewMemoryStorage(), logger)
+
+	require.NoError(t, engine.Start(&config.RecordingConfig{Enabled: true}))
+
+	for i := 0; i < 1000; i++ {
+		_, ok := engine.AcquireCapture()
+		require.True(t, ok)
+	}
+	assert.Equal(t, int64(0), engine.GetStats().DroppedCaptures)
+}
+
+func TestRecordingEngine_EnforcesRetention(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	storage := NewMemoryStorage()
+	engine := NewDefaultRecordingEngine(storage, logger)
+
+	// Left over from an earlier session and past MaxAge
+	require.NoError(t, storage.Save(&Recording{
+		ID:        "expired",
+		Timestamp: time.Now().Add(-8 * 24 * time.Hour),
+		Request:   RecordedRequest{Method: "GET", URI: "/old"},
+	}))
+
+	err := engine.Start(&config.RecordingConfig{
+		Enabled:       true,
+		MaxRecordings: 2,
+		MaxAge:        7 * 24 * time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Zero(t, storage.GetStats().TotalRecordings)
+
+	for _, path := range []string{"/first", "/second", "/third"} {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("http://example.com" + path)
+		ctx.Request.Header.SetMethod("GET")
+		ctx.Response.SetStatusCode(200)
+		require.NoError(t, engine.Record(ctx, []byte("ok"), time.Millisecond))
+		time.Sleep(time.Millisecond)
+	}
+
+	recordings, err := storage.List(ListFilter{})
+	require.NoError(t, err)
+	require.Len(t, recordings, 2)
+	assert.Equal(t, "http://example.com/third", recordings[0].Request.URI)
+	assert.Equal(t, "http://example.com/second", recordings[1].Request.URI)
+	assert.Equal(t, int64(2), engine.GetStats().PrunedRecordings)
+}
+
+func TestRecordingEngine_SweepsExpiredRecordings(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	storage := NewMemoryStorage()
+	engine := NewDefaultRecordingEngine(storage, logger)
+
+	require.NoError(t, engine.Start(&config.RecordingConfig{Enabled: true, MaxAge: 50 * time.Millisecond}))
+	defer engine.Stop()
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("http://example.com/expiring")
+	ctx.Request.Header.SetMethod("GET")
+	ctx.Response.SetStatusCode(200)
+	require.NoError(t, engine.Record(ctx, []byte("ok"), time.Millisecond))
+	require.Equal(t, int64(1), storage.GetStats().TotalRecordings)
+
+	// Nothing else is recorded; the ticker deletes it once it expires
+	assert.Eventually(t, func() bool { return storage.GetStats().TotalRecordings == 0 },
+		time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), engine.GetStats().PrunedRecordings)
+
+	// Stopping the engine stops the sweep
+	require.NoError(t, engine.Stop())
+	require.NoError(t, storage.Save(&Recording{ID: "kept", Timestamp: time.Now().Add(-time.Hour)}))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int64(1), storage.GetStats().TotalRecordings)
+}
+
+func TestRecordingEngine_EvictsOldestBeyondTotalSize(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	storage := NewMemoryStorage()
+	engine := NewDefaultRecordingEngine(storage, logger)
+
+	record := func(path string) {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("http://example.com" + path)
+		ctx.Request.Header.SetMethod("GET")
+		ctx.Response.SetStatusCode(200)
+		require.NoError(t, engine.Record(ctx, make([]byte, 4096), time.Millisecond))
+		time.Sleep(time.Millisecond)
+	}
+
+	// Measure one recording before limiting the total to two of them
+	require.NoError(t, engine.Start(&config.RecordingConfig{Enabled: true}))
+	record("/probe")
+	size := storage.GetStats().TotalSize
+	require.NoError(t, storage.DeleteAll())
+
+	require.NoError(t, engine.Start(&config.RecordingConfig{Enabled: true, MaxTotalSize: size*2 + size/2}))
+	for _, path := range []string{"/a", "/b", "/c", "/d"} {
+		record(path)
+	}
+
+	recordings, err := storage.List(ListFilter{})
+	require.NoError(t, err)
+	require.Len(t, recordings, 2)
+	assert.Equal(t, "http://example.com/d", recordings[0].Request.URI)
+	assert.Equal(t, "http://example.com/c", recordings[1].Request.URI)
+	assert.LessOrEqual(t, storage.GetStats().TotalSize, size*2+size/2)
+}
+
 func TestRecordingEngine_RecordWithFilters(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	storage := NewMemoryStorage()
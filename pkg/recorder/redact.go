@@ -0,0 +1,68 @@
+package recorder
+
+import (
+	"strings"
+
+	"vanta/pkg/config"
+	"vanta/pkg/plugins"
+)
+
+// redactedValue replaces sensitive header values and JSON fields
+const redactedValue = "[REDACTED]"
+
+// Redactor strips sensitive headers and JSON body fields from recordings
+// before they are stored, mirroring the logging plugin's filtering
+type Redactor struct {
+	headers map[string]bool
+	fields  map[string]bool
+}
+
+// NewRedactor creates a redactor for the default sensitive names plus the
+// configured ones
+func NewRedactor(cfg config.RedactConfig) *Redactor {
+	r := &Redactor{
+		headers: make(map[string]bool),
+		fields:  make(map[string]bool),
+	}
+
+	for _, header := range append(append([]string{}, config.DefaultSensitiveHeaders...), cfg.Headers...) {
+		r.headers[strings.ToLower(header)] = true
+	}
+
+	for _, field := range append(append([]string{}, config.DefaultSensitiveFields...), cfg.Fields...) {
+		if field != "" {
+			r.fields[strings.ToLower(field)] = true
+		}
+	}
+
+	return r
+}
+
+// Redact replaces sensitive request and response data in place
+func (r *Redactor) Redact(recording *Recording) {
+	r.redactHeaders(recording.Request.Headers)
+	r.redactHeaders(recording.Response.Headers)
+	recording.Request.Body = r.redactBody(recording.Request.Body)
+	recording.Response.Body = r.redactBody(recording.Response.Body)
+}
+
+func (r *Redactor) redactHeaders(headers map[string]string) {
+	for name := range headers {
+		if r.headers[strings.ToLower(name)] {
+			headers[name] = redactedValue
+		}
+	}
+}
+
+// redactBody redacts sensitive fields in a JSON body the way the logging
+// plugin does. Bodies that are not JSON, or have nothing to redact, are
+// returned unchanged.
+func (r *Redactor) redactBody(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	if redacted := plugins.FilterSensitiveJSON(body, r.fields); redacted != nil {
+		return redacted
+	}
+	return body
+}
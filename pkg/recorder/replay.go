@@ -3,6 +3,7 @@ package recorder
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +20,7 @@ type Replayer struct {
 	logger     *zap.Logger
 	config     *ReplayConfig
 	stats      *ReplayStats
+	variables  map[string]string // Values extracted from live responses
 	mu         sync.RWMutex
 }
 
@@ -36,6 +38,7 @@ func NewReplayer(storage Storage, logger *zap.Logger) *Replayer {
 		stats: &ReplayStats{
 			StartTime: time.Now(),
 		},
+		variables: make(map[string]string),
 	}
 }
 
@@ -78,21 +81,33 @@ func (r *Replayer) LoadRecordingsByIDs(ids []string) error {
 
 // ReplayTraffic replays the loaded recordings against a target URL
 func (r *Replayer) ReplayTraffic(config *ReplayConfig) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	if config == nil {
 		return fmt.Errorf("replay configuration cannot be nil")
 	}
 
+	// Only hold the lock while resetting state; the replay goroutines update
+	// stats and variables through the same mutex.
+	r.mu.Lock()
 	if len(r.recordings) == 0 {
+		r.mu.Unlock()
 		return fmt.Errorf("no recordings loaded for replay")
 	}
 
+	recordings := make([]*Recording, len(r.recordings))
+	copy(recordings, r.recordings)
+	if config.PreserveTiming {
+		sort.SliceStable(recordings, func(i, j int) bool {
+			return recordings[i].Timestamp.Before(recordings[j].Timestamp)
+		})
+	}
+
 	r.config = config
 	r.stats = &ReplayStats{
 		StartTime: time.Now(),
+		Results:   make([]ReplayResult, len(recordings)),
 	}
+	r.variables = make(map[string]string)
+	r.mu.Unlock()
 
 	// Configure client based on replay config
 	r.client.ReadTimeout = config.Timeout
@@ -106,7 +121,7 @@ func (r *Replayer) ReplayTraffic(config *ReplayConfig) error {
 
 	r.logger.Info("Starting traffic replay",
 		zap.String("target", config.TargetURL),
-		zap.Int("recordings", len(r.recordings)),
+		zap.Int("recordings", len(recordings)),
 		zap.Int("concurrency", config.Concurrency))
 
 	// Parse target URL
@@ -126,7 +141,17 @@ func (r *Replayer) ReplayTraffic(config *ReplayConfig) error {
 	}
 
 	// Replay recordings
-	for i, recording := range r.recordings {
+	replayStart := time.Now()
+	for i, recording := range recordings {
+		// Schedule against the replay start rather than the previous request
+		// so time spent sending does not accumulate into drift
+		if config.PreserveTiming {
+			offset := scaleReplayGap(recording.Timestamp.Sub(recordings[0].Timestamp), config.TimeScale)
+			if wait := time.Until(replayStart.Add(offset)); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+
 		sem <- struct{}{} // Acquire semaphore
 		wg.Add(1)
 
@@ -136,7 +161,9 @@ func (r *Replayer) ReplayTraffic(config *ReplayConfig) error {
 				wg.Done()
 			}()
 
-			if err := r.replayRecording(rec, targetURL); err != nil {
+			result, err := r.replayRecording(rec, targetURL)
+			r.setResult(idx, result)
+			if err != nil {
 				r.logger.Error("Failed to replay recording",
 					zap.String("id", rec.ID),
 					zap.Int("index", idx),
@@ -148,7 +175,7 @@ func (r *Replayer) ReplayTraffic(config *ReplayConfig) error {
 		}(i, recording)
 
 		// Add delay between requests if specified
-		if delay > 0 && i < len(r.recordings)-1 {
+		if !config.PreserveTiming && delay > 0 && i < len(recordings)-1 {
 			time.Sleep(delay)
 		}
 	}
@@ -156,20 +183,45 @@ func (r *Replayer) ReplayTraffic(config *ReplayConfig) error {
 	// Wait for all replays to complete
 	wg.Wait()
 
+	r.mu.Lock()
 	r.stats.EndTime = time.Now()
+	r.mu.Unlock()
+
+	stats := r.GetStats()
 	r.logger.Info("Traffic replay completed",
-		zap.Int64("total", r.stats.TotalRequests),
-		zap.Int64("success", r.stats.SuccessRequests),
-		zap.Int64("failed", r.stats.FailedRequests),
-		zap.Duration("duration", r.stats.EndTime.Sub(r.stats.StartTime)))
+		zap.Int64("total", stats.TotalRequests),
+		zap.Int64("success", stats.SuccessRequests),
+		zap.Int64("failed", stats.FailedRequests),
+		zap.Duration("duration", stats.EndTime.Sub(stats.StartTime)))
 
 	return nil
 }
 
-// replayRecording replays a single recording
-func (r *Replayer) replayRecording(recording *Recording, targetURL *url.URL) error {
+// scaleReplayGap scales a recorded gap between requests by the configured
+// time scale
+func scaleReplayGap(gap time.Duration, scale float64) time.Duration {
+	if gap <= 0 {
+		return 0
+	}
+	if scale <= 0 {
+		return gap
+	}
+	return time.Duration(float64(gap) * scale)
+}
+
+// replayRecording replays a single recording and compares the outcome with
+// what was recorded
+func (r *Replayer) replayRecording(recording *Recording, targetURL *url.URL) (ReplayResult, error) {
 	r.incrementTotalRequests()
 
+	result := ReplayResult{
+		ID:               recording.ID,
+		Method:           recording.Request.Method,
+		URI:              recording.Request.URI,
+		RecordedStatus:   recording.Response.StatusCode,
+		RecordedDuration: recording.Duration,
+	}
+
 	// Create request
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
@@ -177,28 +229,48 @@ func (r *Replayer) replayRecording(recording *Recording, targetURL *url.URL) err
 	resp := fasthttp.AcquireResponse()
 	defer fasthttp.ReleaseResponse(resp)
 
+	vars := r.GetVariables()
+
 	// Set method
 	req.Header.SetMethod(recording.Request.Method)
 
 	// Set URI
-	uri := r.buildReplayURI(recording.Request.URI, targetURL)
+	uri := r.buildReplayURI(r.substitute(recording.Request.URI, SubstituteInPath, vars), targetURL)
 	req.SetRequestURI(uri)
 
 	// Set headers
 	for key, value := range recording.Request.Headers {
 		if r.shouldIncludeHeader(key) {
-			req.Header.Set(key, value)
+			req.Header.Set(key, expandVariables(value, vars))
 		}
 	}
 
 	// Apply header overrides
 	for key, value := range r.config.OverrideHeaders {
-		req.Header.Set(key, value)
+		req.Header.Set(key, expandVariables(value, vars))
+	}
+
+	// Inject variables into configured headers
+	for name, targets := range r.config.Substitutions {
+		value, ok := vars[name]
+		if !ok {
+			continue
+		}
+		for _, target := range targets {
+			if target.In != SubstituteInHeader || target.Name == "" {
+				continue
+			}
+			if target.Template == "" {
+				req.Header.Set(target.Name, value)
+			} else {
+				req.Header.Set(target.Name, expandVariables(target.Template, vars))
+			}
+		}
 	}
 
 	// Set body
 	if len(recording.Request.Body) > 0 {
-		req.SetBody(recording.Request.Body)
+		req.SetBody([]byte(r.substitute(string(recording.Request.Body), SubstituteInBody, vars)))
 	}
 
 	// Record start time for latency calculation
@@ -210,9 +282,25 @@ func (r *Replayer) replayRecording(recording *Recording, targetURL *url.URL) err
 
 	// Update average latency
 	r.updateAverageLatency(latency)
+	result.ReplayedDuration = latency
 
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %w", err)
+		err = fmt.Errorf("HTTP request failed: %w", err)
+		result.Error = err.Error()
+		return result, err
+	}
+	result.ReplayedStatus = resp.StatusCode()
+	result.StatusMatch = result.ReplayedStatus == result.RecordedStatus
+
+	// Capture dynamic values for subsequent requests
+	if len(r.config.Extractors) > 0 {
+		extracted, err := extractVariables(resp.Body(), r.config.Extractors)
+		if err != nil {
+			r.logger.Warn("Failed to extract replay variables",
+				zap.String("id", recording.ID),
+				zap.Error(err))
+		}
+		r.setVariables(extracted)
 	}
 
 	r.logger.Debug("Request replayed",
@@ -223,7 +311,25 @@ func (r *Replayer) replayRecording(recording *Recording, targetURL *url.URL) err
 		zap.Int("replay_status", resp.StatusCode()),
 		zap.Duration("latency", latency))
 
-	return nil
+	return result, nil
+}
+
+// substitute expands ${var} tokens and applies the configured literal
+// substitutions for the given request location
+func (r *Replayer) substitute(input, location string, vars map[string]string) string {
+	for name, targets := range r.config.Substitutions {
+		value, ok := vars[name]
+		if !ok {
+			continue
+		}
+		for _, target := range targets {
+			if target.In == location && target.Match != "" {
+				input = strings.ReplaceAll(input, target.Match, value)
+			}
+		}
+	}
+
+	return expandVariables(input, vars)
 }
 
 // buildReplayURI constructs the target URI for replay
@@ -290,6 +396,32 @@ func (r *Replayer) GetStats() *ReplayStats {
 		AverageLatency:  r.stats.AverageLatency,
 		StartTime:       r.stats.StartTime,
 		EndTime:         r.stats.EndTime,
+		Results:         append([]ReplayResult(nil), r.stats.Results...),
+	}
+}
+
+// GetVariables returns a copy of the variables extracted during replay
+func (r *Replayer) GetVariables() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	vars := make(map[string]string, len(r.variables))
+	for name, value := range r.variables {
+		vars[name] = value
+	}
+	return vars
+}
+
+// setVariables merges extracted values into the replay variables
+func (r *Replayer) setVariables(vars map[string]string) {
+	if len(vars) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, value := range vars {
+		r.variables[name] = value
 	}
 }
 
@@ -325,6 +457,15 @@ func (r *Replayer) incrementFailedRequests() {
 	r.stats.FailedRequests++
 }
 
+// setResult stores the outcome of the recording replayed at index idx
+func (r *Replayer) setResult(idx int, result ReplayResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if idx < len(r.stats.Results) {
+		r.stats.Results[idx] = result
+	}
+}
+
 // updateAverageLatency updates the average latency calculation
 func (r *Replayer) updateAverageLatency(latency time.Duration) {
 	r.mu.Lock()
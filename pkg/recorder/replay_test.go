@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
@@ -200,6 +201,257 @@ func TestReplayer_ReplayTraffic(t *testing.T) {
 	assert.True(t, stats.AverageLatency > 0)
 }
 
+func TestReplayer_ReplayTrafficReportsPerRecordingResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	storage := NewMemoryStorage()
+	base := time.Now()
+	for i, rec := range []struct {
+		id, uri  string
+		duration time.Duration
+	}{
+		{"fast", "/fast", 5 * time.Millisecond},
+		{"slow", "/slow", 50 * time.Millisecond},
+		{"gone", "/gone", 2 * time.Millisecond},
+	} {
+		require.NoError(t, storage.Save(&Recording{
+			ID:        rec.id,
+			Timestamp: base.Add(time.Duration(i) * time.Millisecond),
+			Duration:  rec.duration,
+			Request:   RecordedRequest{Method: "GET", URI: rec.uri},
+			Response:  RecordedResponse{StatusCode: http.StatusOK},
+		}))
+	}
+
+	replayer := NewReplayer(storage, zaptest.NewLogger(t))
+	require.NoError(t, replayer.LoadRecordings(ListFilter{}))
+	require.NoError(t, replayer.ReplayTraffic(&ReplayConfig{
+		TargetURL:      server.URL,
+		Concurrency:    3,
+		Timeout:        5 * time.Second,
+		PreserveTiming: true,
+		ReplaceHost:    true,
+	}))
+
+	results := replayer.GetStats().Results
+	require.Len(t, results, 3)
+
+	byID := map[string]ReplayResult{}
+	for _, result := range results {
+		byID[result.ID] = result
+		assert.Positive(t, result.ReplayedDuration, result.ID)
+		assert.Empty(t, result.Error, result.ID)
+	}
+	assert.Equal(t, 50*time.Millisecond, byID["slow"].RecordedDuration)
+	assert.GreaterOrEqual(t, byID["slow"].ReplayedDuration, 20*time.Millisecond)
+	assert.True(t, byID["fast"].StatusMatch)
+	assert.Equal(t, http.StatusNotFound, byID["gone"].ReplayedStatus)
+	assert.False(t, byID["gone"].StatusMatch)
+}
+
+func TestReplayer_ReplayTrafficChainsVariables(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Method+" "+r.URL.Path+" "+r.Header.Get("X-Item-Token"))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/items":
+			w.WriteHeader(201)
+			w.Write([]byte(`{"data": {"id": "item-42", "tokens": ["tok-1"]}}`))
+		case r.Method == "GET" && r.URL.Path == "/items/item-42":
+			w.WriteHeader(200)
+			w.Write([]byte(`{"id": "item-42"}`))
+		case r.Method == "DELETE" && r.URL.Path == "/items/item-42":
+			w.WriteHeader(204)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	logger := zaptest.NewLogger(t)
+	storage := NewMemoryStorage()
+
+	recordings := []*Recording{
+		{
+			ID:        "create",
+			Timestamp: time.Now(),
+			Request: RecordedRequest{
+				Method: "POST",
+				URI:    "http://original.com/items",
+				Body:   []byte(`{"name": "widget"}`),
+			},
+		},
+		{
+			ID:        "fetch",
+			Timestamp: time.Now(),
+			Request: RecordedRequest{
+				Method: "GET",
+				URI:    "http://original.com/items/${item_id}",
+			},
+		},
+		{
+			ID:        "delete",
+			Timestamp: time.Now(),
+			Request: RecordedRequest{
+				Method: "DELETE",
+				URI:    "http://original.com/items/stale-id",
+			},
+		},
+	}
+
+	for _, recording := range recordings {
+		require.NoError(t, storage.Save(recording))
+	}
+
+	replayer := NewReplayer(storage, logger)
+	require.NoError(t, replayer.LoadRecordingsByIDs([]string{"create", "fetch", "delete"}))
+
+	config := &ReplayConfig{
+		TargetURL:    server.URL,
+		Concurrency:  1,
+		DelayBetween: time.Millisecond,
+		Timeout:      5 * time.Second,
+		ReplaceHost:  true,
+		Extractors: map[string]string{
+			"$.data.id":        "item_id",
+			"$.data.tokens[0]": "token",
+		},
+		Substitutions: map[string][]ReplaySubstitution{
+			"item_id": {{In: SubstituteInPath, Match: "stale-id"}},
+			"token":   {{In: SubstituteInHeader, Name: "X-Item-Token", Template: "Bearer ${token}"}},
+		},
+	}
+
+	require.NoError(t, replayer.ReplayTraffic(config))
+
+	assert.Equal(t, []string{
+		"POST /items ",
+		"GET /items/item-42 Bearer tok-1",
+		"DELETE /items/item-42 Bearer tok-1",
+	}, seen)
+	assert.Equal(t, "item-42", replayer.GetVariables()["item_id"])
+
+	stats := replayer.GetStats()
+	assert.Equal(t, int64(3), stats.TotalRequests)
+	assert.Equal(t, int64(3), stats.SuccessRequests)
+}
+
+func TestReplayer_ReplayTrafficPreservesTiming(t *testing.T) {
+	var mu sync.Mutex
+	arrivals := make(map[string]time.Time)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals[r.URL.Path] = time.Now()
+		mu.Unlock()
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	logger := zaptest.NewLogger(t)
+	storage := NewMemoryStorage()
+
+	// Saved out of order and spaced unevenly: 60ms then 240ms
+	base := time.Now().Add(-time.Hour)
+	offsets := map[string]time.Duration{"/third": 300 * time.Millisecond, "/first": 0, "/second": 60 * time.Millisecond}
+	for _, path := range []string{"/third", "/first", "/second"} {
+		require.NoError(t, storage.Save(&Recording{
+			ID:        path,
+			Timestamp: base.Add(offsets[path]),
+			Request: RecordedRequest{
+				Method: "GET",
+				URI:    "http://original.com" + path,
+			},
+		}))
+	}
+
+	replay := func(scale float64) (time.Duration, time.Duration) {
+		mu.Lock()
+		arrivals = make(map[string]time.Time)
+		mu.Unlock()
+
+		replayer := NewReplayer(storage, logger)
+		require.NoError(t, replayer.LoadRecordings(ListFilter{}))
+		require.NoError(t, replayer.ReplayTraffic(&ReplayConfig{
+			TargetURL:      server.URL,
+			Concurrency:    1,
+			DelayBetween:   time.Second, // ignored when preserving timing
+			Timeout:        5 * time.Second,
+			ReplaceHost:    true,
+			PreserveTiming: true,
+			TimeScale:      scale,
+		}))
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, arrivals, 3)
+		return arrivals["/second"].Sub(arrivals["/first"]), arrivals["/third"].Sub(arrivals["/second"])
+	}
+
+	const tolerance = 40 * time.Millisecond
+
+	first, second := replay(0)
+	assert.InDelta(t, float64(60*time.Millisecond), float64(first), float64(tolerance))
+	assert.InDelta(t, float64(240*time.Millisecond), float64(second), float64(tolerance))
+
+	first, second = replay(0.5)
+	assert.InDelta(t, float64(30*time.Millisecond), float64(first), float64(tolerance))
+	assert.InDelta(t, float64(120*time.Millisecond), float64(second), float64(tolerance))
+}
+
+func TestEvaluateJSONPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"id": "abc",
+		"data": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"id": float64(7)},
+			},
+			"odd.key": true,
+		},
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+		wantErr  bool
+	}{
+		{path: "$.id", expected: "abc"},
+		{path: "$.data.items[0].id", expected: "7"},
+		{path: "$.data['odd.key']", expected: "true"},
+		{path: "$.data.items[1]", wantErr: true},
+		{path: "$.missing", wantErr: true},
+		{path: "id", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, err := evaluateJSONPath(doc, tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, stringifyJSONValue(value))
+		})
+	}
+}
+
 func TestReplayConfig_HeaderHandling(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	storage := NewMemoryStorage()
@@ -0,0 +1,107 @@
+package recorder
+
+import (
+	"sort"
+	"time"
+)
+
+// retentionEntry is what a retention policy looks at in a stored recording
+type retentionEntry struct {
+	id        string
+	timestamp time.Time
+	size      int64
+}
+
+// before orders entries oldest first, by ID for equal timestamps
+func (e retentionEntry) before(other retentionEntry) bool {
+	if !e.timestamp.Equal(other.timestamp) {
+		return e.timestamp.Before(other.timestamp)
+	}
+	return e.id < other.id
+}
+
+// retentionIndex keeps the stored recordings oldest first along with their
+// total size, so retention evicts from the front without scanning or sorting
+// the whole store. It is not safe for concurrent use; storages guard it with
+// their own lock.
+type retentionIndex struct {
+	order   []retentionEntry // Oldest first
+	entries map[string]retentionEntry
+	total   int64
+}
+
+func newRetentionIndex() *retentionIndex {
+	return &retentionIndex{entries: make(map[string]retentionEntry)}
+}
+
+// put adds or updates a recording. Recordings normally arrive newest last,
+// so this appends; older ones are inserted in place.
+func (ri *retentionIndex) put(id string, timestamp time.Time, size int64) {
+	ri.remove(id)
+
+	entry := retentionEntry{id: id, timestamp: timestamp, size: size}
+	ri.entries[id] = entry
+	ri.total += size
+
+	if n := len(ri.order); n == 0 || ri.order[n-1].before(entry) {
+		ri.order = append(ri.order, entry)
+		return
+	}
+	i := sort.Search(len(ri.order), func(i int) bool { return entry.before(ri.order[i]) })
+	ri.order = append(ri.order, retentionEntry{})
+	copy(ri.order[i+1:], ri.order[i:])
+	ri.order[i] = entry
+}
+
+// remove forgets a recording; unknown IDs are ignored
+func (ri *retentionIndex) remove(id string) {
+	entry, ok := ri.entries[id]
+	if !ok {
+		return
+	}
+	delete(ri.entries, id)
+	ri.total -= entry.size
+
+	i := sort.Search(len(ri.order), func(i int) bool { return !ri.order[i].before(entry) })
+	if i < len(ri.order) && ri.order[i].id == id {
+		ri.order = append(ri.order[:i], ri.order[i+1:]...)
+	}
+}
+
+// evict pops the recordings policy no longer allows and returns their IDs,
+// oldest first. Expired recordings go first; after that the oldest are
+// evicted until both the count and total size limits are met.
+func (ri *retentionIndex) evict(policy RetentionPolicy, now time.Time) []string {
+	var evicted []string
+	for len(ri.order) > 0 {
+		oldest := ri.order[0]
+		expired := policy.MaxAge > 0 && now.Sub(oldest.timestamp) > policy.MaxAge
+		tooMany := policy.MaxRecordings > 0 && len(ri.order) > policy.MaxRecordings
+		tooLarge := policy.MaxTotalSize > 0 && ri.total > policy.MaxTotalSize
+		if !expired && !tooMany && !tooLarge {
+			break
+		}
+
+		ri.order[0] = retentionEntry{}
+		ri.order = ri.order[1:]
+		delete(ri.entries, oldest.id)
+		ri.total -= oldest.size
+		evicted = append(evicted, oldest.id)
+	}
+	return evicted
+}
+
+// oldest and newest return the timestamps at either end of the index
+func (ri *retentionIndex) oldest() time.Time {
+	if len(ri.order) == 0 {
+		return time.Time{}
+	}
+	return ri.order[0].timestamp
+}
+
+func (ri *retentionIndex) newest() time.Time {
+	if len(ri.order) == 0 {
+		return time.Time{}
+	}
+	return ri.order[len(ri.order)-1].timestamp
+}
@@ -19,8 +19,11 @@ type Storage interface {
 	Save(recording *Recording) error
 	Load(id string) (*Recording, error)
 	List(filter ListFilter) ([]*Recording, error)
+	ListPage(filter ListFilter) (*RecordingPage, error)
 	Delete(id string) error
 	DeleteAll() error
+	SetRetention(policy RetentionPolicy)
+	Prune(policy RetentionPolicy) (int, error)
 	GetStats() StorageStats
 	Close() error
 }
@@ -34,6 +37,9 @@ type FileStorage struct {
 	logger      *zap.Logger
 	index       map[string]*RecordingIndex // In-memory index for performance
 	indexFile   string
+	retention   *retentionIndex  // Recordings oldest first, with their total size
+	policy      RetentionPolicy  // Count and size limits enforced on Save
+	pruned      int64            // Recordings deleted by the retention limits
 }
 
 // NewFileStorage creates a new file-based storage instance
@@ -59,12 +65,14 @@ func NewFileStorage(config *config.StorageConfig, logger *zap.Logger) (*FileStor
 		logger:    logger,
 		index:     make(map[string]*RecordingIndex),
 		indexFile: filepath.Join(config.Directory, "index.json"),
+		retention: newRetentionIndex(),
 	}
 
 	// Load existing index
 	if err := storage.loadIndex(); err != nil {
 		logger.Warn("Failed to load storage index", zap.Error(err))
 	}
+	storage.indexRetention()
 
 	return storage, nil
 }
@@ -91,6 +99,12 @@ func (fs *FileStorage) Save(recording *Recording) error {
 		return fmt.Errorf("failed to save recording to file: %w", err)
 	}
 
+	// Record the size on disk for retention by total size
+	var size int64
+	if info, err := os.Stat(filepath); err == nil {
+		size = info.Size()
+	}
+
 	// Update index
 	fs.index[recording.ID] = &RecordingIndex{
 		ID:        recording.ID,
@@ -98,17 +112,20 @@ func (fs *FileStorage) Save(recording *Recording) error {
 		Method:    recording.Request.Method,
 		URI:       recording.Request.URI,
 		Status:    recording.Response.StatusCode,
+		Count:     recording.Metadata.Count,
+		Size:      size,
 		Filename:  filename,
 	}
+	fs.retention.put(recording.ID, recording.Timestamp, size)
+
+	// Evict the oldest recordings beyond the count and size limits
+	fs.evict(fs.savePolicy(), time.Now())
 
 	// Save index
 	if err := fs.saveIndex(); err != nil {
 		fs.logger.Warn("Failed to save storage index", zap.Error(err))
 	}
 
-	// Clean up old files if necessary
-	fs.cleanupOldFiles()
-
 	return nil
 }
 
@@ -137,48 +154,175 @@ func (fs *FileStorage) List(filter ListFilter) ([]*Recording, error) {
 	fs.mu.RLock()
 	defer fs.mu.RUnlock()
 
-	var recordings []*Recording
-	var indices []*RecordingIndex
+	indices := fs.matchingIndices(filter)
 
-	// Filter indices
-	for _, idx := range fs.index {
-		if fs.matchesFilter(idx, filter) {
-			indices = append(indices, idx)
+	// Headers and bodies are not indexed, so content filters have to load
+	// each candidate before offset and limit can be applied
+	if hasContentFilter(filter) {
+		return fs.listByContent(indices, filter), nil
+	}
+
+	// Apply offset and limit
+	start, end := pageBounds(len(indices), filter)
+	return fs.loadIndices(indices[start:end]), nil
+}
+
+// ListPage returns the page of recordings selected by the filter's offset
+// and limit, together with the number of recordings matching it. With
+// content filters every candidate has to be loaded to count them.
+func (fs *FileStorage) ListPage(filter ListFilter) (*RecordingPage, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	indices := fs.matchingIndices(filter)
+	page := &RecordingPage{Recordings: []*Recording{}, Offset: filter.Offset}
+
+	if !hasContentFilter(filter) {
+		start, end := pageBounds(len(indices), filter)
+		page.Recordings = append(page.Recordings, fs.loadIndices(indices[start:end])...)
+		page.Total = len(indices)
+		return page, nil
+	}
+
+	for _, idx := range indices {
+		recording, err := fs.loadFromFile(filepath.Join(fs.directory, idx.Filename))
+		if err != nil {
+			fs.logger.Warn("Failed to load recording",
+				zap.String("id", idx.ID),
+				zap.Error(err))
+			continue
+		}
+		if !matchesContent(recording, filter) {
+			continue
+		}
+
+		page.Total++
+		if page.Total > filter.Offset && (filter.Limit <= 0 || len(page.Recordings) < filter.Limit) {
+			page.Recordings = append(page.Recordings, recording)
 		}
 	}
 
-	// Sort by timestamp (newest first)
-	sort.Slice(indices, func(i, j int) bool {
-		return indices[i].Timestamp.After(indices[j].Timestamp)
-	})
+	return page, nil
+}
 
-	// Apply offset and limit
-	start := filter.Offset
-	if start >= len(indices) {
-		return recordings, nil
+// loadIndices loads the indexed recordings in order, skipping unreadable
+// files. Callers must hold fs.mu.
+func (fs *FileStorage) loadIndices(indices []*RecordingIndex) []*Recording {
+	var recordings []*Recording
+	for _, idx := range indices {
+		recording, err := fs.loadFromFile(filepath.Join(fs.directory, idx.Filename))
+		if err != nil {
+			fs.logger.Warn("Failed to load recording",
+				zap.String("id", idx.ID),
+				zap.Error(err))
+			continue
+		}
+		recordings = append(recordings, recording)
 	}
+	return recordings
+}
 
-	end := len(indices)
+// pageBounds returns the slice bounds of the page the filter's offset and
+// limit select out of total ordered results
+func pageBounds(total int, filter ListFilter) (int, int) {
+	start := min(max(filter.Offset, 0), total)
+	end := total
 	if filter.Limit > 0 && start+filter.Limit < end {
 		end = start + filter.Limit
 	}
+	return start, end
+}
 
-	indices = indices[start:end]
+// Walk calls fn with each recording matching the filter, newest first,
+// loading one recording at a time so captures larger than memory can be
+// streamed. Walking stops at the first error fn returns.
+func (fs *FileStorage) Walk(filter ListFilter, fn func(*Recording) error) error {
+	// Recordings saved or deleted during the walk are not waited for
+	fs.mu.RLock()
+	indices := fs.matchingIndices(filter)
+	fs.mu.RUnlock()
 
-	// Load recordings
+	visited := 0
+	skipped := 0
 	for _, idx := range indices {
-		filepath := filepath.Join(fs.directory, idx.Filename)
-		recording, err := fs.loadFromFile(filepath)
+		if filter.Limit > 0 && visited >= filter.Limit {
+			break
+		}
+
+		recording, err := fs.loadFromFile(filepath.Join(fs.directory, idx.Filename))
 		if err != nil {
 			fs.logger.Warn("Failed to load recording",
 				zap.String("id", idx.ID),
 				zap.Error(err))
 			continue
 		}
+		if !matchesContent(recording, filter) {
+			continue
+		}
+
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		visited++
+		if err := fn(recording); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchingIndices returns the indexed recordings matching the filter's
+// indexed fields, newest first. Callers must hold fs.mu.
+func (fs *FileStorage) matchingIndices(filter ListFilter) []*RecordingIndex {
+	var indices []*RecordingIndex
+	for _, idx := range fs.index {
+		if fs.matchesFilter(idx, filter) {
+			indices = append(indices, idx)
+		}
+	}
+
+	// Sort by timestamp (newest first), then ID so pages are stable
+	sort.Slice(indices, func(i, j int) bool {
+		if !indices[i].Timestamp.Equal(indices[j].Timestamp) {
+			return indices[i].Timestamp.After(indices[j].Timestamp)
+		}
+		return indices[i].ID < indices[j].ID
+	})
+	return indices
+}
+
+// listByContent loads the candidate recordings in order and returns the
+// page of those matching the filter's header and body conditions
+func (fs *FileStorage) listByContent(indices []*RecordingIndex, filter ListFilter) []*Recording {
+	recordings := []*Recording{}
+	skipped := 0
+
+	for _, idx := range indices {
+		if filter.Limit > 0 && len(recordings) >= filter.Limit {
+			break
+		}
+
+		recording, err := fs.loadFromFile(filepath.Join(fs.directory, idx.Filename))
+		if err != nil {
+			fs.logger.Warn("Failed to load recording",
+				zap.String("id", idx.ID),
+				zap.Error(err))
+			continue
+		}
+		if !matchesContent(recording, filter) {
+			continue
+		}
+
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
 		recordings = append(recordings, recording)
 	}
 
-	return recordings, nil
+	return recordings
 }
 
 // Delete removes a recording by ID
@@ -204,6 +348,7 @@ func (fs *FileStorage) Delete(id string) error {
 
 	// Remove from index
 	delete(fs.index, id)
+	fs.retention.remove(id)
 
 	// Save index
 	if err := fs.saveIndex(); err != nil {
@@ -230,6 +375,7 @@ func (fs *FileStorage) DeleteAll() error {
 
 	// Clear index
 	fs.index = make(map[string]*RecordingIndex)
+	fs.retention = newRetentionIndex()
 
 	// Save empty index
 	if err := fs.saveIndex(); err != nil {
@@ -239,48 +385,89 @@ func (fs *FileStorage) DeleteAll() error {
 	return nil
 }
 
-// GetStats returns storage statistics
-func (fs *FileStorage) GetStats() StorageStats {
-	fs.mu.RLock()
-	defer fs.mu.RUnlock()
+// SetRetention sets the count and size limits Save enforces, evicting the
+// oldest recordings beyond them
+func (fs *FileStorage) SetRetention(policy RetentionPolicy) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.policy = policy
+}
+
+// Prune deletes the recordings the retention policy no longer allows,
+// oldest first, and returns how many were removed
+func (fs *FileStorage) Prune(policy RetentionPolicy) (int, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
 
-	stats := StorageStats{
-		TotalRecordings: int64(len(fs.index)),
+	removed := fs.evict(policy, time.Now())
+	if removed == 0 {
+		return 0, nil
 	}
 
-	if len(fs.index) == 0 {
-		return stats
+	if err := fs.saveIndex(); err != nil {
+		return removed, fmt.Errorf("failed to save storage index: %w", err)
 	}
+	return removed, nil
+}
 
-	// Calculate size and find oldest/newest
-	var oldest, newest time.Time
-	first := true
+// savePolicy is the retention Save enforces: the configured count and size
+// limits, with the count capped at maxFiles
+func (fs *FileStorage) savePolicy() RetentionPolicy {
+	policy := RetentionPolicy{MaxRecordings: fs.maxFiles, MaxTotalSize: fs.policy.MaxTotalSize}
+	if fs.policy.MaxRecordings > 0 && fs.policy.MaxRecordings < fs.maxFiles {
+		policy.MaxRecordings = fs.policy.MaxRecordings
+	}
+	return policy
+}
 
-	for _, index := range fs.index {
-		if first {
-			oldest = index.Timestamp
-			newest = index.Timestamp
-			first = false
-		} else {
-			if index.Timestamp.Before(oldest) {
-				oldest = index.Timestamp
-			}
-			if index.Timestamp.After(newest) {
-				newest = index.Timestamp
-			}
+// evict removes the recordings policy evicts from disk and the index.
+// Callers must hold fs.mu and save the index afterwards.
+func (fs *FileStorage) evict(policy RetentionPolicy, now time.Time) int {
+	evicted := fs.retention.evict(policy, now)
+	for _, id := range evicted {
+		filepath := filepath.Join(fs.directory, fs.index[id].Filename)
+		if err := os.Remove(filepath); err != nil && !os.IsNotExist(err) {
+			fs.logger.Warn("Failed to cleanup old recording",
+				zap.String("file", filepath),
+				zap.Error(err))
 		}
+		delete(fs.index, id)
+	}
 
-		// Get file size
-		filepath := filepath.Join(fs.directory, index.Filename)
-		if info, err := os.Stat(filepath); err == nil {
-			stats.TotalSize += info.Size()
+	if len(evicted) > 0 {
+		fs.pruned += int64(len(evicted))
+		fs.logger.Info("Cleaned up old recordings",
+			zap.Int("removed", len(evicted)),
+			zap.Int("remaining", len(fs.index)))
+	}
+	return len(evicted)
+}
+
+// indexRetention builds the retention index from a loaded index. Indexes
+// written before sizes were tracked are sized from the files.
+func (fs *FileStorage) indexRetention() {
+	for _, idx := range fs.index {
+		if idx.Size == 0 {
+			if info, err := os.Stat(filepath.Join(fs.directory, idx.Filename)); err == nil {
+				idx.Size = info.Size()
+			}
 		}
+		fs.retention.put(idx.ID, idx.Timestamp, idx.Size)
 	}
+}
 
-	stats.OldestRecording = oldest
-	stats.NewestRecording = newest
+// GetStats returns storage statistics
+func (fs *FileStorage) GetStats() StorageStats {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
 
-	return stats
+	return StorageStats{
+		TotalRecordings:  int64(len(fs.index)),
+		TotalSize:        fs.retention.total,
+		PrunedRecordings: fs.pruned,
+		OldestRecording:  fs.retention.oldest(),
+		NewestRecording:  fs.retention.newest(),
+	}
 }
 
 // Close closes the storage
@@ -414,46 +601,50 @@ func (fs *FileStorage) matchesFilter(index *RecordingIndex, filter ListFilter) b
 		}
 	}
 
+	// Duplicate count filter
+	if filter.MinCount > 0 && max(index.Count, 1) < filter.MinCount {
+		return false
+	}
+
 	return true
 }
 
-// cleanupOldFiles removes old recordings if we exceed the maximum file count
-func (fs *FileStorage) cleanupOldFiles() {
-	if len(fs.index) <= fs.maxFiles {
-		return
-	}
+// hasContentFilter reports whether the filter inspects headers or bodies
+func hasContentFilter(filter ListFilter) bool {
+	return len(filter.HeaderMatch) > 0 || filter.BodyContains != ""
+}
 
-	// Sort indices by timestamp (oldest first)
-	var indices []*RecordingIndex
-	for _, idx := range fs.index {
-		indices = append(indices, idx)
+// matchesContent checks a recording against the filter's header and body
+// conditions
+func matchesContent(recording *Recording, filter ListFilter) bool {
+	for name, want := range filter.HeaderMatch {
+		found := false
+		for key, value := range recording.Request.Headers {
+			if strings.EqualFold(key, name) && value == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
 	}
 
-	sort.Slice(indices, func(i, j int) bool {
-		return indices[i].Timestamp.Before(indices[j].Timestamp)
-	})
-
-	// Remove oldest files
-	toRemove := len(indices) - fs.maxFiles
-	for i := 0; i < toRemove; i++ {
-		idx := indices[i]
-		filepath := filepath.Join(fs.directory, idx.Filename)
-		if err := os.Remove(filepath); err != nil && !os.IsNotExist(err) {
-			fs.logger.Warn("Failed to cleanup old recording",
-				zap.String("file", filepath),
-				zap.Error(err))
-		}
-		delete(fs.index, idx.ID)
+	if filter.BodyContains != "" &&
+		!strings.Contains(string(recording.Request.Body), filter.BodyContains) &&
+		!strings.Contains(string(recording.Response.Body), filter.BodyContains) {
+		return false
 	}
 
-	fs.logger.Info("Cleaned up old recordings",
-		zap.Int("removed", toRemove),
-		zap.Int("remaining", len(fs.index)))
+	return true
 }
 
 // MemoryStorage implements in-memory storage for recordings (for testing)
 type MemoryStorage struct {
 	recordings map[string]*Recording
+	retention  *retentionIndex // Encoded sizes, as file storage would write them
+	policy     RetentionPolicy
+	pruned     int64
 	mu         sync.RWMutex
 }
 
@@ -461,6 +652,7 @@ type MemoryStorage struct {
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
 		recordings: make(map[string]*Recording),
+		retention:  newRetentionIndex(),
 	}
 }
 
@@ -477,7 +669,15 @@ func (ms *MemoryStorage) Save(recording *Recording) error {
 		return fmt.Errorf("recording ID cannot be empty")
 	}
 
+	// Size recordings as file storage would write them
+	encoded, err := json.Marshal(recording)
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+
 	ms.recordings[recording.ID] = recording
+	ms.retention.put(recording.ID, recording.Timestamp, int64(len(encoded)))
+	ms.evict(RetentionPolicy{MaxRecordings: ms.policy.MaxRecordings, MaxTotalSize: ms.policy.MaxTotalSize}, time.Now())
 	return nil
 }
 
@@ -503,31 +703,46 @@ func (ms *MemoryStorage) List(filter ListFilter) ([]*Recording, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
-	var recordings []*Recording
+	recordings := ms.matching(filter)
+
+	// Apply offset and limit
+	start, end := pageBounds(len(recordings), filter)
+	return recordings[start:end], nil
+}
+
+// ListPage returns the page of recordings selected by the filter's offset
+// and limit, together with the number of recordings matching it
+func (ms *MemoryStorage) ListPage(filter ListFilter) (*RecordingPage, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	recordings := ms.matching(filter)
+	start, end := pageBounds(len(recordings), filter)
+	return &RecordingPage{
+		Recordings: append([]*Recording{}, recordings[start:end]...),
+		Offset:     filter.Offset,
+		Total:      len(recordings),
+	}, nil
+}
 
+// matching returns the recordings matching the filter, newest first.
+// Callers must hold ms.mu.
+func (ms *MemoryStorage) matching(filter ListFilter) []*Recording {
+	recordings := []*Recording{}
 	for _, recording := range ms.recordings {
 		if ms.matchesFilter(recording, filter) {
 			recordings = append(recordings, recording)
 		}
 	}
 
-	// Sort by timestamp (newest first)
+	// Sort by timestamp (newest first), then ID so pages are stable
 	sort.Slice(recordings, func(i, j int) bool {
-		return recordings[i].Timestamp.After(recordings[j].Timestamp)
+		if !recordings[i].Timestamp.Equal(recordings[j].Timestamp) {
+			return recordings[i].Timestamp.After(recordings[j].Timestamp)
+		}
+		return recordings[i].ID < recordings[j].ID
 	})
-
-	// Apply offset and limit
-	start := filter.Offset
-	if start >= len(recordings) {
-		return []*Recording{}, nil
-	}
-
-	end := len(recordings)
-	if filter.Limit > 0 && start+filter.Limit < end {
-		end = start + filter.Limit
-	}
-
-	return recordings[start:end], nil
+	return recordings
 }
 
 // Delete removes a recording by ID from memory
@@ -544,6 +759,7 @@ func (ms *MemoryStorage) Delete(id string) error {
 	}
 
 	delete(ms.recordings, id)
+	ms.retention.remove(id)
 	return nil
 }
 
@@ -553,44 +769,47 @@ func (ms *MemoryStorage) DeleteAll() error {
 	defer ms.mu.Unlock()
 
 	ms.recordings = make(map[string]*Recording)
+	ms.retention = newRetentionIndex()
 	return nil
 }
 
-// GetStats returns storage statistics from memory
-func (ms *MemoryStorage) GetStats() StorageStats {
-	ms.mu.RLock()
-	defer ms.mu.RUnlock()
+// SetRetention sets the count and size limits Save enforces
+func (ms *MemoryStorage) SetRetention(policy RetentionPolicy) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.policy = policy
+}
 
-	stats := StorageStats{
-		TotalRecordings: int64(len(ms.recordings)),
-	}
+// Prune deletes the recordings the retention policy no longer allows,
+// oldest first, and returns how many were removed
+func (ms *MemoryStorage) Prune(policy RetentionPolicy) (int, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.evict(policy, time.Now()), nil
+}
 
-	if len(ms.recordings) == 0 {
-		return stats
+// evict removes the recordings policy evicts. Callers must hold ms.mu.
+func (ms *MemoryStorage) evict(policy RetentionPolicy, now time.Time) int {
+	evicted := ms.retention.evict(policy, now)
+	for _, id := range evicted {
+		delete(ms.recordings, id)
 	}
+	ms.pruned += int64(len(evicted))
+	return len(evicted)
+}
 
-	var oldest, newest time.Time
-	first := true
+// GetStats returns storage statistics from memory
+func (ms *MemoryStorage) GetStats() StorageStats {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
 
-	for _, recording := range ms.recordings {
-		if first {
-			oldest = recording.Timestamp
-			newest = recording.Timestamp
-			first = false
-		} else {
-			if recording.Timestamp.Before(oldest) {
-				oldest = recording.Timestamp
-			}
-			if recording.Timestamp.After(newest) {
-				newest = recording.Timestamp
-			}
-		}
+	return StorageStats{
+		TotalRecordings:  int64(len(ms.recordings)),
+		TotalSize:        ms.retention.total,
+		PrunedRecordings: ms.pruned,
+		OldestRecording:  ms.retention.oldest(),
+		NewestRecording:  ms.retention.newest(),
 	}
-
-	stats.OldestRecording = oldest
-	stats.NewestRecording = newest
-
-	return stats
 }
 
 // Close closes the memory storage (no-op)
@@ -650,5 +869,10 @@ func (ms *MemoryStorage) matchesFilter(recording *Recording, filter ListFilter)
 		}
 	}
 
-	return true
+	// Duplicate count filter
+	if filter.MinCount > 0 && max(recording.Metadata.Count, 1) < filter.MinCount {
+		return false
+	}
+
+	return matchesContent(recording, filter)
 }
\ No newline at end of file
@@ -309,6 +309,249 @@ func TestListFilter_Pagination(t *testing.T) {
 	assert.Len(t, results, 0)
 }
 
+func TestListPage_StableOrderingAndTotals(t *testing.T) {
+	fileStorage, err := NewFileStorage(&config.StorageConfig{
+		Type:      "file",
+		Directory: t.TempDir(),
+		Format:    "json",
+	}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer fileStorage.Close()
+
+	for name, storage := range map[string]Storage{"file": fileStorage, "memory": NewMemoryStorage()} {
+		t.Run(name, func(t *testing.T) {
+			// Recordings sharing a timestamp must not move between pages
+			now := time.Now()
+			for i := 0; i < 25; i++ {
+				require.NoError(t, storage.Save(&Recording{
+					ID:        fmt.Sprintf("recording-%02d", i),
+					Timestamp: now.Add(-time.Duration(i/5) * time.Minute),
+					Request:   RecordedRequest{Method: "GET", Body: []byte(fmt.Sprintf("payload-%d", i%2))},
+					Response:  RecordedResponse{StatusCode: 200},
+				}))
+			}
+
+			for _, filter := range []ListFilter{{}, {BodyContains: "payload-0"}} {
+				all, err := storage.ListPage(filter)
+				require.NoError(t, err)
+
+				var paged []string
+				for offset := 0; offset < all.Total+10; offset += 10 {
+					filter.Offset, filter.Limit = offset, 10
+					page, err := storage.ListPage(filter)
+					require.NoError(t, err)
+					assert.Equal(t, all.Total, page.Total)
+					assert.Equal(t, offset, page.Offset)
+					for _, recording := range page.Recordings {
+						paged = append(paged, recording.ID)
+					}
+				}
+
+				var ids []string
+				for _, recording := range all.Recordings {
+					ids = append(ids, recording.ID)
+				}
+				assert.Equal(t, ids, paged)
+			}
+
+			all, err := storage.ListPage(ListFilter{})
+			require.NoError(t, err)
+			assert.Equal(t, 25, all.Total)
+			assert.Equal(t, []string{"recording-00", "recording-01"}, []string{all.Recordings[0].ID, all.Recordings[1].ID})
+
+			matching, err := storage.ListPage(ListFilter{BodyContains: "payload-0", Offset: 10, Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, 13, matching.Total)
+			assert.Len(t, matching.Recordings, 3)
+		})
+	}
+}
+
+func TestListFilter_HeaderAndBody(t *testing.T) {
+	fileStorage, err := NewFileStorage(&config.StorageConfig{
+		Type:      "file",
+		Directory: t.TempDir(),
+		Format:    "json",
+	}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer fileStorage.Close()
+
+	storages := map[string]Storage{
+		"memory": NewMemoryStorage(),
+		"file":   fileStorage,
+	}
+
+	now := time.Now()
+	recordings := []*Recording{
+		{
+			ID:        "acme-order",
+			Timestamp: now.Add(-3 * time.Minute),
+			Request: RecordedRequest{
+				Method:  "POST",
+				URI:     "/orders",
+				Headers: map[string]string{"X-Tenant-Id": "acme"},
+				Body:    []byte(`{"sku": "widget-7"}`),
+			},
+			Response: RecordedResponse{StatusCode: 201, Body: []byte(`{"id": "order-1234"}`)},
+		},
+		{
+			ID:        "acme-lookup",
+			Timestamp: now.Add(-2 * time.Minute),
+			Request: RecordedRequest{
+				Method:  "GET",
+				URI:     "/orders/order-1234",
+				Headers: map[string]string{"x-tenant-id": "acme", "Accept": "application/json"},
+			},
+			Response: RecordedResponse{StatusCode: 200, Body: []byte(`{"id": "order-1234", "sku": "widget-7"}`)},
+		},
+		{
+			ID:        "globex-order",
+			Timestamp: now.Add(-1 * time.Minute),
+			Request: RecordedRequest{
+				Method:  "POST",
+				URI:     "/orders",
+				Headers: map[string]string{"X-Tenant-Id": "globex"},
+				Body:    []byte(`{"sku": "gadget-2"}`),
+			},
+			Response: RecordedResponse{StatusCode: 201, Body: []byte(`{"id": "order-5678"}`)},
+		},
+	}
+
+	ids := func(recordings []*Recording) []string {
+		var result []string
+		for _, recording := range recordings {
+			result = append(result, recording.ID)
+		}
+		return result
+	}
+
+	for name, storage := range storages {
+		t.Run(name, func(t *testing.T) {
+			for _, recording := range recordings {
+				require.NoError(t, storage.Save(recording))
+			}
+
+			// Header names match case-insensitively, values exactly
+			results, err := storage.List(ListFilter{HeaderMatch: map[string]string{"X-TENANT-ID": "acme"}})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"acme-lookup", "acme-order"}, ids(results))
+
+			results, err = storage.List(ListFilter{HeaderMatch: map[string]string{"X-Tenant-Id": "ACME"}})
+			require.NoError(t, err)
+			assert.Empty(t, results)
+
+			// Body text matches the request or the response
+			results, err = storage.List(ListFilter{BodyContains: "order-1234"})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"acme-lookup", "acme-order"}, ids(results))
+
+			results, err = storage.List(ListFilter{BodyContains: "gadget"})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"globex-order"}, ids(results))
+
+			// Content filters combine with the indexed ones and with paging
+			results, err = storage.List(ListFilter{
+				Methods:      []string{"POST"},
+				BodyContains: "sku",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"globex-order", "acme-order"}, ids(results))
+
+			results, err = storage.List(ListFilter{BodyContains: "sku", Offset: 1, Limit: 1})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"acme-lookup"}, ids(results))
+		})
+	}
+}
+
+func TestListFilter_MinCount(t *testing.T) {
+	fileStorage, err := NewFileStorage(&config.StorageConfig{
+		Type:      "file",
+		Directory: t.TempDir(),
+		Format:    "json",
+	}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer fileStorage.Close()
+
+	storages := map[string]Storage{
+		"memory": NewMemoryStorage(),
+		"file":   fileStorage,
+	}
+
+	now := time.Now()
+	for name, storage := range storages {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, storage.Save(&Recording{ID: "single", Timestamp: now.Add(-2 * time.Minute)}))
+			require.NoError(t, storage.Save(&Recording{ID: "polled", Timestamp: now.Add(-time.Minute), Metadata: RecordingMetadata{Count: 5}}))
+
+			results, err := storage.List(ListFilter{MinCount: 2})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, "polled", results[0].ID)
+			assert.Equal(t, 5, results[0].Metadata.Count)
+
+			results, err = storage.List(ListFilter{MinCount: 1})
+			require.NoError(t, err)
+			assert.Len(t, results, 2)
+		})
+	}
+}
+
+func TestFileStorage_Walk(t *testing.T) {
+	storage, err := NewFileStorage(&config.StorageConfig{
+		Type:      "file",
+		Directory: t.TempDir(),
+		Format:    "json",
+	}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer storage.Close()
+
+	now := time.Now()
+	for i := 0; i < 6; i++ {
+		method := "GET"
+		if i%2 == 1 {
+			method = "POST"
+		}
+		require.NoError(t, storage.Save(&Recording{
+			ID:        fmt.Sprintf("recording-%d", i),
+			Timestamp: now.Add(-time.Duration(i) * time.Minute),
+			Request:   RecordedRequest{Method: method, Body: []byte(fmt.Sprintf("payload-%d", i))},
+		}))
+	}
+
+	walk := func(filter ListFilter) []string {
+		var ids []string
+		require.NoError(t, storage.Walk(filter, func(recording *Recording) error {
+			ids = append(ids, recording.ID)
+			return nil
+		}))
+		return ids
+	}
+
+	// Walking visits what List returns, in the same order
+	listed, err := storage.List(ListFilter{Methods: []string{"POST"}, Offset: 1})
+	require.NoError(t, err)
+	var listedIDs []string
+	for _, recording := range listed {
+		listedIDs = append(listedIDs, recording.ID)
+	}
+	assert.Equal(t, listedIDs, walk(ListFilter{Methods: []string{"POST"}, Offset: 1}))
+	assert.Equal(t, []string{"recording-3", "recording-5"}, listedIDs)
+
+	assert.Equal(t, []string{"recording-0", "recording-1"}, walk(ListFilter{Limit: 2}))
+	assert.Equal(t, []string{"recording-4"}, walk(ListFilter{BodyContains: "payload-4"}))
+
+	// An error from the callback stops the walk
+	stop := fmt.Errorf("stop")
+	visited := 0
+	err = storage.Walk(ListFilter{}, func(*Recording) error {
+		visited++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, visited)
+}
+
 func TestMemoryStorage_EdgeCases(t *testing.T) {
 	storage := NewMemoryStorage()
 
@@ -334,4 +577,190 @@ func TestMemoryStorage_EdgeCases(t *testing.T) {
 
 	err = storage.Delete("")
 	assert.Error(t, err)
-}
\ No newline at end of file
+}
+func retentionStorages(t *testing.T) map[string]Storage {
+	fileStorage, err := NewFileStorage(&config.StorageConfig{
+		Type:      "file",
+		Directory: t.TempDir(),
+		Format:    "json",
+	}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { fileStorage.Close() })
+
+	return map[string]Storage{
+		"memory": NewMemoryStorage(),
+		"file":   fileStorage,
+	}
+}
+
+func saveAged(t *testing.T, storage Storage, id string, age time.Duration, bodySize int) {
+	require.NoError(t, storage.Save(&Recording{
+		ID:        id,
+		Timestamp: time.Now().Add(-age),
+		Request:   RecordedRequest{Method: "GET", URI: "/" + id},
+		Response:  RecordedResponse{StatusCode: 200, Body: make([]byte, bodySize)},
+	}))
+}
+
+func storedIDs(t *testing.T, storage Storage) []string {
+	recordings, err := storage.List(ListFilter{})
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(recordings))
+	for _, recording := range recordings {
+		ids = append(ids, recording.ID)
+	}
+	return ids
+}
+
+func TestStorage_PruneByAge(t *testing.T) {
+	for name, storage := range retentionStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			saveAged(t, storage, "expired", 8*24*time.Hour, 10)
+			saveAged(t, storage, "stale", 7*24*time.Hour+time.Minute, 10)
+			saveAged(t, storage, "fresh", time.Hour, 10)
+
+			removed, err := storage.Prune(RetentionPolicy{MaxAge: 7 * 24 * time.Hour})
+			require.NoError(t, err)
+			assert.Equal(t, 2, removed)
+			assert.Equal(t, []string{"fresh"}, storedIDs(t, storage))
+
+			_, err = storage.Load("expired")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStorage_PruneByTotalSizeEvictsOldest(t *testing.T) {
+	for name, storage := range retentionStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			saveAged(t, storage, "oldest", 3*time.Hour, 1000)
+			saveAged(t, storage, "older", 2*time.Hour, 1000)
+			saveAged(t, storage, "newest", time.Hour, 1000)
+
+			total := storage.GetStats().TotalSize
+			require.Positive(t, total)
+
+			// Room for two of the three equally sized recordings
+			removed, err := storage.Prune(RetentionPolicy{MaxTotalSize: total * 2 / 3})
+			require.NoError(t, err)
+			assert.Equal(t, 1, removed)
+			assert.Equal(t, []string{"newest", "older"}, storedIDs(t, storage))
+			assert.LessOrEqual(t, storage.GetStats().TotalSize, total*2/3)
+		})
+	}
+}
+
+func TestStorage_PruneByCount(t *testing.T) {
+	for name, storage := range retentionStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 5; i++ {
+				saveAged(t, storage, fmt.Sprintf("recording-%d", i), time.Duration(5-i)*time.Minute, 10)
+			}
+
+			removed, err := storage.Prune(RetentionPolicy{MaxRecordings: 2})
+			require.NoError(t, err)
+			assert.Equal(t, 3, removed)
+			assert.Equal(t, []string{"recording-4", "recording-3"}, storedIDs(t, storage))
+
+			removed, err = storage.Prune(RetentionPolicy{MaxRecordings: 2})
+			require.NoError(t, err)
+			assert.Zero(t, removed)
+		})
+	}
+}
+
+func TestFileStorage_PrunePersistsIndex(t *testing.T) {
+	dir := t.TempDir()
+	storageConfig := &config.StorageConfig{Type: "file", Directory: dir, Format: "json"}
+
+	storage, err := NewFileStorage(storageConfig, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	saveAged(t, storage, "expired", 48*time.Hour, 10)
+	saveAged(t, storage, "fresh", time.Minute, 10)
+
+	removed, err := storage.Prune(RetentionPolicy{MaxAge: 24 * time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	require.NoError(t, storage.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-expired.json"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	reopened, err := NewFileStorage(storageConfig, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, []string{"fresh"}, storedIDs(t, reopened))
+}
+
+func TestStorage_SaveEnforcesCountAndSize(t *testing.T) {
+	for name, storage := range retentionStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			storage.SetRetention(RetentionPolicy{MaxRecordings: 3})
+			for i := 0; i < 5; i++ {
+				saveAged(t, storage, fmt.Sprintf("recording-%d", i), time.Duration(5-i)*time.Minute, 10)
+			}
+			assert.Equal(t, []string{"recording-4", "recording-3", "recording-2"}, storedIDs(t, storage))
+			assert.Equal(t, int64(2), storage.GetStats().PrunedRecordings)
+
+			// A recording older than the rest is the first to go
+			saveAged(t, storage, "backfilled", time.Hour, 10)
+			assert.Equal(t, []string{"recording-4", "recording-3", "recording-2"}, storedIDs(t, storage))
+
+			require.NoError(t, storage.DeleteAll())
+			saveAged(t, storage, "probe", time.Minute, 1000)
+			size := storage.GetStats().TotalSize
+			require.NoError(t, storage.DeleteAll())
+
+			storage.SetRetention(RetentionPolicy{MaxTotalSize: size*2 + size/2})
+			for _, id := range []string{"a", "b", "c", "d"} {
+				saveAged(t, storage, id, time.Minute, 1000)
+			}
+			stats := storage.GetStats()
+			assert.Equal(t, int64(2), stats.TotalRecordings)
+			assert.LessOrEqual(t, stats.TotalSize, size*2+size/2)
+		})
+	}
+}
+
+func TestStorage_TotalSizeIsKeptRunning(t *testing.T) {
+	for name, storage := range retentionStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			saveAged(t, storage, "one", 2*time.Minute, 100)
+			one := storage.GetStats().TotalSize
+			saveAged(t, storage, "two", time.Minute, 100)
+			assert.Greater(t, storage.GetStats().TotalSize, one)
+
+			// Saving the same recording again replaces its size
+			saveAged(t, storage, "two", time.Minute, 100)
+			require.NoError(t, storage.Delete("two"))
+			stats := storage.GetStats()
+			assert.Equal(t, one, stats.TotalSize)
+			assert.Equal(t, stats.OldestRecording, stats.NewestRecording)
+		})
+	}
+}
+
+func TestFileStorage_TotalSizeSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	storageConfig := &config.StorageConfig{Type: "file", Directory: dir, Format: "json"}
+
+	storage, err := NewFileStorage(storageConfig, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	saveAged(t, storage, "older", 2*time.Minute, 100)
+	saveAged(t, storage, "newer", time.Minute, 100)
+	stats := storage.GetStats()
+	require.NoError(t, storage.Close())
+
+	reopened, err := NewFileStorage(storageConfig, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer reopened.Close()
+	reopenedStats := reopened.GetStats()
+	assert.Equal(t, stats.TotalSize, reopenedStats.TotalSize)
+	assert.True(t, stats.OldestRecording.Equal(reopenedStats.OldestRecording))
+
+	reopened.SetRetention(RetentionPolicy{MaxRecordings: 2})
+	saveAged(t, reopened, "newest", 0, 100)
+	assert.Equal(t, []string{"newest", "newer"}, storedIDs(t, reopened))
+}
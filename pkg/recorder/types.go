@@ -34,13 +34,17 @@ type RecordedResponse struct {
 
 // RecordingMetadata contains additional context about the recording
 type RecordingMetadata struct {
-	Source       string   `json:"source"`        // "live" or "generated"
+	Source       string   `json:"source"`        // "live", "proxy" or "generated"
 	Endpoint     string   `json:"endpoint"`      // OpenAPI operation ID
 	ClientIP     string   `json:"client_ip"`
 	UserAgent    string   `json:"user_agent"`
 	RequestID    string   `json:"request_id"`
+	Fingerprint  string   `json:"fingerprint,omitempty"` // Stable hash of method, path, query and relevant headers
 	ChaosApplied bool     `json:"chaos_applied,omitempty"`
+	BodyOmitted  bool     `json:"body_omitted,omitempty"` // Body not captured (streaming path or large proxied body)
 	Tags         []string `json:"tags,omitempty"`
+	ContentHash  string   `json:"content_hash,omitempty"` // Hash identifying duplicates when dedup is enabled
+	Count        int      `json:"count,omitempty"`        // Times the exchange was seen when dedup is enabled
 }
 
 
@@ -53,24 +57,56 @@ type ListFilter struct {
 	Methods     []string  `json:"methods,omitempty"`
 	Endpoints   []string  `json:"endpoints,omitempty"`
 	StatusCodes []int     `json:"status_codes,omitempty"`
+
+	// HeaderMatch requires each request header (name matched
+	// case-insensitively) to have exactly the given value
+	HeaderMatch map[string]string `json:"header_match,omitempty"`
+	// BodyContains requires the request or response body to contain the text
+	BodyContains string `json:"body_contains,omitempty"`
+	// MinCount requires a deduplicated recording to have been seen at least
+	// this many times
+	MinCount int `json:"min_count,omitempty"`
+}
+
+// RecordingPage is one page of a filtered listing, along with the number of
+// recordings matching the filter across all pages
+type RecordingPage struct {
+	Recordings []*Recording `json:"recordings"`
+	Offset     int          `json:"offset"`
+	Total      int          `json:"total"`
 }
 
 // StorageStats provides statistics about storage usage
 type StorageStats struct {
-	TotalRecordings int64     `json:"total_recordings"`
-	TotalSize       int64     `json:"total_size_bytes"`
-	OldestRecording time.Time `json:"oldest_recording,omitempty"`
-	NewestRecording time.Time `json:"newest_recording,omitempty"`
+	TotalRecordings  int64     `json:"total_recordings"`
+	TotalSize        int64     `json:"total_size_bytes"`
+	PrunedRecordings int64     `json:"pruned_recordings"` // Recordings deleted by the retention limits
+	OldestRecording  time.Time `json:"oldest_recording,omitempty"`
+	NewestRecording  time.Time `json:"newest_recording,omitempty"`
+}
+
+// RetentionPolicy bounds the recordings a storage keeps. Recordings older
+// than MaxAge are deleted, then the oldest are evicted until at most
+// MaxRecordings remain using at most MaxTotalSize bytes. Zero disables a limit.
+// Storages enforce the count and size limits on every Save; the age limit is
+// enforced by Prune.
+type RetentionPolicy struct {
+	MaxRecordings int
+	MaxAge        time.Duration
+	MaxTotalSize  int64
 }
 
 // RecordingStats tracks recording system statistics
 type RecordingStats struct {
-	TotalRequests    int64     `json:"total_requests"`
-	RecordedRequests int64     `json:"recorded_requests"`
-	FilteredRequests int64     `json:"filtered_requests"`
-	Errors           int64     `json:"errors"`
-	StartTime        time.Time `json:"start_time"`
-	LastRecording    time.Time `json:"last_recording"`
+	TotalRequests     int64     `json:"total_requests"`
+	RecordedRequests  int64     `json:"recorded_requests"`
+	FilteredRequests  int64     `json:"filtered_requests"`
+	Errors            int64     `json:"errors"`
+	DroppedCaptures   int64     `json:"dropped_captures"`   // Captures skipped because too many were in flight
+	DuplicateRequests int64     `json:"duplicate_requests"` // Captures counted against an identical stored recording
+	PrunedRecordings  int64     `json:"pruned_recordings"`  // Recordings the storage deleted by the retention limits
+	StartTime         time.Time `json:"start_time"`
+	LastRecording     time.Time `json:"last_recording"`
 }
 
 // ReplayConfig defines configuration for traffic replay
@@ -84,6 +120,28 @@ type ReplayConfig struct {
 	ReplaceHost     bool              `yaml:"replace_host"`
 	PreserveHeaders []string          `yaml:"preserve_headers"`
 	OverrideHeaders map[string]string `yaml:"override_headers"`
+
+	// PreserveTiming replays requests in timestamp order, spaced by the gaps
+	// between their recorded timestamps instead of DelayBetween
+	PreserveTiming bool `yaml:"preserve_timing"`
+	// TimeScale multiplies the recorded gaps when PreserveTiming is set, so
+	// 0.5 replays twice as fast. Zero means 1.
+	TimeScale float64 `yaml:"time_scale"`
+
+	// Extractors maps a JSONPath expression (e.g. "$.data.id") evaluated
+	// against each live JSON response to the variable name it populates
+	Extractors map[string]string `yaml:"extractors"`
+	// Substitutions maps a variable name to the places its value is injected
+	// in addition to any ${var} tokens found in the recorded request
+	Substitutions map[string][]ReplaySubstitution `yaml:"substitutions"`
+}
+
+// ReplaySubstitution describes where an extracted variable is injected
+type ReplaySubstitution struct {
+	In       string `yaml:"in"`       // "path", "header" or "body"
+	Name     string `yaml:"name"`     // Header name when In is "header"
+	Match    string `yaml:"match"`    // Recorded literal replaced by the value in the path or body
+	Template string `yaml:"template"` // Header value template, defaults to "${var}"
 }
 
 // ReplayStats tracks replay operation statistics
@@ -94,6 +152,21 @@ type ReplayStats struct {
 	AverageLatency  time.Duration `json:"average_latency"`
 	StartTime       time.Time     `json:"start_time"`
 	EndTime         time.Time     `json:"end_time"`
+	// Results holds one entry per replayed recording, in replay order
+	Results []ReplayResult `json:"results,omitempty"`
+}
+
+// ReplayResult compares a replayed request with the recording it came from
+type ReplayResult struct {
+	ID               string        `json:"id"`
+	Method           string        `json:"method"`
+	URI              string        `json:"uri"`
+	RecordedStatus   int           `json:"recorded_status"`
+	ReplayedStatus   int           `json:"replayed_status"` // 0 when the request failed
+	StatusMatch      bool          `json:"status_match"`
+	RecordedDuration time.Duration `json:"recorded_duration"`
+	ReplayedDuration time.Duration `json:"replayed_duration"`
+	Error            string        `json:"error,omitempty"`
 }
 
 // RecordingIndex represents metadata for efficient recording lookup
@@ -103,6 +176,8 @@ type RecordingIndex struct {
 	Method    string    `json:"method"`
 	URI       string    `json:"uri"`
 	Status    int       `json:"status"`
+	Count     int       `json:"count,omitempty"`
+	Size      int64     `json:"size,omitempty"`
 	Filename  string    `json:"filename"`
 }
 
@@ -0,0 +1,155 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Substitution targets supported by ReplaySubstitution.In
+const (
+	SubstituteInPath   = "path"
+	SubstituteInHeader = "header"
+	SubstituteInBody   = "body"
+)
+
+// variablePattern matches ${var} tokens in replayed requests
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z0-9_.\-]+)\}`)
+
+// expandVariables replaces ${var} tokens with known variable values.
+// Tokens referring to unknown variables are left untouched.
+func expandVariables(input string, vars map[string]string) string {
+	if len(vars) == 0 || !strings.Contains(input, "${") {
+		return input
+	}
+
+	return variablePattern.ReplaceAllStringFunc(input, func(token string) string {
+		name := variablePattern.FindStringSubmatch(token)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return token
+	})
+}
+
+// extractVariables evaluates the configured extractors against a JSON body
+// and returns the resolved variables keyed by name
+func extractVariables(body []byte, extractors map[string]string) (map[string]string, error) {
+	if len(extractors) == 0 || len(body) == 0 {
+		return nil, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("response body is not valid JSON: %w", err)
+	}
+
+	result := make(map[string]string, len(extractors))
+	for path, name := range extractors {
+		value, err := evaluateJSONPath(doc, path)
+		if err != nil {
+			return result, fmt.Errorf("extractor %s: %w", path, err)
+		}
+		result[name] = stringifyJSONValue(value)
+	}
+
+	return result, nil
+}
+
+// evaluateJSONPath resolves a simple JSONPath expression against a decoded
+// JSON document. Supported syntax: $, .field, ['field'] and [index].
+func evaluateJSONPath(doc interface{}, path string) (interface{}, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path must start with '$'")
+	}
+
+	current := doc
+	rest := path[1:]
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("empty field name")
+			}
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot access field %q on non-object", key)
+			}
+			value, exists := obj[key]
+			if !exists {
+				return nil, fmt.Errorf("field %q not found", key)
+			}
+			current = value
+			rest = rest[end:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated bracket")
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+
+			if quoted := strings.Trim(selector, `'"`); quoted != selector {
+				obj, ok := current.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("cannot access field %q on non-object", quoted)
+				}
+				value, exists := obj[quoted]
+				if !exists {
+					return nil, fmt.Errorf("field %q not found", quoted)
+				}
+				current = value
+				continue
+			}
+
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index %q", selector)
+			}
+			arr, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot index non-array")
+			}
+			if index < 0 || index >= len(arr) {
+				return nil, fmt.Errorf("array index %d out of range", index)
+			}
+			current = arr[index]
+
+		default:
+			return nil, fmt.Errorf("unexpected character %q", rest[0])
+		}
+	}
+
+	return current, nil
+}
+
+// stringifyJSONValue converts a decoded JSON value into its textual form
+func stringifyJSONValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
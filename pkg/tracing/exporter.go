@@ -0,0 +1,244 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Exporter receives spans as they end
+type Exporter interface {
+	Export(span *Span)
+	Shutdown(ctx context.Context) error
+}
+
+// InMemoryExporter keeps finished spans in memory, for tests
+type InMemoryExporter struct {
+	spans []*Span
+	mu    sync.Mutex
+}
+
+// NewInMemoryExporter creates an empty in-memory exporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// Export records span
+func (e *InMemoryExporter) Export(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// Spans returns the spans exported so far, in the order they ended
+func (e *InMemoryExporter) Spans() []*Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	spans := make([]*Span, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+// Shutdown is a no-op
+func (e *InMemoryExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+const (
+	otlpQueueSize     = 2048
+	otlpBatchSize     = 512
+	otlpFlushInterval = 5 * time.Second
+)
+
+// OTLPExporter batches spans and posts them to an OTLP/HTTP collector using
+// the JSON encoding. Spans are dropped, not blocked on, when the queue is full.
+type OTLPExporter struct {
+	url         string
+	serviceName string
+	client      *http.Client
+	logger      *zap.Logger
+
+	queue  chan *Span
+	done   chan struct{}
+	closed bool
+	mu     sync.RWMutex // guards closed so Export never sends on a closed queue
+}
+
+// NewOTLPExporter creates an exporter posting to endpoint + "/v1/traces"
+// (for example http://localhost:4318) and starts its background flusher
+func NewOTLPExporter(endpoint, serviceName string, logger *zap.Logger) *OTLPExporter {
+	e := &OTLPExporter{
+		url:         strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		logger:      logger,
+		queue:       make(chan *Span, otlpQueueSize),
+		done:        make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Export queues span for the next batch
+func (e *OTLPExporter) Export(span *Span) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return
+	}
+
+	select {
+	case e.queue <- span:
+	default:
+		e.logger.Warn("Dropping span: export queue is full", zap.String("span", span.Name))
+	}
+}
+
+// Shutdown flushes queued spans, waiting at most until ctx is done. Spans
+// that end after Shutdown are dropped.
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to flush spans: %w", ctx.Err())
+	}
+}
+
+func (e *OTLPExporter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, otlpBatchSize)
+	for {
+		select {
+		case span, ok := <-e.queue:
+			if !ok {
+				e.flush(batch)
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) >= otlpBatchSize {
+				e.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			e.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (e *OTLPExporter) flush(batch []*Span) {
+	if len(batch) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(otlpRequest(e.serviceName, batch))
+	if err != nil {
+		e.logger.Error("Failed to encode spans", zap.Error(err))
+		return
+	}
+
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		e.logger.Warn("Failed to export spans", zap.String("url", e.url), zap.Int("spans", len(batch)), zap.Error(err))
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		e.logger.Warn("Collector rejected spans",
+			zap.String("url", e.url),
+			zap.Int("spans", len(batch)),
+			zap.Int("status", resp.StatusCode))
+	}
+}
+
+// otlpRequest builds an ExportTraceServiceRequest in the OTLP JSON encoding
+func otlpRequest(serviceName string, spans []*Span) map[string]interface{} {
+	encoded := make([]map[string]interface{}, 0, len(spans))
+	for _, span := range spans {
+		encoded = append(encoded, otlpSpan(span))
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]interface{}{"service.name": serviceName}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": "vanta"},
+						"spans": encoded,
+					},
+				},
+			},
+		},
+	}
+}
+
+func otlpSpan(span *Span) map[string]interface{} {
+	span.mu.Lock()
+	defer span.mu.Unlock()
+
+	// OTLP span kinds: 1 internal, 2 server
+	kind := 1
+	if span.Kind == SpanKindServer {
+		kind = 2
+	}
+
+	encoded := map[string]interface{}{
+		"traceId":           span.SpanContext.TraceID.String(),
+		"spanId":            span.SpanContext.SpanID.String(),
+		"name":              span.Name,
+		"kind":              kind,
+		"startTimeUnixNano": strconv.FormatInt(span.StartTime.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+		"attributes":        otlpAttributes(span.Attributes),
+		"status":            map[string]interface{}{"code": int(span.Status), "message": span.StatusMessage},
+	}
+	if span.Parent.IsValid() {
+		encoded["parentSpanId"] = span.Parent.SpanID.String()
+	}
+	return encoded
+}
+
+func otlpAttributes(attributes map[string]interface{}) []map[string]interface{} {
+	encoded := make([]map[string]interface{}, 0, len(attributes))
+	for key, value := range attributes {
+		var anyValue map[string]interface{}
+		switch v := value.(type) {
+		case bool:
+			anyValue = map[string]interface{}{"boolValue": v}
+		case int:
+			anyValue = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case int64:
+			anyValue = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			anyValue = map[string]interface{}{"doubleValue": v}
+		default:
+			anyValue = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		encoded = append(encoded, map[string]interface{}{"key": key, "value": anyValue})
+	}
+	return encoded
+}
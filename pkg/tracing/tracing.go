@@ -0,0 +1,269 @@
+// Package tracing provides lightweight distributed tracing compatible with
+// OpenTelemetry: W3C traceparent propagation, spans, and exporters that ship
+// finished spans to an OTLP/HTTP collector or keep them in memory for tests.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceparentHeader is the W3C Trace Context propagation header
+const TraceparentHeader = "traceparent"
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// IsValid reports whether the ID is not all zeros
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// IsValid reports whether the ID is not all zeros
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// SpanContext is the part of a span that propagates across process boundaries
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether both IDs are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent formats the span context as a W3C traceparent header value
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent parses a W3C traceparent header value. It returns false
+// for malformed values and all-zero IDs, which callers treat as no parent.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	// Version 00 has exactly four fields; later versions may append more
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&0x01 == 0x01
+
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
+
+// SpanKind describes the relationship of a span to its callers
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+)
+
+func (k SpanKind) String() string {
+	if k == SpanKindServer {
+		return "server"
+	}
+	return "internal"
+}
+
+// StatusCode is the outcome of the operation a span covers
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+// Span records a single timed operation. All methods are safe to call on a
+// nil span so callers do not need to check whether tracing is enabled.
+type Span struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	Parent        SpanContext
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    map[string]interface{}
+	Status        StatusCode
+	StatusMessage string
+
+	tracer *Tracer
+	ended  bool
+	mu     sync.Mutex
+}
+
+// SetAttribute sets an attribute on the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attributes[key] = value
+}
+
+// SetStatus records the outcome of the span
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Status = code
+	s.StatusMessage = message
+}
+
+// RecordError marks the span as failed with err; a nil err is ignored
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.SetStatus(StatusError, err.Error())
+	}
+}
+
+// End finishes the span and hands it to the tracer's exporter. Only the
+// first call has any effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+
+	if s.SpanContext.Sampled {
+		s.tracer.exporter.Export(s)
+	}
+}
+
+// Duration returns how long the span lasted, or 0 before End
+func (s *Span) Duration() time.Duration {
+	if s == nil || s.EndTime.IsZero() {
+		return 0
+	}
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// Tracer starts spans and exports them once they end
+type Tracer struct {
+	serviceName string
+	exporter    Exporter
+}
+
+// NewTracer creates a tracer that reports spans for serviceName to exporter
+func NewTracer(serviceName string, exporter Exporter) *Tracer {
+	return &Tracer{serviceName: serviceName, exporter: exporter}
+}
+
+// ServiceName returns the service the tracer reports spans for
+func (t *Tracer) ServiceName() string {
+	return t.serviceName
+}
+
+// Start starts a span as a child of the span or remote span context in ctx,
+// or as the root of a new trace when ctx carries neither
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+
+	span := &Span{
+		Name:       name,
+		Kind:       kind,
+		Parent:     parent,
+		StartTime:  time.Now(),
+		Attributes: make(map[string]interface{}),
+		tracer:     t,
+	}
+	span.SpanContext = SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Sampled: true}
+	if parent.IsValid() {
+		span.SpanContext.Sampled = parent.Sampled
+	} else {
+		span.SpanContext.TraceID = NewTraceID()
+	}
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Shutdown flushes spans that have not been exported yet
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	return t.exporter.Shutdown(ctx)
+}
+
+type spanKey struct{}
+type remoteSpanContextKey struct{}
+
+// SpanFromContext returns the span stored in ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext returns a context whose next span continues
+// the trace described by sc, typically parsed from an incoming traceparent
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteSpanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context new spans in ctx should use
+// as their parent: the current span if any, else a remote parent if any
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext
+	}
+	sc, _ := ctx.Value(remoteSpanContextKey{}).(SpanContext)
+	return sc
+}
+
+// NewTraceID returns a random, valid trace ID
+func NewTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
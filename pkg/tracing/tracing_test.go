@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	sc, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	invalid := []string{
+		"",
+		"garbage",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	}
+	for _, value := range invalid {
+		_, ok := ParseTraceparent(value)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestTracer_ParentChild(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer("test", exporter)
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, parent := tracer.Start(ContextWithRemoteSpanContext(context.Background(), remote), "parent", SpanKindServer)
+	_, child := tracer.Start(ctx, "child", SpanKindInternal)
+	child.RecordError(errors.New("boom"))
+	child.End()
+	parent.End()
+	parent.End() // Only the first End exports
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, parent.SpanContext, spans[0].Parent)
+	assert.Equal(t, StatusError, spans[0].Status)
+	assert.Equal(t, "boom", spans[0].StatusMessage)
+
+	assert.Equal(t, remote, spans[1].Parent)
+	assert.Equal(t, remote.TraceID, spans[1].SpanContext.TraceID)
+	assert.Equal(t, remote.TraceID, spans[0].SpanContext.TraceID)
+	assert.NotEqual(t, spans[0].SpanContext.SpanID, spans[1].SpanContext.SpanID)
+}
+
+func TestTracer_UnsampledParentIsNotExported(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer("test", exporter)
+
+	remote, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.True(t, ok)
+	_, span := tracer.Start(ContextWithRemoteSpanContext(context.Background(), remote), "unsampled", SpanKindServer)
+	span.End()
+
+	assert.Empty(t, exporter.Spans())
+}
+
+func TestSpan_NilIsNoop(t *testing.T) {
+	var span *Span
+	span.SetAttribute("key", "value")
+	span.RecordError(errors.New("ignored"))
+	span.End()
+	assert.Zero(t, span.Duration())
+}
+
+func TestOTLPExporter_FlushesOnShutdown(t *testing.T) {
+	received := make(chan map[string]interface{}, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		received <- payload
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(collector.URL+"/", "vanta-test", zaptest.NewLogger(t))
+	tracer := NewTracer("vanta-test", exporter)
+	_, span := tracer.Start(context.Background(), "GET /users", SpanKindServer)
+	span.SetAttribute("http.response.status_code", 200)
+	span.End()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, exporter.Shutdown(ctx))
+
+	var payload map[string]interface{}
+	select {
+	case payload = <-received:
+	default:
+		t.Fatal("collector received no spans")
+	}
+
+	resourceSpans := payload["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	resourceAttrs := resourceSpans["resource"].(map[string]interface{})["attributes"].([]interface{})
+	assert.Equal(t, "service.name", resourceAttrs[0].(map[string]interface{})["key"])
+
+	spans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	require.Len(t, spans, 1)
+	exported := spans[0].(map[string]interface{})
+	assert.Equal(t, "GET /users", exported["name"])
+	assert.Equal(t, 2.0, exported["kind"])
+	assert.Equal(t, span.SpanContext.TraceID.String(), exported["traceId"])
+	assert.NotContains(t, exported, "parentSpanId")
+
+	// Spans ending after shutdown are dropped rather than panicking
+	_, late := tracer.Start(context.Background(), "late", SpanKindInternal)
+	late.End()
+}